@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// command is a toolbridge-api subcommand (toolbridge-api <name> [flags])
+// Running the binary without a subcommand starts the API server.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{}
+
+// register adds a subcommand; called from init() in each command's file
+func register(name, summary string, run func(args []string) error) {
+	commands[name] = command{summary: summary, run: run}
+}
+
+// runCommand dispatches os.Args to a subcommand and exits
+// Returns only when no subcommand was given (start the server), including when
+// the arguments are server flags (see parseServerFlags).
+func runCommand(args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	name := args[0]
+	if name == "--check-config" {
+		name = "check-config"
+	}
+	if name == "help" {
+		printUsage()
+		os.Exit(0)
+	}
+	if strings.HasPrefix(name, "-") {
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if err := cmd.run(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: toolbridge-api [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command, starts the API server. Settings come from flags, then")
+	fmt.Fprintln(os.Stderr, "environment variables, then the config file (see config.example.yaml).")
+	fmt.Fprintln(os.Stderr, "\nServer flags:")
+	fs := newServerFlagSet(&serverFlags{})
+	fs.SetOutput(os.Stderr)
+	fs.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'toolbridge-api <command> -h' for command flags.")
+}
+
+// openDB connects to the configured database for commands that talk to Postgres directly
+func openDB(ctx context.Context) (*pgxpool.Pool, error) {
+	cfg, err := config.Load(env("CONFIG_FILE", ""))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.URL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required")
+	}
+	return db.Open(ctx, cfg.Database.URL)
+}
+
+// resolveUser accepts an app_user ID or a JWT subject and returns the user ID
+func resolveUser(ctx context.Context, pool *pgxpool.Pool, user string) (string, error) {
+	var id string
+	err := pool.QueryRow(ctx, `
+		SELECT id::text FROM app_user WHERE id::text = $1 OR sub = $1
+	`, user).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("user %q not found: %w", user, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+)
+
+// version is the build version, set with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// serverFlags are the command-line overrides used when starting the server
+// Precedence: flags > environment > config file > defaults.
+type serverFlags struct {
+	configPath  string
+	httpAddr    string
+	grpcAddr    string
+	databaseURL string
+	version     bool
+}
+
+// newServerFlagSet defines the server flags on a new flag set bound to f
+func newServerFlagSet(f *serverFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("toolbridge-api", flag.ContinueOnError)
+	fs.StringVar(&f.configPath, "config", "", "config file (.yaml, .yml or .toml); overrides CONFIG_FILE")
+	fs.StringVar(&f.httpAddr, "http-addr", "", "REST listen address, e.g. :8080 (overrides HTTP_ADDR)")
+	fs.StringVar(&f.grpcAddr, "grpc-addr", "", "gRPC listen address, e.g. :8082 (overrides GRPC_ADDR)")
+	fs.StringVar(&f.databaseURL, "database-url", "", "Postgres URL (overrides DATABASE_URL; visible in ps, prefer DATABASE_URL_FILE in production)")
+	fs.BoolVar(&f.version, "version", false, "print the version and exit")
+	return fs
+}
+
+// parseServerFlags parses the flags given without a subcommand
+// --help and --version print and exit; bad flags exit with status 2.
+func parseServerFlags(args []string) serverFlags {
+	var f serverFlags
+	fs := newServerFlagSet(&f)
+	fs.Usage = printUsage
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if f.version {
+		fmt.Println("toolbridge-api", version)
+		os.Exit(0)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected argument %q\n\n", fs.Arg(0))
+		printUsage()
+		os.Exit(2)
+	}
+	return f
+}
+
+// load reads the configuration (--config, else CONFIG_FILE) and applies the
+// flags on top; the build version is the default Sentry release
+func (f serverFlags) load() (*config.Config, error) {
+	cfg, err := config.Load(cmp.Or(f.configPath, env("CONFIG_FILE", "")))
+	if err != nil {
+		return nil, err
+	}
+	if f.httpAddr != "" {
+		cfg.HTTP.Addr = f.httpAddr
+	}
+	if f.grpcAddr != "" {
+		cfg.GRPC.Addr = f.grpcAddr
+	}
+	if f.databaseURL != "" {
+		cfg.Database.URL = f.databaseURL
+	}
+	if cfg.Sentry.Release == "" && version != "dev" {
+		cfg.Sentry.Release = version
+	}
+	return cfg, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServerFlagsPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "http:\n  addr: \":9000\"\ngrpc:\n  addr: \":9001\"\ndatabase:\n  url: postgres://file@db/toolbridge\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", "/does/not/exist.yaml")
+	t.Setenv("GRPC_ADDR", ":9101")
+	t.Setenv("DATABASE_URL", "postgres://env@db/toolbridge")
+
+	var f serverFlags
+	if err := newServerFlagSet(&f).Parse([]string{"--config", path, "--database-url", "postgres://flag@db/toolbridge"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := f.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP.Addr != ":9000" || cfg.GRPC.Addr != ":9101" || cfg.Database.URL != "postgres://flag@db/toolbridge" {
+		t.Errorf("http=%q grpc=%q db=%q, want file < env < flag", cfg.HTTP.Addr, cfg.GRPC.Addr, cfg.Database.URL)
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // startGRPCServer is a no-op when building without the grpc tag
-func startGRPCServer(pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg) {
+func startGRPCServer(grpcAddr string, pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg) {
 	// No-op: gRPC server not enabled
 }
 
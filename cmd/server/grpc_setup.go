@@ -19,23 +19,27 @@ import (
 var grpcServerInstance *grpc.Server
 
 // startGRPCServer initializes and starts the gRPC server
-func startGRPCServer(pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg) {
-	grpcAddr := env("GRPC_ADDR", ":8082")
+func startGRPCServer(grpcAddr string, pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg) {
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to listen for gRPC")
 	}
 
 	// Chain interceptors (executed in order)
+	interceptors := []grpc.UnaryServerInterceptor{
+		grpcapi.RecoveryInterceptor(),         // Recover from panics
+		grpcapi.CorrelationIDInterceptor(),    // Add correlation ID
+		grpcapi.TracingInterceptor(),          // Start server span
+		grpcapi.ErrorReportingInterceptor(),   // Report panics/internal errors
+		grpcapi.LoggingInterceptor(),          // Log requests
+		grpcapi.AuthInterceptor(pool, jwtCfg), // Validate JWT
+		grpcapi.SessionInterceptor(),          // Validate session
+		grpcapi.EpochInterceptor(pool),        // Validate epoch
+		grpcapi.AnalyticsInterceptor(srv.Analytics), // Count sync payload bytes
+	}
 	grpcServerInstance = grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpcapi.RecoveryInterceptor(),         // Recover from panics
-			grpcapi.CorrelationIDInterceptor(),    // Add correlation ID
-			grpcapi.LoggingInterceptor(),          // Log requests
-			grpcapi.AuthInterceptor(pool, jwtCfg), // Validate JWT
-			grpcapi.SessionInterceptor(),          // Validate session
-			grpcapi.EpochInterceptor(pool),        // Validate epoch
-		),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.StreamInterceptor(grpcapi.StreamFromUnary(interceptors...)), // Same checks when a PushStream opens
 	)
 
 	// Create main gRPC server with all services
@@ -49,6 +53,8 @@ func startGRPCServer(pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg
 		srv.TaskListSvc,
 		srv.TaskListCategorySvc,
 	)
+	grpcApiServer.Analytics = srv.Analytics
+	grpcApiServer.Notify = srv.Notify
 
 	// Register core sync service (sessions, info, wipe, state)
 	syncv1.RegisterSyncServiceServer(grpcServerInstance, grpcApiServer)
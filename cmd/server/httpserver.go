@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+)
+
+// newHTTPServer builds the REST listener
+// HTTP/1.1 keep-alive is always on; HTTP/2 is negotiated over TLS and, with
+// H2C, also accepted in clear text from a proxy that speaks it upstream.
+func newHTTPServer(c config.HTTPConfig, handler http.Handler) *http.Server {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(c.H2C)
+
+	return &http.Server{
+		Addr:         c.Addr,
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  c.IdleTimeout,
+		Protocols:    &protocols,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams: c.MaxConcurrentStreams,
+			SendPingTimeout:      c.PingInterval,
+		},
+	}
+}
+
+// serveHTTP runs srv until it is shut down, over TLS when a certificate is configured
+func serveHTTP(srv *http.Server, c config.HTTPConfig) error {
+	if c.TLSCertFile != "" {
+		return srv.ListenAndServeTLS(c.TLSCertFile, c.TLSKeyFile)
+	}
+	return srv.ListenAndServe()
+}
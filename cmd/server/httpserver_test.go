@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	c := config.Default().HTTP
+	srv := newHTTPServer(c, http.NotFoundHandler())
+	if !srv.Protocols.HTTP1() || !srv.Protocols.HTTP2() || srv.Protocols.UnencryptedHTTP2() {
+		t.Errorf("default protocols = %v, want HTTP/1 and HTTP/2 over TLS only", srv.Protocols)
+	}
+	if srv.IdleTimeout != 120*time.Second || srv.HTTP2.MaxConcurrentStreams != 250 {
+		t.Errorf("idle timeout %s, streams %d", srv.IdleTimeout, srv.HTTP2.MaxConcurrentStreams)
+	}
+
+	c.H2C, c.PingInterval = true, 30*time.Second
+	srv = newHTTPServer(c, http.NotFoundHandler())
+	if !srv.Protocols.UnencryptedHTTP2() || srv.HTTP2.SendPingTimeout != 30*time.Second {
+		t.Errorf("h2c protocols = %v, ping %s", srv.Protocols, srv.HTTP2.SendPingTimeout)
+	}
+}
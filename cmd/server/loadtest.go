@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/google/uuid"
+)
+
+func init() {
+	register("loadtest", "Drive a push/pull mix against a server and report latency percentiles", runLoadtest)
+}
+
+// loadEntities are the entities loadtest can push without parents
+var loadEntities = map[string]func(s *loadWorker) map[string]any{
+	"notes": func(s *loadWorker) map[string]any {
+		return map[string]any{"title": "Load test note", "content": s.body}
+	},
+	"tasks": func(s *loadWorker) map[string]any {
+		return map[string]any{"title": "Load test task", "description": s.body, "status": "todo"}
+	},
+	"task_lists": func(s *loadWorker) map[string]any {
+		return map[string]any{"name": "Load test list"}
+	},
+	"chats": func(s *loadWorker) map[string]any {
+		return map[string]any{"title": "Load test chat", "summary": s.body}
+	},
+}
+
+// runLoadtest implements: toolbridge-api loadtest --target URL [flags]
+func runLoadtest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	target := fs.String("target", "http://localhost:8081", "server base URL")
+	users := fs.Int("users", 10, "concurrent virtual users (one session each)")
+	duration := fs.Duration("duration", 30*time.Second, "test duration")
+	maxRequests := fs.Int("requests", 0, "stop after this many requests in total (0 = until --duration)")
+	pushRatio := fs.Float64("push-ratio", 0.3, "fraction of requests that are pushes (rest are pulls)")
+	entities := fs.String("entities", "notes,tasks", "comma-separated entities ("+strings.Join(sortedKeys(loadEntities), ",")+")")
+	batch := fs.Int("batch", 10, "items per push")
+	pullLimit := fs.Int("pull-limit", 100, "page size per pull")
+	payloadBytes := fs.Int("payload-bytes", 512, "approximate body size of pushed items")
+	token := fs.String("token", "", "bearer token shared by all virtual users (default: X-Debug-Sub per user, dev mode only)")
+	prefix := fs.String("prefix", "loadtest-user", "X-Debug-Sub prefix; virtual users are <prefix>-1..N")
+	tenant := fs.String("tenant", env("DEFAULT_TENANT_ID", "tenant_thinkpen_b2c"), "X-TB-Tenant-ID header")
+	retries := fs.Int("max-retries", 3, "retries per request on 429 (honoring Retry-After)")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var ents []string
+	for _, e := range strings.Split(*entities, ",") {
+		e = strings.TrimSpace(e)
+		if _, ok := loadEntities[e]; !ok {
+			return fmt.Errorf("unsupported entity %q", e)
+		}
+		ents = append(ents, e)
+	}
+	if *users < 1 || *batch < 1 || *pushRatio < 0 || *pushRatio > 1 {
+		return fmt.Errorf("--users and --batch must be at least 1 and --push-ratio within [0,1]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	stats := newLoadStats()
+	var budget *int64
+	if *maxRequests > 0 {
+		n := int64(*maxRequests)
+		budget = &n
+	}
+	var budgetMu sync.Mutex
+	take := func() bool {
+		if budget == nil {
+			return true
+		}
+		budgetMu.Lock()
+		defer budgetMu.Unlock()
+		if *budget <= 0 {
+			return false
+		}
+		*budget--
+		return true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 1; i <= *users; i++ {
+		c := syncclient.New(*target)
+		c.Token = *token
+		c.Subject = fmt.Sprintf("%s-%d", *prefix, i)
+		c.Tenant = *tenant
+		c.MaxRetries = *retries
+		c.OnRetry = func(time.Duration) { stats.retry() }
+
+		w := &loadWorker{
+			client:    c,
+			stats:     stats,
+			rng:       rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))),
+			entities:  ents,
+			pushRatio: *pushRatio,
+			batch:     *batch,
+			pullLimit: *pullLimit,
+			body:      strings.Repeat("x", *payloadBytes),
+			cursors:   make(map[string]string),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx, take)
+		}()
+	}
+	wg.Wait()
+
+	report := stats.report(time.Since(start))
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	report.print()
+	return nil
+}
+
+// loadWorker is one virtual user
+type loadWorker struct {
+	client    *syncclient.Client
+	stats     *loadStats
+	rng       *rand.Rand
+	entities  []string
+	pushRatio float64
+	batch     int
+	pullLimit int
+	body      string
+	cursors   map[string]string // Entity -> pull cursor (full resync loop)
+}
+
+func (w *loadWorker) run(ctx context.Context, take func() bool) {
+	t0 := time.Now()
+	err := w.client.BeginSession(ctx)
+	w.stats.observe("session", time.Since(t0), err)
+	if err != nil {
+		return
+	}
+
+	for ctx.Err() == nil && take() {
+		entity := w.entities[w.rng.Intn(len(w.entities))]
+		if w.rng.Float64() < w.pushRatio {
+			w.push(ctx, entity)
+		} else {
+			w.pull(ctx, entity)
+		}
+	}
+}
+
+func (w *loadWorker) push(ctx context.Context, entity string) {
+	items := make([]map[string]any, w.batch)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for i := range items {
+		item := loadEntities[entity](w)
+		item["uid"] = uuid.NewString()
+		item["updatedTs"] = now
+		item["sync"] = map[string]any{"version": float64(1)}
+		items[i] = item
+	}
+
+	t0 := time.Now()
+	acks, err := w.client.Push(ctx, entity, items)
+	if err == nil {
+		for _, a := range acks {
+			if a.Error != "" {
+				err = fmt.Errorf("item rejected: %s", a.Error)
+				break
+			}
+		}
+	}
+	w.stats.observe("push", time.Since(t0), err)
+	if errors.Is(err, syncclient.ErrEpochMismatch) {
+		w.cursors = make(map[string]string)
+	}
+}
+
+func (w *loadWorker) pull(ctx context.Context, entity string) {
+	t0 := time.Now()
+	resp, err := w.client.Pull(ctx, entity, w.cursors[entity], w.pullLimit)
+	w.stats.observe("pull", time.Since(t0), err)
+	switch {
+	case errors.Is(err, syncclient.ErrEpochMismatch):
+		w.cursors = make(map[string]string)
+	case err == nil && resp.HasMore:
+		w.cursors[entity] = *resp.NextCursor
+	case err == nil:
+		delete(w.cursors, entity) // Caught up: start the next pull from the beginning
+	}
+}
+
+// loadStats collects latencies and error kinds per operation
+type loadStats struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]map[string]int
+	retries   int
+}
+
+func newLoadStats() *loadStats {
+	return &loadStats{latencies: map[string][]time.Duration{}, errors: map[string]map[string]int{}}
+}
+
+func (s *loadStats) retry() {
+	s.mu.Lock()
+	s.retries++
+	s.mu.Unlock()
+}
+
+func (s *loadStats) observe(op string, d time.Duration, err error) {
+	// Requests cut short by the end of the run are not counted
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies[op] = append(s.latencies[op], d)
+	if err != nil {
+		if s.errors[op] == nil {
+			s.errors[op] = map[string]int{}
+		}
+		s.errors[op][errorKind(err)]++
+	}
+}
+
+// errorKind buckets errors for the report (HTTP status, epoch mismatch, network, item rejection)
+func errorKind(err error) string {
+	var se *syncclient.StatusError
+	var ne net.Error
+	switch {
+	case errors.Is(err, syncclient.ErrEpochMismatch):
+		return "epoch_mismatch"
+	case errors.As(err, &se):
+		if se.Status == 429 {
+			return "rate_limited"
+		}
+		return "http_" + strconv.Itoa(se.Status)
+	case errors.As(err, &ne):
+		return "network"
+	case strings.HasPrefix(err.Error(), "item rejected"):
+		return "item_rejected"
+	default:
+		return "other"
+	}
+}
+
+// loadOpReport summarizes one operation
+type loadOpReport struct {
+	Requests int            `json:"requests"`
+	Errors   map[string]int `json:"errors,omitempty"`
+	P50Ms    float64        `json:"p50Ms"`
+	P90Ms    float64        `json:"p90Ms"`
+	P99Ms    float64        `json:"p99Ms"`
+	MaxMs    float64        `json:"maxMs"`
+}
+
+// loadReport is the loadtest result
+type loadReport struct {
+	DurationSec float64                 `json:"durationSec"`
+	Requests    int                     `json:"requests"`
+	RPS         float64                 `json:"rps"`
+	Retries429  int                     `json:"retries429"` // Rate-limited attempts that were retried
+	Operations  map[string]loadOpReport `json:"operations"`
+}
+
+func (s *loadStats) report(elapsed time.Duration) loadReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := loadReport{
+		DurationSec: elapsed.Seconds(),
+		Retries429:  s.retries,
+		Operations:  map[string]loadOpReport{},
+	}
+	for op, lat := range s.latencies {
+		sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+		r.Operations[op] = loadOpReport{
+			Requests: len(lat),
+			Errors:   s.errors[op],
+			P50Ms:    percentileMs(lat, 0.50),
+			P90Ms:    percentileMs(lat, 0.90),
+			P99Ms:    percentileMs(lat, 0.99),
+			MaxMs:    percentileMs(lat, 1),
+		}
+		r.Requests += len(lat)
+	}
+	if elapsed > 0 {
+		r.RPS = float64(r.Requests) / elapsed.Seconds()
+	}
+	return r
+}
+
+func (r loadReport) print() {
+	fmt.Printf("Duration %.1fs, %d requests (%.1f req/s), %d rate-limit retries\n\n", r.DurationSec, r.Requests, r.RPS, r.Retries429)
+	fmt.Printf("%-8s %8s %8s %9s %9s %9s %9s\n", "op", "requests", "errors", "p50(ms)", "p90(ms)", "p99(ms)", "max(ms)")
+	for _, op := range sortedKeys(r.Operations) {
+		o := r.Operations[op]
+		n := 0
+		for _, c := range o.Errors {
+			n += c
+		}
+		fmt.Printf("%-8s %8d %8d %9.1f %9.1f %9.1f %9.1f\n", op, o.Requests, n, o.P50Ms, o.P90Ms, o.P99Ms, o.MaxMs)
+	}
+	for _, op := range sortedKeys(r.Operations) {
+		o := r.Operations[op]
+		for _, kind := range sortedKeys(o.Errors) {
+			fmt.Printf("  %s %s: %d\n", op, kind, o.Errors[kind])
+		}
+	}
+}
+
+// percentileMs returns the nearest-rank percentile of sorted latencies in milliseconds
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	i = max(0, min(i, len(sorted)-1))
+	return float64(sorted[i].Microseconds()) / 1000
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,20 +2,29 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/config"
 	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/eventstream"
 	"github.com/erauner12/toolbridge-api/internal/httpapi"
-	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/jobs"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/erauner12/toolbridge-api/migrations"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/workos/workos-go/v6/pkg/usermanagement"
 )
 
 func env(k, def string) string {
@@ -26,144 +35,161 @@ func env(k, def string) string {
 }
 
 func main() {
+	// Subcommands (backup, restore, ...) exit when done; otherwise the arguments are server flags
+	runCommand(os.Args[1:])
+	flags := parseServerFlags(os.Args[1:])
+
 	// Configure structured logging
 	zerolog.TimeFieldFormat = time.RFC3339Nano
 	log.Logger = log.With().Str("service", "toolbridge-api").Logger()
 
-	// Pretty logging for local dev (only when explicitly set to "dev")
-	if env("ENV", "") == "dev" {
+	// Configuration: defaults < config file (--config or CONFIG_FILE) < environment < flags
+	cfg, err := flags.load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+	// ENV selects the profile (dev, staging, prod): dev mode, strictness and the
+	// LOG_FORMAT / SESSION_STORE defaults
+	prof := cfg.Profile()
+	isDevMode := prof.DevMode
+
+	// Pretty logging (the dev profile default)
+	if cfg.LogFormat == config.LogFormatConsole {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"})
 	}
 
 	ctx := context.Background()
 
-	// Database connection
-	pgURL := env("DATABASE_URL", "")
-	if pgURL == "" {
-		log.Fatal().Msg("DATABASE_URL is required")
+	// Validate the whole configuration before starting anything: every problem is
+	// logged with a remediation hint and any error is fatal, so a bad deploy fails
+	// once with the full list (same rules as `toolbridge-api check-config`)
+	if report := checkConfig(ctx, cfg, false, false); !report.logIssues() {
+		log.Fatal().
+			Int("errors", report.Errors).
+			Int("warnings", report.Warnings).
+			Msg("invalid configuration; run `toolbridge-api check-config` for the full report")
 	}
 
-	pool, err := db.Open(ctx, pgURL)
+	// Log level (can be changed at runtime via PUT /admin/log-level or SIGUSR1)
+	if err := logging.Init(cfg.LogLevel); err != nil {
+		log.Fatal().Err(err).Msg("invalid LOG_LEVEL")
+	}
+	log.Info().Str("version", version).Str("env", cfg.Env).Str("profile", prof.Name).Msg("starting toolbridge-api")
+
+	// Tracing (OTLP exporter is configured via the standard OTEL_EXPORTER_OTLP_* env vars)
+	// Must run before db.Open so the pool picks up the query tracer
+	shutdownTracing, err := telemetry.Init(ctx, telemetry.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		ServiceName: cfg.Tracing.ServiceName,
+		Environment: cfg.Env,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to connect to postgres")
+		log.Fatal().Err(err).Msg("failed to initialize tracing")
 	}
-	defer pool.Close()
 
-	// JWT configuration
-	// DevMode ONLY enabled when ENV is explicitly set to "dev" (allows X-Debug-Sub header)
-	// Secure by default: if ENV is unset or misspelled, DevMode stays false
-	jwtSecret := env("JWT_HS256_SECRET", "dev-secret-change-in-production")
-	isDevMode := env("ENV", "") == "dev"
-
-	// Generic OIDC provider configuration for production RS256 tokens
-	// Supports any OIDC-compliant provider (WorkOS AuthKit, Okta, Keycloak, etc.)
-	jwtIssuer := env("JWT_ISSUER", "")
-	jwksURL := env("JWT_JWKS_URL", "")
-	jwtAudience := env("JWT_AUDIENCE", "")
-
-	// Security validation: JWKS URL and issuer must be set together
-	// If only JWKS is set, we'd accept tokens from any issuer using those keys (security risk)
-	// If only issuer is set, we'd have no JWKS to validate signatures against
-	if (jwksURL != "" && jwtIssuer == "") || (jwksURL == "" && jwtIssuer != "") {
-		log.Fatal().
-			Str("issuer", jwtIssuer).
-			Str("jwks_url", jwksURL).
-			Msg("FATAL: JWT_ISSUER and JWT_JWKS_URL must both be set or both be empty. " +
-				"Setting only JWKS would accept tokens from any issuer. " +
-				"Setting only issuer would have no JWKS to validate signatures.")
+	// Error reporting to a Sentry-compatible DSN (disabled when SENTRY_DSN is unset)
+	shutdownErrReport, err := errreport.Init(errreport.Config{
+		DSN:         cfg.Sentry.DSN,
+		Environment: cfg.Sentry.Environment,
+		Release:     cfg.Sentry.Release,
+		SampleRate:  cfg.Sentry.SampleRate,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize error reporting")
 	}
-
-	// Additional accepted audiences (for MCP OAuth tokens, token exchange, etc.)
-	// These are in addition to the primary JWT_AUDIENCE
-	//
-	// WorkOS AuthKit with DCR: Leave empty (MCP_OAUTH_AUDIENCE="") to skip audience validation
-	// Static registration: MUST equal the `resource` value from the MCP server's
-	// /.well-known/oauth-protected-resource metadata endpoint (path-based discovery).
-	// Example: https://toolbridge-mcp-staging.fly.dev/mcp
-	acceptedAudiences := []string{}
-	if mcpAudience := strings.TrimSpace(env("MCP_OAUTH_AUDIENCE", "")); mcpAudience != "" {
-		acceptedAudiences = append(acceptedAudiences, mcpAudience)
-		log.Info().Str("mcp_audience", mcpAudience).Msg("MCP OAuth audience accepted")
+	if errreport.Enabled() {
+		log.Info().Msg("error reporting enabled")
 	}
 
-	// Backend RS256 signing configuration (optional)
-	// When configured, backend tokens (from token exchange) are signed with RS256 instead of HS256
-	backendRSAPrivateKeyPEM := env("JWT_BACKEND_RS256_PRIVATE_KEY", "")
-	backendKeyID := env("JWT_BACKEND_KEY_ID", "")
-
-	// Validate backend RS256 config: key ID must be set when private key is configured
-	if backendRSAPrivateKeyPEM != "" && backendKeyID == "" {
-		log.Fatal().Msg("FATAL: JWT_BACKEND_KEY_ID must be set when JWT_BACKEND_RS256_PRIVATE_KEY is configured")
+	// Database connection
+	pool, err := db.OpenWithOptions(ctx, cfg.Database.URL, db.PoolOptions{
+		StatementCache:     cfg.Database.StatementCache,
+		StatementCacheSize: cfg.Database.StatementCacheSize,
+		PrepareHot:         cfg.Database.PrepareHotQueries,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to postgres")
 	}
+	defer pool.Close()
 
-	jwtCfg := auth.JWTCfg{
-		HS256Secret:       jwtSecret,
-		DevMode:           isDevMode,
-		Env:               env("ENV", ""), // Used to guard DevMode in production (log.Fatal if DevMode && Env=="prod")
-		Issuer:            jwtIssuer,
-		JWKSURL:           jwksURL,
-		Audience:          jwtAudience,
-		AcceptedAudiences: acceptedAudiences,
-		TenantClaim:       env("TENANT_CLAIM", ""),
-
-		BackendRSAPrivateKeyPEM: backendRSAPrivateKeyPEM,
-		BackendKeyID:            backendKeyID,
+	// Optional startup migrations (same runner as `toolbridge-api migrate up`)
+	if cfg.Database.MigrateOnStart {
+		ran, err := db.NewMigrator(pool, migrations.FS).Up(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("startup migrations failed")
+		}
+		log.Info().Int("applied", len(ran)).Msg("startup migrations complete")
+		if len(ran) > 0 {
+			// Reconnect so connections opened before the schema existed prepare the hot statements
+			pool.Reset()
+		}
 	}
 
-	// WorkOS client setup (optional - for tenant resolution endpoint)
-	// Initialize WorkOS client with API key from environment
-	// This enables the /v1/auth/tenant endpoint for automatic tenant resolution
-	var workosClient *usermanagement.Client
-	workosAPIKey := env("WORKOS_API_KEY", "")
-	if workosAPIKey != "" {
-		workosClient = usermanagement.NewClient(workosAPIKey)
-		log.Info().Msg("WorkOS client initialized for tenant resolution")
-	} else {
-		log.Info().Msg("WorkOS tenant resolution disabled (WORKOS_API_KEY not set)")
+	// Sync sessions: shared in Postgres (staging/prod default) or in this process (dev)
+	if cfg.Session.Store == config.SessionStorePostgres {
+		session.SetStore(session.NewPostgresStore(pool))
 	}
-
-	// Default tenant ID for B2C users (users without organization memberships)
-	// Pattern 3 (Hybrid): B2C users get this default tenant, B2B users get their org ID
-	defaultTenantID := env("DEFAULT_TENANT_ID", "tenant_thinkpen_b2c")
-	log.Info().Str("default_tenant_id", defaultTenantID).Msg("Default B2C tenant configured")
-
-	// Initialize tenant authorization cache (5-minute TTL with background cleanup)
-	tenantAuthCache := auth.NewTenantAuthCache()
-	log.Info().Msg("Tenant authorization cache initialized (5-minute TTL)")
-
-	// HTTP server setup
-	srv := &httpapi.Server{
-		DB:                  pool,
-		RateLimitConfig:     httpapi.DefaultRateLimitConfig,
-		AuthRateLimitConfig: httpapi.DefaultAuthRateLimitConfig, // Stricter limits for auth endpoints
-		JWTCfg:              jwtCfg,
-		WorkOSClient:    workosClient,
-		DefaultTenantID: defaultTenantID,
-		TenantAuthCache: tenantAuthCache,
-		// Initialize services
-		NoteSvc:             syncservice.NewNoteService(pool),
-		TaskSvc:             syncservice.NewTaskService(pool),
-		CommentSvc:          syncservice.NewCommentService(pool),
-		ChatSvc:             syncservice.NewChatService(pool),
-		ChatMessageSvc:      syncservice.NewChatMessageService(pool),
-		TaskListSvc:         syncservice.NewTaskListService(pool),
-		TaskListCategorySvc: syncservice.NewTaskListCategoryService(pool),
+	session.SetValidation(cfg.Session.Validation)
+	log.Info().Str("store", cfg.Session.Store).Str("validation", session.GetValidation()).Msg("sync session store configured")
+
+	// HTTP server with every service wired from the configuration (JWT settings,
+	// WorkOS tenant resolution, email, webhooks, Slack, Zapier, sync services)
+	// DevMode ONLY enabled by the dev profile (allows X-Debug-Sub header)
+	// Secure by default: if ENV is unset or misspelled, the staging profile applies
+	// Outside dev mode checkConfig rejects a missing or default HS256 secret, even with
+	// upstream OIDC: the middleware still accepts HS256 tokens, so the default would let
+	// anyone forge them.
+	srv := httpapi.NewServer(pool, cfg)
+	jwtCfg := srv.JWTCfg
+	log.Info().Str("default_tenant_id", srv.DefaultTenantID).Msg("Default B2C tenant configured")
+	if len(jwtCfg.AcceptedAudiences) > 0 {
+		log.Info().Strs("mcp_audience", jwtCfg.AcceptedAudiences).Msg("MCP OAuth audience accepted")
 	}
 
-	// Security validation: Always require a strong HS256 secret in production mode
-	// This provides defense-in-depth even when upstream OIDC is configured, since the middleware
-	// still accepts HS256 tokens. Without this check, an attacker could forge HS256 tokens
-	// using the default secret and bypass upstream validation entirely.
-	if !isDevMode {
-		if jwtSecret == "" || jwtSecret == "dev-secret-change-in-production" {
-			log.Fatal().
-				Str("secret", jwtSecret).
-				Bool("oidc_enabled", jwtIssuer != "" && jwksURL != "").
-				Msg("FATAL: Cannot start in production mode with default or missing JWT_HS256_SECRET. " +
-					"Even with upstream OIDC configured, a strong HS256 secret is required for defense-in-depth " +
-					"since the middleware still accepts HS256 tokens. " +
-					"Set JWT_HS256_SECRET to a secure random value (e.g., openssl rand -base64 32)")
+	// Secrets loaded by reference (NAME_FILE, file:, vault:) are re-fetched every
+	// SECRETS_REFRESH_INTERVAL; the backend signing key rotates in place, other
+	// secrets are read at startup only
+	secretWatcher := cfg.SecretWatcher()
+	for _, name := range secretWatcher.Names() {
+		if name == "JWT_BACKEND_RS256_PRIVATE_KEY" {
+			continue
 		}
+		secretWatcher.OnRotate(name, func(string) {
+			log.Warn().Str("secret", name).Msg("secret rotated; restart to apply")
+		})
+	}
+	secretWatcher.OnRotate("JWT_BACKEND_RS256_PRIVATE_KEY", func(pem string) {
+		rotated := jwtCfg
+		rotated.BackendRSAPrivateKeyPEM = pem
+		if err := auth.InitBackendSigner(rotated); err != nil {
+			log.Error().Err(err).Msg("rotated backend RS256 key rejected; still signing with the previous key")
+		}
+	})
+
+	// Log level, rate limits, CORS origins, feature flags and maintenance mode
+	// are re-read on SIGHUP and POST /admin/reload (NewServer applied them at
+	// startup); other changes need a restart
+	var reloadMu sync.Mutex
+	srv.Reload = func(ctx context.Context) error {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		next, err := flags.load()
+		if err != nil {
+			return err
+		}
+		if err := logging.Init(next.LogLevel); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+		srv.ApplySettings(httpapi.SettingsFromConfig(next))
+		if changed := config.RestartRequired(cfg, next); len(changed) > 0 {
+			log.Warn().Strs("sections", changed).Msg("config reload: changes to these sections take effect after a restart")
+		}
+		log.Info().
+			Str("log_level", logging.Level().String()).
+			Bool("maintenance", next.Maintenance.Enabled).
+			Msg("configuration reloaded")
+		return nil
 	}
 
 	// Initialize upstream IdP JWKS cache (shared by both HTTP and gRPC)
@@ -179,22 +205,22 @@ func main() {
 	}
 
 	// Log backend signing mode
-	if backendRSAPrivateKeyPEM != "" {
+	if jwtCfg.BackendRSAPrivateKeyPEM != "" {
 		log.Info().
-			Str("backend_kid", backendKeyID).
+			Str("backend_kid", jwtCfg.BackendKeyID).
 			Msg("Backend RS256 signing enabled for token exchange")
 	}
 
 	// Log authentication mode
-	if jwtIssuer != "" && jwksURL != "" {
+	if jwtCfg.Issuer != "" && jwtCfg.JWKSURL != "" {
 		log.Info().
-			Str("issuer", jwtIssuer).
-			Str("jwks_url", jwksURL).
-			Str("audience", jwtAudience).
+			Str("issuer", jwtCfg.Issuer).
+			Str("jwks_url", jwtCfg.JWKSURL).
+			Str("audience", jwtCfg.Audience).
 			Msg("Upstream OIDC RS256 authentication enabled")
 
 		// Security warning if audience is not configured
-		if jwtAudience == "" && len(acceptedAudiences) == 0 {
+		if jwtCfg.Audience == "" && len(jwtCfg.AcceptedAudiences) == 0 {
 			log.Warn().
 				Msg("SECURITY WARNING: Upstream OIDC configured without audience validation. " +
 					"This accepts tokens from ANY client in the issuer's tenant. " +
@@ -202,7 +228,7 @@ func main() {
 		}
 
 		// Informational warning if MCP audience might be needed
-		if len(acceptedAudiences) == 0 && jwtAudience != "" {
+		if len(jwtCfg.AcceptedAudiences) == 0 && jwtCfg.Audience != "" {
 			log.Info().
 				Msg("MCP_OAUTH_AUDIENCE not set; MCP-issued tokens will only be accepted " +
 					"if their audience matches JWT_AUDIENCE. " +
@@ -213,19 +239,52 @@ func main() {
 		log.Info().Msg("HS256-only authentication enabled (no upstream OIDC configured)")
 	}
 
-	httpAddr := env("HTTP_ADDR", ":8080")
-	httpServer := &http.Server{
-		Addr:         httpAddr,
-		Handler:      srv.Routes(jwtCfg),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	httpAddr := cfg.HTTP.Addr
+	httpServer := newHTTPServer(cfg.HTTP, srv.Routes(jwtCfg))
+
+	// Background jobs: sync analytics rollup, account export and import workers,
+	// outbox dispatch, webhook delivery, email notifications, integrity check, trash purge
+	// and the change feed listener
+	// (stopped after servers drain; analytics does a final flush)
+	analyticsInterval := cfg.Jobs.AnalyticsFlushInterval
+	integrityInterval := cfg.Jobs.IntegrityCheckInterval
+	leaderRetry := cfg.Jobs.LeaderRetryInterval
+	// Analytics buffers are per replica and exports/imports/webhook deliveries/emails are claimed with SKIP LOCKED,
+	// so only the outbox dispatcher (publishes in outbox order), the integrity check
+	// and the trash purge need a single leader across replicas.
+	// Optional change event stream (NATS JetStream or Kafka), fed from the outbox
+	stream, err := eventstream.New(eventstream.Config{
+		Backend: cfg.EventStream.Backend,
+		URL:     cfg.EventStream.URL,
+		Subject: cfg.EventStream.Subject,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect event stream")
+	}
+	dispatcher := outbox.NewDispatcher(pool, srv.Webhooks, srv.Slack, srv.Zapier, changefeed.Publisher{})
+	if stream != nil {
+		dispatcher.Publishers = append(dispatcher.Publishers, stream)
+		log.Info().Str("backend", cfg.EventStream.Backend).Msg("change event stream enabled")
 	}
+	scheduler := jobs.NewScheduler(pool)
+	scheduler.RetryInterval = leaderRetry
+	scheduler.Add("analytics", false, func(ctx context.Context) { srv.Analytics.Run(ctx, analyticsInterval) })
+	scheduler.Add("exports", false, func(ctx context.Context) { srv.Exports.Run(ctx, 10*time.Second) })
+	scheduler.Add("imports", false, func(ctx context.Context) { srv.Imports.Run(ctx, 5*time.Second) })
+	scheduler.Add("webhooks", false, func(ctx context.Context) { srv.Webhooks.Run(ctx, 5*time.Second) })
+	scheduler.Add("notifications", false, func(ctx context.Context) { srv.Notify.Run(ctx, time.Minute) })
+	scheduler.Add("outbox", true, func(ctx context.Context) { dispatcher.Run(ctx, time.Second) })
+	scheduler.Add("integrity", true, func(ctx context.Context) { srv.Integrity.Run(ctx, integrityInterval) })
+	scheduler.Add("trash", true, func(ctx context.Context) { srv.Trash.Run(ctx, time.Hour) })
+	scheduler.Add("changefeed", false, func(ctx context.Context) { srv.Changes.Run(ctx, 5*time.Second) })
+	scheduler.Add("secrets", false, func(ctx context.Context) { secretWatcher.Run(ctx, cfg.Secrets.RefreshInterval) })
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	scheduler.Start(jobsCtx)
 
 	// Start server in goroutine
 	go func() {
-		log.Info().Str("addr", httpAddr).Msg("starting HTTP server")
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info().Str("addr", httpAddr).Bool("tls", cfg.HTTP.TLSCertFile != "").Bool("h2c", cfg.HTTP.H2C).Msg("starting HTTP server")
+		if err := serveHTTP(httpServer, cfg.HTTP); err != nil && err != http.ErrServerClosed {
 			log.Fatal().Err(err).Msg("HTTP server failed")
 		}
 	}()
@@ -234,9 +293,29 @@ func main() {
 	// gRPC Server Setup (Conditionally compiled with -tags grpc)
 	// ===================================================================
 	// gRPC server is started in grpc_setup.go when building with -tags grpc
-	startGRPCServer(pool, srv, jwtCfg) // No-op without grpc tag
+	startGRPCServer(cfg.GRPC.Addr, pool, srv, jwtCfg) // No-op without grpc tag
 	// ===================================================================
 
+	// SIGUSR1 toggles debug logging without a restart (kill -USR1 <pid>)
+	usr1Chan := make(chan os.Signal, 1)
+	signal.Notify(usr1Chan, syscall.SIGUSR1)
+	go func() {
+		for range usr1Chan {
+			logging.ToggleDebug()
+		}
+	}()
+
+	// SIGHUP reloads runtime settings without dropping connections or sessions (kill -HUP <pid>)
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			if err := srv.Reload(ctx); err != nil {
+				log.Error().Err(err).Msg("config reload failed; keeping current settings")
+			}
+		}
+	}()
+
 	// Graceful shutdown on SIGINT/SIGTERM
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -255,5 +334,18 @@ func main() {
 	// Shutdown gRPC server (no-op without grpc tag)
 	stopGRPCServer()
 
+	// Stop background jobs (flushes pending analytics, aborts in-flight exports)
+	stopJobs()
+	scheduler.Wait()
+	if err := stream.Close(); err != nil {
+		log.Error().Err(err).Msg("event stream close error")
+	}
+
+	// Flush buffered spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("tracing shutdown error")
+	}
+	_ = shutdownErrReport(shutdownCtx) // Flush queued error events
+
 	log.Info().Msg("server stopped")
 }
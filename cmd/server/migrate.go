@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/migrations"
+)
+
+func init() {
+	register("migrate", "Apply, revert, inspect or create schema migrations (up|down|status|create)", runMigrate)
+}
+
+const migrateUsage = `Usage:
+  toolbridge-api migrate up                 Apply all pending migrations
+  toolbridge-api migrate down [--steps N]   Revert the last N migrations (default 1; needs .down.sql files)
+  toolbridge-api migrate status [--json]    List migrations and whether they are applied
+  toolbridge-api migrate create <name> [--dir migrations]
+                                            Create the next NNNN_<name>.sql and .down.sql pair
+
+up, down and status use the migrations embedded in this binary and DATABASE_URL.`
+
+// runMigrate implements: toolbridge-api migrate <up|down|status|create>
+func runMigrate(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing migrate command")
+		}
+		return flag.ErrHelp
+	}
+
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("migrate "+sub, flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to revert (down)")
+	jsonOut := fs.Bool("json", false, "print status as JSON (status)")
+	dir := fs.String("dir", "migrations", "migrations source directory (create)")
+
+	if sub == "create" {
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			return fmt.Errorf("usage: migrate create <name> [--dir migrations]")
+		}
+		name := args[0]
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return createMigration(*dir, name)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	pool, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	m := db.NewMigrator(pool, migrations.FS)
+
+	switch sub {
+	case "up":
+		ran, err := m.Up(ctx)
+		for _, name := range ran {
+			fmt.Printf("applied  %s\n", name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ran) == 0 {
+			fmt.Println("No pending migrations - database is up to date")
+		}
+		return nil
+
+	case "down":
+		if *steps < 1 {
+			return fmt.Errorf("--steps must be at least 1")
+		}
+		ran, err := m.Down(ctx, *steps)
+		for _, name := range ran {
+			fmt.Printf("reverted %s\n", name)
+		}
+		return err
+
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(status)
+		}
+		pending := 0
+		for _, s := range status {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.UTC().Format("2006-01-02 15:04:05Z")
+			} else {
+				pending++
+			}
+			fmt.Printf("%-40s %s\n", s.Name, state)
+		}
+		fmt.Printf("\n%d migrations, %d pending\n", len(status), pending)
+		return nil
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", sub)
+	}
+}
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// createMigration writes the next numbered up/down pair into dir
+// New files are only picked up by the binary after a rebuild (they are embedded).
+func createMigration(dir, name string) error {
+	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+	if !migrationName.MatchString(name) {
+		return fmt.Errorf("migration name %q must contain only letters, digits and underscores", name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	next := 1
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if n, err := strconv.Atoi(prefix); ok && err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	up := filepath.Join(dir, base+".sql")
+	down := filepath.Join(dir, base+".down.sql")
+	files := map[string]string{
+		up:   "-- " + strings.ReplaceAll(name, "_", " ") + "\n\n",
+		down: "-- Revert " + base + ".sql\n\n",
+	}
+	for _, path := range []string{up, down} {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(files[path])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created  %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register("print-config", "Print the effective configuration as YAML (secrets redacted)", runPrintConfig)
+}
+
+// runPrintConfig implements: toolbridge-api print-config
+func runPrintConfig(args []string) error {
+	fs := flag.NewFlagSet("print-config", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Load(env("CONFIG_FILE", ""))
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -240,6 +240,7 @@ type PullResponse struct {
 	Upserts       []*structpb.Struct     `protobuf:"bytes,1,rep,name=upserts,proto3" json:"upserts,omitempty"`
 	Deletes       []*structpb.Struct     `protobuf:"bytes,2,rep,name=deletes,proto3" json:"deletes,omitempty"` // { "uid": "...", "deletedAt": "..." }
 	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"` // Pull again with next_cursor now; false = caught up
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -295,6 +296,13 @@ func (x *PullResponse) GetNextCursor() string {
 	return ""
 }
 
+func (x *PullResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
 type GetServerInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -1056,12 +1064,13 @@ const file_sync_v1_sync_proto_rawDesc = "" +
 	"\x05error\x18\x04 \x01(\tR\x05error\";\n" +
 	"\vPullRequest\x12\x16\n" +
 	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\x95\x01\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xb0\x01\n" +
 	"\fPullResponse\x121\n" +
 	"\aupserts\x18\x01 \x03(\v2\x17.google.protobuf.StructR\aupserts\x121\n" +
 	"\adeletes\x18\x02 \x03(\v2\x17.google.protobuf.StructR\adeletes\x12\x1f\n" +
 	"\vnext_cursor\x18\x03 \x01(\tR\n" +
-	"nextCursor\"\x16\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\"\x16\n" +
 	"\x14GetServerInfoRequest\"\xfd\x03\n" +
 	"\n" +
 	"ServerInfo\x12\x1f\n" +
@@ -1128,27 +1137,41 @@ const file_sync_v1_sync_proto_rawDesc = "" +
 	"\n" +
 	"EndSession\x12%.toolbridge.sync.v1.EndSessionRequest\x1a&.toolbridge.sync.v1.EndSessionResponse\"\x00\x12W\n" +
 	"\vWipeAccount\x12&.toolbridge.sync.v1.WipeAccountRequest\x1a\x1e.toolbridge.sync.v1.WipeResult\"\x00\x12\\\n" +
-	"\fGetSyncState\x12'.toolbridge.sync.v1.GetSyncStateRequest\x1a!.toolbridge.sync.v1.UserSyncState\"\x002\xab\x01\n" +
+	"\fGetSyncState\x12'.toolbridge.sync.v1.GetSyncStateRequest\x1a!.toolbridge.sync.v1.UserSyncState\"\x002\x82\x02\n" +
 	"\x0fNoteSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xab\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x82\x02\n" +
 	"\x0fTaskSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xae\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x85\x02\n" +
 	"\x12CommentSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xab\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x82\x02\n" +
 	"\x0fChatSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xb2\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x89\x02\n" +
 	"\x16ChatMessageSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xaf\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x86\x02\n" +
 	"\x13TaskListSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xb7\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x8e\x02\n" +
 	"\x1bTaskListCategorySyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12U\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00(\x010\x01\x12K\n" +
 	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x00B;Z9github.com/erauner12/toolbridge-api/gen/go/sync/v1;syncv1b\x06proto3"
 
 var (
@@ -1211,40 +1234,54 @@ var file_sync_v1_sync_proto_depIdxs = []int32{
 	15, // 18: toolbridge.sync.v1.SyncService.WipeAccount:input_type -> toolbridge.sync.v1.WipeAccountRequest
 	17, // 19: toolbridge.sync.v1.SyncService.GetSyncState:input_type -> toolbridge.sync.v1.GetSyncStateRequest
 	0,  // 20: toolbridge.sync.v1.NoteSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 21: toolbridge.sync.v1.NoteSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 22: toolbridge.sync.v1.TaskSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 23: toolbridge.sync.v1.TaskSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 24: toolbridge.sync.v1.CommentSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 25: toolbridge.sync.v1.CommentSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 26: toolbridge.sync.v1.ChatSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 27: toolbridge.sync.v1.ChatSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 28: toolbridge.sync.v1.ChatMessageSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 29: toolbridge.sync.v1.ChatMessageSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 30: toolbridge.sync.v1.TaskListSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 31: toolbridge.sync.v1.TaskListSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 32: toolbridge.sync.v1.TaskListCategorySyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 33: toolbridge.sync.v1.TaskListCategorySyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	6,  // 34: toolbridge.sync.v1.SyncService.GetServerInfo:output_type -> toolbridge.sync.v1.ServerInfo
-	12, // 35: toolbridge.sync.v1.SyncService.BeginSession:output_type -> toolbridge.sync.v1.SyncSession
-	14, // 36: toolbridge.sync.v1.SyncService.EndSession:output_type -> toolbridge.sync.v1.EndSessionResponse
-	16, // 37: toolbridge.sync.v1.SyncService.WipeAccount:output_type -> toolbridge.sync.v1.WipeResult
-	18, // 38: toolbridge.sync.v1.SyncService.GetSyncState:output_type -> toolbridge.sync.v1.UserSyncState
-	1,  // 39: toolbridge.sync.v1.NoteSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 40: toolbridge.sync.v1.NoteSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 41: toolbridge.sync.v1.TaskSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 42: toolbridge.sync.v1.TaskSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 43: toolbridge.sync.v1.CommentSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 44: toolbridge.sync.v1.CommentSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 45: toolbridge.sync.v1.ChatSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 46: toolbridge.sync.v1.ChatSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 47: toolbridge.sync.v1.ChatMessageSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 48: toolbridge.sync.v1.ChatMessageSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 49: toolbridge.sync.v1.TaskListSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 50: toolbridge.sync.v1.TaskListSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 51: toolbridge.sync.v1.TaskListCategorySyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 52: toolbridge.sync.v1.TaskListCategorySyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	34, // [34:53] is the sub-list for method output_type
-	15, // [15:34] is the sub-list for method input_type
+	0,  // 21: toolbridge.sync.v1.NoteSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 22: toolbridge.sync.v1.NoteSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 23: toolbridge.sync.v1.TaskSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 24: toolbridge.sync.v1.TaskSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 25: toolbridge.sync.v1.TaskSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 26: toolbridge.sync.v1.CommentSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 27: toolbridge.sync.v1.CommentSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 28: toolbridge.sync.v1.CommentSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 29: toolbridge.sync.v1.ChatSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 30: toolbridge.sync.v1.ChatSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 31: toolbridge.sync.v1.ChatSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 32: toolbridge.sync.v1.ChatMessageSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 33: toolbridge.sync.v1.ChatMessageSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 34: toolbridge.sync.v1.ChatMessageSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 35: toolbridge.sync.v1.TaskListSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 36: toolbridge.sync.v1.TaskListSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 37: toolbridge.sync.v1.TaskListSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 38: toolbridge.sync.v1.TaskListCategorySyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 39: toolbridge.sync.v1.TaskListCategorySyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 40: toolbridge.sync.v1.TaskListCategorySyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	6,  // 41: toolbridge.sync.v1.SyncService.GetServerInfo:output_type -> toolbridge.sync.v1.ServerInfo
+	12, // 42: toolbridge.sync.v1.SyncService.BeginSession:output_type -> toolbridge.sync.v1.SyncSession
+	14, // 43: toolbridge.sync.v1.SyncService.EndSession:output_type -> toolbridge.sync.v1.EndSessionResponse
+	16, // 44: toolbridge.sync.v1.SyncService.WipeAccount:output_type -> toolbridge.sync.v1.WipeResult
+	18, // 45: toolbridge.sync.v1.SyncService.GetSyncState:output_type -> toolbridge.sync.v1.UserSyncState
+	1,  // 46: toolbridge.sync.v1.NoteSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 47: toolbridge.sync.v1.NoteSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 48: toolbridge.sync.v1.NoteSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 49: toolbridge.sync.v1.TaskSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 50: toolbridge.sync.v1.TaskSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 51: toolbridge.sync.v1.TaskSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 52: toolbridge.sync.v1.CommentSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 53: toolbridge.sync.v1.CommentSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 54: toolbridge.sync.v1.CommentSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 55: toolbridge.sync.v1.ChatSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 56: toolbridge.sync.v1.ChatSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 57: toolbridge.sync.v1.ChatSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 58: toolbridge.sync.v1.ChatMessageSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 59: toolbridge.sync.v1.ChatMessageSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 60: toolbridge.sync.v1.ChatMessageSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 61: toolbridge.sync.v1.TaskListSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 62: toolbridge.sync.v1.TaskListSyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 63: toolbridge.sync.v1.TaskListSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 64: toolbridge.sync.v1.TaskListCategorySyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	1,  // 65: toolbridge.sync.v1.TaskListCategorySyncService.PushStream:output_type -> toolbridge.sync.v1.PushResponse
+	4,  // 66: toolbridge.sync.v1.TaskListCategorySyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	41, // [41:67] is the sub-list for method output_type
+	15, // [15:41] is the sub-list for method input_type
 	15, // [15:15] is the sub-list for extension type_name
 	15, // [15:15] is the sub-list for extension extendee
 	0,  // [0:15] is the sub-list for field type_name
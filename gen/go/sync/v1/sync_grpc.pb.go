@@ -291,8 +291,9 @@ var SyncService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	NoteSyncService_Push_FullMethodName = "/toolbridge.sync.v1.NoteSyncService/Push"
-	NoteSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.NoteSyncService/Pull"
+	NoteSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.NoteSyncService/Push"
+	NoteSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.NoteSyncService/PushStream"
+	NoteSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.NoteSyncService/Pull"
 )
 
 // NoteSyncServiceClient is the client API for NoteSyncService service.
@@ -300,6 +301,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NoteSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -321,6 +323,19 @@ func (c *noteSyncServiceClient) Push(ctx context.Context, in *PushRequest, opts
 	return out, nil
 }
 
+func (c *noteSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteSyncService_ServiceDesc.Streams[0], NoteSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *noteSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -336,6 +351,7 @@ func (c *noteSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts
 // for forward compatibility.
 type NoteSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedNoteSyncServiceServer()
 }
@@ -350,6 +366,9 @@ type UnimplementedNoteSyncServiceServer struct{}
 func (UnimplementedNoteSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedNoteSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedNoteSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -392,6 +411,13 @@ func _NoteSyncService_Push_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NoteSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NoteSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _NoteSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -426,13 +452,21 @@ var NoteSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _NoteSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _NoteSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	TaskSyncService_Push_FullMethodName = "/toolbridge.sync.v1.TaskSyncService/Push"
-	TaskSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.TaskSyncService/Pull"
+	TaskSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.TaskSyncService/Push"
+	TaskSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.TaskSyncService/PushStream"
+	TaskSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.TaskSyncService/Pull"
 )
 
 // TaskSyncServiceClient is the client API for TaskSyncService service.
@@ -440,6 +474,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -461,6 +496,19 @@ func (c *taskSyncServiceClient) Push(ctx context.Context, in *PushRequest, opts
 	return out, nil
 }
 
+func (c *taskSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskSyncService_ServiceDesc.Streams[0], TaskSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *taskSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -476,6 +524,7 @@ func (c *taskSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts
 // for forward compatibility.
 type TaskSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedTaskSyncServiceServer()
 }
@@ -490,6 +539,9 @@ type UnimplementedTaskSyncServiceServer struct{}
 func (UnimplementedTaskSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedTaskSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedTaskSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -532,6 +584,13 @@ func _TaskSyncService_Push_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TaskSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _TaskSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -566,13 +625,21 @@ var TaskSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _TaskSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _TaskSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	CommentSyncService_Push_FullMethodName = "/toolbridge.sync.v1.CommentSyncService/Push"
-	CommentSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.CommentSyncService/Pull"
+	CommentSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.CommentSyncService/Push"
+	CommentSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.CommentSyncService/PushStream"
+	CommentSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.CommentSyncService/Pull"
 )
 
 // CommentSyncServiceClient is the client API for CommentSyncService service.
@@ -580,6 +647,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CommentSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -601,6 +669,19 @@ func (c *commentSyncServiceClient) Push(ctx context.Context, in *PushRequest, op
 	return out, nil
 }
 
+func (c *commentSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CommentSyncService_ServiceDesc.Streams[0], CommentSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CommentSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *commentSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -616,6 +697,7 @@ func (c *commentSyncServiceClient) Pull(ctx context.Context, in *PullRequest, op
 // for forward compatibility.
 type CommentSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedCommentSyncServiceServer()
 }
@@ -630,6 +712,9 @@ type UnimplementedCommentSyncServiceServer struct{}
 func (UnimplementedCommentSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedCommentSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedCommentSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -672,6 +757,13 @@ func _CommentSyncService_Push_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommentSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CommentSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CommentSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _CommentSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -706,13 +798,21 @@ var CommentSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _CommentSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _CommentSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	ChatSyncService_Push_FullMethodName = "/toolbridge.sync.v1.ChatSyncService/Push"
-	ChatSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.ChatSyncService/Pull"
+	ChatSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.ChatSyncService/Push"
+	ChatSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.ChatSyncService/PushStream"
+	ChatSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.ChatSyncService/Pull"
 )
 
 // ChatSyncServiceClient is the client API for ChatSyncService service.
@@ -720,6 +820,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ChatSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -741,6 +842,19 @@ func (c *chatSyncServiceClient) Push(ctx context.Context, in *PushRequest, opts
 	return out, nil
 }
 
+func (c *chatSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatSyncService_ServiceDesc.Streams[0], ChatSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *chatSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -756,6 +870,7 @@ func (c *chatSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts
 // for forward compatibility.
 type ChatSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedChatSyncServiceServer()
 }
@@ -770,6 +885,9 @@ type UnimplementedChatSyncServiceServer struct{}
 func (UnimplementedChatSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedChatSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedChatSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -812,6 +930,13 @@ func _ChatSyncService_Push_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _ChatSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -846,13 +971,21 @@ var ChatSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ChatSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _ChatSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	ChatMessageSyncService_Push_FullMethodName = "/toolbridge.sync.v1.ChatMessageSyncService/Push"
-	ChatMessageSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.ChatMessageSyncService/Pull"
+	ChatMessageSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.ChatMessageSyncService/Push"
+	ChatMessageSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.ChatMessageSyncService/PushStream"
+	ChatMessageSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.ChatMessageSyncService/Pull"
 )
 
 // ChatMessageSyncServiceClient is the client API for ChatMessageSyncService service.
@@ -860,6 +993,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ChatMessageSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -881,6 +1015,19 @@ func (c *chatMessageSyncServiceClient) Push(ctx context.Context, in *PushRequest
 	return out, nil
 }
 
+func (c *chatMessageSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatMessageSyncService_ServiceDesc.Streams[0], ChatMessageSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatMessageSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *chatMessageSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -896,6 +1043,7 @@ func (c *chatMessageSyncServiceClient) Pull(ctx context.Context, in *PullRequest
 // for forward compatibility.
 type ChatMessageSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedChatMessageSyncServiceServer()
 }
@@ -910,6 +1058,9 @@ type UnimplementedChatMessageSyncServiceServer struct{}
 func (UnimplementedChatMessageSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedChatMessageSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedChatMessageSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -953,6 +1104,13 @@ func _ChatMessageSyncService_Push_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatMessageSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatMessageSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatMessageSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _ChatMessageSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -987,13 +1145,21 @@ var ChatMessageSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ChatMessageSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _ChatMessageSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	TaskListSyncService_Push_FullMethodName = "/toolbridge.sync.v1.TaskListSyncService/Push"
-	TaskListSyncService_Pull_FullMethodName = "/toolbridge.sync.v1.TaskListSyncService/Pull"
+	TaskListSyncService_Push_FullMethodName       = "/toolbridge.sync.v1.TaskListSyncService/Push"
+	TaskListSyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.TaskListSyncService/PushStream"
+	TaskListSyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.TaskListSyncService/Pull"
 )
 
 // TaskListSyncServiceClient is the client API for TaskListSyncService service.
@@ -1001,6 +1167,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskListSyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -1022,6 +1189,19 @@ func (c *taskListSyncServiceClient) Push(ctx context.Context, in *PushRequest, o
 	return out, nil
 }
 
+func (c *taskListSyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskListSyncService_ServiceDesc.Streams[0], TaskListSyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskListSyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *taskListSyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -1037,6 +1217,7 @@ func (c *taskListSyncServiceClient) Pull(ctx context.Context, in *PullRequest, o
 // for forward compatibility.
 type TaskListSyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedTaskListSyncServiceServer()
 }
@@ -1051,6 +1232,9 @@ type UnimplementedTaskListSyncServiceServer struct{}
 func (UnimplementedTaskListSyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedTaskListSyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedTaskListSyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -1093,6 +1277,13 @@ func _TaskListSyncService_Push_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskListSyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TaskListSyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskListSyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _TaskListSyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -1127,13 +1318,21 @@ var TaskListSyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _TaskListSyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _TaskListSyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
 
 const (
-	TaskListCategorySyncService_Push_FullMethodName = "/toolbridge.sync.v1.TaskListCategorySyncService/Push"
-	TaskListCategorySyncService_Pull_FullMethodName = "/toolbridge.sync.v1.TaskListCategorySyncService/Pull"
+	TaskListCategorySyncService_Push_FullMethodName       = "/toolbridge.sync.v1.TaskListCategorySyncService/Push"
+	TaskListCategorySyncService_PushStream_FullMethodName = "/toolbridge.sync.v1.TaskListCategorySyncService/PushStream"
+	TaskListCategorySyncService_Pull_FullMethodName       = "/toolbridge.sync.v1.TaskListCategorySyncService/Pull"
 )
 
 // TaskListCategorySyncServiceClient is the client API for TaskListCategorySyncService service.
@@ -1141,6 +1340,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskListCategorySyncServiceClient interface {
 	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error)
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
 }
 
@@ -1162,6 +1362,19 @@ func (c *taskListCategorySyncServiceClient) Push(ctx context.Context, in *PushRe
 	return out, nil
 }
 
+func (c *taskListCategorySyncServiceClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskListCategorySyncService_ServiceDesc.Streams[0], TaskListCategorySyncService_PushStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskListCategorySyncService_PushStreamClient = grpc.BidiStreamingClient[PushRequest, PushResponse]
+
 func (c *taskListCategorySyncServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PullResponse)
@@ -1177,6 +1390,7 @@ func (c *taskListCategorySyncServiceClient) Pull(ctx context.Context, in *PullRe
 // for forward compatibility.
 type TaskListCategorySyncServiceServer interface {
 	Push(context.Context, *PushRequest) (*PushResponse, error)
+	PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error
 	Pull(context.Context, *PullRequest) (*PullResponse, error)
 	mustEmbedUnimplementedTaskListCategorySyncServiceServer()
 }
@@ -1191,6 +1405,9 @@ type UnimplementedTaskListCategorySyncServiceServer struct{}
 func (UnimplementedTaskListCategorySyncServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (UnimplementedTaskListCategorySyncServiceServer) PushStream(grpc.BidiStreamingServer[PushRequest, PushResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
 func (UnimplementedTaskListCategorySyncServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Pull not implemented")
 }
@@ -1234,6 +1451,13 @@ func _TaskListCategorySyncService_Push_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskListCategorySyncService_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TaskListCategorySyncServiceServer).PushStream(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskListCategorySyncService_PushStreamServer = grpc.BidiStreamingServer[PushRequest, PushResponse]
+
 func _TaskListCategorySyncService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PullRequest)
 	if err := dec(in); err != nil {
@@ -1268,6 +1492,13 @@ var TaskListCategorySyncService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _TaskListCategorySyncService_Pull_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _TaskListCategorySyncService_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "sync/v1/sync.proto",
 }
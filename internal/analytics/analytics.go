@@ -0,0 +1,222 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Counts holds sync activity for one user on one day
+type Counts struct {
+	Pushes      int64 `json:"pushes"`
+	Pulls       int64 `json:"pulls"`
+	ItemsPushed int64 `json:"itemsPushed"`
+	ItemsPulled int64 `json:"itemsPulled"`
+	BytesIn     int64 `json:"bytesIn"`
+	BytesOut    int64 `json:"bytesOut"`
+	Conflicts   int64 `json:"conflicts"`
+}
+
+func (c *Counts) add(o Counts) {
+	c.Pushes += o.Pushes
+	c.Pulls += o.Pulls
+	c.ItemsPushed += o.ItemsPushed
+	c.ItemsPulled += o.ItemsPulled
+	c.BytesIn += o.BytesIn
+	c.BytesOut += o.BytesOut
+	c.Conflicts += o.Conflicts
+}
+
+// Day is one row of a user's sync history
+type Day struct {
+	Date string `json:"date"` // YYYY-MM-DD (UTC)
+	Counts
+}
+
+type key struct {
+	userID string
+	day    string
+}
+
+// Recorder accumulates per-user daily sync counts in memory and periodically
+// rolls them up into sync_analytics_daily
+// Recording is a map update under a mutex so sync handlers never wait on the DB.
+// All methods are safe on a nil *Recorder (analytics disabled).
+type Recorder struct {
+	db      *pgxpool.Pool
+	mu      sync.Mutex
+	pending map[key]Counts
+	now     func() time.Time
+}
+
+// NewRecorder creates a recorder that flushes into db
+func NewRecorder(db *pgxpool.Pool) *Recorder {
+	return &Recorder{
+		db:      db,
+		pending: make(map[key]Counts),
+		now:     time.Now,
+	}
+}
+
+func (r *Recorder) record(userID string, c Counts) {
+	if r == nil || userID == "" {
+		return
+	}
+	k := key{userID: userID, day: r.now().UTC().Format(time.DateOnly)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur := r.pending[k]
+	cur.add(c)
+	r.pending[k] = cur
+}
+
+// RecordPush records a committed push batch
+func (r *Recorder) RecordPush(userID string, items, conflicts int) {
+	r.record(userID, Counts{Pushes: 1, ItemsPushed: int64(items), Conflicts: int64(conflicts)})
+}
+
+// RecordPull records one pull page
+func (r *Recorder) RecordPull(userID string, items int) {
+	r.record(userID, Counts{Pulls: 1, ItemsPulled: int64(items)})
+}
+
+// RecordBytes records request/response payload sizes
+func (r *Recorder) RecordBytes(userID string, in, out int64) {
+	r.record(userID, Counts{BytesIn: in, BytesOut: out})
+}
+
+// Run flushes pending counts every interval until ctx is cancelled,
+// then performs a final flush (with a short timeout) before returning
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				log.Warn().Err(err).Msg("analytics rollup failed; will retry")
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := r.Flush(flushCtx); err != nil {
+				log.Warn().Err(err).Msg("final analytics rollup failed")
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// Flush adds all pending counts to sync_analytics_daily
+// On failure the counts are merged back so the next flush retries them.
+func (r *Recorder) Flush(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[key]Counts)
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	b := &pgx.Batch{}
+	for k, c := range batch {
+		b.Queue(`
+			INSERT INTO sync_analytics_daily
+				(owner_id, day, pushes, pulls, items_pushed, items_pulled, bytes_in, bytes_out, conflicts)
+			VALUES ($1, $2::date, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (owner_id, day) DO UPDATE SET
+				pushes       = sync_analytics_daily.pushes + EXCLUDED.pushes,
+				pulls        = sync_analytics_daily.pulls + EXCLUDED.pulls,
+				items_pushed = sync_analytics_daily.items_pushed + EXCLUDED.items_pushed,
+				items_pulled = sync_analytics_daily.items_pulled + EXCLUDED.items_pulled,
+				bytes_in     = sync_analytics_daily.bytes_in + EXCLUDED.bytes_in,
+				bytes_out    = sync_analytics_daily.bytes_out + EXCLUDED.bytes_out,
+				conflicts    = sync_analytics_daily.conflicts + EXCLUDED.conflicts,
+				updated_at   = now()
+		`, k.userID, k.day, c.Pushes, c.Pulls, c.ItemsPushed, c.ItemsPulled, c.BytesIn, c.BytesOut, c.Conflicts)
+	}
+
+	// One transaction so a failed flush can be retried in full without double counting
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, b).Close()
+	})
+	if err != nil {
+		r.mu.Lock()
+		for k, c := range batch {
+			cur := r.pending[k]
+			cur.add(c)
+			r.pending[k] = cur
+		}
+		r.mu.Unlock()
+		return err
+	}
+
+	log.Debug().Int("rows", len(batch)).Msg("analytics rollup flushed")
+	return nil
+}
+
+// Daily returns a user's sync history for [from, to] (UTC dates, inclusive),
+// oldest first, including counts not yet flushed by this process
+// Days without activity are omitted.
+func (r *Recorder) Daily(ctx context.Context, userID string, from, to time.Time) ([]Day, error) {
+	fromDay := from.UTC().Format(time.DateOnly)
+	toDay := to.UTC().Format(time.DateOnly)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT to_char(day, 'YYYY-MM-DD'), pushes, pulls, items_pushed, items_pulled, bytes_in, bytes_out, conflicts
+		FROM sync_analytics_daily
+		WHERE owner_id = $1 AND day BETWEEN $2::date AND $3::date
+		ORDER BY day
+	`, userID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDay := make(map[string]Counts)
+	for rows.Next() {
+		var date string
+		var c Counts
+		if err := rows.Scan(&date, &c.Pushes, &c.Pulls, &c.ItemsPushed, &c.ItemsPulled, &c.BytesIn, &c.BytesOut, &c.Conflicts); err != nil {
+			return nil, err
+		}
+		byDay[date] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Merge unflushed counts (normally only today's)
+	r.mu.Lock()
+	for k, c := range r.pending {
+		if k.userID != userID || k.day < fromDay || k.day > toDay {
+			continue
+		}
+		cur := byDay[k.day]
+		cur.add(c)
+		byDay[k.day] = cur
+	}
+	r.mu.Unlock()
+
+	days := make([]Day, 0, len(byDay))
+	for date, c := range byDay {
+		days = append(days, Day{Date: date, Counts: c})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
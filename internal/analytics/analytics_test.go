@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecorderAccumulatesPerUserDay(t *testing.T) {
+	r := NewRecorder(nil)
+	day := time.Date(2025, 3, 1, 23, 30, 0, 0, time.UTC)
+	r.now = func() time.Time { return day }
+
+	r.RecordPush("u1", 10, 2)
+	r.RecordPush("u1", 5, 0)
+	r.RecordPull("u1", 7)
+	r.RecordBytes("u1", 100, 200)
+	r.RecordPull("u2", 1)
+	r.RecordPull("", 1) // unauthenticated requests are ignored
+
+	got := r.pending[key{userID: "u1", day: "2025-03-01"}]
+	want := Counts{Pushes: 2, Pulls: 1, ItemsPushed: 15, ItemsPulled: 7, BytesIn: 100, BytesOut: 200, Conflicts: 2}
+	if got != want {
+		t.Errorf("u1 counts = %+v, want %+v", got, want)
+	}
+	if len(r.pending) != 2 {
+		t.Errorf("pending keys = %d, want 2", len(r.pending))
+	}
+
+	// Activity after midnight UTC lands in a new bucket
+	r.now = func() time.Time { return day.Add(time.Hour) }
+	r.RecordPull("u1", 1)
+	if c := r.pending[key{userID: "u1", day: "2025-03-02"}]; c.Pulls != 1 {
+		t.Errorf("next-day pulls = %d, want 1", c.Pulls)
+	}
+}
+
+func TestNilRecorderIsNoop(t *testing.T) {
+	var r *Recorder
+	r.RecordPush("u1", 1, 0)
+	r.RecordPull("u1", 1)
+	r.RecordBytes("u1", 1, 1)
+	if err := r.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush on nil recorder: %v", err)
+	}
+}
@@ -12,9 +12,13 @@ import (
 	"io"
 	"math/big"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/clock"
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
@@ -59,6 +63,35 @@ type JWTCfg struct {
 	// This enables secure distribution of the public key to downstream services for validation.
 	BackendRSAPrivateKeyPEM string // PEM-encoded RSA private key for backend tokens (optional)
 	BackendKeyID            string // kid used for backend tokens (must be non-empty if private key is set)
+
+	Clock clock.Clock // Time source for exp/nbf/iat checks (nil means the system clock)
+}
+
+// NewJWTCfg builds the JWT configuration from the server configuration
+// DevMode follows the ENV profile and MCP_OAUTH_AUDIENCE is accepted in
+// addition to JWT_AUDIENCE.
+func NewJWTCfg(c *config.Config) JWTCfg {
+	// WorkOS AuthKit with DCR: leave MCP_OAUTH_AUDIENCE empty to skip audience validation.
+	// Static registration: it MUST equal the `resource` value from the MCP server's
+	// /.well-known/oauth-protected-resource metadata (e.g. https://toolbridge-mcp-staging.fly.dev/mcp).
+	acceptedAudiences := []string{}
+	if mcpAudience := strings.TrimSpace(c.MCP.OAuthAudience); mcpAudience != "" {
+		acceptedAudiences = append(acceptedAudiences, mcpAudience)
+	}
+	prof := c.Profile()
+	return JWTCfg{
+		HS256Secret:       c.Auth.HS256Secret,
+		DevMode:           prof.DevMode,
+		Env:               prof.Name,
+		Issuer:            c.Auth.Issuer,
+		JWKSURL:           c.Auth.JWKSURL,
+		Audience:          c.Auth.Audience,
+		AcceptedAudiences: acceptedAudiences,
+		TenantClaim:       c.Auth.TenantClaim,
+
+		BackendRSAPrivateKeyPEM: c.Auth.BackendRSAPrivateKey,
+		BackendKeyID:            c.Auth.BackendKeyID,
+	}
 }
 
 // JWKS caching for upstream IdP public keys
@@ -74,14 +107,28 @@ type jwksCache struct {
 var globalJWKSCache *jwksCache
 
 // BackendSigner holds the RSA key pair for signing backend tokens (token exchange)
-// Initialized once at startup if BackendRSAPrivateKeyPEM is configured
+// Initialized at startup if BackendRSAPrivateKeyPEM is configured, and again
+// when the key is rotated
 type BackendSigner struct {
 	PrivateKey *rsa.PrivateKey
 	PublicKey  *rsa.PublicKey
 	KeyID      string
 }
 
-var backendSigner *BackendSigner
+var (
+	backendSignerMu sync.RWMutex
+	backendSigner   *BackendSigner
+	// previousBackendKey is the key replaced by the last rotation under the same kid
+	// Still accepted for verification so tokens issued before the rotation stay valid.
+	previousBackendKey *rsa.PublicKey
+)
+
+// currentBackendSigner returns the signer and the previous public key (nil unless rotated)
+func currentBackendSigner() (*BackendSigner, *rsa.PublicKey) {
+	backendSignerMu.RLock()
+	defer backendSignerMu.RUnlock()
+	return backendSigner, previousBackendKey
+}
 
 // JWKS response structure from OIDC provider
 type jwksResponse struct {
@@ -234,8 +281,11 @@ func ValidateToken(tokenString string, cfg JWTCfg) (string, jwt.MapClaims, error
 
 			// 1) Backend RS256 tokens: use internal backendSigner public key
 			// This routes tokens signed by our backend (token exchange) to the correct key
-			if backendSigner != nil && cfg.BackendKeyID != "" && kid == cfg.BackendKeyID {
-				return backendSigner.PublicKey, nil
+			if signer, previous := currentBackendSigner(); signer != nil && cfg.BackendKeyID != "" && kid == cfg.BackendKeyID {
+				if previous != nil {
+					return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{signer.PublicKey, previous}}, nil
+				}
+				return signer.PublicKey, nil
 			}
 
 			// 2) External IdP RS256 tokens: validate via JWKS
@@ -264,7 +314,7 @@ func ValidateToken(tokenString string, cfg JWTCfg) (string, jwt.MapClaims, error
 		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-	})
+	}, jwt.WithTimeFunc(clock.Or(cfg.Clock).Now))
 
 	if err != nil || !t.Valid {
 		return "", nil, fmt.Errorf("jwt validation failed: %w", err)
@@ -360,8 +410,8 @@ func InitJWKSCache(cfg JWTCfg) error {
 		return nil // No upstream IdP configured, skip initialization
 	}
 
-	if globalJWKSCache != nil {
-		return nil // Already initialized
+	if globalJWKSCache != nil && globalJWKSCache.jwksURL == cfg.JWKSURL {
+		return nil // Already initialized (a different URL, e.g. another test server's mock IdP, replaces it)
 	}
 
 	globalJWKSCache = &jwksCache{
@@ -384,7 +434,8 @@ func InitJWKSCache(cfg JWTCfg) error {
 }
 
 // InitBackendSigner initializes the backend RS256 signer from configuration
-// Should be called once at application startup if BackendRSAPrivateKeyPEM is configured
+// Called at application startup if BackendRSAPrivateKeyPEM is configured; calling it
+// again with a new key rotates the signer (the replaced key still verifies)
 func InitBackendSigner(cfg JWTCfg) error {
 	if cfg.BackendRSAPrivateKeyPEM == "" {
 		// RS256 backend signing not configured, HS256 will be used
@@ -427,11 +478,16 @@ func InitBackendSigner(cfg JWTCfg) error {
 		return fmt.Errorf("unsupported PEM block type: %s (expected 'PRIVATE KEY' or 'RSA PRIVATE KEY')", block.Type)
 	}
 
+	backendSignerMu.Lock()
+	if backendSigner != nil && backendSigner.KeyID == cfg.BackendKeyID && !backendSigner.PublicKey.Equal(&privateKey.PublicKey) {
+		previousBackendKey = backendSigner.PublicKey
+	}
 	backendSigner = &BackendSigner{
 		PrivateKey: privateKey,
 		PublicKey:  &privateKey.PublicKey,
 		KeyID:      cfg.BackendKeyID,
 	}
+	backendSignerMu.Unlock()
 
 	log.Info().
 		Str("kid", cfg.BackendKeyID).
@@ -444,10 +500,10 @@ func InitBackendSigner(cfg JWTCfg) error {
 // This centralizes the signing logic for all backend token issuance (token exchange, etc.)
 func SignBackendToken(claims jwt.MapClaims, cfg JWTCfg) (string, error) {
 	// Prefer RS256 when backend signer is configured
-	if backendSigner != nil && cfg.BackendRSAPrivateKeyPEM != "" && cfg.BackendKeyID != "" {
+	if signer, _ := currentBackendSigner(); signer != nil && cfg.BackendRSAPrivateKeyPEM != "" && cfg.BackendKeyID != "" {
 		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-		token.Header["kid"] = backendSigner.KeyID
-		return token.SignedString(backendSigner.PrivateKey)
+		token.Header["kid"] = signer.KeyID
+		return token.SignedString(signer.PrivateKey)
 	}
 
 	// Fallback to HS256
@@ -532,6 +588,7 @@ func Middleware(db *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
 			// Add user ID and subject to request context
 			ctx := context.WithValue(r.Context(), CtxUserID, userID)
 			ctx = context.WithValue(ctx, CtxSubject, sub)
+			errreport.SetUser(ctx, userID)
 
 			// Extract tenant from JWT claims if configured and not already set by header middleware
 			// Precedence: X-TB-Tenant-ID header (if present) > JWT tenant claim > no tenant
@@ -560,6 +617,7 @@ func Middleware(db *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
 						Msg("tenant claim not found in JWT (expected for backend-driven tenant resolution or header-based tenancy)")
 				}
 			}
+			errreport.SetTag(ctx, "tenant_id", TenantID(ctx))
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -5,9 +5,11 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/clock"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -825,6 +827,50 @@ func TestValidateToken_RS256BackendToken_WrongKid(t *testing.T) {
 	backendSigner = nil
 }
 
+// TestInitBackendSigner_Rotation tests that a rotated key signs new tokens
+// while tokens signed with the replaced key still validate
+func TestInitBackendSigner_Rotation(t *testing.T) {
+	backendSigner, previousBackendKey = nil, nil
+	globalJWKSCache = nil
+	defer func() { backendSigner, previousBackendKey = nil, nil }()
+
+	cfg := JWTCfg{BackendKeyID: "backend-rotating"}
+	claims := jwt.MapClaims{
+		"sub":        "user_rotation",
+		"token_type": "backend",
+		"exp":        time.Now().Add(1 * time.Hour).Unix(),
+	}
+
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	cfg.BackendRSAPrivateKeyPEM = pemEncode(marshalPKCS1PrivateKey(oldKey), "RSA PRIVATE KEY")
+	if err := InitBackendSigner(cfg); err != nil {
+		t.Fatalf("InitBackendSigner failed: %v", err)
+	}
+	oldToken, err := SignBackendToken(claims, cfg)
+	if err != nil {
+		t.Fatalf("SignBackendToken failed: %v", err)
+	}
+
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	cfg.BackendRSAPrivateKeyPEM = pemEncode(marshalPKCS1PrivateKey(newKey), "RSA PRIVATE KEY")
+	if err := InitBackendSigner(cfg); err != nil {
+		t.Fatalf("rotation failed: %v", err)
+	}
+	newToken, err := SignBackendToken(claims, cfg)
+	if err != nil {
+		t.Fatalf("SignBackendToken after rotation failed: %v", err)
+	}
+
+	for name, tok := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, _, err := ValidateToken(tok, cfg); err != nil {
+			t.Errorf("%s token rejected after rotation: %v", name, err)
+		}
+	}
+	if signer, _ := currentBackendSigner(); !signer.PublicKey.Equal(&newKey.PublicKey) {
+		t.Error("new tokens are not signed with the rotated key")
+	}
+}
+
 // Helper functions for tests
 func marshalPKCS8PrivateKey(key *rsa.PrivateKey) ([]byte, error) {
 	return x509.MarshalPKCS8PrivateKey(key)
@@ -841,3 +887,31 @@ func pemEncode(data []byte, blockType string) string {
 	}
 	return string(pem.EncodeToMemory(block))
 }
+
+// TestValidateToken_Clock checks that expiry is judged by cfg.Clock
+func TestValidateToken_Clock(t *testing.T) {
+	secret := "test-hmac-secret"
+	issued := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(issued)
+	cfg := JWTCfg{HS256Secret: secret, Clock: fake}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":        "user_123",
+		"iss":        "toolbridge-api",
+		"token_type": "backend",
+		"iat":        issued.Unix(),
+		"exp":        issued.Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Long expired by the system clock, but not by the configured one
+	if _, _, err := ValidateToken(token, cfg); err != nil {
+		t.Fatalf("token rejected within its lifetime: %v", err)
+	}
+	fake.Advance(time.Hour + time.Second)
+	if _, _, err := ValidateToken(token, cfg); err == nil || !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("err = %v, want ErrTokenExpired", err)
+	}
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/clock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdPMaxTTL caps the lifetime of mock IdP tokens
+const mockIdPMaxTTL = 24 * time.Hour
+
+// MockIdP is a minimal OIDC issuer for local development and CI
+// It serves a discovery document, a JWKS and a token endpoint that signs an
+// RS256 access token for any subject it is asked for, so tokens go through
+// the same JWKS fetch, kid lookup and issuer/audience checks as ones from
+// WorkOS or Auth0. The signing key is generated per process. Only mounted in
+// dev mode (MOCK_IDP=true).
+type MockIdP struct {
+	Issuer      string // Must match JWT_ISSUER
+	Audience    string // Default aud claim (JWT_AUDIENCE); empty omits it
+	TenantClaim string // Claim the token endpoint's tenant parameter is written to
+
+	key   *rsa.PrivateKey
+	kid   string
+	clock clock.Clock
+}
+
+// NewMockIdP generates a signing key for an issuer configured like cfg
+func NewMockIdP(cfg JWTCfg) (*MockIdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	// The kid changes with the key, so a JWKS cached from a previous run is
+	// refreshed instead of failing signature checks
+	sum := sha256.Sum256(key.N.Bytes())
+	return &MockIdP{
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		TenantClaim: cfg.TenantClaim,
+		key:         key,
+		kid:         "mock-" + hex.EncodeToString(sum[:8]),
+		clock:       cfg.Clock,
+	}, nil
+}
+
+// Sign returns an RS256 token for sub valid for ttl, with extra claims merged in
+func (m *MockIdP) Sign(sub string, ttl time.Duration, extra jwt.MapClaims) (string, error) {
+	now := clock.Or(m.clock).Now()
+	claims := jwt.MapClaims{
+		"iss": m.Issuer,
+		"sub": sub,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	if m.Audience != "" {
+		claims["aud"] = m.Audience
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	return token.SignedString(m.key)
+}
+
+// Handler serves the IdP's endpoints relative to the issuer URL:
+//
+//	GET  /.well-known/openid-configuration  discovery document
+//	GET  /jwks                              public signing key
+//	POST /token                             issue a token (see token)
+//
+// Mount it with the issuer's path stripped.
+func (m *MockIdP) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("GET /jwks", m.jwks)
+	mux.HandleFunc("POST /token", m.token)
+	return mux
+}
+
+func (m *MockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeMockIdPJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                m.Issuer,
+		"jwks_uri":                              m.Issuer + "/jwks",
+		"token_endpoint":                        m.Issuer + "/token",
+		"grant_types_supported":                 []string{"client_credentials", "password"},
+		"response_types_supported":              []string{"token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *MockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := m.key.PublicKey
+	writeMockIdPJSON(w, http.StatusOK, jwksResponse{Keys: []jwk{{
+		Kid: m.kid,
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+// token issues a token for any subject, without credentials
+// Form (or query) parameters: sub (or username, required), aud (defaults to
+// Audience), tenant (written to TenantClaim) and expires_in in seconds
+// (default 3600).
+func (m *MockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		mockIdPError(w, "invalid_request", "malformed form body")
+		return
+	}
+	sub := r.Form.Get("sub")
+	if sub == "" {
+		sub = r.Form.Get("username")
+	}
+	if sub == "" {
+		mockIdPError(w, "invalid_request", "sub is required")
+		return
+	}
+	ttl := time.Hour
+	if v := r.Form.Get("expires_in"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 || time.Duration(secs)*time.Second > mockIdPMaxTTL {
+			mockIdPError(w, "invalid_request", "expires_in must be between 1 and 86400 seconds")
+			return
+		}
+		ttl = time.Duration(secs) * time.Second
+	}
+
+	extra := jwt.MapClaims{}
+	if aud := r.Form.Get("aud"); aud != "" {
+		extra["aud"] = aud
+	}
+	if tenant := r.Form.Get("tenant"); tenant != "" {
+		if m.TenantClaim == "" {
+			mockIdPError(w, "invalid_request", "tenant requires TENANT_CLAIM to be configured")
+			return
+		}
+		extra[m.TenantClaim] = tenant
+	}
+	if scope := strings.TrimSpace(r.Form.Get("scope")); scope != "" {
+		extra["scope"] = scope
+	}
+
+	token, err := m.Sign(sub, ttl, extra)
+	if err != nil {
+		writeMockIdPJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeMockIdPJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"id_token":     token,
+		"token_type":   "Bearer",
+		"expires_in":   int(ttl.Seconds()),
+	})
+}
+
+// mockIdPError writes an OAuth 2.0 error response (RFC 6749 section 5.2)
+func mockIdPError(w http.ResponseWriter, code, description string) {
+	writeMockIdPJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeMockIdPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
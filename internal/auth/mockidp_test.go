@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// TestMockIdP runs mock IdP tokens through the upstream IdP validation path:
+// discovery, JWKS fetch, kid lookup, issuer and audience checks
+func TestMockIdP(t *testing.T) {
+	var idp *MockIdP
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idp.Handler().ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	cfg := JWTCfg{
+		Issuer:      ts.URL,
+		JWKSURL:     ts.URL + "/jwks",
+		Audience:    "toolbridge-api",
+		TenantClaim: "organization_id",
+	}
+	var err error
+	if idp, err = NewMockIdP(cfg); err != nil {
+		t.Fatal(err)
+	}
+	prev := globalJWKSCache
+	globalJWKSCache = nil
+	t.Cleanup(func() { globalJWKSCache = prev })
+	if err := InitJWKSCache(cfg); err != nil {
+		t.Fatalf("fetch JWKS: %v", err)
+	}
+
+	resp, err := http.Get(ts.URL + "/.well-known/openid-configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+	if doc["issuer"] != ts.URL || doc["jwks_uri"] != ts.URL+"/jwks" || doc["token_endpoint"] != ts.URL+"/token" {
+		t.Errorf("discovery = %v", doc)
+	}
+
+	token := func(form url.Values) (int, string) {
+		t.Helper()
+		resp, err := http.PostForm(ts.URL+"/token", form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			AccessToken string `json:"access_token"`
+			TokenType   string `json:"token_type"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode == http.StatusOK && body.TokenType != "Bearer" {
+			t.Errorf("token_type = %q", body.TokenType)
+		}
+		return resp.StatusCode, body.AccessToken
+	}
+
+	code, tok := token(url.Values{"sub": {"user_mock"}, "tenant": {"org_123"}})
+	if code != http.StatusOK {
+		t.Fatalf("token status = %d", code)
+	}
+	sub, claims, err := ValidateToken(tok, cfg)
+	if err != nil {
+		t.Fatalf("mock IdP token rejected: %v", err)
+	}
+	if sub != "user_mock" || claims["organization_id"] != "org_123" {
+		t.Errorf("sub = %q, claims = %v", sub, claims)
+	}
+
+	// The regular checks still apply
+	if _, tok := token(url.Values{"sub": {"user_mock"}, "aud": {"someone-else"}}); tok == "" {
+		t.Error("no token for another audience")
+	} else if _, _, err := ValidateToken(tok, cfg); err == nil {
+		t.Error("token for another audience accepted")
+	}
+	other := cfg
+	other.Issuer = "https://auth.example.com"
+	if _, _, err := ValidateToken(tok, other); err == nil {
+		t.Error("token accepted for a different issuer")
+	}
+
+	for name, form := range map[string]url.Values{
+		"no subject":  {"grant_type": {"client_credentials"}},
+		"bad expiry":  {"sub": {"u"}, "expires_in": {"-5"}},
+		"long expiry": {"sub": {"u"}, "expires_in": {"100000"}},
+	} {
+		if code, _ := token(form); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, code)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound is returned when the user has no feed, or a token matches no feed
+var ErrNotFound = errors.New("calendar feed not found")
+
+// Feed describes a user's feed (the token is only known when it is created)
+type Feed struct {
+	Token     string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Service issues feed tokens and renders task feeds
+type Service struct {
+	DB       *pgxpool.Pool
+	MaxTasks int // Cap on tasks rendered into one feed
+}
+
+// NewService creates a calendar feed service
+func NewService(db *pgxpool.Pool) *Service {
+	return &Service{DB: db, MaxTasks: 5000}
+}
+
+// hashToken returns the stored form of a feed token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Rotate issues a new feed token for the user, invalidating any previous one
+func (s *Service) Rotate(ctx context.Context, userID string) (*Feed, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	feed := Feed{Token: base64.RawURLEncoding.EncodeToString(b)}
+	err := s.DB.QueryRow(ctx, `
+		INSERT INTO calendar_feed (owner_id, token_hash) VALUES ($1, $2)
+		ON CONFLICT (owner_id) DO UPDATE SET token_hash = excluded.token_hash, created_at = now()
+		RETURNING created_at
+	`, userID, hashToken(feed.Token)).Scan(&feed.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Get returns the user's feed (without its token)
+func (s *Service) Get(ctx context.Context, userID string) (*Feed, error) {
+	var feed Feed
+	err := s.DB.QueryRow(ctx, `SELECT created_at FROM calendar_feed WHERE owner_id = $1`, userID).Scan(&feed.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Revoke deletes the user's feed token
+func (s *Service) Revoke(ctx context.Context, userID string) error {
+	tag, err := s.DB.Exec(ctx, `DELETE FROM calendar_feed WHERE owner_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RenderTasks renders the ICS feed for the user owning token
+// Regenerated from the task table on every request: live tasks with a dueDate.
+func (s *Service) RenderTasks(ctx context.Context, token string) ([]byte, error) {
+	var userID string
+	err := s.DB.QueryRow(ctx, `SELECT owner_id::text FROM calendar_feed WHERE token_hash = $1`, hashToken(token)).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT uid::text, version, updated_at_ms, payload_json
+		FROM task
+		WHERE owner_id = $1 AND deleted_at_ms IS NULL AND payload_json ? 'dueDate'
+		ORDER BY payload_json->>'dueDate', uid
+		LIMIT $2
+	`, userID, s.MaxTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		var t Task
+		var raw []byte
+		if err := rows.Scan(&t.UID, &t.Version, &t.UpdatedAtMs, &raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &t.Payload); err != nil {
+			continue // Skip unreadable payloads rather than failing the whole feed
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return Render("ToolBridge Tasks", tasks), nil
+}
@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// Task is the subset of a task payload rendered into the feed
+type Task struct {
+	UID         string
+	Version     int
+	UpdatedAtMs int64
+	Payload     map[string]any
+}
+
+// dateOnly is the layout of all-day due dates ("2025-12-15")
+const dateOnly = "2006-01-02"
+
+// parseDue parses a task's dueDate
+// Returns allDay for date-only values; false if missing or unparseable.
+func parseDue(payload map[string]any) (due time.Time, allDay, ok bool) {
+	s, found := syncx.GetString(payload, "dueDate")
+	if !found || s == "" {
+		return time.Time{}, false, false
+	}
+	if t, err := time.Parse(dateOnly, s); err == nil {
+		return t, true, true
+	}
+	if ms, parsed := syncx.ParseTimeToMs(s); parsed {
+		return syncx.MsToTime(ms), false, true
+	}
+	return time.Time{}, false, false
+}
+
+// alarmTriggers returns the VALARM TRIGGER values for a task's reminders
+// Each reminder is an RFC3339 timestamp (absolute) or {"minutesBefore": n}
+// (relative to the due date).
+func alarmTriggers(payload map[string]any) []string {
+	list, _ := payload["reminders"].([]any)
+	var out []string
+	for _, r := range list {
+		switch v := r.(type) {
+		case string:
+			if ms, ok := syncx.ParseTimeToMs(v); ok {
+				out = append(out, ";VALUE=DATE-TIME:"+formatUTC(syncx.MsToTime(ms)))
+			}
+		case map[string]any:
+			if n, ok := v["minutesBefore"].(float64); ok && n >= 0 {
+				out = append(out, ":-PT"+strconv.Itoa(int(n))+"M")
+			}
+		}
+	}
+	return out
+}
+
+// ReminderTimes returns when a task's reminders fire
+// Relative reminders ({"minutesBefore": n}) need a dueDate; for a date-only
+// dueDate they count back from midnight UTC.
+func ReminderTimes(payload map[string]any) []time.Time {
+	due, _, hasDue := parseDue(payload)
+	list, _ := payload["reminders"].([]any)
+	var out []time.Time
+	for _, r := range list {
+		switch v := r.(type) {
+		case string:
+			if ms, ok := syncx.ParseTimeToMs(v); ok {
+				out = append(out, syncx.MsToTime(ms))
+			}
+		case map[string]any:
+			if n, ok := v["minutesBefore"].(float64); ok && n >= 0 && hasDue {
+				out = append(out, due.Add(-time.Duration(n)*time.Minute))
+			}
+		}
+	}
+	return out
+}
+
+// formatUTC formats t as an iCalendar UTC date-time
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes an iCalendar TEXT value (RFC 5545 §3.3.11)
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine writes one content line, folded at 75 octets (RFC 5545 §3.1)
+// Folds never split a UTF-8 sequence.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// Render builds a VCALENDAR with one VEVENT per task that has a due date
+// Timed due dates become zero-length events at the due time; date-only due
+// dates become all-day events. Reminders become display VALARMs.
+func Render(name string, tasks []Task) []byte {
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//ToolBridge//Tasks//EN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	writeLine(&buf, "X-WR-CALNAME:"+escapeText(name))
+
+	for _, t := range tasks {
+		due, allDay, ok := parseDue(t.Payload)
+		if !ok {
+			continue
+		}
+		title, _ := syncx.GetString(t.Payload, "title")
+		if title == "" {
+			title = "Untitled task"
+		}
+
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+t.UID+"@toolbridge")
+		writeLine(&buf, "DTSTAMP:"+formatUTC(syncx.MsToTime(t.UpdatedAtMs)))
+		writeLine(&buf, "LAST-MODIFIED:"+formatUTC(syncx.MsToTime(t.UpdatedAtMs)))
+		writeLine(&buf, "SEQUENCE:"+strconv.Itoa(t.Version))
+		if allDay {
+			writeLine(&buf, "DTSTART;VALUE=DATE:"+due.Format("20060102"))
+			writeLine(&buf, "DTEND;VALUE=DATE:"+due.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeLine(&buf, "DTSTART:"+formatUTC(due))
+		}
+		writeLine(&buf, "SUMMARY:"+escapeText(title))
+		if desc, _ := syncx.GetString(t.Payload, "description"); desc != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(desc))
+		}
+		if status, _ := syncx.GetString(t.Payload, "status"); status != "" {
+			writeLine(&buf, "X-TOOLBRIDGE-STATUS:"+escapeText(status))
+		}
+		if tags, _ := t.Payload["tags"].([]any); len(tags) > 0 {
+			cats := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				if s, ok := tag.(string); ok && s != "" {
+					cats = append(cats, escapeText(s))
+				}
+			}
+			if len(cats) > 0 {
+				writeLine(&buf, "CATEGORIES:"+strings.Join(cats, ","))
+			}
+		}
+		for _, trigger := range alarmTriggers(t.Payload) {
+			writeLine(&buf, "BEGIN:VALARM")
+			writeLine(&buf, "ACTION:DISPLAY")
+			writeLine(&buf, "DESCRIPTION:"+escapeText(title))
+			writeLine(&buf, "TRIGGER"+trigger)
+			writeLine(&buf, "END:VALARM")
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
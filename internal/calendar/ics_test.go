@@ -0,0 +1,58 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tasks := []Task{
+		{
+			UID: "t1", Version: 2, UpdatedAtMs: 1700000000000,
+			Payload: map[string]any{
+				"title":       "Ship release; tag, notes",
+				"description": "line one\nline two",
+				"dueDate":     "2025-12-15T17:00:00Z",
+				"tags":        []any{"work", "q4"},
+				"reminders":   []any{map[string]any{"minutesBefore": float64(30)}, "2025-12-15T09:00:00Z"},
+			},
+		},
+		{UID: "t2", Version: 1, UpdatedAtMs: 1700000000000, Payload: map[string]any{"title": "Holiday", "dueDate": "2025-12-25"}},
+		{UID: "t3", Version: 1, UpdatedAtMs: 1700000000000, Payload: map[string]any{"title": "No due date"}},
+	}
+	out := string(Render("Tasks", tasks))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:t1@toolbridge\r\n",
+		"DTSTART:20251215T170000Z\r\n",
+		`SUMMARY:Ship release\; tag\, notes` + "\r\n",
+		`DESCRIPTION:line one\nline two` + "\r\n",
+		"CATEGORIES:work,q4\r\n",
+		"TRIGGER:-PT30M\r\n",
+		"TRIGGER;VALUE=DATE-TIME:20251215T090000Z\r\n",
+		"DTSTART;VALUE=DATE:20251225\r\n",
+		"DTEND;VALUE=DATE:20251226\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("feed missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "No due date") {
+		t.Error("tasks without a due date must be skipped")
+	}
+}
+
+func TestRenderFoldsLongLines(t *testing.T) {
+	title := strings.Repeat("é", 100) // 200 octets
+	out := string(Render("Tasks", []Task{{UID: "t1", Payload: map[string]any{"title": title, "dueDate": "2025-12-25"}}}))
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets (%d): %q", len(line), line)
+		}
+	}
+	if !strings.Contains(strings.ReplaceAll(out, "\r\n ", ""), "SUMMARY:"+title) {
+		t.Error("unfolded summary does not round-trip")
+	}
+}
@@ -0,0 +1,269 @@
+// Package changefeed tells connected clients which entity types changed for
+// their account, so they can pull right away instead of polling. Committed
+// outbox events and epoch bumps are sent with Postgres NOTIFY, and every
+// replica's Hub LISTENs, so a client hears about changes made through any
+// replica.
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Channel is the Postgres NOTIFY channel
+const Channel = "toolbridge_changes"
+
+// syncNames maps outbox entity tables to the sync path names clients pull
+var syncNames = map[string]string{
+	"note":               "notes",
+	"task":               "tasks",
+	"task_list":          "task_lists",
+	"task_list_category": "task_list_categories",
+	"comment":            "comments",
+	"chat":               "chats",
+	"chat_message":       "chat_messages",
+	"pin":                "pins",
+}
+
+// notification is the NOTIFY payload: one owner's changed entities, or their new epoch
+type notification struct {
+	OwnerID  string   `json:"ownerId"`
+	Entities []string `json:"entities,omitempty"` // Sync names
+	Epoch    int      `json:"epoch,omitempty"`    // Set when the owner's epoch changed
+}
+
+// notify sends n when tx commits
+func notify(ctx context.Context, tx pgx.Tx, n notification) error {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `SELECT pg_notify($1, $2)`, Channel, string(b))
+	return err
+}
+
+// NotifyEpoch tells the owner's sockets that their epoch changed (wipe,
+// restore), once tx commits
+func NotifyEpoch(ctx context.Context, tx pgx.Tx, ownerID string, epoch int) error {
+	return notify(ctx, tx, notification{OwnerID: ownerID, Epoch: epoch})
+}
+
+// Publisher is an outbox.Publisher that notifies each owner once per batch
+// with the entity types that changed; the notifications are delivered when
+// the dispatcher's transaction commits
+type Publisher struct{}
+
+// Name implements outbox.Publisher
+func (Publisher) Name() string { return "changefeed" }
+
+// Publish implements outbox.Publisher
+func (Publisher) Publish(ctx context.Context, tx pgx.Tx, events []outbox.Event) error {
+	var owners []string
+	changed := map[string][]string{}
+	for _, e := range events {
+		name, ok := syncNames[e.Entity]
+		if !ok {
+			continue
+		}
+		if _, seen := changed[e.OwnerID]; !seen {
+			owners = append(owners, e.OwnerID)
+		}
+		if !slices.Contains(changed[e.OwnerID], name) {
+			changed[e.OwnerID] = append(changed[e.OwnerID], name)
+		}
+	}
+	for _, owner := range owners {
+		if err := notify(ctx, tx, notification{OwnerID: owner, Entities: changed[owner]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hub fans notifications out to this replica's subscribers
+type Hub struct {
+	DB *pgxpool.Pool
+
+	mu      sync.Mutex
+	subs    map[string]map[*Subscription]struct{} // By owner
+	stopped bool
+}
+
+// NewHub creates a hub; Run receives the notifications
+func NewHub(db *pgxpool.Pool) *Hub {
+	return &Hub{DB: db, subs: map[string]map[*Subscription]struct{}{}}
+}
+
+// Subscription collects an owner's pending changes until they are taken
+// Changes coalesce: however often an entity changes before Take, it is
+// reported once.
+type Subscription struct {
+	hub     *Hub
+	ownerID string
+
+	mu       sync.Mutex
+	entities []string
+	epoch    int
+
+	ready chan struct{} // Signalled when changes are pending
+	done  chan struct{} // Closed when the hub stops
+}
+
+// Subscribe starts collecting the owner's changes; Close the subscription when done
+func (h *Hub) Subscribe(ownerID string) *Subscription {
+	s := &Subscription{hub: h, ownerID: ownerID, ready: make(chan struct{}, 1), done: make(chan struct{})}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		close(s.done)
+		return s
+	}
+	if h.subs[ownerID] == nil {
+		h.subs[ownerID] = map[*Subscription]struct{}{}
+	}
+	h.subs[ownerID][s] = struct{}{}
+	return s
+}
+
+// Ready is signalled when changes are pending
+func (s *Subscription) Ready() <-chan struct{} { return s.ready }
+
+// Done is closed when the hub stops (server shutdown)
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Take returns and clears the pending changes: the changed entity types
+// (sync names) and the owner's new epoch, or 0 if it didn't change
+func (s *Subscription) Take() ([]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entities, epoch := s.entities, s.epoch
+	s.entities, s.epoch = nil, 0
+	return entities, epoch
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[s.ownerID], s)
+	if len(h.subs[s.ownerID]) == 0 {
+		delete(h.subs, s.ownerID)
+	}
+}
+
+// add records changes and signals the subscriber
+func (s *Subscription) add(entities []string, epoch int) {
+	s.mu.Lock()
+	for _, e := range entities {
+		if !slices.Contains(s.entities, e) {
+			s.entities = append(s.entities, e)
+		}
+	}
+	if epoch != 0 {
+		s.epoch = epoch
+	}
+	s.mu.Unlock()
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands a notification to the owner's subscribers
+func (h *Hub) deliver(n notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs[n.OwnerID] {
+		s.add(n.Entities, n.Epoch)
+	}
+}
+
+// resyncAll reports every entity type as changed to every subscriber
+func (h *Hub) resyncAll() {
+	all := make([]string, 0, len(syncNames))
+	for _, name := range syncNames {
+		all = append(all, name)
+	}
+	slices.Sort(all)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for s := range subs {
+			s.add(all, 0)
+		}
+	}
+}
+
+// Run listens for notifications until ctx is cancelled, reconnecting after
+// errors; then it ends every subscription
+func (h *Hub) Run(ctx context.Context, retry time.Duration) {
+	if h == nil {
+		return
+	}
+	defer h.stop()
+
+	for ctx.Err() == nil {
+		err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn().Err(err).Dur("retry", retry).Msg("change feed listener failed; reconnecting")
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// listen holds one connection LISTENing on Channel and delivers its
+// notifications
+func (h *Hub) listen(ctx context.Context) error {
+	pc, err := h.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection stays subscribed to the channel, so it never returns to the pool
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	// Subscribers may have missed changes while no connection was listening
+	h.resyncAll()
+	for {
+		pn, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var n notification
+		if err := json.Unmarshal([]byte(pn.Payload), &n); err != nil || n.OwnerID == "" {
+			log.Warn().Str("payload", pn.Payload).Msg("ignoring malformed change notification")
+			continue
+		}
+		h.deliver(n)
+	}
+}
+
+// stop ends every subscription; later ones start ended
+func (h *Hub) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+	for _, subs := range h.subs {
+		for s := range subs {
+			close(s.done)
+		}
+	}
+	h.subs = map[string]map[*Subscription]struct{}{}
+}
@@ -0,0 +1,61 @@
+package changefeed
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHubDelivery(t *testing.T) {
+	h := NewHub(nil)
+	a := h.Subscribe("owner-a")
+	b := h.Subscribe("owner-b")
+
+	h.deliver(notification{OwnerID: "owner-a", Entities: []string{"notes", "tasks"}})
+	h.deliver(notification{OwnerID: "owner-a", Entities: []string{"tasks", "comments"}})
+
+	select {
+	case <-a.Ready():
+	default:
+		t.Fatal("subscriber not signalled")
+	}
+	entities, epoch := a.Take()
+	if want := []string{"notes", "tasks", "comments"}; !reflect.DeepEqual(entities, want) || epoch != 0 {
+		t.Errorf("Take() = %v, %d; want %v, 0", entities, epoch, want)
+	}
+	if entities, _ := a.Take(); entities != nil {
+		t.Errorf("second Take() = %v, want nothing pending", entities)
+	}
+	select {
+	case <-b.Ready():
+		t.Error("other owner's subscriber signalled")
+	default:
+	}
+
+	h.deliver(notification{OwnerID: "owner-b", Epoch: 3})
+	if _, epoch := b.Take(); epoch != 3 {
+		t.Errorf("epoch = %d, want 3", epoch)
+	}
+
+	b.Close()
+	h.deliver(notification{OwnerID: "owner-b", Entities: []string{"notes"}})
+	if entities, _ := b.Take(); entities != nil {
+		t.Errorf("closed subscription received %v", entities)
+	}
+
+	h.resyncAll()
+	if entities, _ := a.Take(); len(entities) != len(syncNames) {
+		t.Errorf("resync reported %v", entities)
+	}
+
+	h.stop()
+	select {
+	case <-a.Done():
+	default:
+		t.Error("subscription not done after stop")
+	}
+	select {
+	case <-h.Subscribe("owner-c").Done():
+	default:
+		t.Error("subscription after stop not done")
+	}
+}
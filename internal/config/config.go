@@ -0,0 +1,620 @@
+// Package config is the server configuration: one typed struct loaded from
+// defaults, a YAML or TOML file and environment variables, shared by
+// cmd/server, httpapi and auth.
+//
+// Load rejects unparsable values and unresolvable secret references; the
+// semantic checks (required secrets, URL schemes, ...) are in
+// `toolbridge-api check-config`, which the server also runs at startup. Tests
+// can skip both and build a Config literal (start from Default).
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/secrets"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the server configuration
+// Values come from defaults, then the config file (CONFIG_FILE, YAML or TOML),
+// then environment variables. The env tag names the variable that overrides a
+// field (set and non-empty wins); secret fields are redacted when printed.
+type Config struct {
+	Env       string `yaml:"env" env:"ENV"`               // dev|staging|prod profile (see profile.go)
+	LogLevel  string `yaml:"log_level" env:"LOG_LEVEL"`   // trace|debug|info|warn|error
+	LogFormat string `yaml:"log_format" env:"LOG_FORMAT"` // console|json (defaults by profile)
+
+	HTTP         HTTPConfig         `yaml:"http"`
+	GRPC         GRPCConfig         `yaml:"grpc"`
+	Database     DatabaseConfig     `yaml:"database"`
+	Session      SessionConfig      `yaml:"session"`
+	Auth         AuthConfig         `yaml:"auth"`
+	MCP          MCPConfig          `yaml:"mcp"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
+	Tracing      TracingConfig      `yaml:"tracing"`
+	Sentry       SentryConfig       `yaml:"sentry"`
+	Jobs         JobsConfig         `yaml:"jobs"`
+	EventStream  EventStreamConfig  `yaml:"event_stream"`
+	Notify       NotifyConfig       `yaml:"notify"`
+	Integrations IntegrationsConfig `yaml:"integrations"`
+	Admin        AdminConfig        `yaml:"admin"`
+	Export       ExportConfig       `yaml:"export"`
+	CORS         CORSConfig         `yaml:"cors"`
+	Features     map[string]bool    `yaml:"features" env:"FEATURE_FLAGS"` // Client feature flags (env: "a,b,c=false")
+	Maintenance  MaintenanceConfig  `yaml:"maintenance"`
+	Sync         SyncConfig         `yaml:"sync"`
+	Secrets      SecretsConfig      `yaml:"secrets"`
+
+	secretRefs map[string]string // Setting name (env var) -> reference, for secrets loaded by reference
+	resolver   *secrets.Resolver
+}
+
+// HTTPConfig configures the REST listener
+// Keep-alive and HTTP/2 settings let mobile clients that sync in frequent small
+// batches reuse one connection instead of paying a TLS handshake per batch.
+type HTTPConfig struct {
+	Addr                 string        `yaml:"addr" env:"HTTP_ADDR"`
+	TLSCertFile          string        `yaml:"tls_cert_file" env:"HTTP_TLS_CERT_FILE"` // Serve HTTPS (HTTP/2 via ALPN) directly; usually the proxy terminates TLS
+	TLSKeyFile           string        `yaml:"tls_key_file" env:"HTTP_TLS_KEY_FILE"`
+	H2C                  bool          `yaml:"h2c" env:"HTTP_H2C"`                                        // Accept cleartext HTTP/2 from a proxy (prior knowledge)
+	IdleTimeout          time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`                      // Close keep-alive connections idle this long
+	MaxConcurrentStreams int           `yaml:"max_concurrent_streams" env:"HTTP2_MAX_CONCURRENT_STREAMS"` // Per HTTP/2 connection
+	PingInterval         time.Duration `yaml:"ping_interval" env:"HTTP2_PING_INTERVAL"`                   // Ping quiet HTTP/2 connections to find dead clients (0 disables)
+}
+
+// GRPCConfig configures the gRPC listener (binaries built with -tags grpc)
+type GRPCConfig struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR"`
+}
+
+// DatabaseConfig configures Postgres
+type DatabaseConfig struct {
+	URL                string `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	MigrateOnStart     bool   `yaml:"migrate_on_start" env:"MIGRATE_ON_START"`
+	StatementCache     string `yaml:"statement_cache" env:"DB_STATEMENT_CACHE"`           // prepare|describe|off (describe behind PgBouncer transaction pooling)
+	StatementCacheSize int    `yaml:"statement_cache_size" env:"DB_STATEMENT_CACHE_SIZE"` // Statements cached per connection
+	PrepareHotQueries  bool   `yaml:"prepare_hot_queries" env:"DB_PREPARE_HOT_QUERIES"`   // Prepare sync push/pull queries on connect (prepare mode only)
+}
+
+// SessionConfig configures sync session storage and validation
+type SessionConfig struct {
+	Store      string `yaml:"store" env:"SESSION_STORE"`           // memory|postgres (defaults by profile)
+	Validation string `yaml:"validation" env:"SESSION_VALIDATION"` // off|standard|strict checks of X-Sync-Session
+}
+
+// AuthConfig configures JWT validation, backend token signing and tenancy
+type AuthConfig struct {
+	HS256Secret          string `yaml:"hs256_secret" env:"JWT_HS256_SECRET" secret:"true"`
+	Issuer               string `yaml:"issuer" env:"JWT_ISSUER"`
+	JWKSURL              string `yaml:"jwks_url" env:"JWT_JWKS_URL"`
+	Audience             string `yaml:"audience" env:"JWT_AUDIENCE"`
+	TenantClaim          string `yaml:"tenant_claim" env:"TENANT_CLAIM"`
+	BackendRSAPrivateKey string `yaml:"backend_rs256_private_key" env:"JWT_BACKEND_RS256_PRIVATE_KEY" secret:"true"`
+	BackendKeyID         string `yaml:"backend_key_id" env:"JWT_BACKEND_KEY_ID"`
+	WorkOSAPIKey         string `yaml:"workos_api_key" env:"WORKOS_API_KEY" secret:"true"`
+	DefaultTenantID      string `yaml:"default_tenant_id" env:"DEFAULT_TENANT_ID"`
+	MockIdP              bool   `yaml:"mock_idp" env:"MOCK_IDP"` // Serve a mock OIDC issuer at MockIdPPath (dev mode only)
+}
+
+// MockIdPPath is where the mock IdP is mounted; its issuer is the server's URL plus this path
+const MockIdPPath = "/dev/idp"
+
+// MockIdPIssuer returns the mock IdP's issuer for a server reached at baseURL
+// (e.g. http://localhost:8080)
+func MockIdPIssuer(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + MockIdPPath
+}
+
+// MCPConfig configures tokens issued to the MCP server
+type MCPConfig struct {
+	OAuthAudience string `yaml:"oauth_audience" env:"MCP_OAUTH_AUDIENCE"` // Extra accepted JWT audience
+}
+
+// RateLimitConfig holds the per-user limits for sync/REST and auth endpoints
+type RateLimitConfig struct {
+	Sync RateLimitValues `yaml:"sync" env:"RATE_LIMIT_SYNC"`
+	Auth RateLimitValues `yaml:"auth" env:"RATE_LIMIT_AUTH"`
+}
+
+// RateLimitValues is one token bucket (env names are prefixed by the parent's env tag)
+type RateLimitValues struct {
+	WindowSeconds int `yaml:"window_seconds" env:"WINDOW_SECONDS"`
+	MaxRequests   int `yaml:"max_requests" env:"MAX_REQUESTS"`
+	Burst         int `yaml:"burst" env:"BURST"`
+}
+
+// Default rate limits (httpapi also falls back to these for unset limits)
+var (
+	DefaultSyncRateLimit = RateLimitValues{
+		WindowSeconds: 60,  // 1 minute window
+		MaxRequests:   600, // 600 requests per window (sustained rate)
+		Burst:         120, // Allow burst of 120 requests
+	}
+	// Stricter: token exchange, tenant resolution and session creation are
+	// brute-force and abuse targets
+	DefaultAuthRateLimit = RateLimitValues{
+		WindowSeconds: 60, // 1 minute window
+		MaxRequests:   60, // 60 auth requests per minute per client
+		Burst:         20, // Small burst allowance
+	}
+)
+
+// TracingConfig configures OpenTelemetry (the exporter itself reads the standard OTEL_EXPORTER_OTLP_* env vars)
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" env:"TRACING_ENABLED"` // Also enabled by OTEL_EXPORTER_OTLP_ENDPOINT
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// SentryConfig configures error reporting
+type SentryConfig struct {
+	DSN         string  `yaml:"dsn" env:"SENTRY_DSN" secret:"true"`
+	Environment string  `yaml:"environment" env:"SENTRY_ENVIRONMENT"` // Defaults to Env
+	Release     string  `yaml:"release" env:"SENTRY_RELEASE"`         // Defaults to the build version (set by cmd/server)
+	SampleRate  float64 `yaml:"sample_rate" env:"SENTRY_SAMPLE_RATE"`
+}
+
+// JobsConfig configures background jobs
+type JobsConfig struct {
+	AnalyticsFlushInterval time.Duration `yaml:"analytics_flush_interval" env:"ANALYTICS_FLUSH_INTERVAL"`
+	IntegrityCheckInterval time.Duration `yaml:"integrity_check_interval" env:"INTEGRITY_CHECK_INTERVAL"`
+	LeaderRetryInterval    time.Duration `yaml:"leader_retry_interval" env:"JOB_LEADER_RETRY_INTERVAL"`
+	OrphanPolicy           string        `yaml:"orphan_policy" env:"ORPHAN_POLICY"`     // report|repair
+	TrashRetention         time.Duration `yaml:"trash_retention" env:"TRASH_RETENTION"` // Deleted items are purged after this (0 keeps them)
+}
+
+// EventStreamConfig configures the optional change event stream
+type EventStreamConfig struct {
+	Backend string `yaml:"backend" env:"EVENT_STREAM"` // nats|kafka ("" disables)
+	URL     string `yaml:"url" env:"EVENT_STREAM_URL" secret:"true"`
+	Subject string `yaml:"subject" env:"EVENT_STREAM_SUBJECT"`
+}
+
+// NotifyConfig configures email notifications
+type NotifyConfig struct {
+	SMTPAddr     string `yaml:"smtp_addr" env:"NOTIFY_SMTP_ADDR"`
+	SMTPUsername string `yaml:"smtp_username" env:"NOTIFY_SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"NOTIFY_SMTP_PASSWORD" secret:"true"`
+	SESRegion    string `yaml:"ses_region" env:"NOTIFY_SES_REGION"`
+	From         string `yaml:"from" env:"NOTIFY_FROM"`
+}
+
+// IntegrationsConfig configures third-party integrations
+type IntegrationsConfig struct {
+	SlackSigningSecret string `yaml:"slack_signing_secret" env:"SLACK_SIGNING_SECRET" secret:"true"`
+}
+
+// AdminConfig configures the operator endpoints
+type AdminConfig struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN" secret:"true"` // Empty disables /admin
+}
+
+// ExportConfig configures account exports
+type ExportConfig struct {
+	SigningKey string `yaml:"signing_key" env:"EXPORT_SIGNING_KEY" secret:"true"` // Defaults to the HS256 secret
+}
+
+// CORSConfig configures browser access
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // Comma-separated in env; "*" allows any
+}
+
+// MaintenanceConfig turns away API traffic with 503 (health, metrics, /admin and /v1/sync/info keep working)
+type MaintenanceConfig struct {
+	Enabled bool   `yaml:"enabled" env:"MAINTENANCE_MODE"`
+	Message string `yaml:"message" env:"MAINTENANCE_MESSAGE"`
+}
+
+// SyncConfig configures push validation (reloadable) and pull cursor signing
+type SyncConfig struct {
+	MaxClockSkew     time.Duration `yaml:"max_clock_skew" env:"SYNC_MAX_CLOCK_SKEW"`       // Reject items stamped further ahead of server time (0 disables)
+	CursorKey        string        `yaml:"cursor_key" env:"SYNC_CURSOR_KEY" secret:"true"` // HMAC key for pull cursors; defaults to the HS256 secret (startup only)
+	ServerTimestamps bool          `yaml:"server_timestamps" env:"SYNC_SERVER_TIMESTAMPS"` // Server assigns updated_at_ms on push (last push wins)
+}
+
+// SecretsConfig configures secret references
+// Any secret field may be a reference instead of a value: file:/path (also set
+// by NAME_FILE variables) or vault:<path>#<key>. References are re-fetched every
+// RefreshInterval and rotated values applied where supported.
+type SecretsConfig struct {
+	VaultAddr       string        `yaml:"vault_addr" env:"VAULT_ADDR"`
+	VaultToken      string        `yaml:"vault_token" env:"VAULT_TOKEN" secret:"true"`
+	VaultNamespace  string        `yaml:"vault_namespace" env:"VAULT_NAMESPACE"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL"` // 0 disables rotation checks
+}
+
+// DefaultHS256Secret is the insecure placeholder secret (rejected outside dev mode)
+const DefaultHS256Secret = "dev-secret-change-in-production"
+
+// Default returns the built-in defaults
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:                 ":8080",
+			IdleTimeout:          120 * time.Second,
+			MaxConcurrentStreams: 250,
+		},
+		GRPC:    GRPCConfig{Addr: ":8082"},
+		Session: SessionConfig{Validation: session.ValidationStandard},
+		Database: DatabaseConfig{
+			StatementCache:     db.StatementCachePrepare,
+			StatementCacheSize: db.DefaultStatementCacheSize,
+			PrepareHotQueries:  true,
+		},
+		Auth: AuthConfig{
+			HS256Secret:     DefaultHS256Secret,
+			DefaultTenantID: "tenant_thinkpen_b2c",
+		},
+		RateLimit: RateLimitConfig{
+			Sync: DefaultSyncRateLimit,
+			Auth: DefaultAuthRateLimit,
+		},
+		Tracing: TracingConfig{ServiceName: "toolbridge-api", SampleRatio: 1},
+		Sentry:  SentryConfig{SampleRate: 1},
+		Jobs: JobsConfig{
+			AnalyticsFlushInterval: time.Minute,
+			IntegrityCheckInterval: time.Hour,
+			LeaderRetryInterval:    15 * time.Second,
+			OrphanPolicy:           syncservice.OrphanPolicyReport,
+			TrashRetention:         30 * 24 * time.Hour,
+		},
+		EventStream: EventStreamConfig{Subject: "toolbridge.changes"},
+		Notify:      NotifyConfig{From: "ToolBridge <no-reply@toolbridge.local>"},
+		Sync:        SyncConfig{MaxClockSkew: syncx.DefaultMaxClockSkew},
+		Secrets:     SecretsConfig{RefreshInterval: 5 * time.Minute},
+	}
+}
+
+// Profile returns the profile selected by ENV (staging when unrecognized)
+func (c *Config) Profile() Profile {
+	p, _ := LookupProfile(c.Env)
+	return p
+}
+
+// DevMode reports whether ENV selects the dev profile
+// Secure by default: unset or misspelled values run as non-dev.
+func (c *Config) DevMode() bool {
+	return c.Profile().DevMode
+}
+
+// Load builds the effective configuration: defaults, then path (when
+// non-empty), then environment overrides
+// Every unparsable value is reported, not just the first.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := loadConfigFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	var errs []error
+	applyEnv(reflect.ValueOf(cfg).Elem(), "", &errs)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	if !cfg.Tracing.Enabled && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+		cfg.Tracing.Enabled = true
+	}
+	if cfg.Sentry.Environment == "" {
+		cfg.Sentry.Environment = cfg.Env
+	}
+	if cfg.Export.SigningKey == "" {
+		cfg.Export.SigningKey = cfg.Auth.HS256Secret
+	}
+	if cfg.Sync.CursorKey == "" {
+		cfg.Sync.CursorKey = cfg.Auth.HS256Secret
+	}
+	prof := cfg.Profile()
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = prof.LogFormat
+	}
+	if cfg.Session.Store == "" {
+		cfg.Session.Store = prof.SessionStore
+	}
+	// The mock IdP validates through the regular issuer/JWKS settings; by default
+	// they point at this server on localhost
+	if cfg.Auth.MockIdP && prof.DevMode {
+		if cfg.Auth.Issuer == "" {
+			cfg.Auth.Issuer = MockIdPIssuer(localURL(cfg.HTTP.Addr))
+		}
+		if cfg.Auth.JWKSURL == "" {
+			cfg.Auth.JWKSURL = cfg.Auth.Issuer + "/jwks"
+		}
+	}
+	return cfg, nil
+}
+
+// localURL returns the http:// URL of a listener on addr as seen from this host
+func localURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://localhost:8080"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// loadConfigFile decodes a YAML (.yaml, .yml) or TOML (.toml) file into cfg
+// Unknown keys are errors, so typos don't silently fall back to defaults.
+func loadConfigFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		tree, err := parseTOML(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(tree); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported extension (want .yaml, .yml or .toml)", path)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides fields from their env tags, recursing into structs
+// A struct's env tag prefixes its fields' variables (RATE_LIMIT_SYNC_BURST).
+// Secret fields also read NAME_FILE, which becomes a file: reference.
+func applyEnv(v reflect.Value, prefix string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		name := f.Tag.Get("env")
+		if prefix != "" && name != "" {
+			name = prefix + "_" + name
+		}
+		if f.Type.Kind() == reflect.Struct {
+			applyEnv(fv, name, errs)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		raw := os.Getenv(name)
+		if path := os.Getenv(name + "_FILE"); path != "" && f.Tag.Get("secret") == "true" {
+			if raw != "" {
+				*errs = append(*errs, fmt.Errorf("%s: set %s or %s_FILE, not both", name, name, name))
+				continue
+			}
+			raw = "file:" + path
+		}
+		if raw == "" {
+			continue
+		}
+		if err := setField(fv, raw); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+}
+
+// resolveSecrets replaces secret references with their values
+// The Vault token is resolved first (it may itself be a file: reference), then
+// every other secret field; failures are reported together.
+func (c *Config) resolveSecrets() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c.resolver = secrets.NewResolver()
+	c.secretRefs = map[string]string{}
+	resolve := func(name string, v reflect.Value) error {
+		ref := v.String()
+		if !c.resolver.IsRef(ref) {
+			return nil
+		}
+		value, err := c.resolver.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		v.SetString(value)
+		c.secretRefs[name] = ref
+		return nil
+	}
+
+	if err := resolve("VAULT_TOKEN", reflect.ValueOf(&c.Secrets.VaultToken).Elem()); err != nil {
+		return err
+	}
+	if c.Secrets.VaultAddr != "" {
+		c.resolver.Register("vault", secrets.NewVault(c.Secrets.VaultAddr, c.Secrets.VaultToken, c.Secrets.VaultNamespace))
+	}
+
+	var errs []error
+	walkSecrets(reflect.ValueOf(c).Elem(), "", func(name string, v reflect.Value) {
+		if name == "VAULT_TOKEN" {
+			return
+		}
+		if err := resolve(name, v); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walkSecrets calls fn for every secret field with its env var name
+func walkSecrets(v reflect.Value, prefix string, fn func(name string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		name := f.Tag.Get("env")
+		if prefix != "" && name != "" {
+			name = prefix + "_" + name
+		}
+		switch {
+		case f.Type.Kind() == reflect.Struct:
+			walkSecrets(fv, name, fn)
+		case f.Tag.Get("secret") == "true":
+			fn(name, fv)
+		}
+	}
+}
+
+// SecretRefs returns the references secrets were loaded from, by setting name
+// (env var)
+func (c *Config) SecretRefs() map[string]string {
+	return maps.Clone(c.secretRefs)
+}
+
+// SecretWatcher returns a watcher for the secrets loaded by reference
+func (c *Config) SecretWatcher() *secrets.Watcher {
+	w := secrets.NewWatcher(c.resolver)
+	for name, ref := range c.secretRefs {
+		w.Watch(name, ref, secretValue(c, name))
+	}
+	return w
+}
+
+// secretValue returns the resolved value of the secret field named by its env var
+func secretValue(c *Config, name string) string {
+	var value string
+	walkSecrets(reflect.ValueOf(c).Elem(), "", func(n string, v reflect.Value) {
+		if n == name {
+			value = v.String()
+		}
+	})
+	return value
+}
+
+// setField parses raw into a string, bool, int, float or duration field,
+// a comma-separated string list, or a comma-separated flag set ("a,b=false")
+func setField(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == reflect.TypeOf([]string(nil)):
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	case v.Type() == reflect.TypeOf(map[string]bool(nil)):
+		flags := map[string]bool{}
+		for _, item := range strings.Split(raw, ",") {
+			name, value, hasValue := strings.Cut(strings.TrimSpace(item), "=")
+			if name == "" {
+				continue
+			}
+			on := true
+			if hasValue {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("invalid boolean %q for %s", value, name)
+				}
+				on = b
+			}
+			flags[name] = on
+		}
+		v.Set(reflect.ValueOf(flags))
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// RestartRequired lists the sections (by config file key) that differ between
+// the running and reloaded configuration but are only read at startup
+func RestartRequired(running, next *Config) []string {
+	a, b := *running, *next
+	for _, c := range []*Config{&a, &b} {
+		c.LogLevel = ""
+		c.RateLimit = RateLimitConfig{}
+		c.CORS = CORSConfig{}
+		c.Features = nil
+		c.Maintenance = MaintenanceConfig{}
+		c.Sync.MaxClockSkew = 0
+		c.Sync.ServerTimestamps = false
+	}
+	var changed []string
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < av.NumField(); i++ {
+		if !av.Type().Field(i).IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(av.Field(i).Interface(), bv.Field(i).Interface()) {
+			changed = append(changed, av.Type().Field(i).Tag.Get("yaml"))
+		}
+	}
+	return changed
+}
+
+// Redacted returns a copy with every non-empty secret field replaced
+// Secrets loaded by reference show the reference instead.
+func (c *Config) Redacted() *Config {
+	out := *c
+	redact(reflect.ValueOf(&out).Elem())
+	walkSecrets(reflect.ValueOf(&out).Elem(), "", func(name string, v reflect.Value) {
+		if ref, ok := c.secretRefs[name]; ok {
+			v.SetString(ref)
+		}
+	})
+	return &out
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		switch {
+		case f.Type.Kind() == reflect.Struct:
+			redact(fv)
+		case f.Tag.Get("secret") == "true" && fv.String() != "":
+			fv.SetString("[redacted]")
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func mustLoad(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+env: staging
+http:
+  addr: ":9000"
+database:
+  url: postgres://file@db/toolbridge
+rate_limit:
+  sync:
+    burst: 50
+jobs:
+  integrity_check_interval: 30m
+`)
+	t.Setenv("DATABASE_URL", "postgres://env@db/toolbridge")
+	t.Setenv("RATE_LIMIT_SYNC_MAX_REQUESTS", "100")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Env != "staging" || cfg.HTTP.Addr != ":9000" {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.Database.URL != "postgres://env@db/toolbridge" {
+		t.Errorf("env must override file: %q", cfg.Database.URL)
+	}
+	if got := cfg.RateLimit.Sync; got.Burst != 50 || got.MaxRequests != 100 || got.WindowSeconds != 60 {
+		t.Errorf("rate limit = %+v, want file burst, env max and default window", got)
+	}
+	if cfg.Jobs.IntegrityCheckInterval != 30*time.Minute || cfg.Jobs.LeaderRetryInterval != 15*time.Second {
+		t.Errorf("jobs = %+v", cfg.Jobs)
+	}
+	if cfg.Export.SigningKey != cfg.Auth.HS256Secret || cfg.Sentry.Environment != "staging" {
+		t.Errorf("derived defaults not applied: export=%q sentry=%q", cfg.Export.SigningKey, cfg.Sentry.Environment)
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+env = "prod"  # inline comment
+log_level = 'debug'
+
+[auth]
+issuer = "https://auth.example.com"
+jwks_url = "https://auth.example.com/#/jwks"
+
+[rate_limit.auth]
+max_requests = 1_000
+
+[tracing]
+enabled = true
+sample_ratio = 0.25
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Env != "prod" || cfg.LogLevel != "debug" || cfg.Auth.JWKSURL != "https://auth.example.com/#/jwks" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.RateLimit.Auth.MaxRequests != 1000 || !cfg.Tracing.Enabled || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("rate limit %+v tracing %+v", cfg.RateLimit.Auth, cfg.Tracing)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	// Unknown keys are rejected so typos don't silently fall back to defaults
+	if _, err := Load(writeConfigFile(t, "c.yaml", "htp:\n  addr: x\n")); err == nil {
+		t.Error("unknown key accepted")
+	}
+	if _, err := Load(writeConfigFile(t, "c.json", "{}")); err == nil {
+		t.Error("unsupported extension accepted")
+	}
+
+	// Every bad variable is reported at once
+	t.Setenv("TRACING_SAMPLE_RATIO", "half")
+	t.Setenv("ANALYTICS_FLUSH_INTERVAL", "60")
+	_, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "TRACING_SAMPLE_RATIO") || !strings.Contains(err.Error(), "ANALYTICS_FLUSH_INTERVAL") {
+		t.Errorf("err = %v, want both variables reported", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.URL = "postgres://user:pw@db/toolbridge"
+	cfg.Admin.Token = ""
+	red := cfg.Redacted()
+	if red.Database.URL != "[redacted]" || red.Auth.HS256Secret != "[redacted]" {
+		t.Errorf("secrets not redacted: %+v", red)
+	}
+	if red.Admin.Token != "" || red.HTTP.Addr != ":8080" {
+		t.Errorf("empty secrets and plain values must be kept: %+v", red)
+	}
+	if cfg.Database.URL == "[redacted]" {
+		t.Error("redacted modified the original")
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, src := range []string{
+		"key",
+		"[a\nx = 1",
+		"x = 1\nx = 2",
+		`x = """multi"""`,
+		"x = 2024-01-01",
+		"x = 1\n[x]",
+	} {
+		if _, err := parseTOML([]byte(src)); err == nil {
+			t.Errorf("parseTOML(%q) accepted", src)
+		}
+	}
+}
+
+func TestLoadConfigReloadableSettings(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+cors:
+  allowed_origins: ["https://app.example.com"]
+features:
+  shared_lists: true
+maintenance:
+  message: back soon
+`)
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com,")
+	t.Setenv("FEATURE_FLAGS", "beta_search, shared_lists=false")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origins := cfg.CORS.AllowedOrigins; len(origins) != 2 || origins[1] != "https://b.example.com" {
+		t.Errorf("cors origins = %q", origins)
+	}
+	if !cfg.Features["beta_search"] || cfg.Features["shared_lists"] || cfg.Maintenance.Message != "back soon" {
+		t.Errorf("features = %v, maintenance = %+v", cfg.Features, cfg.Maintenance)
+	}
+
+	t.Setenv("FEATURE_FLAGS", "x=maybe")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "FEATURE_FLAGS") {
+		t.Errorf("err = %v, want invalid FEATURE_FLAGS", err)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	running := Default()
+	next := Default()
+	next.LogLevel = "debug"
+	next.RateLimit.Sync.Burst = 1
+	next.Maintenance.Enabled = true
+	next.Features = map[string]bool{"beta": true}
+	if got := RestartRequired(running, next); len(got) != 0 {
+		t.Errorf("reloadable-only changes reported: %v", got)
+	}
+
+	next.HTTP.Addr = ":9000"
+	next.Database.URL = "postgres://other"
+	if got := RestartRequired(running, next); strings.Join(got, ",") != "http,database" {
+		t.Errorf("restartRequired = %v, want [http database]", got)
+	}
+}
+
+func TestLoadConfigSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	jwtFile := filepath.Join(dir, "jwt")
+	if err := os.WriteFile(jwtFile, []byte("from-file-0123456789abcdef0123456789\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_HS256_SECRET_FILE", jwtFile)
+	t.Setenv("ADMIN_TOKEN", "file:"+jwtFile)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.HS256Secret != "from-file-0123456789abcdef0123456789" || cfg.Admin.Token != cfg.Auth.HS256Secret {
+		t.Errorf("secrets = %q / %q", cfg.Auth.HS256Secret, cfg.Admin.Token)
+	}
+	if red := cfg.Redacted(); red.Auth.HS256Secret != "file:"+jwtFile {
+		t.Errorf("redacted reference = %q", red.Auth.HS256Secret)
+	}
+	if names := cfg.SecretWatcher().Names(); len(names) != 2 {
+		t.Errorf("watched = %v", names)
+	}
+
+	t.Setenv("JWT_HS256_SECRET", "inline")
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "JWT_HS256_SECRET_FILE") {
+		t.Errorf("err = %v, want both-set error", err)
+	}
+	t.Setenv("JWT_HS256_SECRET", "")
+	t.Setenv("JWT_HS256_SECRET_FILE", filepath.Join(dir, "missing"))
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "JWT_HS256_SECRET") {
+		t.Errorf("err = %v, want missing file error", err)
+	}
+}
+
+func TestLoadConfigMockIdP(t *testing.T) {
+	t.Setenv("ENV", "dev")
+	t.Setenv("MOCK_IDP", "true")
+	t.Setenv("HTTP_ADDR", ":9090")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.Issuer != "http://localhost:9090/dev/idp" || cfg.Auth.JWKSURL != "http://localhost:9090/dev/idp/jwks" {
+		t.Errorf("issuer = %q, jwks = %q", cfg.Auth.Issuer, cfg.Auth.JWKSURL)
+	}
+
+	// An explicit issuer (e.g. the host name other containers use) is kept
+	t.Setenv("JWT_ISSUER", "http://api:8080/dev/idp")
+	if cfg, _ = Load(""); cfg.Auth.Issuer != "http://api:8080/dev/idp" || cfg.Auth.JWKSURL != "http://api:8080/dev/idp/jwks" {
+		t.Errorf("issuer = %q, jwks = %q", cfg.Auth.Issuer, cfg.Auth.JWKSURL)
+	}
+
+	// Never outside dev mode
+	t.Setenv("ENV", "staging")
+	t.Setenv("JWT_ISSUER", "")
+	if cfg, _ = Load(""); cfg.Auth.Issuer != "" {
+		t.Errorf("staging issuer = %q, want unset", cfg.Auth.Issuer)
+	}
+}
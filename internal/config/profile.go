@@ -0,0 +1,54 @@
+package config
+
+import "strings"
+
+// Session stores (SESSION_STORE)
+const (
+	SessionStoreMemory   = "memory"
+	SessionStorePostgres = "postgres"
+)
+
+// Log formats (LOG_FORMAT)
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// Profile bundles the defaults and guard rails selected by ENV
+// Settings left empty in the file and environment (LOG_FORMAT, SESSION_STORE)
+// take the profile's value; DevMode and Strict can't be overridden.
+type Profile struct {
+	Name         string // Canonical ENV value
+	DevMode      bool   // X-Debug-Sub auth, http:// webhooks and OIDC URLs, logged emails, default HS256 secret
+	LogFormat    string
+	SessionStore string
+	Strict       bool // Security warnings (short secrets, http:// URLs, CORS *) are errors
+}
+
+var profiles = map[string]Profile{
+	"dev":     {Name: "dev", DevMode: true, LogFormat: LogFormatConsole, SessionStore: SessionStoreMemory},
+	"staging": {Name: "staging", LogFormat: LogFormatJSON, SessionStore: SessionStorePostgres},
+	"prod":    {Name: "prod", LogFormat: LogFormatJSON, SessionStore: SessionStorePostgres, Strict: true},
+}
+
+// profileAliases are other spellings accepted for ENV
+var profileAliases = map[string]string{
+	"development": "dev",
+	"stage":       "staging",
+	"production":  "prod",
+	"prd":         "prod",
+}
+
+// LookupProfile returns the profile for an ENV value (case-insensitive)
+// Secure by default: unset or unrecognized values get the staging profile
+// (never dev mode) and ok=false.
+func LookupProfile(env string) (p Profile, ok bool) {
+	name := strings.ToLower(strings.TrimSpace(env))
+	if alias, isAlias := profileAliases[name]; isAlias {
+		name = alias
+	}
+	if p, ok = profiles[name]; ok {
+		return p, true
+	}
+	return profiles["staging"], false
+}
@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestProfiles(t *testing.T) {
+	for env, want := range map[string]Profile{
+		"dev":        profiles["dev"],
+		"Production": profiles["prod"],
+		"prd":        profiles["prod"],
+		"staging":    profiles["staging"],
+		"":           profiles["staging"],
+		"devv":       profiles["staging"], // Misspelled dev never enables dev mode
+	} {
+		t.Setenv("ENV", env)
+		cfg := mustLoad(t)
+		if got := cfg.Profile(); got != want {
+			t.Errorf("ENV=%q Profile = %+v, want %+v", env, got, want)
+		}
+		if cfg.LogFormat != want.LogFormat || cfg.Session.Store != want.SessionStore {
+			t.Errorf("ENV=%q defaults log_format=%q session=%q", env, cfg.LogFormat, cfg.Session.Store)
+		}
+	}
+
+	// Explicit settings win over the Profile
+	t.Setenv("ENV", "dev")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("SESSION_STORE", "postgres")
+	if cfg := mustLoad(t); cfg.LogFormat != "json" || cfg.Session.Store != "postgres" || !cfg.DevMode() {
+		t.Errorf("overrides: log_format=%q session=%q dev=%t", cfg.LogFormat, cfg.Session.Store, cfg.DevMode())
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the TOML subset config files need into nested maps:
+// [table] and [table.sub] headers, key = value pairs, # comments, and values
+// that are basic or literal strings, integers, floats, booleans, or
+// single-line arrays of those. Multi-line strings, inline tables and dates
+// are rejected.
+func parseTOML(data []byte) (map[string]any, error) {
+	root := map[string]any{}
+	table := root
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(stripTOMLComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header", lineNo)
+			}
+			table = root
+			for _, part := range strings.Split(line[1:len(line)-1], ".") {
+				key := strings.TrimSpace(part)
+				if key == "" {
+					return nil, fmt.Errorf("line %d: invalid table header", lineNo)
+				}
+				next, ok := table[key].(map[string]any)
+				if !ok {
+					if _, exists := table[key]; exists {
+						return nil, fmt.Errorf("line %d: %s is not a table", lineNo, key)
+					}
+					next = map[string]any{}
+					table[key] = next
+				}
+				table = next
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		if _, exists := table[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNo, key)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNo, key, err)
+		}
+		table[key] = value
+	}
+	return root, nil
+}
+
+// stripTOMLComment removes a trailing # comment that isn't inside a string
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseTOMLValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''"):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case raw[0] == '"':
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string")
+		}
+		return s, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' || strings.Contains(raw[1:len(raw)-1], "'") {
+			return nil, fmt.Errorf("invalid literal string")
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw[0] == '[':
+		if raw[len(raw)-1] != ']' {
+			return nil, fmt.Errorf("arrays must be on one line")
+		}
+		items := []any{}
+		for _, part := range splitTOMLArray(raw[1 : len(raw)-1]) {
+			if part = strings.TrimSpace(part); part == "" {
+				continue // Trailing comma
+			}
+			v, err := parseTOMLValue(part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	}
+	num := strings.ReplaceAll(raw, "_", "")
+	if n, err := strconv.ParseInt(num, 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(num, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", raw)
+}
+
+// splitTOMLArray splits array contents on commas outside strings
+func splitTOMLArray(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
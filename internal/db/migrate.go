@@ -0,0 +1,353 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Migration file conventions (migrations/):
+//   - NNNN_name.sql is applied by `migrate up`, in file name order
+//   - NNNN_name.down.sql, if present, reverts it for `migrate down`
+//   - A "-- migrate:no-transaction" line runs the file statement by statement
+//     outside a transaction (required for CREATE INDEX CONCURRENTLY)
+//
+// Applied migrations are recorded in schema_migrations by file name, the same
+// table scripts/migrate.sh uses, so either runner can take over from the other.
+
+const (
+	downSuffix    = ".down.sql"
+	noTxDirective = "-- migrate:no-transaction"
+
+	// migrateLockKey serializes runners across replicas (pg_advisory_lock)
+	migrateLockKey = 0x7462_6d69_6772 // "tbmigr"
+)
+
+// MigrationStatus is one migration file and whether it has been applied
+type MigrationStatus struct {
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	HasDown   bool       `json:"hasDown"`
+}
+
+// Migrator applies the migration files in FS to a database
+type Migrator struct {
+	Pool *pgxpool.Pool
+	FS   fs.FS // Usually migrations.FS
+}
+
+// NewMigrator creates a migrator for the given files
+func NewMigrator(pool *pgxpool.Pool, files fs.FS) *Migrator {
+	return &Migrator{Pool: pool, FS: files}
+}
+
+// migrationFiles lists up migrations (sorted) and the set of available down files
+func migrationFiles(files fs.FS) ([]string, map[string]bool, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ups []string
+	downs := make(map[string]bool)
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case e.IsDir() || path.Ext(name) != ".sql":
+		case strings.HasSuffix(name, downSuffix):
+			downs[strings.TrimSuffix(name, downSuffix)+".sql"] = true
+		default:
+			ups = append(ups, name)
+		}
+	}
+	sort.Strings(ups)
+	return ups, downs, nil
+}
+
+// withLock runs fn on one connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrateLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockKey)
+	}()
+
+	if _, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id SERIAL PRIMARY KEY,
+			migration VARCHAR(255) UNIQUE NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func applied(ctx context.Context, conn *pgxpool.Conn) (map[string]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT migration, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var at time.Time
+		if err := rows.Scan(&name, &at); err != nil {
+			return nil, err
+		}
+		out[name] = at
+	}
+	return out, rows.Err()
+}
+
+// Status reports every migration file and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	ups, downs, err := migrationFiles(m.FS)
+	if err != nil {
+		return nil, err
+	}
+	var out []MigrationStatus
+	err = m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, name := range ups {
+			st := MigrationStatus{Name: name, HasDown: downs[name]}
+			if at, ok := done[name]; ok {
+				st.Applied, st.AppliedAt = true, &at
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Up applies all pending migrations in order and returns the names applied
+// Stops at the first failure; earlier migrations in the run stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]string, error) {
+	ups, _, err := migrationFiles(m.FS)
+	if err != nil {
+		return nil, err
+	}
+	var ran []string
+	err = m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, name := range ups {
+			if _, ok := done[name]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, name, `INSERT INTO schema_migrations (migration) VALUES ($1)`); err != nil {
+				return fmt.Errorf("apply %s: %w", name, err)
+			}
+			log.Info().Str("migration", name).Msg("migration applied")
+			ran = append(ran, name)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down reverts the last steps applied migrations (newest first) and returns their names
+// Fails before changing anything if one of them has no .down.sql file.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]string, error) {
+	ups, downs, err := migrationFiles(m.FS)
+	if err != nil {
+		return nil, err
+	}
+	var ran []string
+	err = m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		var targets []string
+		for i := len(ups) - 1; i >= 0 && len(targets) < steps; i-- {
+			if _, ok := done[ups[i]]; ok {
+				targets = append(targets, ups[i])
+			}
+		}
+		for _, name := range targets {
+			if !downs[name] {
+				return fmt.Errorf("%s has no %s file", name, strings.TrimSuffix(name, ".sql")+downSuffix)
+			}
+		}
+		for _, name := range targets {
+			down := strings.TrimSuffix(name, ".sql") + downSuffix
+			if err := m.applyFile(ctx, conn, down, name, `DELETE FROM schema_migrations WHERE migration = $1`); err != nil {
+				return fmt.Errorf("revert %s: %w", name, err)
+			}
+			log.Info().Str("migration", name).Msg("migration reverted")
+			ran = append(ran, name)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, name, record string) error {
+	return m.applyFile(ctx, conn, name, name, record)
+}
+
+// applyFile runs one file and then record (with the migration name) to update schema_migrations
+func (m *Migrator) applyFile(ctx context.Context, conn *pgxpool.Conn, file, name, record string) error {
+	body, err := fs.ReadFile(m.FS, file)
+	if err != nil {
+		return err
+	}
+	sql := string(body)
+
+	if !hasNoTxDirective(sql) {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, sql); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, record, name)
+			return err
+		})
+	}
+
+	// No transaction: statements run one by one (autocommit), like psql < file
+	for _, stmt := range SplitStatements(sql) {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Exec(ctx, record, name)
+	return err
+}
+
+func hasNoTxDirective(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.TrimSpace(line) == noTxDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitStatements splits a SQL script on top-level semicolons
+// Semicolons inside quotes, dollar-quoted bodies ($$ ... $$) and comments are
+// ignored. Statements that are empty or contain only comments are dropped.
+func SplitStatements(sql string) []string {
+	var out []string
+	var cur strings.Builder
+	hasCode := false
+
+	flush := func() {
+		if hasCode {
+			out = append(out, strings.TrimSpace(cur.String()))
+		}
+		cur.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			cur.WriteString(sql[i : i+end])
+			i += end
+
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			} else {
+				end += 2
+			}
+			cur.WriteString(sql[i : i+2+end])
+			i += 2 + end
+
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == c {
+					if end+1 < len(sql) && sql[end+1] == c { // Escaped quote ('' or "")
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(sql))
+			cur.WriteString(sql[i:end])
+			hasCode = true
+			i = end
+
+		case c == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				cur.WriteByte(c)
+				hasCode = true
+				i++
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - len(tag)
+			} else {
+				end += len(tag)
+			}
+			cur.WriteString(sql[i : i+len(tag)+end])
+			hasCode = true
+			i += len(tag) + end
+
+		case c == ';':
+			cur.WriteByte(c)
+			flush()
+			i++
+
+		default:
+			cur.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	flush()
+	return out
+}
+
+// dollarTag returns the opening dollar-quote tag ($$ or $name$) at the start of s, or ""
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '$':
+			return s[:j+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9':
+		default:
+			return "" // Not a tag (e.g. a $1 parameter)
+		}
+	}
+	return ""
+}
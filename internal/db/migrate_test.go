@@ -0,0 +1,74 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/erauner12/toolbridge-api/migrations"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"0002_b.sql":      {Data: []byte("SELECT 2;")},
+		"0001_a.sql":      {Data: []byte("SELECT 1;")},
+		"0001_a.down.sql": {Data: []byte("SELECT -1;")},
+		"README.md":       {Data: []byte("not a migration")},
+	}
+	ups, downs, err := migrationFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0001_a.sql", "0002_b.sql"}; !reflect.DeepEqual(ups, want) {
+		t.Errorf("ups = %v, want %v", ups, want)
+	}
+	if !downs["0001_a.sql"] || downs["0002_b.sql"] {
+		t.Errorf("downs = %v, want only 0001_a.sql", downs)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ups, _, err := migrationFiles(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ups) == 0 || ups[0] != "0001_init.sql" {
+		t.Fatalf("embedded migrations = %v, want 0001_init.sql first", ups)
+	}
+
+	// Files with CONCURRENTLY must opt out of the wrapping transaction
+	body, err := migrations.FS.ReadFile("0003_task_parent_uid.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasNoTxDirective(string(body)) {
+		t.Error("0003_task_parent_uid.sql is missing the no-transaction directive")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- leading comment
+CREATE TABLE a (note TEXT DEFAULT 'x;y');
+/* block; comment */
+CREATE FUNCTION f() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = NOW();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+SELECT $1, "odd;name" FROM a; -- trailing comment
+`
+	got := SplitStatements(sql)
+	if len(got) != 3 {
+		t.Fatalf("got %d statements, want 3: %q", len(got), got)
+	}
+	if want := "-- leading comment\nCREATE TABLE a (note TEXT DEFAULT 'x;y');"; got[0] != want {
+		t.Errorf("stmt 0 = %q, want %q", got[0], want)
+	}
+	if want := "$$ LANGUAGE plpgsql;"; len(got[1]) < len(want) || got[1][len(got[1])-len(want):] != want {
+		t.Errorf("stmt 1 = %q, want dollar-quoted body kept intact", got[1])
+	}
+	if want := `SELECT $1, "odd;name" FROM a;`; got[2] != want {
+		t.Errorf("stmt 2 = %q, want %q", got[2], want)
+	}
+}
@@ -1,20 +1,43 @@
 package db
 
 import (
+	"cmp"
 	"context"
 	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
 
-// Open creates a new PostgreSQL connection pool
+// Open creates a new PostgreSQL connection pool with default statement caching
 func Open(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	return OpenWithOptions(ctx, url, PoolOptions{})
+}
+
+// OpenWithOptions creates a new PostgreSQL connection pool tuned by opts
+func OpenWithOptions(ctx context.Context, url string, opts PoolOptions) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, err
 	}
 
+	// Statement caching: prepared statements skip re-parsing and let Postgres
+	// reuse a generic plan; describe/off exist for poolers that can't keep them
+	mode, err := opts.execMode()
+	if err != nil {
+		return nil, err
+	}
+	cacheSize := cmp.Or(opts.StatementCacheSize, DefaultStatementCacheSize)
+	cfg.ConnConfig.DefaultQueryExecMode = mode
+	cfg.ConnConfig.StatementCacheCapacity = cacheSize
+	cfg.ConnConfig.DescriptionCacheCapacity = cacheSize
+	prepareHotStatements := opts.PrepareHot && mode == pgx.QueryExecModeCacheStatement
+	if prepareHotStatements {
+		cfg.AfterConnect = prepareHot
+	}
+
 	// Connection pool configuration
 	cfg.MaxConns = 20
 	cfg.MinConns = 2
@@ -22,6 +45,11 @@ func Open(ctx context.Context, url string) (*pgxpool.Pool, error) {
 	cfg.MaxConnIdleTime = 30 * time.Minute
 	cfg.HealthCheckPeriod = time.Minute
 
+	// Per-query spans (only when tracing is exported; the tracer isn't free)
+	if telemetry.Enabled() {
+		cfg.ConnConfig.Tracer = queryTracer{}
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -36,6 +64,9 @@ func Open(ctx context.Context, url string) (*pgxpool.Pool, error) {
 	log.Info().
 		Int32("max_conns", cfg.MaxConns).
 		Int32("min_conns", cfg.MinConns).
+		Str("statement_cache", cmp.Or(opts.StatementCache, StatementCachePrepare)).
+		Int("statement_cache_size", cacheSize).
+		Bool("prepare_hot", prepareHotStatements).
 		Msg("postgres connection pool created")
 
 	return pool, nil
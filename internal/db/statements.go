@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Statement cache modes (DB_STATEMENT_CACHE)
+const (
+	// StatementCachePrepare prepares each distinct query once per connection
+	// and reuses the server-side statement (pgx's default)
+	StatementCachePrepare = "prepare"
+	// StatementCacheDescribe caches only result descriptions and sends queries
+	// unnamed; use it behind PgBouncer in transaction pooling mode, where a
+	// statement prepared on one server connection is missing on the next
+	StatementCacheDescribe = "describe"
+	// StatementCacheOff parses and plans every query (pgx exec mode)
+	StatementCacheOff = "off"
+)
+
+// DefaultStatementCacheSize is the per-connection statement cache capacity (pgx's default)
+const DefaultStatementCacheSize = 512
+
+// PoolOptions tunes how a pool prepares and caches statements
+// The zero value is pgx's default: cached prepared statements, nothing prepared up front.
+type PoolOptions struct {
+	StatementCache     string // prepare|describe|off (empty means prepare)
+	StatementCacheSize int    // 0 means DefaultStatementCacheSize
+	PrepareHot         bool   // Prepare registered hot statements on connect (prepare mode only)
+}
+
+// execMode returns the pgx query mode for the configured cache mode
+func (o PoolOptions) execMode() (pgx.QueryExecMode, error) {
+	switch o.StatementCache {
+	case "", StatementCachePrepare:
+		return pgx.QueryExecModeCacheStatement, nil
+	case StatementCacheDescribe:
+		return pgx.QueryExecModeCacheDescribe, nil
+	case StatementCacheOff:
+		return pgx.QueryExecModeExec, nil
+	default:
+		return 0, fmt.Errorf("unknown statement cache mode %q (want prepare, describe or off)", o.StatementCache)
+	}
+}
+
+var (
+	hotMu         sync.RWMutex
+	hotStatements = map[string]string{} // Name -> SQL
+)
+
+// HotStatement registers sql as a hot query and returns it unchanged
+// With PrepareHot, every new connection prepares the registered statements
+// before its first use. They are keyed by their SQL text, so callers keep
+// passing sql to Query/Exec and pgx runs the prepared statement; unlike the
+// statement cache, these are never evicted by other queries.
+// The name identifies the statement in logs. Call from package initialization.
+func HotStatement(name, sql string) string {
+	hotMu.Lock()
+	defer hotMu.Unlock()
+	if existing, ok := hotStatements[name]; ok && existing != sql {
+		panic("db: hot statement " + name + " registered twice with different SQL")
+	}
+	hotStatements[name] = sql
+	return sql
+}
+
+// HotStatements returns the registered hot statement names, sorted
+func HotStatements() []string {
+	hotMu.RLock()
+	defer hotMu.RUnlock()
+	names := make([]string, 0, len(hotStatements))
+	for name := range hotStatements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// prepareHot prepares the registered hot statements on conn
+// A statement that fails to prepare (e.g. its table is not migrated yet) is
+// skipped, not fatal: its queries still run through the statement cache.
+func prepareHot(ctx context.Context, conn *pgx.Conn) error {
+	hotMu.RLock()
+	defer hotMu.RUnlock()
+	var failed []string
+	var firstErr error
+	for name, sql := range hotStatements {
+		if _, err := conn.Prepare(ctx, sql, sql); err != nil {
+			failed = append(failed, name)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		log.Warn().Err(firstErr).Strs("statements", failed).Msg("failed to prepare hot statements; they will use the statement cache")
+	}
+	return nil
+}
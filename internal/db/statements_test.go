@@ -0,0 +1,42 @@
+package db
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPoolOptionsExecMode(t *testing.T) {
+	for cache, want := range map[string]pgx.QueryExecMode{
+		"":                     pgx.QueryExecModeCacheStatement,
+		StatementCachePrepare:  pgx.QueryExecModeCacheStatement,
+		StatementCacheDescribe: pgx.QueryExecModeCacheDescribe,
+		StatementCacheOff:      pgx.QueryExecModeExec,
+	} {
+		if got, err := (PoolOptions{StatementCache: cache}).execMode(); err != nil || got != want {
+			t.Errorf("execMode(%q) = %v, %v; want %v", cache, got, err, want)
+		}
+	}
+	if _, err := (PoolOptions{StatementCache: "simple"}).execMode(); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestHotStatement(t *testing.T) {
+	const sql = `SELECT 1 WHERE $1::int > 0`
+	if got := HotStatement("test_hot", sql); got != sql {
+		t.Errorf("HotStatement returned %q, want the SQL unchanged", got)
+	}
+	HotStatement("test_hot", sql) // Re-registering the same SQL is allowed
+	if !slices.Contains(HotStatements(), "test_hot") {
+		t.Errorf("HotStatements() = %v, missing test_hot", HotStatements())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("conflicting registration did not panic")
+		}
+	}()
+	HotStatement("test_hot", `SELECT 2`)
+}
@@ -0,0 +1,95 @@
+// Package devdata generates fake sync data for development and load tests
+// Payloads come from internal/fixtures, so seeded data satisfies the same
+// invariants as test data. Items are written through the sync service push calls, so LWW, parent
+// validation and the activity log behave exactly as for real clients. It backs
+// the `seed` command and the dev-mode /dev/seed endpoint.
+package devdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/fixtures"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config controls the volume generated per user
+type Config struct {
+	fixtures.Counts
+	Batch int // Items per transaction
+}
+
+// pushFunc writes one item through a sync service
+type pushFunc func(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) syncservice.PushAck
+
+// Seeder pushes generated items for one user at a time
+// A Seeder is not safe for concurrent use (it owns a seeded random source).
+type Seeder struct {
+	cfg  Config
+	pool *pgxpool.Pool
+	gen  *fixtures.Generator
+	push map[string]pushFunc // Entity -> service push call
+}
+
+// NewSeeder returns a Seeder drawing from seed, so a given seed reproduces the same UIDs
+// Timestamps are spread over the 90 days before now so pulls page through
+// realistic history.
+func NewSeeder(pool *pgxpool.Pool, cfg Config, seed int64) *Seeder {
+	if cfg.Batch < 1 {
+		cfg.Batch = 500
+	}
+	return &Seeder{
+		cfg:  cfg,
+		pool: pool,
+		gen:  fixtures.New(seed, time.Now()),
+		push: map[string]pushFunc{
+			fixtures.TaskListCategory: syncservice.NewTaskListCategoryService(pool).PushTaskListCategoryItem,
+			fixtures.TaskList:         syncservice.NewTaskListService(pool).PushTaskListItem,
+			fixtures.Note:             syncservice.NewNoteService(pool).PushNoteItem,
+			fixtures.Task:             syncservice.NewTaskService(pool).PushTaskItem,
+			fixtures.Comment:          syncservice.NewCommentService(pool).PushCommentItem,
+			fixtures.Chat:             syncservice.NewChatService(pool).PushChatItem,
+			fixtures.ChatMessage:      syncservice.NewChatMessageService(pool).PushChatMessageItem,
+			fixtures.Pin:              syncservice.NewPinService(pool).PushPinItem,
+		},
+	}
+}
+
+// EnsureUser returns the app_user ID for sub, creating it as the auth middleware would
+func EnsureUser(ctx context.Context, pool *pgxpool.Pool, sub string) (string, error) {
+	var userID string
+	err := pool.QueryRow(ctx,
+		`INSERT INTO app_user (sub) VALUES ($1)
+		 ON CONFLICT (sub) DO UPDATE SET sub = excluded.sub
+		 RETURNING id`, sub).Scan(&userID)
+	return userID, err
+}
+
+// Seed pushes a generated data set for userID and returns the item count per entity
+// Items are pushed in Config.Batch-sized transactions, parents first.
+func (s *Seeder) Seed(ctx context.Context, userID string) (map[string]int, error) {
+	items := s.gen.Generate(s.cfg.Counts)
+	counts := make(map[string]int)
+	ctx = syncservice.WithChangeSource(ctx, syncservice.ChangeSource{DeviceID: "seed"})
+	for start := 0; start < len(items); start += s.cfg.Batch {
+		end := min(start+s.cfg.Batch, len(items))
+		err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+			for _, it := range items[start:end] {
+				if ack := s.push[it.Entity](ctx, tx, userID, it.Payload); ack.Error != "" {
+					return fmt.Errorf("%s %s: %s", it.Entity, ack.UID, ack.Error)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, it := range items {
+		counts[it.Entity]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,155 @@
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Config controls error reporting to a Sentry-compatible endpoint
+type Config struct {
+	DSN         string  // Sentry DSN; reporting is disabled when empty
+	Environment string  // environment tag (e.g., "prod", "staging")
+	Release     string  // release/version tag (optional)
+	SampleRate  float64 // fraction of error events to send (0 means 1.0)
+}
+
+var enabled bool
+
+// Init configures the Sentry client
+// Returns a shutdown function that flushes queued events; it is safe to call
+// even when reporting is disabled.
+func Init(cfg Config) (func(context.Context) error, error) {
+	if cfg.DSN == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.DSN,
+		Environment:      cfg.Environment,
+		Release:          cfg.Release,
+		SampleRate:       cfg.SampleRate,
+		AttachStacktrace: true,
+		// Never send Authorization/Cookie headers or client IPs
+		SendDefaultPII: false,
+	}); err != nil {
+		return nil, fmt.Errorf("init error reporting: %w", err)
+	}
+	enabled = true
+
+	return func(ctx context.Context) error {
+		timeout := 5 * time.Second
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		sentry.Flush(timeout)
+		return nil
+	}, nil
+}
+
+// Enabled reports whether errors are being reported
+func Enabled() bool {
+	return enabled
+}
+
+// requestScope is stored in the request context alongside the per-request hub
+// captured marks that an event was already sent for this request, so the
+// generic 5xx capture doesn't duplicate a more specific panic/error event.
+type requestScope struct {
+	hub      *sentry.Hub
+	captured atomic.Bool
+}
+
+type scopeKey struct{}
+
+// WithRequest returns a context carrying a per-request hub
+// The hub's scope is shared by everything downstream of ctx, so SetUser and
+// SetTag calls made by inner middleware show up on events captured by outer ones.
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	if !enabled {
+		return ctx
+	}
+	hub := sentry.CurrentHub().Clone()
+	if r != nil {
+		hub.Scope().SetRequest(r)
+	}
+	ctx = sentry.SetHubOnContext(ctx, hub)
+	return context.WithValue(ctx, scopeKey{}, &requestScope{hub: hub})
+}
+
+func fromContext(ctx context.Context) *requestScope {
+	if rs, ok := ctx.Value(scopeKey{}).(*requestScope); ok {
+		return rs
+	}
+	return nil
+}
+
+// hub returns the request hub, or the global hub outside a request
+func hub(ctx context.Context) *sentry.Hub {
+	if rs := fromContext(ctx); rs != nil {
+		return rs.hub
+	}
+	return sentry.CurrentHub()
+}
+
+// SetUser attaches the authenticated user ID to events for this request
+func SetUser(ctx context.Context, userID string) {
+	if !enabled || userID == "" {
+		return
+	}
+	hub(ctx).Scope().SetUser(sentry.User{ID: userID})
+}
+
+// SetTag attaches a searchable tag (correlation_id, trace_id, route, ...) to events for this request
+func SetTag(ctx context.Context, key, value string) {
+	if !enabled || value == "" {
+		return
+	}
+	hub(ctx).Scope().SetTag(key, value)
+}
+
+// CaptureError reports err with the request context
+func CaptureError(ctx context.Context, err error) {
+	if !enabled || err == nil {
+		return
+	}
+	hub(ctx).CaptureException(err)
+	markCaptured(ctx)
+}
+
+// CapturePanic reports a recovered panic value with the request context
+func CapturePanic(ctx context.Context, recovered any) {
+	if !enabled || recovered == nil {
+		return
+	}
+	hub(ctx).RecoverWithContext(ctx, recovered)
+	markCaptured(ctx)
+}
+
+// CaptureStatus reports a 5xx response unless an event was already captured for the request
+func CaptureStatus(ctx context.Context, method, route string, status int) {
+	if !enabled || status < 500 {
+		return
+	}
+	if Captured(ctx) {
+		return
+	}
+	hub(ctx).CaptureMessage(fmt.Sprintf("%s %s returned %d", method, route, status))
+	markCaptured(ctx)
+}
+
+// Captured reports whether an event was already sent for the request in ctx
+func Captured(ctx context.Context) bool {
+	rs := fromContext(ctx)
+	return rs != nil && rs.captured.Load()
+}
+
+func markCaptured(ctx context.Context) {
+	if rs := fromContext(ctx); rs != nil {
+		rs.captured.Store(true)
+	}
+}
@@ -0,0 +1,85 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// recordingTransport collects events instead of sending them
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Close()                                {}
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func setupTestClient(t *testing.T) *recordingTransport {
+	t.Helper()
+	transport := &recordingTransport{}
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:       "https://public@sentry.example.com/1",
+		Transport: transport,
+	}); err != nil {
+		t.Fatalf("sentry.Init: %v", err)
+	}
+	enabled = true
+	t.Cleanup(func() { enabled = false })
+	return transport
+}
+
+func TestCaptureWithRequestContext(t *testing.T) {
+	transport := setupTestClient(t)
+
+	req := httptest.NewRequest("POST", "/v1/sync/notes/push", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	ctx := WithRequest(context.Background(), req)
+	SetUser(ctx, "user-123")
+	SetTag(ctx, "correlation_id", "corr-1")
+
+	CaptureError(ctx, errors.New("commit failed"))
+	// The generic 5xx report is suppressed once a specific error was captured
+	CaptureStatus(ctx, "POST", "/v1/sync/notes/push", 500)
+
+	if len(transport.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(transport.events))
+	}
+	event := transport.events[0]
+	if event.User.ID != "user-123" {
+		t.Errorf("user = %q, want user-123", event.User.ID)
+	}
+	if event.Tags["correlation_id"] != "corr-1" {
+		t.Errorf("correlation_id tag = %q, want corr-1", event.Tags["correlation_id"])
+	}
+	if event.Request == nil || event.Request.URL == "" {
+		t.Fatalf("request context missing from event")
+	}
+	if _, ok := event.Request.Headers["Authorization"]; ok {
+		t.Errorf("Authorization header must not be reported")
+	}
+}
+
+func TestCaptureStatusIgnoresClientErrors(t *testing.T) {
+	transport := setupTestClient(t)
+
+	ctx := WithRequest(context.Background(), httptest.NewRequest("GET", "/v1/notes", nil))
+	CaptureStatus(ctx, "GET", "/v1/notes", 404)
+	CaptureStatus(ctx, "GET", "/v1/notes", 503)
+
+	if len(transport.events) != 1 {
+		t.Fatalf("events = %d, want 1 (only the 503)", len(transport.events))
+	}
+}
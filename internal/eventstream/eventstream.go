@@ -0,0 +1,121 @@
+// Package eventstream publishes outbox change events to NATS JetStream or Kafka
+// so downstream consumers (analytics, search indexers) can follow sync activity
+// without reading the primary database.
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+)
+
+// Backends
+const (
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+)
+
+// Config selects and configures the stream backend
+type Config struct {
+	Backend string // nats or kafka ("" disables the stream)
+	URL     string // NATS server URL, or comma-separated Kafka brokers
+	Subject string // NATS subject prefix or Kafka topic
+}
+
+// Message is the JSON body of one published change event
+type Message struct {
+	EventID    string `json:"eventId"` // Dedup key (the same if the event is re-published)
+	OwnerID    string `json:"ownerId"`
+	Entity     string `json:"entity"`
+	UID        string `json:"uid"`
+	Version    int    `json:"version"`
+	Op         string `json:"op"` // created, updated or deleted
+	UpdatedAt  string `json:"updatedAt"`
+	OccurredAt string `json:"occurredAt"`
+}
+
+// newMessage converts an outbox event to its wire form
+func newMessage(e outbox.Event) Message {
+	return Message{
+		EventID:    e.ID,
+		OwnerID:    e.OwnerID,
+		Entity:     e.Entity,
+		UID:        e.UID.String(),
+		Version:    e.Version,
+		Op:         e.Action,
+		UpdatedAt:  syncx.RFC3339(e.UpdatedAtMs),
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// sender is a backend that publishes encoded messages
+type sender interface {
+	send(ctx context.Context, msgs []Message, bodies [][]byte) error
+	close() error
+}
+
+// Publisher is an outbox.Publisher that forwards events to the stream
+// A batch is only marked dispatched once the broker acknowledged every message;
+// on error the whole batch is re-published, and consumers dedupe on eventId.
+type Publisher struct {
+	backend string
+	sender  sender
+}
+
+// New connects to the configured backend
+// Returns nil (and no error) when cfg.Backend is empty.
+func New(cfg Config) (*Publisher, error) {
+	if cfg.Backend == "" {
+		return nil, nil
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("event stream %s: URL is required", cfg.Backend)
+	}
+
+	var s sender
+	var err error
+	switch cfg.Backend {
+	case BackendNATS:
+		s, err = newNATSSender(cfg.URL, cfg.Subject)
+	case BackendKafka:
+		s, err = newKafkaSender(strings.Split(cfg.URL, ","), cfg.Subject)
+	default:
+		return nil, fmt.Errorf("unknown event stream backend %q (nats|kafka)", cfg.Backend)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("event stream %s: %w", cfg.Backend, err)
+	}
+	return &Publisher{backend: cfg.Backend, sender: s}, nil
+}
+
+// Name implements outbox.Publisher
+func (p *Publisher) Name() string { return "eventstream:" + p.backend }
+
+// Publish implements outbox.Publisher; the dispatcher's tx is unused
+func (p *Publisher) Publish(ctx context.Context, _ pgx.Tx, events []outbox.Event) error {
+	msgs := make([]Message, len(events))
+	bodies := make([][]byte, len(events))
+	for i, e := range events {
+		msgs[i] = newMessage(e)
+		b, err := json.Marshal(msgs[i])
+		if err != nil {
+			return err
+		}
+		bodies[i] = b
+	}
+	return p.sender.send(ctx, msgs, bodies)
+}
+
+// Close flushes and disconnects from the broker
+func (p *Publisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	return p.sender.close()
+}
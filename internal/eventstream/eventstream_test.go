@@ -0,0 +1,45 @@
+package eventstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/google/uuid"
+)
+
+func TestNewMessage(t *testing.T) {
+	uid := uuid.MustParse("6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c")
+	m := newMessage(outbox.Event{
+		ID:          "evt-1",
+		OwnerID:     "owner-1",
+		Entity:      "task",
+		UID:         uid,
+		Action:      "updated",
+		Version:     3,
+		UpdatedAtMs: 1700000000000,
+		OccurredAt:  time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+	})
+	if m.EventID != "evt-1" || m.Op != "updated" || m.UID != uid.String() || m.Version != 3 {
+		t.Errorf("unexpected message: %+v", m)
+	}
+	if m.OccurredAt != "2025-11-03T10:00:00Z" {
+		t.Errorf("occurredAt = %q", m.OccurredAt)
+	}
+	if got := natsSubject("toolbridge.changes", m); got != "toolbridge.changes.task.updated" {
+		t.Errorf("subject = %q", got)
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	p, err := New(Config{})
+	if p != nil || err != nil {
+		t.Errorf("New(disabled) = %v, %v; want nil, nil", p, err)
+	}
+	if _, err := New(Config{Backend: "redis", URL: "redis://localhost"}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	if _, err := New(Config{Backend: BackendKafka}); err == nil {
+		t.Error("expected error for missing URL")
+	}
+}
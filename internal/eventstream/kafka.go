@@ -0,0 +1,43 @@
+package eventstream
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSender publishes to one Kafka topic
+// Messages are keyed by owner ID, so each user's events stay ordered within a
+// partition; the event-id header is the dedup key.
+type kafkaSender struct {
+	w *kafka.Writer
+}
+
+func newKafkaSender(brokers []string, topic string) (*kafkaSender, error) {
+	return &kafkaSender{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}, nil
+}
+
+func (s *kafkaSender) send(ctx context.Context, msgs []Message, bodies [][]byte) error {
+	out := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = kafka.Message{
+			Key:   []byte(m.OwnerID),
+			Value: bodies[i],
+			Headers: []kafka.Header{
+				{Key: "event-id", Value: []byte(m.EventID)},
+				{Key: "entity", Value: []byte(m.Entity)},
+				{Key: "op", Value: []byte(m.Op)},
+			},
+		}
+	}
+	return s.w.WriteMessages(ctx, out...)
+}
+
+func (s *kafkaSender) close() error {
+	return s.w.Close()
+}
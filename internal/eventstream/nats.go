@@ -0,0 +1,67 @@
+package eventstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsSender publishes to JetStream on <prefix>.<entity>.<op>
+// The subject prefix must be bound to a stream (e.g. subjects "toolbridge.changes.>").
+// Each message carries Nats-Msg-Id = eventId, so re-published events inside the
+// stream's duplicate window are dropped by the server.
+type natsSender struct {
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+}
+
+func newNATSSender(url, prefix string) (*natsSender, error) {
+	nc, err := nats.Connect(url, nats.Name("toolbridge-api"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &natsSender{nc: nc, js: js, prefix: prefix}, nil
+}
+
+// natsSubject returns the subject for a message
+func natsSubject(prefix string, m Message) string {
+	return prefix + "." + m.Entity + "." + m.Op
+}
+
+func (s *natsSender) send(ctx context.Context, msgs []Message, bodies [][]byte) error {
+	futures := make([]jetstream.PubAckFuture, len(msgs))
+	for i, m := range msgs {
+		f, err := s.js.PublishAsync(natsSubject(s.prefix, m), bodies[i], jetstream.WithMsgID(m.EventID))
+		if err != nil {
+			return err
+		}
+		futures[i] = f
+	}
+
+	// Wait for every ack (in order) before the batch counts as dispatched
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	for i, f := range futures {
+		select {
+		case <-f.Ok():
+		case err := <-f.Err():
+			return fmt.Errorf("publish %s: %w", msgs[i].EventID, err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *natsSender) close() error {
+	return s.nc.Drain()
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// FormatCSV is the spreadsheet format of single-entity exports (tasks only)
+const FormatCSV = "csv"
+
+// EntityFilter narrows a single-entity export; zero values don't filter
+type EntityFilter struct {
+	IncludeDeleted bool
+	UpdatedSince   int64  // Unix ms, inclusive
+	UpdatedBefore  int64  // Unix ms, exclusive
+	Status         string // payload status
+	Tag            string // In the payload's tags
+	TaskListUID    string // payload taskListUid
+}
+
+// EntityTable returns the table of an entity by its archive name (notes,
+// tasks, ...), as used by GET /v1/{entity}/export
+func EntityTable(entity string) (string, bool) {
+	for _, et := range entityTables {
+		if et.file == entity+".json" {
+			return et.table, true
+		}
+	}
+	return "", false
+}
+
+// taskCSVColumns are the payload fields of the task CSV, after uid
+var taskCSVColumns = []string{"title", "status", "done", "priority", "dueDate", "completedAt", "taskListUid", "parentUid", "tags", "description"}
+
+// WriteEntity streams the user's items of one entity matching f to w, oldest
+// change first: as a JSON array of archive records (the entries of the
+// account archive's <entity>.json), or for tasks as CSV. Returns the number of
+// items written. Errors after the first write leave w truncated.
+func (s *Service) WriteEntity(ctx context.Context, w io.Writer, userID, entity, format string, f EntityFilter) (int, error) {
+	table, ok := EntityTable(entity)
+	if !ok || (format != FormatJSON && format != FormatCSV) || (format == FormatCSV && table != "task") {
+		return 0, ErrUnknownFormat
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT uid::text, version, updated_at_ms, deleted_at_ms, payload_json::text
+		FROM `+table+`
+		WHERE owner_id = $1
+		  AND ($2 OR deleted_at_ms IS NULL)
+		  AND ($3::bigint = 0 OR updated_at_ms >= $3)
+		  AND ($4::bigint = 0 OR updated_at_ms < $4)
+		  AND ($5 = '' OR payload_json->>'status' = $5)
+		  AND ($6 = '' OR coalesce(payload_json->'tags', '[]') ? $6)
+		  AND ($7 = '' OR lower(payload_json->>'taskListUid') = lower($7))
+		ORDER BY updated_at_ms, uid
+	`, userID, f.IncludeDeleted, f.UpdatedSince, f.UpdatedBefore, f.Status, f.Tag, f.TaskListUID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var write func(it exportItem) error
+	var finish func() error
+	if format == FormatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(append(append([]string{"uid"}, taskCSVColumns...), "updatedAt", "deletedAt")); err != nil {
+			return 0, err
+		}
+		write = func(it exportItem) error { return cw.Write(taskCSVRecord(it)) }
+		finish = func() error { cw.Flush(); return cw.Error() }
+	} else {
+		if _, err := io.WriteString(w, "["); err != nil {
+			return 0, err
+		}
+		sep := "\n"
+		write = func(it exportItem) error {
+			b, err := json.Marshal(it)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			sep = ",\n"
+			_, err = w.Write(b)
+			return err
+		}
+		finish = func() error {
+			_, err := io.WriteString(w, "\n]\n")
+			return err
+		}
+	}
+
+	n := 0
+	for rows.Next() {
+		var it exportItem
+		var ms int64
+		var deletedMs *int64
+		var payload string
+		if err := rows.Scan(&it.UID, &it.Version, &ms, &deletedMs, &payload); err != nil {
+			return n, err
+		}
+		it.UpdatedAt = syncx.RFC3339(ms)
+		if deletedMs != nil {
+			ts := syncx.RFC3339(*deletedMs)
+			it.DeletedAt = &ts
+		}
+		it.Payload = json.RawMessage(payload)
+		if err := write(it); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	return n, finish()
+}
+
+// taskCSVRecord flattens a task to the CSV columns
+// Tags are joined with ", "; other non-string values are written as JSON.
+func taskCSVRecord(it exportItem) []string {
+	var p map[string]any
+	_ = json.Unmarshal(it.Payload, &p)
+
+	rec := []string{it.UID}
+	for _, col := range taskCSVColumns {
+		if tags, ok := p[col].([]any); ok && col == "tags" {
+			names := make([]string, len(tags))
+			for i, tag := range tags {
+				names[i] = fmt.Sprint(tag)
+			}
+			rec = append(rec, strings.Join(names, ", "))
+			continue
+		}
+		switch v := p[col].(type) {
+		case nil:
+			rec = append(rec, "")
+		case string:
+			rec = append(rec, v)
+		case bool:
+			rec = append(rec, strconv.FormatBool(v))
+		case float64:
+			rec = append(rec, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			b, _ := json.Marshal(v)
+			rec = append(rec, string(b))
+		}
+	}
+	deletedAt := ""
+	if it.DeletedAt != nil {
+		deletedAt = *it.DeletedAt
+	}
+	return append(rec, it.UpdatedAt, deletedAt)
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEntityTable(t *testing.T) {
+	if table, ok := EntityTable("task_list_categories"); !ok || table != "task_list_category" {
+		t.Errorf("EntityTable(task_list_categories) = %q, %v", table, ok)
+	}
+	if _, ok := EntityTable("activity"); ok {
+		t.Error("activity is not an entity")
+	}
+}
+
+func TestTaskCSVRecord(t *testing.T) {
+	deleted := "2025-11-05T09:00:00Z"
+	got := taskCSVRecord(exportItem{
+		UID:       "t1",
+		UpdatedAt: "2025-11-04T08:00:00Z",
+		DeletedAt: &deleted,
+		Payload: json.RawMessage(`{"title":"Pay rent","status":"completed","done":true,"priority":2,
+			"tags":["home","money"],"description":"line 1\nline 2","reminders":[{"at":"x"}]}`),
+	})
+	want := []string{"t1", "Pay rent", "completed", "true", "2", "", "", "", "", "home, money", "line 1\nline 2",
+		"2025-11-04T08:00:00Z", deleted}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("taskCSVRecord =\n%q\nwant\n%q", got, want)
+	}
+}
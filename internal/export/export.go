@@ -0,0 +1,476 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusExpired   = "expired"
+)
+
+// Archive formats
+const (
+	FormatJSON     = "json"     // Full account archive (restorable)
+	FormatMarkdown = "markdown" // Markdown vault for Obsidian / plain-file workflows
+)
+
+// ErrNotFound is returned when a job doesn't exist (or belongs to another user)
+var ErrNotFound = errors.New("export job not found")
+
+// ErrInProgress is returned when the user already has a queued or running export
+var ErrInProgress = errors.New("export already in progress")
+
+// ErrUnknownFormat is returned for an unsupported Options.Format
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// entityTables maps archive file names to entity tables
+// All entity tables share the sync columns (uid, version, updated_at_ms, deleted_at_ms, payload_json).
+var entityTables = []struct {
+	file  string
+	table string
+}{
+	{"notes.json", "note"},
+	{"tasks.json", "task"},
+	{"task_lists.json", "task_list"},
+	{"task_list_categories.json", "task_list_category"},
+	{"comments.json", "comment"},
+	{"chats.json", "chat"},
+	{"chat_messages.json", "chat_message"},
+	{"pins.json", "pin"},
+}
+
+// Options selects what an export job builds
+type Options struct {
+	Format          string `json:"format"`          // FormatJSON (default) or FormatMarkdown
+	IncludeTasks    bool   `json:"includeTasks"`    // Markdown only: render tasks as well as notes
+	IncludeComments bool   `json:"includeComments"` // Markdown only: append comments to their note/task
+}
+
+// Job is an export job as returned by the status API
+type Job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Format     string     `json:"format"`
+	Error      string     `json:"error,omitempty"`
+	SizeBytes  int64      `json:"sizeBytes,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Service enqueues, builds and serves account data exports
+// Archives are kept in export_job until Retention passes; download URLs are
+// HMAC-signed with Key and valid for URLTTL (never past the archive's expiry).
+type Service struct {
+	DB        *pgxpool.Pool
+	Key       []byte        // Download URL signing key
+	URLTTL    time.Duration // Lifetime of a signed download URL
+	Retention time.Duration // How long a finished archive is kept
+	StaleJob  time.Duration // Running jobs older than this are requeued (worker crashed)
+}
+
+// NewService creates an export service with default lifetimes
+func NewService(db *pgxpool.Pool, key []byte) *Service {
+	return &Service{
+		DB:        db,
+		Key:       key,
+		URLTTL:    15 * time.Minute,
+		Retention: 7 * 24 * time.Hour,
+		StaleJob:  15 * time.Minute,
+	}
+}
+
+// Enqueue creates a queued export job for the user
+// Returns ErrInProgress if an export is already queued or running.
+func (s *Service) Enqueue(ctx context.Context, userID string, opts Options) (*Job, error) {
+	if opts.Format == "" {
+		opts.Format = FormatJSON
+	}
+	if opts.Format != FormatJSON && opts.Format != FormatMarkdown {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+
+	var job Job
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Serialize per-user enqueues so the in-progress check can't race
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('export:' || $1))`, userID); err != nil {
+			return err
+		}
+
+		var active bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM export_job
+				WHERE owner_id = $1 AND status IN ('queued', 'running')
+			)
+		`, userID).Scan(&active); err != nil {
+			return err
+		}
+		if active {
+			return ErrInProgress
+		}
+
+		return tx.QueryRow(ctx, `
+			INSERT INTO export_job (owner_id, format, include_tasks, include_comments) VALUES ($1, $2, $3, $4)
+			RETURNING id::text, status, format, created_at
+		`, userID, opts.Format, opts.IncludeTasks, opts.IncludeComments).Scan(&job.ID, &job.Status, &job.Format, &job.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Get returns the user's job by ID
+func (s *Service) Get(ctx context.Context, userID, id string) (*Job, error) {
+	var job Job
+	var errMsg *string
+	var size *int64
+	err := s.DB.QueryRow(ctx, `
+		SELECT id::text, status, format, error, size_bytes, created_at, finished_at, expires_at
+		FROM export_job
+		WHERE id = $1 AND owner_id = $2
+	`, id, userID).Scan(&job.ID, &job.Status, &job.Format, &errMsg, &size, &job.CreatedAt, &job.FinishedAt, &job.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errMsg != nil {
+		job.Error = *errMsg
+	}
+	if size != nil {
+		job.SizeBytes = *size
+	}
+	return &job, nil
+}
+
+// Archive returns a succeeded, unexpired job's zip archive
+// Called by the signed download endpoint (no user context; the signature binds the job ID).
+func (s *Service) Archive(ctx context.Context, id string) ([]byte, error) {
+	var archive []byte
+	err := s.DB.QueryRow(ctx, `
+		SELECT archive FROM export_job
+		WHERE id = $1 AND status = 'succeeded' AND expires_at > now()
+	`, id).Scan(&archive)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return archive, err
+}
+
+// Run processes queued jobs until ctx is cancelled
+// Each tick drains the queue, then expires old archives and requeues stale jobs.
+// Safe to run on every replica: jobs are claimed with FOR UPDATE SKIP LOCKED.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := s.processNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("export job processing failed")
+			}
+			if !processed || ctx.Err() != nil {
+				break
+			}
+		}
+		if err := s.maintain(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("export job maintenance failed")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processNext claims and builds one queued job
+// Returns false when the queue is empty.
+func (s *Service) processNext(ctx context.Context) (bool, error) {
+	var id, userID string
+	var opts Options
+	err := s.DB.QueryRow(ctx, `
+		UPDATE export_job SET status = 'running', started_at = now()
+		WHERE id = (
+			SELECT id FROM export_job
+			WHERE status = 'queued'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id::text, owner_id::text, format, include_tasks, include_comments
+	`).Scan(&id, &userID, &opts.Format, &opts.IncludeTasks, &opts.IncludeComments)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logger := log.With().Str("exportId", id).Str("userId", userID).Logger()
+	start := time.Now()
+
+	var archive []byte
+	if opts.Format == FormatMarkdown {
+		archive, err = s.BuildMarkdown(ctx, userID, opts)
+	} else {
+		archive, err = s.Build(ctx, userID)
+	}
+	if err != nil && ctx.Err() != nil {
+		// Shutting down: hand the job back to the queue for the next worker
+		_, uerr := s.DB.Exec(context.WithoutCancel(ctx), `
+			UPDATE export_job SET status = 'queued', started_at = NULL WHERE id = $1
+		`, id)
+		return true, uerr
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("export build failed")
+		_, uerr := s.DB.Exec(context.WithoutCancel(ctx), `
+			UPDATE export_job SET status = 'failed', error = $2, finished_at = now()
+			WHERE id = $1
+		`, id, "export failed")
+		return true, uerr
+	}
+
+	if _, err := s.DB.Exec(ctx, `
+		UPDATE export_job
+		SET status = 'succeeded', archive = $2, size_bytes = $3, finished_at = now(), expires_at = now() + $4::interval
+		WHERE id = $1
+	`, id, archive, len(archive), fmt.Sprintf("%d seconds", int64(s.Retention.Seconds()))); err != nil {
+		return true, err
+	}
+
+	logger.Info().Int("bytes", len(archive)).Dur("took", time.Since(start)).Msg("export archive ready")
+	return true, nil
+}
+
+// maintain drops expired archives and requeues jobs whose worker died
+func (s *Service) maintain(ctx context.Context) error {
+	if _, err := s.DB.Exec(ctx, `
+		UPDATE export_job SET status = 'expired', archive = NULL
+		WHERE status = 'succeeded' AND expires_at <= now()
+	`); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(ctx, `
+		UPDATE export_job SET status = 'queued', started_at = NULL
+		WHERE status = 'running' AND started_at < now() - $1::interval
+	`, fmt.Sprintf("%d seconds", int64(s.StaleJob.Seconds())))
+	return err
+}
+
+// exportItem is one entity row in the archive
+type exportItem struct {
+	UID       string          `json:"uid"`
+	Version   int             `json:"version"`
+	UpdatedAt string          `json:"updatedAt"`
+	DeletedAt *string         `json:"deletedAt,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// manifest describes the archive contents
+type manifest struct {
+	Format     int            `json:"format"`
+	UserID     string         `json:"userId"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Counts     map[string]int `json:"counts"` // File name -> number of records
+}
+
+// Build produces the zip archive for a user from one consistent snapshot
+// The whole archive is read in a single REPEATABLE READ, read-only transaction,
+// so it never mixes rows from before and after a concurrent push.
+func (s *Service) Build(ctx context.Context, userID string) ([]byte, error) {
+	tx, err := s.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	m := manifest{Format: 1, UserID: userID, ExportedAt: time.Now().UTC(), Counts: map[string]int{}}
+
+	writeJSON := func(name string, v any) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	writeFile := func(name string, v any, n int) error {
+		m.Counts[name] = n
+		return writeJSON(name, v)
+	}
+
+	// Entities (including tombstones, so the export mirrors sync state)
+	for _, et := range entityTables {
+		items, err := queryItems(ctx, tx, et.table, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", et.table, err)
+		}
+		if err := writeFile(et.file, items, len(items)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Change history
+	activity, err := queryJSON(ctx, tx, `
+		SELECT entity, uid::text, action, version, updated_at_ms, session_id, device_id, created_at
+		FROM activity_log WHERE owner_id = $1 ORDER BY id
+	`, userID, func(rows pgx.Rows) (any, error) {
+		var e struct {
+			Entity    string    `json:"entity"`
+			UID       string    `json:"uid"`
+			Action    string    `json:"action"`
+			Version   int       `json:"version"`
+			UpdatedAt string    `json:"updatedAt"`
+			SessionID *string   `json:"sessionId,omitempty"`
+			DeviceID  *string   `json:"deviceId,omitempty"`
+			ChangedAt time.Time `json:"changedAt"`
+		}
+		var ms int64
+		err := rows.Scan(&e.Entity, &e.UID, &e.Action, &e.Version, &ms, &e.SessionID, &e.DeviceID, &e.ChangedAt)
+		e.UpdatedAt = syncx.RFC3339(ms)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export activity: %w", err)
+	}
+	if err := writeFile("activity.json", activity, len(activity)); err != nil {
+		return nil, err
+	}
+
+	// Sync usage history
+	usage, err := queryJSON(ctx, tx, `
+		SELECT to_char(day, 'YYYY-MM-DD'), pushes, pulls, items_pushed, items_pulled, bytes_in, bytes_out, conflicts
+		FROM sync_analytics_daily WHERE owner_id = $1 ORDER BY day
+	`, userID, func(rows pgx.Rows) (any, error) {
+		var d struct {
+			Date        string `json:"date"`
+			Pushes      int64  `json:"pushes"`
+			Pulls       int64  `json:"pulls"`
+			ItemsPushed int64  `json:"itemsPushed"`
+			ItemsPulled int64  `json:"itemsPulled"`
+			BytesIn     int64  `json:"bytesIn"`
+			BytesOut    int64  `json:"bytesOut"`
+			Conflicts   int64  `json:"conflicts"`
+		}
+		err := rows.Scan(&d.Date, &d.Pushes, &d.Pulls, &d.ItemsPushed, &d.ItemsPulled, &d.BytesIn, &d.BytesOut, &d.Conflicts)
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export usage: %w", err)
+	}
+	if err := writeFile("sync_usage.json", usage, len(usage)); err != nil {
+		return nil, err
+	}
+
+	// Account and session metadata
+	account := struct {
+		Subject    string            `json:"subject"`
+		CreatedAt  time.Time         `json:"createdAt"`
+		Epoch      *int              `json:"epoch,omitempty"`
+		LastWipeAt *time.Time        `json:"lastWipeAt,omitempty"`
+		Sessions   []session.Session `json:"activeSessions"`
+	}{Sessions: session.GetStore().UserSessions(userID)}
+	if err := tx.QueryRow(ctx, `
+		SELECT u.sub, u.created_at, s.epoch, s.last_wipe_at
+		FROM app_user u LEFT JOIN owner_state s ON s.owner_id = u.id::text
+		WHERE u.id = $1
+	`, userID).Scan(&account.Subject, &account.CreatedAt, &account.Epoch, &account.LastWipeAt); err != nil {
+		return nil, fmt.Errorf("export account: %w", err)
+	}
+	if account.Sessions == nil {
+		account.Sessions = []session.Session{}
+	}
+	if err := writeFile("account.json", account, 1); err != nil {
+		return nil, err
+	}
+
+	if err := writeJSON("manifest.json", m); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// queryItems reads all rows of an entity table for a user
+func queryItems(ctx context.Context, tx pgx.Tx, table, userID string) ([]exportItem, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT uid::text, version, updated_at_ms, deleted_at_ms, payload_json::text
+		FROM `+table+`
+		WHERE owner_id = $1
+		ORDER BY updated_at_ms, uid
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []exportItem{}
+	for rows.Next() {
+		var it exportItem
+		var ms int64
+		var deletedMs *int64
+		var payload string
+		if err := rows.Scan(&it.UID, &it.Version, &ms, &deletedMs, &payload); err != nil {
+			return nil, err
+		}
+		it.UpdatedAt = syncx.RFC3339(ms)
+		if deletedMs != nil {
+			ts := syncx.RFC3339(*deletedMs)
+			it.DeletedAt = &ts
+		}
+		it.Payload = json.RawMessage(payload)
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// queryJSON runs a per-user query and converts each row with scan
+func queryJSON(ctx context.Context, tx pgx.Tx, sql, userID string, scan func(pgx.Rows) (any, error)) ([]any, error) {
+	rows, err := tx.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []any{}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
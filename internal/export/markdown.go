@@ -0,0 +1,230 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+)
+
+// mdDoc is one live entity rendered as a Markdown file
+type mdDoc struct {
+	entity   string
+	uid      string
+	version  int
+	updated  string
+	payload  map[string]any
+	dir      string // Folder inside the vault
+	title    string // File name (before sanitizing)
+	bodyKey  string // Payload field rendered as the Markdown body
+	comments []exportItem
+}
+
+// mdOmit are payload fields that duplicate the sync metadata in front-matter
+var mdOmit = map[string]bool{"uid": true, "updatedTs": true, "sync": true}
+
+// BuildMarkdown renders the user's live notes (and optionally tasks and
+// comments) as a zip of Markdown files, from one consistent snapshot
+// Each file starts with YAML front-matter holding the sync metadata (uid,
+// entity, version, updatedAt) and the remaining payload fields, so the vault
+// can be opened in Obsidian as-is. Notes go to Notes/, tasks to Tasks/<list>/;
+// comments are appended to their parent's file. Tombstones are skipped.
+func (s *Service) BuildMarkdown(ctx context.Context, userID string, opts Options) ([]byte, error) {
+	tx, err := s.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var docs []*mdDoc
+	byKey := map[string]*mdDoc{} // "<entity>:<uid>" -> doc, for attaching comments
+
+	notes, err := queryItems(ctx, tx, "note", userID)
+	if err != nil {
+		return nil, fmt.Errorf("export note: %w", err)
+	}
+	for _, it := range notes {
+		if d := newMDDoc("note", it, "content"); d != nil {
+			d.dir = "Notes"
+			d.title, _ = syncx.GetString(d.payload, "title")
+			docs = append(docs, d)
+			byKey["note:"+d.uid] = d
+		}
+	}
+
+	if opts.IncludeTasks {
+		lists, err := queryItems(ctx, tx, "task_list", userID)
+		if err != nil {
+			return nil, fmt.Errorf("export task_list: %w", err)
+		}
+		listNames := map[string]string{}
+		for _, it := range lists {
+			if it.DeletedAt != nil {
+				continue
+			}
+			var p map[string]any
+			if json.Unmarshal(it.Payload, &p) == nil {
+				listNames[it.UID], _ = syncx.GetString(p, "name")
+			}
+		}
+
+		tasks, err := queryItems(ctx, tx, "task", userID)
+		if err != nil {
+			return nil, fmt.Errorf("export task: %w", err)
+		}
+		for _, it := range tasks {
+			d := newMDDoc("task", it, "description")
+			if d == nil {
+				continue
+			}
+			d.dir = "Tasks"
+			if listUID, _ := syncx.GetString(d.payload, "taskListUid"); listUID != "" {
+				if name, ok := listNames[listUID]; ok {
+					d.dir = path.Join("Tasks", sanitizeFileName(name))
+					d.payload["taskList"] = name
+				}
+			}
+			d.title, _ = syncx.GetString(d.payload, "title")
+			docs = append(docs, d)
+			byKey["task:"+d.uid] = d
+		}
+	}
+
+	if opts.IncludeComments {
+		comments, err := queryItems(ctx, tx, "comment", userID)
+		if err != nil {
+			return nil, fmt.Errorf("export comment: %w", err)
+		}
+		for _, it := range comments {
+			if it.DeletedAt != nil {
+				continue
+			}
+			var p map[string]any
+			if json.Unmarshal(it.Payload, &p) != nil {
+				continue
+			}
+			parentType, _ := syncx.GetString(p, "parentType")
+			parentUID, _ := syncx.GetString(p, "parentUid")
+			if d, ok := byKey[parentType+":"+parentUID]; ok {
+				d.comments = append(d.comments, it)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	used := map[string]bool{}
+	for _, d := range docs {
+		w, err := zw.Create(uniquePath(used, d.dir, d.title))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(renderMarkdown(d)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newMDDoc decodes a live row; returns nil for tombstones and unreadable payloads
+func newMDDoc(entity string, it exportItem, bodyKey string) *mdDoc {
+	if it.DeletedAt != nil {
+		return nil
+	}
+	var p map[string]any
+	if json.Unmarshal(it.Payload, &p) != nil {
+		return nil
+	}
+	return &mdDoc{entity: entity, uid: it.UID, version: it.Version, updated: it.UpdatedAt, payload: p, bodyKey: bodyKey}
+}
+
+// renderMarkdown writes front-matter, the body field and any comments
+// Front-matter values are JSON-encoded, which is valid YAML and round-trips
+// strings with colons, quotes or newlines exactly.
+func renderMarkdown(d *mdDoc) []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	writeYAML(&b, "uid", d.uid)
+	writeYAML(&b, "entity", d.entity)
+	writeYAML(&b, "version", d.version)
+	writeYAML(&b, "updatedAt", d.updated)
+	keys := make([]string, 0, len(d.payload))
+	for k := range d.payload {
+		if !mdOmit[k] && k != d.bodyKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeYAML(&b, k, d.payload[k])
+	}
+	b.WriteString("---\n\n")
+
+	if body, _ := syncx.GetString(d.payload, d.bodyKey); body != "" {
+		b.WriteString(strings.TrimRight(body, "\n"))
+		b.WriteString("\n")
+	}
+
+	if len(d.comments) > 0 {
+		b.WriteString("\n## Comments\n")
+		for _, c := range d.comments {
+			var p map[string]any
+			_ = json.Unmarshal(c.Payload, &p)
+			content, _ := syncx.GetString(p, "content")
+			fmt.Fprintf(&b, "\n### %s\n<!-- uid: %s, version: %d -->\n\n%s\n", c.UpdatedAt, c.UID, c.Version, strings.TrimRight(content, "\n"))
+		}
+	}
+	return b.Bytes()
+}
+
+func writeYAML(b *bytes.Buffer, key string, v any) {
+	var val bytes.Buffer
+	enc := json.NewEncoder(&val)
+	enc.SetEscapeHTML(false)
+	if enc.Encode(v) != nil {
+		return
+	}
+	fmt.Fprintf(b, "%s: %s", key, val.Bytes()) // Encode appends the newline
+}
+
+// sanitizeFileName makes s safe as a file name on every OS and in Obsidian
+// (which reserves # ^ [ ] | for links)
+func sanitizeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|#^[]`, r):
+			return '-'
+		}
+		return r
+	}, s)
+	s = strings.Trim(strings.TrimSpace(s), ".")
+	if r := []rune(s); len(r) > 100 {
+		s = strings.TrimSpace(string(r[:100]))
+	}
+	if s == "" {
+		return "Untitled"
+	}
+	return s
+}
+
+// uniquePath returns dir/<title>.md, numbering duplicates " (2)", " (3)", ...
+// Names are compared case-insensitively (macOS and Windows file systems).
+func uniquePath(used map[string]bool, dir, title string) string {
+	base := sanitizeFileName(title)
+	name := path.Join(dir, base+".md")
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		name = path.Join(dir, fmt.Sprintf("%s (%d).md", base, n))
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	d := newMDDoc("note", exportItem{
+		UID:       "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f",
+		Version:   3,
+		UpdatedAt: "2025-11-03T10:00:00Z",
+		Payload:   json.RawMessage(`{"uid":"c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f","sync":{"version":3},"title":"Plan: \"Q1\"","content":"Body text\n","tags":["work","ideas"]}`),
+	}, "content")
+	d.comments = []exportItem{{UID: "c2", Version: 1, UpdatedAt: "2025-11-04T08:00:00Z", Payload: json.RawMessage(`{"content":"Looks good"}`)}}
+
+	got := string(renderMarkdown(d))
+	want := `---
+uid: "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f"
+entity: "note"
+version: 3
+updatedAt: "2025-11-03T10:00:00Z"
+tags: ["work","ideas"]
+title: "Plan: \"Q1\""
+---
+
+Body text
+
+## Comments
+
+### 2025-11-04T08:00:00Z
+<!-- uid: c2, version: 1 -->
+
+Looks good
+`
+	if got != want {
+		t.Errorf("renderMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNewMDDocSkipsTombstones(t *testing.T) {
+	deleted := "2025-11-03T10:00:00Z"
+	if d := newMDDoc("note", exportItem{DeletedAt: &deleted, Payload: json.RawMessage(`{}`)}, "content"); d != nil {
+		t.Error("tombstones should not be rendered")
+	}
+}
+
+func TestUniquePath(t *testing.T) {
+	used := map[string]bool{}
+	got := []string{
+		uniquePath(used, "Notes", "Groceries"),
+		uniquePath(used, "Notes", "groceries"),
+		uniquePath(used, "Notes", "a/b: [[link]]"),
+		uniquePath(used, "Notes", "  "),
+		uniquePath(used, "Tasks", "Groceries"),
+	}
+	want := []string{"Notes/Groceries.md", "Notes/groceries (2).md", "Notes/a-b- --link--.md", "Notes/Untitled.md", "Tasks/Groceries.md"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("uniquePath #%d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if long := sanitizeFileName(strings.Repeat("x", 300)); len(long) != 100 {
+		t.Errorf("long titles should be truncated, got %d runes", len(long))
+	}
+}
+
+func TestEnqueueRejectsUnknownFormat(t *testing.T) {
+	s := NewService(nil, nil)
+	if _, err := s.Enqueue(context.Background(), "u1", Options{Format: "pdf"}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Enqueue(pdf) = %v, want ErrUnknownFormat", err)
+	}
+}
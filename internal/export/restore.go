@@ -0,0 +1,233 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// restoreOrder lists entity tables parents-first, so comments, chat
+// messages and pins are restored after the notes/tasks/chats they point at
+var restoreOrder = []string{"note", "task", "task_list_category", "task_list", "chat", "comment", "chat_message", "pin"}
+
+// optionalFiles are entity files added after archive format 1 shipped; older
+// archives without them restore as if they were empty
+var optionalFiles = map[string]bool{"pins.json": true}
+
+// RestoreOpts controls Restore
+type RestoreOpts struct {
+	// Replace deletes the user's existing entities (and activity log) before
+	// restoring. Otherwise archive rows are merged with LWW: a row is only
+	// written if it is newer than what the database already holds.
+	Replace bool
+}
+
+// RestoreResult reports what Restore changed
+type RestoreResult struct {
+	SourceUserID string         `json:"sourceUserId"` // User the archive was taken from
+	Written      map[string]int `json:"written"`      // Table -> rows inserted or updated
+	Skipped      map[string]int `json:"skipped"`      // Table -> rows kept because the DB copy was newer
+	Deleted      map[string]int `json:"deleted"`      // Table -> rows removed by Replace
+	Epoch        int            `json:"epoch"`        // New epoch (always bumped)
+}
+
+// readArchive parses an archive produced by Build
+// Returns entity items keyed by table name and the manifest's user ID.
+func readArchive(archive []byte) (map[string][]exportItem, string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, "", fmt.Errorf("read archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("archive is missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return json.NewDecoder(io.LimitReader(rc, 1<<30)).Decode(v)
+	}
+
+	var m manifest
+	if err := decode("manifest.json", &m); err != nil {
+		return nil, "", err
+	}
+	if m.Format != 1 {
+		return nil, "", fmt.Errorf("unsupported archive format %d", m.Format)
+	}
+
+	items := make(map[string][]exportItem)
+	for _, et := range entityTables {
+		var list []exportItem
+		if _, ok := files[et.file]; !ok && optionalFiles[et.file] {
+			continue
+		}
+		if err := decode(et.file, &list); err != nil {
+			return nil, "", err
+		}
+		items[et.table] = list
+	}
+	return items, m.UserID, nil
+}
+
+// Restore loads an archive produced by Build into userID's account
+// Runs in one transaction: either every row is restored or nothing is.
+// The user's epoch is bumped like a wipe, so every device discards its local
+// state and performs a full resync on its next request (EpochRequired reads the
+// epoch from the database, so this also applies to servers other than the caller).
+func Restore(ctx context.Context, db *pgxpool.Pool, userID string, archive []byte, opts RestoreOpts) (*RestoreResult, error) {
+	items, source, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RestoreResult{
+		SourceUserID: source,
+		Written:      map[string]int{},
+		Skipped:      map[string]int{},
+		Deleted:      map[string]int{},
+	}
+
+	err = pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if opts.Replace {
+			// Children before parents, mirroring POST /v1/sync/wipe
+			for i := len(restoreOrder) - 1; i >= 0; i-- {
+				table := restoreOrder[i]
+				tag, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE owner_id = $1`, userID)
+				if err != nil {
+					return fmt.Errorf("delete %s: %w", table, err)
+				}
+				res.Deleted[table] = int(tag.RowsAffected())
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM activity_log WHERE owner_id = $1`, userID); err != nil {
+				return fmt.Errorf("delete activity_log: %w", err)
+			}
+		}
+
+		for _, table := range restoreOrder {
+			for _, it := range items[table] {
+				written, err := restoreItem(ctx, tx, table, userID, it)
+				if err != nil {
+					return fmt.Errorf("restore %s %s: %w", table, it.UID, err)
+				}
+				if written {
+					res.Written[table]++
+				} else {
+					res.Skipped[table]++
+				}
+			}
+		}
+
+		// Restored rows skip the push path, so rebuild what it derives from payloads
+		if err := syncservice.ReindexLinks(ctx, tx, userID); err != nil {
+			return fmt.Errorf("reindex links: %w", err)
+		}
+
+		// Bump epoch so clients reset instead of merging stale local state
+		err := tx.QueryRow(ctx, `
+			INSERT INTO owner_state(owner_id, epoch, last_wipe_at, last_wipe_by, created_at, updated_at)
+			VALUES ($1, 2, NOW(), 'restore', NOW(), NOW())
+			ON CONFLICT (owner_id) DO UPDATE
+				SET epoch = owner_state.epoch + 1,
+					last_wipe_at = NOW(),
+					last_wipe_by = EXCLUDED.last_wipe_by,
+					updated_at = NOW()
+			RETURNING epoch
+		`, userID).Scan(&res.Epoch)
+		if err != nil {
+			return err
+		}
+		return changefeed.NotifyEpoch(ctx, tx, userID, res.Epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// restoreItem writes one archived row with its original version and timestamps
+// Table-specific columns (comment parent, chat message chat, pin target) are derived from
+// the payload the same way the sync push handlers derive them.
+func restoreItem(ctx context.Context, tx pgx.Tx, table, userID string, it exportItem) (bool, error) {
+	updatedMs, ok := syncx.ParseTimeToMs(it.UpdatedAt)
+	if !ok {
+		return false, fmt.Errorf("invalid updatedAt %q", it.UpdatedAt)
+	}
+	var deletedMs *int64
+	if it.DeletedAt != nil {
+		ms, ok := syncx.ParseTimeToMs(*it.DeletedAt)
+		if !ok {
+			return false, fmt.Errorf("invalid deletedAt %q", *it.DeletedAt)
+		}
+		deletedMs = &ms
+	}
+
+	cols := "uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json"
+	vals := "$1, $2, $3, $4, $5, $6"
+	sets := "payload_json = EXCLUDED.payload_json, updated_at_ms = EXCLUDED.updated_at_ms, deleted_at_ms = EXCLUDED.deleted_at_ms, version = EXCLUDED.version"
+	args := []any{it.UID, userID, updatedMs, deletedMs, it.Version, []byte(it.Payload)}
+
+	switch table {
+	case "comment", "chat_message", "pin":
+		var payload map[string]any
+		if err := json.Unmarshal(it.Payload, &payload); err != nil {
+			return false, err
+		}
+		if table == "comment" {
+			ext, err := syncx.ExtractComment(payload)
+			if err != nil {
+				return false, err
+			}
+			cols += ", parent_type, parent_uid"
+			vals += ", $7, $8"
+			sets += ", parent_type = EXCLUDED.parent_type, parent_uid = EXCLUDED.parent_uid"
+			args = append(args, ext.ParentType, *ext.ParentUID)
+		} else if table == "chat_message" {
+			ext, err := syncx.ExtractChatMessage(payload)
+			if err != nil {
+				return false, err
+			}
+			cols += ", chat_uid"
+			vals += ", $7"
+			sets += ", chat_uid = EXCLUDED.chat_uid"
+			args = append(args, *ext.ChatUID)
+		} else {
+			ext, err := syncx.ExtractPin(payload)
+			if err != nil {
+				return false, err
+			}
+			cols += ", target_type, target_uid"
+			vals += ", $7, $8"
+			sets += ", target_type = EXCLUDED.target_type, target_uid = EXCLUDED.target_uid"
+			args = append(args, ext.TargetType, *ext.TargetUID)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO `+table+` (`+cols+`)
+		VALUES (`+vals+`)
+		ON CONFLICT (owner_id, uid) DO UPDATE SET `+sets+`
+		WHERE EXCLUDED.updated_at_ms > `+table+`.updated_at_ms
+	`, args...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testArchive(t *testing.T, m manifest, skip string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, v any) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, et := range entityTables {
+		if et.file == skip {
+			continue
+		}
+		items := []exportItem{}
+		if et.table == "note" {
+			items = append(items, exportItem{UID: "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f", Version: 3, UpdatedAt: "2025-11-03T10:00:00Z", Payload: json.RawMessage(`{"title":"x"}`)})
+		}
+		write(et.file, items)
+	}
+	write("manifest.json", m)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadArchive(t *testing.T) {
+	items, source, err := readArchive(testArchive(t, manifest{Format: 1, UserID: "u1"}, ""))
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	if source != "u1" {
+		t.Errorf("source = %q, want u1", source)
+	}
+	if len(items["note"]) != 1 || items["note"][0].Version != 3 {
+		t.Errorf("notes = %+v, want one item at version 3", items["note"])
+	}
+	if len(items["chat_message"]) != 0 {
+		t.Errorf("chat messages = %d, want 0", len(items["chat_message"]))
+	}
+}
+
+func TestReadArchiveRejectsInvalid(t *testing.T) {
+	if _, _, err := readArchive([]byte("not a zip")); err == nil {
+		t.Error("expected error for non-zip input")
+	}
+	if _, _, err := readArchive(testArchive(t, manifest{Format: 2}, "")); err == nil || !strings.Contains(err.Error(), "format") {
+		t.Errorf("format 2: err = %v, want unsupported format", err)
+	}
+	if _, _, err := readArchive(testArchive(t, manifest{Format: 1}, "tasks.json")); err == nil || !strings.Contains(err.Error(), "tasks.json") {
+		t.Errorf("missing tasks.json: err = %v, want missing file error", err)
+	}
+	// Archives from before pins existed still restore
+	if items, _, err := readArchive(testArchive(t, manifest{Format: 1}, "pins.json")); err != nil || len(items["pin"]) != 0 {
+		t.Errorf("archive without pins.json: items %v, err %v", items["pin"], err)
+	}
+}
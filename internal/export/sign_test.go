@@ -0,0 +1,53 @@
+package export
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignedQueryRoundTrip(t *testing.T) {
+	s := NewService(nil, []byte("test-key"))
+	id := "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c"
+
+	q, expires := s.SignedQuery(id, time.Time{})
+	if !s.VerifyQuery(id, q) {
+		t.Fatalf("fresh signature rejected")
+	}
+	if d := time.Until(expires); d <= 0 || d > s.URLTTL {
+		t.Errorf("expires in %v, want within URLTTL %v", d, s.URLTTL)
+	}
+
+	// Bound to the job ID and key
+	if s.VerifyQuery("00000000-0000-0000-0000-000000000001", q) {
+		t.Errorf("signature accepted for a different job")
+	}
+	if NewService(nil, []byte("other-key")).VerifyQuery(id, q) {
+		t.Errorf("signature accepted with a different key")
+	}
+
+	// Tampered expiry
+	tampered := url.Values{"expires": {strconv.FormatInt(expires.Unix()+3600, 10)}, "sig": {q.Get("sig")}}
+	if s.VerifyQuery(id, tampered) {
+		t.Errorf("signature accepted with extended expiry")
+	}
+}
+
+func TestSignedQueryExpiry(t *testing.T) {
+	s := NewService(nil, []byte("test-key"))
+	id := "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c"
+
+	// Never outlives the archive
+	archiveExpiry := time.Now().Add(time.Minute)
+	_, expires := s.SignedQuery(id, archiveExpiry)
+	if expires.After(archiveExpiry) {
+		t.Errorf("URL expires %v after archive %v", expires, archiveExpiry)
+	}
+
+	// Expired links are rejected
+	q, _ := s.SignedQuery(id, time.Now().Add(-time.Second))
+	if s.VerifyQuery(id, q) {
+		t.Errorf("expired signature accepted")
+	}
+}
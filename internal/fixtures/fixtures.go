@@ -0,0 +1,265 @@
+// Package fixtures generates valid sync payloads for every entity from a seed
+// The same seed and base time always give the same items, byte for byte, so
+// unit tests, fuzz corpora and the seed command (via internal/devdata) all
+// exercise the invariants the push endpoints enforce:
+//   - every item has a canonical UID, an updatedTs no later than the base
+//     time and sync.version 1
+//   - parents come before their children, and a live child's parent is live
+//     (comments on notes and tasks, messages in chats, tasks in lists, pins
+//     on notes, tasks and chats)
+//   - tombstones carry sync.isDeleted and a deletedAt, and the children of a
+//     tombstoned parent are tombstoned too
+//
+// Text fields can mix in Unicode edge cases: combining marks, right-to-left
+// text, ZWJ emoji sequences, zero-width and separator characters. NUL is never
+// generated (Postgres jsonb rejects it).
+package fixtures
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types, in the order Generate emits them (parents first)
+const (
+	TaskListCategory = "task_list_category"
+	TaskList         = "task_list"
+	Note             = "note"
+	Task             = "task"
+	Comment          = "comment"
+	Chat             = "chat"
+	ChatMessage      = "chat_message"
+	Pin              = "pin"
+)
+
+// Entities lists every entity type in push order
+var Entities = []string{TaskListCategory, TaskList, Note, Task, Comment, Chat, ChatMessage, Pin}
+
+// Counts sets how much Generate produces
+type Counts struct {
+	Categories   int
+	TaskLists    int
+	Notes        int
+	Tasks        int
+	Comments     int // Per note/task, on average
+	Chats        int
+	Messages     int // Per chat
+	Pins         int // Pinned notes, tasks and chats (at most one pin per item)
+	PayloadBytes int // Approximate size of each item's body text
+
+	Tombstones float64 // Fraction of items deleted (0 to 1)
+	Unicode    bool    // Mix Unicode edge cases into text fields
+}
+
+// Item is one generated payload
+type Item struct {
+	Entity  string
+	Payload map[string]any
+}
+
+// Deleted reports whether the item is a tombstone
+func (it Item) Deleted() bool {
+	s, _ := it.Payload["sync"].(map[string]any)
+	del, _ := s["isDeleted"].(bool)
+	return del
+}
+
+// Generator draws items from a seeded source
+// A Generator is not safe for concurrent use.
+type Generator struct {
+	rng  *rand.Rand
+	base time.Time
+}
+
+// New returns a generator for seed with timestamps spread over the 90 days
+// before base
+func New(seed int64, base time.Time) *Generator {
+	return &Generator{rng: rand.New(rand.NewSource(seed)), base: base.UTC()}
+}
+
+// Generate returns one user's items, parents before children
+func (g *Generator) Generate(c Counts) []Item {
+	var items []Item
+	live := map[string][]string{} // Entity -> UIDs of live items
+	add := func(entity string, deleted bool, fields map[string]any) string {
+		uid := g.UUID()
+		ts := g.timestamp()
+		fields["uid"] = uid
+		fields["updatedTs"] = ts
+		sync := map[string]any{"version": float64(1)}
+		if deleted {
+			sync["isDeleted"] = true
+			sync["deletedAt"] = ts
+		} else {
+			live[entity] = append(live[entity], uid)
+		}
+		fields["sync"] = sync
+		items = append(items, Item{Entity: entity, Payload: fields})
+		return uid
+	}
+	tombstone := func() bool {
+		return c.Tombstones > 0 && g.rng.Float64() < c.Tombstones
+	}
+
+	colors := []string{"#e57373", "#64b5f6", "#81c784", "#ffd54f", "#ba68c8"}
+	for i := range c.Categories {
+		add(TaskListCategory, tombstone(), map[string]any{
+			"name":      g.title(c.Unicode),
+			"color":     colors[g.rng.Intn(len(colors))],
+			"sortOrder": float64(i),
+		})
+	}
+	for range c.TaskLists {
+		add(TaskList, tombstone(), map[string]any{
+			"name": g.title(c.Unicode),
+		})
+	}
+
+	type parent struct{ entity, uid string }
+	var parents []parent
+	deletedParents := map[string]bool{}
+	for range c.Notes {
+		deleted := tombstone()
+		uid := add(Note, deleted, map[string]any{
+			"title":   g.title(c.Unicode),
+			"content": g.text(c.PayloadBytes, c.Unicode),
+			"tags":    []any{g.word(), g.word()},
+		})
+		parents = append(parents, parent{Note, uid})
+		deletedParents[uid] = deleted
+	}
+	statuses := []string{"todo", "in_progress", "done"}
+	for range c.Tasks {
+		task := map[string]any{
+			"title":       g.title(c.Unicode),
+			"description": g.text(c.PayloadBytes, c.Unicode),
+			"status":      statuses[g.rng.Intn(len(statuses))],
+			"priority":    float64(g.rng.Intn(4)),
+		}
+		deleted := tombstone()
+		if lists := live[TaskList]; len(lists) > 0 {
+			task["taskListUid"] = lists[g.rng.Intn(len(lists))]
+		}
+		uid := add(Task, deleted, task)
+		parents = append(parents, parent{Task, uid})
+		deletedParents[uid] = deleted
+	}
+
+	if len(parents) > 0 {
+		for range c.Comments * len(parents) {
+			p := parents[g.rng.Intn(len(parents))]
+			add(Comment, deletedParents[p.uid] || tombstone(), map[string]any{
+				"parentType": p.entity,
+				"parentUid":  p.uid,
+				"content":    g.text(c.PayloadBytes/4, c.Unicode),
+			})
+		}
+	}
+
+	var chats []string
+	for range c.Chats {
+		deleted := tombstone()
+		uid := add(Chat, deleted, map[string]any{
+			"title": g.title(c.Unicode),
+		})
+		chats = append(chats, uid)
+		deletedParents[uid] = deleted
+	}
+	roles := []string{"user", "assistant"}
+	for _, chatUID := range chats {
+		for m := range c.Messages {
+			add(ChatMessage, deletedParents[chatUID] || tombstone(), map[string]any{
+				"chatUid": chatUID,
+				"role":    roles[m%len(roles)],
+				"content": g.text(c.PayloadBytes, c.Unicode),
+			})
+		}
+	}
+
+	// Pins go on distinct live items, so deleting a pin never orphans anything
+	var targets []parent
+	for _, entity := range []string{Note, Task, Chat} {
+		for _, uid := range live[entity] {
+			targets = append(targets, parent{entity, uid})
+		}
+	}
+	g.rng.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	for i := range min(c.Pins, len(targets)) {
+		add(Pin, tombstone(), map[string]any{
+			"targetType": targets[i].entity,
+			"targetUid":  targets[i].uid,
+			"sortOrder":  float64(i),
+		})
+	}
+	return items
+}
+
+var words = strings.Fields(`
+	alpha budget call design draft email follow-up grocery idea invoice
+	journal kickoff launch meeting notes plan project quarterly recipe
+	release research review roadmap sprint summary sync travel update weekly`)
+
+// unicodeWords are the edge cases Counts.Unicode mixes in
+var unicodeWords = []string{
+	"caf\u00e9",                      // Precomposed é
+	"cafe\u0301",                     // e + combining acute (same text, different bytes)
+	"日本語のメモ",                         // CJK
+	"مرحبا",                          // Right-to-left
+	"\u05e9\u05dc\u05d5\u05dd\u200f", // Hebrew with a right-to-left mark
+	"👩\u200d💻",                       // ZWJ emoji sequence
+	"🏳\ufe0f\u200d🌈",                 // Variation selector + ZWJ
+	"zero\u200bwidth",                // Zero-width space
+	"\ufeffbom",                      // Byte order mark
+	"line\u2028sep",                  // JSON-legal line separator
+	`"quoted" \back\`,                // JSON escapes
+	"<b>&amp;</b>",                   // Markup
+	"tab\there\r\nCRLF",              // Control characters other than NUL
+	"𝔘𝔫𝔦𝔠𝔬𝔡𝔢",                        // Outside the BMP
+}
+
+func (g *Generator) word() string {
+	return words[g.rng.Intn(len(words))]
+}
+
+// unicodeWord returns a plain word, or with unicode an edge case a third of the time
+func (g *Generator) unicodeWord(unicode bool) string {
+	if unicode && g.rng.Intn(3) == 0 {
+		return unicodeWords[g.rng.Intn(len(unicodeWords))]
+	}
+	return g.word()
+}
+
+func (g *Generator) title(unicode bool) string {
+	w := g.word()
+	return strings.ToUpper(w[:1]) + w[1:] + " " + g.unicodeWord(unicode) + " " + g.word()
+}
+
+// text returns roughly n bytes of space-separated words
+func (g *Generator) text(n int, unicode bool) string {
+	var b strings.Builder
+	for b.Len() < n {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(g.unicodeWord(unicode))
+	}
+	return b.String()
+}
+
+// UUID returns a random v4 UID drawn from the seeded source
+func (g *Generator) UUID() string {
+	id, err := uuid.NewRandomFromReader(g.rng)
+	if err != nil {
+		panic(err) // math/rand never fails
+	}
+	return id.String()
+}
+
+// timestamp returns an RFC 3339 time within the 90 days before base
+func (g *Generator) timestamp() string {
+	age := time.Duration(g.rng.Int63n(int64(90 * 24 * time.Hour)))
+	return g.base.Add(-age).Format(time.RFC3339Nano)
+}
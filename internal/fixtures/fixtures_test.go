@@ -0,0 +1,146 @@
+package fixtures_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/clock"
+	"github.com/erauner12/toolbridge-api/internal/fixtures"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+var base = time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+var everything = fixtures.Counts{
+	Categories: 3, TaskLists: 4, Notes: 20, Tasks: 20, Comments: 2, Chats: 5, Messages: 6, Pins: 8,
+	PayloadBytes: 200, Tombstones: 0.3, Unicode: true,
+}
+
+// extract runs the push extractor the entity's endpoint uses
+func extract(entity string, payload []byte) (syncx.Extracted, error) {
+	switch entity {
+	case fixtures.Comment:
+		return syncx.ExtractCommentJSON(payload)
+	case fixtures.ChatMessage:
+		return syncx.ExtractChatMessageJSON(payload)
+	case fixtures.Pin:
+		return syncx.ExtractPinJSON(payload)
+	default:
+		return syncx.ExtractCommonJSON(payload)
+	}
+}
+
+func TestGenerateIsValid(t *testing.T) {
+	syncx.SetClock(clock.NewFake(base))
+	t.Cleanup(func() { syncx.SetClock(nil) })
+
+	items := fixtures.New(1, base).Generate(everything)
+	live := map[string]bool{} // UIDs of live items so far
+	order := map[string]int{} // Entity -> position in fixtures.Entities
+	seen := map[string]bool{} // Entities emitted so far
+	for i, e := range fixtures.Entities {
+		order[e] = i
+	}
+	last, kinds, tombstones := -1, map[string]int{}, 0
+	for _, it := range items {
+		kinds[it.Entity]++
+		if order[it.Entity] < last {
+			t.Fatalf("%s emitted after a later entity type", it.Entity)
+		}
+		last = order[it.Entity]
+		seen[it.Entity] = true
+
+		payload, err := json.Marshal(it.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext, err := extract(it.Entity, payload)
+		if err != nil {
+			t.Fatalf("%s %s rejected: %v", it.Entity, payload, err)
+		}
+		if ext.NonCanonical || ext.Version != 1 || ext.UpdatedAtMs > base.UnixMilli() {
+			t.Errorf("%s %s: extracted %+v", it.Entity, ext.UID, ext)
+		}
+		if (ext.DeletedAtMs != nil) != it.Deleted() {
+			t.Errorf("%s %s: tombstone flag and deletedAt disagree", it.Entity, ext.UID)
+		}
+
+		// Live children need live parents that were emitted first
+		var parentUID string
+		switch it.Entity {
+		case fixtures.Comment:
+			parentUID = ext.ParentUID.String()
+		case fixtures.ChatMessage:
+			parentUID = ext.ChatUID.String()
+		case fixtures.Task:
+			parentUID, _ = it.Payload["taskListUid"].(string)
+		case fixtures.Pin:
+			parentUID = ext.TargetUID.String()
+		}
+		if parentUID != "" && !it.Deleted() && !live[parentUID] {
+			t.Errorf("live %s %s has no live parent %s before it", it.Entity, ext.UID, parentUID)
+		}
+		if it.Deleted() {
+			tombstones++
+		} else {
+			live[ext.UID.String()] = true
+		}
+	}
+
+	for _, e := range fixtures.Entities {
+		if !seen[e] {
+			t.Errorf("no %s generated", e)
+		}
+	}
+	if kinds[fixtures.Comment] != 2*(20+20) || kinds[fixtures.ChatMessage] != 5*6 || kinds[fixtures.Pin] != 8 {
+		t.Errorf("counts = %v", kinds)
+	}
+	if tombstones == 0 || tombstones == len(items) {
+		t.Errorf("%d of %d items are tombstones, want some", tombstones, len(items))
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	gen := func(seed int64) []byte {
+		b, err := json.Marshal(fixtures.New(seed, base).Generate(everything))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	if !bytes.Equal(gen(7), gen(7)) {
+		t.Error("same seed generated different items")
+	}
+	if bytes.Equal(gen(7), gen(8)) {
+		t.Error("different seeds generated the same items")
+	}
+}
+
+func TestGenerateUnicodeRoundTrips(t *testing.T) {
+	items := fixtures.New(3, base).Generate(fixtures.Counts{Notes: 50, PayloadBytes: 300, Unicode: true})
+	var unusual int
+	for _, it := range items {
+		b, err := json.Marshal(it.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.IndexByte(b, 0) >= 0 || bytes.Contains(b, []byte(`\u0000`)) {
+			t.Fatalf("payload contains NUL: %s", b)
+		}
+		var back map[string]any
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if back["content"] != it.Payload["content"] {
+			t.Errorf("content does not round-trip through JSON: %q", it.Payload["content"])
+		}
+		if bytes.ContainsAny(b, "\u200b\u0301\u200d") {
+			unusual++
+		}
+	}
+	if unusual == 0 {
+		t.Error("no Unicode edge cases generated")
+	}
+}
@@ -0,0 +1,583 @@
+//go:build grpc
+// +build grpc
+
+package grpcapi_test
+
+// Contract tests: the same scenarios run through the REST handlers and the
+// gRPC server on one database, and every outcome (acks, pages, cursors,
+// errors) is reduced to a transport-neutral form and compared. A feature
+// that lands on one transport but not the other fails here.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
+	"github.com/erauner12/toolbridge-api/internal/grpcapi"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// contractAck is a push ack as either transport reports it
+type contractAck struct {
+	UID         string
+	Version     int
+	UpdatedAtMs int64
+	Error       string
+}
+
+// contractPage is a pull page as either transport reports it
+type contractPage struct {
+	Upserts    []map[string]any
+	Deletes    []map[string]any
+	NextCursor string // "" when absent
+	HasMore    bool
+}
+
+// caller is one user's credentials and sync session
+// The session store is shared, so a caller may switch transports mid-scenario.
+type caller struct {
+	token   string
+	session string
+	epoch   int
+}
+
+// transport drives the API one way; every method returns an outcome that is
+// "ok" or a transport-neutral error class (see httpOutcome and grpcOutcome)
+type transport interface {
+	name() string
+	beginSession(t *testing.T, c *caller) string
+	push(t *testing.T, c *caller, entity string, items []map[string]any) ([]contractAck, string)
+	pull(t *testing.T, c *caller, entity, cursor string, limit int) (contractPage, string)
+	wipe(t *testing.T, c *caller) (int, string)
+}
+
+// ===== Outcome classes =====
+
+// httpOutcome classifies a REST response
+func httpOutcome(status int, body []byte) string {
+	switch {
+	case status >= 200 && status < 300:
+		return "ok"
+	case status == http.StatusConflict:
+		var conflict struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(body, &conflict)
+		return conflict.Error
+	case status == http.StatusBadRequest:
+		return "invalid_argument"
+	case status == http.StatusUnauthorized:
+		return "unauthenticated"
+	case status == http.StatusForbidden:
+		return "permission_denied"
+	case status == http.StatusPreconditionRequired:
+		return "failed_precondition"
+	}
+	return fmt.Sprintf("http_%d", status)
+}
+
+// grpcOutcome classifies a gRPC error the way httpOutcome does the HTTP status
+// it corresponds to; conflicts are told apart by their ErrorInfo reason
+func grpcOutcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	st := status.Convert(err)
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == syncx.ConflictDomain {
+			return info.Reason
+		}
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return "invalid_argument"
+	case codes.Unauthenticated:
+		return "unauthenticated"
+	case codes.PermissionDenied:
+		return "permission_denied"
+	case codes.FailedPrecondition:
+		return "failed_precondition"
+	}
+	return "grpc_" + st.Code().String()
+}
+
+// ===== REST =====
+
+type restTransport struct{ env *testutil.Env }
+
+func (restTransport) name() string { return "rest" }
+
+func (rt restTransport) do(t *testing.T, c *caller, method, path string, body, out any) string {
+	t.Helper()
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, rt.env.URL+path, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if c.session != "" {
+		req.Header.Set("X-Sync-Session", c.session)
+		req.Header.Set("X-Sync-Epoch", strconv.Itoa(c.epoch))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome := httpOutcome(resp.StatusCode, data)
+	if outcome == "ok" && out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decode %s: %v", method, path, data, err)
+		}
+	}
+	return outcome
+}
+
+func (rt restTransport) beginSession(t *testing.T, c *caller) string {
+	var s struct {
+		ID    string `json:"id"`
+		Epoch int    `json:"epoch"`
+	}
+	outcome := rt.do(t, c, http.MethodPost, "/v1/sync/sessions", nil, &s)
+	if outcome == "ok" {
+		c.session, c.epoch = s.ID, s.Epoch
+	}
+	return outcome
+}
+
+func (rt restTransport) push(t *testing.T, c *caller, entity string, items []map[string]any) ([]contractAck, string) {
+	var raw []struct {
+		UID       string `json:"uid"`
+		Version   int    `json:"version"`
+		UpdatedAt string `json:"updatedAt"`
+		Error     string `json:"error"`
+	}
+	outcome := rt.do(t, c, http.MethodPost, "/v1/sync/"+entity+"/push", map[string]any{"items": items}, &raw)
+	acks := make([]contractAck, 0, len(raw))
+	for _, a := range raw {
+		ack := contractAck{UID: a.UID, Version: a.Version, Error: a.Error}
+		if ms, ok := syncx.ParseTimeToMs(a.UpdatedAt); ok {
+			ack.UpdatedAtMs = ms
+		}
+		acks = append(acks, ack)
+	}
+	return acks, outcome
+}
+
+func (rt restTransport) pull(t *testing.T, c *caller, entity, cursor string, limit int) (contractPage, string) {
+	q := url.Values{"limit": {strconv.Itoa(limit)}}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	var raw struct {
+		Upserts    []map[string]any `json:"upserts"`
+		Deletes    []map[string]any `json:"deletes"`
+		NextCursor *string          `json:"nextCursor"`
+		HasMore    bool             `json:"hasMore"`
+	}
+	outcome := rt.do(t, c, http.MethodGet, "/v1/sync/"+entity+"/pull?"+q.Encode(), nil, &raw)
+	page := contractPage{Upserts: raw.Upserts, Deletes: raw.Deletes, HasMore: raw.HasMore}
+	if raw.NextCursor != nil {
+		page.NextCursor = *raw.NextCursor
+	}
+	return page, outcome
+}
+
+func (rt restTransport) wipe(t *testing.T, c *caller) (int, string) {
+	var resp struct {
+		Epoch int `json:"epoch"`
+	}
+	outcome := rt.do(t, c, http.MethodPost, "/v1/sync/wipe", map[string]string{"confirm": "WIPE"}, &resp)
+	return resp.Epoch, outcome
+}
+
+// ===== gRPC =====
+
+type grpcTransport struct {
+	sync   syncv1.SyncServiceClient
+	entity map[string]entityClient
+}
+
+// entityClient is the Push/Pull pair every entity service has
+type entityClient interface {
+	Push(ctx context.Context, in *syncv1.PushRequest, opts ...grpc.CallOption) (*syncv1.PushResponse, error)
+	Pull(ctx context.Context, in *syncv1.PullRequest, opts ...grpc.CallOption) (*syncv1.PullResponse, error)
+}
+
+// newGRPCTransport serves the gRPC API over bufconn with the production
+// interceptor chain and the REST server's services and auth settings
+func newGRPCTransport(t *testing.T, env *testutil.Env) grpcTransport {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	srv := env.Server
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcapi.RecoveryInterceptor(),
+			grpcapi.CorrelationIDInterceptor(),
+			grpcapi.AuthInterceptor(env.DB, srv.JWTCfg),
+			grpcapi.SessionInterceptor(),
+			grpcapi.EpochInterceptor(env.DB),
+		),
+	)
+	api := grpcapi.NewServer(env.DB, srv.NoteSvc, srv.TaskSvc, srv.CommentSvc, srv.ChatSvc,
+		srv.ChatMessageSvc, srv.TaskListSvc, srv.TaskListCategorySvc)
+	api.Analytics = srv.Analytics
+	api.Notify = srv.Notify
+	syncv1.RegisterSyncServiceServer(grpcServer, api)
+	syncv1.RegisterNoteSyncServiceServer(grpcServer, api)
+	syncv1.RegisterTaskSyncServiceServer(grpcServer, &grpcapi.TaskServer{Server: api})
+	syncv1.RegisterCommentSyncServiceServer(grpcServer, &grpcapi.CommentServer{Server: api})
+	syncv1.RegisterChatSyncServiceServer(grpcServer, &grpcapi.ChatServer{Server: api})
+	syncv1.RegisterChatMessageSyncServiceServer(grpcServer, &grpcapi.ChatMessageServer{Server: api})
+	syncv1.RegisterTaskListSyncServiceServer(grpcServer, &grpcapi.TaskListServer{Server: api})
+	syncv1.RegisterTaskListCategorySyncServiceServer(grpcServer, &grpcapi.TaskListCategoryServer{Server: api})
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return grpcTransport{
+		sync: syncv1.NewSyncServiceClient(conn),
+		entity: map[string]entityClient{
+			"notes":                syncv1.NewNoteSyncServiceClient(conn),
+			"tasks":                syncv1.NewTaskSyncServiceClient(conn),
+			"comments":             syncv1.NewCommentSyncServiceClient(conn),
+			"chats":                syncv1.NewChatSyncServiceClient(conn),
+			"chat_messages":        syncv1.NewChatMessageSyncServiceClient(conn),
+			"task_lists":           syncv1.NewTaskListSyncServiceClient(conn),
+			"task_list_categories": syncv1.NewTaskListCategorySyncServiceClient(conn),
+		},
+	}
+}
+
+func (grpcTransport) name() string { return "grpc" }
+
+func (grpcTransport) ctx(c *caller) context.Context {
+	md := metadata.Pairs("authorization", "Bearer "+c.token)
+	if c.session != "" {
+		md.Set("x-sync-session", c.session)
+		md.Set("x-sync-epoch", strconv.Itoa(c.epoch))
+	}
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func (gt grpcTransport) beginSession(t *testing.T, c *caller) string {
+	s, err := gt.sync.BeginSession(gt.ctx(c), &syncv1.BeginSessionRequest{})
+	if err == nil {
+		c.session, c.epoch = s.Id, int(s.Epoch)
+	}
+	return grpcOutcome(err)
+}
+
+func (gt grpcTransport) push(t *testing.T, c *caller, entity string, items []map[string]any) ([]contractAck, string) {
+	t.Helper()
+	req := &syncv1.PushRequest{}
+	for _, item := range items {
+		st, err := structpb.NewStruct(item)
+		if err != nil {
+			t.Fatalf("item %v: %v", item, err)
+		}
+		req.Items = append(req.Items, st)
+	}
+	resp, err := gt.entity[entity].Push(gt.ctx(c), req)
+	if err != nil {
+		return nil, grpcOutcome(err)
+	}
+	acks := make([]contractAck, 0, len(resp.Acks))
+	for _, a := range resp.Acks {
+		ack := contractAck{UID: a.Uid, Version: int(a.Version), Error: a.Error}
+		if a.UpdatedAt != nil {
+			ack.UpdatedAtMs = a.UpdatedAt.AsTime().UnixMilli()
+		}
+		acks = append(acks, ack)
+	}
+	return acks, "ok"
+}
+
+func (gt grpcTransport) pull(t *testing.T, c *caller, entity, cursor string, limit int) (contractPage, string) {
+	t.Helper()
+	resp, err := gt.entity[entity].Pull(gt.ctx(c), &syncv1.PullRequest{Cursor: cursor, Limit: int32(limit)})
+	if err != nil {
+		return contractPage{}, grpcOutcome(err)
+	}
+	return contractPage{
+		Upserts:    structMaps(t, resp.Upserts),
+		Deletes:    structMaps(t, resp.Deletes),
+		NextCursor: resp.NextCursor,
+		HasMore:    resp.HasMore,
+	}, "ok"
+}
+
+func (gt grpcTransport) wipe(t *testing.T, c *caller) (int, string) {
+	resp, err := gt.sync.WipeAccount(gt.ctx(c), &syncv1.WipeAccountRequest{Confirm: "WIPE"})
+	if err != nil {
+		return 0, grpcOutcome(err)
+	}
+	return int(resp.Epoch), "ok"
+}
+
+// structMaps decodes Structs the way the REST client decodes JSON objects, so
+// pulled items compare equal exactly when their JSON does
+func structMaps(t *testing.T, sts []*structpb.Struct) []map[string]any {
+	t.Helper()
+	if len(sts) == 0 {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(sts))
+	for _, st := range sts {
+		b, err := protojson.Marshal(st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// ===== Scenarios =====
+
+// newTransports starts both transports on one database
+func newTransports(t *testing.T) (*testutil.Env, []transport) {
+	t.Helper()
+	env := testutil.NewEnv(t, nil)
+	return env, []transport{restTransport{env: env}, newGRPCTransport(t, env)}
+}
+
+// newCaller returns a caller for subject with a session begun over tr
+func newCaller(t *testing.T, env *testutil.Env, tr transport, subject string) *caller {
+	t.Helper()
+	c := &caller{token: env.Token(t, subject)}
+	if outcome := tr.beginSession(t, c); outcome != "ok" {
+		t.Fatalf("%s: begin session as %s: %s", tr.name(), subject, outcome)
+	}
+	return c
+}
+
+func contractNote(uid, title, updatedTs string) map[string]any {
+	return map[string]any{"uid": uid, "title": title, "updatedTs": updatedTs}
+}
+
+// step is one call in a scenario and what it returned
+type step struct {
+	Acks    []contractAck
+	Page    contractPage
+	Outcome string
+}
+
+// runScenario runs fn against each transport as a user of its own and
+// requires identical steps
+// Users differ, so pages are compared without their (user-signed) cursors;
+// TestContract_CursorsInterchangeable covers cursors.
+func runScenario(t *testing.T, fn func(t *testing.T, tr transport, c *caller) []step) {
+	t.Helper()
+	env, transports := newTransports(t)
+	var want []step
+	for i, tr := range transports {
+		c := newCaller(t, env, tr, t.Name()+"-"+tr.name())
+		got := fn(t, tr, c)
+		for j := range got {
+			got[j].Page.NextCursor = ""
+		}
+		if i == 0 {
+			want = got
+			continue
+		}
+		for j := range want {
+			if j >= len(got) || !reflect.DeepEqual(got[j], want[j]) {
+				var g any = "(missing)"
+				if j < len(got) {
+					g = got[j]
+				}
+				t.Errorf("step %d: %s = %+v, %s = %+v", j, tr.name(), g, transports[0].name(), want[j])
+			}
+		}
+	}
+}
+
+func TestContract_PushLastWriteWins(t *testing.T) {
+	runScenario(t, func(t *testing.T, tr transport, c *caller) []step {
+		const uid = "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"
+		var steps []step
+		push := func(entity string, items ...map[string]any) {
+			acks, outcome := tr.push(t, c, entity, items)
+			steps = append(steps, step{Acks: acks, Outcome: outcome})
+		}
+		push("notes", contractNote(uid, "first", "2025-11-03T10:01:00Z"))
+		push("notes", contractNote(uid, "older", "2025-11-03T10:00:00Z"))
+		push("notes", contractNote(uid, "first", "2025-11-03T10:01:00Z"))
+		push("notes", contractNote(uid, "newer", "2025-11-03T10:02:00Z"))
+		push("notes",
+			map[string]any{"uid": uid, "updatedTs": "2025-11-03T10:03:00Z", "sync": map[string]any{"isDeleted": true}},
+			map[string]any{"title": "no uid", "updatedTs": "2025-11-03T10:00:00Z"},
+		)
+		push("tasks", map[string]any{"uid": "d1e9b7dc-b2c3-4d4e-af9f-8b7c6d5e4f3e", "title": "Task", "updatedTs": "2025-11-03T10:00:00Z"})
+
+		page, outcome := tr.pull(t, c, "notes", "", 100)
+		steps = append(steps, step{Page: page, Outcome: outcome})
+		return steps
+	})
+}
+
+func TestContract_Pagination(t *testing.T) {
+	runScenario(t, func(t *testing.T, tr transport, c *caller) []step {
+		// Timestamps collide in pairs so pages split inside a millisecond
+		var items []map[string]any
+		for i := 0; i < 5; i++ {
+			uid := fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+			items = append(items, contractNote(uid, "n", fmt.Sprintf("2025-11-03T10:00:0%dZ", i/2)))
+		}
+		acks, outcome := tr.push(t, c, "notes", items)
+		steps := []step{{Acks: acks, Outcome: outcome}}
+
+		var cursor string
+		for pages := 0; pages < 10; pages++ {
+			page, outcome := tr.pull(t, c, "notes", cursor, 2)
+			cursor = page.NextCursor
+			steps = append(steps, step{Page: page, Outcome: outcome})
+			if outcome != "ok" || !page.HasMore {
+				break
+			}
+		}
+		return steps
+	})
+}
+
+func TestContract_Errors(t *testing.T) {
+	runScenario(t, func(t *testing.T, tr transport, c *caller) []step {
+		var steps []step
+		pull := func(c *caller, entity, cursor string) {
+			page, outcome := tr.pull(t, c, entity, cursor, 10)
+			steps = append(steps, step{Page: page, Outcome: outcome})
+		}
+
+		pull(c, "notes", "not-a-cursor")
+
+		// A cursor is only good for the entity it was issued for
+		tr.push(t, c, "notes", []map[string]any{contractNote("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "n", "2025-11-03T10:00:00Z")})
+		page, _ := tr.pull(t, c, "notes", "", 10)
+		pull(c, "tasks", page.NextCursor)
+
+		noSession := &caller{token: c.token}
+		pull(noSession, "notes", "")
+		unknownSession := &caller{token: c.token, session: "no-such-session", epoch: c.epoch}
+		pull(unknownSession, "notes", "")
+		staleEpoch := &caller{token: c.token, session: c.session, epoch: c.epoch + 1}
+		pull(staleEpoch, "notes", "")
+		noEpoch := &caller{token: c.token, session: c.session}
+		pull(noEpoch, "notes", "")
+		badToken := &caller{token: "garbage", session: c.session, epoch: c.epoch}
+		pull(badToken, "notes", "")
+
+		// Wiping moves the epoch and ends the session
+		epoch, outcome := tr.wipe(t, c)
+		steps = append(steps, step{Outcome: fmt.Sprintf("%s epoch+%d", outcome, epoch-c.epoch)})
+		pull(c, "notes", "")
+		if outcome := tr.beginSession(t, c); outcome != "ok" {
+			t.Fatalf("begin session after wipe: %s", outcome)
+		}
+		pull(c, "notes", "")
+		return steps
+	})
+}
+
+// TestContract_CursorsInterchangeable pages one user's data over both
+// transports in lockstep: the pages and cursors must be identical, and a
+// cursor issued by one transport must be accepted by the other
+func TestContract_CursorsInterchangeable(t *testing.T) {
+	env, transports := newTransports(t)
+	rest, rpc := transports[0], transports[1]
+	c := newCaller(t, env, rest, "cursor-user")
+
+	var items []map[string]any
+	for i := 0; i < 5; i++ {
+		items = append(items, contractNote(fmt.Sprintf("00000000-0000-4000-8000-%012d", i), "n", "2025-11-03T10:00:00Z"))
+	}
+	if _, outcome := rpc.push(t, c, "notes", items); outcome != "ok" {
+		t.Fatalf("push: %s", outcome)
+	}
+
+	// Alternate which transport's cursor drives the next page
+	var cursor string
+	for i := 0; ; i++ {
+		restPage, restOutcome := rest.pull(t, c, "notes", cursor, 2)
+		rpcPage, rpcOutcome := rpc.pull(t, c, "notes", cursor, 2)
+		if restOutcome != "ok" || rpcOutcome != "ok" {
+			t.Fatalf("page %d: rest %s, grpc %s", i, restOutcome, rpcOutcome)
+		}
+		if !reflect.DeepEqual(restPage, rpcPage) {
+			t.Fatalf("page %d differs:\nrest %+v\ngrpc %+v", i, restPage, rpcPage)
+		}
+		if !restPage.HasMore {
+			break
+		}
+		if i > 5 {
+			t.Fatal("pagination did not finish")
+		}
+		cursor = restPage.NextCursor
+		if i%2 == 1 {
+			cursor = rpcPage.NextCursor
+		}
+	}
+}
+
+// TestContract_SessionsShared checks a session begun on one transport is
+// honored by the other, and a wipe through one invalidates it for both
+func TestContract_SessionsShared(t *testing.T) {
+	env, transports := newTransports(t)
+	rest, rpc := transports[0], transports[1]
+	c := newCaller(t, env, rpc, "session-user")
+
+	if _, outcome := rest.pull(t, c, "notes", "", 10); outcome != "ok" {
+		t.Fatalf("rest pull with a gRPC session: %s", outcome)
+	}
+	if _, outcome := rest.wipe(t, c); outcome != "ok" {
+		t.Fatalf("rest wipe: %s", outcome)
+	}
+	_, restOutcome := rest.pull(t, c, "notes", "", 10)
+	_, rpcOutcome := rpc.pull(t, c, "notes", "", 10)
+	if restOutcome != rpcOutcome || restOutcome == "ok" {
+		t.Errorf("pull after wipe: rest %s, grpc %s; want the same failure", restOutcome, rpcOutcome)
+	}
+}
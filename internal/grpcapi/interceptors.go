@@ -9,17 +9,28 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/erauner12/toolbridge-api/internal/analytics"
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // CorrelationIDInterceptor generates or reads correlation ID from metadata
@@ -36,6 +47,9 @@ func CorrelationIDInterceptor() grpc.UnaryServerInterceptor {
 			corrID = uuid.New().String()
 		}
 
+		// Continue (or start) the W3C trace context from traceparent metadata
+		ctx = telemetry.Extract(ctx, metadataCarrier(md))
+
 		// Add correlation ID to zerolog context
 		logger := log.With().Str("correlation_id", corrID).Str("grpc_method", info.FullMethod).Logger()
 		ctx = logger.WithContext(ctx)
@@ -56,6 +70,68 @@ func CorrelationIDInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// TracingInterceptor starts a server span per RPC
+// Mirrors HTTP TracingMiddleware: runs after CorrelationIDInterceptor (which
+// continues the trace context), adds trace_id to the request logger and returns
+// the server span's traceparent in the response header metadata.
+func TracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := telemetry.Tracer().Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "grpc"),
+				attribute.String("rpc.method", info.FullMethod),
+			),
+		)
+		defer span.End()
+
+		if traceID := telemetry.TraceID(ctx); traceID != "" {
+			logger := log.Ctx(ctx).With().Str("trace_id", traceID).Logger()
+			ctx = logger.WithContext(ctx)
+		}
+
+		header := metadata.MD{}
+		telemetry.Inject(ctx, metadataCarrier(header))
+		_ = grpc.SetHeader(ctx, header) // best effort; fails only outside a real server stream
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+		// Client errors (InvalidArgument, NotFound, ...) are not span errors
+		switch code {
+		case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal,
+			codes.Unavailable, codes.DataLoss:
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+
+		return resp, err
+	}
+}
+
+// metadataCarrier adapts incoming gRPC metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 // AuthInterceptor validates JWT tokens and sets userID in context
 // Mirrors HTTP auth.Middleware behavior
 func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerInterceptor {
@@ -122,6 +198,7 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 
 		// 5. Add userID to context
 		ctx = context.WithValue(ctx, auth.CtxUserID, userID)
+		errreport.SetUser(ctx, userID)
 
 		logger.Debug().Str("user_id", userID).Str("subject", subject).Msg("authenticated")
 
@@ -132,10 +209,9 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 // SessionInterceptor validates X-Sync-Session header
 // Mirrors HTTP SessionRequired middleware behavior
 func SessionInterceptor() grpc.UnaryServerInterceptor {
-	sessionStore := session.GetStore()
-
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		logger := log.Ctx(ctx)
+		sessionStore := session.GetStore()
 
 		// Skip session check for certain RPCs
 		if isSessionExempt(info.FullMethod) {
@@ -145,6 +221,16 @@ func SessionInterceptor() grpc.UnaryServerInterceptor {
 		// 1. Read X-Sync-Session from metadata
 		md, _ := metadata.FromIncomingContext(ctx)
 		sessionHeaders := md.Get("x-sync-session")
+
+		// SESSION_VALIDATION=off: a session that is sent is only recorded
+		level := session.GetValidation()
+		if level == session.ValidationOff {
+			var sessionID string
+			if len(sessionHeaders) > 0 {
+				sessionID = sessionHeaders[0]
+			}
+			return handler(withChangeSource(ctx, md, sessionID), req)
+		}
 		if len(sessionHeaders) == 0 || sessionHeaders[0] == "" {
 			logger.Warn().
 				Str("method", info.FullMethod).
@@ -177,15 +263,45 @@ func SessionInterceptor() grpc.UnaryServerInterceptor {
 				"Session does not belong to authenticated user.")
 		}
 
+		// 4. Strict: the session must have been begun at the claimed epoch
+		if level == session.ValidationStrict {
+			var claimed string
+			if epochHeaders := md.Get("x-sync-epoch"); len(epochHeaders) > 0 {
+				claimed = epochHeaders[0]
+			}
+			if claimed != strconv.Itoa(sess.Epoch) {
+				logger.Warn().
+					Str("session_id", sessionID).
+					Int("session_epoch", sess.Epoch).
+					Str("claimed_epoch", claimed).
+					Msg("session epoch does not match X-Sync-Epoch")
+				return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf(
+					"Sync session was begun at epoch %d but X-Sync-Epoch is %q. Call BeginSession to create a new session.",
+					sess.Epoch, claimed))
+			}
+		}
+
 		logger.Debug().
 			Str("session_id", sessionID).
 			Int("epoch", sess.Epoch).
 			Msg("session validated")
 
-		return handler(ctx, req)
+		return handler(withChangeSource(ctx, md, sessionID), req)
 	}
 }
 
+// withChangeSource records who is writing (session + optional device) for the activity log
+func withChangeSource(ctx context.Context, md metadata.MD, sessionID string) context.Context {
+	src := syncservice.ChangeSource{SessionID: sessionID}
+	if deviceHeaders := md.Get("x-device-id"); len(deviceHeaders) > 0 {
+		src.DeviceID = deviceHeaders[0]
+	}
+	if src == (syncservice.ChangeSource{}) {
+		return ctx
+	}
+	return syncservice.WithChangeSource(ctx, src)
+}
+
 // EpochInterceptor validates X-Sync-Epoch header
 // Mirrors HTTP EpochRequired middleware behavior
 func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
@@ -198,17 +314,9 @@ func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
 		}
 
 		// 1. Read X-Sync-Epoch from metadata
-		md, _ := metadata.FromIncomingContext(ctx)
-		epochHeaders := md.Get("x-sync-epoch")
-		if len(epochHeaders) == 0 || epochHeaders[0] == "" {
-			logger.Warn().Msg("missing X-Sync-Epoch header")
-			return nil, status.Error(codes.FailedPrecondition, "X-Sync-Epoch header required")
-		}
-
-		clientEpoch, err := strconv.Atoi(epochHeaders[0])
+		clientEpoch, err := claimedEpoch(ctx)
 		if err != nil {
-			logger.Warn().Str("epoch_header", epochHeaders[0]).Msg("invalid epoch format")
-			return nil, status.Error(codes.InvalidArgument, "X-Sync-Epoch must be an integer")
+			return nil, err
 		}
 
 		// 2. Query server epoch
@@ -245,10 +353,9 @@ func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
 				Int("server_epoch", serverEpoch).
 				Msg("epoch mismatch - client must reset")
 
-			// Return error with server epoch in message
-			// Client must detect this and trigger full reset
-			return nil, status.Error(codes.FailedPrecondition,
-				fmt.Sprintf("Epoch mismatch: server=%d, client=%d. Local data must be reset.", serverEpoch, clientEpoch))
+			// Client must detect this (ErrorInfo reason epoch_mismatch, current
+			// epoch in its metadata and the x-sync-epoch header) and trigger full reset
+			return nil, epochMismatchError(ctx, serverEpoch, clientEpoch)
 		}
 
 		logger.Debug().Int("epoch", serverEpoch).Msg("epoch validated")
@@ -257,6 +364,40 @@ func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
 	}
 }
 
+// claimedEpoch reads the client's X-Sync-Epoch from the call's metadata
+// A missing epoch is 0, a mismatch (as over HTTP), so the client learns the current one.
+func claimedEpoch(ctx context.Context) (int, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	epochHeaders := md.Get("x-sync-epoch")
+	if len(epochHeaders) == 0 || epochHeaders[0] == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(epochHeaders[0])
+	if err != nil {
+		log.Ctx(ctx).Warn().Str("epoch_header", epochHeaders[0]).Msg("invalid epoch format")
+		return 0, status.Error(codes.InvalidArgument, "X-Sync-Epoch must be an integer")
+	}
+	return n, nil
+}
+
+// epochMismatchError is the gRPC form of the HTTP 409 epoch_mismatch body:
+// FailedPrecondition with an ErrorInfo carrying the server's current epoch
+func epochMismatchError(ctx context.Context, serverEpoch, clientEpoch int) error {
+	epoch := strconv.Itoa(serverEpoch)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-sync-epoch", epoch)) // best effort, as for the correlation ID
+	st := status.New(codes.FailedPrecondition,
+		fmt.Sprintf("Epoch mismatch: server=%d, client=%d. Local data must be reset.", serverEpoch, clientEpoch))
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   syncx.ErrCodeEpochMismatch,
+		Domain:   syncx.ConflictDomain,
+		Metadata: map[string]string{"epoch": epoch},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
 // ChainUnaryServer creates a single interceptor from a chain of interceptors
 // Interceptors are executed in the order they are provided
 func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
@@ -274,6 +415,31 @@ func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnarySer
 	}
 }
 
+// StreamFromUnary runs unary interceptors once when a stream opens, so
+// streaming RPCs (PushStream) get the same auth, session and epoch checks as
+// unary ones. The interceptors see a nil request; the stream handler sees the
+// context they build.
+func StreamFromUnary(interceptors ...grpc.UnaryServerInterceptor) grpc.StreamServerInterceptor {
+	chained := ChainUnaryServer(interceptors...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		unaryInfo := &grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod}
+		_, err := chained(ss.Context(), nil, unaryInfo, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return nil, handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// contextStream is a server stream carrying the context built by interceptors
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
 // isSessionExempt returns true if the method does not require a session
 func isSessionExempt(method string) bool {
 	exempt := []string{
@@ -303,6 +469,7 @@ func RecoveryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer func() {
 			if r := recover(); r != nil {
+				metrics.ObservePanic(metrics.TransportGRPC)
 				logger := log.Ctx(ctx)
 				logger.Error().
 					Interface("panic", r).
@@ -315,6 +482,44 @@ func RecoveryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// ErrorReportingInterceptor reports panics and server-side errors to Sentry
+// Runs inside RecoveryInterceptor: panics are captured here with request context
+// (method, correlation/trace IDs, user) and re-panicked for RecoveryInterceptor
+// to turn into codes.Internal.
+func ErrorReportingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !errreport.Enabled() {
+			return handler(ctx, req)
+		}
+
+		ctx = errreport.WithRequest(ctx, nil)
+		errreport.SetTag(ctx, "grpc_method", info.FullMethod)
+		errreport.SetTag(ctx, "trace_id", telemetry.TraceID(ctx))
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get("x-correlation-id"); len(v) > 0 {
+				errreport.SetTag(ctx, "correlation_id", v[0])
+			}
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				errreport.CapturePanic(ctx, r)
+				panic(r)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		switch status.Code(err) {
+		case codes.Unknown, codes.Internal, codes.DataLoss:
+			// Handlers may already have reported the underlying cause (e.g. a failed commit)
+			if !errreport.Captured(ctx) {
+				errreport.CaptureError(ctx, err)
+			}
+		}
+		return resp, err
+	}
+}
+
 // LoggingInterceptor logs request details (simple version)
 func LoggingInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -340,3 +545,28 @@ func SetLoggerInContext(ctx context.Context) context.Context {
 	}
 	return ctx
 }
+
+// AnalyticsInterceptor counts Push/Pull payload bytes for sync analytics
+// Mirrors the HTTP analyticsBytes middleware; item and conflict counts are
+// recorded by the handlers themselves.
+func AnalyticsInterceptor(rec *analytics.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if rec == nil || err != nil {
+			return resp, err
+		}
+		if !strings.HasSuffix(info.FullMethod, "/Push") && !strings.HasSuffix(info.FullMethod, "/Pull") {
+			return resp, err
+		}
+
+		var in, out int
+		if m, ok := req.(proto.Message); ok {
+			in = proto.Size(m)
+		}
+		if m, ok := resp.(proto.Message); ok {
+			out = proto.Size(m)
+		}
+		rec.RecordBytes(auth.UserID(ctx), int64(in), int64(out))
+		return resp, err
+	}
+}
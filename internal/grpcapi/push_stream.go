@@ -0,0 +1,144 @@
+//go:build grpc
+// +build grpc
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxStreamChunk caps the items of one PushStream message, so a chunk's
+// transaction stays short; clients split larger uploads into more messages
+const maxStreamChunk = 1000
+
+// pushStreamServer is the server side of every entity's PushStream
+type pushStreamServer = grpc.BidiStreamingServer[syncv1.PushRequest, syncv1.PushResponse]
+
+// streamEpochKey carries the epoch a PushStream was opened at to its chunks' pushes
+type streamEpochKey struct{}
+
+// checkStreamEpoch fails a PushStream chunk whose account was wiped or
+// restored since the stream opened (EpochInterceptor only ran then), with the
+// unary epoch mismatch error. owner_state is read FOR SHARE in the chunk's
+// transaction, and wipes bump the epoch first, so a concurrent wipe either
+// waits for the chunk and deletes its items or commits first and fails it.
+// Unary pushes carry no stream epoch and aren't checked again.
+func checkStreamEpoch(ctx context.Context, tx pgx.Tx) error {
+	claimed, ok := ctx.Value(streamEpochKey{}).(int)
+	if !ok {
+		return nil
+	}
+	var epoch int
+	err := tx.QueryRow(ctx, `SELECT epoch FROM owner_state WHERE owner_id = $1 FOR SHARE`, auth.UserID(ctx)).Scan(&epoch)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load epoch")
+		return status.Error(codes.Internal, "failed to load sync state")
+	}
+	if epoch != claimed {
+		log.Ctx(ctx).Warn().Int("client_epoch", claimed).Int("server_epoch", epoch).Msg("epoch changed during push stream")
+		return epochMismatchError(ctx, epoch, claimed)
+	}
+	return nil
+}
+
+// pushStream serves PushStream for one entity: each message is a chunk pushed
+// exactly like a unary Push (one transaction, acks in item order) and answered
+// with its PushResponse before the next message is read. A client that doesn't
+// read acks stops the server reading, and HTTP/2 flow control then stops the
+// client sending. Chunks already acked stay committed if the stream fails.
+func (s *Server) pushStream(stream pushStreamServer, entity string, push func(context.Context, *syncv1.PushRequest) (*syncv1.PushResponse, error)) error {
+	ctx := stream.Context()
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return status.Error(codes.Unauthenticated, "missing user")
+	}
+	// Each chunk re-checks the epoch in its transaction (checkStreamEpoch)
+	epoch, err := claimedEpoch(ctx)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, streamEpochKey{}, epoch)
+
+	chunks, items := 0, 0
+	defer func() {
+		logging.Sampled(ctx).Info().
+			Str("user_id", userID).
+			Int("chunk_count", chunks).
+			Int("item_count", items).
+			Msg("grpc_" + entity + "_push_stream_completed")
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(req.Items) > maxStreamChunk {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("chunk %d has %d items; send at most %d per message", chunks+1, len(req.Items), maxStreamChunk))
+		}
+
+		resp, err := push(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		chunks++
+		items += len(req.Items)
+		// AnalyticsInterceptor only sees unary calls; count each chunk here
+		s.Analytics.RecordBytes(userID, int64(proto.Size(req)), int64(proto.Size(resp)))
+	}
+}
+
+// PushStream implements NoteSyncService.PushStream
+func (s *Server) PushStream(stream pushStreamServer) error {
+	return s.pushStream(stream, "notes", s.Push)
+}
+
+// PushStream implements TaskSyncService.PushStream
+func (ts *TaskServer) PushStream(stream pushStreamServer) error {
+	return ts.pushStream(stream, "tasks", ts.Push)
+}
+
+// PushStream implements CommentSyncService.PushStream
+func (cs *CommentServer) PushStream(stream pushStreamServer) error {
+	return cs.pushStream(stream, "comments", cs.Push)
+}
+
+// PushStream implements ChatSyncService.PushStream
+func (chs *ChatServer) PushStream(stream pushStreamServer) error {
+	return chs.pushStream(stream, "chats", chs.Push)
+}
+
+// PushStream implements ChatMessageSyncService.PushStream
+func (cms *ChatMessageServer) PushStream(stream pushStreamServer) error {
+	return cms.pushStream(stream, "chat_messages", cms.Push)
+}
+
+// PushStream implements TaskListSyncService.PushStream
+func (tls *TaskListServer) PushStream(stream pushStreamServer) error {
+	return tls.pushStream(stream, "task_lists", tls.Push)
+}
+
+// PushStream implements TaskListCategorySyncService.PushStream
+func (tlcs *TaskListCategoryServer) PushStream(stream pushStreamServer) error {
+	return tlcs.pushStream(stream, "task_list_categories", tlcs.Push)
+}
@@ -6,22 +6,40 @@ package grpcapi
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
+	"github.com/erauner12/toolbridge-api/internal/analytics"
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/notify"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/erauner12/toolbridge-api/internal/session"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// verifyCursor checks a pull cursor issued for the user's listing of entity
+// A bad cursor is InvalidArgument; the client restarts pagination without one.
+func verifyCursor(raw, userID, entity string) (syncx.Cursor, error) {
+	cur, err := syncx.VerifyCursor(raw, userID, entity)
+	if err != nil {
+		return syncx.Cursor{}, status.Error(codes.InvalidArgument, err.Error()+"; restart pagination without a cursor")
+	}
+	return cur, nil
+}
+
 // Server implements all gRPC sync services
 type Server struct {
 	// Embed unimplemented servers for forward compatibility
@@ -41,6 +59,8 @@ type Server struct {
 	ChatMessageSvc      *syncservice.ChatMessageService
 	TaskListSvc         *syncservice.TaskListService
 	TaskListCategorySvc *syncservice.TaskListCategoryService
+	Analytics           *analytics.Recorder // Per-user daily sync counters (nil disables recording)
+	Notify              *notify.Service     // Email notifications (nil disables)
 }
 
 // NewServer creates a new gRPC server instance
@@ -70,9 +90,29 @@ func NewServer(
 // NoteSyncService Implementation (Phase 1: Unary/Batch RPCs)
 // ===================================================================
 
+// itemJSON serializes a pushed item for the sync services in one pass, rather
+// than Struct → map → JSON. A Struct holding values JSON can't represent
+// (NaN, ±Inf) yields nil, which the service rejects as an invalid item.
+func itemJSON(st *structpb.Struct) json.RawMessage {
+	b, err := protojson.Marshal(st)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// upsertStruct converts a pulled payload (raw JSON) to a proto Struct in one pass
+func upsertStruct(payload json.RawMessage) (*structpb.Struct, error) {
+	st := &structpb.Struct{}
+	if err := protojson.Unmarshal(payload, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
 // Push implements NoteSyncService.Push
 func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	// 1. Get userID from context (set by auth interceptor)
 	userID := auth.UserID(ctx)
@@ -85,23 +125,31 @@ func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.Pus
 		Int("item_count", len(req.Items)).
 		Msg("grpc_notes_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "notes", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "notes", len(req.Items))
+	defer rec.Finish()
+
 	// 2. Begin transaction
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
 
 	// 3. Loop through items and call service
 	for _, itemStruct := range req.Items {
-		// Convert proto Struct to map[string]any
-		itemMap := itemStruct.AsMap()
-
-		// 4. Call shared business logic
-		svcAck := s.NoteSvc.PushNoteItem(ctx, tx, userID, itemMap)
+		// 4. Call shared business logic with the item serialized straight to JSON
+		svcAck := s.NoteSvc.PushNoteItemJSON(ctx, tx, userID, itemJSON(itemStruct))
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		// 5. Convert service PushAck to proto
 		protoAck := &syncv1.PushAck{
@@ -121,8 +169,11 @@ func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.Pus
 	// 6. Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().
 		Str("user_id", userID).
@@ -134,7 +185,7 @@ func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.Pus
 
 // Pull implements NoteSyncService.Pull
 func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	// 1. Get userID from context (set by auth interceptor)
 	userID := auth.UserID(ctx)
@@ -151,11 +202,9 @@ func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.Pul
 		limit = 1000 // max
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "notes")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().
@@ -170,11 +219,13 @@ func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.Pul
 		logger.Error().Err(err).Msg("failed to pull notes")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "notes", len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	// 4. Convert response to proto
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		st, err := structpb.NewStruct(item)
+		st, err := upsertStruct(item)
 		if err != nil {
 			logger.Warn().Err(err).Msg("failed to convert upsert to proto struct")
 			continue
@@ -195,6 +246,7 @@ func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.Pul
 	protoResp := &syncv1.PullResponse{
 		Upserts: upserts,
 		Deletes: deletes,
+		HasMore: resp.HasMore,
 	}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
@@ -204,7 +256,7 @@ func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.Pul
 		Str("user_id", userID).
 		Int("upsert_count", len(upserts)).
 		Int("delete_count", len(deletes)).
-		Bool("has_next_page", resp.NextCursor != nil).
+		Bool("has_next_page", resp.HasMore).
 		Msg("grpc_notes_pull_completed")
 
 	return protoResp, nil
@@ -222,7 +274,7 @@ type TaskServer struct {
 
 // Push implements TaskSyncService.Push
 func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -230,17 +282,27 @@ func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_tasks_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "tasks", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "tasks", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := ts.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
 	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := ts.TaskSvc.PushTaskItem(ctx, tx, userID, itemMap)
+		svcAck := ts.TaskSvc.PushTaskItemJSON(ctx, tx, userID, itemJSON(itemStruct))
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -255,8 +317,11 @@ func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	ts.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_tasks_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -264,7 +329,7 @@ func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv
 
 // Pull implements TaskSyncService.Pull
 func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -278,11 +343,9 @@ func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "tasks")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_tasks_pull_started")
@@ -292,10 +355,12 @@ func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv
 		logger.Error().Err(err).Msg("failed to pull tasks")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "tasks", len(resp.Upserts), len(resp.Deletes))
+	ts.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -307,7 +372,7 @@ func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -328,7 +393,7 @@ type CommentServer struct {
 
 // Push implements CommentSyncService.Push
 func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -336,17 +401,30 @@ func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sy
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_comments_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "comments", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "comments", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := cs.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
-	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := cs.CommentSvc.PushCommentItem(ctx, tx, userID, itemMap)
+	payloads := make([]json.RawMessage, len(req.Items))
+	for i, itemStruct := range req.Items {
+		payloads[i] = itemJSON(itemStruct)
+	}
+	for _, svcAck := range cs.CommentSvc.PushCommentBatchJSON(ctx, tx, userID, payloads) {
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -361,8 +439,11 @@ func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sy
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	cs.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_comments_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -370,7 +451,7 @@ func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sy
 
 // Pull implements CommentSyncService.Pull
 func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -384,11 +465,9 @@ func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sy
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "comments")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_comments_pull_started")
@@ -398,10 +477,12 @@ func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sy
 		logger.Error().Err(err).Msg("failed to pull comments")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "comments", len(resp.Upserts), len(resp.Deletes))
+	cs.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -413,7 +494,7 @@ func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sy
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -434,7 +515,7 @@ type ChatServer struct {
 
 // Push implements ChatSyncService.Push
 func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -442,17 +523,27 @@ func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sync
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_chats_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "chats", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "chats", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := chs.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
 	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := chs.ChatSvc.PushChatItem(ctx, tx, userID, itemMap)
+		svcAck := chs.ChatSvc.PushChatItemJSON(ctx, tx, userID, itemJSON(itemStruct))
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -467,8 +558,11 @@ func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sync
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	chs.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_chats_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -476,7 +570,7 @@ func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sync
 
 // Pull implements ChatSyncService.Pull
 func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -490,11 +584,9 @@ func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sync
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "chats")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_chats_pull_started")
@@ -504,10 +596,12 @@ func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sync
 		logger.Error().Err(err).Msg("failed to pull chats")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "chats", len(resp.Upserts), len(resp.Deletes))
+	chs.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -519,7 +613,7 @@ func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sync
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -540,7 +634,7 @@ type ChatMessageServer struct {
 
 // Push implements ChatMessageSyncService.Push
 func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -548,17 +642,30 @@ func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest)
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_chat_messages_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "chat_messages", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "chat_messages", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := cms.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
-	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := cms.ChatMessageSvc.PushChatMessageItem(ctx, tx, userID, itemMap)
+	payloads := make([]json.RawMessage, len(req.Items))
+	for i, itemStruct := range req.Items {
+		payloads[i] = itemJSON(itemStruct)
+	}
+	for _, svcAck := range cms.ChatMessageSvc.PushChatMessageBatchJSON(ctx, tx, userID, payloads) {
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -573,8 +680,11 @@ func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest)
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	cms.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_chat_messages_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -582,7 +692,7 @@ func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest)
 
 // Pull implements ChatMessageSyncService.Pull
 func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -596,11 +706,9 @@ func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest)
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "chat_messages")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_chat_messages_pull_started")
@@ -610,10 +718,12 @@ func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest)
 		logger.Error().Err(err).Msg("failed to pull chat_messages")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "chat_messages", len(resp.Upserts), len(resp.Deletes))
+	cms.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -625,7 +735,7 @@ func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest)
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -646,7 +756,7 @@ type TaskListServer struct {
 
 // Push implements TaskListSyncService.Push
 func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -654,17 +764,27 @@ func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_task_lists_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "task_lists", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "task_lists", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := tls.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
 	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := tls.TaskListSvc.PushTaskListItem(ctx, tx, userID, itemMap)
+		svcAck := tls.TaskListSvc.PushTaskListItemJSON(ctx, tx, userID, itemJSON(itemStruct))
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -679,8 +799,11 @@ func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	tls.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_task_lists_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -688,7 +811,7 @@ func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*
 
 // Pull implements TaskListSyncService.Pull
 func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -702,11 +825,9 @@ func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "task_lists")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_task_lists_pull_started")
@@ -716,10 +837,12 @@ func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*
 		logger.Error().Err(err).Msg("failed to pull task_lists")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "task_lists", len(resp.Upserts), len(resp.Deletes))
+	tls.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -731,7 +854,7 @@ func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -752,7 +875,7 @@ type TaskListCategoryServer struct {
 
 // Push implements TaskListCategorySyncService.Push
 func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.PushResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -760,17 +883,27 @@ func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRe
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_task_list_categories_push_started")
 
+	// Trace the batch as one span; per-query DB spans nest under it
+	ctx, span := telemetry.StartPushSpan(ctx, "task_list_categories", len(req.Items))
+	defer span.End()
+	rec := metrics.StartPush(metrics.TransportGRPC, "task_list_categories", len(req.Items))
+	defer rec.Finish()
+
 	tx, err := tlcs.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "db error")
 	}
 	defer tx.Rollback(ctx)
+	if err := checkStreamEpoch(ctx, tx); err != nil {
+		return nil, err
+	}
 
 	acks := make([]*syncv1.PushAck, 0, len(req.Items))
 	for _, itemStruct := range req.Items {
-		itemMap := itemStruct.AsMap()
-		svcAck := tlcs.TaskListCategorySvc.PushTaskListCategoryItem(ctx, tx, userID, itemMap)
+		svcAck := tlcs.TaskListCategorySvc.PushTaskListCategoryItemJSON(ctx, tx, userID, itemJSON(itemStruct))
+		rec.Ack(svcAck.Error, svcAck.Applied)
 
 		protoAck := &syncv1.PushAck{
 			Uid:     svcAck.UID,
@@ -785,8 +918,11 @@ func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRe
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		return nil, status.Error(codes.Internal, "commit error")
 	}
+	rec.Commit()
+	tlcs.Analytics.RecordPush(userID, len(req.Items), rec.Conflicts())
 
 	logger.Info().Str("user_id", userID).Int("success_count", len(acks)).Msg("grpc_task_list_categories_push_completed")
 	return &syncv1.PushResponse{Acks: acks}, nil
@@ -794,7 +930,7 @@ func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRe
 
 // Pull implements TaskListCategorySyncService.Pull
 func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 	userID := auth.UserID(ctx)
 	if userID == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing user")
@@ -808,11 +944,9 @@ func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRe
 		limit = 1000
 	}
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := verifyCursor(req.Cursor, userID, "task_list_categories")
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_task_list_categories_pull_started")
@@ -822,10 +956,12 @@ func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRe
 		logger.Error().Err(err).Msg("failed to pull task_list_categories")
 		return nil, status.Error(codes.Internal, "pull failed")
 	}
+	metrics.ObservePull(metrics.TransportGRPC, "task_list_categories", len(resp.Upserts), len(resp.Deletes))
+	tlcs.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	upserts := make([]*structpb.Struct, 0, len(resp.Upserts))
 	for _, item := range resp.Upserts {
-		if st, err := structpb.NewStruct(item); err == nil {
+		if st, err := upsertStruct(item); err == nil {
 			upserts = append(upserts, st)
 		}
 	}
@@ -837,7 +973,7 @@ func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRe
 		}
 	}
 
-	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes}
+	protoResp := &syncv1.PullResponse{Upserts: upserts, Deletes: deletes, HasMore: resp.HasMore}
 	if resp.NextCursor != nil {
 		protoResp.NextCursor = *resp.NextCursor
 	}
@@ -1045,7 +1181,7 @@ func (s *Server) WipeAccount(ctx context.Context, req *syncv1.WipeAccountRequest
 
 	// Delete all entity rows for this user
 	deleted := make(map[string]int32)
-	tables := []string{"chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
+	tables := []string{"pin", "chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
 
 	for _, table := range tables {
 		var count int
@@ -1063,6 +1199,52 @@ func (s *Server) WipeAccount(ctx context.Context, req *syncv1.WipeAccountRequest
 		deleted[table] = int32(count)
 	}
 
+	// Clear the activity feed too (it would otherwise reference wiped entities)
+	if _, err := tx.Exec(ctx, `DELETE FROM activity_log WHERE owner_id = $1`, userID); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to delete activity log")
+		return nil, status.Error(codes.Internal, "delete failed: activity_log")
+	}
+
+	// The link index only describes the wiped items
+	if _, err := tx.Exec(ctx, `DELETE FROM link WHERE owner_id = $1`, userID); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to delete links")
+		return nil, status.Error(codes.Internal, "delete failed: link")
+	}
+
+	// Grants reference the wiped notes and chats (list memberships go with task_list)
+	if _, err := tx.Exec(ctx, `DELETE FROM item_grant WHERE owner_id = $1`, userID); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to delete item grants")
+		return nil, status.Error(codes.Internal, "delete failed: item_grant")
+	}
+
+	// Exports hold pre-wipe copies of the data
+	if _, err := tx.Exec(ctx, `DELETE FROM export_job WHERE owner_id = $1`, userID); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to delete export jobs")
+		return nil, status.Error(codes.Internal, "delete failed: export_job")
+	}
+
+	// Pending imports would repopulate the account after the wipe
+	if _, err := tx.Exec(ctx, `DELETE FROM import_job WHERE owner_id = $1`, userID); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to delete import jobs")
+		return nil, status.Error(codes.Internal, "delete failed: import_job")
+	}
+
+	// Tell the user by email (queued only if the wipe commits)
+	counts := make(map[string]int, len(deleted))
+	for table, n := range deleted {
+		counts[table] = int(n)
+	}
+	if err := s.Notify.Enqueue(ctx, tx, userID, notify.WipeMessage(userID, newEpoch, counts)); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to queue wipe notification")
+		return nil, status.Error(codes.Internal, "notification queue failed")
+	}
+
+	// Close the user's change notification sockets (sent only if the wipe commits)
+	if err := changefeed.NotifyEpoch(ctx, tx, userID, newEpoch); err != nil {
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to notify epoch change")
+		return nil, status.Error(codes.Internal, "epoch notification failed")
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Str("userId", userID).Msg("Failed to commit wipe transaction")
@@ -6,6 +6,7 @@ package grpcapi
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"testing"
@@ -15,12 +16,14 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -66,15 +69,17 @@ func setupTestGrpcServer(t *testing.T, pool *pgxpool.Pool) *grpc.Server {
 	lis = bufconn.Listen(bufSize)
 
 	// Create gRPC server with full interceptor chain
+	interceptors := []grpc.UnaryServerInterceptor{
+		RecoveryInterceptor(),
+		CorrelationIDInterceptor(),
+		AuthInterceptor(pool, auth.JWTCfg{HS256Secret: "test-secret", DevMode: true}),
+		SessionInterceptor(),
+		EpochInterceptor(pool),
+		LoggingInterceptor(),
+	}
 	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			RecoveryInterceptor(),
-			CorrelationIDInterceptor(),
-			AuthInterceptor(pool, auth.JWTCfg{HS256Secret: "test-secret", DevMode: true}),
-			SessionInterceptor(),
-			EpochInterceptor(pool),
-			LoggingInterceptor(),
-		),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.StreamInterceptor(StreamFromUnary(interceptors...)),
 	)
 
 	// Create and register server implementation
@@ -332,6 +337,17 @@ func TestEpochInterceptor_EpochMismatch(t *testing.T) {
 	if !contains(st.Message(), "Epoch mismatch") {
 		t.Errorf("Expected epoch mismatch message, got: %s", st.Message())
 	}
+
+	// Clients branch on the ErrorInfo, not the message
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			info = ei
+		}
+	}
+	if info == nil || info.Reason != "epoch_mismatch" || info.Metadata["epoch"] != fmt.Sprint(session.Epoch) {
+		t.Errorf("ErrorInfo = %v, want reason epoch_mismatch with epoch %d", info, session.Epoch)
+	}
 }
 
 // ===== Core RPC Tests =====
@@ -1171,6 +1187,190 @@ func TestConcurrentPush(t *testing.T) {
 	}
 }
 
+// ===== Streaming Push Tests =====
+
+func TestNotePushStream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	grpcServer := setupTestGrpcServer(t, pool)
+	defer grpcServer.Stop()
+
+	conn, syncClient, noteClient, _, _, _, _ := createTestClients(t)
+	defer conn.Close()
+
+	userID := "test-user-push-stream"
+	ctx := createDevModeContext(userID)
+
+	session, err := syncClient.BeginSession(ctx, &syncv1.BeginSessionRequest{})
+	if err != nil {
+		t.Fatalf("BeginSession failed: %v", err)
+	}
+	authCtx := createAuthenticatedContext(userID, session.Id, int(session.Epoch))
+
+	noteItem := func(i int) *structpb.Struct {
+		st, _ := structpb.NewStruct(map[string]interface{}{
+			"uid":       fmt.Sprintf("ffff%04d-0000-0000-0000-000000000000", i),
+			"title":     fmt.Sprintf("Streamed Note %d", i),
+			"updatedTs": "2025-11-09T10:00:00Z",
+			"sync":      map[string]interface{}{"version": 1},
+		})
+		return st
+	}
+
+	// Each chunk is acked before the next is sent
+	stream, err := noteClient.PushStream(authCtx)
+	if err != nil {
+		t.Fatalf("PushStream failed: %v", err)
+	}
+	for chunk := 0; chunk < 3; chunk++ {
+		items := []*structpb.Struct{noteItem(chunk * 2), noteItem(chunk*2 + 1)}
+		if err := stream.Send(&syncv1.PushRequest{Items: items}); err != nil {
+			t.Fatalf("Send chunk %d failed: %v", chunk, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv chunk %d failed: %v", chunk, err)
+		}
+		if len(resp.Acks) != 2 {
+			t.Fatalf("chunk %d: expected 2 acks, got %d", chunk, len(resp.Acks))
+		}
+		for i, ack := range resp.Acks {
+			if ack.Uid != items[i].Fields["uid"].GetStringValue() || ack.Error != "" || ack.Version != 1 {
+				t.Errorf("chunk %d ack %d = %+v", chunk, i, ack)
+			}
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("expected EOF after CloseSend, got %v", err)
+	}
+
+	pullResp, err := noteClient.Pull(authCtx, &syncv1.PullRequest{Limit: 100})
+	if err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if len(pullResp.Upserts) != 6 {
+		t.Errorf("Expected 6 notes after streaming push, got %d", len(pullResp.Upserts))
+	}
+
+	// An oversized chunk ends the stream
+	stream, err = noteClient.PushStream(authCtx)
+	if err != nil {
+		t.Fatalf("PushStream failed: %v", err)
+	}
+	big := make([]*structpb.Struct, maxStreamChunk+1)
+	for i := range big {
+		big[i] = noteItem(100 + i)
+	}
+	_ = stream.Send(&syncv1.PushRequest{Items: big})
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("oversized chunk: expected InvalidArgument, got %v", err)
+	}
+
+	// Streams are checked like unary calls when they open
+	stream, err = noteClient.PushStream(ctx)
+	if err != nil {
+		t.Fatalf("PushStream failed: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("missing session: expected FailedPrecondition, got %v", err)
+	}
+
+	// Each chunk re-checks the epoch: after a wipe the next chunk is refused
+	stream, err = noteClient.PushStream(authCtx)
+	if err != nil {
+		t.Fatalf("PushStream failed: %v", err)
+	}
+	if err := stream.Send(&syncv1.PushRequest{Items: []*structpb.Struct{noteItem(200)}}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv before wipe failed: %v", err)
+	}
+	wipeResp, err := syncClient.WipeAccount(authCtx, &syncv1.WipeAccountRequest{Confirm: "WIPE"})
+	if err != nil {
+		t.Fatalf("WipeAccount failed: %v", err)
+	}
+	_ = stream.Send(&syncv1.PushRequest{Items: []*structpb.Struct{noteItem(201)}})
+	_, err = stream.Recv()
+	st, _ := status.FromError(err)
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			info = ei
+		}
+	}
+	if st.Code() != codes.FailedPrecondition || info == nil || info.Reason != "epoch_mismatch" || info.Metadata["epoch"] != fmt.Sprint(wipeResp.Epoch) {
+		t.Errorf("chunk after wipe: got %v (%v), want epoch_mismatch with epoch %d", err, info, wipeResp.Epoch)
+	}
+	newSession, err := syncClient.BeginSession(ctx, &syncv1.BeginSessionRequest{})
+	if err != nil {
+		t.Fatalf("BeginSession after wipe failed: %v", err)
+	}
+	pullResp, err = noteClient.Pull(createAuthenticatedContext(userID, newSession.Id, int(newSession.Epoch)), &syncv1.PullRequest{Limit: 100})
+	if err != nil {
+		t.Fatalf("Pull after wipe failed: %v", err)
+	}
+	if len(pullResp.Upserts) != 0 {
+		t.Errorf("Expected no notes after wipe, got %d", len(pullResp.Upserts))
+	}
+}
+
+// fakeServerStream is a server stream with only a context
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func TestStreamFromUnary(t *testing.T) {
+	type key struct{}
+	var order []string
+	mark := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			order = append(order, name+" "+info.FullMethod)
+			return handler(context.WithValue(ctx, key{}, name), req)
+		}
+	}
+	reject := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return nil, status.Error(codes.FailedPrecondition, "rejected")
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/PushStream"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	var seen interface{}
+	err := StreamFromUnary(mark("a"), mark("b"))(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		seen = stream.Context().Value(key{})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "b" {
+		t.Errorf("handler context value = %v, want b", seen)
+	}
+	if len(order) != 2 || order[0] != "a "+info.FullMethod || order[1] != "b "+info.FullMethod {
+		t.Errorf("interceptor order = %v", order)
+	}
+
+	called := false
+	err = StreamFromUnary(reject)(nil, ss, info, func(interface{}, grpc.ServerStream) error {
+		called = true
+		return nil
+	})
+	if status.Code(err) != codes.FailedPrecondition || called {
+		t.Errorf("rejected stream: err = %v, handler called = %v", err, called)
+	}
+}
+
 // Helper functions
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsSubstring(s, substr)))
@@ -1184,3 +1384,32 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestItemJSONRoundTrip(t *testing.T) {
+	item, err := structpb.NewStruct(map[string]any{
+		"uid":       "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f",
+		"title":     "Round trip",
+		"updatedTs": "2025-11-03T10:00:00Z",
+		"sync":      map[string]any{"version": 2, "isDeleted": false},
+		"tags":      []any{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := itemJSON(item)
+	if payload == nil {
+		t.Fatal("itemJSON returned nil")
+	}
+	back, err := upsertStruct(payload)
+	if err != nil {
+		t.Fatalf("upsertStruct: %v", err)
+	}
+	if !proto.Equal(item, back) {
+		t.Errorf("round trip changed the item:\n got %v\nwant %v", back, item)
+	}
+
+	if _, err := upsertStruct([]byte(`[1,2]`)); err == nil {
+		t.Error("non-object payload converted")
+	}
+}
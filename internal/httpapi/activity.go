@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/rs/zerolog/log"
+)
+
+// GetActivity handles GET /v1/activity?since=<RFC3339>&limit=<n>&cursor=<c>
+// Returns the authenticated user's recent changes across all entities, newest
+// first: what was created/updated/deleted and by which session/device.
+// Pass nextCursor back as cursor to page further back; since bounds the feed
+// (e.g. "what changed since yesterday").
+func (s *Server) GetActivity(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	limit := parseLimit(r.URL.Query().Get("limit"), 100, 500)
+
+	var beforeID int64
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		id, ok := syncservice.DecodeActivityCursor(c)
+		if !ok {
+			writeError(w, r, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		beforeID = id
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid since: must be RFC3339")
+			return
+		}
+		since = t
+	}
+
+	feed, err := s.ActivitySvc.ListActivity(ctx, userID, beforeID, since, limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to list activity")
+		writeError(w, r, http.StatusInternalServerError, "failed to list activity")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, feed)
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestActivityFeed_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+	_, _ = pool.Exec(context.Background(), "DELETE FROM activity_log")
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+		ActivitySvc:     syncservice.NewActivityService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	push := func(updatedTs string, deleted bool) {
+		t.Helper()
+		item := map[string]any{
+			"uid":       "5b2f0c8e-3d4a-4e6b-9c1d-2a3b4c5d6e7f",
+			"title":     "Activity",
+			"updatedTs": updatedTs,
+			"sync":      map[string]any{"version": float64(1), "isDeleted": deleted},
+		}
+		w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session)
+		if w.Code != 200 {
+			t.Fatalf("push failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	push("2025-11-03T10:00:00Z", false)
+	push("2025-11-03T10:00:00Z", false) // idempotent re-push is not logged
+	push("2025-11-03T11:00:00Z", false)
+	push("2025-11-03T12:00:00Z", true)
+
+	w := makeRequestWithSession(t, router, "GET", "/v1/activity?limit=2", nil, session)
+	if w.Code != 200 {
+		t.Fatalf("GET /v1/activity: %d %s", w.Code, w.Body.String())
+	}
+	var page syncservice.ActivityFeed
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Items) != 2 || page.NextCursor == nil {
+		t.Fatalf("first page = %d items (cursor %v), want 2 with cursor", len(page.Items), page.NextCursor)
+	}
+	if page.Items[0].Action != syncservice.ActionDeleted || page.Items[1].Action != syncservice.ActionUpdated {
+		t.Errorf("actions = %s, %s; want deleted, updated", page.Items[0].Action, page.Items[1].Action)
+	}
+	if page.Items[0].SessionID == nil || *page.Items[0].SessionID != session.ID {
+		t.Errorf("sessionId = %v, want %s", page.Items[0].SessionID, session.ID)
+	}
+
+	w = makeRequestWithSession(t, router, "GET", "/v1/activity?limit=2&cursor="+*page.NextCursor, nil, session)
+	var rest syncservice.ActivityFeed
+	if err := json.NewDecoder(w.Body).Decode(&rest); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(rest.Items) != 1 || rest.Items[0].Action != syncservice.ActionCreated || rest.Items[0].Entity != "note" {
+		t.Fatalf("second page = %+v, want one created note", rest.Items)
+	}
+
+	w = makeRequestWithSession(t, router, "GET", "/v1/activity?cursor=bogus", nil, session)
+	if w.Code != 400 {
+		t.Errorf("invalid cursor status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,171 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/usage"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// AdminAuth requires Authorization: Bearer <ADMIN_TOKEN> on operator endpoints
+// Admin routes are only mounted when a token is configured, so an empty token
+// never reaches this middleware.
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := r.Header.Get("Authorization")
+			if len(h) < 8 || h[:7] != "Bearer " ||
+				subtle.ConstantTimeCompare([]byte(h[7:]), []byte(token)) != 1 {
+				writeError(w, r, 401, "admin token required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// adminRoutes mounts operator endpoints under /admin
+func (s *Server) adminRoutes(r chi.Router) {
+	r.Use(AdminAuth(s.AdminToken))
+	r.Get("/log-level", s.GetLogLevel)
+	r.Put("/log-level", s.SetLogLevel)
+	r.Get("/usage", s.GetUsage)
+	r.Get("/usage/users/{id}", s.GetUserUsage)
+	r.Get("/integrity", s.GetIntegrity)
+	r.Post("/integrity/run", s.RunIntegrity)
+	r.Get("/settings", s.GetSettings)
+	r.Post("/reload", s.ReloadSettings)
+}
+
+// logLevelResp is the body for GET/PUT /admin/log-level
+type logLevelResp struct {
+	Level       string `json:"level"`
+	SampleEvery uint32 `json:"sampleEvery"` // keep 1 of every N sampled info/debug logs (0 = off)
+}
+
+// logLevelReq changes the runtime log level and/or sampling; omitted fields are unchanged
+type logLevelReq struct {
+	Level       *string `json:"level,omitempty"`
+	SampleEvery *uint32 `json:"sampleEvery,omitempty"`
+}
+
+// GetLogLevel handles GET /admin/log-level
+func (s *Server) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, logLevelResp{
+		Level:       logging.Level().String(),
+		SampleEvery: logging.SampleEvery(),
+	})
+}
+
+// SetLogLevel handles PUT /admin/log-level
+// Example: {"level":"debug","sampleEvery":10}
+func (s *Server) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, 400, "invalid json")
+		return
+	}
+
+	if req.Level != nil {
+		if _, err := logging.SetLevel(*req.Level); err != nil {
+			writeError(w, r, 400, "invalid level: "+*req.Level)
+			return
+		}
+	}
+	if req.SampleEvery != nil {
+		logging.SetSampleEvery(*req.SampleEvery)
+	}
+
+	log.Ctx(r.Context()).Warn().
+		Str("level", logging.Level().String()).
+		Uint32("sample_every", logging.SampleEvery()).
+		Msg("admin: log settings updated")
+
+	s.GetLogLevel(w, r)
+}
+
+// usageResp is the body for GET /admin/usage
+type usageResp struct {
+	*usage.Snapshot
+	TopUsers []usage.UserUsage `json:"topUsers"` // Largest storage first
+}
+
+// loadUsage returns the cached usage snapshot (?refresh=true recomputes it)
+// Writes the error response and returns nil on failure.
+func (s *Server) loadUsage(w http.ResponseWriter, r *http.Request) *usage.Snapshot {
+	if s.Usage == nil {
+		writeError(w, r, 404, "usage statistics disabled")
+		return nil
+	}
+	snap, err := s.Usage.Snapshot(r.Context(), r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("admin: failed to compute usage")
+		writeError(w, r, 500, "failed to compute usage")
+		return nil
+	}
+	return snap
+}
+
+// GetUsage handles GET /admin/usage?limit=20&refresh=true
+// Global row counts by entity, storage bytes, active sessions and request
+// volumes, plus the top users by storage. Served from a cached snapshot.
+func (s *Server) GetUsage(w http.ResponseWriter, r *http.Request) {
+	snap := s.loadUsage(w, r)
+	if snap == nil {
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 20, 1000)
+	top := snap.PerUser
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	writeJSON(w, 200, usageResp{Snapshot: snap, TopUsers: top})
+}
+
+// GetUserUsage handles GET /admin/usage/users/{id}
+func (s *Server) GetUserUsage(w http.ResponseWriter, r *http.Request) {
+	snap := s.loadUsage(w, r)
+	if snap == nil {
+		return
+	}
+	u, ok := snap.User(chi.URLParam(r, "id"))
+	if !ok {
+		writeError(w, r, 404, "no usage for user")
+		return
+	}
+	writeJSON(w, 200, u)
+}
+
+// GetIntegrity handles GET /admin/integrity
+// Returns the last orphan check report (runs one if none has completed yet).
+func (s *Server) GetIntegrity(w http.ResponseWriter, r *http.Request) {
+	if s.Integrity == nil {
+		writeError(w, r, 404, "integrity checks disabled")
+		return
+	}
+	if report := s.Integrity.Last(); report != nil {
+		writeJSON(w, 200, report)
+		return
+	}
+	s.RunIntegrity(w, r)
+}
+
+// RunIntegrity handles POST /admin/integrity/run
+// Runs a check now with the configured policy and returns its report.
+func (s *Server) RunIntegrity(w http.ResponseWriter, r *http.Request) {
+	if s.Integrity == nil {
+		writeError(w, r, 404, "integrity checks disabled")
+		return
+	}
+	report, err := s.Integrity.Check(r.Context())
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("admin: integrity check failed")
+		writeError(w, r, 500, "integrity check failed")
+		return
+	}
+	writeJSON(w, 200, report)
+}
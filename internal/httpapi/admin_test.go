@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+)
+
+func newAdminRouter(s *Server) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/admin", s.adminRoutes)
+	return r
+}
+
+func TestAdminAuth(t *testing.T) {
+	router := newAdminRouter(&Server{AdminToken: "admin-secret"})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing token", "", 401},
+		{"wrong token", "Bearer nope", 401},
+		{"not bearer", "admin-secret", 401},
+		{"valid token", "Bearer admin-secret", 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/log-level", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	defer logging.SetSampleEvery(0)
+
+	router := newAdminRouter(&Server{AdminToken: "admin-secret"})
+
+	req := httptest.NewRequest("PUT", "/admin/log-level", strings.NewReader(`{"level":"debug","sampleEvery":10}`))
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var resp logLevelResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Level != "debug" || resp.SampleEvery != 10 {
+		t.Errorf("got %+v, want level=debug sampleEvery=10", resp)
+	}
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Errorf("global level = %s, want debug", zerolog.GlobalLevel())
+	}
+
+	// Invalid level is rejected and leaves the level unchanged
+	req = httptest.NewRequest("PUT", "/admin/log-level", strings.NewReader(`{"level":"loud"}`))
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Errorf("global level = %s, want debug", zerolog.GlobalLevel())
+	}
+}
+
+func TestUsageDisabledWithoutCollector(t *testing.T) {
+	router := newAdminRouter(&Server{AdminToken: "admin-secret"})
+
+	for _, path := range []string{"/admin/usage", "/admin/usage/users/abc", "/admin/integrity"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 404 {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/analytics"
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// syncAnalyticsResponse is the body for GET /v1/sync/analytics
+type syncAnalyticsResponse struct {
+	From   string           `json:"from"` // YYYY-MM-DD (UTC), inclusive
+	To     string           `json:"to"`
+	Days   []analytics.Day  `json:"days"` // Oldest first; days without activity are omitted
+	Totals analytics.Counts `json:"totals"`
+}
+
+// GetSyncAnalytics handles GET /v1/sync/analytics?days=<1..365>
+// Returns the authenticated user's daily sync activity (pushes, pulls, items,
+// bytes, conflicts) for the last N UTC days including today (default 30).
+func (s *Server) GetSyncAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.Analytics == nil {
+		writeError(w, r, http.StatusNotFound, "sync analytics disabled")
+		return
+	}
+
+	days := parseLimit(r.URL.Query().Get("days"), 30, 365)
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -(days - 1))
+
+	history, err := s.Analytics.Daily(r.Context(), userID, from, to)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to load sync analytics")
+		writeError(w, r, http.StatusInternalServerError, "failed to load sync analytics")
+		return
+	}
+
+	resp := syncAnalyticsResponse{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Days: history,
+	}
+	for _, d := range history {
+		resp.Totals.Pushes += d.Pushes
+		resp.Totals.Pulls += d.Pulls
+		resp.Totals.ItemsPushed += d.ItemsPushed
+		resp.Totals.ItemsPulled += d.ItemsPulled
+		resp.Totals.BytesIn += d.BytesIn
+		resp.Totals.BytesOut += d.BytesOut
+		resp.Totals.Conflicts += d.Conflicts
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// analyticsBytes counts request and response payload bytes for sync analytics
+// Only successful responses are recorded; item and conflict counts are
+// recorded by the push/pull handlers themselves.
+func (s *Server) analyticsBytes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Analytics == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		if status := ww.Status(); status == 0 || status < 300 {
+			s.Analytics.RecordBytes(auth.UserID(r.Context()), body.n, int64(ww.BytesWritten()))
+		}
+	})
+}
+
+// countingReader counts bytes read from a request body
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
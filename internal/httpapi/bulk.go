@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// BulkUpdate handles POST /v1/bulk/{type}
+// Body: {"filter": {...}, "action": "set_status", "value": "archived", "dryRun": false}
+// Applies the action to every matching note or task in one transaction (at
+// most syncservice.MaxBulkItems; "more" asks the caller to repeat).
+func (s *Server) BulkUpdate(w http.ResponseWriter, r *http.Request) {
+	typ := chi.URLParam(r, "type")
+	if !s.Bulk.Type(typ) {
+		writeError(w, r, http.StatusBadRequest, "type must be notes or tasks")
+		return
+	}
+	var req syncservice.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	res, err := s.Bulk.Apply(r.Context(), auth.UserID(r.Context()), typ, req)
+	var me *syncservice.MutationError
+	switch {
+	case errors.Is(err, syncservice.ErrInvalidBulk):
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	case errors.As(err, &me):
+		// Nothing was written: a push validation failed for one item
+		writeError(w, r, http.StatusConflict, "cannot apply: "+me.Message)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to apply bulk update")
+		writeError(w, r, http.StatusInternalServerError, "failed to apply bulk update")
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
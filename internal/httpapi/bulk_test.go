@@ -0,0 +1,89 @@
+package httpapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestBulkUpdate(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "bulk-user")
+	ctx := context.Background()
+
+	const (
+		done1 = "e0000000-0000-4000-8000-000000000001"
+		done2 = "e0000000-0000-4000-8000-000000000002"
+		open  = "e0000000-0000-4000-8000-000000000003"
+	)
+	task := func(uid, status, ts string) map[string]any {
+		item := note(uid, "task", ts)
+		item["status"] = status
+		return item
+	}
+	acks, err := c.Push(ctx, "tasks", []map[string]any{
+		task(done1, "completed", "2025-11-03T10:00:00Z"),
+		task(done2, "completed", "2025-11-03T10:00:01Z"),
+		task(open, "open", "2025-11-03T10:00:02Z"),
+	})
+	if err != nil || len(acks) != 3 {
+		t.Fatalf("push = %+v, %v", acks, err)
+	}
+
+	if code := call(t, env, c, http.MethodPost, "/v1/bulk/tasks", `{"filter":{},"action":"delete"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("empty filter status = %d, want 400", code)
+	}
+	if code := call(t, env, c, http.MethodPost, "/v1/bulk/notes", `{"filter":{"status":["active"]},"action":"move"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("moving notes status = %d, want 400", code)
+	}
+
+	var res struct {
+		Updated int  `json:"updated"`
+		More    bool `json:"more"`
+	}
+	archive := `{"filter":{"status":["completed"],"updatedBefore":"2025-11-03T10:00:02Z"},"action":"set_status","value":"archived"}`
+	dryRun := strings.Replace(archive, `"action"`, `"dryRun":true,"action"`, 1)
+	call(t, env, c, http.MethodPost, "/v1/bulk/tasks", dryRun, &res)
+	if res.Updated != 2 {
+		t.Errorf("dry run = %+v, want 2", res)
+	}
+	if code := call(t, env, c, http.MethodPost, "/v1/bulk/tasks", archive, &res); code != http.StatusOK || res.Updated != 2 || res.More {
+		t.Fatalf("archive = %d %+v, want 2 updated", code, res)
+	}
+	// Archived tasks no longer match, so a repeat is a no-op
+	call(t, env, c, http.MethodPost, "/v1/bulk/tasks", archive, &res)
+	if res.Updated != 0 {
+		t.Errorf("repeat archive = %+v, want 0", res)
+	}
+
+	// The updates sync like any other edit
+	pull, err := c.Pull(ctx, "tasks", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := 0
+	for _, item := range pull.Upserts {
+		if item["status"] == "archived" && item["done"] == true {
+			archived++
+		}
+	}
+	if archived != 2 {
+		t.Errorf("pulled %d archived tasks, want 2", archived)
+	}
+
+	tag := fmt.Sprintf(`{"filter":{"uids":[%q,%q]},"action":"add_tag","value":"later"}`, done1, open)
+	if call(t, env, c, http.MethodPost, "/v1/bulk/tasks", tag, &res); res.Updated != 2 {
+		t.Errorf("add tag = %+v, want 2", res)
+	}
+	var got struct {
+		Payload map[string]any `json:"payload"`
+	}
+	call(t, env, c, http.MethodGet, "/v1/tasks/"+open, "", &got)
+	if tags, _ := got.Payload["tags"].([]any); len(tags) != 1 || tags[0] != "later" {
+		t.Errorf("tags = %v, want [later]", got.Payload["tags"])
+	}
+}
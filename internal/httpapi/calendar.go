@@ -0,0 +1,115 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/calendar"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// calendarFeedResponse is the body for calendar feed endpoints
+// URL is relative to the API base URL and only returned when the token is issued.
+type calendarFeedResponse struct {
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RotateCalendarFeed handles POST /v1/calendar/feed
+// Issues a new tokenized ICS feed URL for the user's tasks, replacing any
+// previous one (the old URL stops working). The URL is only returned here.
+func (s *Server) RotateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Calendar == nil {
+		writeError(w, r, http.StatusNotFound, "calendar feed disabled")
+		return
+	}
+
+	feed, err := s.Calendar.Rotate(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to issue calendar feed")
+		writeError(w, r, http.StatusInternalServerError, "failed to issue calendar feed")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Msg("calendar feed issued")
+	writeJSON(w, http.StatusCreated, calendarFeedResponse{
+		URL:       "/v1/calendar/" + feed.Token + "/tasks.ics",
+		CreatedAt: feed.CreatedAt,
+	})
+}
+
+// GetCalendarFeed handles GET /v1/calendar/feed
+// Reports whether a feed URL is active (the token itself can't be shown again).
+func (s *Server) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Calendar == nil {
+		writeError(w, r, http.StatusNotFound, "calendar feed disabled")
+		return
+	}
+
+	feed, err := s.Calendar.Get(r.Context(), userID)
+	if errors.Is(err, calendar.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "no calendar feed")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to load calendar feed")
+		writeError(w, r, http.StatusInternalServerError, "failed to load calendar feed")
+		return
+	}
+	writeJSON(w, http.StatusOK, calendarFeedResponse{CreatedAt: feed.CreatedAt})
+}
+
+// RevokeCalendarFeed handles DELETE /v1/calendar/feed
+func (s *Server) RevokeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Calendar == nil {
+		writeError(w, r, http.StatusNotFound, "calendar feed disabled")
+		return
+	}
+
+	err := s.Calendar.Revoke(r.Context(), userID)
+	if errors.Is(err, calendar.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "no calendar feed")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to revoke calendar feed")
+		writeError(w, r, http.StatusInternalServerError, "failed to revoke calendar feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CalendarTasksICS handles GET /v1/calendar/{token}/tasks.ics
+// Unauthenticated: the token in the path authorizes the feed, so calendar apps
+// (Google, Apple) can subscribe to the URL directly.
+func (s *Server) CalendarTasksICS(w http.ResponseWriter, r *http.Request) {
+	if s.Calendar == nil {
+		writeError(w, r, http.StatusNotFound, "calendar feed disabled")
+		return
+	}
+
+	ics, err := s.Calendar.RenderTasks(r.Context(), chi.URLParam(r, "token"))
+	if errors.Is(err, calendar.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "calendar feed not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to render calendar feed")
+		writeError(w, r, http.StatusInternalServerError, "failed to render calendar feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(ics)))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ics)
+}
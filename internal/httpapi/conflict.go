@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// conflictResponse is the body of every sync conflict: 409 for an epoch
+// mismatch, 412 for a failed If-Match (409 when the version was expected some
+// other way). Error is a syncx.ErrCode* value; the other fields are the
+// server's current state so the client can recover without parsing Message.
+type conflictResponse struct {
+	Error           string `json:"error"`
+	Message         string `json:"message"`
+	UID             string `json:"uid,omitempty"`
+	Version         *int   `json:"version,omitempty"`         // Current server version of the item
+	ExpectedVersion *int   `json:"expectedVersion,omitempty"` // Version the client sent
+	Epoch           *int   `json:"epoch,omitempty"`           // Current server epoch
+	CorrelationID   string `json:"correlation_id,omitempty"`
+}
+
+// writeEpochConflict writes the 409 epoch_mismatch response
+func writeEpochConflict(w http.ResponseWriter, r *http.Request, epoch int) {
+	w.Header().Set("X-Sync-Epoch", strconv.Itoa(epoch))
+	writeJSON(w, http.StatusConflict, conflictResponse{
+		Error:         syncx.ErrCodeEpochMismatch,
+		Message:       "sync epoch changed; reset local data and begin a new session",
+		Epoch:         &epoch,
+		CorrelationID: GetCorrelationID(r.Context()),
+	})
+}
+
+// writeVersionConflict writes the version_mismatch response for a mutation
+// RFC 7232: a failed If-Match is 412 Precondition Failed.
+func writeVersionConflict(w http.ResponseWriter, r *http.Request, err *syncservice.VersionMismatchError, usedIfMatch bool) {
+	code := http.StatusPreconditionFailed
+	if !usedIfMatch {
+		code = http.StatusConflict
+	}
+	writeJSON(w, code, conflictResponse{
+		Error:           syncx.ErrCodeVersionMismatch,
+		Message:         err.Error(),
+		UID:             err.UID,
+		Version:         &err.Actual,
+		ExpectedVersion: &err.Expected,
+		CorrelationID:   GetCorrelationID(r.Context()),
+	})
+}
@@ -0,0 +1,33 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+// GetCounters handles GET /v1/sync/counters?tz=<IANA zone>
+// Returns cheap aggregates for dashboard screens (open tasks per list, notes
+// created this week, unread chats) so they don't have to pull whole tables.
+// tz sets where the week starts (default UTC).
+func (s *Server) GetCounters(w http.ResponseWriter, r *http.Request) {
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid tz: must be an IANA time zone")
+			return
+		}
+		loc = l
+	}
+
+	counters, err := s.CounterSvc.Counters(r.Context(), auth.UserID(r.Context()), loc)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to compute counters")
+		writeError(w, r, http.StatusInternalServerError, "failed to compute counters")
+		return
+	}
+	writeJSON(w, http.StatusOK, counters)
+}
@@ -0,0 +1,78 @@
+package httpapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestCounters(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	c := env.Client(t, "counters-user")
+
+	const (
+		list = "b0000000-0000-4000-8000-000000000001"
+		chat = "b1000000-0000-4000-8000-000000000001"
+	)
+	push := func(entity string, items ...map[string]any) {
+		t.Helper()
+		acks, err := c.Push(ctx, entity, items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ack := range acks {
+			if ack.Error != "" {
+				t.Fatalf("push %s: ack %+v", entity, ack)
+			}
+		}
+	}
+	item := func(n int, fields map[string]any) map[string]any {
+		it := note(fmt.Sprintf("b2000000-0000-4000-8000-%012d", n), "item", "2025-11-03T10:00:00Z")
+		for k, v := range fields {
+			it[k] = v
+		}
+		return it
+	}
+
+	push("task_lists", note(list, "Groceries", "2025-11-03T10:00:00Z"))
+	push("tasks",
+		item(1, map[string]any{"taskListUid": list, "status": "todo"}),
+		item(2, map[string]any{"taskListUid": list, "status": "in_progress"}),
+		item(3, map[string]any{"taskListUid": list, "status": "done"}),
+		item(4, map[string]any{"status": "todo"}),
+	)
+	push("notes", item(5, nil))
+	push("chats", note(chat, "chat", "2025-11-03T10:00:00Z"))
+	push("chat_messages",
+		item(6, map[string]any{"chatUid": chat, "role": "assistant"}),
+		item(7, map[string]any{"chatUid": chat, "role": "assistant", "read": true}),
+		item(8, map[string]any{"chatUid": chat, "role": "user"}),
+	)
+
+	var got struct {
+		OpenTasks       int64 `json:"openTasks"`
+		OpenTasksByList []struct {
+			TaskListUID string `json:"taskListUid"`
+			Count       int64  `json:"count"`
+		} `json:"openTasksByList"`
+		NotesCreatedThisWeek int64 `json:"notesCreatedThisWeek"`
+		UnreadChats          int64 `json:"unreadChats"`
+		UnreadMessages       int64 `json:"unreadMessages"`
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/sync/counters?tz=Europe/Berlin", "", &got); code != http.StatusOK {
+		t.Fatalf("counters status = %d", code)
+	}
+	if got.OpenTasks != 3 || len(got.OpenTasksByList) != 2 || got.OpenTasksByList[0].TaskListUID != list || got.OpenTasksByList[0].Count != 2 {
+		t.Errorf("open tasks = %d %+v, want 2 in the list and 1 in none", got.OpenTasks, got.OpenTasksByList)
+	}
+	if got.NotesCreatedThisWeek != 1 || got.UnreadChats != 1 || got.UnreadMessages != 1 {
+		t.Errorf("counters = %+v, want 1 note, 1 unread chat with 1 message", got)
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/sync/counters?tz=Mars/Olympus", "", nil); code != http.StatusBadRequest {
+		t.Errorf("bad tz status = %d, want 400", code)
+	}
+}
@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/devdata"
+	"github.com/erauner12/toolbridge-api/internal/fixtures"
+	"github.com/rs/zerolog/log"
+)
+
+// devSeedMax caps each count in a /dev/seed request
+const devSeedMax = 1000
+
+// devSeedRequest is the body of POST /dev/seed; omitted fields keep their defaults
+type devSeedRequest struct {
+	Reset        bool  `json:"reset"` // Wipe the account first, so the result is exactly the generated set
+	Seed         int64 `json:"seed"`  // Same seed, same UIDs and content (default 1)
+	Categories   int   `json:"categories"`
+	Notes        int   `json:"notes"`
+	Tasks        int   `json:"tasks"`
+	TaskLists    int   `json:"taskLists"`
+	Comments     int   `json:"comments"` // Per note/task, on average
+	Chats        int   `json:"chats"`
+	Messages     int   `json:"messages"` // Per chat
+	Pins         int   `json:"pins"`
+	PayloadBytes int   `json:"payloadBytes"`
+
+	Tombstones float64 `json:"tombstones"` // Fraction of items seeded deleted (0 to 1)
+	Unicode    bool    `json:"unicode"`    // Mix Unicode edge cases into text fields
+}
+
+type devSeedResponse struct {
+	Epoch   int            `json:"epoch"`
+	Seed    int64          `json:"seed"`
+	Items   map[string]int `json:"items"`
+	Deleted map[string]int `json:"deleted,omitempty"` // Set when reset
+}
+
+// DevSeed populates the authenticated (usually X-Debug-Sub) user's account
+// with generated data, pushed through the sync services like any client
+// push. With "reset": true the account is wiped first, bumping the epoch
+// and ending every session, so one call gets front-end and MCP developers
+// to a known state. Only mounted in dev mode.
+//
+// Returns:
+// - 200: the current epoch and the number of items seeded per entity
+// - 400: invalid body or counts out of range
+func (s *Server) DevSeed(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := devSeedRequest{
+		Seed:         1,
+		Categories:   1,
+		Notes:        10,
+		Tasks:        10,
+		TaskLists:    2,
+		Comments:     1,
+		Chats:        2,
+		Messages:     5,
+		Pins:         3,
+		PayloadBytes: 256,
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	for _, n := range []int{req.Categories, req.Notes, req.Tasks, req.TaskLists, req.Comments, req.Chats, req.Messages, req.Pins} {
+		if n < 0 || n > devSeedMax {
+			writeError(w, r, http.StatusBadRequest, "counts must be between 0 and 1000")
+			return
+		}
+	}
+	if req.PayloadBytes < 0 || req.PayloadBytes > 64*1024 {
+		writeError(w, r, http.StatusBadRequest, "payloadBytes must be between 0 and 65536")
+		return
+	}
+	if req.Tombstones < 0 || req.Tombstones > 1 {
+		writeError(w, r, http.StatusBadRequest, "tombstones must be between 0 and 1")
+		return
+	}
+
+	ctx := r.Context()
+	resp := devSeedResponse{Seed: req.Seed}
+	if req.Reset {
+		epoch, deleted, err := s.wipeUser(ctx, userID)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		sessionStore().DeleteUserSessions(userID)
+		resp.Epoch, resp.Deleted = epoch, deleted
+	} else {
+		epoch, err := currentEpoch(ctx, s.DB, userID)
+		if err != nil {
+			log.Error().Err(err).Str("userId", userID).Msg("Failed to read epoch")
+			writeError(w, r, http.StatusInternalServerError, "epoch lookup failed")
+			return
+		}
+		resp.Epoch = epoch
+	}
+
+	seeder := devdata.NewSeeder(s.DB, devdata.Config{Counts: fixtures.Counts{
+		Categories:   req.Categories,
+		Notes:        req.Notes,
+		Tasks:        req.Tasks,
+		TaskLists:    req.TaskLists,
+		Comments:     req.Comments,
+		Chats:        req.Chats,
+		Messages:     req.Messages,
+		Pins:         req.Pins,
+		PayloadBytes: req.PayloadBytes,
+		Tombstones:   req.Tombstones,
+		Unicode:      req.Unicode,
+	}}, req.Seed)
+	items, err := seeder.Seed(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to seed dev data")
+		writeError(w, r, http.StatusInternalServerError, "seed failed")
+		return
+	}
+	resp.Items = items
+
+	log.Info().
+		Str("userId", userID).
+		Int("epoch", resp.Epoch).
+		Bool("reset", req.Reset).
+		Interface("items", items).
+		Msg("Dev data seeded")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DevReset wipes the authenticated user's account like POST /v1/sync/wipe,
+// without the confirmation body or a session. Only mounted in dev mode.
+//
+// Returns:
+// - 200: the new epoch and deletion counts (same shape as the wipe response)
+func (s *Server) DevReset(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	epoch, deleted, err := s.wipeUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sessionsDeleted := sessionStore().DeleteUserSessions(userID)
+
+	log.Info().
+		Str("userId", userID).
+		Int("newEpoch", epoch).
+		Int("sessionsInvalidated", sessionsDeleted).
+		Msg("Dev account reset")
+	writeJSON(w, http.StatusOK, wipeResponse{
+		Epoch:   epoch,
+		Deleted: deleted,
+	})
+}
@@ -0,0 +1,131 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+// devPost calls one of the dev-mode fixture endpoints as subject (X-Debug-Sub)
+func devPost(t *testing.T, env *testutil.Env, path, subject, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, env.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Debug-Sub", subject)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestDevSeedAndReset(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	const sub = "dev-user"
+	c := syncclient.New(env.URL)
+	c.Subject = sub
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var seeded struct {
+		Epoch int            `json:"epoch"`
+		Items map[string]int `json:"items"`
+	}
+	body := `{"reset":true,"notes":3,"tasks":2,"taskLists":1,"comments":1,"chats":1,"messages":2}`
+	if code := devPost(t, env, "/dev/seed", sub, body, &seeded); code != http.StatusOK {
+		t.Fatalf("seed status = %d", code)
+	}
+	want := map[string]int{"note": 3, "task": 2, "task_list_category": 1, "task_list": 1, "comment": 5, "chat": 1, "chat_message": 2, "pin": 3}
+	if fmt.Sprint(seeded.Items) != fmt.Sprint(want) {
+		t.Errorf("seeded %v, want %v", seeded.Items, want)
+	}
+
+	// The reset ended the session; a new one sees the new epoch and the data
+	var se *syncclient.StatusError
+	if _, err := c.Pull(ctx, "notes", "", 0); !errors.As(err, &se) || se.Status != http.StatusPreconditionRequired {
+		t.Errorf("pull on the pre-reset session: err = %v, want 428", err)
+	}
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, epoch := c.Session(); epoch != seeded.Epoch {
+		t.Errorf("session epoch = %d, seed reported %d", epoch, seeded.Epoch)
+	}
+	pullUIDs := func() []string {
+		t.Helper()
+		resp, err := c.Pull(ctx, "notes", "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var uids []string
+		for _, n := range resp.Upserts {
+			uids = append(uids, n["uid"].(string))
+		}
+		return uids
+	}
+	first := pullUIDs()
+	if len(first) != 3 {
+		t.Fatalf("pulled %d notes, want 3", len(first))
+	}
+
+	// Same seed, same state
+	if code := devPost(t, env, "/dev/seed", sub, body, &seeded); code != http.StatusOK {
+		t.Fatalf("reseed status = %d", code)
+	}
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if again := pullUIDs(); fmt.Sprint(again) != fmt.Sprint(first) {
+		t.Errorf("reseeded notes %v, want %v", again, first)
+	}
+
+	var reset struct {
+		Epoch int `json:"epoch"`
+	}
+	if code := devPost(t, env, "/dev/reset", sub, "", &reset); code != http.StatusOK {
+		t.Fatalf("reset status = %d", code)
+	}
+	if reset.Epoch != seeded.Epoch+1 {
+		t.Errorf("epoch after reset = %d, want %d", reset.Epoch, seeded.Epoch+1)
+	}
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if uids := pullUIDs(); len(uids) != 0 {
+		t.Errorf("pulled %v after reset, want nothing", uids)
+	}
+}
+
+func TestDevEndpointsOutsideDevMode(t *testing.T) {
+	env := testutil.NewEnv(t, func(c *config.Config) { c.Env = "staging" })
+	for _, path := range []string{"/dev/seed", "/dev/reset"} {
+		req, _ := http.NewRequest(http.MethodPost, env.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+env.Token(t, "someone"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("POST %s outside dev mode = %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
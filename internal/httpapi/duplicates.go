@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// mergeRequest is the body of POST /v1/duplicates/merge
+type mergeRequest struct {
+	Type  string      `json:"type"`  // notes or tasks
+	Keep  uuid.UUID   `json:"keep"`  // The item the others merge into
+	Merge []uuid.UUID `json:"merge"` // The duplicates to tombstone
+}
+
+// duplicateType checks a duplicates type ("notes" or "tasks")
+func (s *Server) duplicateType(w http.ResponseWriter, r *http.Request, typ string) bool {
+	if !s.Duplicates.Type(typ) {
+		writeError(w, r, http.StatusBadRequest, "type must be notes or tasks")
+		return false
+	}
+	return true
+}
+
+// ListDuplicates handles GET /v1/duplicates?type=notes&limit=<n>
+// Returns groups of the caller's live items sharing a title, largest first.
+// identical marks groups whose content matches too (safe to merge blindly).
+func (s *Server) ListDuplicates(w http.ResponseWriter, r *http.Request) {
+	typ := r.URL.Query().Get("type")
+	if !s.duplicateType(w, r, typ) {
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 50, 200)
+
+	groups, err := s.Duplicates.Find(r.Context(), auth.UserID(r.Context()), typ, limit)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to find duplicates")
+		writeError(w, r, http.StatusInternalServerError, "failed to find duplicates")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"groups": groups})
+}
+
+// MergeDuplicates handles POST /v1/duplicates/merge
+// Body: {"type": "notes", "keep": "<uid>", "merge": ["<uid>", ...]}
+// Moves the duplicates' comments (and subtasks) to keep and tombstones them,
+// all or nothing. keep's own content is unchanged.
+func (s *Server) MergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	var req mergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if !s.duplicateType(w, r, req.Type) {
+		return
+	}
+
+	res, err := s.Duplicates.Merge(r.Context(), auth.UserID(r.Context()), req.Type, req.Keep, req.Merge)
+	var me *syncservice.MutationError
+	switch {
+	case errors.Is(err, syncservice.ErrInvalidMerge):
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf(
+			"keep and merge must be distinct live %s (1 to %d to merge)", req.Type, syncservice.MaxMergeItems))
+	case errors.As(err, &me):
+		writeError(w, r, http.StatusConflict, "cannot merge: "+me.Message)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to merge duplicates")
+		writeError(w, r, http.StatusInternalServerError, "failed to merge duplicates")
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,91 @@
+package httpapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestDuplicates(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "dup-user")
+	ctx := context.Background()
+
+	const (
+		keep    = "d0000000-0000-4000-8000-000000000001"
+		dup     = "d0000000-0000-4000-8000-000000000002"
+		other   = "d0000000-0000-4000-8000-000000000003"
+		comment = "d0000000-0000-4000-8000-000000000004"
+	)
+	pushOne(t, c, note(keep, "Groceries", "2025-11-03T10:00:00Z"))
+	pushOne(t, c, note(dup, " groceries", "2025-11-03T10:00:01Z"))
+	pushOne(t, c, note(other, "Something else", "2025-11-03T10:00:02Z"))
+	acks, err := c.Push(ctx, "comments", []map[string]any{{
+		"uid":        comment,
+		"parentType": "note",
+		"parentUid":  dup,
+		"content":    "milk",
+		"updatedTs":  "2025-11-03T10:00:03Z",
+		"sync":       map[string]any{"version": float64(1)},
+	}})
+	if err != nil || len(acks) != 1 || acks[0].Error != "" {
+		t.Fatalf("comment push = %+v, %v", acks, err)
+	}
+
+	var found struct {
+		Groups []struct {
+			Title     string `json:"title"`
+			Identical bool   `json:"identical"`
+			Items     []struct {
+				UID string `json:"uid"`
+			} `json:"items"`
+		} `json:"groups"`
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/duplicates?type=notes", "", &found); code != http.StatusOK {
+		t.Fatalf("duplicates status = %d", code)
+	}
+	if len(found.Groups) != 1 || found.Groups[0].Title != "groceries" || !found.Groups[0].Identical ||
+		len(found.Groups[0].Items) != 2 || found.Groups[0].Items[0].UID != keep {
+		t.Fatalf("duplicates = %+v, want one identical group, oldest first", found.Groups)
+	}
+
+	// keep can't be merged into itself
+	bad := fmt.Sprintf(`{"type":"notes","keep":%q,"merge":[%q]}`, keep, keep)
+	if code := call(t, env, c, http.MethodPost, "/v1/duplicates/merge", bad, nil); code != http.StatusBadRequest {
+		t.Errorf("self-merge status = %d, want 400", code)
+	}
+
+	var res struct {
+		Merged     int `json:"merged"`
+		Reparented int `json:"reparented"`
+	}
+	body := fmt.Sprintf(`{"type":"notes","keep":%q,"merge":[%q]}`, keep, dup)
+	if code := call(t, env, c, http.MethodPost, "/v1/duplicates/merge", body, &res); code != http.StatusOK {
+		t.Fatalf("merge status = %d", code)
+	}
+	if res.Merged != 1 || res.Reparented != 1 {
+		t.Errorf("merge = %+v, want 1 merged, 1 re-parented", res)
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/notes/"+dup, "", nil); code != http.StatusGone {
+		t.Errorf("merged note status = %d, want 410", code)
+	}
+	var moved struct {
+		Payload map[string]any `json:"payload"`
+	}
+	call(t, env, c, http.MethodGet, "/v1/comments/"+comment, "", &moved)
+	if moved.Payload["parentUid"] != keep {
+		t.Errorf("comment parent = %v, want %s", moved.Payload["parentUid"], keep)
+	}
+
+	call(t, env, c, http.MethodGet, "/v1/duplicates?type=notes", "", &found)
+	if len(found.Groups) != 0 {
+		t.Errorf("duplicates after merge = %+v, want none", found.Groups)
+	}
+	// A second merge of the now-deleted duplicate is rejected
+	if code := call(t, env, c, http.MethodPost, "/v1/duplicates/merge", body, nil); code != http.StatusBadRequest {
+		t.Errorf("repeat merge status = %d, want 400", code)
+	}
+}
@@ -0,0 +1,71 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+// The tests in httpapi_test run the API against a database of their own
+// (testutil.NewEnv); they can't be in package httpapi, which testutil imports.
+
+func note(uid, title, updatedTs string) map[string]any {
+	return map[string]any{
+		"uid":       uid,
+		"title":     title,
+		"updatedTs": updatedTs,
+		"sync":      map[string]any{"version": float64(1)},
+	}
+}
+
+func pushOne(t *testing.T, c *syncclient.Client, item map[string]any) syncclient.PushAck {
+	t.Helper()
+	acks, err := c.Push(context.Background(), "notes", []map[string]any{item})
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if len(acks) != 1 || acks[0].Error != "" {
+		t.Fatalf("push acks = %+v", acks)
+	}
+	return acks[0]
+}
+
+// call sends a request with c's token and session and decodes a 2xx JSON body into out
+func call(t *testing.T, env *testutil.Env, c *syncclient.Client, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, env.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, epoch := c.Session()
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-Sync-Session", session)
+	req.Header.Set("X-Sync-Epoch", fmt.Sprint(epoch))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
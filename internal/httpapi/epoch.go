@@ -1,6 +1,8 @@
 package httpapi
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,11 +12,32 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// currentEpoch returns the user's sync epoch, creating their owner_state row
+// (epoch 1) on first use
+// The common case is a single read; concurrent first requests converge on the
+// same row.
+func currentEpoch(ctx context.Context, db *pgxpool.Pool, userID string) (int, error) {
+	var epoch int
+	err := db.QueryRow(ctx, `SELECT epoch FROM owner_state WHERE owner_id = $1`, userID).Scan(&epoch)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return epoch, err
+	}
+	err = db.QueryRow(ctx, `
+		INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
+		VALUES ($1, 1, NOW(), NOW())
+		ON CONFLICT (owner_id) DO UPDATE SET owner_id = excluded.owner_id
+		RETURNING epoch
+	`, userID).Scan(&epoch)
+	return epoch, err
+}
+
 // EpochRequired middleware validates that the client's X-Sync-Epoch header
 // matches the server's current epoch for the authenticated user.
 //
-// If the client's epoch is less than the server's epoch, returns 409 Conflict
-// with the current epoch in the response body and X-Sync-Epoch header.
+// A missing or different epoch (behind after a wipe, or ahead after a restore)
+// returns 409 Conflict with {"error":"epoch_mismatch","epoch":<current>} (see
+// conflictResponse) and the current epoch in the X-Sync-Epoch header; the
+// client resets and begins a new session. A header that isn't an integer is a 400.
 //
 // This prevents stale clients from pushing/pulling data after a server wipe.
 func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
@@ -22,66 +45,38 @@ func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userID := auth.UserID(r.Context())
 			if userID == "" {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				writeError(w, r, http.StatusUnauthorized, "unauthorized")
 				return
 			}
 
-			// Load current user epoch (lazy create if not exists)
-			var epoch int
-			err := db.QueryRow(r.Context(), `
-				INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
-				VALUES ($1, 1, NOW(), NOW())
-				ON CONFLICT (owner_id) DO NOTHING
-				RETURNING epoch
-			`, userID).Scan(&epoch)
-
-			if err != nil {
-				// If insert did nothing, select existing epoch
-				if err == pgx.ErrNoRows {
-					err = db.QueryRow(r.Context(),
-						`SELECT epoch FROM owner_state WHERE owner_id = $1`,
-						userID,
-					).Scan(&epoch)
-					if err != nil {
-						log.Error().Err(err).Str("userId", userID).Msg("Failed to load epoch")
-						http.Error(w, "epoch load failed", http.StatusInternalServerError)
-						return
-					}
-				} else {
-					log.Error().Err(err).Str("userId", userID).Msg("Failed to initialize epoch")
-					http.Error(w, "epoch init failed", http.StatusInternalServerError)
+			clientEpoch := 0
+			if h := r.Header.Get("X-Sync-Epoch"); h != "" {
+				n, err := strconv.Atoi(h)
+				if err != nil {
+					writeError(w, r, http.StatusBadRequest, "X-Sync-Epoch must be an integer")
 					return
 				}
+				clientEpoch = n
 			}
 
-			// Compare with client's epoch from header
-			clientEpochStr := r.Header.Get("X-Sync-Epoch")
-			clientEpoch := 0
-			if clientEpochStr != "" {
-				clientEpoch, _ = strconv.Atoi(clientEpochStr)
+			epoch, err := currentEpoch(r.Context(), db, userID)
+			if err != nil {
+				log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to load epoch")
+				writeError(w, r, http.StatusInternalServerError, "epoch load failed")
+				return
 			}
 
-			// If client epoch is behind server epoch, reject with 409
-			if clientEpoch < epoch {
-				log.Warn().
+			if clientEpoch != epoch {
+				log.Ctx(r.Context()).Warn().
 					Str("userId", userID).
 					Int("clientEpoch", clientEpoch).
 					Int("serverEpoch", epoch).
 					Msg("Epoch mismatch detected - client must reset")
 
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("X-Sync-Epoch", strconv.Itoa(epoch))
-				w.Header().Set("X-Correlation-ID", r.Header.Get("X-Correlation-ID"))
-		
-				writeJSON(w, http.StatusConflict, map[string]any{
-					"error":          "epoch_mismatch",
-					"epoch":          epoch,
-					"correlation_id": r.Header.Get("X-Correlation-ID"),
-				})
+				writeEpochConflict(w, r, epoch)
 				return
 			}
 
-			// Epoch matches or client is ahead (shouldn't happen, but allow)
 			next.ServeHTTP(w, r)
 		})
 	}
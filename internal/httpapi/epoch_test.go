@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestEpochRequired_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	pull := func(epoch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/sync/notes/pull", nil)
+		req.Header.Set("X-Debug-Sub", "test-user")
+		req.Header.Set("X-Sync-Session", session.ID)
+		if epoch != "" {
+			req.Header.Set("X-Sync-Epoch", epoch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := pull(strconv.Itoa(session.Epoch)); w.Code != 200 {
+		t.Fatalf("current epoch: status = %d, want 200 (%s)", w.Code, w.Body.String())
+	}
+	if w := pull("one"); w.Code != 400 {
+		t.Errorf("malformed epoch: status = %d, want 400", w.Code)
+	}
+
+	for name, epoch := range map[string]string{
+		"missing": "",
+		"behind":  strconv.Itoa(session.Epoch - 1),
+		"ahead":   strconv.Itoa(session.Epoch + 1),
+	} {
+		w := pull(epoch)
+		if w.Code != 409 {
+			t.Errorf("%s epoch: status = %d, want 409", name, w.Code)
+			continue
+		}
+		var body struct {
+			Error string `json:"error"`
+			Epoch int    `json:"epoch"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("%s epoch: decode: %v", name, err)
+		}
+		if body.Error != "epoch_mismatch" || body.Epoch != session.Epoch {
+			t.Errorf("%s epoch: body = %+v, want epoch_mismatch at epoch %d", name, body, session.Epoch)
+		}
+		if got := w.Header().Get("X-Sync-Epoch"); got != strconv.Itoa(session.Epoch) {
+			t.Errorf("%s epoch: X-Sync-Epoch = %q, want %d", name, got, session.Epoch)
+		}
+	}
+
+	// A wipe bumps the epoch: the old session's epoch is now stale
+	if _, err := pool.Exec(context.Background(),
+		`UPDATE owner_state SET epoch = epoch + 1 WHERE owner_id = $1`, session.UserID); err != nil {
+		t.Fatalf("bump epoch: %v", err)
+	}
+	if w := pull(strconv.Itoa(session.Epoch)); w.Code != 409 {
+		t.Errorf("after wipe: status = %d, want 409", w.Code)
+	}
+}
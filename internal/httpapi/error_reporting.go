@@ -0,0 +1,46 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ErrorReportingMiddleware reports panics and 5xx responses to Sentry
+// Runs inside RecoveryMiddleware: panics are captured here with request
+// context (correlation/trace IDs, user, tenant) and re-panicked so
+// RecoveryMiddleware still writes the 500. A 5xx without a more specific event (panic, failed
+// commit) is reported once as a message.
+func ErrorReportingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !errreport.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := errreport.WithRequest(r.Context(), r)
+		errreport.SetTag(ctx, "correlation_id", GetCorrelationID(ctx))
+		errreport.SetTag(ctx, "trace_id", telemetry.TraceID(ctx))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec != http.ErrAbortHandler {
+					errreport.CapturePanic(ctx, rec)
+				}
+				panic(rec)
+			}
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route := r.URL.Path
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		errreport.CaptureStatus(ctx, r.Method, route, ww.Status())
+	})
+}
@@ -0,0 +1,211 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/export"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// exportJobResponse is the body for export job endpoints
+// DownloadURL is relative to the API base URL and only set once the job succeeded.
+type exportJobResponse struct {
+	*export.Job
+	DownloadURL       string     `json:"downloadUrl,omitempty"`
+	DownloadExpiresAt *time.Time `json:"downloadExpiresAt,omitempty"`
+}
+
+// RequestExport handles POST /v1/account/export
+// Enqueues an asynchronous export of all the user's data. Returns 202 with the
+// job; poll GET /v1/account/export/{id} until status is "succeeded".
+// An optional body selects a Markdown vault instead of the JSON archive:
+// {"format": "markdown", "includeTasks": true, "includeComments": true}.
+func (s *Server) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.Exports == nil {
+		writeError(w, r, http.StatusNotFound, "account export disabled")
+		return
+	}
+
+	var opts export.Options
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	job, err := s.Exports.Enqueue(r.Context(), userID, opts)
+	if errors.Is(err, export.ErrUnknownFormat) {
+		writeError(w, r, http.StatusBadRequest, "format must be \"json\" or \"markdown\"")
+		return
+	}
+	if errors.Is(err, export.ErrInProgress) {
+		writeError(w, r, http.StatusConflict, "an export is already in progress")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to enqueue export")
+		writeError(w, r, http.StatusInternalServerError, "failed to enqueue export")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("exportId", job.ID).Str("format", job.Format).Msg("account export requested")
+	w.Header().Set("Location", "/v1/account/export/"+job.ID)
+	writeJSON(w, http.StatusAccepted, exportJobResponse{Job: job})
+}
+
+// GetExport handles GET /v1/account/export/{id}
+// Returns job status; succeeded jobs include a freshly signed, expiring download URL.
+func (s *Server) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.Exports == nil {
+		writeError(w, r, http.StatusNotFound, "account export disabled")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, r, http.StatusNotFound, "export not found")
+		return
+	}
+
+	job, err := s.Exports.Get(r.Context(), userID, id)
+	if errors.Is(err, export.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "export not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("exportId", id).Msg("Failed to load export")
+		writeError(w, r, http.StatusInternalServerError, "failed to load export")
+		return
+	}
+
+	resp := exportJobResponse{Job: job}
+	if job.Status == export.StatusSucceeded && job.ExpiresAt != nil {
+		q, expires := s.Exports.SignedQuery(job.ID, *job.ExpiresAt)
+		resp.DownloadURL = "/v1/account/export/" + job.ID + "/download?" + q.Encode()
+		resp.DownloadExpiresAt = &expires
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DownloadExport handles GET /v1/account/export/{id}/download?expires=..&sig=..
+// Unauthenticated: the HMAC signature from GetExport authorizes the download,
+// so the URL can be handed to a browser or download manager.
+func (s *Server) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	if s.Exports == nil {
+		writeError(w, r, http.StatusNotFound, "account export disabled")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil || !s.Exports.VerifyQuery(id, r.URL.Query()) {
+		writeError(w, r, http.StatusForbidden, "invalid or expired download link")
+		return
+	}
+
+	archive, err := s.Exports.Archive(r.Context(), id)
+	if errors.Is(err, export.ErrNotFound) {
+		writeError(w, r, http.StatusGone, "export expired or unavailable")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("exportId", id).Msg("Failed to load export archive")
+		writeError(w, r, http.StatusInternalServerError, "failed to load export")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="toolbridge-export-`+id+`.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive)
+}
+
+// startedWriter records whether the response body has been started, after
+// which errors can no longer be reported with a status code
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// ExportEntity returns the handler for GET /v1/{entity}/export
+// Query: format=json|csv (csv for tasks only), includeDeleted=true,
+// updatedSince, updatedBefore (RFC 3339 or Unix ms), status, tag, taskListUid.
+// Streams the matching items as a download in the same record format as the
+// account archive: a lighter alternative when only one entity is wanted.
+func (s *Server) ExportEntity(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Exports == nil {
+			writeError(w, r, http.StatusNotFound, "export disabled")
+			return
+		}
+		q := r.URL.Query()
+		format := q.Get("format")
+		if format == "" {
+			format = export.FormatJSON
+		}
+		if format != export.FormatJSON && (format != export.FormatCSV || entity != "tasks") {
+			writeError(w, r, http.StatusBadRequest, "format must be \"json\" (or \"csv\" for tasks)")
+			return
+		}
+		since, okSince := parseSince(q.Get("updatedSince"))
+		before, okBefore := parseSince(q.Get("updatedBefore"))
+		if !okSince || !okBefore {
+			writeError(w, r, http.StatusBadRequest, "updatedSince and updatedBefore must be RFC 3339 or Unix milliseconds")
+			return
+		}
+		f := export.EntityFilter{
+			IncludeDeleted: parseIncludeDeleted(r),
+			Status:         q.Get("status"),
+			Tag:            q.Get("tag"),
+			TaskListUID:    q.Get("taskListUid"),
+		}
+		if !since.IsZero() {
+			f.UpdatedSince = since.UnixMilli()
+		}
+		if !before.IsZero() {
+			f.UpdatedBefore = before.UnixMilli()
+		}
+
+		userID := auth.UserID(r.Context())
+		contentType := "application/json"
+		if format == export.FormatCSV {
+			contentType = "text/csv; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="toolbridge-`+entity+`-`+time.Now().UTC().Format("20060102")+`.`+format+`"`)
+		w.Header().Set("Cache-Control", "private, no-store")
+
+		sw := &startedWriter{ResponseWriter: w}
+		n, err := s.Exports.WriteEntity(r.Context(), sw, userID, entity, format, f)
+		if err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Str("entity", entity).Int("written", n).Msg("Failed to export entity")
+			if !sw.started {
+				w.Header().Del("Content-Disposition")
+				writeError(w, r, http.StatusInternalServerError, "failed to export "+entity)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/export"
+	"github.com/erauner12/toolbridge-api/internal/httpapi"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestDownloadExport_RejectsUnsignedLinks(t *testing.T) {
+	srv := &httpapi.Server{Exports: export.NewService(nil, []byte("test-key"))}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	id := "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c"
+	for _, path := range []string{
+		"/v1/account/export/" + id + "/download",
+		"/v1/account/export/" + id + "/download?expires=9999999999&sig=deadbeef",
+		"/v1/account/export/not-a-uuid/download",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 403 {
+			t.Errorf("GET %s = %d, want 403", path, w.Code)
+		}
+	}
+}
+
+func TestEntityExport(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "entity-export-user")
+
+	const (
+		tagged  = "f0000000-0000-4000-8000-000000000001"
+		plain   = "f0000000-0000-4000-8000-000000000002"
+		deleted = "f0000000-0000-4000-8000-000000000003"
+	)
+	item := note(tagged, "tagged", "2025-11-03T10:00:00Z")
+	item["tags"] = []any{"work"}
+	pushOne(t, c, item)
+	pushOne(t, c, note(plain, "plain", "2025-11-03T10:00:01Z"))
+	pushOne(t, c, note(deleted, "deleted", "2025-11-03T10:00:02Z"))
+	call(t, env, c, http.MethodDelete, "/v1/notes/"+deleted, "", nil)
+
+	var records []struct {
+		UID       string         `json:"uid"`
+		DeletedAt *string        `json:"deletedAt"`
+		Payload   map[string]any `json:"payload"`
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/notes/export", "", &records); code != http.StatusOK {
+		t.Fatalf("export status = %d", code)
+	}
+	if len(records) != 2 || records[0].UID != tagged || records[1].Payload["title"] != "plain" {
+		t.Errorf("export = %+v, want the two live notes, oldest first", records)
+	}
+	call(t, env, c, http.MethodGet, "/v1/notes/export?includeDeleted=true", "", &records)
+	if len(records) != 3 || records[2].DeletedAt == nil {
+		t.Errorf("export with deleted = %+v, want 3 with a tombstone last", records)
+	}
+	call(t, env, c, http.MethodGet, "/v1/notes/export?tag=work", "", &records)
+	if len(records) != 1 || records[0].UID != tagged {
+		t.Errorf("tag export = %+v, want the tagged note", records)
+	}
+
+	if code := call(t, env, c, http.MethodGet, "/v1/notes/export?format=csv", "", nil); code != http.StatusBadRequest {
+		t.Errorf("notes csv status = %d, want 400", code)
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/tasks/export?format=csv", "", nil); code != http.StatusOK {
+		t.Errorf("tasks csv status = %d, want 200", code)
+	}
+}
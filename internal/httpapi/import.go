@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/importer"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestImport handles POST /v1/import/{source} (todoist, ticktick)
+// The request body is the raw export file: a Todoist backup zip, project CSV or
+// Sync API JSON dump, or a TickTick CSV backup. The upload is validated, then
+// imported asynchronously; returns 202 with the job. Poll GET /v1/import/{id}
+// for progress.
+func (s *Server) RequestImport(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.Imports == nil {
+		writeError(w, r, http.StatusNotFound, "import disabled")
+		return
+	}
+
+	source := chi.URLParam(r, "source")
+	if source == "tick-tick" {
+		source = importer.SourceTickTick
+	}
+	if source != importer.SourceTodoist && source != importer.SourceTickTick {
+		writeError(w, r, http.StatusNotFound, "unsupported import source")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, importer.MaxInputBytes))
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "export file too large")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	job, err := s.Imports.Enqueue(r.Context(), userID, source, data)
+	var verr *importer.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	}
+	if errors.Is(err, importer.ErrInProgress) {
+		writeError(w, r, http.StatusConflict, "an import is already in progress")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to enqueue import")
+		writeError(w, r, http.StatusInternalServerError, "failed to enqueue import")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("importId", job.ID).Str("source", source).Int("items", job.Total).Msg("import requested")
+	w.Header().Set("Location", "/v1/import/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// GetImport handles GET /v1/import/{id}
+// Returns job status and progress (processed of total items).
+func (s *Server) GetImport(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.Imports == nil {
+		writeError(w, r, http.StatusNotFound, "import disabled")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, r, http.StatusNotFound, "import not found")
+		return
+	}
+
+	job, err := s.Imports.Get(r.Context(), userID, id)
+	if errors.Is(err, importer.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "import not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("importId", id).Msg("Failed to load import")
+		writeError(w, r, http.StatusInternalServerError, "failed to load import")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
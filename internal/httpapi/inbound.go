@@ -0,0 +1,186 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/inbound"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// inboundEndpointResponse is the body for inbound endpoint management
+// URL is relative to the API base URL and only returned when the endpoint is created.
+type inboundEndpointResponse struct {
+	*inbound.Endpoint
+	URL string `json:"url,omitempty"`
+}
+
+// messageIDFields are payload fields email-parse webhooks put the Message-ID in
+var messageIDFields = []string{"Message-Id", "message-id", "MessageID", "messageId"}
+
+// CreateInboundEndpoint handles POST /v1/inbound/endpoints
+// The endpoint URL is only returned here.
+func (s *Server) CreateInboundEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Inbound == nil {
+		writeError(w, r, http.StatusNotFound, "inbound webhooks disabled")
+		return
+	}
+
+	var req inbound.EndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	ep, err := s.Inbound.Create(r.Context(), userID, req)
+	var verr *inbound.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, inbound.ErrLimit):
+		writeError(w, r, http.StatusConflict, "inbound endpoint limit reached")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to create inbound endpoint")
+		writeError(w, r, http.StatusInternalServerError, "failed to create inbound endpoint")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("endpointId", ep.ID).Str("entity", ep.Entity).Msg("inbound endpoint created")
+	writeJSON(w, http.StatusCreated, inboundEndpointResponse{Endpoint: ep, URL: "/v1/inbound/" + ep.Token})
+}
+
+// ListInboundEndpoints handles GET /v1/inbound/endpoints
+func (s *Server) ListInboundEndpoints(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Inbound == nil {
+		writeError(w, r, http.StatusNotFound, "inbound webhooks disabled")
+		return
+	}
+
+	eps, err := s.Inbound.List(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to list inbound endpoints")
+		writeError(w, r, http.StatusInternalServerError, "failed to list inbound endpoints")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": eps})
+}
+
+// UpdateInboundEndpoint handles PUT /v1/inbound/endpoints/{id}
+// Replaces the name, entity and template; the URL stays the same.
+func (s *Server) UpdateInboundEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Inbound == nil {
+		writeError(w, r, http.StatusNotFound, "inbound webhooks disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	var req inbound.EndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	ep, err := s.Inbound.Update(r.Context(), userID, id, req)
+	var verr *inbound.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, inbound.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, "inbound endpoint not found")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("endpointId", id).Msg("Failed to update inbound endpoint")
+		writeError(w, r, http.StatusInternalServerError, "failed to update inbound endpoint")
+		return
+	}
+	writeJSON(w, http.StatusOK, ep)
+}
+
+// DeleteInboundEndpoint handles DELETE /v1/inbound/endpoints/{id}
+func (s *Server) DeleteInboundEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Inbound == nil {
+		writeError(w, r, http.StatusNotFound, "inbound webhooks disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.Inbound.Delete(r.Context(), userID, id)
+	if errors.Is(err, inbound.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "inbound endpoint not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("endpointId", id).Msg("Failed to delete inbound endpoint")
+		writeError(w, r, http.StatusInternalServerError, "failed to delete inbound endpoint")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("endpointId", id).Msg("inbound endpoint deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReceiveInbound handles POST /v1/inbound/{token}
+// Unauthenticated: the token in the URL is the credential. Accepts JSON, form
+// posts and plain text; the endpoint's template decides the created item.
+// The Idempotency-Key header (or an email Message-ID field) makes retries
+// rewrite the same item instead of creating duplicates.
+func (s *Server) ReceiveInbound(w http.ResponseWriter, r *http.Request) {
+	if s.Inbound == nil {
+		writeError(w, r, http.StatusNotFound, "inbound webhooks disabled")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, inbound.MaxBodyBytes))
+	if err != nil {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	payload, err := inbound.ParsePayload(r.Header.Get("Content-Type"), body)
+	var verr *inbound.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	for _, f := range messageIDFields {
+		if key != "" {
+			break
+		}
+		key, _ = payload[f].(string)
+	}
+
+	receipt, err := s.Inbound.Receive(r.Context(), chi.URLParam(r, "token"), payload, key)
+	switch {
+	case errors.Is(err, inbound.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, "inbound endpoint not found")
+		return
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusUnprocessableEntity, verr.Msg)
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to ingest inbound payload")
+		writeError(w, r, http.StatusInternalServerError, "failed to ingest payload")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("entity", receipt.Entity).Str("uid", receipt.UID).Msg("inbound payload ingested")
+	writeJSON(w, http.StatusCreated, receipt)
+}
@@ -3,6 +3,9 @@ package httpapi
 import (
 	"net/http"
 	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
 )
 
 // ServerInfo represents the server's capabilities and configuration
@@ -15,6 +18,8 @@ type ServerInfo struct {
 	MinClientVersion string                       `json:"minClientVersion"`
 	RateLimit        *RateLimitInfo               `json:"rateLimit,omitempty"`
 	Hints            *SyncHints                   `json:"hints,omitempty"`
+	Features         map[string]bool              `json:"features,omitempty"`    // Feature flags (reloadable)
+	Maintenance      bool                         `json:"maintenance,omitempty"` // Other endpoints return 503 while set
 }
 
 // RateLimitInfo describes the server's rate limiting policy
@@ -26,8 +31,11 @@ type RateLimitInfo struct {
 
 // SyncHints provides recommendations for client behavior
 type SyncHints struct {
-	RecommendedBatch int `json:"recommendedBatch"` // safe batch size
-	BackoffMsOn429   int `json:"backoffMsOn429"`   // default backoff if Retry-After missing
+	RecommendedBatch int   `json:"recommendedBatch"` // safe batch size
+	BackoffMsOn429   int   `json:"backoffMsOn429"`   // default backoff if Retry-After missing
+	MaxResponseBytes int   `json:"maxResponseBytes"` // pull pages end early at this size; keep following nextCursor
+	MaxClockSkewMs   int64 `json:"maxClockSkewMs"`   // pushed timestamps further ahead of serverTime are rejected (code clock_skew); 0 = unchecked
+	ServerTimestamps bool  `json:"serverTimestamps"` // the server assigns updatedTs on push; use the ack's updatedAt, not the local clock
 }
 
 // EntityCapability describes capabilities for a specific entity type
@@ -48,9 +56,10 @@ type LockingCapability struct {
 // Returns server capabilities, API version, and supported features
 // This endpoint can be called without authentication to allow capability discovery
 func (s *Server) Info(w http.ResponseWriter, r *http.Request) {
+	settings := s.Settings()
 	info := ServerInfo{
 		APIVersion: "1.1",
-		ServerTime: time.Now().UTC().Format(time.RFC3339Nano),
+		ServerTime: syncx.Now().Format(time.RFC3339Nano),
 		Entities: map[string]EntityCapability{
 			"notes": {
 				MaxLimit: 1000,
@@ -87,17 +96,27 @@ func (s *Server) Info(w http.ResponseWriter, r *http.Request) {
 				Push:     true,
 				Pull:     true,
 			},
+			"pins": {
+				MaxLimit: 1000,
+				Push:     true,
+				Pull:     true,
+			},
 		},
 		Locking: LockingCapability{
 			Supported: true,
 			Mode:      "session",
 		},
 		MinClientVersion: "0.1.0",
-		RateLimit:        &s.RateLimitConfig,
+		RateLimit:        &settings.RateLimit,
 		Hints: &SyncHints{
 			RecommendedBatch: 500,
 			BackoffMsOn429:   1500,
+			MaxResponseBytes: syncservice.MaxPullBytes,
+			MaxClockSkewMs:   syncx.MaxClockSkew().Milliseconds(),
+			ServerTimestamps: syncx.ServerTimestamps(),
 		},
+		Features:    settings.Features,
+		Maintenance: settings.Maintenance,
 	}
 
 	writeJSON(w, http.StatusOK, info)
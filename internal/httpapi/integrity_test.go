@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestIntegrityRepairsOrphanedComments_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+		CommentSvc:      syncservice.NewCommentService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	noteUID := "7e1d2c3b-4a5f-4e6d-8c7b-9a0b1c2d3e4f"
+	commentUID := "8f2e3d4c-5b6a-4f7e-9d8c-0b1c2d3e4f5a"
+	push := func(path string, item map[string]any) {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "POST", path, pushReq{Items: []map[string]any{item}}, session)
+		if w.Code != 200 {
+			t.Fatalf("push %s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	push("/v1/sync/notes/push", map[string]any{
+		"uid": noteUID, "title": "Parent", "updatedTs": "2025-11-03T10:00:00Z",
+		"sync": map[string]any{"version": float64(1)},
+	})
+	push("/v1/sync/comments/push", map[string]any{
+		"uid": commentUID, "content": "Child", "parentType": "note", "parentUid": noteUID,
+		"updatedTs": "2025-11-03T10:00:00Z", "sync": map[string]any{"version": float64(1)},
+	})
+	// Tombstoning the parent does not cascade to its comments
+	push("/v1/sync/notes/push", map[string]any{
+		"uid": noteUID, "title": "Parent", "updatedTs": "2025-11-03T11:00:00Z",
+		"sync": map[string]any{"version": float64(2), "isDeleted": true},
+	})
+
+	ctx := context.Background()
+	report := syncservice.NewIntegrityService(pool, syncservice.OrphanPolicyReport)
+	report.Grace = 0
+	r, err := report.Check(ctx)
+	if err != nil {
+		t.Fatalf("report check: %v", err)
+	}
+	if r.Orphans["comment"] < 1 || r.Repaired["comment"] != 0 {
+		t.Fatalf("report policy: orphans=%v repaired=%v, want >=1 orphan and no repairs", r.Orphans, r.Repaired)
+	}
+
+	repair := syncservice.NewIntegrityService(pool, syncservice.OrphanPolicyRepair)
+	repair.Grace = 0
+	if _, err := repair.Check(ctx); err != nil {
+		t.Fatalf("repair check: %v", err)
+	}
+
+	var deleted bool
+	var version int
+	if err := pool.QueryRow(ctx,
+		`SELECT deleted_at_ms IS NOT NULL, version FROM comment WHERE uid = $1 AND owner_id = $2`,
+		commentUID, session.UserID).Scan(&deleted, &version); err != nil {
+		t.Fatalf("load comment: %v", err)
+	}
+	if !deleted || version < 2 {
+		t.Errorf("comment deleted=%t version=%d, want tombstoned with bumped version", deleted, version)
+	}
+
+	var device string
+	if err := pool.QueryRow(ctx,
+		`SELECT device_id FROM activity_log WHERE owner_id = $1 AND uid = $2 AND action = 'deleted' ORDER BY id DESC LIMIT 1`,
+		session.UserID, commentUID).Scan(&device); err != nil || device != "integrity" {
+		t.Errorf("activity device = %q (%v), want integrity", device, err)
+	}
+}
@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+// GetBacklinks handles GET /v1/links/backlinks/{uid}?limit=<n>
+// Returns the caller's live items whose "links" name uid, so clients can show
+// "referenced by" without scanning every entity. The target doesn't have to
+// exist: links pushed ahead of their target are listed too.
+func (s *Server) GetBacklinks(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 100, 1000)
+
+	backlinks, err := s.LinkSvc.Backlinks(r.Context(), auth.UserID(r.Context()), uid, limit)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to list backlinks")
+		writeError(w, r, http.StatusInternalServerError, "failed to list backlinks")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"backlinks": backlinks})
+}
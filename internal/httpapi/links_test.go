@@ -0,0 +1,55 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestBacklinks(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	c := env.Client(t, "links-user")
+
+	const (
+		target  = "60000000-0000-4000-8000-000000000001"
+		missing = "60000000-0000-4000-8000-000000000002"
+		task    = "70000000-0000-4000-8000-000000000001"
+	)
+	pushOne(t, c, note(target, "spec", "2025-11-03T10:00:00Z"))
+	linked := note(task, "implement", "2025-11-03T10:00:01Z")
+	linked["links"] = []map[string]any{{"entity": "note", "uid": target}, {"entity": "note", "uid": missing}}
+	acks, err := c.Push(ctx, "tasks", []map[string]any{linked})
+	if err != nil || len(acks) != 1 || acks[0].Error != "" {
+		t.Fatalf("push: %v, %v", acks, err)
+	}
+	if w := acks[0].Warning; !strings.Contains(w, missing) || strings.Contains(w, target) {
+		t.Errorf("warning = %q, want one for the missing note only", w)
+	}
+
+	var resp struct {
+		Backlinks []struct {
+			Entity string `json:"entity"`
+			UID    string `json:"uid"`
+		} `json:"backlinks"`
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/links/backlinks/"+target, "", &resp); code != http.StatusOK {
+		t.Fatalf("backlinks status = %d", code)
+	}
+	if len(resp.Backlinks) != 1 || resp.Backlinks[0].Entity != "task" || resp.Backlinks[0].UID != task {
+		t.Errorf("backlinks = %+v, want the task", resp.Backlinks)
+	}
+
+	// Dropping the link (or deleting the task) removes the backlink
+	unlinked := note(task, "implement", "2025-11-03T10:00:02Z")
+	if _, err := c.Push(ctx, "tasks", []map[string]any{unlinked}); err != nil {
+		t.Fatal(err)
+	}
+	call(t, env, c, http.MethodGet, "/v1/links/backlinks/"+target, "", &resp)
+	if len(resp.Backlinks) != 0 {
+		t.Errorf("backlinks after unlinking = %+v, want none", resp.Backlinks)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type contextKey string
@@ -17,6 +20,7 @@ const (
 
 // SessionMiddleware reads X-Sync-Session header and adds it to context
 // This allows correlation of all sync operations within a session
+// X-Device-ID (optional) is carried alongside it into the activity log.
 func SessionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sessionID := r.Header.Get("X-Sync-Session")
@@ -32,6 +36,14 @@ func SessionMiddleware(next http.Handler) http.Handler {
 			r = r.WithContext(ctx)
 		}
 
+		// Record who is writing (session + optional device) for the activity log
+		if deviceID := r.Header.Get("X-Device-ID"); sessionID != "" || deviceID != "" {
+			r = r.WithContext(syncservice.WithChangeSource(r.Context(), syncservice.ChangeSource{
+				SessionID: sessionID,
+				DeviceID:  deviceID,
+			}))
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -47,6 +59,8 @@ func GetSessionID(ctx context.Context) string {
 // CorrelationMiddleware reads X-Correlation-ID header and adds it to context
 // Generates a new correlation ID if client doesn't provide one
 // This enables end-to-end request tracing across client and server logs
+// W3C traceparent/tracestate headers are continued the same way (generated when
+// absent); TracingMiddleware echoes the server span's traceparent in the response.
 func CorrelationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract correlation ID from request header
@@ -62,6 +76,9 @@ func CorrelationMiddleware(next http.Handler) http.Handler {
 		// Store in context for downstream handlers
 		ctx := context.WithValue(r.Context(), correlationIDKey, correlationID)
 
+		// Continue (or start) the W3C trace context
+		ctx = telemetry.Extract(ctx, propagation.HeaderCarrier(r.Header))
+
 		// Add to logger context for all logs in this request
 		logger := log.With().Str("correlation_id", correlationID).Logger()
 		ctx = logger.WithContext(ctx)
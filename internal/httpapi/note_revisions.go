@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// writeRevisionError maps note revision errors to HTTP responses
+func writeRevisionError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	var vm *syncservice.VersionMismatchError
+	switch {
+	case errors.Is(err, syncservice.ErrItemNotFound):
+		writeError(w, r, http.StatusNotFound, "note not found")
+	case errors.Is(err, syncservice.ErrRevisionNotFound):
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case errors.As(err, &vm):
+		writeVersionConflict(w, r, vm, true)
+	default:
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to " + action)
+		writeError(w, r, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// parseVersion parses a positive note version ("" gives 0)
+func parseVersion(s string) (int, bool) {
+	if s == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(s)
+	return v, err == nil && v > 0
+}
+
+// ListNoteRevisions handles GET /v1/notes/{uid}/revisions?limit=<n>
+// Returns the note's recorded revisions (payload as of each text-changing
+// write), newest first. Only the latest syncservice.MaxNoteRevisions are kept.
+func (s *Server) ListNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 20, syncservice.MaxNoteRevisions)
+
+	revisions, err := s.NoteSvc.ListRevisions(r.Context(), auth.UserID(r.Context()), uid, limit)
+	if err != nil {
+		writeRevisionError(w, r, err, "list revisions")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revisions": revisions})
+}
+
+// GetNoteRevision handles GET /v1/notes/{uid}/revisions/{version}
+func (s *Server) GetNoteRevision(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	version, ok := parseVersion(chi.URLParam(r, "version"))
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid version")
+		return
+	}
+
+	rev, err := s.NoteSvc.GetRevision(r.Context(), auth.UserID(r.Context()), uid, version)
+	if err != nil {
+		writeRevisionError(w, r, err, "get revision")
+		return
+	}
+	writeJSON(w, http.StatusOK, rev)
+}
+
+// DiffNoteRevisions handles GET /v1/notes/{uid}/revisions/diff?from=<v>&to=<v>
+// Returns line diffs of the note's title and content between two revisions;
+// without to, against the note as it is now.
+func (s *Server) DiffNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	from, okFrom := parseVersion(r.URL.Query().Get("from"))
+	to, okTo := parseVersion(r.URL.Query().Get("to"))
+	if !okFrom || !okTo || from == 0 {
+		writeError(w, r, http.StatusBadRequest, "from (and optional to) must be note versions")
+		return
+	}
+
+	diff, err := s.NoteSvc.DiffRevisions(r.Context(), auth.UserID(r.Context()), uid, from, to)
+	if err != nil {
+		writeRevisionError(w, r, err, "diff revisions")
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// RestoreNoteRevision handles POST /v1/notes/{uid}/revisions/{version}/restore
+// Writes the revision's payload as a new version of the note (supports
+// If-Match). Deleted notes can't be restored this way (410).
+func (s *Server) RestoreNoteRevision(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	version, ok := parseVersion(chi.URLParam(r, "version"))
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid version")
+		return
+	}
+
+	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
+	if err != nil {
+		writeRevisionError(w, r, err, "get note")
+		return
+	}
+	if existing == nil {
+		writeError(w, r, http.StatusNotFound, "note not found")
+		return
+	}
+	if existing.DeletedAt != nil {
+		writeJSON(w, http.StatusGone, map[string]any{
+			"error":     "note deleted",
+			"deletedAt": existing.DeletedAt,
+		})
+		return
+	}
+
+	opts := syncservice.MutationOpts{}
+	if v, ok := parseIfMatchHeader(r); ok {
+		opts.EnforceVersion = true
+		opts.ExpectedVersion = v
+	}
+	item, err := s.NoteSvc.RestoreRevision(ctx, userID, uid, version, opts)
+	if err != nil {
+		writeRevisionError(w, r, err, "restore revision")
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
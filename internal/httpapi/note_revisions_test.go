@@ -0,0 +1,72 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestNoteRevisions(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "revisions-user")
+
+	const uid = "a0000000-0000-4000-8000-000000000001"
+	edit := func(content, ts string, extra map[string]any) {
+		item := note(uid, "draft", ts)
+		item["content"] = content
+		for k, v := range extra {
+			item[k] = v
+		}
+		pushOne(t, c, item)
+	}
+	edit("one\ntwo", "2025-11-03T10:00:00Z", nil)
+	edit("one\ntwo", "2025-11-03T10:00:01Z", map[string]any{"status": "active"}) // No text change, no revision
+	edit("one\n2", "2025-11-03T10:00:02Z", nil)
+
+	var list struct {
+		Revisions []struct {
+			Version int            `json:"version"`
+			Payload map[string]any `json:"payload"`
+		} `json:"revisions"`
+	}
+	base := "/v1/notes/" + uid + "/revisions"
+	if code := call(t, env, c, http.MethodGet, base, "", &list); code != http.StatusOK {
+		t.Fatalf("list status = %d", code)
+	}
+	if len(list.Revisions) != 2 || list.Revisions[0].Version != 3 || list.Revisions[1].Version != 1 {
+		t.Fatalf("revisions = %+v, want versions 3 and 1", list.Revisions)
+	}
+
+	var diff struct {
+		Fields map[string][]struct {
+			Op    string   `json:"op"`
+			Lines []string `json:"lines"`
+		} `json:"fields"`
+	}
+	if code := call(t, env, c, http.MethodGet, base+"/diff?from=1", "", &diff); code != http.StatusOK {
+		t.Fatalf("diff status = %d", code)
+	}
+	if _, ok := diff.Fields["title"]; ok || len(diff.Fields["content"]) != 3 {
+		t.Errorf("diff = %+v, want content equal/delete/insert only", diff.Fields)
+	}
+	if code := call(t, env, c, http.MethodGet, base+"/2", "", nil); code != http.StatusNotFound {
+		t.Errorf("revision 2 status = %d, want 404", code)
+	}
+
+	// Restoring writes the old content as a new version
+	var restored struct {
+		Version int            `json:"version"`
+		Payload map[string]any `json:"payload"`
+	}
+	if code := call(t, env, c, http.MethodPost, base+"/1/restore", "", &restored); code != http.StatusOK {
+		t.Fatalf("restore status = %d", code)
+	}
+	if restored.Version != 4 || restored.Payload["content"] != "one\ntwo" {
+		t.Errorf("restored = %+v, want version 4 with the first content", restored)
+	}
+	call(t, env, c, http.MethodGet, base, "", &list)
+	if len(list.Revisions) != 3 || list.Revisions[0].Version != 4 {
+		t.Errorf("revisions after restore = %+v, want version 4 first", list.Revisions)
+	}
+}
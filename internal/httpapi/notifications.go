@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/notify"
+	"github.com/rs/zerolog/log"
+)
+
+// GetNotificationPreferences handles GET /v1/notifications/preferences
+// Users who never saved preferences get the defaults (every trigger on, no email).
+func (s *Server) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Notify == nil {
+		writeError(w, r, http.StatusNotFound, "notifications disabled")
+		return
+	}
+
+	prefs, err := s.Notify.GetPreferences(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to load notification preferences")
+		writeError(w, r, http.StatusInternalServerError, "failed to load preferences")
+		return
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// PutNotificationPreferences handles PUT /v1/notifications/preferences
+// Replaces the user's preferences; omitted triggers default to on. An empty
+// email turns all notifications off.
+func (s *Server) PutNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Notify == nil {
+		writeError(w, r, http.StatusNotFound, "notifications disabled")
+		return
+	}
+
+	prefs := notify.DefaultPreferences()
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	saved, err := s.Notify.SavePreferences(r.Context(), userID, prefs)
+	var verr *notify.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to save notification preferences")
+		writeError(w, r, http.StatusInternalServerError, "failed to save preferences")
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+// collisionNotes pushes n notes that mostly share one timestamp, as a bulk
+// import leaves them, followed by a short run at the next second; every third
+// note is a tombstone
+// Returns all UIDs and the live ones, each in (updated_at_ms, uid) order.
+func collisionNotes(t *testing.T, router http.Handler, session TestSession, n int) (all, live []string) {
+	t.Helper()
+
+	items := make([]map[string]any, 0, n)
+	for i := 0; i < n; i++ {
+		uid := fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		ts := "2025-11-03T10:00:00Z"
+		if i >= n-3 {
+			ts = "2025-11-03T10:00:01Z"
+		}
+		sync := map[string]any{"version": float64(1)}
+		if i%3 == 1 {
+			sync["isDeleted"] = true
+			sync["deletedAt"] = ts
+		} else {
+			live = append(live, uid)
+		}
+		// Pushed newest-first so insertion order doesn't line up with uid order
+		items = append([]map[string]any{{"uid": uid, "title": "bulk", "updatedTs": ts, "sync": sync}}, items...)
+		all = append(all, uid)
+	}
+
+	rec := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: items}, session)
+	var acks []pushAck
+	if err := json.NewDecoder(rec.Body).Decode(&acks); err != nil || len(acks) != n {
+		t.Fatalf("push: status %d, %d acks, err %v", rec.Code, len(acks), err)
+	}
+	for _, ack := range acks {
+		if ack.Error != "" {
+			t.Fatalf("push %s: %s", ack.UID, ack.Error)
+		}
+	}
+	return all, live
+}
+
+// pageResult is one page as the pagination checks see it
+type pageResult struct {
+	uids       []string
+	nextCursor *string
+	hasMore    bool
+}
+
+// checkPagination follows fetch from the start until hasMore is false and
+// checks that the pages are contiguous runs of want, that no page is empty and
+// that limit-sized pages take exactly ceil(len(want)/limit) requests
+// Within a page, sync pulls split upserts from deletes, so ordered compares
+// the page as returned and unordered compares it as a set.
+func checkPagination(t *testing.T, want []string, limit int, ordered bool, fetch func(t *testing.T, cursor string) pageResult) {
+	t.Helper()
+
+	var got []string
+	var cursor string
+	pages := 0
+	for {
+		pages++
+		if pages > len(want)+1 {
+			t.Fatalf("pagination did not terminate after %d pages", pages)
+		}
+		page := fetch(t, cursor)
+		if len(page.uids) == 0 {
+			t.Fatalf("page %d is empty (hasMore should have been false on the page before)", pages)
+		}
+		end := len(got) + len(page.uids)
+		if end > len(want) {
+			t.Fatalf("page %d runs past the end: got %d rows, want %d in total", pages, end, len(want))
+		}
+		expect := append([]string(nil), want[len(got):end]...)
+		seen := append([]string(nil), page.uids...)
+		if !ordered {
+			sort.Strings(expect)
+			sort.Strings(seen)
+		}
+		if fmt.Sprint(seen) != fmt.Sprint(expect) {
+			t.Fatalf("page %d = %v, want %v", pages, seen, expect)
+		}
+		got = append(got, page.uids...)
+
+		if page.nextCursor == nil {
+			t.Fatalf("page %d has rows but no nextCursor", pages)
+		}
+		cursor = *page.nextCursor
+		if !page.hasMore {
+			break
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("got %d rows, want %d", len(got), len(want))
+	}
+	if wantPages := (len(want) + limit - 1) / limit; pages != wantPages {
+		t.Errorf("took %d pages, want %d", pages, wantPages)
+	}
+
+	// The last page's cursor is the resume point for the next sync: nothing
+	// is behind it yet
+	if page := fetch(t, cursor); len(page.uids) != 0 || page.hasMore || page.nextCursor != nil {
+		t.Errorf("resuming after the last page = %+v, want an empty page", page)
+	}
+}
+
+func TestPaginationTimestampCollisions_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	const n = 14
+	all, live := collisionNotes(t, router, session, n)
+
+	pull := func(limit int) func(*testing.T, string) pageResult {
+		return func(t *testing.T, cursor string) pageResult {
+			t.Helper()
+			path := fmt.Sprintf("/v1/sync/notes/pull?limit=%d", limit)
+			if cursor != "" {
+				path += "&cursor=" + url.QueryEscape(cursor)
+			}
+			rec := makeRequestWithSession(t, router, "GET", path, nil, session)
+			var resp pullResp
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("pull: status %d, %v", rec.Code, err)
+			}
+			page := pageResult{nextCursor: resp.NextCursor, hasMore: resp.HasMore}
+			for _, item := range append(resp.Upserts, resp.Deletes...) {
+				page.uids = append(page.uids, item["uid"].(string))
+			}
+			return page
+		}
+	}
+	list := func(limit int, includeDeleted bool) func(*testing.T, string) pageResult {
+		return func(t *testing.T, cursor string) pageResult {
+			t.Helper()
+			path := fmt.Sprintf("/v1/notes?limit=%d&includeDeleted=%t", limit, includeDeleted)
+			if cursor != "" {
+				path += "&cursor=" + url.QueryEscape(cursor)
+			}
+			rec := makeRequestWithSession(t, router, "GET", path, nil, session)
+			var resp syncservice.RESTListResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("list: status %d, %v", rec.Code, err)
+			}
+			page := pageResult{nextCursor: resp.NextCursor, hasMore: resp.HasMore}
+			for _, item := range resp.Items {
+				page.uids = append(page.uids, item.UID)
+			}
+			return page
+		}
+	}
+
+	// Every page size, including ones that end a page mid-timestamp, on a
+	// tombstone, exactly at the end and past it
+	for limit := 1; limit <= n+1; limit++ {
+		t.Run(fmt.Sprintf("pull/limit=%d", limit), func(t *testing.T) {
+			checkPagination(t, all, limit, false, pull(limit))
+		})
+		t.Run(fmt.Sprintf("list/limit=%d", limit), func(t *testing.T) {
+			checkPagination(t, all, limit, true, list(limit, true))
+		})
+		t.Run(fmt.Sprintf("list-live/limit=%d", limit), func(t *testing.T) {
+			checkPagination(t, live, limit, true, list(limit, false))
+		})
+	}
+}
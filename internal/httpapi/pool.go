@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// Pools for the hot sync path: response encode buffers, push ack slices and
+// the push decoder's item stage. Objects that grew past a retention cap
+// (one oversized pull page or payload) are dropped rather than pooled, so a
+// single large request doesn't pin its memory for the life of the process.
+const (
+	maxPooledBufferBytes = 4 << 20 // Encode buffers (a full pull page)
+	maxPooledAcks        = 1000    // Ack slices (the largest push batch)
+	maxPooledItemBytes   = 64 << 10
+)
+
+var (
+	bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+	ackPool    = sync.Pool{New: func() any { s := make([]pushAck, 0, pushStageSize); return &s }}
+	stagePool  = sync.Pool{New: func() any { s := make([]json.RawMessage, 0, pushStageSize); return &s }}
+)
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBufferBytes {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// getAcks returns an empty ack slice; pass the grown slice to putAcks once the
+// response has been written
+func getAcks() []pushAck {
+	return (*ackPool.Get().(*[]pushAck))[:0]
+}
+
+func putAcks(acks []pushAck) {
+	if cap(acks) > maxPooledAcks {
+		return
+	}
+	clear(acks) // Release UID and error strings
+	acks = acks[:0]
+	ackPool.Put(&acks)
+}
+
+// getStage returns an empty push item stage whose item buffers are reused
+// by pushDecoder (decoding into a RawMessage appends to its existing capacity)
+func getStage() []json.RawMessage {
+	return (*stagePool.Get().(*[]json.RawMessage))[:0]
+}
+
+func putStage(stage []json.RawMessage) {
+	stage = stage[:cap(stage)]
+	for i, item := range stage {
+		if cap(item) > maxPooledItemBytes {
+			stage[i] = nil
+		} else {
+			stage[i] = item[:0]
+		}
+	}
+	stage = stage[:0]
+	stagePool.Put(&stage)
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestWriteJSONPooledBuffer(t *testing.T) {
+	for range 3 { // Buffers come back from the pool reset
+		w := httptest.NewRecorder()
+		writeJSON(w, 201, map[string]string{"uid": "a"})
+		if w.Code != 201 || w.Body.String() != "{\"uid\":\"a\"}\n" {
+			t.Fatalf("got %d %q", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Length"); got != strconv.Itoa(w.Body.Len()) {
+			t.Errorf("Content-Length = %s, body %d bytes", got, w.Body.Len())
+		}
+	}
+
+	// An unencodable value is a clean 500, not a partial body
+	w := httptest.NewRecorder()
+	writeJSON(w, 200, map[string]any{"ok": true, "bad": make(chan int)})
+	var body map[string]string
+	if w.Code != 500 || json.Unmarshal(w.Body.Bytes(), &body) != nil || body["error"] == "" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestPutAcksClears(t *testing.T) {
+	acks := append(getAcks(), pushAck{UID: "a", Error: "boom"})
+	putAcks(acks)
+	// The backing array no longer references the old strings
+	if acks[:1][0] != (pushAck{}) {
+		t.Errorf("pooled ack not cleared: %+v", acks[:1][0])
+	}
+	if got := getAcks(); len(got) != 0 {
+		t.Errorf("pooled slice has %d acks", len(got))
+	}
+}
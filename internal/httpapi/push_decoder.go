@@ -0,0 +1,171 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// pushStageSize bounds how many decoded push items are held in memory at once
+// A 1000-item push with large payloads is applied in stages of this size
+// instead of being buffered whole.
+const pushStageSize = 50
+
+// Push request limits: past either, the whole push fails (413 / 400) and
+// nothing is applied
+const (
+	maxPushItems = 1000     // Items per push
+	maxPushBytes = 16 << 20 // Request body (wrap it with http.MaxBytesReader)
+)
+
+var (
+	errItemsNotArray = errors.New(`"items" must be an array`)
+	errItemNotObject = errors.New("push items must be JSON objects")
+	errTooManyItems  = fmt.Errorf("at most %d items can be pushed at once", maxPushItems)
+)
+
+// pushDecoder reads the items of a push body ({"items": [...]}) incrementally
+// Items are staged as raw JSON and handed to the sync services undecoded.
+// Use it like bufio.Scanner: call Next until it returns no items, then Err;
+// Release returns the stage (and its item buffers) to the pool.
+type pushDecoder struct {
+	dec   *json.Decoder
+	stage []json.RawMessage
+	count int // Items decoded so far
+	done  bool
+	err   error
+}
+
+// newPushDecoder reads body up to the first item
+// A body without items (or with "items": null) is an empty push; anything that
+// is not a JSON object fails here, before the handler opens a transaction.
+func newPushDecoder(body io.Reader) (*pushDecoder, error) {
+	d := &pushDecoder{dec: json.NewDecoder(body)}
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("push body must be a JSON object")
+	}
+	if err := d.seekItems(); err != nil {
+		return nil, err
+	}
+	d.stage = getStage()
+	return d, nil
+}
+
+// seekItems advances past the object's keys to the opening of the items array
+// Other keys are skipped; without an items array the whole object is consumed.
+func (d *pushDecoder) seekItems() error {
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		// encoding/json matches field names case-insensitively
+		if key, _ := tok.(string); !strings.EqualFold(key, "items") {
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		tok, err = d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('['):
+			return nil
+		case nil:
+			continue
+		default:
+			return errItemsNotArray
+		}
+	}
+	d.done = true
+	_, err := d.dec.Token() // Closing '}'
+	return err
+}
+
+// Next decodes up to pushStageSize items
+// The returned slice and the items' bytes are reused by the following call.
+// An empty result means the body is exhausted or malformed; check Err.
+func (d *pushDecoder) Next() []json.RawMessage {
+	d.stage = d.stage[:0]
+	for !d.done && len(d.stage) < pushStageSize {
+		if !d.dec.More() {
+			d.done = true
+			if err := d.finish(); err != nil {
+				d.err = err
+				return nil
+			}
+			break
+		}
+		if d.count == maxPushItems {
+			d.done, d.err = true, errTooManyItems
+			return nil
+		}
+		d.count++
+		// Decode into the staged buffer left by the previous batch, if any
+		d.stage = d.stage[:len(d.stage)+1]
+		item := &d.stage[len(d.stage)-1]
+		if err := d.dec.Decode(item); err != nil {
+			d.done, d.err = true, err
+			return nil
+		}
+		// null is passed through (and rejected per item, as it always was)
+		if (*item)[0] != '{' && string(*item) != "null" {
+			d.done, d.err = true, errItemNotObject
+			return nil
+		}
+	}
+	return d.stage
+}
+
+// finish consumes the closing ']' and the rest of the object, so a body
+// with trailing garbage is rejected like a buffered decode would reject it
+func (d *pushDecoder) finish() error {
+	if _, err := d.dec.Token(); err != nil {
+		return err
+	}
+	for d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return err
+		}
+		var skip json.RawMessage
+		if err := d.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	_, err := d.dec.Token()
+	return err
+}
+
+// Release returns the item stage to the pool; the decoder must not be used after
+func (d *pushDecoder) Release() {
+	putStage(d.stage)
+	d.stage = nil
+}
+
+// Err returns the first decode error, if any
+func (d *pushDecoder) Err() error {
+	return d.err
+}
+
+// writePushBodyError answers a push whose body the decoder rejected
+func writePushBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSON(w, http.StatusRequestEntityTooLarge, []pushAck{{Error: fmt.Sprintf("push body exceeds %d bytes", maxPushBytes)}})
+	case errors.Is(err, errTooManyItems):
+		writeJSON(w, http.StatusBadRequest, []pushAck{{Error: err.Error()}})
+	default:
+		writeJSON(w, http.StatusBadRequest, []pushAck{{Error: "invalid json"}})
+	}
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decodeAll drains a pushDecoder, returning the items and the largest stage seen
+func decodeAll(t *testing.T, body string) (items []json.RawMessage, maxStage int, err error) {
+	t.Helper()
+	d, err := newPushDecoder(strings.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	for batch := d.Next(); len(batch) > 0; batch = d.Next() {
+		maxStage = max(maxStage, len(batch))
+		for _, item := range batch {
+			items = append(items, bytes.Clone(item)) // Item buffers are reused by Next
+		}
+	}
+	defer d.Release()
+	return items, maxStage, d.Err()
+}
+
+func TestPushDecoder(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		body  string
+		items int
+	}{
+		{"items", `{"items":[{"uid":"a"},{"uid":"b"}]}`, 2},
+		{"empty array", `{"items":[]}`, 0},
+		{"null items", `{"items":null}`, 0},
+		{"no items", `{}`, 0},
+		{"other keys skipped", `{"client":{"v":[1,2]},"Items":[{"uid":"a"}],"trailing":true}`, 1},
+		{"null item", `{"items":[null,{"uid":"a"}]}`, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			items, _, err := decodeAll(t, tc.body)
+			if err != nil || len(items) != tc.items {
+				t.Fatalf("got %d items, %v; want %d", len(items), err, tc.items)
+			}
+		})
+	}
+
+	for _, body := range []string{
+		``,
+		`[]`,
+		`{"items":{"uid":"a"}}`,
+		`{"items":[{"uid":"a"},42]}`,
+		`{"items":[{"uid":"a"},[]]}`,
+		`{"items":[{"uid":}]}`,
+		`{"items":[{"uid":"a"}`,
+		`{"items":[{"uid":"a"}],"x":}`,
+	} {
+		if _, _, err := decodeAll(t, body); err == nil {
+			t.Errorf("%q accepted", body)
+		}
+	}
+}
+
+// itemsBody returns a push body of n small items
+func itemsBody(n int) string {
+	var b strings.Builder
+	b.WriteString(`{"items":[`)
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"uid":"%d"}`, i)
+	}
+	b.WriteString(`]}`)
+	return b.String()
+}
+
+func TestPushDecoderStages(t *testing.T) {
+	const n = pushStageSize*3 + 7
+	items, maxStage, err := decodeAll(t, itemsBody(n))
+	if err != nil || len(items) != n {
+		t.Fatalf("got %d items, %v; want %d", len(items), err, n)
+	}
+	if maxStage != pushStageSize {
+		t.Errorf("largest stage = %d, want %d", maxStage, pushStageSize)
+	}
+	// Later stages decode into earlier stages' buffers without corrupting the copies
+	if first, last := string(items[0]), string(items[n-1]); first != `{"uid":"0"}` || last != fmt.Sprintf(`{"uid":"%d"}`, n-1) {
+		t.Errorf("first/last = %s/%s", first, last)
+	}
+}
+
+func TestPushDecoderLimits(t *testing.T) {
+	if items, _, err := decodeAll(t, itemsBody(maxPushItems)); err != nil || len(items) != maxPushItems {
+		t.Errorf("got %d items, %v; want %d", len(items), err, maxPushItems)
+	}
+	_, _, err := decodeAll(t, itemsBody(maxPushItems+1))
+	if !errors.Is(err, errTooManyItems) {
+		t.Fatalf("err = %v, want errTooManyItems", err)
+	}
+	w := httptest.NewRecorder()
+	writePushBodyError(w, err)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("too many items status = %d, want 400", w.Code)
+	}
+
+	// Oversized bodies fail wherever the limit is hit, first stage or later
+	for _, body := range []string{itemsBody(3), itemsBody(pushStageSize * 2)} {
+		w := httptest.NewRecorder()
+		d, err := newPushDecoder(http.MaxBytesReader(w, io.NopCloser(strings.NewReader(body)), int64(len(body)-1)))
+		if err == nil {
+			for len(d.Next()) > 0 {
+			}
+			err = d.Err()
+			d.Release()
+		}
+		writePushBodyError(w, err)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%d-byte body over the limit: status = %d (err %v), want 413", len(body), w.Code, err)
+		}
+	}
+}
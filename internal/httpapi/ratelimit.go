@@ -148,6 +148,30 @@ func (rl *RateLimiter) Allow(userID string) (bool, int, time.Time, time.Time) {
 	return bucket.Allow()
 }
 
+// Config returns the limits new buckets are created with
+func (rl *RateLimiter) Config() RateLimitInfo {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.config
+}
+
+// SetConfig changes the limits for new and existing buckets
+// Existing buckets keep their tokens, capped at the new burst.
+func (rl *RateLimiter) SetConfig(config RateLimitInfo) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.config = config
+	refillRate := float64(config.MaxRequests) / float64(config.WindowSeconds)
+	for _, bucket := range rl.buckets {
+		bucket.mu.Lock()
+		bucket.capacity = float64(config.Burst)
+		bucket.refillRate = refillRate
+		bucket.tokens = min(bucket.tokens, bucket.capacity)
+		bucket.mu.Unlock()
+	}
+}
+
 // cleanupLoop periodically removes inactive buckets to prevent memory leaks
 func (rl *RateLimiter) cleanupLoop() {
 	ticker := time.NewTicker(10 * time.Minute)
@@ -183,16 +207,22 @@ func AuthRateLimitMiddleware(config RateLimitInfo) func(http.Handler) http.Handl
 
 // rateLimitMiddlewareWithDefault is the internal implementation that accepts a fallback default
 func rateLimitMiddlewareWithDefault(config, defaultConfig RateLimitInfo) func(http.Handler) http.Handler {
-	// Use provided default config if provided config is zero-valued (e.g., in tests)
-	// This prevents immediate 429s when Server{} is created without explicit config
-	if config.WindowSeconds == 0 || config.MaxRequests == 0 || config.Burst == 0 {
-		config = defaultConfig
-	}
-
 	// Create a dedicated rate limiter for this middleware instance
 	// This allows different routes to have different rate limits
-	limiter := NewRateLimiter(config)
+	return limitMiddleware(NewRateLimiter(rateLimitOrDefault(config, defaultConfig)))
+}
+
+// rateLimitOrDefault returns defaultConfig when config is zero-valued (e.g., in tests)
+// This prevents immediate 429s when Server{} is created without explicit config
+func rateLimitOrDefault(config, defaultConfig RateLimitInfo) RateLimitInfo {
+	if config.WindowSeconds == 0 || config.MaxRequests == 0 || config.Burst == 0 {
+		return defaultConfig
+	}
+	return config
+}
 
+// limitMiddleware enforces limiter per user (its current config sets the headers)
+func limitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get user ID from context (set by auth middleware)
@@ -205,6 +235,7 @@ func rateLimitMiddlewareWithDefault(config, defaultConfig RateLimitInfo) func(ht
 
 			// Check rate limit
 			allowed, remaining, nextTokenTime, fullResetTime := limiter.Allow(userID)
+			config := limiter.Config()
 
 			// Set rate limit headers
 			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.MaxRequests))
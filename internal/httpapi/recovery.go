@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// problemResponse is an RFC 9457 problem details body (application/problem+json)
+type problemResponse struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// RecoveryMiddleware converts handler panics into 500 problem+json responses
+// HTTP counterpart of the gRPC RecoveryInterceptor. Logs the panic with its
+// stack trace and correlation ID and increments toolbridge_panics_total.
+// Sentry capture happens in ErrorReportingMiddleware, which runs inside this one.
+// http.ErrAbortHandler is re-panicked so net/http can abort the connection.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			metrics.ObservePanic(metrics.TransportHTTP)
+			log.Ctx(r.Context()).Error().
+				Interface("panic", rec).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Str("stack", string(debug.Stack())).
+				Msg("panic recovered in HTTP handler")
+
+			// Nothing more to send if the handler already started the response
+			// (or upgraded the connection)
+			if ww.Status() != 0 || r.Header.Get("Connection") == "Upgrade" {
+				return
+			}
+
+			ww.Header().Set("Content-Type", "application/problem+json")
+			ww.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(ww).Encode(problemResponse{
+				Type:          "about:blank",
+				Title:         http.StatusText(http.StatusInternalServerError),
+				Status:        http.StatusInternalServerError,
+				Detail:        "internal server error",
+				Instance:      r.URL.Path,
+				CorrelationID: GetCorrelationID(r.Context()),
+			})
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
@@ -621,6 +621,14 @@ func TestOptimisticLocking(t *testing.T) {
 			if w.Code != http.StatusPreconditionFailed {
 				t.Errorf("Expected 412 Precondition Failed for stale If-Match, got %d. Body: %s", w.Code, w.Body.String())
 			}
+			var conflict conflictResponse
+			if err := json.NewDecoder(w.Body).Decode(&conflict); err != nil {
+				t.Fatalf("Failed to decode conflict body: %v", err)
+			}
+			if conflict.Error != "version_mismatch" || conflict.UID != entityUID ||
+				conflict.Version == nil || *conflict.Version != currentVersion {
+				t.Errorf("conflict body = %+v, want version_mismatch for %s at version %d", conflict, entityUID, currentVersion)
+			}
 
 			// Verify current version is still intact
 			getPath := fmt.Sprintf("/v1/%s/%s", tt.entityType, entityUID)
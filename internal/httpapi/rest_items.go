@@ -7,7 +7,6 @@ import (
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -93,9 +92,9 @@ func (s *Server) ListNotes(w http.ResponseWriter, r *http.Request) {
 
 	// Parse pagination params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "notes", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -222,13 +221,8 @@ func (s *Server) UpdateNote(w http.ResponseWriter, r *http.Request) {
 	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, payload, opts)
 	if err != nil {
 		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update note")
@@ -296,13 +290,8 @@ func (s *Server) PatchNote(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch note")
@@ -480,9 +469,9 @@ func (s *Server) ListTasks(w http.ResponseWriter, r *http.Request) {
 
 	// Parse pagination params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "tasks", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -609,13 +598,8 @@ func (s *Server) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, payload, opts)
 	if err != nil {
 		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update task")
@@ -683,13 +667,8 @@ func (s *Server) PatchTask(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch task")
@@ -869,9 +848,9 @@ func (s *Server) ListChats(w http.ResponseWriter, r *http.Request) {
 
 	// Parse pagination params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "chats", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -998,13 +977,8 @@ func (s *Server) UpdateChat(w http.ResponseWriter, r *http.Request) {
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, opts)
 	if err != nil {
 		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update chat")
@@ -1072,13 +1046,8 @@ func (s *Server) PatchChat(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch chat")
@@ -1252,9 +1221,9 @@ func (s *Server) ListComments(w http.ResponseWriter, r *http.Request) {
 
 	// Parse pagination params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "comments", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -1381,13 +1350,8 @@ func (s *Server) UpdateComment(w http.ResponseWriter, r *http.Request) {
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, opts)
 	if err != nil {
 		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update comment")
@@ -1455,13 +1419,8 @@ func (s *Server) PatchComment(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch comment")
@@ -1635,9 +1594,9 @@ func (s *Server) ListChatMessages(w http.ResponseWriter, r *http.Request) {
 
 	// Parse pagination params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "chat_messages", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -1764,13 +1723,8 @@ func (s *Server) UpdateChatMessage(w http.ResponseWriter, r *http.Request) {
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, opts)
 	if err != nil {
 		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update chat message")
@@ -1838,13 +1792,8 @@ func (s *Server) PatchChatMessage(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch chat message")
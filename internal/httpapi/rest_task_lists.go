@@ -6,8 +6,6 @@ import (
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
@@ -22,9 +20,9 @@ func (s *Server) ListTaskLists(w http.ResponseWriter, r *http.Request) {
 	logger := log.Ctx(ctx)
 
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "task_lists", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -144,12 +142,8 @@ func (s *Server) UpdateTaskList(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, payload, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update task_list")
@@ -213,12 +207,8 @@ func (s *Server) PatchTaskList(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch task_list")
@@ -389,9 +379,9 @@ func (s *Server) ListTaskListCategories(w http.ResponseWriter, r *http.Request)
 	logger := log.Ctx(ctx)
 
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "task_list_categories", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 	includeDeleted := parseIncludeDeleted(r)
 
@@ -511,12 +501,8 @@ func (s *Server) UpdateTaskListCategory(w http.ResponseWriter, r *http.Request)
 
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, payload, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to update task_list_category")
@@ -580,12 +566,8 @@ func (s *Server) PatchTaskListCategory(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		if vm, ok := err.(*syncservice.VersionMismatchError); ok {
+			writeVersionConflict(w, r, vm, usedIfMatch)
 			return
 		}
 		logger.Error().Err(err).Msg("failed to patch task_list_category")
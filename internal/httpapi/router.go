@@ -1,12 +1,27 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"github.com/erauner12/toolbridge-api/internal/analytics"
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/calendar"
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/export"
+	"github.com/erauner12/toolbridge-api/internal/importer"
+	"github.com/erauner12/toolbridge-api/internal/inbound"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/notify"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/slack"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/usage"
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+	"github.com/erauner12/toolbridge-api/internal/zapier"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,6 +38,25 @@ type Server struct {
 	WorkOSClient    *usermanagement.Client // WorkOS client for tenant resolution
 	DefaultTenantID string        // Default tenant ID for B2C users (no organization memberships)
 	TenantAuthCache *auth.TenantAuthCache // In-memory cache for tenant authorization validation
+	AdminToken      string                // Bearer token for /admin endpoints (admin routes disabled when empty)
+	Analytics       *analytics.Recorder   // Per-user daily sync counters (nil disables recording)
+	Exports         *export.Service       // Account data export jobs (nil disables /v1/account/export)
+	Usage           *usage.Collector      // Cached usage aggregates for /admin/usage
+	Integrity       *syncservice.IntegrityService // Orphan detection/repair job (nil disables /admin/integrity)
+	Trash           *syncservice.TrashService     // Deleted items browsable at /v1/trash, and the purge job
+	Duplicates      *syncservice.DuplicateService // Duplicate note/task detection and merging at /v1/duplicates
+	Bulk            *syncservice.BulkService      // Filtered bulk updates of notes and tasks at /v1/bulk
+	Changes         *changefeed.Hub               // Change notifications for /v1/sync/ws (Run by the caller)
+	Webhooks        *webhook.Service              // Webhook subscriptions and delivery log (nil disables /v1/webhooks)
+	Calendar        *calendar.Service             // Tokenized ICS task feeds (nil disables /v1/calendar)
+	Notify          *notify.Service               // Email notifications and preferences (nil disables)
+	Imports         *importer.Service             // Todoist/TickTick import jobs (nil disables /v1/import)
+	Slack           *slack.Service                // Slack chat mirroring (nil disables /v1/integrations/slack)
+	Zapier          *zapier.Service               // API keys, polling triggers and REST hooks (nil disables /v1/zapier)
+	Inbound         *inbound.Service              // Inbound webhook endpoints (nil disables /v1/inbound)
+	Reload          func(ctx context.Context) error // Re-reads config and calls ApplySettings (nil disables POST /admin/reload)
+	DevMode         bool                            // Mounts the /dev fixture endpoints (dev profile only)
+	MockIdP         *auth.MockIdP                   // Dev-mode OIDC issuer at config.MockIdPPath (nil disables)
 	// Services
 	NoteSvc             *syncservice.NoteService
 	TaskSvc             *syncservice.TaskService
@@ -31,27 +65,27 @@ type Server struct {
 	CommentSvc          *syncservice.CommentService
 	ChatSvc             *syncservice.ChatService
 	ChatMessageSvc      *syncservice.ChatMessageService
+	PinSvc              *syncservice.PinService
+	ActivitySvc         *syncservice.ActivityService
+	LinkSvc             *syncservice.LinkService
+	CounterSvc          *syncservice.CounterService
+	SharingSvc          *syncservice.SharingService
+
+	runtime runtimeState // Reloadable settings (see ApplySettings)
 }
 
 // DefaultRateLimitConfig provides the default rate limiting configuration for sync endpoints
-var DefaultRateLimitConfig = RateLimitInfo{
-	WindowSeconds: 60,  // 1 minute window
-	MaxRequests:   600, // 600 requests per window (sustained rate)
-	Burst:         120, // Allow burst of 120 requests
-}
+var DefaultRateLimitConfig = RateLimitInfo(config.DefaultSyncRateLimit)
 
 // DefaultAuthRateLimitConfig provides stricter rate limiting for auth/bootstrap endpoints
 // These endpoints are more sensitive (token exchange, tenant resolution, session creation)
 // and should have lower limits to mitigate brute force and abuse
-var DefaultAuthRateLimitConfig = RateLimitInfo{
-	WindowSeconds: 60, // 1 minute window
-	MaxRequests:   60, // 60 auth requests per minute per client
-	Burst:         20, // Small burst allowance
-}
+var DefaultAuthRateLimitConfig = RateLimitInfo(config.DefaultAuthRateLimit)
 
 // Common request/response types for sync endpoints
 
 // pushReq is the request body for push endpoints
+// Handlers read it incrementally with pushDecoder; clients and tests build it whole.
 type pushReq struct {
 	Items []map[string]any `json:"items"`
 }
@@ -62,22 +96,36 @@ type pushAck struct {
 	Version   int    `json:"version"`
 	UpdatedAt string `json:"updatedAt"`
 	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"` // e.g. "clock_skew": fix the device clock before retrying
+	Warning   string `json:"warning,omitempty"` // Applied, but e.g. a link names a missing item
 }
 
 // pullResp is the response body for pull endpoints
+// Handlers write syncservice.PullResponse (upserts stay raw JSON); clients and tests decode into this.
 type pullResp struct {
 	Upserts    []map[string]any `json:"upserts"`
 	Deletes    []map[string]any `json:"deletes"`
 	NextCursor *string          `json:"nextCursor,omitempty"`
+	HasMore    bool             `json:"hasMore"`
 }
 
 // writeJSON writes a JSON response with the given status code
+// The body is encoded into a pooled buffer first, so an encoding failure is a
+// clean 500 rather than a truncated response.
 func writeJSON(w http.ResponseWriter, code int, v any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
 		log.Error().Err(err).Msg("failed to encode json response")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"response encoding failed"}` + "\n"))
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
 }
 
 // errorResponse represents a standardized error response with correlation ID
@@ -112,6 +160,18 @@ func parseLimit(q string, def, max int) int {
 	return n
 }
 
+// parseCursor verifies a pull or list cursor issued for the user's listing of
+// entity; an empty cursor starts from the beginning
+// On a bad cursor it writes a 400 and returns false.
+func parseCursor(w http.ResponseWriter, r *http.Request, entity, raw string) (syncx.Cursor, bool) {
+	cur, err := syncx.VerifyCursor(raw, auth.UserID(r.Context()), entity)
+	if err != nil {
+		writeError(w, r, 400, err.Error()+"; restart pagination without a cursor")
+		return syncx.Cursor{}, false
+	}
+	return cur, true
+}
+
 // Routes creates the HTTP router with all sync endpoints
 // If tenantHeaderSecret is provided, tenant header validation is enabled for MCP deployments
 func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
@@ -121,9 +181,13 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(CorrelationMiddleware) // Track X-Correlation-ID header for request tracing
+	r.Use(TracingMiddleware)     // OpenTelemetry server span per request (honors traceparent)
 	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(RecoveryMiddleware)       // Panics -> 500 problem+json (logged with stack, counted)
+	r.Use(ErrorReportingMiddleware) // Sentry: panics and 5xx (no-op without SENTRY_DSN)
 	r.Use(SessionMiddleware) // Track X-Sync-Session header
+	r.Use(s.corsMiddleware)        // Browser origins from CORS_ALLOWED_ORIGINS (reloadable)
+	r.Use(s.maintenanceMiddleware) // 503 while MAINTENANCE_MODE is on (reloadable)
 
 	// Health check (unauthenticated)
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -131,9 +195,45 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		w.Write([]byte("ok"))
 	})
 
+	// Prometheus metrics (unauthenticated; restrict /metrics at the ingress if exposed publicly)
+	r.Handle("/metrics", metrics.Handler())
+
+	// Operator endpoints (separate from user auth; only mounted when ADMIN_TOKEN is set)
+	if s.AdminToken != "" {
+		r.Route("/admin", s.adminRoutes)
+	}
+
+	// Mock OIDC issuer for local RS256/JWKS validation (unauthenticated; dev mode only)
+	if s.MockIdP != nil {
+		r.Mount(config.MockIdPPath, http.StripPrefix(config.MockIdPPath, s.MockIdP.Handler()))
+	}
+
 	// Server info / capability discovery (unauthenticated)
 	r.Get("/v1/sync/info", s.Info)
 
+	// Account export downloads (unauthenticated; authorized by the signed URL from GET /v1/account/export/{id})
+	r.Get("/v1/account/export/{id}/download", s.DownloadExport)
+
+	// ICS task feed (unauthenticated; authorized by the token from POST /v1/calendar/feed)
+	r.Get("/v1/calendar/{token}/tasks.ics", s.CalendarTasksICS)
+
+	// Slack Events API callback (unauthenticated; verified with the Slack signing secret)
+	r.Post("/v1/integrations/slack/events", s.SlackEvents)
+
+	// Inbound webhooks (unauthenticated; authorized by the token from POST /v1/inbound/endpoints)
+	r.Post("/v1/inbound/{token}", s.ReceiveInbound)
+
+	// No-code platform endpoints (Zapier, Make): static API key instead of JWT + sync session
+	r.Group(func(r chi.Router) {
+		r.Use(s.APIKeyMiddleware)
+		r.Use(s.rateLimit(false))
+
+		r.Get("/v1/zapier/me", s.ZapierMe)
+		r.Get("/v1/zapier/items/{entity}", s.ZapierItems)
+		r.Post("/v1/zapier/hooks", s.SubscribeZapierHook)
+		r.Delete("/v1/zapier/hooks/{id}", s.UnsubscribeZapierHook)
+	})
+
 	// All sync endpoints require authentication
 	r.Group(func(r chi.Router) {
 		r.Use(auth.Middleware(s.DB, jwt))
@@ -142,7 +242,7 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		// These are used to discover tenant ID or exchange tokens before tenant is known
 		// Rate limited with stricter auth defaults (60 req/min vs 600 for sync endpoints)
 		r.Group(func(r chi.Router) {
-			r.Use(AuthRateLimitMiddleware(s.AuthRateLimitConfig))
+			r.Use(s.rateLimit(true))
 
 			// Token exchange (Path B OAuth 2.1)
 			// Converts MCP OAuth tokens to backend JWTs
@@ -160,6 +260,13 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			r.Delete("/v1/sync/sessions/{id}", s.EndSession)
 		})
 
+		// Fixture endpoints for front-end and MCP development; no session or epoch
+		// check, since both reset them
+		if s.DevMode {
+			r.Post("/dev/seed", s.DevSeed)
+			r.Post("/dev/reset", s.DevReset)
+		}
+
 		// Routes that require tenant header validation (MCP deployments)
 		r.Group(func(r chi.Router) {
 			// Tenant header validation for multi-tenant MCP deployments
@@ -168,11 +275,31 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			log.Info().Msg("Tenant header validation enabled with WorkOS authorization check")
 			r.Use(auth.SimpleTenantHeaderMiddleware(s.WorkOSClient, s.TenantAuthCache, s.DefaultTenantID))
 
+		// Change notifications: checked like the sync endpoints when the socket opens,
+		// without byte counting (the connection is hijacked)
+		r.Group(func(r chi.Router) {
+			r.Use(SessionRequired)
+			r.Use(s.rateLimit(false))
+			r.Use(EpochRequired(s.DB))
+
+			r.Get("/v1/sync/ws", s.SyncWebSocket)
+		})
+
 		// Entity sync endpoints require active session, rate limiting, and epoch validation
 		r.Group(func(r chi.Router) {
 			r.Use(SessionRequired) // Enforce X-Sync-Session header
-			r.Use(RateLimitMiddleware(s.RateLimitConfig))
+			r.Use(s.rateLimit(false))
 			r.Use(EpochRequired(s.DB)) // NEW: Validate epoch on all entity operations
+			r.Use(s.analyticsBytes)    // Count push/pull payload bytes for /v1/sync/analytics
+
+			// All entity types in one request (per-entity queries run concurrently)
+			r.Get("/v1/sync/pull", s.PullAll)
+
+			// Pushes and pulls of several entity types in one round trip
+			r.Post("/v1/sync/batch", s.SyncBatch)
+
+			// Dashboard aggregates (open tasks, notes this week, unread chats)
+			r.Get("/v1/sync/counters", s.GetCounters)
 
 			// Notes
 			r.Post("/v1/sync/notes/push", s.PushNotes)
@@ -201,6 +328,23 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			// Task List Categories
 			r.Post("/v1/sync/task_list_categories/push", s.PushTaskListCategories)
 			r.Get("/v1/sync/task_list_categories/pull", s.PullTaskListCategories)
+
+			// Pins (favorite notes, tasks and chats)
+			r.Post("/v1/sync/pins/push", s.PushPins)
+			r.Get("/v1/sync/pins/pull", s.PullPins)
+
+			// Task lists other users shared with the caller, and their tasks
+			r.Get("/v1/sync/shared/task_lists/pull", s.PullSharedTaskLists)
+			r.Post("/v1/sync/shared/tasks/push", s.PushSharedTasks)
+			r.Get("/v1/sync/shared/tasks/pull", s.PullSharedTasks)
+
+			// Notes and chats (with their messages) other users shared with the caller
+			r.Post("/v1/sync/shared/notes/push", s.PushSharedNotes)
+			r.Get("/v1/sync/shared/notes/pull", s.PullSharedNotes)
+			r.Post("/v1/sync/shared/chats/push", s.PushSharedChats)
+			r.Get("/v1/sync/shared/chats/pull", s.PullSharedChats)
+			r.Post("/v1/sync/shared/chat_messages/push", s.PushSharedChatMessages)
+			r.Get("/v1/sync/shared/chat_messages/pull", s.PullSharedChatMessages)
 		})
 
 		// REST CRUD endpoints require same protections as sync endpoints
@@ -208,9 +352,14 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		// so we don't need to apply it again here
 		r.Group(func(r chi.Router) {
 			r.Use(SessionRequired)
-			r.Use(RateLimitMiddleware(s.RateLimitConfig))
+			r.Use(s.rateLimit(false))
 			r.Use(EpochRequired(s.DB))
 
+			// Single-entity downloads (static segments take precedence over /{uid})
+			for _, entity := range []string{"notes", "tasks", "comments", "chats", "chat_messages", "task_lists", "task_list_categories"} {
+				r.Get("/v1/"+entity+"/export", s.ExportEntity(entity))
+			}
+
 			// Notes REST endpoints
 			r.Get("/v1/notes", s.ListNotes)
 			r.Post("/v1/notes", s.CreateNote)
@@ -221,6 +370,25 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			r.Post("/v1/notes/{uid}/archive", s.ArchiveNote)
 			r.Post("/v1/notes/{uid}/process", s.ProcessNote)
 
+			// Trash: deleted items until purged (by hand or after TRASH_RETENTION)
+			r.Get("/v1/trash", s.ListTrash)
+			r.Delete("/v1/trash", s.EmptyTrash)
+			r.Post("/v1/trash/{type}/{uid}/restore", s.RestoreTrashItem)
+			r.Delete("/v1/trash/{type}/{uid}", s.PurgeTrashItem)
+
+			// Duplicates left by double pushes: detect, then merge into one UID
+			r.Get("/v1/duplicates", s.ListDuplicates)
+			r.Post("/v1/duplicates/merge", s.MergeDuplicates)
+
+			// Bulk updates: one action over a filtered set, in one transaction
+			r.Post("/v1/bulk/{type}", s.BulkUpdate)
+
+			// Note revision history (bounded; restore writes a new version)
+			r.Get("/v1/notes/{uid}/revisions", s.ListNoteRevisions)
+			r.Get("/v1/notes/{uid}/revisions/diff", s.DiffNoteRevisions)
+			r.Get("/v1/notes/{uid}/revisions/{version}", s.GetNoteRevision)
+			r.Post("/v1/notes/{uid}/revisions/{version}/restore", s.RestoreNoteRevision)
+
 			// Tasks REST endpoints
 			r.Get("/v1/tasks", s.ListTasks)
 			r.Post("/v1/tasks", s.CreateTask)
@@ -271,6 +439,25 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			r.Post("/v1/task_lists/{uid}/archive", s.ArchiveTaskList)
 			r.Post("/v1/task_lists/{uid}/process", s.ProcessTaskList)
 
+			// Task list sharing (owner side, then member side)
+			r.Post("/v1/task_lists/{uid}/members", s.AddTaskListMember)
+			r.Get("/v1/task_lists/{uid}/members", s.ListTaskListMembers)
+			r.Delete("/v1/task_lists/{uid}/members/{memberId}", s.RemoveTaskListMember)
+			r.Get("/v1/shared/task_lists", s.ListSharedTaskLists)
+			r.Delete("/v1/shared/task_lists/{uid}", s.LeaveSharedTaskList)
+
+			// Per-item sharing of notes and chats (owner side, then grantee side)
+			r.Post("/v1/notes/{uid}/grants", s.AddItemGrant(syncservice.GrantNote))
+			r.Get("/v1/notes/{uid}/grants", s.ListItemGrants(syncservice.GrantNote))
+			r.Delete("/v1/notes/{uid}/grants/{userId}", s.RevokeItemGrant(syncservice.GrantNote))
+			r.Post("/v1/chats/{uid}/grants", s.AddItemGrant(syncservice.GrantChat))
+			r.Get("/v1/chats/{uid}/grants", s.ListItemGrants(syncservice.GrantChat))
+			r.Delete("/v1/chats/{uid}/grants/{userId}", s.RevokeItemGrant(syncservice.GrantChat))
+			r.Get("/v1/shared/notes", s.ListSharedItems(syncservice.GrantNote))
+			r.Delete("/v1/shared/notes/{uid}", s.LeaveSharedItem(syncservice.GrantNote))
+			r.Get("/v1/shared/chats", s.ListSharedItems(syncservice.GrantChat))
+			r.Delete("/v1/shared/chats/{uid}", s.LeaveSharedItem(syncservice.GrantChat))
+
 			// Task List Categories REST endpoints
 			r.Get("/v1/task_list_categories", s.ListTaskListCategories)
 			r.Post("/v1/task_list_categories", s.CreateTaskListCategory)
@@ -280,6 +467,12 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			r.Delete("/v1/task_list_categories/{uid}", s.DeleteTaskListCategory)
 			r.Post("/v1/task_list_categories/{uid}/archive", s.ArchiveTaskListCategory)
 			r.Post("/v1/task_list_categories/{uid}/process", s.ProcessTaskListCategory)
+
+			// Activity feed (recent changes across all entities)
+			r.Get("/v1/activity", s.GetActivity)
+
+			// Items that link to an item (see the payload "links" field)
+			r.Get("/v1/links/backlinks/{uid}", s.GetBacklinks)
 		})
 
 			// Wipe & state routes require auth + session, but NO epoch check
@@ -289,6 +482,48 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 
 				r.Post("/v1/sync/wipe", s.WipeAccount)
 				r.Get("/v1/sync/state", s.GetSyncState)
+				r.Get("/v1/sync/analytics", s.GetSyncAnalytics)
+
+				// Account data export (GDPR): enqueue + poll
+				r.Post("/v1/account/export", s.RequestExport)
+				r.Get("/v1/account/export/{id}", s.GetExport)
+
+				// Webhook subscriptions for entity change events
+				r.Post("/v1/webhooks", s.CreateWebhook)
+				r.Get("/v1/webhooks", s.ListWebhooks)
+				r.Get("/v1/webhooks/{id}", s.GetWebhook)
+				r.Delete("/v1/webhooks/{id}", s.DeleteWebhook)
+				r.Get("/v1/webhooks/{id}/deliveries", s.ListWebhookDeliveries)
+
+				// Calendar feed URL management
+				r.Post("/v1/calendar/feed", s.RotateCalendarFeed)
+				r.Get("/v1/calendar/feed", s.GetCalendarFeed)
+				r.Delete("/v1/calendar/feed", s.RevokeCalendarFeed)
+
+				// Email notification preferences
+				r.Get("/v1/notifications/preferences", s.GetNotificationPreferences)
+				r.Put("/v1/notifications/preferences", s.PutNotificationPreferences)
+
+				// Todoist / TickTick import: upload + poll
+				r.Post("/v1/import/{source}", s.RequestImport)
+				r.Get("/v1/import/{id}", s.GetImport)
+
+				// Slack chat mirroring
+				r.Post("/v1/integrations/slack", s.CreateSlackConnection)
+				r.Get("/v1/integrations/slack", s.ListSlackConnections)
+				r.Delete("/v1/integrations/slack/{id}", s.DeleteSlackConnection)
+				r.Get("/v1/integrations/slack/{id}/deliveries", s.ListSlackDeliveries)
+
+				// API keys for /v1/zapier
+				r.Post("/v1/api-keys", s.CreateAPIKey)
+				r.Get("/v1/api-keys", s.ListAPIKeys)
+				r.Delete("/v1/api-keys/{id}", s.RevokeAPIKey)
+
+				// Inbound webhook endpoints (mapping templates -> notes/tasks)
+				r.Post("/v1/inbound/endpoints", s.CreateInboundEndpoint)
+				r.Get("/v1/inbound/endpoints", s.ListInboundEndpoints)
+				r.Put("/v1/inbound/endpoints/{id}", s.UpdateInboundEndpoint)
+				r.Delete("/v1/inbound/endpoints/{id}", s.DeleteInboundEndpoint)
 			})
 		}) // End tenant header middleware group
 	})
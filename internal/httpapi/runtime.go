@@ -0,0 +1,167 @@
+package httpapi
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// RuntimeSettings are the settings that can change without a restart
+// Applied at startup and again on SIGHUP or POST /admin/reload; everything
+// else on Server is fixed for the life of the process.
+type RuntimeSettings struct {
+	RateLimit          RateLimitInfo   `json:"rateLimit"`
+	AuthRateLimit      RateLimitInfo   `json:"authRateLimit"`
+	CORSOrigins        []string        `json:"corsOrigins"` // Exact origins or "*" (empty disables CORS headers)
+	Features           map[string]bool `json:"features"`    // Client feature flags advertised by /v1/sync/info
+	Maintenance        bool            `json:"maintenance"` // 503 for everything but probes, /admin and /v1/sync/info
+	MaintenanceMessage string          `json:"maintenanceMessage,omitempty"`
+	MaxClockSkewMs     int64           `json:"maxClockSkewMs"`   // Pushed timestamps further ahead are rejected (0 disables)
+	ServerTimestamps   bool            `json:"serverTimestamps"` // Server assigns pushed items' updated_at_ms
+}
+
+// runtimeState holds the live settings and the limiters built from them
+type runtimeState struct {
+	settings atomic.Pointer[RuntimeSettings]
+
+	mu           sync.Mutex // Serializes ApplySettings with limiter registration
+	limiters     []*RateLimiter
+	authLimiters []*RateLimiter
+}
+
+// corsExposeHeaders are the response headers browser clients may read
+const corsExposeHeaders = "X-Correlation-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, X-RateLimit-Burst, Retry-After"
+
+// Settings returns the settings in effect
+// Before ApplySettings is called these are the startup rate limits with
+// CORS, feature flags and maintenance mode off.
+func (s *Server) Settings() RuntimeSettings {
+	if rs := s.runtime.settings.Load(); rs != nil {
+		return *rs
+	}
+	return RuntimeSettings{RateLimit: s.RateLimitConfig, AuthRateLimit: s.AuthRateLimitConfig}
+}
+
+// ApplySettings swaps in new runtime settings
+// Rate limiters keep their per-user buckets, so a reload doesn't reset anyone's budget.
+func (s *Server) ApplySettings(rs RuntimeSettings) {
+	s.runtime.mu.Lock()
+	defer s.runtime.mu.Unlock()
+
+	s.runtime.settings.Store(&rs)
+	syncx.SetMaxClockSkew(time.Duration(rs.MaxClockSkewMs) * time.Millisecond)
+	syncx.SetServerTimestamps(rs.ServerTimestamps)
+	for _, l := range s.runtime.limiters {
+		l.SetConfig(rateLimitOrDefault(rs.RateLimit, DefaultRateLimitConfig))
+	}
+	for _, l := range s.runtime.authLimiters {
+		l.SetConfig(rateLimitOrDefault(rs.AuthRateLimit, DefaultAuthRateLimitConfig))
+	}
+}
+
+// FeatureEnabled reports whether a feature flag is on (unknown flags are off)
+func (s *Server) FeatureEnabled(name string) bool {
+	return s.Settings().Features[name]
+}
+
+// rateLimit is RateLimitMiddleware (or AuthRateLimitMiddleware with authLimits)
+// whose limiter follows ApplySettings
+func (s *Server) rateLimit(authLimits bool) func(http.Handler) http.Handler {
+	s.runtime.mu.Lock()
+	defer s.runtime.mu.Unlock()
+
+	settings := s.Settings()
+	var limiter *RateLimiter
+	if authLimits {
+		limiter = NewRateLimiter(rateLimitOrDefault(settings.AuthRateLimit, DefaultAuthRateLimitConfig))
+		s.runtime.authLimiters = append(s.runtime.authLimiters, limiter)
+	} else {
+		limiter = NewRateLimiter(rateLimitOrDefault(settings.RateLimit, DefaultRateLimitConfig))
+		s.runtime.limiters = append(s.runtime.limiters, limiter)
+	}
+	return limitMiddleware(limiter)
+}
+
+// corsMiddleware sets Access-Control-* headers for allowed origins and answers preflight requests
+// Requests from other origins pass through untouched (the browser blocks the response).
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins := s.Settings().CORSOrigins
+		if len(origins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if origin == "" || !(slices.Contains(origins, "*") || slices.Contains(origins, origin)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", reqHeaders)
+			}
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maintenanceExempt reports whether path is served during maintenance
+// Probes, metrics and operators keep working; /v1/sync/info tells clients why.
+func maintenanceExempt(path string) bool {
+	switch path {
+	case "/healthz", "/metrics", "/v1/sync/info":
+		return true
+	}
+	return strings.HasPrefix(path, "/admin/")
+}
+
+// maintenanceMiddleware returns 503 with Retry-After while maintenance mode is on
+func (s *Server) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs := s.runtime.settings.Load()
+		if rs == nil || !rs.Maintenance || maintenanceExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", "60")
+		writeError(w, r, http.StatusServiceUnavailable, cmp.Or(rs.MaintenanceMessage, "service under maintenance"))
+	})
+}
+
+// GetSettings handles GET /admin/settings
+func (s *Server) GetSettings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Settings())
+}
+
+// ReloadSettings handles POST /admin/reload
+// Same as SIGHUP: re-reads the configuration and applies its runtime
+// settings. Sessions and connections are unaffected.
+func (s *Server) ReloadSettings(w http.ResponseWriter, r *http.Request) {
+	if s.Reload == nil {
+		writeError(w, r, http.StatusNotFound, "config reload disabled")
+		return
+	}
+	if err := s.Reload(r.Context()); err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("admin: config reload failed")
+		writeError(w, r, http.StatusUnprocessableEntity, "config reload failed: "+err.Error())
+		return
+	}
+	log.Ctx(r.Context()).Warn().Msg("admin: configuration reloaded")
+	s.GetSettings(w, r)
+}
@@ -0,0 +1,169 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/config"
+)
+
+func TestRateLimiterSetConfig(t *testing.T) {
+	rl := NewRateLimiter(RateLimitInfo{WindowSeconds: 60, MaxRequests: 60, Burst: 5})
+	if allowed, remaining, _, _ := rl.Allow("u1"); !allowed || remaining != 4 {
+		t.Fatalf("allowed=%t remaining=%d", allowed, remaining)
+	}
+
+	// Shrinking the burst caps the existing bucket instead of resetting it
+	rl.SetConfig(RateLimitInfo{WindowSeconds: 3600, MaxRequests: 1, Burst: 1})
+	if allowed, _, _, _ := rl.Allow("u1"); !allowed {
+		t.Fatal("first request after reload rejected")
+	}
+	if allowed, _, _, _ := rl.Allow("u1"); allowed {
+		t.Error("second request allowed with burst 1")
+	}
+	if got := rl.Config(); got.Burst != 1 || got.MaxRequests != 1 {
+		t.Errorf("config = %+v", got)
+	}
+}
+
+func TestApplySettingsUpdatesLimiters(t *testing.T) {
+	srv := &Server{}
+	handler := srv.rateLimit(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.ApplySettings(RuntimeSettings{RateLimit: RateLimitInfo{WindowSeconds: 60, MaxRequests: 30, Burst: 7}})
+
+	req := httptest.NewRequest("GET", "/v1/notes", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.CtxUserID, "u1"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get("X-RateLimit-Burst"); got != "7" {
+		t.Errorf("X-RateLimit-Burst = %q, want reloaded 7", got)
+	}
+	if got := srv.Settings().RateLimit.MaxRequests; got != 30 {
+		t.Errorf("settings max requests = %d", got)
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	srv := &Server{}
+	handler := srv.maintenanceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	srv.ApplySettings(RuntimeSettings{Maintenance: true, MaintenanceMessage: "back at 10:00 UTC"})
+	for path, want := range map[string]int{
+		"/v1/notes":        503,
+		"/v1/sync/info":    200,
+		"/healthz":         200,
+		"/admin/log-level": 200,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != want {
+			t.Errorf("%s = %d, want %d", path, w.Code, want)
+		}
+		if want == 503 {
+			var body errorResponse
+			_ = json.NewDecoder(w.Body).Decode(&body)
+			if body.Error != "back at 10:00 UTC" || w.Header().Get("Retry-After") == "" {
+				t.Errorf("503 body %+v, Retry-After %q", body, w.Header().Get("Retry-After"))
+			}
+		}
+	}
+
+	srv.ApplySettings(RuntimeSettings{})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/v1/notes", nil))
+	if w.Code != 200 {
+		t.Errorf("after reload = %d, want 200", w.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	srv := &Server{}
+	called := false
+	handler := srv.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	srv.ApplySettings(RuntimeSettings{CORSOrigins: []string{"https://app.example.com"}})
+
+	req := httptest.NewRequest("OPTIONS", "/v1/notes", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, x-sync-session")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != 204 || called {
+		t.Fatalf("preflight = %d (next called %t)", w.Code, called)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "authorization, x-sync-session" {
+		t.Errorf("preflight headers = %v", w.Header())
+	}
+
+	req = httptest.NewRequest("GET", "/v1/notes", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin: next called %t, headers %v", called, w.Header())
+	}
+}
+
+func TestAdminReload(t *testing.T) {
+	srv := &Server{AdminToken: "admin-secret"}
+	router := newAdminRouter(srv)
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/reload", nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(); w.Code != 404 {
+		t.Errorf("without Reload = %d, want 404", w.Code)
+	}
+
+	srv.Reload = func(ctx context.Context) error {
+		srv.ApplySettings(RuntimeSettings{Features: map[string]bool{"shared_lists": true}})
+		return nil
+	}
+	w := post()
+	var got RuntimeSettings
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil || w.Code != 200 || !got.Features["shared_lists"] {
+		t.Errorf("reload = %d %+v (%v)", w.Code, got, err)
+	}
+	if !srv.FeatureEnabled("shared_lists") || srv.FeatureEnabled("unknown") {
+		t.Error("FeatureEnabled does not follow reloaded flags")
+	}
+
+	srv.Reload = func(ctx context.Context) error { return errors.New("config.yaml: bad") }
+	if w := post(); w.Code != 422 || !srv.FeatureEnabled("shared_lists") {
+		t.Errorf("failed reload = %d, settings must be kept", w.Code)
+	}
+}
+
+func TestNewServerFromConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Env = "dev"
+	cfg.MCP.OAuthAudience = "https://mcp.example.com/mcp"
+	cfg.Maintenance.Enabled = true
+	cfg.RateLimit.Sync.Burst = 7
+
+	srv := NewServer(nil, cfg)
+	if !srv.JWTCfg.DevMode || srv.JWTCfg.Env != "dev" || len(srv.JWTCfg.AcceptedAudiences) != 1 {
+		t.Errorf("jwt cfg = %+v", srv.JWTCfg)
+	}
+	if !srv.Webhooks.AllowHTTP || srv.Notify == nil {
+		t.Error("dev profile must allow http webhooks and log emails")
+	}
+	if rs := srv.Settings(); !rs.Maintenance || rs.RateLimit.Burst != 7 {
+		t.Errorf("settings = %+v", rs)
+	}
+
+	cfg.Env = "prod"
+	if srv := NewServer(nil, cfg); srv.JWTCfg.DevMode || srv.Webhooks.AllowHTTP || srv.Notify != nil {
+		t.Error("prod profile must not enable dev behavior")
+	}
+}
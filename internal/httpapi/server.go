@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"github.com/erauner12/toolbridge-api/internal/analytics"
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/calendar"
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/export"
+	"github.com/erauner12/toolbridge-api/internal/importer"
+	"github.com/erauner12/toolbridge-api/internal/inbound"
+	"github.com/erauner12/toolbridge-api/internal/notify"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/slack"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/usage"
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+	"github.com/erauner12/toolbridge-api/internal/zapier"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/workos/workos-go/v6/pkg/usermanagement"
+)
+
+// NewServer builds a Server with every service wired from the configuration
+// and its runtime settings applied. Reload is left nil (the caller owns how
+// configuration is re-read).
+func NewServer(pool *pgxpool.Pool, c *config.Config) *Server {
+	devMode := c.DevMode()
+	jwtCfg := auth.NewJWTCfg(c)
+
+	// WorkOS client enables /v1/auth/tenant for automatic tenant resolution
+	var workosClient *usermanagement.Client
+	if c.Auth.WorkOSAPIKey != "" {
+		workosClient = usermanagement.NewClient(c.Auth.WorkOSAPIKey)
+		log.Info().Msg("WorkOS client initialized for tenant resolution")
+	} else {
+		log.Info().Msg("WorkOS tenant resolution disabled (WORKOS_API_KEY not set)")
+	}
+
+	// Email notifications: SMTP relay (NOTIFY_SES_REGION targets Amazon SES's SMTP
+	// endpoint); dev mode without a relay logs emails instead of sending them
+	var notifier *notify.Service
+	smtpAddr := c.Notify.SMTPAddr
+	if region := c.Notify.SESRegion; smtpAddr == "" && region != "" {
+		smtpAddr = notify.SESSMTPAddr(region)
+	}
+	switch {
+	case smtpAddr != "":
+		notifier = notify.NewService(pool, notify.NewSMTPSender(notify.SMTPConfig{
+			Addr:     smtpAddr,
+			Username: c.Notify.SMTPUsername,
+			Password: c.Notify.SMTPPassword,
+			From:     c.Notify.From,
+		}))
+		log.Info().Str("smtp", smtpAddr).Msg("email notifications enabled")
+	case devMode:
+		notifier = notify.NewService(pool, notify.LogSender{})
+	}
+
+	// Mock OIDC issuer: tokens it signs are validated via JWT_ISSUER/JWT_JWKS_URL
+	// like any upstream IdP's (config.Load points those at it by default)
+	var mockIdP *auth.MockIdP
+	if devMode && c.Auth.MockIdP {
+		var err error
+		if mockIdP, err = auth.NewMockIdP(jwtCfg); err != nil {
+			log.Error().Err(err).Msg("mock IdP disabled: failed to generate signing key")
+		} else {
+			log.Warn().Str("issuer", mockIdP.Issuer).Msg("mock IdP enabled: its token endpoint signs tokens for any subject")
+		}
+	}
+
+	// Webhook callbacks must be HTTPS outside dev mode
+	webhooks := webhook.NewService(pool)
+	webhooks.AllowHTTP = devMode
+
+	// Pushes into shared lists and items go through the entity services
+	notes := syncservice.NewNoteService(pool)
+	tasks := syncservice.NewTaskService(pool)
+	chats := syncservice.NewChatService(pool)
+	messages := syncservice.NewChatMessageService(pool)
+
+	srv := &Server{
+		DB:                  pool,
+		RateLimitConfig:     RateLimitInfo(c.RateLimit.Sync),
+		AuthRateLimitConfig: RateLimitInfo(c.RateLimit.Auth),
+		JWTCfg:              jwtCfg,
+		WorkOSClient:        workosClient,
+		DefaultTenantID:     c.Auth.DefaultTenantID,
+		TenantAuthCache:     auth.NewTenantAuthCache(),
+		AdminToken:          c.Admin.Token,
+		Analytics:           analytics.NewRecorder(pool),
+		Exports:             export.NewService(pool, []byte(c.Export.SigningKey)),
+		Usage:               usage.NewCollector(pool),
+		Integrity:           syncservice.NewIntegrityService(pool, c.Jobs.OrphanPolicy),
+		Trash:               syncservice.NewTrashService(pool, c.Jobs.TrashRetention),
+		Duplicates:          syncservice.NewDuplicateService(pool),
+		Bulk:                syncservice.NewBulkService(pool),
+		Changes:             changefeed.NewHub(pool),
+		Webhooks:            webhooks,
+		Calendar:            calendar.NewService(pool),
+		Notify:              notifier,
+		Imports:             importer.NewService(pool),
+		Slack:               slack.NewService(pool, c.Integrations.SlackSigningSecret), // Replies need the app's signing secret
+		Zapier:              zapier.NewService(pool, webhooks),
+		Inbound:             inbound.NewService(pool),
+		DevMode:             devMode,
+		MockIdP:             mockIdP,
+
+		NoteSvc:             notes,
+		TaskSvc:             tasks,
+		CommentSvc:          syncservice.NewCommentService(pool),
+		ChatSvc:             chats,
+		ChatMessageSvc:      messages,
+		PinSvc:              syncservice.NewPinService(pool),
+		TaskListSvc:         syncservice.NewTaskListService(pool),
+		TaskListCategorySvc: syncservice.NewTaskListCategoryService(pool),
+		ActivitySvc:         syncservice.NewActivityService(pool),
+		LinkSvc:             syncservice.NewLinkService(pool),
+		CounterSvc:          syncservice.NewCounterService(pool),
+		SharingSvc:          syncservice.NewSharingService(pool, tasks, notes, chats, messages),
+	}
+	srv.ApplySettings(SettingsFromConfig(c))
+
+	// Pull cursors are signed for every transport; the key is read at startup only
+	syncx.SetCursorKey([]byte(c.Sync.CursorKey))
+	return srv
+}
+
+// SettingsFromConfig returns the configuration's reloadable settings
+func SettingsFromConfig(c *config.Config) RuntimeSettings {
+	return RuntimeSettings{
+		RateLimit:          RateLimitInfo(c.RateLimit.Sync),
+		AuthRateLimit:      RateLimitInfo(c.RateLimit.Auth),
+		CORSOrigins:        c.CORS.AllowedOrigins,
+		Features:           c.Features,
+		Maintenance:        c.Maintenance.Enabled,
+		MaintenanceMessage: c.Maintenance.Message,
+		MaxClockSkewMs:     c.Sync.MaxClockSkew.Milliseconds(),
+		ServerTimestamps:   c.Sync.ServerTimestamps,
+	}
+}
@@ -0,0 +1,34 @@
+package httpapi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestMockIdP(t *testing.T) {
+	env := testutil.NewEnv(t, func(c *config.Config) {
+		c.Auth.MockIdP = true
+		c.Auth.Audience = "toolbridge-api"
+	})
+	c := syncclient.New(env.URL)
+	c.Token = env.MockIdPToken(t, "idp-user")
+	if err := c.BeginSession(context.Background()); err != nil {
+		t.Fatalf("begin session with a mock IdP token: %v", err)
+	}
+	pushOne(t, c, note("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "via jwks", "2025-11-03T10:00:00Z"))
+
+	// Keys are per server; a restarted server's tokens are still accepted
+	env.Restart()
+	c.Token = env.MockIdPToken(t, "idp-user")
+	if err := c.BeginSession(context.Background()); err != nil {
+		t.Fatalf("begin session after restart: %v", err)
+	}
+	resp, err := c.Pull(context.Background(), "notes", "", 0)
+	if err != nil || len(resp.Upserts) != 1 {
+		t.Errorf("pulled %v (err %v), want the note pushed with the first key", resp, err)
+	}
+}
@@ -1,17 +1,27 @@
 package httpapi
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/session"
 	"github.com/rs/zerolog/log"
 )
 
 // SessionRequired middleware enforces that a valid sync session is active
 // This should be applied to all sync entity endpoints (push/pull)
 // but NOT to /info or session management endpoints
+// How much is checked follows session.GetValidation (SESSION_VALIDATION).
 func SessionRequired(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level := session.GetValidation()
+		if level == session.ValidationOff {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Get session ID from context (set by SessionMiddleware)
 		sessionID := GetSessionID(r.Context())
 
@@ -27,7 +37,7 @@ func SessionRequired(next http.Handler) http.Handler {
 		}
 
 		// Validate that the session exists and is not expired
-		session, ok := sessionStore.GetSession(sessionID)
+		sess, ok := sessionStore().GetSession(sessionID)
 		if !ok {
 			log.Warn().
 				Str("sessionId", sessionID).
@@ -41,10 +51,10 @@ func SessionRequired(next http.Handler) http.Handler {
 
 		// Validate that the session belongs to the authenticated user
 		authenticatedUserID := auth.UserID(r.Context())
-		if session.UserID != authenticatedUserID {
+		if sess.UserID != authenticatedUserID {
 			log.Warn().
 				Str("sessionId", sessionID).
-				Str("sessionUserId", session.UserID).
+				Str("sessionUserId", sess.UserID).
 				Str("authenticatedUserId", authenticatedUserID).
 				Str("path", r.URL.Path).
 				Msg("Session does not belong to authenticated user")
@@ -54,6 +64,25 @@ func SessionRequired(next http.Handler) http.Handler {
 			return
 		}
 
+		// Strict: the session must have been begun at the epoch the client claims
+		// (a session from before a wipe can't be reused with the new epoch)
+		if level == session.ValidationStrict {
+			claimed := r.Header.Get("X-Sync-Epoch")
+			if claimed != strconv.Itoa(sess.Epoch) {
+				log.Warn().
+					Str("sessionId", sessionID).
+					Int("sessionEpoch", sess.Epoch).
+					Str("claimedEpoch", claimed).
+					Str("path", r.URL.Path).
+					Msg("Session epoch does not match X-Sync-Epoch")
+
+				writeError(w, r, http.StatusPreconditionRequired, fmt.Sprintf(
+					"Sync session was begun at epoch %d but X-Sync-Epoch is %q. Please call POST /v1/sync/sessions to begin a new session.",
+					sess.Epoch, claimed))
+				return
+			}
+		}
+
 		// Session is valid and belongs to the authenticated user, proceed with request
 		next.ServeHTTP(w, r)
 	})
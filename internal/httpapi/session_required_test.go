@@ -3,10 +3,12 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/session"
 )
 
 func TestSessionRequired_UserMismatch(t *testing.T) {
@@ -115,3 +117,47 @@ func TestSessionRequired_SameUser(t *testing.T) {
 			rec.Code, rec.Body.String())
 	}
 }
+
+func TestSessionRequired_Validation(t *testing.T) {
+	t.Cleanup(func() { session.SetValidation(session.ValidationStandard) })
+	sess := sessionStore().CreateSession("user-a", 2)
+	handler := SessionRequired(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(userID, sessionID, epoch string) int {
+		req := httptest.NewRequest("POST", "/v1/sync/notes/push", nil)
+		ctx := context.WithValue(req.Context(), auth.CtxUserID, userID)
+		if sessionID != "" {
+			ctx = context.WithValue(ctx, sessionIDKey, sessionID)
+		}
+		if epoch != "" {
+			req.Header.Set("X-Sync-Epoch", epoch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		return w.Code
+	}
+
+	tests := []struct {
+		level, user, session, epoch string
+		want                        int
+	}{
+		{session.ValidationOff, "user-a", "", "", 204},
+		{session.ValidationOff, "user-b", sess.ID, "", 204},
+		{session.ValidationStandard, "user-a", "", "2", 428},
+		{session.ValidationStandard, "user-a", "00000000-0000-4000-8000-000000000000", "2", 428},
+		{session.ValidationStandard, "user-b", sess.ID, "2", 403},
+		{session.ValidationStandard, "user-a", sess.ID, "1", 204},
+		{session.ValidationStrict, "user-a", sess.ID, "2", 204},
+		{session.ValidationStrict, "user-a", sess.ID, "3", 428},
+		{session.ValidationStrict, "user-a", sess.ID, "", 428},
+	}
+	for _, tt := range tests {
+		session.SetValidation(tt.level)
+		if got := serve(tt.user, tt.session, tt.epoch); got != tt.want {
+			t.Errorf("%s: user %s, session %q, epoch %q: status = %d, want %d",
+				tt.level, tt.user, tt.session, tt.epoch, got, tt.want)
+		}
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/session"
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog/log"
 )
 
-// Use the global shared session store
-var sessionStore = session.GetStore()
+// sessionStore returns the global shared session store (memory or postgres,
+// chosen at startup)
+func sessionStore() session.Store {
+	return session.GetStore()
+}
 
 // HTTP Handlers
 
@@ -26,35 +28,15 @@ func (s *Server) BeginSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Load or create owner_state row (lazy initialization)
-	var epoch int
-	err := s.DB.QueryRow(r.Context(), `
-		INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
-		VALUES ($1, 1, NOW(), NOW())
-		ON CONFLICT (owner_id) DO NOTHING
-		RETURNING epoch
-	`, userID).Scan(&epoch)
-
+	epoch, err := currentEpoch(r.Context(), s.DB, userID)
 	if err != nil {
-		// If insert did nothing (row exists), select epoch
-		if err == pgx.ErrNoRows {
-			err = s.DB.QueryRow(r.Context(),
-				`SELECT epoch FROM owner_state WHERE owner_id = $1`,
-				userID,
-			).Scan(&epoch)
-			if err != nil {
-				log.Error().Err(err).Str("userId", userID).Msg("Failed to load epoch")
-				writeError(w, r, http.StatusInternalServerError, "Failed to load epoch")
-				return
-			}
-		} else {
-			log.Error().Err(err).Str("userId", userID).Msg("Failed to initialize epoch")
-			writeError(w, r, http.StatusInternalServerError, "Failed to initialize epoch")
-			return
-		}
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to load epoch")
+		writeError(w, r, http.StatusInternalServerError, "Failed to load epoch")
+		return
 	}
 
 	// Create session with epoch
-	session := sessionStore.CreateSession(userID, epoch)
+	session := sessionStore().CreateSession(userID, epoch)
 
 	log.Info().
 		Str("sessionId", session.ID).
@@ -84,7 +66,7 @@ func (s *Server) EndSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify session belongs to user
-	session, exists := sessionStore.GetSession(sessionID)
+	session, exists := sessionStore().GetSession(sessionID)
 	if !exists {
 		http.Error(w, "session not found or expired", http.StatusNotFound)
 		return
@@ -95,7 +77,7 @@ func (s *Server) EndSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionStore.DeleteSession(sessionID)
+	sessionStore().DeleteSession(sessionID)
 
 	log.Info().
 		Str("sessionId", sessionID).
@@ -120,7 +102,7 @@ func (s *Server) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, exists := sessionStore.GetSession(sessionID)
+	session, exists := sessionStore().GetSession(sessionID)
 	if !exists {
 		http.Error(w, "session not found or expired", http.StatusNotFound)
 		return
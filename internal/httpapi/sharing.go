@@ -0,0 +1,402 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// addMemberRequest is the body of POST /v1/task_lists/{uid}/members
+type addMemberRequest struct {
+	Sub        string `json:"sub"`        // The member's auth subject (they must have signed in once)
+	Permission string `json:"permission"` // "view" or "edit" (default "view")
+}
+
+// writeSharingError maps sharing service errors to responses
+func writeSharingError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	switch {
+	case errors.Is(err, syncservice.ErrListNotFound),
+		errors.Is(err, syncservice.ErrItemNotFound),
+		errors.Is(err, syncservice.ErrUserNotFound),
+		errors.Is(err, syncservice.ErrMemberNotFound):
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, syncservice.ErrInvalidPermission),
+		errors.Is(err, syncservice.ErrShareWithSelf):
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	default:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", auth.UserID(r.Context())).Msg("Failed to " + action)
+		writeError(w, r, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// AddTaskListMember handles POST /v1/task_lists/{uid}/members
+// Shares one of the caller's live task lists with another user, or changes
+// their permission. Members pull the list and its tasks from
+// /v1/sync/shared/*; with "edit" they can also push tasks into it.
+func (s *Server) AddTaskListMember(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	req := addMemberRequest{Permission: syncservice.PermissionView}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Sub == "" {
+		writeError(w, r, http.StatusBadRequest, `body must be {"sub": "...", "permission": "view"|"edit"}`)
+		return
+	}
+
+	member, err := s.SharingSvc.AddMember(r.Context(), userID, uid, req.Sub, req.Permission)
+	if err != nil {
+		writeSharingError(w, r, err, "share task list")
+		return
+	}
+	log.Ctx(r.Context()).Info().
+		Str("userId", userID).
+		Str("listUid", uid.String()).
+		Str("memberId", member.UserID).
+		Str("permission", member.Permission).
+		Msg("task list shared")
+	writeJSON(w, http.StatusOK, member)
+}
+
+// ListTaskListMembers handles GET /v1/task_lists/{uid}/members
+func (s *Server) ListTaskListMembers(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	members, err := s.SharingSvc.ListMembers(r.Context(), auth.UserID(r.Context()), uid)
+	if err != nil {
+		writeSharingError(w, r, err, "list task list members")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"members": members})
+}
+
+// RemoveTaskListMember handles DELETE /v1/task_lists/{uid}/members/{memberId}
+// The member stops seeing the list; tasks they added stay in it.
+func (s *Server) RemoveTaskListMember(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	if err := s.SharingSvc.RemoveMember(r.Context(), auth.UserID(r.Context()), uid, chi.URLParam(r, "memberId")); err != nil {
+		writeSharingError(w, r, err, "remove task list member")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSharedTaskLists handles GET /v1/shared/task_lists
+// Lists the task lists other users shared with the caller.
+func (s *Server) ListSharedTaskLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := s.SharingSvc.SharedWith(r.Context(), auth.UserID(r.Context()))
+	if err != nil {
+		writeSharingError(w, r, err, "list shared task lists")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"lists": lists})
+}
+
+// LeaveSharedTaskList handles DELETE /v1/shared/task_lists/{uid}
+func (s *Server) LeaveSharedTaskList(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+	if err := s.SharingSvc.Leave(r.Context(), auth.UserID(r.Context()), uid); err != nil {
+		writeSharingError(w, r, err, "leave shared task list")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddItemGrant returns the handler for POST /v1/{notes,chats}/{uid}/grants
+// Shares one of the caller's live notes or chats (entity is
+// syncservice.GrantNote or GrantChat) with another user, or changes their
+// permission; the body is the same as for task list members.
+func (s *Server) AddItemGrant(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := auth.UserID(r.Context())
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, http.StatusBadRequest, "invalid UID")
+			return
+		}
+		req := addMemberRequest{Permission: syncservice.PermissionView}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Sub == "" {
+			writeError(w, r, http.StatusBadRequest, `body must be {"sub": "...", "permission": "view"|"edit"}`)
+			return
+		}
+
+		grant, err := s.SharingSvc.Grant(r.Context(), userID, entity, uid, req.Sub, req.Permission)
+		if err != nil {
+			writeSharingError(w, r, err, "share "+entity)
+			return
+		}
+		log.Ctx(r.Context()).Info().
+			Str("userId", userID).
+			Str("entity", entity).
+			Str("uid", uid.String()).
+			Str("granteeId", grant.UserID).
+			Str("permission", grant.Permission).
+			Msg("item shared")
+		writeJSON(w, http.StatusOK, grant)
+	}
+}
+
+// ListItemGrants returns the handler for GET /v1/{notes,chats}/{uid}/grants
+func (s *Server) ListItemGrants(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, http.StatusBadRequest, "invalid UID")
+			return
+		}
+		grants, err := s.SharingSvc.ListGrants(r.Context(), auth.UserID(r.Context()), entity, uid)
+		if err != nil {
+			writeSharingError(w, r, err, "list "+entity+" grants")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"grants": grants})
+	}
+}
+
+// RevokeItemGrant returns the handler for DELETE /v1/{notes,chats}/{uid}/grants/{userId}
+func (s *Server) RevokeItemGrant(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, http.StatusBadRequest, "invalid UID")
+			return
+		}
+		if err := s.SharingSvc.RevokeGrant(r.Context(), auth.UserID(r.Context()), entity, uid, chi.URLParam(r, "userId")); err != nil {
+			writeSharingError(w, r, err, "revoke "+entity+" grant")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListSharedItems returns the handler for GET /v1/shared/{notes,chats}
+// Lists the notes or chats other users shared with the caller.
+func (s *Server) ListSharedItems(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := s.SharingSvc.GrantedTo(r.Context(), auth.UserID(r.Context()), entity)
+		if err != nil {
+			writeSharingError(w, r, err, "list shared items")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	}
+}
+
+// LeaveSharedItem returns the handler for DELETE /v1/shared/{notes,chats}/{uid}
+func (s *Server) LeaveSharedItem(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, http.StatusBadRequest, "invalid UID")
+			return
+		}
+		if err := s.SharingSvc.LeaveItem(r.Context(), auth.UserID(r.Context()), entity, uid); err != nil {
+			writeSharingError(w, r, err, "leave shared "+entity)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// sharedCursorEntity returns the cursor scope for a shared pull of entity
+// It includes the caller's membership tag, so cursors issued before a
+// membership change fail with 400 and the client restarts its pull.
+func (s *Server) sharedCursorEntity(r *http.Request, entity string) (string, error) {
+	tag, err := s.SharingSvc.MembershipTag(r.Context(), auth.UserID(r.Context()))
+	if err != nil {
+		return "", err
+	}
+	return "shared_" + entity + ":" + tag, nil
+}
+
+// pullShared serves GET /v1/sync/shared/{entity}/pull from the service's pull
+func (s *Server) pullShared(w http.ResponseWriter, r *http.Request, entity string,
+	pull func(ctx context.Context, memberID, scope string, cursor syncx.Cursor, limit int) (*syncservice.PullResponse, error)) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := logging.Sampled(ctx)
+
+	scope, err := s.sharedCursorEntity(r, entity)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load task list memberships")
+		writeError(w, r, 500, "pull failed")
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
+	cur, ok := parseCursor(w, r, scope, r.URL.Query().Get("cursor"))
+	if !ok {
+		return
+	}
+
+	resp, err := pull(ctx, userID, scope, cur, limit)
+	if err != nil {
+		writeError(w, r, 500, "pull failed")
+		return
+	}
+	metrics.ObservePull(metrics.TransportHTTP, "shared_"+entity, len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("upsert_count", len(resp.Upserts)).
+		Int("delete_count", len(resp.Deletes)).
+		Bool("has_next_page", resp.HasMore).
+		Msg("sync_pull_completed: shared " + entity)
+
+	writeJSON(w, 200, resp)
+}
+
+// PullSharedTaskLists handles GET /v1/sync/shared/task_lists/pull?cursor=<opaque>&limit=<int>
+// Returns the lists shared with the caller (not their own), including
+// tombstones for shared lists the owner deleted.
+func (s *Server) PullSharedTaskLists(w http.ResponseWriter, r *http.Request) {
+	s.pullShared(w, r, "task_lists", s.SharingSvc.PullSharedTaskLists)
+}
+
+// PullSharedTasks handles GET /v1/sync/shared/tasks/pull?cursor=<opaque>&limit=<int>
+// Returns the tasks in lists shared with the caller.
+func (s *Server) PullSharedTasks(w http.ResponseWriter, r *http.Request) {
+	s.pullShared(w, r, "tasks", s.SharingSvc.PullSharedTasks)
+}
+
+// PullSharedNotes handles GET /v1/sync/shared/notes/pull?cursor=<opaque>&limit=<int>
+// Returns the notes shared with the caller.
+func (s *Server) PullSharedNotes(w http.ResponseWriter, r *http.Request) {
+	s.pullShared(w, r, "notes", s.SharingSvc.PullSharedNotes)
+}
+
+// PullSharedChats handles GET /v1/sync/shared/chats/pull?cursor=<opaque>&limit=<int>
+func (s *Server) PullSharedChats(w http.ResponseWriter, r *http.Request) {
+	s.pullShared(w, r, "chats", s.SharingSvc.PullSharedChats)
+}
+
+// PullSharedChatMessages handles GET /v1/sync/shared/chat_messages/pull?cursor=<opaque>&limit=<int>
+// Returns the messages in chats shared with the caller.
+func (s *Server) PullSharedChatMessages(w http.ResponseWriter, r *http.Request) {
+	s.pullShared(w, r, "chat_messages", s.SharingSvc.PullSharedChatMessages)
+}
+
+// PushSharedTasks handles POST /v1/sync/shared/tasks/push
+// Each task's taskListUid must name a list shared with the caller for
+// editing; tasks are stored as the list owner's with the usual LWW rules.
+// Items the caller may not write get an ack with code "forbidden".
+func (s *Server) PushSharedTasks(w http.ResponseWriter, r *http.Request) {
+	s.pushShared(w, r, "tasks", s.SharingSvc.PushSharedTaskItemJSON)
+}
+
+// PushSharedNotes handles POST /v1/sync/shared/notes/push
+// Edits to notes shared with the caller for editing; see PushSharedTasks.
+func (s *Server) PushSharedNotes(w http.ResponseWriter, r *http.Request) {
+	s.pushShared(w, r, "notes", s.SharingSvc.PushSharedNoteItemJSON)
+}
+
+// PushSharedChats handles POST /v1/sync/shared/chats/push
+func (s *Server) PushSharedChats(w http.ResponseWriter, r *http.Request) {
+	s.pushShared(w, r, "chats", s.SharingSvc.PushSharedChatItemJSON)
+}
+
+// PushSharedChatMessages handles POST /v1/sync/shared/chat_messages/push
+// Messages go into chats shared with the caller for editing.
+func (s *Server) PushSharedChatMessages(w http.ResponseWriter, r *http.Request) {
+	s.pushShared(w, r, "chat_messages", s.SharingSvc.PushSharedChatMessageItemJSON)
+}
+
+// pushShared serves POST /v1/sync/shared/{entity}/push with the service's
+// per-item push, in one transaction like the per-entity push handlers
+func (s *Server) pushShared(w http.ResponseWriter, r *http.Request, entity string,
+	push func(ctx context.Context, tx pgx.Tx, memberID string, payload json.RawMessage) syncservice.PushAck) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := logging.Sampled(ctx)
+
+	items, err := newPushDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes))
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	defer items.Release()
+
+	acks := getAcks()
+	defer func() { putAcks(acks) }()
+
+	ctx, span := telemetry.StartPushSpan(ctx, "shared_"+entity, 0)
+	defer span.End()
+	rec := metrics.StartStreamingPush(metrics.TransportHTTP, "shared_"+entity)
+	defer rec.Finish()
+
+	// Stage the first items before taking a connection: a push that fits in
+	// one stage holds no transaction while its body arrives
+	batch := items.Next()
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
+		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	for ; len(batch) > 0; batch = items.Next() {
+		for _, item := range batch {
+			svcAck := push(ctx, tx, userID, item)
+			rec.Ack(svcAck.Error, svcAck.Applied)
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
+	}
+	if err := items.Err(); err != nil {
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	telemetry.SetBatchSize(span, len(acks))
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
+		writeJSON(w, 500, []pushAck{{Error: "commit failed"}})
+		return
+	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(acks), rec.Conflicts())
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("success_count", len(acks)).
+		Msg("sync_push_completed: shared " + entity)
+
+	writeJSON(w, 200, acks)
+}
@@ -0,0 +1,252 @@
+package httpapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestSharedTaskLists(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	owner := env.Client(t, "list-owner")
+	member := env.Client(t, "list-member")
+	outsider := env.Client(t, "outsider")
+
+	const (
+		list     = "10000000-0000-4000-8000-000000000001"
+		inList   = "20000000-0000-4000-8000-000000000001"
+		unlisted = "20000000-0000-4000-8000-000000000002"
+		added    = "20000000-0000-4000-8000-000000000003"
+		smuggle  = "20000000-0000-4000-8000-000000000004"
+		private  = "10000000-0000-4000-8000-000000000002"
+	)
+	task := func(uid, listUID, ts string) map[string]any {
+		item := note(uid, "task", ts)
+		if listUID != "" {
+			item["taskListUid"] = listUID
+		}
+		return item
+	}
+	if _, err := owner.Push(ctx, "task_lists", []map[string]any{note(list, "Groceries", "2025-11-03T10:00:00Z")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := owner.Push(ctx, "tasks", []map[string]any{
+		task(inList, list, "2025-11-03T10:00:01Z"),
+		task(unlisted, "", "2025-11-03T10:00:02Z"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pullShared := func(entity, cursor string) (*syncclient.PullResponse, error) {
+		return member.Pull(ctx, "shared/"+entity, cursor, 0)
+	}
+	pushShared := func(item map[string]any) syncclient.PushAck {
+		t.Helper()
+		acks, err := member.Push(ctx, "shared/tasks", []map[string]any{item})
+		if err != nil || len(acks) != 1 {
+			t.Fatalf("shared push: %v, %v", acks, err)
+		}
+		return acks[0]
+	}
+
+	if resp, err := pullShared("tasks", ""); err != nil || len(resp.Upserts) != 0 {
+		t.Fatalf("pulled %v (err %v) before sharing, want nothing", resp, err)
+	}
+	members := "/v1/task_lists/" + list + "/members"
+	if code := call(t, env, owner, http.MethodPost, members, `{"sub":"list-member","permission":"view"}`, nil); code != http.StatusOK {
+		t.Fatalf("share status = %d", code)
+	}
+	var shared struct {
+		Lists []struct {
+			ListUID    string `json:"listUid"`
+			Permission string `json:"permission"`
+		} `json:"lists"`
+	}
+	call(t, env, member, http.MethodGet, "/v1/shared/task_lists", "", &shared)
+	if len(shared.Lists) != 1 || shared.Lists[0].ListUID != list || shared.Lists[0].Permission != "view" {
+		t.Errorf("shared lists = %+v", shared.Lists)
+	}
+
+	// Members see the list and its tasks, nothing else of the owner's
+	lists, err := pullShared("task_lists", "")
+	if err != nil || len(lists.Upserts) != 1 {
+		t.Fatalf("pulled lists %v (err %v), want the shared list", lists, err)
+	}
+	tasks, err := pullShared("tasks", "")
+	if err != nil || len(tasks.Upserts) != 1 || tasks.Upserts[0]["uid"] != inList {
+		t.Fatalf("pulled tasks %v (err %v), want only the task in the list", tasks, err)
+	}
+	if ack := pushShared(task(added, list, "2025-11-03T10:00:03Z")); ack.Code != "forbidden" {
+		t.Errorf("push with view permission: ack %+v, want forbidden", ack)
+	}
+
+	// Changing the membership invalidates shared cursors
+	if code := call(t, env, owner, http.MethodPost, members, `{"sub":"list-member","permission":"edit"}`, nil); code != http.StatusOK {
+		t.Fatalf("upgrade status = %d", code)
+	}
+	var se *syncclient.StatusError
+	if _, err := pullShared("tasks", *tasks.NextCursor); !errors.As(err, &se) || se.Status != http.StatusBadRequest {
+		t.Errorf("pull with a pre-change cursor: err = %v, want 400", err)
+	}
+
+	// Editors push into the list; the tasks are the owner's
+	if ack := pushShared(task(added, list, "2025-11-03T10:00:03Z")); ack.Error != "" {
+		t.Errorf("push with edit permission: ack %+v", ack)
+	}
+	if ack := pushShared(task(unlisted, list, "2025-11-03T10:00:04Z")); ack.Code != "forbidden" {
+		t.Errorf("moving an unshared task into the list: ack %+v, want forbidden", ack)
+	}
+	if ack := pushShared(task(added, "", "2025-11-03T10:00:05Z")); ack.Code != "forbidden" {
+		t.Errorf("push without a list: ack %+v, want forbidden", ack)
+	}
+	// The stored taskListUid is checked, not a case variant of the key
+	// (encoding/json matches struct fields case-insensitively, last key wins)
+	if _, err := owner.Push(ctx, "task_lists", []map[string]any{note(private, "Private", "2025-11-03T10:00:06Z")}); err != nil {
+		t.Fatal(err)
+	}
+	smuggled := task(smuggle, private, "2025-11-03T10:00:07Z")
+	smuggled["tasklistuid"] = list
+	if ack := pushShared(smuggled); ack.Code != "forbidden" {
+		t.Errorf("push into an unshared list under a case-variant key: ack %+v, want forbidden", ack)
+	}
+	ownTasks, err := owner.Pull(ctx, "tasks", "", 0)
+	if err != nil || len(ownTasks.Upserts) != 3 {
+		t.Errorf("owner pulled %v (err %v), want 3 tasks", ownTasks, err)
+	}
+	if mine, err := member.Pull(ctx, "tasks", "", 0); err != nil || len(mine.Upserts) != 0 {
+		t.Errorf("member's own tasks = %v (err %v), want none", mine, err)
+	}
+
+	// Only the owner manages members; removed members stop seeing the list
+	if code := call(t, env, outsider, http.MethodGet, members, "", nil); code != http.StatusNotFound {
+		t.Errorf("outsider listing members = %d, want 404", code)
+	}
+	var listed struct {
+		Members []struct {
+			UserID string `json:"userId"`
+		} `json:"members"`
+	}
+	call(t, env, owner, http.MethodGet, members, "", &listed)
+	if len(listed.Members) != 1 {
+		t.Fatalf("members = %+v, want one", listed.Members)
+	}
+	if code := call(t, env, owner, http.MethodDelete, members+"/"+listed.Members[0].UserID, "", nil); code != http.StatusNoContent {
+		t.Errorf("remove member status = %d", code)
+	}
+	if resp, err := pullShared("tasks", ""); err != nil || len(resp.Upserts) != 0 {
+		t.Errorf("pulled %v (err %v) after removal, want nothing", resp, err)
+	}
+}
+
+func TestItemGrants(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	owner := env.Client(t, "item-owner")
+	grantee := env.Client(t, "item-grantee")
+
+	const (
+		shared   = "30000000-0000-4000-8000-000000000001"
+		private  = "30000000-0000-4000-8000-000000000002"
+		chat     = "40000000-0000-4000-8000-000000000001"
+		message  = "50000000-0000-4000-8000-000000000001"
+		reply    = "50000000-0000-4000-8000-000000000002"
+		stranger = "40000000-0000-4000-8000-000000000002"
+	)
+	msg := func(uid, chatUID, ts string) map[string]any {
+		item := note(uid, "message", ts)
+		item["chatUid"] = chatUID
+		return item
+	}
+	pushOne(t, owner, note(shared, "shared", "2025-11-03T10:00:00Z"))
+	pushOne(t, owner, note(private, "private", "2025-11-03T10:00:01Z"))
+	if _, err := owner.Push(ctx, "chats", []map[string]any{note(chat, "chat", "2025-11-03T10:00:02Z")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := owner.Push(ctx, "chat_messages", []map[string]any{msg(message, chat, "2025-11-03T10:00:03Z")}); err != nil {
+		t.Fatal(err)
+	}
+	pushShared := func(entity string, item map[string]any) syncclient.PushAck {
+		t.Helper()
+		acks, err := grantee.Push(ctx, "shared/"+entity, []map[string]any{item})
+		if err != nil || len(acks) != 1 {
+			t.Fatalf("shared push: %v, %v", acks, err)
+		}
+		return acks[0]
+	}
+
+	if code := call(t, env, owner, http.MethodPost, "/v1/notes/"+shared+"/grants", `{"sub":"item-grantee"}`, nil); code != http.StatusOK {
+		t.Fatalf("share note status = %d", code)
+	}
+	if code := call(t, env, owner, http.MethodPost, "/v1/chats/"+chat+"/grants", `{"sub":"item-grantee","permission":"edit"}`, nil); code != http.StatusOK {
+		t.Fatalf("share chat status = %d", code)
+	}
+	if code := call(t, env, grantee, http.MethodPost, "/v1/notes/"+private+"/grants", `{"sub":"item-owner"}`, nil); code != http.StatusNotFound {
+		t.Errorf("granting someone else's note = %d, want 404", code)
+	}
+
+	// Grantees pull exactly the granted items, and a chat grant covers its messages
+	notes, err := grantee.Pull(ctx, "shared/notes", "", 0)
+	if err != nil || len(notes.Upserts) != 1 || notes.Upserts[0]["uid"] != shared {
+		t.Fatalf("pulled notes %v (err %v), want only the shared note", notes, err)
+	}
+	if chats, err := grantee.Pull(ctx, "shared/chats", "", 0); err != nil || len(chats.Upserts) != 1 {
+		t.Errorf("pulled chats %v (err %v), want the shared chat", chats, err)
+	}
+	if msgs, err := grantee.Pull(ctx, "shared/chat_messages", "", 0); err != nil || len(msgs.Upserts) != 1 {
+		t.Errorf("pulled messages %v (err %v), want the chat's message", msgs, err)
+	}
+
+	// View grants are read-only; edit grants write the owner's rows
+	if ack := pushShared("notes", note(shared, "edited", "2025-11-03T10:01:00Z")); ack.Code != "forbidden" {
+		t.Errorf("push with view grant: ack %+v, want forbidden", ack)
+	}
+	if ack := pushShared("notes", note(private, "edited", "2025-11-03T10:01:00Z")); ack.Code != "forbidden" {
+		t.Errorf("push to an ungranted note: ack %+v, want forbidden", ack)
+	}
+	if ack := pushShared("chat_messages", msg(reply, chat, "2025-11-03T10:01:01Z")); ack.Error != "" {
+		t.Errorf("reply with edit grant: ack %+v", ack)
+	}
+	if ack := pushShared("chat_messages", msg(reply, stranger, "2025-11-03T10:01:02Z")); ack.Code != "forbidden" {
+		t.Errorf("moving a message out of the chat: ack %+v, want forbidden", ack)
+	}
+	if own, err := owner.Pull(ctx, "chat_messages", "", 0); err != nil || len(own.Upserts) != 2 {
+		t.Errorf("owner pulled %v (err %v), want 2 messages", own, err)
+	}
+
+	// Changing grants invalidates shared cursors; leaving drops the item
+	if code := call(t, env, grantee, http.MethodDelete, "/v1/shared/notes/"+shared, "", nil); code != http.StatusNoContent {
+		t.Fatalf("leave status = %d", code)
+	}
+	var se *syncclient.StatusError
+	if _, err := grantee.Pull(ctx, "shared/notes", *notes.NextCursor, 0); !errors.As(err, &se) || se.Status != http.StatusBadRequest {
+		t.Errorf("pull with a pre-change cursor: err = %v, want 400", err)
+	}
+	if resp, err := grantee.Pull(ctx, "shared/notes", "", 0); err != nil || len(resp.Upserts) != 0 {
+		t.Errorf("pulled %v (err %v) after leaving, want nothing", resp, err)
+	}
+	var granted struct {
+		Items []struct {
+			UID        string `json:"uid"`
+			Permission string `json:"permission"`
+		} `json:"items"`
+	}
+	call(t, env, grantee, http.MethodGet, "/v1/shared/chats", "", &granted)
+	if len(granted.Items) != 1 || granted.Items[0].UID != chat || granted.Items[0].Permission != "edit" {
+		t.Errorf("shared chats = %+v", granted.Items)
+	}
+
+	// Edit grants don't cover items the owner deleted: no resurrecting them
+	if code := call(t, env, owner, http.MethodDelete, "/v1/chats/"+chat, "", nil); code != http.StatusOK {
+		t.Fatalf("delete chat status = %d", code)
+	}
+	if ack := pushShared("chats", note(chat, "revived", "2025-11-03T10:02:00Z")); ack.Code != "forbidden" {
+		t.Errorf("push to a deleted chat: ack %+v, want forbidden", ack)
+	}
+	if ack := pushShared("chat_messages", msg(reply, chat, "2025-11-03T10:02:01Z")); ack.Code != "forbidden" {
+		t.Errorf("message into a deleted chat: ack %+v, want forbidden", ack)
+	}
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/slack"
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateSlackConnection handles POST /v1/integrations/slack
+// Mirrors a chat to the Slack channel behind an incoming webhook URL; with
+// ingestReplies (and channelId), messages posted in the channel are appended
+// to the chat.
+func (s *Server) CreateSlackConnection(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Slack == nil {
+		writeError(w, r, http.StatusNotFound, "slack integration disabled")
+		return
+	}
+
+	var req slack.ConnectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.IngestReplies && s.Slack.SigningSecret == "" {
+		writeError(w, r, http.StatusBadRequest, "ingesting replies is not configured on this server")
+		return
+	}
+
+	conn, err := s.Slack.Create(r.Context(), userID, req)
+	var verr *slack.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, slack.ErrExists):
+		writeError(w, r, http.StatusConflict, "chat already connected to slack")
+		return
+	case errors.Is(err, slack.ErrLimit):
+		writeError(w, r, http.StatusConflict, "slack connection limit reached")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to create slack connection")
+		writeError(w, r, http.StatusInternalServerError, "failed to create slack connection")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("connectionId", conn.ID).Str("chatUid", conn.ChatUID).Msg("slack connection created")
+	writeJSON(w, http.StatusCreated, conn)
+}
+
+// ListSlackConnections handles GET /v1/integrations/slack
+func (s *Server) ListSlackConnections(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Slack == nil {
+		writeError(w, r, http.StatusNotFound, "slack integration disabled")
+		return
+	}
+
+	conns, err := s.Slack.List(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to list slack connections")
+		writeError(w, r, http.StatusInternalServerError, "failed to list slack connections")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": conns})
+}
+
+// DeleteSlackConnection handles DELETE /v1/integrations/slack/{id}
+func (s *Server) DeleteSlackConnection(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Slack == nil {
+		writeError(w, r, http.StatusNotFound, "slack integration disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.Slack.Delete(r.Context(), userID, id)
+	if errors.Is(err, slack.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "slack connection not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("connectionId", id).Msg("Failed to delete slack connection")
+		writeError(w, r, http.StatusInternalServerError, "failed to delete slack connection")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("connectionId", id).Msg("slack connection deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSlackDeliveries handles GET /v1/integrations/slack/{id}/deliveries?limit=<n>
+// Mirrored messages are webhook deliveries; this is the connection's delivery log.
+func (s *Server) ListSlackDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Slack == nil || s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "slack integration disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := parseLimit(r.URL.Query().Get("limit"), 50, 200)
+	deliveries, err := s.Webhooks.Deliveries(r.Context(), userID, id, limit)
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "slack connection not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("connectionId", id).Msg("Failed to list slack deliveries")
+		writeError(w, r, http.StatusInternalServerError, "failed to list deliveries")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": deliveries})
+}
+
+// SlackEvents handles POST /v1/integrations/slack/events (Slack Events API)
+// Unauthenticated: requests are verified with the Slack app's signing secret.
+// Errors return 500 so Slack retries; ingestion is idempotent per message.
+func (s *Server) SlackEvents(w http.ResponseWriter, r *http.Request) {
+	if s.Slack == nil || s.Slack.SigningSecret == "" {
+		writeError(w, r, http.StatusNotFound, "slack events disabled")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	if !s.Slack.Verify(r.Header.Get(slack.HeaderTimestamp), r.Header.Get(slack.HeaderSignature), body, time.Now()) {
+		writeError(w, r, http.StatusUnauthorized, "invalid slack signature")
+		return
+	}
+
+	challenge, err := s.Slack.HandleEvent(r.Context(), body)
+	var verr *slack.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to ingest slack event")
+		writeError(w, r, http.StatusInternalServerError, "failed to process event")
+		return
+	}
+	if challenge != "" {
+		writeJSON(w, http.StatusOK, map[string]string{"challenge": challenge})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
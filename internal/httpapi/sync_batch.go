@@ -0,0 +1,215 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/jackc/pgx/v5"
+)
+
+// Batch sync limits: the body is decoded whole, unlike the streamed per-entity pushes
+const (
+	maxBatchItems = 1000     // Pushed items per request, over all entity types
+	maxBatchBytes = 16 << 20 // Request body
+)
+
+// batchPushOrder is the order a batch applies pushes in: parents first, so
+// items may reference lists, notes, tasks and chats pushed in the same request
+var batchPushOrder = []string{"task_list_categories", "task_lists", "notes", "tasks", "chats", "comments", "chat_messages", "pins"}
+
+// batchReq is the request body for POST /v1/sync/batch
+type batchReq struct {
+	Push  map[string][]json.RawMessage `json:"push"`  // Items per entity type
+	Pull  map[string]string            `json:"pull"`  // Cursor per entity type ("" for the first page)
+	Limit int                          `json:"limit"` // Page size of each pull (default 500, max 1000)
+}
+
+// batchResp is the response body for POST /v1/sync/batch
+type batchResp struct {
+	Push map[string][]pushAck                 `json:"push"`
+	Pull map[string]*syncservice.PullResponse `json:"pull"`
+}
+
+// batchPusher applies one entity type's items in tx, returning an ack per item
+type batchPusher func(ctx context.Context, tx pgx.Tx, userID string, items []json.RawMessage) []syncservice.PushAck
+
+// eachItem adapts a single-item push to a batchPusher
+func eachItem(push func(context.Context, pgx.Tx, string, json.RawMessage) syncservice.PushAck) batchPusher {
+	return func(ctx context.Context, tx pgx.Tx, userID string, items []json.RawMessage) []syncservice.PushAck {
+		acks := make([]syncservice.PushAck, len(items))
+		for i, item := range items {
+			acks[i] = push(ctx, tx, userID, item)
+		}
+		return acks
+	}
+}
+
+// pushers maps each sync entity type to its service's push
+// Comments and chat messages validate their parents once per batch.
+func (s *Server) pushers() map[string]batchPusher {
+	return map[string]batchPusher{
+		"notes":                eachItem(s.NoteSvc.PushNoteItemJSON),
+		"tasks":                eachItem(s.TaskSvc.PushTaskItemJSON),
+		"comments":             s.CommentSvc.PushCommentBatchJSON,
+		"chats":                eachItem(s.ChatSvc.PushChatItemJSON),
+		"chat_messages":        s.ChatMessageSvc.PushChatMessageBatchJSON,
+		"task_lists":           eachItem(s.TaskListSvc.PushTaskListItemJSON),
+		"task_list_categories": eachItem(s.TaskListCategorySvc.PushTaskListCategoryItemJSON),
+		"pins":                 eachItem(s.PinSvc.PushPinItemJSON),
+	}
+}
+
+// SyncBatch handles POST /v1/sync/batch
+// Body: {"push": {"notes": [...], "comments": [...]}, "pull": {"notes": "<cursor>", "tasks": ""}, "limit": 500}
+// A whole sync cycle in one round trip: every push is applied in one
+// transaction (parents first, see batchPushOrder), then the pulls run
+// concurrently as in GET /v1/sync/pull and include the batch's own changes.
+// Acks and pages are keyed by entity type; item errors are reported in acks
+// as on the per-entity push endpoints, and a failed request writes nothing.
+func (s *Server) SyncBatch(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := logging.Sampled(ctx)
+
+	var req batchReq
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch body exceeds %d bytes", maxBatchBytes))
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	// Validate everything before writing: a bad cursor mustn't follow committed pushes
+	pushers := s.pushers()
+	total := 0
+	for entity, items := range req.Push {
+		if _, ok := pushers[entity]; !ok {
+			writeError(w, r, http.StatusBadRequest, "unknown entity: "+entity)
+			return
+		}
+		total += len(items)
+	}
+	if total > maxBatchItems {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("at most %d items can be pushed per batch", maxBatchItems))
+		return
+	}
+	pullers := s.pullers()
+	limit := parseLimit(strconv.Itoa(req.Limit), 500, 1000)
+	pulls := make([]syncservice.EntityPull, 0, len(req.Pull))
+	for entity, raw := range req.Pull {
+		if _, ok := pullers[entity]; !ok {
+			writeError(w, r, http.StatusBadRequest, "unknown entity: "+entity)
+			return
+		}
+		cur, ok := parseCursor(w, r, entity, raw)
+		if !ok {
+			return
+		}
+		pulls = append(pulls, syncservice.EntityPull{Entity: entity, Cursor: cur, Limit: limit})
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("item_count", total).
+		Int("pull_count", len(pulls)).
+		Msg("sync_batch_started")
+
+	resp := batchResp{Push: map[string][]pushAck{}, Pull: map[string]*syncservice.PullResponse{}}
+	if total > 0 {
+		acks, err := s.pushBatch(ctx, userID, req.Push, pushers)
+		if err != nil {
+			logger.Error().Err(err).Msg("batch push failed")
+			errreport.CaptureError(ctx, err)
+			writeError(w, r, http.StatusInternalServerError, "push failed")
+			return
+		}
+		resp.Push = acks
+	}
+
+	if len(pulls) > 0 {
+		pages, err := syncservice.PullEntities(ctx, userID, pulls, pullers)
+		if err != nil {
+			// The pushes are committed; their acks are lost, but repeating them is idempotent
+			logger.Error().Err(err).Msg("batch pull failed")
+			errreport.CaptureError(ctx, err)
+			writeError(w, r, http.StatusInternalServerError, "pull failed")
+			return
+		}
+		pulled := 0
+		for entity, page := range pages {
+			metrics.ObservePull(metrics.TransportHTTP, entity, len(page.Upserts), len(page.Deletes))
+			pulled += len(page.Upserts) + len(page.Deletes)
+		}
+		s.Analytics.RecordPull(userID, pulled)
+		resp.Pull = pages
+	}
+
+	logger.Info().Str("user_id", userID).Msg("sync_batch_completed")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// pushBatch applies the pushed items of every entity type in one transaction
+func (s *Server) pushBatch(ctx context.Context, userID string, push map[string][]json.RawMessage, pushers map[string]batchPusher) (map[string][]pushAck, error) {
+	total := 0
+	for _, items := range push {
+		total += len(items)
+	}
+	ctx, span := telemetry.StartPushSpan(ctx, "batch", total)
+	defer span.End()
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	out := make(map[string][]pushAck, len(push))
+	var recs []*metrics.PushRecorder
+	for _, entity := range batchPushOrder {
+		items := push[entity]
+		if len(items) == 0 {
+			continue
+		}
+		rec := metrics.StartPush(metrics.TransportHTTP, entity, len(items))
+		defer rec.Finish()
+		recs = append(recs, rec)
+
+		acks := make([]pushAck, 0, len(items))
+		for _, svcAck := range pushers[entity](ctx, tx, userID, items) {
+			rec.Ack(svcAck.Error, svcAck.Applied)
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
+		out[entity] = acks
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	conflicts := 0
+	for _, rec := range recs {
+		rec.Commit()
+		conflicts += rec.Conflicts()
+	}
+	s.Analytics.RecordPush(userID, total, conflicts)
+	return out, nil
+}
@@ -0,0 +1,21 @@
+package httpapi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBatchPushOrderCoversSyncEntities(t *testing.T) {
+	pushers := (&Server{}).pushers()
+	if len(batchPushOrder) != len(syncEntities) || len(pushers) != len(syncEntities) {
+		t.Fatalf("batchPushOrder has %d entities, pushers %d; want the %d sync entities", len(batchPushOrder), len(pushers), len(syncEntities))
+	}
+	for _, entity := range syncEntities {
+		if !slices.Contains(batchPushOrder, entity) {
+			t.Errorf("%s missing from batchPushOrder", entity)
+		}
+		if pushers[entity] == nil {
+			t.Errorf("%s has no pusher", entity)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestSyncBatch(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "batch-user")
+	ctx := context.Background()
+
+	const (
+		first   = "20000000-0000-4000-8000-000000000001"
+		second  = "20000000-0000-4000-8000-000000000002"
+		comment = "20000000-0000-4000-8000-000000000003"
+	)
+	pushOne(t, c, note(first, "first", "2025-11-03T10:00:00Z"))
+
+	// The comment's parent is pushed in the same batch, listed after it
+	body, _ := json.Marshal(map[string]any{
+		"push": map[string]any{
+			"comments": []map[string]any{{
+				"uid":        comment,
+				"parentType": "note",
+				"parentUid":  second,
+				"content":    "batched",
+				"updatedTs":  "2025-11-03T10:00:02Z",
+				"sync":       map[string]any{"version": float64(1)},
+			}},
+			"notes": []map[string]any{note(second, "second", "2025-11-03T10:00:01Z")},
+		},
+		"pull": map[string]string{"notes": "", "comments": ""},
+	})
+	var res struct {
+		Push map[string][]syncclient.PushAck `json:"push"`
+		Pull map[string]struct {
+			Upserts []map[string]any `json:"upserts"`
+			HasMore bool             `json:"hasMore"`
+		} `json:"pull"`
+	}
+	if code := call(t, env, c, http.MethodPost, "/v1/sync/batch", string(body), &res); code != http.StatusOK {
+		t.Fatalf("batch status = %d", code)
+	}
+	for _, entity := range []string{"notes", "comments"} {
+		if acks := res.Push[entity]; len(acks) != 1 || acks[0].Error != "" || acks[0].Version != 1 {
+			t.Errorf("%s acks = %+v", entity, acks)
+		}
+	}
+	if got := len(res.Pull["notes"].Upserts); got != 2 {
+		t.Errorf("pulled %d notes, want 2 (including the batch's own)", got)
+	}
+	if got := len(res.Pull["comments"].Upserts); got != 1 {
+		t.Errorf("pulled %d comments, want 1", got)
+	}
+
+	// Invalid requests write nothing
+	for _, bad := range []string{
+		`{"push":{"notes":[` + mustJSON(t, note("20000000-0000-4000-8000-000000000004", "x", "2025-11-03T10:00:03Z")) + `]},"pull":{"notes":"garbage"}}`,
+		`{"push":{"widgets":[{}]}}`,
+		`{"pull":{"widgets":""}}`,
+	} {
+		if code := call(t, env, c, http.MethodPost, "/v1/sync/batch", bad, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", bad, code)
+		}
+	}
+	resp, err := c.Pull(ctx, "notes", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Upserts) != 2 {
+		t.Errorf("after rejected batches: %d notes, want 2", len(resp.Upserts))
+	}
+}
@@ -1,13 +1,13 @@
 package httpapi
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
 )
 
 // PushChatMessages handles POST /v1/sync/chat_messages/push
@@ -16,47 +16,76 @@ import (
 func (s *Server) PushChatMessages(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	// Use contextual logger with correlation ID
-	logger := log.Ctx(ctx)
+	// Use contextual logger with correlation ID (info logs sampled when enabled)
+	logger := logging.Sampled(ctx)
 
 	logger.Info().Str("user_id", userID).Str("entity_type", "chat_messages").Msg("sync_push_started")
 
-	var req pushReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// Items are decoded and applied in bounded stages rather than buffered whole
+	items, err := newPushDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes))
+	if err != nil {
 		logger.Warn().Err(err).Msg("invalid push request body")
-		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
+		writePushBodyError(w, err)
 		return
 	}
+	defer items.Release()
+
+	acks := getAcks()
+	defer func() { putAcks(acks) }()
 
-	acks := make([]pushAck, 0, len(req.Items))
+	// Trace the batch as one span; per-query DB spans nest under it
+	// (the batch size is only known once the body has been read)
+	ctx, span := telemetry.StartPushSpan(ctx, "chat_messages", 0)
+	defer span.End()
+	rec := metrics.StartStreamingPush(metrics.TransportHTTP, "chat_messages")
+	defer rec.Finish()
+
+	// Stage the first items before taking a connection: a push that fits in
+	// one stage holds no transaction while its body arrives
+	batch := items.Next()
 
 	// Use transaction for atomicity (all-or-nothing per batch)
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
 		return
 	}
 	defer tx.Rollback(ctx)
 
-	for _, item := range req.Items {
-		// Call the refactored service layer
-		svcAck := s.ChatMessageSvc.PushChatMessageItem(ctx, tx, userID, item)
-
-		// Convert service PushAck to HTTP pushAck
-		acks = append(acks, pushAck{
-			UID:       svcAck.UID,
-			Version:   svcAck.Version,
-			UpdatedAt: svcAck.UpdatedAt,
-			Error:     svcAck.Error,
-		})
+	for ; len(batch) > 0; batch = items.Next() {
+		// Parents are validated once per stage, not once per item
+		for _, svcAck := range s.ChatMessageSvc.PushChatMessageBatchJSON(ctx, tx, userID, batch) {
+			rec.Ack(svcAck.Error, svcAck.Applied)
+
+			// Convert service PushAck to HTTP pushAck
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
 	}
+	if err := items.Err(); err != nil {
+		// The deferred rollback discards the items applied so far
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	telemetry.SetBatchSize(span, len(acks))
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "commit failed"}})
 		return
 	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(acks), rec.Conflicts())
 
 	logger.Info().
 		Str("user_id", userID).
@@ -71,15 +100,14 @@ func (s *Server) PushChatMessages(w http.ResponseWriter, r *http.Request) {
 func (s *Server) PullChatMessages(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	// Use contextual logger with correlation ID
-	logger := log.Ctx(ctx)
+	// Use contextual logger with correlation ID (info logs sampled when enabled)
+	logger := logging.Sampled(ctx)
 
 	// Parse query params
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "chat_messages", r.URL.Query().Get("cursor"))
 	if !ok {
-		// No cursor = start from beginning (epoch)
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 
 	logger.Info().
@@ -94,17 +122,15 @@ func (s *Server) PullChatMessages(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, 500, "pull failed")
 		return
 	}
+	metrics.ObservePull(metrics.TransportHTTP, "chat_messages", len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	logger.Info().
 		Str("user_id", userID).
 		Int("upsert_count", len(resp.Upserts)).
 		Int("delete_count", len(resp.Deletes)).
-		Bool("has_next_page", resp.NextCursor != nil).
+		Bool("has_next_page", resp.HasMore).
 		Msg("sync_pull_completed: chat_messages")
 
-	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
-	})
+	writeJSON(w, 200, resp)
 }
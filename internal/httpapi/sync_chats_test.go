@@ -244,7 +244,7 @@ func TestPullChats_Integration(t *testing.T) {
 		},
 		{
 			name:       "pull with cursor (page 2)",
-			query:      "?limit=1&cursor=MTczMDYzMTYwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY",
+			query:      "?limit=1&cursor=" + signedCursor(session, "chats", 1730631600000, "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
 			wantStatus: 200,
 			checkResp: func(t *testing.T, resp pullResp) {
 				// Should get the second chat (or none if cursor is past it)
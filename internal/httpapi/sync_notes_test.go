@@ -9,6 +9,7 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -244,7 +245,7 @@ func TestPullNotes_Integration(t *testing.T) {
 		},
 		{
 			name:       "pull with cursor (page 2)",
-			query:      "?limit=1&cursor=MTczMDYzMTYwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY",
+			query:      "?limit=1&cursor=" + signedCursor(session, "notes", 1730631600000, "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
 			wantStatus: 200,
 			checkResp: func(t *testing.T, resp pullResp) {
 				// Should get the second note (or none if cursor is past it)
@@ -406,3 +407,66 @@ func TestSoftDelete_Integration(t *testing.T) {
 		t.Errorf("Wrong note in deletes: %v", pullResp.Deletes[0])
 	}
 }
+
+func TestServerTimestamps_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	defer syncx.SetServerTimestamps(syncx.ServerTimestamps())
+	syncx.SetServerTimestamps(true)
+
+	push := func(uid, updatedTs string) pushAck {
+		t.Helper()
+		item := map[string]any{"uid": uid, "title": "t", "updatedTs": updatedTs, "sync": map[string]any{"version": float64(1)}}
+		rec := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session)
+		var acks []pushAck
+		if err := json.NewDecoder(rec.Body).Decode(&acks); err != nil || len(acks) != 1 {
+			t.Fatalf("push: %d %v", rec.Code, err)
+		}
+		if acks[0].Error != "" {
+			t.Fatalf("push error: %s", acks[0].Error)
+		}
+		return acks[0]
+	}
+
+	// A device with a fast clock pushes first; a device an hour behind still
+	// lands after it
+	first := push("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "2025-11-03T10:00:00Z")
+	if first.UpdatedAt != "2025-11-03T10:00:00Z" {
+		t.Errorf("first ack updatedAt = %s, want the client's timestamp", first.UpdatedAt)
+	}
+	second := push("d2e8c8ed-b2c3-4d4e-8f9a-8b7c6d5e4f3a", "2025-11-03T09:00:00Z")
+	if second.UpdatedAt != "2025-11-03T10:00:00.001Z" {
+		t.Errorf("second ack updatedAt = %s, want the server stamp", second.UpdatedAt)
+	}
+
+	// A pull resumed after the first item sees the second, stamped to match the ack
+	firstMs, _ := syncx.ParseTimeToMs(first.UpdatedAt)
+	cursor := signedCursor(session, "notes", firstMs, first.UID)
+	rec := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull?cursor="+cursor, nil, session)
+	var resp pullResp
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("pull: %d %v", rec.Code, err)
+	}
+	if len(resp.Upserts) != 1 || resp.Upserts[0]["updatedTs"] != second.UpdatedAt {
+		t.Errorf("pull after first item = %v, want the second item stamped %s", resp.Upserts, second.UpdatedAt)
+	}
+
+	// An older edit of an existing item is applied, not dropped by LWW
+	again := push("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "2025-11-03T09:30:00Z")
+	if again.Version != 2 || again.UpdatedAt != "2025-11-03T10:00:00.002Z" {
+		t.Errorf("re-push ack = %+v, want version 2 stamped after the second item", again)
+	}
+}
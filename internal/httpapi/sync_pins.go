@@ -0,0 +1,136 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+)
+
+// PushPins handles POST /v1/sync/pins/push
+// Implements Last-Write-Wins (LWW) conflict resolution with idempotent pushes
+func (s *Server) PushPins(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	// Use contextual logger with correlation ID (info logs sampled when enabled)
+	logger := logging.Sampled(ctx)
+
+	logger.Info().Str("user_id", userID).Str("entity_type", "pins").Msg("sync_push_started")
+
+	// Items are decoded and applied in bounded stages rather than buffered whole
+	items, err := newPushDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes))
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	defer items.Release()
+
+	acks := getAcks()
+	defer func() { putAcks(acks) }()
+
+	// Trace the batch as one span; per-query DB spans nest under it
+	// (the batch size is only known once the body has been read)
+	ctx, span := telemetry.StartPushSpan(ctx, "pins", 0)
+	defer span.End()
+	rec := metrics.StartStreamingPush(metrics.TransportHTTP, "pins")
+	defer rec.Finish()
+
+	// Stage the first items before taking a connection: a push that fits in
+	// one stage holds no transaction while its body arrives
+	batch := items.Next()
+
+	// Use transaction for atomicity (all-or-nothing per batch)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
+		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	for ; len(batch) > 0; batch = items.Next() {
+		for _, item := range batch {
+			// Call the refactored service layer
+			svcAck := s.PinSvc.PushPinItemJSON(ctx, tx, userID, item)
+			rec.Ack(svcAck.Error, svcAck.Applied)
+
+			// Convert service PushAck to HTTP pushAck
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
+	}
+	if err := items.Err(); err != nil {
+		// The deferred rollback discards the items applied so far
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	telemetry.SetBatchSize(span, len(acks))
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
+		writeJSON(w, 500, []pushAck{{Error: "commit failed"}})
+		return
+	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(acks), rec.Conflicts())
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("success_count", len(acks)).
+		Msg("sync_push_completed: pins")
+
+	writeJSON(w, 200, acks)
+}
+
+// PullPins handles GET /v1/sync/pins/pull?cursor=<opaque>&limit=<int>
+// Returns upserts and deletes in deterministic order using cursor-based pagination
+func (s *Server) PullPins(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	// Use contextual logger with correlation ID (info logs sampled when enabled)
+	logger := logging.Sampled(ctx)
+
+	// Parse query params
+	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
+	cur, ok := parseCursor(w, r, "pins", r.URL.Query().Get("cursor"))
+	if !ok {
+		return
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("limit", limit).
+		Str("cursor", r.URL.Query().Get("cursor")).
+		Msg("sync_pull_started: pins")
+
+	// Call the refactored service layer
+	resp, err := s.PinSvc.PullPins(ctx, userID, cur, limit)
+	if err != nil {
+		writeError(w, r, 500, "pull failed")
+		return
+	}
+	metrics.ObservePull(metrics.TransportHTTP, "pins", len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("upsert_count", len(resp.Upserts)).
+		Int("delete_count", len(resp.Deletes)).
+		Bool("has_next_page", resp.HasMore).
+		Msg("sync_pull_completed: pins")
+
+	writeJSON(w, 200, resp)
+}
@@ -0,0 +1,61 @@
+package httpapi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestPins(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	c := env.Client(t, "pins-user")
+
+	const (
+		target = "80000000-0000-4000-8000-000000000001"
+		pinUID = "90000000-0000-4000-8000-000000000001"
+	)
+	pin := func(targetUID, ts string, deleted bool) map[string]any {
+		item := note(pinUID, "", ts)
+		delete(item, "title")
+		item["targetType"] = "note"
+		item["targetUid"] = targetUID
+		item["sortOrder"] = float64(1)
+		if deleted {
+			item["sync"] = map[string]any{"version": float64(1), "isDeleted": true}
+		}
+		return item
+	}
+	pushPin := func(item map[string]any) syncclient.PushAck {
+		t.Helper()
+		acks, err := c.Push(ctx, "pins", []map[string]any{item})
+		if err != nil || len(acks) != 1 {
+			t.Fatalf("push: %v, %v", acks, err)
+		}
+		return acks[0]
+	}
+
+	if ack := pushPin(pin(target, "2025-11-03T10:00:00Z", false)); !strings.Contains(ack.Error, "not found") {
+		t.Errorf("pinning a missing note: ack %+v, want target not found", ack)
+	}
+	pushOne(t, c, note(target, "favorite", "2025-11-03T10:00:01Z"))
+	if ack := pushPin(pin(target, "2025-11-03T10:00:02Z", false)); ack.Error != "" {
+		t.Fatalf("pin: ack %+v", ack)
+	}
+	pins, err := c.Pull(ctx, "pins", "", 0)
+	if err != nil || len(pins.Upserts) != 1 || pins.Upserts[0]["targetUid"] != target {
+		t.Fatalf("pulled %v (err %v), want the pin", pins, err)
+	}
+
+	// Unpinning succeeds even once the target is gone
+	pushOne(t, c, map[string]any{"uid": target, "updatedTs": "2025-11-03T10:00:03Z", "sync": map[string]any{"version": float64(1), "isDeleted": true}})
+	if ack := pushPin(pin(target, "2025-11-03T10:00:04Z", true)); ack.Error != "" {
+		t.Errorf("unpin after deleting the note: ack %+v", ack)
+	}
+	if resp, err := c.Pull(ctx, "pins", *pins.NextCursor, 0); err != nil || len(resp.Deletes) != 1 {
+		t.Errorf("pulled %v (err %v), want the unpin", resp, err)
+	}
+}
@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+// syncEntities lists the sync entity types served by the combined pull
+var syncEntities = []string{"notes", "tasks", "comments", "chats", "chat_messages", "task_lists", "task_list_categories", "pins"}
+
+// multiPullResp is the response body for GET /v1/sync/pull
+type multiPullResp struct {
+	Entities map[string]*syncservice.PullResponse `json:"entities"`
+}
+
+// pullers maps each sync entity type to its service's pull
+func (s *Server) pullers() map[string]syncservice.PullFunc {
+	return map[string]syncservice.PullFunc{
+		"notes":                s.NoteSvc.PullNotes,
+		"tasks":                s.TaskSvc.PullTasks,
+		"comments":             s.CommentSvc.PullComments,
+		"chats":                s.ChatSvc.PullChats,
+		"chat_messages":        s.ChatMessageSvc.PullChatMessages,
+		"task_lists":           s.TaskListSvc.PullTaskLists,
+		"task_list_categories": s.TaskListCategorySvc.PullTaskListCategories,
+		"pins":                 s.PinSvc.PullPins,
+	}
+}
+
+// PullAll handles GET /v1/sync/pull?entities=<list>&limit=<int>&cursor.<entity>=<opaque>
+// Pulls one page of several entity types in a single request, running the
+// per-entity queries concurrently. entities defaults to every type; limit
+// applies to each entity. Clients follow each entity's nextCursor as they would
+// on the per-entity pull endpoints.
+func (s *Server) PullAll(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := logging.Sampled(ctx)
+	q := r.URL.Query()
+
+	entities := syncEntities
+	if list := q.Get("entities"); list != "" {
+		entities = strings.Split(list, ",")
+	}
+	pullers := s.pullers()
+	limit := parseLimit(q.Get("limit"), 500, 1000)
+	pulls := make([]syncservice.EntityPull, 0, len(entities))
+	seen := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		entity = strings.TrimSpace(entity)
+		if _, ok := pullers[entity]; !ok {
+			writeError(w, r, 400, "unknown entity: "+entity)
+			return
+		}
+		if seen[entity] {
+			continue
+		}
+		seen[entity] = true
+		cur, ok := parseCursor(w, r, entity, q.Get("cursor."+entity))
+		if !ok {
+			return
+		}
+		pulls = append(pulls, syncservice.EntityPull{Entity: entity, Cursor: cur, Limit: limit})
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("limit", limit).
+		Int("entity_count", len(pulls)).
+		Msg("sync_pull_started: multi")
+
+	pages, err := syncservice.PullEntities(ctx, userID, pulls, pullers)
+	if err != nil {
+		logger.Error().Err(err).Msg("multi-entity pull failed")
+		errreport.CaptureError(ctx, err)
+		writeError(w, r, 500, "pull failed")
+		return
+	}
+
+	total := 0
+	for entity, page := range pages {
+		metrics.ObservePull(metrics.TransportHTTP, entity, len(page.Upserts), len(page.Deletes))
+		total += len(page.Upserts) + len(page.Deletes)
+	}
+	s.Analytics.RecordPull(userID, total)
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("item_count", total).
+		Msg("sync_pull_completed: multi")
+
+	writeJSON(w, 200, multiPullResp{Entities: pages})
+}
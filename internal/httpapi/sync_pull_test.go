@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestPullAll_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+	if _, err := pool.Exec(context.Background(), "DELETE FROM task"); err != nil {
+		t.Fatalf("Failed to clean task table: %v", err)
+	}
+
+	srv := &Server{
+		DB:                  pool,
+		RateLimitConfig:     DefaultRateLimitConfig,
+		NoteSvc:             syncservice.NewNoteService(pool),
+		TaskSvc:             syncservice.NewTaskService(pool),
+		CommentSvc:          syncservice.NewCommentService(pool),
+		ChatSvc:             syncservice.NewChatService(pool),
+		ChatMessageSvc:      syncservice.NewChatMessageService(pool),
+		TaskListSvc:         syncservice.NewTaskListService(pool),
+		TaskListCategorySvc: syncservice.NewTaskListCategoryService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{
+		Items: []map[string]any{
+			{"uid": "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "title": "Note 1", "updatedTs": "2025-11-03T10:00:00Z"},
+			{"uid": "a1b2c3d4-e5f6-7890-abcd-ef1234567890", "title": "Note 2", "updatedTs": "2025-11-03T10:01:00Z"},
+		},
+	}, session)
+	makeRequestWithSession(t, router, "POST", "/v1/sync/tasks/push", pushReq{
+		Items: []map[string]any{
+			{"uid": "b2c3d4e5-f6a7-4890-bcde-f12345678901", "title": "Task 1", "updatedTs": "2025-11-03T10:02:00Z"},
+		},
+	}, session)
+
+	pull := func(query string) (int, map[string]pullResp) {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "GET", "/v1/sync/pull"+query, nil, session)
+		var resp struct {
+			Entities map[string]pullResp `json:"entities"`
+		}
+		if w.Code == 200 {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return w.Code, resp.Entities
+	}
+
+	// Every entity by default
+	code, pages := pull("")
+	if code != 200 || len(pages) != len(syncEntities) {
+		t.Fatalf("status %d, %d entities", code, len(pages))
+	}
+	if got := len(pages["notes"].Upserts); got != 2 {
+		t.Errorf("notes upserts = %d, want 2", got)
+	}
+	if got := len(pages["tasks"].Upserts); got != 1 {
+		t.Errorf("tasks upserts = %d, want 1", got)
+	}
+	if got := len(pages["chats"].Upserts); got != 0 {
+		t.Errorf("chats upserts = %d, want 0", got)
+	}
+
+	// Per-entity cursors: notes resume after the last page, tasks start over
+	code, pages = pull("?entities=notes,tasks&cursor.notes=" + url.QueryEscape(*pages["notes"].NextCursor))
+	if code != 200 || len(pages) != 2 {
+		t.Fatalf("status %d, %d entities", code, len(pages))
+	}
+	if got := len(pages["notes"].Upserts); got != 0 {
+		t.Errorf("notes upserts after cursor = %d, want 0", got)
+	}
+	if got := len(pages["tasks"].Upserts); got != 1 {
+		t.Errorf("tasks upserts = %d, want 1", got)
+	}
+
+	if code, _ := pull("?entities=notes,widgets"); code != 400 {
+		t.Errorf("unknown entity status = %d, want 400", code)
+	}
+}
@@ -1,13 +1,13 @@
 package httpapi
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/erauner12/toolbridge-api/internal/errreport"
+	"github.com/erauner12/toolbridge-api/internal/logging"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
 )
 
 // ============================================================================
@@ -18,42 +18,72 @@ import (
 func (s *Server) PushTaskLists(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	logger.Info().Str("user_id", userID).Str("entity_type", "task_lists").Msg("sync_push_started")
 
-	var req pushReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// Items are decoded and applied in bounded stages rather than buffered whole
+	items, err := newPushDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes))
+	if err != nil {
 		logger.Warn().Err(err).Msg("invalid push request body")
-		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
+		writePushBodyError(w, err)
 		return
 	}
+	defer items.Release()
+
+	acks := getAcks()
+	defer func() { putAcks(acks) }()
 
-	acks := make([]pushAck, 0, len(req.Items))
+	// Trace the batch as one span; per-query DB spans nest under it
+	// (the batch size is only known once the body has been read)
+	ctx, span := telemetry.StartPushSpan(ctx, "task_lists", 0)
+	defer span.End()
+	rec := metrics.StartStreamingPush(metrics.TransportHTTP, "task_lists")
+	defer rec.Finish()
+
+	// Stage the first items before taking a connection: a push that fits in
+	// one stage holds no transaction while its body arrives
+	batch := items.Next()
 
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
 		return
 	}
 	defer tx.Rollback(ctx)
 
-	for _, item := range req.Items {
-		svcAck := s.TaskListSvc.PushTaskListItem(ctx, tx, userID, item)
-		acks = append(acks, pushAck{
-			UID:       svcAck.UID,
-			Version:   svcAck.Version,
-			UpdatedAt: svcAck.UpdatedAt,
-			Error:     svcAck.Error,
-		})
+	for ; len(batch) > 0; batch = items.Next() {
+		for _, item := range batch {
+			svcAck := s.TaskListSvc.PushTaskListItemJSON(ctx, tx, userID, item)
+			rec.Ack(svcAck.Error, svcAck.Applied)
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
 	}
+	if err := items.Err(); err != nil {
+		// The deferred rollback discards the items applied so far
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	telemetry.SetBatchSize(span, len(acks))
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "commit failed"}})
 		return
 	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(acks), rec.Conflicts())
 
 	logger.Info().
 		Str("user_id", userID).
@@ -67,12 +97,12 @@ func (s *Server) PushTaskLists(w http.ResponseWriter, r *http.Request) {
 func (s *Server) PullTaskLists(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "task_lists", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 
 	logger.Info().
@@ -86,19 +116,17 @@ func (s *Server) PullTaskLists(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, 500, "pull failed")
 		return
 	}
+	metrics.ObservePull(metrics.TransportHTTP, "task_lists", len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	logger.Info().
 		Str("user_id", userID).
 		Int("upsert_count", len(resp.Upserts)).
 		Int("delete_count", len(resp.Deletes)).
-		Bool("has_next_page", resp.NextCursor != nil).
+		Bool("has_next_page", resp.HasMore).
 		Msg("sync_pull_completed: task_lists")
 
-	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
-	})
+	writeJSON(w, 200, resp)
 }
 
 // ============================================================================
@@ -109,42 +137,72 @@ func (s *Server) PullTaskLists(w http.ResponseWriter, r *http.Request) {
 func (s *Server) PushTaskListCategories(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	logger.Info().Str("user_id", userID).Str("entity_type", "task_list_categories").Msg("sync_push_started")
 
-	var req pushReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// Items are decoded and applied in bounded stages rather than buffered whole
+	items, err := newPushDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes))
+	if err != nil {
 		logger.Warn().Err(err).Msg("invalid push request body")
-		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
+		writePushBodyError(w, err)
 		return
 	}
+	defer items.Release()
+
+	acks := getAcks()
+	defer func() { putAcks(acks) }()
 
-	acks := make([]pushAck, 0, len(req.Items))
+	// Trace the batch as one span; per-query DB spans nest under it
+	// (the batch size is only known once the body has been read)
+	ctx, span := telemetry.StartPushSpan(ctx, "task_list_categories", 0)
+	defer span.End()
+	rec := metrics.StartStreamingPush(metrics.TransportHTTP, "task_list_categories")
+	defer rec.Finish()
+
+	// Stage the first items before taking a connection: a push that fits in
+	// one stage holds no transaction while its body arrives
+	batch := items.Next()
 
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
 		return
 	}
 	defer tx.Rollback(ctx)
 
-	for _, item := range req.Items {
-		svcAck := s.TaskListCategorySvc.PushTaskListCategoryItem(ctx, tx, userID, item)
-		acks = append(acks, pushAck{
-			UID:       svcAck.UID,
-			Version:   svcAck.Version,
-			UpdatedAt: svcAck.UpdatedAt,
-			Error:     svcAck.Error,
-		})
+	for ; len(batch) > 0; batch = items.Next() {
+		for _, item := range batch {
+			svcAck := s.TaskListCategorySvc.PushTaskListCategoryItemJSON(ctx, tx, userID, item)
+			rec.Ack(svcAck.Error, svcAck.Applied)
+			acks = append(acks, pushAck{
+				UID:       svcAck.UID,
+				Version:   svcAck.Version,
+				UpdatedAt: svcAck.UpdatedAt,
+				Error:     svcAck.Error,
+				Code:      svcAck.Code,
+				Warning:   svcAck.Warning,
+			})
+		}
 	}
+	if err := items.Err(); err != nil {
+		// The deferred rollback discards the items applied so far
+		logger.Warn().Err(err).Msg("invalid push request body")
+		writePushBodyError(w, err)
+		return
+	}
+	telemetry.SetBatchSize(span, len(acks))
 
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error().Err(err).Msg("failed to commit transaction")
+		errreport.CaptureError(ctx, err)
 		writeJSON(w, 500, []pushAck{{Error: "commit failed"}})
 		return
 	}
+	rec.Commit()
+	s.Analytics.RecordPush(userID, len(acks), rec.Conflicts())
 
 	logger.Info().
 		Str("user_id", userID).
@@ -158,12 +216,12 @@ func (s *Server) PushTaskListCategories(w http.ResponseWriter, r *http.Request)
 func (s *Server) PullTaskListCategories(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
+	logger := logging.Sampled(ctx)
 
 	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	cur, ok := parseCursor(w, r, "task_list_categories", r.URL.Query().Get("cursor"))
 	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+		return
 	}
 
 	logger.Info().
@@ -177,17 +235,15 @@ func (s *Server) PullTaskListCategories(w http.ResponseWriter, r *http.Request)
 		writeError(w, r, 500, "pull failed")
 		return
 	}
+	metrics.ObservePull(metrics.TransportHTTP, "task_list_categories", len(resp.Upserts), len(resp.Deletes))
+	s.Analytics.RecordPull(userID, len(resp.Upserts)+len(resp.Deletes))
 
 	logger.Info().
 		Str("user_id", userID).
 		Int("upsert_count", len(resp.Upserts)).
 		Int("delete_count", len(resp.Deletes)).
-		Bool("has_next_page", resp.NextCursor != nil).
+		Bool("has_next_page", resp.HasMore).
 		Msg("sync_pull_completed: task_list_categories")
 
-	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
-	})
+	writeJSON(w, 200, resp)
 }
@@ -230,7 +230,7 @@ func TestPullTasks_Integration(t *testing.T) {
 		},
 		{
 			name:       "pull with cursor (page 2)",
-			query:      "?limit=1&cursor=MTczMDYzMTYwMDAwMHxkMWU5YjdkYy1iMmMzLTVkNGUtYWY5Zi04YjdjNmQ1ZTRmM2U",
+			query:      "?limit=1&cursor=" + signedCursor(session, "tasks", 1730631600000, "d1e9b7dc-b2c3-5d4e-af9f-8b7c6d5e4f3e"),
 			wantStatus: 200,
 			checkResp: func(t *testing.T, resp pullResp) {
 				// Should get the second task (or none if cursor is past it)
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// CloseEpochMismatch closes /v1/sync/ws when the account's epoch changes
+// (wipe or restore): the client resets like on a 409 epoch_mismatch, begins
+// a new session and reconnects. The close reason is "epoch_mismatch:<epoch>".
+const CloseEpochMismatch = 4409
+
+// wsPingInterval is how often /v1/sync/ws pings; a client that doesn't answer
+// (or send anything) for two intervals is disconnected
+const wsPingInterval = 30 * time.Second
+
+// wsMessage is one notification on /v1/sync/ws
+type wsMessage struct {
+	Type   string `json:"type"`             // ready or changed
+	Entity string `json:"entity,omitempty"` // changed: the sync name to pull (notes, tasks, ...)
+}
+
+// SyncWebSocket handles GET /v1/sync/ws (WebSocket upgrade)
+// Sends {"type":"ready"} once subscribed, then {"type":"changed","entity":"tasks"}
+// whenever that entity type changed for the caller's account (coalesced; may
+// include the caller's own pushes). Clients pull the entity in response; pull
+// everything once after ready to catch up. Close codes: CloseEpochMismatch,
+// 1001 on server shutdown.
+func (s *Server) SyncWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.UserID(ctx)
+	epoch, _ := strconv.Atoi(r.Header.Get("X-Sync-Epoch")) // Checked by EpochRequired
+
+	sub := s.Changes.Subscribe(userID)
+	defer sub.Close()
+
+	conn, err := websocket.Upgrade(w, r, 2*wsPingInterval)
+	if errors.Is(err, websocket.ErrNotWebSocket) {
+		writeError(w, r, http.StatusUpgradeRequired, err.Error())
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("websocket upgrade failed")
+		return
+	}
+	defer conn.Close(websocket.CloseNormal, "") // No-op after an explicit close
+	closeEpoch := func(current int) {
+		_ = conn.Close(CloseEpochMismatch, "epoch_mismatch:"+strconv.Itoa(current))
+	}
+
+	// An epoch bump between EpochRequired and Subscribe isn't notified to us
+	current, err := currentEpoch(ctx, s.DB, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to load epoch")
+		_ = conn.Close(websocket.CloseInternalError, "epoch load failed")
+		return
+	}
+	if current != epoch {
+		closeEpoch(current)
+		return
+	}
+
+	send := func(m wsMessage) bool {
+		b, _ := json.Marshal(m)
+		return conn.WriteText(b) == nil
+	}
+	if !send(wsMessage{Type: "ready"}) {
+		return
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-sub.Ready():
+			entities, newEpoch := sub.Take()
+			if newEpoch != 0 && newEpoch != epoch {
+				closeEpoch(newEpoch)
+				return
+			}
+			for _, entity := range entities {
+				if !send(wsMessage{Type: "changed", Entity: entity}) {
+					return
+				}
+			}
+		case <-ping.C:
+			if conn.Ping() != nil {
+				return
+			}
+		case <-sub.Done():
+			_ = conn.Close(websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-conn.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package httpapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+// wsDial opens /v1/sync/ws as c; the handshake response is returned unread
+func wsDial(t *testing.T, env *testutil.Env, c *syncclient.Client) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(env.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	session, epoch := c.Session()
+	fmt.Fprintf(conn, "GET /v1/sync/ws HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Authorization: Bearer %s\r\nX-Sync-Session: %s\r\nX-Sync-Epoch: %d\r\n\r\n", c.Token, session, epoch)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, br
+}
+
+// wsRead reads one (short, unmasked) server frame
+func wsRead(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var hdr [2]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, hdr[1]&0x7F)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return hdr[0] & 0x0F, payload
+}
+
+func TestSyncWebSocket(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "ws-user")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go env.Server.Changes.Run(ctx, 100*time.Millisecond)
+	go outbox.NewDispatcher(env.DB, changefeed.Publisher{}).Run(ctx, 50*time.Millisecond)
+	// Wait for the hub's LISTEN, so the socket's first messages are ours
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		var listening bool
+		if err := env.DB.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM pg_stat_activity WHERE query = 'LISTEN '||$1::text AND datname = current_database())
+		`, changefeed.Channel).Scan(&listening); err != nil {
+			t.Fatal(err)
+		}
+		if listening {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("change feed not listening")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if code := call(t, env, c, http.MethodGet, "/v1/sync/ws", "", nil); code != http.StatusUpgradeRequired {
+		t.Errorf("plain GET status = %d, want 426", code)
+	}
+
+	resp, br := wsDial(t, env, c)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d", resp.StatusCode)
+	}
+	if op, msg := wsRead(t, br); op != 0x1 || string(msg) != `{"type":"ready"}` {
+		t.Fatalf("first message = %x %s", op, msg)
+	}
+
+	pushOne(t, c, note("1a000000-0000-4000-8000-000000000001", "ws", "2025-11-03T10:00:00Z"))
+	if op, msg := wsRead(t, br); op != 0x1 || string(msg) != `{"type":"changed","entity":"notes"}` {
+		t.Errorf("after push = %x %s", op, msg)
+	}
+
+	// A wipe bumps the epoch: the socket closes with 4409 and the new epoch
+	epoch, err := c.Wipe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, msg := wsRead(t, br)
+	if op != 0x8 || len(msg) < 2 || binary.BigEndian.Uint16(msg) != 4409 || string(msg[2:]) != fmt.Sprintf("epoch_mismatch:%d", epoch) {
+		t.Errorf("after wipe = %x %v", op, msg)
+	}
+}
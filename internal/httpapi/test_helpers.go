@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"testing"
 
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -65,6 +68,13 @@ func createTestSession(t *testing.T, router http.Handler) TestSession {
 	}
 }
 
+// signedCursor returns a query-escaped cursor positioned at (ms, uid) in the
+// session user's listing of entity
+func signedCursor(session TestSession, entity string, ms int64, uid string) string {
+	cur := syncx.Cursor{Ms: ms, UID: uuid.MustParse(uid)}
+	return url.QueryEscape(syncx.SignCursor(cur, session.UserID, entity))
+}
+
 // makeRequestWithSession makes an HTTP request with X-Sync-Session and X-Sync-Epoch headers
 func makeRequestWithSession(t *testing.T, router http.Handler, method, path string, body interface{}, sessionOrID interface{}) *httptest.ResponseRecorder {
 	t.Helper()
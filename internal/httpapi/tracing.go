@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/telemetry"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span per request
+// Runs after CorrelationMiddleware, which continues (or generates) the W3C trace
+// context. Adds trace_id to the request logger so logs and traces can be joined,
+// and returns the server span's traceparent in the response headers.
+// The span is renamed to the matched chi route pattern once routing completes
+// (e.g. "GET /v1/notes/{uid}") to keep span names low-cardinality.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := telemetry.Tracer().Start(r.Context(), r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("correlation_id", GetCorrelationID(r.Context())),
+			),
+		)
+		defer span.End()
+
+		if traceID := telemetry.TraceID(ctx); traceID != "" {
+			logger := log.Ctx(ctx).With().Str("trace_id", traceID).Logger()
+			ctx = logger.WithContext(ctx)
+		}
+
+		telemetry.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				span.SetName(r.Method + " " + pattern)
+				span.SetAttributes(attribute.String("http.route", pattern))
+			}
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
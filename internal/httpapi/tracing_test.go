@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceparentPropagation(t *testing.T) {
+	handler := CorrelationMiddleware(TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	})))
+
+	t.Run("continues incoming trace", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/healthz", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req.Header.Set("tracestate", "vendor=value")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		got := w.Header().Get("traceparent")
+		if !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+			t.Errorf("traceparent = %q, want trace ID 4bf92f3577b34da6a3ce929d0e0e4736", got)
+		}
+		if ts := w.Header().Get("tracestate"); ts != "vendor=value" {
+			t.Errorf("tracestate = %q, want vendor=value", ts)
+		}
+	})
+
+	t.Run("generates trace when absent", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/healthz", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		got := w.Header().Get("traceparent")
+		parts := strings.Split(got, "-")
+		if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+			t.Errorf("traceparent = %q, want generated 00-<trace>-<span>-<flags>", got)
+		}
+	})
+
+	t.Run("invalid traceparent is replaced", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/healthz", nil)
+		req.Header.Set("traceparent", "not-a-traceparent")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		got := w.Header().Get("traceparent")
+		if got == "" || got == "not-a-traceparent" {
+			t.Errorf("traceparent = %q, want a freshly generated value", got)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// trashType reads the trash type from the path or ?type= and checks it
+func (s *Server) trashType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	typ := chi.URLParam(r, "type")
+	if typ == "" {
+		typ = r.URL.Query().Get("type")
+	}
+	if !s.Trash.Type(typ) {
+		writeError(w, r, http.StatusBadRequest, "type must be notes, tasks, comments, chats, chat_messages, task_lists or task_list_categories")
+		return "", false
+	}
+	return typ, true
+}
+
+// writeTrashError maps trash errors to HTTP responses
+func writeTrashError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	var me *syncservice.MutationError
+	switch {
+	case errors.Is(err, syncservice.ErrItemNotFound):
+		writeError(w, r, http.StatusNotFound, "item not found in the trash")
+	case errors.Is(err, syncservice.ErrNotInTrash):
+		writeError(w, r, http.StatusConflict, err.Error())
+	case errors.As(err, &me):
+		// e.g. a comment whose note is still deleted
+		writeError(w, r, http.StatusConflict, "cannot restore: "+me.Message)
+	default:
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to " + action)
+		writeError(w, r, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// ListTrash handles GET /v1/trash?type=notes&limit=<n>&cursor=<c>
+// Returns the caller's deleted items of one type that can still be restored,
+// most recently deleted first, with when the purge job will drop them.
+func (s *Server) ListTrash(w http.ResponseWriter, r *http.Request) {
+	typ, ok := s.trashType(w, r)
+	if !ok {
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 100, 500)
+	cur, ok := parseCursor(w, r, "trash:"+typ, r.URL.Query().Get("cursor"))
+	if !ok {
+		return
+	}
+
+	page, err := s.Trash.List(r.Context(), auth.UserID(r.Context()), typ, cur, limit)
+	if err != nil {
+		writeTrashError(w, r, err, "list trash")
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// RestoreTrashItem handles POST /v1/trash/{type}/{uid}/restore
+// Revives the item as a new version (it syncs back to every device).
+func (s *Server) RestoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	typ, ok := s.trashType(w, r)
+	if !ok {
+		return
+	}
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+
+	item, err := s.Trash.Restore(r.Context(), auth.UserID(r.Context()), typ, uid)
+	if err != nil {
+		writeTrashError(w, r, err, "restore item")
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// PurgeTrashItem handles DELETE /v1/trash/{type}/{uid}
+// Deletes the item's content for good. Its tombstone stays, so devices still
+// pull the delete.
+func (s *Server) PurgeTrashItem(w http.ResponseWriter, r *http.Request) {
+	typ, ok := s.trashType(w, r)
+	if !ok {
+		return
+	}
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid UID")
+		return
+	}
+
+	n, err := s.Trash.Purge(r.Context(), auth.UserID(r.Context()), typ, &uid)
+	if err != nil {
+		writeTrashError(w, r, err, "purge item")
+		return
+	}
+	if n == 0 {
+		writeTrashError(w, r, syncservice.ErrItemNotFound, "purge item")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EmptyTrash handles DELETE /v1/trash?type=notes
+// Purges every item in the caller's trash of that type.
+func (s *Server) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	typ, ok := s.trashType(w, r)
+	if !ok {
+		return
+	}
+
+	n, err := s.Trash.Purge(r.Context(), auth.UserID(r.Context()), typ, nil)
+	if err != nil {
+		writeTrashError(w, r, err, "empty trash")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"purged": n})
+}
@@ -0,0 +1,68 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func TestTrash(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "trash-user")
+
+	const uid = "c0000000-0000-4000-8000-000000000001"
+	pushOne(t, c, note(uid, "doomed", "2025-11-03T10:00:00Z"))
+	if code := call(t, env, c, http.MethodDelete, "/v1/notes/"+uid, "", nil); code != http.StatusOK {
+		t.Fatalf("delete status = %d", code)
+	}
+
+	var trash struct {
+		Items []struct {
+			UID       string         `json:"uid"`
+			ExpiresAt string         `json:"expiresAt"`
+			Payload   map[string]any `json:"payload"`
+		} `json:"items"`
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/trash?type=notes", "", &trash); code != http.StatusOK {
+		t.Fatalf("trash status = %d", code)
+	}
+	if len(trash.Items) != 1 || trash.Items[0].UID != uid || trash.Items[0].ExpiresAt == "" {
+		t.Fatalf("trash = %+v, want the deleted note", trash.Items)
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/trash?type=pins", "", nil); code != http.StatusBadRequest {
+		t.Errorf("unknown type status = %d, want 400", code)
+	}
+
+	// Restore revives it; it's then no longer in the trash
+	if code := call(t, env, c, http.MethodPost, "/v1/trash/notes/"+uid+"/restore", "", nil); code != http.StatusOK {
+		t.Fatalf("restore status = %d", code)
+	}
+	if code := call(t, env, c, http.MethodGet, "/v1/notes/"+uid, "", nil); code != http.StatusOK {
+		t.Errorf("restored note status = %d, want 200", code)
+	}
+	if code := call(t, env, c, http.MethodPost, "/v1/trash/notes/"+uid+"/restore", "", nil); code != http.StatusConflict {
+		t.Errorf("restoring a live note status = %d, want 409", code)
+	}
+
+	// Purging keeps a bare tombstone
+	call(t, env, c, http.MethodDelete, "/v1/notes/"+uid, "", nil)
+	if code := call(t, env, c, http.MethodDelete, "/v1/trash/notes/"+uid, "", nil); code != http.StatusNoContent {
+		t.Fatalf("purge status = %d", code)
+	}
+	call(t, env, c, http.MethodGet, "/v1/trash?type=notes", "", &trash)
+	if len(trash.Items) != 0 {
+		t.Errorf("trash after purge = %+v, want empty", trash.Items)
+	}
+	var tombstone struct {
+		DeletedAt *string        `json:"deletedAt"`
+		Payload   map[string]any `json:"payload"`
+	}
+	call(t, env, c, http.MethodGet, "/v1/notes/"+uid+"?includeDeleted=true", "", &tombstone)
+	if _, ok := tombstone.Payload["title"]; ok || tombstone.Payload["purged"] != true || tombstone.DeletedAt == nil {
+		t.Errorf("purged note = %+v, want a tombstone without content", tombstone)
+	}
+	if code := call(t, env, c, http.MethodPost, "/v1/trash/notes/"+uid+"/restore", "", nil); code != http.StatusNotFound {
+		t.Errorf("restoring a purged note status = %d, want 404", code)
+	}
+}
@@ -0,0 +1,163 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// createWebhookReq is the body for POST /v1/webhooks
+type createWebhookReq struct {
+	URL      string   `json:"url"`
+	Entities []string `json:"entities"` // Optional filter, e.g. ["task", "note"]; empty = all
+}
+
+// webhookID validates the {id} path param, writing a 404 if it isn't a UUID
+func webhookID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, r, http.StatusNotFound, "webhook not found")
+		return "", false
+	}
+	return id, true
+}
+
+// CreateWebhook handles POST /v1/webhooks
+// Registers an HTTPS callback for entity change events. The response includes
+// the signing secret; it is never returned again.
+func (s *Server) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "webhooks disabled")
+		return
+	}
+
+	var req createWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	sub, err := s.Webhooks.Create(r.Context(), userID, req.URL, req.Entities)
+	var verr *webhook.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, webhook.ErrLimit):
+		writeError(w, r, http.StatusConflict, "webhook subscription limit reached")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to create webhook")
+		writeError(w, r, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("webhookId", sub.ID).Msg("webhook created")
+	w.Header().Set("Location", "/v1/webhooks/"+sub.ID)
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+// ListWebhooks handles GET /v1/webhooks
+func (s *Server) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "webhooks disabled")
+		return
+	}
+
+	subs, err := s.Webhooks.List(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to list webhooks")
+		writeError(w, r, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": subs})
+}
+
+// GetWebhook handles GET /v1/webhooks/{id}
+func (s *Server) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "webhooks disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := s.Webhooks.Get(r.Context(), userID, id)
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("webhookId", id).Msg("Failed to load webhook")
+		writeError(w, r, http.StatusInternalServerError, "failed to load webhook")
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// DeleteWebhook handles DELETE /v1/webhooks/{id}
+// Pending deliveries for the subscription are dropped.
+func (s *Server) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "webhooks disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.Webhooks.Delete(r.Context(), userID, id)
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("webhookId", id).Msg("Failed to delete webhook")
+		writeError(w, r, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("webhookId", id).Msg("webhook deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /v1/webhooks/{id}/deliveries?limit=<n>
+// Returns the subscription's most recent deliveries (newest first) with their
+// status, attempt count and last response, for debugging a callback.
+func (s *Server) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Webhooks == nil {
+		writeError(w, r, http.StatusNotFound, "webhooks disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := parseLimit(r.URL.Query().Get("limit"), 50, 200)
+	deliveries, err := s.Webhooks.Deliveries(r.Context(), userID, id, limit)
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("webhookId", id).Msg("Failed to list webhook deliveries")
+		writeError(w, r, http.StatusInternalServerError, "failed to list deliveries")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": deliveries})
+}
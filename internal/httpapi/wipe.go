@@ -1,10 +1,13 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/changefeed"
+	"github.com/erauner12/toolbridge-api/internal/notify"
 	"github.com/rs/zerolog/log"
 )
 
@@ -56,11 +59,43 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	newEpoch, deleted, err := s.wipeUser(ctx, userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Invalidate all sessions for this user (outside transaction)
+	sessionsDeleted := sessionStore().DeleteUserSessions(userID)
+
+	log.Info().
+		Str("userId", userID).
+		Int("newEpoch", newEpoch).
+		Interface("deleted", deleted).
+		Int("sessionsInvalidated", sessionsDeleted).
+		Msg("Account wiped successfully")
+
+	// Return success response
+	writeJSON(w, http.StatusOK, wipeResponse{
+		Epoch:   newEpoch,
+		Deleted: deleted,
+	})
+}
+
+// wipeFailure names the wipe step that failed; unlike the underlying database
+// error (which is logged), it is safe to return to the client
+type wipeFailure string
+
+func (f wipeFailure) Error() string { return string(f) }
+
+// wipeUser bumps the user's epoch and deletes their synced data in one
+// transaction, returning the new epoch and per-table deletion counts
+// Sessions are left to the caller (they live outside the database).
+func (s *Server) wipeUser(ctx context.Context, userID string) (int, map[string]int, error) {
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to begin transaction")
-		writeError(w, r, http.StatusInternalServerError, "transaction begin failed")
-		return
+		return 0, nil, wipeFailure("transaction begin failed")
 	}
 	defer tx.Rollback(ctx)
 
@@ -79,14 +114,13 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to bump epoch")
-		writeError(w, r, http.StatusInternalServerError, "epoch update failed")
-		return
+		return 0, nil, wipeFailure("epoch update failed")
 	}
 
 	// Delete all entity rows for this user
 	// Order matters: delete children before parents (e.g., chat_message before chat)
 	deleted := make(map[string]int)
-	tables := []string{"chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
+	tables := []string{"pin", "chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
 
 	for _, table := range tables {
 		var count int
@@ -99,32 +133,58 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 
 		if err != nil {
 			log.Error().Err(err).Str("table", table).Str("userId", userID).Msg("Failed to delete rows")
-			writeError(w, r, http.StatusInternalServerError, "delete failed: "+table)
-			return
+			return 0, nil, wipeFailure("delete failed: " + table)
 		}
 		deleted[table] = count
 	}
 
+	// Clear the activity feed too (it would otherwise reference wiped entities)
+	if _, err := tx.Exec(ctx, `DELETE FROM activity_log WHERE owner_id = $1`, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to delete activity log")
+		return 0, nil, wipeFailure("delete failed: activity_log")
+	}
+
+	// The link index only describes the wiped items
+	if _, err := tx.Exec(ctx, `DELETE FROM link WHERE owner_id = $1`, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to delete links")
+		return 0, nil, wipeFailure("delete failed: link")
+	}
+
+	// Grants reference the wiped notes and chats (list memberships go with task_list)
+	if _, err := tx.Exec(ctx, `DELETE FROM item_grant WHERE owner_id = $1`, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to delete item grants")
+		return 0, nil, wipeFailure("delete failed: item_grant")
+	}
+
+	// Exports hold pre-wipe copies of the data
+	if _, err := tx.Exec(ctx, `DELETE FROM export_job WHERE owner_id = $1`, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to delete export jobs")
+		return 0, nil, wipeFailure("delete failed: export_job")
+	}
+
+	// Pending imports would repopulate the account after the wipe
+	if _, err := tx.Exec(ctx, `DELETE FROM import_job WHERE owner_id = $1`, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to delete import jobs")
+		return 0, nil, wipeFailure("delete failed: import_job")
+	}
+
+	// Tell the user by email (queued only if the wipe commits)
+	if err := s.Notify.Enqueue(ctx, tx, userID, notify.WipeMessage(userID, newEpoch, deleted)); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to queue wipe notification")
+		return 0, nil, wipeFailure("notification queue failed")
+	}
+
+	// Close the user's change notification sockets (sent only if the wipe commits)
+	if err := changefeed.NotifyEpoch(ctx, tx, userID, newEpoch); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to notify epoch change")
+		return 0, nil, wipeFailure("epoch notification failed")
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to commit wipe transaction")
-		writeError(w, r, http.StatusInternalServerError, "commit failed")
-		return
+		return 0, nil, wipeFailure("commit failed")
 	}
 
-	// Invalidate all sessions for this user (outside transaction)
-	sessionsDeleted := sessionStore.DeleteUserSessions(userID)
-
-	log.Info().
-		Str("userId", userID).
-		Int("newEpoch", newEpoch).
-		Interface("deleted", deleted).
-		Int("sessionsInvalidated", sessionsDeleted).
-		Msg("Account wiped successfully")
-
-	// Return success response
-	writeJSON(w, http.StatusOK, wipeResponse{
-		Epoch:   newEpoch,
-		Deleted: deleted,
-	})
+	return newEpoch, deleted, nil
 }
@@ -0,0 +1,235 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/zapier"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// APIKeyMiddleware authenticates /v1/zapier requests with a static API key
+// (X-API-Key header or "Authorization: Bearer tbk_...") instead of a JWT
+func (s *Server) APIKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Zapier == nil {
+			writeError(w, r, http.StatusNotFound, "api keys disabled")
+			return
+		}
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			key, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if key == "" {
+			writeError(w, r, http.StatusUnauthorized, "missing api key")
+			return
+		}
+
+		userID, sub, err := s.Zapier.Authenticate(r.Context(), key)
+		if errors.Is(err, zapier.ErrInvalidKey) {
+			writeError(w, r, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		if err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Msg("Failed to authenticate api key")
+			writeError(w, r, http.StatusInternalServerError, "server error")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), auth.CtxUserID, userID)
+		ctx = context.WithValue(ctx, auth.CtxSubject, sub)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CreateAPIKey handles POST /v1/api-keys
+// The key is only returned in this response.
+func (s *Server) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Zapier == nil {
+		writeError(w, r, http.StatusNotFound, "api keys disabled")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	key, err := s.Zapier.CreateKey(r.Context(), userID, req.Name)
+	var verr *zapier.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, zapier.ErrLimit):
+		writeError(w, r, http.StatusConflict, "api key limit reached")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to create api key")
+		writeError(w, r, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("keyId", key.ID).Msg("api key created")
+	writeJSON(w, http.StatusCreated, key)
+}
+
+// ListAPIKeys handles GET /v1/api-keys
+func (s *Server) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Zapier == nil {
+		writeError(w, r, http.StatusNotFound, "api keys disabled")
+		return
+	}
+
+	keys, err := s.Zapier.ListKeys(r.Context(), userID)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to list api keys")
+		writeError(w, r, http.StatusInternalServerError, "failed to list api keys")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": keys})
+}
+
+// RevokeAPIKey handles DELETE /v1/api-keys/{id}
+func (s *Server) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if s.Zapier == nil {
+		writeError(w, r, http.StatusNotFound, "api keys disabled")
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.Zapier.RevokeKey(r.Context(), userID, id)
+	if errors.Is(err, zapier.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "api key not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("keyId", id).Msg("Failed to revoke api key")
+		writeError(w, r, http.StatusInternalServerError, "failed to revoke api key")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("keyId", id).Msg("api key revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ZapierMe handles GET /v1/zapier/me (connection test for no-code platforms)
+func (s *Server) ZapierMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"id":  auth.UserID(r.Context()),
+		"sub": auth.Subject(r.Context()),
+	})
+}
+
+// parseSince parses the since query param: RFC 3339 or Unix milliseconds
+func parseSince(q string) (time.Time, bool) {
+	if q == "" {
+		return time.Time{}, true
+	}
+	if ms, err := strconv.ParseInt(q, 10, 64); err == nil {
+		return time.UnixMilli(ms), true
+	}
+	t, err := time.Parse(time.RFC3339, q)
+	return t, err == nil
+}
+
+// ZapierItems handles GET /v1/zapier/items/{entity}?since=<time>&limit=<n>
+// Returns a bare JSON array of flat items changed after since, newest first,
+// which is the shape polling triggers expect.
+func (s *Server) ZapierItems(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	since, ok := parseSince(r.URL.Query().Get("since"))
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "since must be RFC 3339 or Unix milliseconds")
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 50, 200)
+
+	items, err := s.Zapier.Poll(r.Context(), userID, chi.URLParam(r, "entity"), since, limit)
+	var verr *zapier.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to poll items")
+		writeError(w, r, http.StatusInternalServerError, "failed to list items")
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// SubscribeZapierHook handles POST /v1/zapier/hooks (REST hook subscribe)
+func (s *Server) SubscribeZapierHook(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+
+	var req struct {
+		Event     string `json:"event"`
+		TargetURL string `json:"targetUrl"`
+		HookURL   string `json:"target_url"` // Zapier's default field name
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.TargetURL == "" {
+		req.TargetURL = req.HookURL
+	}
+
+	hook, err := s.Zapier.Subscribe(r.Context(), userID, req.Event, req.TargetURL)
+	var verr *zapier.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeError(w, r, http.StatusBadRequest, verr.Msg)
+		return
+	case errors.Is(err, zapier.ErrLimit):
+		writeError(w, r, http.StatusConflict, "hook limit reached")
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("userId", userID).Msg("Failed to subscribe hook")
+		writeError(w, r, http.StatusInternalServerError, "failed to subscribe hook")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("hookId", hook.ID).Str("event", hook.Event).Msg("rest hook subscribed")
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+// UnsubscribeZapierHook handles DELETE /v1/zapier/hooks/{id} (REST hook unsubscribe)
+func (s *Server) UnsubscribeZapierHook(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.Zapier.Unsubscribe(r.Context(), userID, id)
+	if errors.Is(err, zapier.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "hook not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("hookId", id).Msg("Failed to unsubscribe hook")
+		writeError(w, r, http.StatusInternalServerError, "failed to unsubscribe hook")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().Str("userId", userID).Str("hookId", id).Msg("rest hook unsubscribed")
+	w.WriteHeader(http.StatusNoContent)
+}
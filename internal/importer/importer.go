@@ -0,0 +1,316 @@
+// Package importer imports exports from other task managers (Todoist, TickTick)
+// Uploads are queued as import jobs and applied by a background worker through
+// the same sync service calls the push endpoints use.
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Import sources
+const (
+	SourceTodoist  = "todoist"
+	SourceTickTick = "ticktick"
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// MaxInputBytes bounds an uploaded export
+const MaxInputBytes = 20 << 20
+
+// ErrNotFound is returned when a job doesn't exist (or belongs to another user)
+var ErrNotFound = errors.New("import job not found")
+
+// ErrInProgress is returned when the user already has a queued or running import
+var ErrInProgress = errors.New("import already in progress")
+
+// ValidationError describes an upload that can't be imported
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Parse maps an export from source onto toolbridge entities
+func Parse(source string, data []byte) (*Plan, error) {
+	switch source {
+	case SourceTodoist:
+		return parseTodoist(data)
+	case SourceTickTick:
+		return parseTickTick(data)
+	default:
+		return nil, &ValidationError{Msg: fmt.Sprintf("unsupported import source %q", source)}
+	}
+}
+
+// Job is an import job as returned by the status API
+type Job struct {
+	ID         string         `json:"id"`
+	Source     string         `json:"source"`
+	Status     string         `json:"status"`
+	Total      int            `json:"total"`     // Items to import
+	Processed  int            `json:"processed"` // Items pushed so far
+	Counts     map[string]int `json:"counts,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+}
+
+// Service enqueues and runs import jobs
+type Service struct {
+	DB        *pgxpool.Pool
+	Lists     *syncservice.TaskListService
+	Tasks     *syncservice.TaskService
+	Notes     *syncservice.NoteService
+	Comments  *syncservice.CommentService
+	BatchSize int           // Items per transaction (progress is reported per batch)
+	StaleJob  time.Duration // Running jobs older than this are requeued (worker crashed)
+}
+
+// NewService creates an import service
+func NewService(db *pgxpool.Pool) *Service {
+	return &Service{
+		DB:        db,
+		Lists:     syncservice.NewTaskListService(db),
+		Tasks:     syncservice.NewTaskService(db),
+		Notes:     syncservice.NewNoteService(db),
+		Comments:  syncservice.NewCommentService(db),
+		BatchSize: 200,
+		StaleJob:  15 * time.Minute,
+	}
+}
+
+// Enqueue validates an uploaded export and queues it for import
+// Returns a *ValidationError if data can't be parsed, or ErrInProgress if an
+// import is already queued or running.
+func (s *Service) Enqueue(ctx context.Context, userID, source string, data []byte) (*Job, error) {
+	plan, err := Parse(source, data)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Len() == 0 {
+		return nil, &ValidationError{Msg: "export contains nothing to import"}
+	}
+
+	job := Job{Source: source, Total: plan.Len()}
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Serialize per-user enqueues so the in-progress check can't race
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('import:' || $1))`, userID); err != nil {
+			return err
+		}
+
+		var active bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM import_job
+				WHERE owner_id = $1 AND status IN ('queued', 'running')
+			)
+		`, userID).Scan(&active); err != nil {
+			return err
+		}
+		if active {
+			return ErrInProgress
+		}
+
+		return tx.QueryRow(ctx, `
+			INSERT INTO import_job (owner_id, source, input, total) VALUES ($1, $2, $3, $4)
+			RETURNING id::text, status, created_at
+		`, userID, source, data, job.Total).Scan(&job.ID, &job.Status, &job.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Get returns the user's job by ID
+func (s *Service) Get(ctx context.Context, userID, id string) (*Job, error) {
+	var job Job
+	var errMsg *string
+	var counts []byte
+	err := s.DB.QueryRow(ctx, `
+		SELECT id::text, source, status, total, processed, counts, error, created_at, finished_at
+		FROM import_job
+		WHERE id = $1 AND owner_id = $2
+	`, id, userID).Scan(&job.ID, &job.Source, &job.Status, &job.Total, &job.Processed, &counts, &errMsg, &job.CreatedAt, &job.FinishedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errMsg != nil {
+		job.Error = *errMsg
+	}
+	if counts != nil {
+		if err := json.Unmarshal(counts, &job.Counts); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+// Run processes queued jobs until ctx is cancelled
+// Safe to run on every replica: jobs are claimed with FOR UPDATE SKIP LOCKED.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := s.processNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("import job processing failed")
+			}
+			if !processed || ctx.Err() != nil {
+				break
+			}
+		}
+		if _, err := s.DB.Exec(ctx, `
+			UPDATE import_job SET status = 'queued', started_at = NULL
+			WHERE status = 'running' AND started_at < now() - $1::interval
+		`, fmt.Sprintf("%d seconds", int64(s.StaleJob.Seconds()))); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("import job maintenance failed")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processNext claims and runs one queued job
+// Returns false when the queue is empty.
+func (s *Service) processNext(ctx context.Context) (bool, error) {
+	var id, userID, source string
+	var input []byte
+	var createdAt time.Time
+	err := s.DB.QueryRow(ctx, `
+		UPDATE import_job SET status = 'running', started_at = now(), processed = 0
+		WHERE id = (
+			SELECT id FROM import_job
+			WHERE status = 'queued'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id::text, owner_id::text, source, input, created_at
+	`).Scan(&id, &userID, &source, &input, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logger := log.With().Str("importId", id).Str("userId", userID).Str("source", source).Logger()
+	start := time.Now()
+
+	counts, err := s.apply(ctx, id, userID, source, input, createdAt)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down: hand the job back to the queue. Re-running is safe because
+		// item UIDs and timestamps are derived from the job.
+		_, uerr := s.DB.Exec(context.WithoutCancel(ctx), `
+			UPDATE import_job SET status = 'queued', started_at = NULL WHERE id = $1
+		`, id)
+		return true, uerr
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("import failed")
+		msg := "import failed"
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			msg = verr.Msg
+		}
+		_, uerr := s.DB.Exec(context.WithoutCancel(ctx), `
+			UPDATE import_job SET status = 'failed', error = $2, input = NULL, finished_at = now()
+			WHERE id = $1
+		`, id, msg)
+		return true, uerr
+	}
+
+	countsJSON, err := json.Marshal(counts)
+	if err != nil {
+		return true, err
+	}
+	if _, err := s.DB.Exec(ctx, `
+		UPDATE import_job SET status = 'succeeded', counts = $2, input = NULL, finished_at = now()
+		WHERE id = $1
+	`, id, countsJSON); err != nil {
+		return true, err
+	}
+
+	logger.Info().Interface("counts", counts).Dur("took", time.Since(start)).Msg("import finished")
+	return true, nil
+}
+
+// apply pushes a job's items in batches, recording progress with each batch
+// Items stamped with the job's creation time lose LWW against anything the
+// user has edited since, so a retried job never clobbers newer changes.
+// Returns entity -> items written; items the sync services reject are counted
+// under "skipped".
+func (s *Service) apply(ctx context.Context, id, userID, source string, input []byte, createdAt time.Time) (map[string]int, error) {
+	plan, err := Parse(source, input)
+	if err != nil {
+		return nil, err
+	}
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	items := plan.items(jobID, createdAt)
+
+	push := map[string]func(context.Context, pgx.Tx, string, map[string]any) syncservice.PushAck{
+		"task_list": s.Lists.PushTaskListItem,
+		"task":      s.Tasks.PushTaskItem,
+		"note":      s.Notes.PushNoteItem,
+		"comment":   s.Comments.PushCommentItem,
+	}
+	ctx = syncservice.WithChangeSource(ctx, syncservice.ChangeSource{DeviceID: "import:" + source})
+	counts := map[string]int{}
+	batchSize := max(s.BatchSize, 1)
+	for start := 0; start < len(items); start += batchSize {
+		end := min(start+batchSize, len(items))
+		batchCounts := map[string]int{}
+		err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+			for _, it := range items[start:end] {
+				if ack := push[it.entity](ctx, tx, userID, it.data); ack.Error != "" {
+					log.Debug().Str("importId", id).Str("entity", it.entity).Str("uid", ack.UID).Str("error", ack.Error).Msg("import item skipped")
+					batchCounts["skipped"]++
+					continue
+				}
+				batchCounts[it.entity]++
+			}
+			_, err := tx.Exec(ctx, `UPDATE import_job SET processed = $2 WHERE id = $1`, id, end)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range batchCounts {
+			counts[k] += v
+		}
+	}
+	return counts, nil
+}
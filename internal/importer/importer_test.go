@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const todoistCSV = `TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE
+task,Buy milk @errands @home,2%,1,1,Ann (1),,2025-12-15,en,UTC
+note,Whole milk only,,,,Ann (1),,,,
+task,Check fridge,,4,2,Ann (1),,every monday,en,UTC
+,,,,,,,,,
+task,Call plumber,,2,1,Ann (1),,,en,UTC
+`
+
+func TestParseTodoistCSV(t *testing.T) {
+	p, err := Parse(SourceTodoist, []byte(todoistCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Lists) != 1 || len(p.Tasks) != 3 || len(p.Comments) != 1 {
+		t.Fatalf("got %d lists, %d tasks, %d comments", len(p.Lists), len(p.Tasks), len(p.Comments))
+	}
+	milk, fridge := p.Tasks[0], p.Tasks[1]
+	if milk.Title != "Buy milk" || len(milk.Tags) != 2 || milk.Tags[0] != "errands" {
+		t.Errorf("labels not split from content: %+v", milk)
+	}
+	if milk.Priority != 3 || milk.Due != "2025-12-15" {
+		t.Errorf("priority/due = %d/%q, want 3/2025-12-15", milk.Priority, milk.Due)
+	}
+	if fridge.ParentKey != milk.Key || fridge.Due != "" || fridge.Priority != 0 {
+		t.Errorf("subtask not nested or recurring date kept: %+v", fridge)
+	}
+	if p.Tasks[2].ParentKey != "" {
+		t.Error("indent 1 task after a subtask should be top-level")
+	}
+	if p.Comments[0].TaskKey != milk.Key {
+		t.Error("note row should comment on the task above")
+	}
+}
+
+func TestParseTodoistZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"Inbox [2203306141].csv", "Work [2203306142].csv"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(todoistCSV))
+	}
+	zw.Close()
+
+	p, err := Parse(SourceTodoist, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Lists) != 2 || p.Lists[0].Name != "Inbox" || p.Lists[1].Name != "Work" {
+		t.Fatalf("lists = %+v", p.Lists)
+	}
+	if len(p.Tasks) != 6 || p.Tasks[3].ListKey != p.Lists[1].Key {
+		t.Error("tasks should belong to their file's project")
+	}
+}
+
+func TestParseTodoistJSON(t *testing.T) {
+	data := `{
+		"projects": [{"id": "p1", "name": "Inbox"}],
+		"labels": [{"id": 7, "name": "home"}],
+		"items": [
+			{"id": "i1", "project_id": "p1", "content": "Done thing", "priority": 4, "checked": true,
+			 "completed_at": "2025-12-01T10:00:00Z", "labels": ["errands"], "due": {"date": "2025-12-01T09:00:00Z"}},
+			{"id": 2, "project_id": "p1", "parent_id": "i1", "content": "Child", "priority": 1, "labels": [7]}
+		],
+		"notes": [{"id": "n1", "item_id": "i1", "content": "a comment"}]
+	}`
+	p, err := Parse(SourceTodoist, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, child := p.Tasks[0], p.Tasks[1]
+	if !done.Completed || done.CompletedAt.IsZero() || done.Priority != 3 || done.Due != "2025-12-01T09:00:00Z" {
+		t.Errorf("completion/priority/due not preserved: %+v", done)
+	}
+	if child.Key != "task:2" || child.ParentKey != done.Key || child.Tags[0] != "home" {
+		t.Errorf("numeric IDs or label IDs not mapped: %+v", child)
+	}
+}
+
+func TestParseTickTick(t *testing.T) {
+	data := `"Date: 2025-12-15+0000"
+"Version: 7.1"
+"Status:
+0 Normal
+1 Completed
+2 Archived"
+"Folder Name","List Name","Title","Kind","Tags","Content","Is Check list","Start Date","Due Date","Reminder","Repeat","Priority","Status","Created Time","Completed Time","Order","Timezone","Is All Day","Is Floating","Column Name","Column Order","View Mode","taskId","parentId"
+"","Work","Ship release","TEXT","work, urgent","notes","N","","2025-12-15T17:00:00+0000","","","5","2","2025-12-01T09:00:00+0000","2025-12-15T16:00:00+0000","1","UTC","false","false","","","list","11",""
+"","Work","Write changelog","TEXT","","","N","","2025-12-14T23:00:00+0000","","","0","0","2025-12-01T09:00:00+0000","","2","Europe/Berlin","true","false","","","list","12","11"
+"","Ideas","Garden plan","NOTE","home","Tomatoes","N","","","","","0","0","2025-12-01T09:00:00+0000","","3","UTC","false","false","","","list","13",""
+`
+	p, err := Parse(SourceTickTick, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Lists) != 1 || len(p.Tasks) != 2 || len(p.Notes) != 1 {
+		t.Fatalf("got %d lists, %d tasks, %d notes", len(p.Lists), len(p.Tasks), len(p.Notes))
+	}
+	ship, changelog := p.Tasks[0], p.Tasks[1]
+	if !ship.Completed || ship.CompletedAt.IsZero() || ship.Priority != 3 || ship.Due != "2025-12-15T17:00:00Z" {
+		t.Errorf("ship = %+v", ship)
+	}
+	if len(ship.Tags) != 2 || ship.Tags[1] != "urgent" {
+		t.Errorf("tags = %v", ship.Tags)
+	}
+	if changelog.Completed || changelog.Due != "2025-12-15" || changelog.ParentKey != ship.Key {
+		t.Errorf("all-day due should use the task's zone: %+v", changelog)
+	}
+}
+
+func TestParseRejectsGarbage(t *testing.T) {
+	for source, data := range map[string]string{
+		SourceTodoist:  "hello,world\n1,2\n",
+		SourceTickTick: "not,a,backup\n",
+		"wunderlist":   "{}",
+	} {
+		_, err := Parse(source, []byte(data))
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Parse(%s) = %v, want ValidationError", source, err)
+		}
+	}
+}
+
+func TestPlanItems(t *testing.T) {
+	p := &Plan{
+		Lists: []List{{Key: "l", Name: "Inbox"}},
+		Tasks: []Task{
+			{Key: "child", ParentKey: "parent", ListKey: "l", Title: "Child"},
+			{Key: "parent", ListKey: "l", Title: "Parent", Completed: true, Priority: 2},
+		},
+		Comments: []Comment{{Key: "c", TaskKey: "parent", Content: "hi"}, {Key: "orphan", TaskKey: "missing"}},
+	}
+	jobID := uuid.MustParse("0b6f8a4e-1d2c-4f3a-9b8e-7c6d5e4f3a2b")
+	ts := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	items := p.items(jobID, ts)
+	if len(items) != 4 {
+		t.Fatalf("got %d items, want list, 2 tasks and 1 comment", len(items))
+	}
+	parent, child := items[1].data, items[2].data
+	if parent["title"] != "Parent" || child["parentUid"] != parent["uid"] || child["taskListUid"] != items[0].data["uid"] {
+		t.Errorf("parents must precede children and be linked: %v / %v", parent, child)
+	}
+	if parent["status"] != "completed" || parent["done"] != true {
+		t.Errorf("completion not mapped: %v", parent)
+	}
+	if again := p.items(jobID, ts); again[1].data["uid"] != parent["uid"] {
+		t.Error("UIDs must be deterministic per job")
+	}
+}
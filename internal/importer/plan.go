@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan is a parsed export, mapped onto toolbridge entities
+// Keys are source-specific IDs ("task:123"); they become deterministic UIDs
+// when the plan is pushed, so re-running a job rewrites the same items.
+type Plan struct {
+	Lists    []List
+	Tasks    []Task
+	Notes    []Note
+	Comments []Comment
+}
+
+// List becomes a task_list
+type List struct {
+	Key  string
+	Name string
+}
+
+// Task becomes a task
+type Task struct {
+	Key         string
+	ListKey     string // Empty if the task has no list
+	ParentKey   string // Empty for top-level tasks
+	Title       string
+	Description string
+	Completed   bool
+	CompletedAt time.Time // Zero if unknown
+	Due         string    // ISO 8601 date or date-time ("" if none)
+	Priority    int       // 0 none, 1 low, 2 medium, 3 high
+	Tags        []string
+}
+
+// Note becomes a note
+type Note struct {
+	Key     string
+	Title   string
+	Content string
+	Tags    []string
+}
+
+// Comment becomes a comment on a task
+type Comment struct {
+	Key     string
+	TaskKey string
+	Content string
+}
+
+// Len returns the number of items the plan pushes
+func (p *Plan) Len() int {
+	return len(p.Lists) + len(p.Tasks) + len(p.Notes) + len(p.Comments)
+}
+
+// item is one entity payload ready for the sync push services
+type item struct {
+	entity string
+	data   map[string]any
+}
+
+// items converts the plan into push payloads, parents before children
+// UIDs are derived from jobID and each key; every item is stamped updatedAt.
+func (p *Plan) items(jobID uuid.UUID, updatedAt time.Time) []item {
+	uid := func(key string) string {
+		return uuid.NewSHA1(jobID, []byte(key)).String()
+	}
+	ts := updatedAt.UTC().Format(time.RFC3339Nano)
+	out := make([]item, 0, p.Len())
+	add := func(entity, key string, data map[string]any) {
+		data["uid"] = uid(key)
+		data["updatedTs"] = ts
+		data["sync"] = map[string]any{"version": float64(1)}
+		out = append(out, item{entity: entity, data: data})
+	}
+
+	lists := make(map[string]bool, len(p.Lists))
+	for _, l := range p.Lists {
+		lists[l.Key] = true
+		add("task_list", l.Key, map[string]any{"name": l.Name})
+	}
+
+	tasks := make(map[string]bool, len(p.Tasks))
+	for _, t := range orderTasks(p.Tasks) {
+		tasks[t.Key] = true
+		data := map[string]any{
+			"title":  t.Title,
+			"status": "open",
+			"done":   t.Completed,
+		}
+		// Same status/done pair as POST /v1/tasks/{uid}/action
+		if t.Completed {
+			data["status"] = "completed"
+			if !t.CompletedAt.IsZero() {
+				data["completedAt"] = t.CompletedAt.UTC().Format(time.RFC3339)
+			}
+		}
+		if t.Description != "" {
+			data["description"] = t.Description
+		}
+		if t.Due != "" {
+			data["dueDate"] = t.Due
+		}
+		if t.Priority > 0 {
+			data["priority"] = float64(t.Priority)
+		}
+		if len(t.Tags) > 0 {
+			data["tags"] = stringsToAny(t.Tags)
+		}
+		if lists[t.ListKey] {
+			data["taskListUid"] = uid(t.ListKey)
+		}
+		if tasks[t.ParentKey] {
+			data["parentUid"] = uid(t.ParentKey)
+		}
+		add("task", t.Key, data)
+	}
+
+	for _, n := range p.Notes {
+		data := map[string]any{"title": n.Title, "content": n.Content}
+		if len(n.Tags) > 0 {
+			data["tags"] = stringsToAny(n.Tags)
+		}
+		add("note", n.Key, data)
+	}
+
+	for _, c := range p.Comments {
+		if !tasks[c.TaskKey] {
+			continue // Comment on a task that isn't part of the export
+		}
+		add("comment", c.Key, map[string]any{
+			"parentType": "task",
+			"parentUid":  uid(c.TaskKey),
+			"content":    c.Content,
+		})
+	}
+	return out
+}
+
+// orderTasks returns tasks sorted so every parent precedes its subtasks
+// Source order is kept otherwise; parent cycles are broken arbitrarily.
+func orderTasks(tasks []Task) []Task {
+	parent := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		parent[t.Key] = t.ParentKey
+	}
+	depth := func(key string) int {
+		d := 0
+		for p := parent[key]; p != "" && d <= len(tasks); p = parent[p] {
+			d++
+		}
+		return d
+	}
+	out := append([]Task(nil), tasks...)
+	depths := make(map[string]int, len(out))
+	for _, t := range out {
+		depths[t.Key] = depth(t.Key)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return depths[out[i].Key] < depths[out[j].Key] })
+	return out
+}
+
+func stringsToAny(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tickTickTimeLayout is the timestamp format of TickTick's CSV backup
+const tickTickTimeLayout = "2006-01-02T15:04:05-0700"
+
+// parseTickTick reads a TickTick CSV backup (Settings > Backup)
+// The file starts with a few metadata lines before the header row. Lists become
+// task lists, items of kind NOTE become notes, everything else becomes a task.
+func parseTickTick(data []byte) (*Plan, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, &ValidationError{Msg: "invalid TickTick CSV: " + err.Error()}
+	}
+
+	header := -1
+	for i, rec := range records {
+		col := columnIndex(rec)
+		_, hasTitle := col["Title"]
+		_, hasList := col["List Name"]
+		if hasTitle && hasList {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil, &ValidationError{Msg: "TickTick CSV has no header row (expected \"List Name\" and \"Title\" columns)"}
+	}
+	col := columnIndex(records[header])
+
+	p := &Plan{}
+	lists := make(map[string]bool)
+	for i, rec := range records[header+1:] {
+		get := func(name string) string { return field(rec, col, name) }
+		id := get("taskId")
+		if id == "" {
+			if get("Title") == "" {
+				continue
+			}
+			id = "row" + strconv.Itoa(i+1)
+		}
+		tags := splitTags(get("Tags"))
+
+		if strings.EqualFold(get("Kind"), "NOTE") {
+			p.Notes = append(p.Notes, Note{Key: "note:" + id, Title: get("Title"), Content: get("Content"), Tags: tags})
+			continue
+		}
+
+		t := Task{
+			Key:         "task:" + id,
+			Title:       get("Title"),
+			Description: get("Content"),
+			Tags:        tags,
+			Priority:    tickTickPriority(get("Priority")),
+			// Status: 0 normal, 1 completed, 2 archived (completed and hidden)
+			Completed: get("Status") != "" && get("Status") != "0",
+		}
+		if name := get("List Name"); name != "" {
+			t.ListKey = "list:" + name
+			if !lists[name] {
+				lists[name] = true
+				p.Lists = append(p.Lists, List{Key: t.ListKey, Name: name})
+			}
+		}
+		if parent := get("parentId"); parent != "" {
+			t.ParentKey = "task:" + parent
+		}
+		if t.Completed {
+			t.CompletedAt, _ = time.Parse(tickTickTimeLayout, get("Completed Time"))
+		}
+		if due, err := time.Parse(tickTickTimeLayout, get("Due Date")); err == nil {
+			if strings.EqualFold(get("Is All Day"), "true") {
+				// All-day dates are exported as midnight in the task's time zone
+				if loc, err := time.LoadLocation(get("Timezone")); err == nil {
+					due = due.In(loc)
+				}
+				t.Due = due.Format("2006-01-02")
+			} else {
+				t.Due = due.UTC().Format(time.RFC3339)
+			}
+		}
+		p.Tasks = append(p.Tasks, t)
+	}
+	return p, nil
+}
+
+// tickTickPriority maps TickTick's 0/1/3/5 scale to ours
+func tickTickPriority(s string) int {
+	switch s {
+	case "5":
+		return 3
+	case "3":
+		return 2
+	case "1":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// splitTags splits a comma separated tag column
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// columnIndex maps header names to column positions
+func columnIndex(header []string) map[string]int {
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(name)] = i
+	}
+	return col
+}
+
+// field returns the named column of rec ("" if absent)
+func field(rec []string, col map[string]int, name string) string {
+	i, ok := col[name]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+// normalizeDue returns an ISO 8601 date or date-time for due dates that are
+// already machine-readable ("" otherwise)
+func normalizeDue(s string) string {
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return s
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	// Floating (local) time without an offset
+	if t, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return ""
+}
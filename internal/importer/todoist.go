@@ -0,0 +1,289 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTodoist accepts the formats Todoist exports:
+//   - a backup zip (one template CSV per project)
+//   - a single project template CSV
+//   - a Sync API dump ({"projects": [...], "items": [...], "notes": [...], "labels": [...]})
+//
+// Only the JSON dump carries completed tasks; template CSVs hold active tasks.
+func parseTodoist(data []byte) (*Plan, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK")):
+		return parseTodoistZip(data)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		return parseTodoistJSON(data)
+	default:
+		p := &Plan{}
+		if err := parseTodoistCSV(p, "project:Todoist", "Todoist", data); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// flexID accepts IDs encoded as JSON strings (Sync API v9) or numbers (older dumps)
+type flexID string
+
+func (id *flexID) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = flexID(s)
+		return nil
+	}
+	if string(b) == "null" {
+		*id = ""
+		return nil
+	}
+	*id = flexID(b)
+	return nil
+}
+
+type todoistDump struct {
+	Projects []struct {
+		ID   flexID `json:"id"`
+		Name string `json:"name"`
+	} `json:"projects"`
+	Items []struct {
+		ID          flexID   `json:"id"`
+		ProjectID   flexID   `json:"project_id"`
+		ParentID    flexID   `json:"parent_id"`
+		Content     string   `json:"content"`
+		Description string   `json:"description"`
+		Priority    int      `json:"priority"` // API scale: 4 is p1 (urgent), 1 is none
+		Labels      []flexID `json:"labels"`   // Names (v9) or label IDs (older dumps)
+		Checked     bool     `json:"checked"`
+		CompletedAt string   `json:"completed_at"`
+		Due         *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+	Notes []struct {
+		ID      flexID `json:"id"`
+		ItemID  flexID `json:"item_id"`
+		Content string `json:"content"`
+	} `json:"notes"`
+	Labels []struct {
+		ID   flexID `json:"id"`
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+func parseTodoistJSON(data []byte) (*Plan, error) {
+	var dump todoistDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, &ValidationError{Msg: "invalid Todoist JSON: " + err.Error()}
+	}
+	if dump.Projects == nil && dump.Items == nil {
+		return nil, &ValidationError{Msg: "Todoist JSON has no projects or items"}
+	}
+
+	labelNames := make(map[flexID]string, len(dump.Labels))
+	for _, l := range dump.Labels {
+		labelNames[l.ID] = l.Name
+	}
+
+	p := &Plan{}
+	for _, pr := range dump.Projects {
+		p.Lists = append(p.Lists, List{Key: "project:" + string(pr.ID), Name: pr.Name})
+	}
+	for _, it := range dump.Items {
+		t := Task{
+			Key:         "task:" + string(it.ID),
+			ListKey:     "project:" + string(it.ProjectID),
+			Title:       it.Content,
+			Description: it.Description,
+			Completed:   it.Checked,
+			Priority:    todoistAPIPriority(it.Priority),
+		}
+		if it.ParentID != "" {
+			t.ParentKey = "task:" + string(it.ParentID)
+		}
+		if it.CompletedAt != "" {
+			t.CompletedAt, _ = time.Parse(time.RFC3339Nano, it.CompletedAt)
+		}
+		if it.Due != nil {
+			t.Due = normalizeDue(it.Due.Date)
+		}
+		for _, l := range it.Labels {
+			name := string(l)
+			if n, ok := labelNames[l]; ok {
+				name = n
+			}
+			t.Tags = append(t.Tags, name)
+		}
+		p.Tasks = append(p.Tasks, t)
+	}
+	for _, n := range dump.Notes {
+		p.Comments = append(p.Comments, Comment{
+			Key:     "note:" + string(n.ID),
+			TaskKey: "task:" + string(n.ItemID),
+			Content: n.Content,
+		})
+	}
+	return p, nil
+}
+
+// todoistAPIPriority maps the API scale (4 urgent .. 1 none) to ours
+func todoistAPIPriority(p int) int {
+	if p < 1 || p > 4 {
+		return 0
+	}
+	return p - 1
+}
+
+// projectSuffix matches the " [1234567]" project ID Todoist appends to backup file names
+var projectSuffix = regexp.MustCompile(`\s*\[\d+\]$`)
+
+func parseTodoistZip(data []byte) (*Plan, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, &ValidationError{Msg: "invalid zip archive"}
+	}
+	p := &Plan{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".csv") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, &ValidationError{Msg: "unreadable zip entry " + f.Name}
+		}
+		body, err := io.ReadAll(io.LimitReader(rc, MaxInputBytes))
+		rc.Close()
+		if err != nil {
+			return nil, &ValidationError{Msg: "unreadable zip entry " + f.Name}
+		}
+		name := projectSuffix.ReplaceAllString(strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name)), "")
+		if err := parseTodoistCSV(p, "project:"+f.Name, name, body); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Lists) == 0 {
+		return nil, &ValidationError{Msg: "zip archive contains no Todoist CSV files"}
+	}
+	return p, nil
+}
+
+// labelToken matches "@label" in task content
+var labelToken = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// parseTodoistCSV adds one project template CSV to p
+// Columns: TYPE, CONTENT, DESCRIPTION, PRIORITY, INDENT, AUTHOR, RESPONSIBLE,
+// DATE, DATE_LANG, TIMEZONE. Rows of TYPE "note" are comments on the task above.
+func parseTodoistCSV(p *Plan, listKey, listName string, data []byte) error {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return &ValidationError{Msg: "invalid Todoist CSV: " + err.Error()}
+	}
+	if len(records) == 0 {
+		return &ValidationError{Msg: "Todoist CSV is empty"}
+	}
+	col := columnIndex(records[0])
+	if _, ok := col["TYPE"]; !ok {
+		return &ValidationError{Msg: "Todoist CSV is missing the TYPE column"}
+	}
+	if _, ok := col["CONTENT"]; !ok {
+		return &ValidationError{Msg: "Todoist CSV is missing the CONTENT column"}
+	}
+
+	p.Lists = append(p.Lists, List{Key: listKey, Name: listName})
+	var parents []string // Task key at each indent level
+	for i, rec := range records[1:] {
+		get := func(name string) string { return field(rec, col, name) }
+		key := listKey + ":" + strconv.Itoa(i+1)
+		switch strings.ToLower(get("TYPE")) {
+		case "task":
+			indent, _ := strconv.Atoi(get("INDENT"))
+			indent = max(indent, 1)
+			if indent > len(parents)+1 {
+				indent = len(parents) + 1
+			}
+			parents = append(parents[:indent-1], key)
+
+			title, tags := splitLabels(get("CONTENT"))
+			t := Task{
+				Key:         key,
+				ListKey:     listKey,
+				Title:       title,
+				Description: get("DESCRIPTION"),
+				Due:         parseTodoistDate(get("DATE")),
+				Tags:        tags,
+			}
+			// Template CSVs use the display scale: 1 is p1 (urgent), 4 is none
+			if pr, err := strconv.Atoi(get("PRIORITY")); err == nil && pr >= 1 && pr <= 4 {
+				t.Priority = 4 - pr
+			}
+			if indent > 1 {
+				t.ParentKey = parents[indent-2]
+			}
+			p.Tasks = append(p.Tasks, t)
+		case "note":
+			if len(parents) > 0 && get("CONTENT") != "" {
+				p.Comments = append(p.Comments, Comment{Key: key, TaskKey: parents[len(parents)-1], Content: get("CONTENT")})
+			}
+		}
+	}
+	return nil
+}
+
+// splitLabels removes "@label" tokens from content and returns them as tags
+func splitLabels(content string) (string, []string) {
+	var tags []string
+	for _, m := range labelToken.FindAllStringSubmatch(content, -1) {
+		tags = append(tags, m[1])
+	}
+	if len(tags) == 0 {
+		return content, nil
+	}
+	return strings.TrimSpace(labelToken.ReplaceAllString(content, "")), tags
+}
+
+// todoistDateLayouts are the absolute date formats seen in template CSVs
+// Recurring and relative dates ("every monday") can't be mapped and are dropped.
+var todoistDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"Jan 2 2006",
+	"Jan 2 2006 15:04",
+	"2 Jan 2006",
+	"2 Jan 2006 15:04",
+}
+
+func parseTodoistDate(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if due := normalizeDue(s); due != "" {
+		return due
+	}
+	for _, layout := range todoistDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			if strings.Contains(layout, "15") {
+				return t.Format("2006-01-02T15:04:05")
+			}
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
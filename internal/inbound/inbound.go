@@ -0,0 +1,269 @@
+// Package inbound turns payloads POSTed to tokenized URLs into notes or tasks
+// Each endpoint has a mapping template (see Template) that renders the item's
+// fields from the payload, so external services (email-to-task forwarders,
+// form builders) can write into a user's synced data without credentials.
+package inbound
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxEndpoints caps inbound endpoints per user
+const MaxEndpoints = 20
+
+// MaxBodyBytes caps inbound request bodies
+const MaxBodyBytes = 1 << 20
+
+// Template limits
+const (
+	maxTemplateFields = 20
+	maxTemplateLen    = 4096
+)
+
+// ErrNotFound is returned when an endpoint (or token) doesn't exist
+var ErrNotFound = errors.New("inbound endpoint not found")
+
+// ErrLimit is returned when the user already has MaxEndpoints endpoints
+var ErrLimit = errors.New("inbound endpoint limit reached")
+
+// ValidationError describes an invalid endpoint or payload
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// reservedFields are set by the receiver and can't be templated
+var reservedFields = map[string]bool{"uid": true, "updatedTs": true, "sync": true, "deletedAt": true, "source": true}
+
+// EndpointRequest is the body for creating or updating an endpoint
+// An empty Template selects the entity's DefaultTemplates entry.
+type EndpointRequest struct {
+	Name     string   `json:"name"`
+	Entity   string   `json:"entity"` // note or task
+	Template Template `json:"template,omitempty"`
+}
+
+// Endpoint describes an inbound endpoint (Token is only set when it is created)
+type Endpoint struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Entity         string     `json:"entity"`
+	Template       Template   `json:"template"`
+	Token          string     `json:"-"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastReceivedAt *time.Time `json:"lastReceivedAt,omitempty"`
+}
+
+// Service manages inbound endpoints and ingests their payloads
+type Service struct {
+	DB    *pgxpool.Pool
+	Notes *syncservice.NoteService
+	Tasks *syncservice.TaskService
+}
+
+// NewService creates an inbound webhook service
+func NewService(db *pgxpool.Pool) *Service {
+	return &Service{
+		DB:    db,
+		Notes: syncservice.NewNoteService(db),
+		Tasks: syncservice.NewTaskService(db),
+	}
+}
+
+// hashToken returns the stored form of an endpoint token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validate normalizes req, filling in the default template
+func validate(req EndpointRequest) (EndpointRequest, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return req, &ValidationError{Msg: "name is required (at most 100 characters)"}
+	}
+	def, ok := DefaultTemplates[req.Entity]
+	if !ok {
+		return req, &ValidationError{Msg: "entity must be note or task"}
+	}
+	if len(req.Template) == 0 {
+		req.Template = def
+		return req, nil
+	}
+	if len(req.Template) > maxTemplateFields {
+		return req, &ValidationError{Msg: "template has too many fields"}
+	}
+	for field, tmpl := range req.Template {
+		if field == "" || reservedFields[field] {
+			return req, &ValidationError{Msg: "template field not allowed: " + field}
+		}
+		if len(tmpl) > maxTemplateLen {
+			return req, &ValidationError{Msg: "template too long: " + field}
+		}
+	}
+	if _, ok := req.Template["title"]; !ok {
+		return req, &ValidationError{Msg: "template must set title"}
+	}
+	return req, nil
+}
+
+// Create registers an endpoint; the returned Token is not stored and can't be shown again
+func (s *Service) Create(ctx context.Context, userID string, req EndpointRequest) (*Endpoint, error) {
+	req, err := validate(req)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	ep := Endpoint{Name: req.Name, Entity: req.Entity, Template: req.Template, Token: base64.RawURLEncoding.EncodeToString(b)}
+
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('inbound:' || $1))`, userID); err != nil {
+			return err
+		}
+		var n int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM inbound_endpoint WHERE owner_id = $1`, userID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= MaxEndpoints {
+			return ErrLimit
+		}
+		return tx.QueryRow(ctx, `
+			INSERT INTO inbound_endpoint (owner_id, name, token_hash, entity, template)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id::text, created_at
+		`, userID, ep.Name, hashToken(ep.Token), ep.Entity, ep.Template).Scan(&ep.ID, &ep.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// Update replaces an endpoint's name, entity and template (the token is kept)
+func (s *Service) Update(ctx context.Context, userID, id string, req EndpointRequest) (*Endpoint, error) {
+	req, err := validate(req)
+	if err != nil {
+		return nil, err
+	}
+	ep := Endpoint{ID: id, Name: req.Name, Entity: req.Entity, Template: req.Template}
+	err = s.DB.QueryRow(ctx, `
+		UPDATE inbound_endpoint SET name = $3, entity = $4, template = $5
+		WHERE id = $1 AND owner_id = $2
+		RETURNING created_at, last_received_at
+	`, id, userID, ep.Name, ep.Entity, ep.Template).Scan(&ep.CreatedAt, &ep.LastReceivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// List returns the user's endpoints, oldest first (without tokens)
+func (s *Service) List(ctx context.Context, userID string) ([]Endpoint, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT id::text, name, entity, template, created_at, last_received_at
+		FROM inbound_endpoint WHERE owner_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eps := []Endpoint{}
+	for rows.Next() {
+		var ep Endpoint
+		if err := rows.Scan(&ep.ID, &ep.Name, &ep.Entity, &ep.Template, &ep.CreatedAt, &ep.LastReceivedAt); err != nil {
+			return nil, err
+		}
+		eps = append(eps, ep)
+	}
+	return eps, rows.Err()
+}
+
+// Delete removes one of the user's endpoints (its URL stops working)
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	tag, err := s.DB.Exec(ctx, `DELETE FROM inbound_endpoint WHERE id = $1 AND owner_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Receipt identifies the item an inbound payload was written to
+type Receipt struct {
+	Entity string `json:"entity"`
+	UID    string `json:"uid"`
+}
+
+// Receive renders payload with the token's endpoint template and pushes the
+// item through the sync service, as device "inbound"
+// With an idempotency key (e.g. the email Message-ID), redelivered payloads
+// map to the same UID and the later write wins; without one every call
+// creates a new item.
+func (s *Service) Receive(ctx context.Context, token string, payload map[string]any, idempotencyKey string) (*Receipt, error) {
+	var id uuid.UUID
+	var userID, entity string
+	var tmpl Template
+	err := s.DB.QueryRow(ctx, `
+		UPDATE inbound_endpoint SET last_received_at = now()
+		WHERE token_hash = $1
+		RETURNING id, owner_id::text, entity, template
+	`, hashToken(token)).Scan(&id, &userID, &entity, &tmpl)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item := tmpl.Render(payload)
+	if _, ok := item["title"]; !ok {
+		return nil, &ValidationError{Msg: "template rendered an empty title"}
+	}
+	uid := uuid.New()
+	if idempotencyKey != "" {
+		uid = uuid.NewSHA1(id, []byte(idempotencyKey))
+	}
+	item["uid"] = uid.String()
+	item["updatedTs"] = time.Now().UTC().Format(time.RFC3339Nano)
+	item["sync"] = map[string]any{"version": float64(1)}
+	item["source"] = "inbound"
+
+	push := s.Notes.PushNoteItem
+	if entity == "task" {
+		push = s.Tasks.PushTaskItem
+	}
+	ctx = syncservice.WithChangeSource(ctx, syncservice.ChangeSource{DeviceID: "inbound"})
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		if ack := push(ctx, tx, userID, item); ack.Error != "" {
+			return &ValidationError{Msg: ack.Error}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{Entity: entity, UID: uid.String()}, nil
+}
@@ -0,0 +1,92 @@
+package inbound
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	payload := map[string]any{
+		"subject": "Invoice due",
+		"from":    map[string]any{"name": "Billing", "address": "billing@example.com"},
+		"items":   []any{map[string]any{"amount": 42.5}},
+		"urgent":  true,
+		"h.x":     "dotted key",
+	}
+	for tmpl, want := range map[string]string{
+		"{{subject}}": "Invoice due",
+		"From {{from.name}} <{{ from.address }}>": "From Billing <billing@example.com>",
+		"{{items.0.amount}}":                      "42.5",
+		"{{urgent}}":                              "true",
+		"{{title|subject}}":                       "Invoice due",
+		`{{title|"Untitled"}}`:                    "Untitled",
+		"{{missing}}":                             "",
+		"{{items.7.amount}}":                      "",
+		"{{h.x}}":                                 "dotted key",
+		"{{from}}":                                `{"address":"billing@example.com","name":"Billing"}`,
+	} {
+		if got := render(tmpl, payload); got != want {
+			t.Errorf("render(%q) = %q, want %q", tmpl, got, want)
+		}
+	}
+}
+
+func TestDefaultTemplateOmitsEmptyFields(t *testing.T) {
+	item := DefaultTemplates["task"].Render(map[string]any{"Subject": "Call back"})
+	if item["title"] != "Call back" {
+		t.Errorf("title = %v", item["title"])
+	}
+	if _, ok := item["description"]; ok {
+		t.Errorf("empty description rendered: %v", item)
+	}
+	if got := DefaultTemplates["note"].Render(map[string]any{})["title"]; got != "Inbound note" {
+		t.Errorf("default title = %v", got)
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	got, err := ParsePayload("application/json; charset=utf-8", []byte(`{"title":"x"}`))
+	if err != nil || got["title"] != "x" {
+		t.Errorf("json = %v, %v", got, err)
+	}
+	var verr *ValidationError
+	if _, err := ParsePayload("application/json", []byte(`[1]`)); !errors.As(err, &verr) {
+		t.Errorf("json array = %v, want ValidationError", err)
+	}
+
+	got, err = ParsePayload("application/x-www-form-urlencoded", []byte("subject=Hi&body-plain=Hello+there&subject=ignored"))
+	if err != nil || got["subject"] != "Hi" || got["body-plain"] != "Hello there" {
+		t.Errorf("form = %v, %v", got, err)
+	}
+
+	multipart := "--b\r\nContent-Disposition: form-data; name=\"subject\"\r\n\r\nHi\r\n" +
+		"--b\r\nContent-Disposition: form-data; name=\"attachment1\"; filename=\"a.txt\"\r\n\r\nfile\r\n" +
+		"--b--\r\n"
+	got, err = ParsePayload("multipart/form-data; boundary=b", []byte(multipart))
+	if err != nil || got["subject"] != "Hi" || got["attachment1"] != nil {
+		t.Errorf("multipart = %v, %v", got, err)
+	}
+
+	got, err = ParsePayload("text/plain", []byte("remember the milk"))
+	if err != nil || got["text"] != "remember the milk" {
+		t.Errorf("text = %v, %v", got, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	req, err := validate(EndpointRequest{Name: " Email ", Entity: "task"})
+	if err != nil || req.Name != "Email" || req.Template["title"] == "" {
+		t.Fatalf("validate defaults = %+v, %v", req, err)
+	}
+	for name, bad := range map[string]EndpointRequest{
+		"no name":        {Entity: "note"},
+		"bad entity":     {Name: "x", Entity: "comment"},
+		"reserved field": {Name: "x", Entity: "note", Template: Template{"title": "t", "uid": "{{id}}"}},
+		"no title":       {Name: "x", Entity: "note", Template: Template{"content": "{{text}}"}},
+	} {
+		var verr *ValidationError
+		if _, err := validate(bad); !errors.As(err, &verr) {
+			t.Errorf("%s: got %v, want ValidationError", name, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Template maps item fields to template strings
+// "{{path}}" is replaced with the payload value at a dotted path (array
+// elements by index, e.g. "{{attachments.0.name}}"); "{{a|b}}" uses the first
+// non-empty alternative, and a quoted alternative is a literal default:
+// "{{subject|\"No subject\"}}". Text outside placeholders is kept as is.
+type Template map[string]string
+
+// DefaultTemplates are used when an endpoint is created without a template
+// They cover common JSON bodies and email-parse webhooks (Mailgun, SendGrid,
+// Postmark field names).
+var DefaultTemplates = map[string]Template{
+	"note": {
+		"title":   "{{subject|Subject|title|name|\"Inbound note\"}}",
+		"content": "{{text|TextBody|body-plain|body|content|message}}",
+	},
+	"task": {
+		"title":       "{{subject|Subject|title|name|\"Inbound task\"}}",
+		"description": "{{text|TextBody|body-plain|body|content|message}}",
+	},
+}
+
+var placeholder = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// Render returns the item fields for payload; fields that render empty are omitted
+func (t Template) Render(payload map[string]any) map[string]any {
+	out := make(map[string]any, len(t))
+	for field, tmpl := range t {
+		if v := strings.TrimSpace(render(tmpl, payload)); v != "" {
+			out[field] = v
+		}
+	}
+	return out
+}
+
+func render(tmpl string, payload map[string]any) string {
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		for _, alt := range strings.Split(m[2:len(m)-2], "|") {
+			alt = strings.TrimSpace(alt)
+			if len(alt) >= 2 && alt[0] == '"' && alt[len(alt)-1] == '"' {
+				return alt[1 : len(alt)-1]
+			}
+			if v := lookup(payload, alt); v != "" {
+				return v
+			}
+		}
+		return ""
+	})
+}
+
+// lookup returns the value at a dotted path as a string ("" if missing)
+func lookup(payload map[string]any, path string) string {
+	if path == "" {
+		return ""
+	}
+	// A key containing dots (form fields often do) wins over traversal
+	if v, ok := payload[path]; ok {
+		return stringify(v)
+	}
+	var cur any = payload
+	for _, part := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			cur = node[part]
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return ""
+			}
+			cur = node[i]
+		default:
+			return ""
+		}
+	}
+	return stringify(cur)
+}
+
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// ParsePayload decodes a request body into the map templates read from
+// JSON bodies must be objects; form posts (urlencoded or multipart, as sent by
+// email-parse webhooks) become field -> first value, with file parts skipped;
+// any other text body is available as {{text}}.
+func ParsePayload(contentType string, body []byte) (map[string]any, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil || payload == nil {
+			return nil, &ValidationError{Msg: "body must be a JSON object"}
+		}
+		return payload, nil
+
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, &ValidationError{Msg: "invalid form body"}
+		}
+		payload := make(map[string]any, len(values))
+		for k, v := range values {
+			payload[k] = v[0]
+		}
+		return payload, nil
+
+	case mediaType == "multipart/form-data":
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		payload := map[string]any{}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, &ValidationError{Msg: "invalid multipart body"}
+			}
+			name := part.FormName()
+			if name == "" || part.FileName() != "" {
+				continue // Attachments are not stored
+			}
+			if _, dup := payload[name]; dup {
+				continue
+			}
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return nil, &ValidationError{Msg: "invalid multipart body"}
+			}
+			payload[name] = string(value)
+		}
+		return payload, nil
+
+	default:
+		return map[string]any{"text": string(body)}, nil
+	}
+}
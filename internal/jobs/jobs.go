@@ -0,0 +1,153 @@
+// Package jobs runs the server's background jobs.
+// Singleton jobs run on exactly one replica at a time: each holds a Postgres
+// session-level advisory lock on a dedicated connection while it runs, and the
+// other replicas keep retrying until the holder exits or loses its connection.
+package jobs
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Job is a long-running background loop
+// Run must return promptly once ctx is cancelled.
+type Job struct {
+	Name      string
+	Singleton bool // Run on one replica only (leader elected via advisory lock)
+	Run       func(ctx context.Context)
+}
+
+// Scheduler starts jobs and waits for them on shutdown
+type Scheduler struct {
+	DB *pgxpool.Pool
+	// RetryInterval is how often followers retry the lock and how often the
+	// leader checks that its lock connection is still alive.
+	RetryInterval time.Duration
+
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler whose singleton jobs elect a leader through db
+func NewScheduler(db *pgxpool.Pool) *Scheduler {
+	return &Scheduler{DB: db, RetryInterval: 15 * time.Second}
+}
+
+// Add registers a job; call before Start
+func (s *Scheduler) Add(name string, singleton bool, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, Job{Name: name, Singleton: singleton, Run: run})
+}
+
+// Start launches every job; they stop when ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if job.Singleton {
+				s.runElected(ctx, job)
+			} else {
+				job.Run(ctx)
+			}
+		}()
+	}
+}
+
+// Wait blocks until every job has returned
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// lockKey derives a stable advisory lock key from the job name
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("toolbridge-job:" + name))
+	return int64(h.Sum64())
+}
+
+// runElected runs job whenever this replica holds its lock, until ctx is cancelled
+func (s *Scheduler) runElected(ctx context.Context, job Job) {
+	logger := log.With().Str("job", job.Name).Logger()
+	key := lockKey(job.Name)
+	metrics.SetJobLeader(job.Name, false)
+
+	for ctx.Err() == nil {
+		if s.leadOnce(ctx, job, key) {
+			logger.Info().Msg("background job leadership released")
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.RetryInterval):
+		}
+	}
+}
+
+// leadOnce tries to take the lock and, if it does, runs the job until ctx is
+// cancelled or the lock connection fails. Returns whether it was leader.
+func (s *Scheduler) leadOnce(ctx context.Context, job Job, key int64) bool {
+	logger := log.With().Str("job", job.Name).Logger()
+
+	conn, err := s.DB.Acquire(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn().Err(err).Msg("job leader election: acquire connection failed")
+		}
+		return false
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil || !locked {
+		if err != nil && ctx.Err() == nil {
+			logger.Warn().Err(err).Msg("job leader election: lock query failed")
+		}
+		conn.Release()
+		return false
+	}
+
+	logger.Info().Msg("background job leadership acquired")
+	metrics.SetJobLeader(job.Name, true)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.Run(jobCtx)
+	}()
+
+	// Hold the lock while the connection lives; a dead connection means Postgres
+	// already released the lock and another replica may take over.
+	ticker := time.NewTicker(s.RetryInterval)
+	lost := false
+	for !lost {
+		select {
+		case <-ctx.Done():
+			lost = true
+		case <-done:
+			lost = true // Job returned on its own
+		case <-ticker.C:
+			if err := conn.Ping(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn().Err(err).Msg("job leader lock connection lost; stopping job")
+				lost = true
+			}
+		}
+	}
+	ticker.Stop()
+	cancel()
+	<-done
+	metrics.SetJobLeader(job.Name, false)
+
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unlockCancel()
+	if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		// Closing the connection releases the lock server-side
+		_ = conn.Conn().Close(unlockCtx)
+	}
+	conn.Release()
+	return true
+}
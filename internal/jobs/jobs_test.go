@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockKeyStable(t *testing.T) {
+	if lockKey("integrity") != lockKey("integrity") {
+		t.Fatal("lock key must be deterministic across replicas")
+	}
+	if lockKey("integrity") == lockKey("exports") {
+		t.Fatal("different jobs must use different lock keys")
+	}
+}
+
+func TestSchedulerRunsUnelectedJobsUntilCancelled(t *testing.T) {
+	s := NewScheduler(nil) // No singleton jobs, so no database needed
+	var running atomic.Int32
+	for _, name := range []string{"a", "b"} {
+		s.Add(name, false, func(ctx context.Context) {
+			running.Add(1)
+			<-ctx.Done()
+			running.Add(-1)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	deadline := time.Now().Add(time.Second)
+	for running.Load() != 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := running.Load(); got != 2 {
+		t.Fatalf("running jobs = %d, want 2", got)
+	}
+
+	cancel()
+	s.Wait()
+	if got := running.Load(); got != 0 {
+		t.Fatalf("running jobs after Wait = %d, want 0", got)
+	}
+}
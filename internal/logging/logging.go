@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Runtime log controls
+//
+// The global level and info/debug sampling can be changed while the server is
+// running (admin endpoint, SIGUSR1), so diagnosing a user issue doesn't need a
+// restart to go from info to debug.
+
+// baseLevel is the level restored when SIGUSR1 toggles debug off
+var baseLevel atomic.Int32
+
+// sampler is shared by all sampled loggers so "every Nth" counts across requests
+// nil means sampling is disabled
+var sampler atomic.Pointer[zerolog.BasicSampler]
+
+func init() {
+	baseLevel.Store(int32(zerolog.InfoLevel))
+}
+
+// Init sets the starting level from a LOG_LEVEL style string (empty = info)
+func Init(level string) error {
+	if level == "" {
+		level = "info"
+	}
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return err
+	}
+	baseLevel.Store(int32(lvl))
+	zerolog.SetGlobalLevel(lvl)
+	return nil
+}
+
+// Level returns the current global level
+func Level() zerolog.Level {
+	return zerolog.GlobalLevel()
+}
+
+// SetLevel changes the global level (e.g., "debug", "info", "warn")
+func SetLevel(level string) (zerolog.Level, error) {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return zerolog.NoLevel, err
+	}
+	zerolog.SetGlobalLevel(lvl)
+	return lvl, nil
+}
+
+// ToggleDebug switches between debug and the configured base level (SIGUSR1)
+func ToggleDebug() zerolog.Level {
+	lvl := zerolog.DebugLevel
+	if zerolog.GlobalLevel() == zerolog.DebugLevel {
+		lvl = zerolog.Level(baseLevel.Load())
+	}
+	zerolog.SetGlobalLevel(lvl)
+	log.Warn().Str("level", lvl.String()).Msg("log level toggled by signal")
+	return lvl
+}
+
+// SampleEvery returns the current info/debug sampling rate (0 = disabled)
+func SampleEvery() uint32 {
+	if s := sampler.Load(); s != nil {
+		return s.N
+	}
+	return 0
+}
+
+// SetSampleEvery keeps 1 of every n info/debug logs written through Sampled
+// n <= 1 disables sampling. Warn and above are never sampled.
+func SetSampleEvery(n uint32) {
+	if n <= 1 {
+		sampler.Store(nil)
+	} else {
+		sampler.Store(&zerolog.BasicSampler{N: n})
+	}
+}
+
+// Sampled returns the request logger with info/debug sampling applied
+// Use for high-volume logs (per-request sync push/pull logs); errors and
+// warnings always pass through.
+func Sampled(ctx context.Context) *zerolog.Logger {
+	logger := log.Ctx(ctx)
+	s := sampler.Load()
+	if s == nil {
+		return logger
+	}
+	sampled := logger.Sample(zerolog.LevelSampler{DebugSampler: s, InfoSampler: s})
+	return &sampled
+}
@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Transport labels (HTTP and gRPC share the same sync metrics)
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Sync metrics
+// Labels are bounded: transport (http|grpc), entity (fixed set of sync entities),
+// and a small fixed result/outcome/kind set. Never label by user or tenant.
+var (
+	pushItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "toolbridge_sync_push_items_total",
+		Help: "Pushed items by result: applied (row written), conflict (LWW kept the server row), error (ack carried an error or the batch rolled back).",
+	}, []string{"transport", "entity", "result"})
+
+	pushBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "toolbridge_sync_push_batch_size",
+		Help:    "Number of items per push request.",
+		Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"transport", "entity"})
+
+	pushTxDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "toolbridge_sync_push_tx_duration_seconds",
+		Help:    "Push transaction duration by outcome (commit or rollback).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"transport", "entity", "outcome"})
+
+	pullItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "toolbridge_sync_pull_items_total",
+		Help: "Pulled items by kind: upsert or tombstone.",
+	}, []string{"transport", "entity", "kind"})
+
+	panics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "toolbridge_panics_total",
+		Help: "Handler panics recovered by the HTTP recovery middleware or gRPC recovery interceptor.",
+	}, []string{"transport"})
+
+	serviceDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "toolbridge_service_operation_duration_seconds",
+		Help:    "Service-layer operation latency by entity and operation (push_item, pull_page, mutation), shared by HTTP, REST and gRPC.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"entity", "operation"})
+
+	integrityOrphans = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "toolbridge_integrity_orphans",
+		Help: "Orphaned children found by the last integrity check (comments/chat_messages with a missing or deleted parent, tasks in a missing list, pins on a missing item).",
+	}, []string{"entity"})
+
+	integrityRepaired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "toolbridge_integrity_repaired_total",
+		Help: "Orphaned children repaired by the integrity job (tombstoned, or detached from a missing task list).",
+	}, []string{"entity"})
+
+	jobLeader = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "toolbridge_job_leader",
+		Help: "1 while this replica holds the leader lock for a singleton background job, else 0.",
+	}, []string{"job"})
+
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "toolbridge_webhook_delivery_attempts_total",
+		Help: "Webhook delivery attempts by result: succeeded, retry (failed, will retry) or failed (gave up).",
+	}, []string{"result"})
+)
+
+// Service operations (operation label of toolbridge_service_operation_duration_seconds)
+// A mutation includes the push_item it performs, plus the REST read-modify-write and commit.
+const (
+	OpPushItem = "push_item"
+	OpPullPage = "pull_page"
+	OpMutation = "mutation"
+)
+
+// Handler serves the Prometheus scrape endpoint
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// PushRecorder accumulates per-item outcomes for one push batch
+// Counts are only flushed as applied/conflict when the transaction commits;
+// a rolled-back batch counts every item as an error.
+type PushRecorder struct {
+	transport string
+	entity    string
+	items     int
+	start     time.Time
+	applied   int
+	conflicts int
+	errors    int
+	committed bool
+	streaming bool // Batch size unknown up front; items are counted as they are acked
+}
+
+// StartPush begins recording a push batch
+// Usage:
+//
+//	rec := metrics.StartPush(metrics.TransportHTTP, "notes", len(req.Items))
+//	defer rec.Finish()
+func StartPush(transport, entity string, items int) *PushRecorder {
+	pushBatchSize.WithLabelValues(transport, entity).Observe(float64(items))
+	return &PushRecorder{
+		transport: transport,
+		entity:    entity,
+		items:     items,
+		start:     time.Now(),
+	}
+}
+
+// StartStreamingPush begins recording a push batch that is decoded as it is
+// applied, so its size is only known (and observed) at Finish
+func StartStreamingPush(transport, entity string) *PushRecorder {
+	return &PushRecorder{
+		transport: transport,
+		entity:    entity,
+		start:     time.Now(),
+		streaming: true,
+	}
+}
+
+// Ack records the outcome of a single item
+// applied is false for idempotent re-pushes and stale (older) updates that LWW rejected
+func (p *PushRecorder) Ack(errMsg string, applied bool) {
+	if p.streaming {
+		p.items++
+	}
+	switch {
+	case errMsg != "":
+		p.errors++
+	case applied:
+		p.applied++
+	default:
+		p.conflicts++
+	}
+}
+
+// Conflicts returns the number of items LWW rejected so far
+func (p *PushRecorder) Conflicts() int {
+	return p.conflicts
+}
+
+// Commit marks the batch transaction as committed
+func (p *PushRecorder) Commit() {
+	p.committed = true
+}
+
+// Finish observes the transaction duration and flushes item counts
+func (p *PushRecorder) Finish() {
+	outcome := "rollback"
+	if p.committed {
+		outcome = "commit"
+	}
+	pushTxDuration.WithLabelValues(p.transport, p.entity, outcome).Observe(time.Since(p.start).Seconds())
+	if p.streaming {
+		pushBatchSize.WithLabelValues(p.transport, p.entity).Observe(float64(p.items))
+	}
+
+	if !p.committed {
+		pushItems.WithLabelValues(p.transport, p.entity, "error").Add(float64(p.items))
+		return
+	}
+	pushItems.WithLabelValues(p.transport, p.entity, "applied").Add(float64(p.applied))
+	pushItems.WithLabelValues(p.transport, p.entity, "conflict").Add(float64(p.conflicts))
+	pushItems.WithLabelValues(p.transport, p.entity, "error").Add(float64(p.errors))
+}
+
+// TimeOperation starts timing a service-layer operation; call the returned func when it finishes
+// Usage:
+//
+//	defer metrics.TimeOperation("comments", metrics.OpPushItem)()
+func TimeOperation(entity, operation string) func() {
+	start := time.Now()
+	return func() {
+		serviceDuration.WithLabelValues(entity, operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObservePull records the items returned by one pull page
+func ObservePull(transport, entity string, upserts, tombstones int) {
+	pullItems.WithLabelValues(transport, entity, "upsert").Add(float64(upserts))
+	pullItems.WithLabelValues(transport, entity, "tombstone").Add(float64(tombstones))
+}
+
+// ObservePanic records a recovered handler panic
+func ObservePanic(transport string) {
+	panics.WithLabelValues(transport).Inc()
+}
+
+// ObserveIntegrity records one integrity check result for an entity
+func ObserveIntegrity(entity string, orphans, repaired int64) {
+	integrityOrphans.WithLabelValues(entity).Set(float64(orphans))
+	integrityRepaired.WithLabelValues(entity).Add(float64(repaired))
+}
+
+// SetJobLeader records whether this replica currently leads a singleton job
+func SetJobLeader(job string, leader bool) {
+	v := 0.0
+	if leader {
+		v = 1
+	}
+	jobLeader.WithLabelValues(job).Set(v)
+}
+
+// ObserveWebhookDelivery records one webhook delivery attempt
+func ObserveWebhookDelivery(result string) {
+	webhookDeliveries.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testAck struct {
+	err     string
+	applied bool
+}
+
+func TestPushRecorder(t *testing.T) {
+	tests := []struct {
+		name     string
+		entity   string
+		acks     []testAck
+		commit   bool
+		applied  float64
+		conflict float64
+		errors   float64
+	}{
+		{
+			name:   "committed batch",
+			entity: "test_committed",
+			acks: []testAck{
+				{"", true}, {"", true}, {"", false}, {"invalid uid", false},
+			},
+			commit:   true,
+			applied:  2,
+			conflict: 1,
+			errors:   1,
+		},
+		{
+			name:   "rolled back batch counts every item as error",
+			entity: "test_rollback",
+			acks: []testAck{
+				{"", true}, {"", false},
+			},
+			commit: false,
+			errors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := StartPush(TransportHTTP, tt.entity, len(tt.acks))
+			for _, a := range tt.acks {
+				rec.Ack(a.err, a.applied)
+			}
+			if tt.commit {
+				rec.Commit()
+			}
+			rec.Finish()
+
+			for result, want := range map[string]float64{"applied": tt.applied, "conflict": tt.conflict, "error": tt.errors} {
+				got := testutil.ToFloat64(pushItems.WithLabelValues(TransportHTTP, tt.entity, result))
+				if got != want {
+					t.Errorf("%s items = %v, want %v", result, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamingPushRecorder(t *testing.T) {
+	before := testutil.CollectAndCount(pushBatchSize)
+	rec := StartStreamingPush(TransportHTTP, "test_streaming")
+	rec.Ack("", true)
+	rec.Ack("", false)
+	rec.Ack("invalid uid", false)
+	rec.Finish() // Not committed: every streamed item counts as an error
+
+	if got := testutil.ToFloat64(pushItems.WithLabelValues(TransportHTTP, "test_streaming", "error")); got != 3 {
+		t.Errorf("error items = %v, want 3", got)
+	}
+	if after := testutil.CollectAndCount(pushBatchSize); after != before+1 {
+		t.Errorf("batch size series = %d, want %d", after, before+1)
+	}
+}
+
+func TestObservePull(t *testing.T) {
+	ObservePull(TransportGRPC, "test_pull", 3, 2)
+
+	if got := testutil.ToFloat64(pullItems.WithLabelValues(TransportGRPC, "test_pull", "upsert")); got != 3 {
+		t.Errorf("upserts = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(pullItems.WithLabelValues(TransportGRPC, "test_pull", "tombstone")); got != 2 {
+		t.Errorf("tombstones = %v, want 2", got)
+	}
+}
+
+func TestTimeOperation(t *testing.T) {
+	before := testutil.CollectAndCount(serviceDuration)
+	TimeOperation("test_timed", OpPushItem)()
+	TimeOperation("test_timed", OpPullPage)()
+
+	if got := testutil.CollectAndCount(serviceDuration); got != before+2 {
+		t.Errorf("series = %d, want %d (one per entity/operation)", got, before+2)
+	}
+}
@@ -0,0 +1,282 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Notification kinds (triggers)
+const (
+	KindReminderDue  = "reminder_due"
+	KindTaskAssigned = "task_assigned"
+	KindAccountWipe  = "account_wipe"
+)
+
+// kindColumns maps each kind to its opt-out column in notification_preference
+var kindColumns = map[string]string{
+	KindReminderDue:  "reminder_due",
+	KindTaskAssigned: "task_assigned",
+	KindAccountWipe:  "account_wipe",
+}
+
+// ValidationError describes invalid preferences
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Preferences are a user's notification settings
+// Email empty means notifications are off.
+type Preferences struct {
+	Email        string    `json:"email"`
+	ReminderDue  bool      `json:"reminderDue"`
+	TaskAssigned bool      `json:"taskAssigned"`
+	AccountWipe  bool      `json:"accountWipe"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// DefaultPreferences are returned for users who never saved any
+func DefaultPreferences() Preferences {
+	return Preferences{ReminderDue: true, TaskAssigned: true, AccountWipe: true}
+}
+
+// Message is one queued email
+type Message struct {
+	Kind     string
+	DedupKey string // Queues at most one email per key (e.g. "wipe:<user>:<epoch>")
+	Subject  string
+	Body     string // Plain text
+}
+
+// execer is satisfied by both pgx.Tx and *pgxpool.Pool
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Service stores preferences, queues notifications and sends them
+// A nil *Service disables notifications: Enqueue is a no-op.
+type Service struct {
+	DB          *pgxpool.Pool
+	Sender      Sender
+	MaxAttempts int           // Attempts before a notification is marked failed
+	RetryDelay  time.Duration // Delay between attempts
+	Lease       time.Duration // How long a claimed notification is hidden from other workers
+	Retention   time.Duration // Finished notifications older than this are deleted
+	// ReminderGrace bounds how late a reminder may still be emailed (covers
+	// missed scans without mailing reminders that were long past when created).
+	ReminderGrace time.Duration
+}
+
+// NewService creates a notification service that sends through sender
+func NewService(db *pgxpool.Pool, sender Sender) *Service {
+	return &Service{
+		DB:            db,
+		Sender:        sender,
+		MaxAttempts:   5,
+		RetryDelay:    5 * time.Minute,
+		Lease:         2 * time.Minute,
+		Retention:     30 * 24 * time.Hour,
+		ReminderGrace: 15 * time.Minute,
+	}
+}
+
+// GetPreferences returns the user's preferences (defaults if never saved)
+func (s *Service) GetPreferences(ctx context.Context, userID string) (Preferences, error) {
+	p := DefaultPreferences()
+	var email *string
+	err := s.DB.QueryRow(ctx, `
+		SELECT email, reminder_due, task_assigned, account_wipe, updated_at
+		FROM notification_preference WHERE owner_id = $1
+	`, userID).Scan(&email, &p.ReminderDue, &p.TaskAssigned, &p.AccountWipe, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if email != nil {
+		p.Email = *email
+	}
+	return p, nil
+}
+
+// SavePreferences validates and stores the user's preferences
+func (s *Service) SavePreferences(ctx context.Context, userID string, p Preferences) (Preferences, error) {
+	var email *string
+	if p.Email != "" {
+		addr, err := mail.ParseAddress(p.Email)
+		if err != nil || addr.Name != "" {
+			return p, &ValidationError{Msg: "invalid email address"}
+		}
+		email = &addr.Address
+	}
+	err := s.DB.QueryRow(ctx, `
+		INSERT INTO notification_preference (owner_id, email, reminder_due, task_assigned, account_wipe)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_id) DO UPDATE SET
+			email = excluded.email,
+			reminder_due = excluded.reminder_due,
+			task_assigned = excluded.task_assigned,
+			account_wipe = excluded.account_wipe,
+			updated_at = now()
+		RETURNING updated_at
+	`, userID, email, p.ReminderDue, p.TaskAssigned, p.AccountWipe).Scan(&p.UpdatedAt)
+	return p, err
+}
+
+// Enqueue queues an email to the user if they have an address and haven't
+// opted out of msg.Kind
+// Pass the caller's transaction to queue the email only if the trigger commits.
+func (s *Service) Enqueue(ctx context.Context, db execer, userID string, msg Message) error {
+	if s == nil {
+		return nil
+	}
+	col, ok := kindColumns[msg.Kind]
+	if !ok {
+		return fmt.Errorf("unknown notification kind %q", msg.Kind)
+	}
+	_, err := db.Exec(ctx, `
+		INSERT INTO notification (owner_id, kind, dedup_key, to_address, subject, body)
+		SELECT owner_id, $2, $3, email, $4, $5
+		FROM notification_preference
+		WHERE owner_id = $1 AND email IS NOT NULL AND `+col+`
+		ON CONFLICT (dedup_key) DO NOTHING
+	`, userID, msg.Kind, msg.DedupKey, msg.Subject, msg.Body)
+	return err
+}
+
+// Run sends queued notifications and scans for due reminders until ctx is cancelled
+// Safe to run on every replica: notifications are claimed with FOR UPDATE SKIP
+// LOCKED, and reminders are deduplicated per task and reminder time.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.scanReminders(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("reminder scan failed")
+		}
+		for {
+			n, err := s.sendBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("notification send failed")
+			}
+			if n == 0 || err != nil || ctx.Err() != nil {
+				break
+			}
+		}
+		if _, err := s.DB.Exec(ctx, `
+			DELETE FROM notification WHERE status <> 'pending' AND created_at < now() - $1::interval
+		`, fmt.Sprintf("%d seconds", int64(s.Retention.Seconds()))); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("notification cleanup failed")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendBatch claims and sends up to 20 due notifications
+// Returns how many were claimed.
+func (s *Service) sendBatch(ctx context.Context) (int, error) {
+	rows, err := s.DB.Query(ctx, `
+		UPDATE notification SET next_attempt_at = now() + $1::interval
+		WHERE id IN (
+			SELECT id FROM notification
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT 20
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id::text, kind, to_address, subject, body, attempts
+	`, fmt.Sprintf("%d seconds", int64(s.Lease.Seconds())))
+	if err != nil {
+		return 0, err
+	}
+	type claimed struct {
+		id, kind, to, subject, body string
+		attempts                    int
+	}
+	var batch []claimed
+	for rows.Next() {
+		var c claimed
+		if err := rows.Scan(&c.id, &c.kind, &c.to, &c.subject, &c.body, &c.attempts); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range batch {
+		if ctx.Err() != nil {
+			break // Unsent notifications are retried when the lease expires
+		}
+		attempts := c.attempts + 1
+		sendErr := s.Sender.Send(ctx, c.to, c.subject, c.body)
+		switch {
+		case sendErr == nil:
+			_, err = s.DB.Exec(ctx, `
+				UPDATE notification SET status = 'sent', attempts = $2, last_error = NULL, sent_at = now() WHERE id = $1
+			`, c.id, attempts)
+		case attempts >= s.MaxAttempts:
+			log.Warn().Err(sendErr).Str("notificationId", c.id).Str("kind", c.kind).Msg("notification failed permanently")
+			_, err = s.DB.Exec(ctx, `
+				UPDATE notification SET status = 'failed', attempts = $2, last_error = $3 WHERE id = $1
+			`, c.id, attempts, sendErr.Error())
+		default:
+			_, err = s.DB.Exec(ctx, `
+				UPDATE notification SET attempts = $2, last_error = $3, next_attempt_at = now() + $4::interval WHERE id = $1
+			`, c.id, attempts, sendErr.Error(), fmt.Sprintf("%d seconds", int64(s.RetryDelay.Seconds())))
+		}
+		if err != nil {
+			return len(batch), err
+		}
+	}
+	return len(batch), nil
+}
+
+// WipeMessage builds the account wipe email
+// Keyed by the new epoch, so a wipe is reported once.
+func WipeMessage(userID string, epoch int, deleted map[string]int) Message {
+	tables := make([]string, 0, len(deleted))
+	for table := range deleted {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var b strings.Builder
+	b.WriteString("All synced data in your ToolBridge account was permanently deleted.\n\n")
+	for _, table := range tables {
+		fmt.Fprintf(&b, "  %s: %d\n", table, deleted[table])
+	}
+	b.WriteString("\nEvery device was signed out of sync and will start over on its next sync.\n")
+	b.WriteString("If you did not request this, secure your account immediately.\n")
+	return Message{
+		Kind:     KindAccountWipe,
+		DedupKey: "wipe:" + userID + ":" + strconv.Itoa(epoch),
+		Subject:  "Your ToolBridge data was wiped",
+		Body:     b.String(),
+	}
+}
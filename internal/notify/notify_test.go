@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueReminders(t *testing.T) {
+	now := time.Date(2025, 12, 15, 16, 30, 0, 0, time.UTC)
+	payload := map[string]any{
+		"title":   "Ship release",
+		"dueDate": "2025-12-15T17:00:00Z",
+		"reminders": []any{
+			map[string]any{"minutesBefore": float64(30)}, // 16:30 - due now
+			map[string]any{"minutesBefore": float64(10)}, // 16:50 - not yet
+			"2025-12-15T16:20:00Z",                       // within grace
+			"2025-12-15T09:00:00Z",                       // long past
+		},
+	}
+	got := dueReminders(payload, now, 15*time.Minute)
+	if len(got) != 2 {
+		t.Fatalf("dueReminders = %v, want 16:30 and 16:20", got)
+	}
+
+	a := reminderMessage("u1", "t1", payload, got[0])
+	b := reminderMessage("u1", "t1", payload, got[1])
+	if a.DedupKey == b.DedupKey {
+		t.Error("each reminder time needs its own dedup key")
+	}
+	if a.Kind != KindReminderDue || !strings.Contains(a.Subject, "Ship release") {
+		t.Errorf("unexpected reminder message: %+v", a)
+	}
+}
+
+func TestWipeMessage(t *testing.T) {
+	m := WipeMessage("u1", 3, map[string]int{"task": 2, "note": 5})
+	if m.Kind != KindAccountWipe || m.DedupKey != "wipe:u1:3" {
+		t.Errorf("unexpected wipe message: %+v", m)
+	}
+	if strings.Index(m.Body, "note: 5") > strings.Index(m.Body, "task: 2") {
+		t.Error("deleted counts should be listed in table order")
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg := string(buildMessage("a@example.com", "b@example.com", "Réminder\nBcc: x@example.com", "one\ntwo", time.Unix(0, 0)))
+	if strings.Contains(msg, "\nBcc:") {
+		t.Errorf("subject newline leaked into headers:\n%s", msg)
+	}
+	if !strings.HasSuffix(msg, "\r\n\r\none\r\ntwo") {
+		t.Errorf("body not CRLF-normalized:\n%q", msg)
+	}
+}
+
+func TestSavePreferencesRejectsInvalidEmail(t *testing.T) {
+	s := NewService(nil, LogSender{})
+	for _, email := range []string{"not-an-email", "Name <a@example.com>"} {
+		_, err := s.SavePreferences(context.Background(), "u1", Preferences{Email: email})
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("SavePreferences(%q) = %v, want ValidationError", email, err)
+		}
+	}
+}
+
+func TestEnqueueNilServiceIsNoop(t *testing.T) {
+	var s *Service
+	if err := s.Enqueue(context.Background(), nil, "u1", Message{Kind: KindAccountWipe}); err != nil {
+		t.Fatal(err)
+	}
+}
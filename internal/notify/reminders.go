@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/calendar"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// dueReminders returns the reminder times of payload that fell in (now-grace, now]
+func dueReminders(payload map[string]any, now time.Time, grace time.Duration) []time.Time {
+	var out []time.Time
+	for _, at := range calendar.ReminderTimes(payload) {
+		if at.After(now.Add(-grace)) && !at.After(now) {
+			out = append(out, at)
+		}
+	}
+	return out
+}
+
+// reminderMessage builds the email for one task reminder
+func reminderMessage(userID, uid string, payload map[string]any, at time.Time) Message {
+	title, _ := syncx.GetString(payload, "title")
+	if title == "" {
+		title = "Untitled task"
+	}
+	body := "Reminder: " + title + "\n"
+	if due, ok := syncx.GetString(payload, "dueDate"); ok && due != "" {
+		body += "Due: " + due + "\n"
+	}
+	if desc, ok := syncx.GetString(payload, "description"); ok && desc != "" {
+		body += "\n" + desc + "\n"
+	}
+	return Message{
+		Kind:     KindReminderDue,
+		DedupKey: "reminder:" + userID + ":" + uid + ":" + strconv.FormatInt(at.Unix(), 10),
+		Subject:  "Reminder: " + title,
+		Body:     body,
+	}
+}
+
+// scanReminders queues an email for every task reminder that came due within
+// ReminderGrace, for users who have an address and reminders enabled
+func (s *Service) scanReminders(ctx context.Context, now time.Time) error {
+	rows, err := s.DB.Query(ctx, `
+		SELECT t.owner_id::text, t.uid::text, t.payload_json
+		FROM task t
+		JOIN notification_preference p ON p.owner_id = t.owner_id
+		WHERE p.email IS NOT NULL AND p.reminder_due
+		  AND t.deleted_at_ms IS NULL AND t.payload_json ? 'reminders'
+	`)
+	if err != nil {
+		return err
+	}
+	type queued struct {
+		userID string
+		msg    Message
+	}
+	var due []queued
+	for rows.Next() {
+		var userID, uid string
+		var raw []byte
+		if err := rows.Scan(&userID, &uid, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		var payload map[string]any
+		if json.Unmarshal(raw, &payload) != nil {
+			continue
+		}
+		for _, at := range dueReminders(payload, now, s.ReminderGrace) {
+			due = append(due, queued{userID, reminderMessage(userID, uid, payload, at)})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		if err := s.Enqueue(ctx, s.DB, d.userID, d.msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
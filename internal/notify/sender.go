@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Sender delivers one plain-text email
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPConfig configures SMTPSender
+type SMTPConfig struct {
+	Addr     string // host:port (587 uses STARTTLS when offered)
+	Username string // Optional; PLAIN auth (requires TLS unless the host is localhost)
+	Password string
+	From     string // Envelope and header sender
+}
+
+// SESSMTPAddr returns the Amazon SES SMTP endpoint for a region
+// SES is used through its SMTP interface with SMTP credentials from the SES console.
+func SESSMTPAddr(region string) string {
+	return "email-smtp." + region + ".amazonaws.com:587"
+}
+
+// SMTPSender sends through an SMTP relay (Postfix, SES, SendGrid, ...)
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender creates an SMTP sender
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// buildMessage renders RFC 5322 headers and a plain-text body
+func buildMessage(from, to, subject, body string, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// Send implements Sender
+// net/smtp has no context support; the relay's own timeouts bound the call.
+func (s *SMTPSender) Send(_ context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(s.cfg.Addr)
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+	msg := buildMessage(s.cfg.From, to, subject, body, time.Now())
+	return smtp.SendMail(s.cfg.Addr, auth, s.cfg.From, []string{to}, msg)
+}
+
+// LogSender logs emails instead of sending them (dev mode without SMTP)
+type LogSender struct{}
+
+// Send implements Sender
+func (LogSender) Send(_ context.Context, to, subject, body string) error {
+	log.Info().Str("to", to).Str("subject", subject).Int("bodyBytes", len(body)).Msg("notification email (not sent: no SMTP configured)")
+	return nil
+}
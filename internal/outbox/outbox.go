@@ -0,0 +1,160 @@
+// Package outbox implements the transactional outbox for change events.
+// Writers call Write inside the transaction that changes an entity; the
+// Dispatcher later hands committed events to every Publisher, at least once.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Event is one committed entity change
+// ID is the dedup key: it is the same on every (re)publish of the event.
+type Event struct {
+	ID          string
+	OwnerID     string
+	Entity      string // Entity table name (note, task, comment, ...)
+	UID         uuid.UUID
+	Action      string // created, updated or deleted
+	Version     int
+	UpdatedAtMs int64
+	OccurredAt  time.Time
+}
+
+// Publisher receives dispatched events
+// Publish is called with the dispatcher's transaction; publishers that write to
+// Postgres should use tx so their writes commit together with the dispatch mark.
+// Returning an error leaves the batch undispatched, and it is offered again
+// (to every publisher) on the next tick.
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, tx pgx.Tx, events []Event) error
+}
+
+// Write records an event within the caller's transaction
+func Write(ctx context.Context, tx pgx.Tx, ev Event) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO event_outbox (owner_id, entity, uid, action, version, updated_at_ms)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, ev.OwnerID, ev.Entity, ev.UID, ev.Action, ev.Version, ev.UpdatedAtMs)
+	return err
+}
+
+// Dispatcher moves events from the outbox to the publishers
+type Dispatcher struct {
+	DB         *pgxpool.Pool
+	Publishers []Publisher
+	BatchSize  int           // Events claimed per transaction
+	Retention  time.Duration // Dispatched events older than this are deleted
+
+	lastCleanup time.Time
+}
+
+// NewDispatcher creates a dispatcher with default batch size and retention
+func NewDispatcher(db *pgxpool.Pool, publishers ...Publisher) *Dispatcher {
+	return &Dispatcher{
+		DB:         db,
+		Publishers: publishers,
+		BatchSize:  100,
+		Retention:  7 * 24 * time.Hour,
+	}
+}
+
+// Run dispatches pending events until ctx is cancelled
+// Old dispatched events are deleted at most hourly.
+// Run it as a singleton job so publishers see events in outbox order; claiming
+// with FOR UPDATE SKIP LOCKED keeps concurrent dispatchers from double-sending.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	if d == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := d.dispatchBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("outbox dispatch failed; will retry")
+			}
+			if n == 0 || err != nil || ctx.Err() != nil {
+				break
+			}
+		}
+		if time.Since(d.lastCleanup) > time.Hour {
+			if _, err := d.DB.Exec(ctx, `
+				DELETE FROM event_outbox
+				WHERE dispatched_at IS NOT NULL AND dispatched_at < now() - $1::interval
+			`, fmt.Sprintf("%d seconds", int64(d.Retention.Seconds()))); err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("outbox cleanup failed")
+			}
+			d.lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatchBatch publishes one batch of pending events in a transaction
+// Returns how many events were dispatched.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	var n int
+	err := pgx.BeginFunc(ctx, d.DB, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT id, event_id::text, owner_id::text, entity, uid, action, version, updated_at_ms, created_at
+			FROM event_outbox
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		`, d.BatchSize)
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		var events []Event
+		for rows.Next() {
+			var id int64
+			var ev Event
+			if err := rows.Scan(&id, &ev.ID, &ev.OwnerID, &ev.Entity, &ev.UID, &ev.Action, &ev.Version, &ev.UpdatedAtMs, &ev.OccurredAt); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+			events = append(events, ev)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		for _, p := range d.Publishers {
+			if err := p.Publish(ctx, tx, events); err != nil {
+				return fmt.Errorf("publish to %s: %w", p.Name(), err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE event_outbox SET dispatched_at = now() WHERE id = ANY($1)`, ids); err != nil {
+			return err
+		}
+		n = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
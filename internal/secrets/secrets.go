@@ -0,0 +1,184 @@
+// Package secrets resolves secret references in configuration and re-fetches
+// them so rotated values reach the server without a restart.
+//
+// A reference is "<scheme>:<ref>". file:/path reads a mounted secret
+// (Kubernetes secret volumes, Docker secrets) and vault:<path>#<key> reads a
+// HashiCorp Vault KV secret. Other secret managers (AWS Secrets Manager or
+// KMS-encrypted blobs, GCP Secret Manager) plug in by registering a Fetcher.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Fetcher reads one secret; ref is the reference without its scheme
+type Fetcher interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+// FetcherFunc adapts a function to Fetcher
+type FetcherFunc func(ctx context.Context, ref string) (string, error)
+
+// Fetch calls f
+func (f FetcherFunc) Fetch(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// FileFetcher reads a secret file, trimming the trailing newline editors and
+// `kubectl create secret --from-file` leave behind
+var FileFetcher = FetcherFunc(func(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+})
+
+// Resolver maps reference schemes to fetchers
+// The zero value is not usable; use NewResolver.
+type Resolver struct {
+	fetchers map[string]Fetcher
+}
+
+// NewResolver returns a resolver with file: registered
+func NewResolver() *Resolver {
+	return &Resolver{fetchers: map[string]Fetcher{"file": FileFetcher}}
+}
+
+// Register adds (or replaces) the fetcher for scheme
+func (r *Resolver) Register(scheme string, f Fetcher) {
+	r.fetchers[scheme] = f
+}
+
+// IsRef reports whether value is a reference with a registered scheme
+// Plain secrets (and values like postgres:// URLs) are not references.
+func (r *Resolver) IsRef(value string) bool {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok || ref == "" {
+		return false
+	}
+	_, ok = r.fetchers[scheme]
+	return ok
+}
+
+// Resolve fetches value if it is a reference and returns it unchanged otherwise
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !r.IsRef(value) {
+		return value, nil
+	}
+	scheme, ref, _ := strings.Cut(value, ":")
+	secret, err := r.fetchers[scheme].Fetch(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s secret %s: %w", scheme, ref, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("%s secret %s is empty", scheme, ref)
+	}
+	return secret, nil
+}
+
+// watch is one reference followed by a Watcher
+type watch struct {
+	name     string // Setting name for logs (e.g. JWT_HS256_SECRET)
+	ref      string
+	value    string
+	onRotate []func(value string)
+}
+
+// Watcher re-fetches references and calls rotation callbacks when a value changes
+type Watcher struct {
+	resolver *Resolver
+
+	mu      sync.Mutex
+	watches map[string]*watch
+}
+
+// NewWatcher returns a watcher that fetches through resolver
+func NewWatcher(resolver *Resolver) *Watcher {
+	return &Watcher{resolver: resolver, watches: map[string]*watch{}}
+}
+
+// Watch follows ref, currently resolved to value, under name
+// Calling Watch again for the same name replaces the reference and keeps its callbacks.
+func (w *Watcher) Watch(name, ref, value string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if existing, ok := w.watches[name]; ok {
+		existing.ref, existing.value = ref, value
+		return
+	}
+	w.watches[name] = &watch{name: name, ref: ref, value: value}
+}
+
+// OnRotate registers fn to run with the new value when name's secret changes
+// Callbacks run on the watcher goroutine, one secret at a time.
+func (w *Watcher) OnRotate(name string, fn func(value string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if wt, ok := w.watches[name]; ok {
+		wt.onRotate = append(wt.onRotate, fn)
+	}
+}
+
+// Names returns the watched setting names
+func (w *Watcher) Names() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make([]string, 0, len(w.watches))
+	for name := range w.watches {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Check re-fetches every reference once and returns the names that rotated
+// A failed fetch keeps the current value (the secret manager may be briefly
+// unavailable) and is logged.
+func (w *Watcher) Check(ctx context.Context) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var rotated []string
+	for name, wt := range w.watches {
+		value, err := w.resolver.Resolve(ctx, wt.ref)
+		if err != nil {
+			log.Warn().Err(err).Str("secret", name).Msg("secret refresh failed; keeping current value")
+			continue
+		}
+		if value == wt.value {
+			continue
+		}
+		wt.value = value
+		rotated = append(rotated, name)
+		log.Info().Str("secret", name).Int("callbacks", len(wt.onRotate)).Msg("secret rotated")
+		for _, fn := range wt.onRotate {
+			fn(value)
+		}
+	}
+	return rotated
+}
+
+// Run checks for rotated secrets every interval until ctx is cancelled
+// Returns immediately when nothing is watched or interval is not positive.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	if w == nil || interval <= 0 || len(w.Names()) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
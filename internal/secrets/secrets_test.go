@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewResolver()
+	ctx := context.Background()
+
+	if got, err := r.Resolve(ctx, "file:"+path); err != nil || got != "s3cret" {
+		t.Errorf("file ref = %q, %v", got, err)
+	}
+	for _, plain := range []string{"s3cret", "postgres://u:p@db/x", "vault:secret/x#k", ""} {
+		if got, err := r.Resolve(ctx, plain); err != nil || got != plain {
+			t.Errorf("Resolve(%q) = %q, %v; want it unchanged", plain, got, err)
+		}
+	}
+	if _, err := r.Resolve(ctx, "file:"+path+".missing"); err == nil {
+		t.Error("missing file accepted")
+	}
+}
+
+func TestVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/toolbridge": // KV v2
+			w.Write([]byte(`{"data":{"data":{"jwt":"v2-secret","n":1},"metadata":{"version":3}}}`))
+		case "/v1/kv/toolbridge": // KV v1
+			w.Write([]byte(`{"data":{"jwt":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer vault.Close()
+
+	r := NewResolver()
+	r.Register("vault", NewVault(vault.URL, "root", ""))
+	ctx := context.Background()
+	for ref, want := range map[string]string{
+		"vault:secret/data/toolbridge#jwt": "v2-secret",
+		"vault:kv/toolbridge#jwt":          "v1-secret",
+	} {
+		if got, err := r.Resolve(ctx, ref); err != nil || got != want {
+			t.Errorf("%s = %q, %v", ref, got, err)
+		}
+	}
+	for _, bad := range []string{
+		"vault:secret/data/toolbridge#missing",
+		"vault:secret/data/toolbridge#n",
+		"vault:secret/data/other#jwt",
+		"vault:secret/data/toolbridge",
+	} {
+		if _, err := r.Resolve(ctx, bad); err == nil {
+			t.Errorf("%s accepted", bad)
+		}
+	}
+
+	r.Register("vault", NewVault(vault.URL, "wrong", ""))
+	if _, err := r.Resolve(ctx, "vault:kv/toolbridge#jwt"); err == nil {
+		t.Error("bad token accepted")
+	}
+}
+
+func TestWatcherRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	write := func(v string) {
+		if err := os.WriteFile(path, []byte(v), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("one")
+
+	w := NewWatcher(NewResolver())
+	w.Watch("KEY", "file:"+path, "one")
+	var got []string
+	w.OnRotate("KEY", func(v string) { got = append(got, v) })
+	w.OnRotate("UNKNOWN", func(string) { t.Error("callback for unwatched name") })
+
+	ctx := context.Background()
+	if rotated := w.Check(ctx); len(rotated) != 0 {
+		t.Errorf("unchanged secret reported rotated: %v", rotated)
+	}
+	write("two")
+	if rotated := w.Check(ctx); len(rotated) != 1 || len(got) != 1 || got[0] != "two" {
+		t.Errorf("rotated %v, callbacks %v", rotated, got)
+	}
+
+	// A failed fetch keeps the current value and fires no callback
+	os.Remove(path)
+	w.Check(ctx)
+	write("two")
+	if w.Check(ctx); len(got) != 1 {
+		t.Errorf("callbacks after failed fetch = %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Vault reads KV secrets from HashiCorp Vault
+// References are "<mount path>#<key>", e.g. vault:secret/data/toolbridge#jwt_hs256_secret
+// for KV v2 (note the /data/ segment) or vault:kv/toolbridge#jwt_hs256_secret for KV v1.
+type Vault struct {
+	Addr      string // e.g. https://vault.internal:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace (optional)
+	Client    *http.Client
+}
+
+// NewVault returns a Vault fetcher with a 10s request timeout
+func NewVault(addr, token, namespace string) *Vault {
+	return &Vault{
+		Addr:      strings.TrimRight(addr, "/"),
+		Token:     token,
+		Namespace: namespace,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultResponse is the subset of a Vault read response we use
+// KV v2 nests the secret under data.data; KV v1 returns it as data.
+type vaultResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []string       `json:"errors"`
+}
+
+// Fetch reads one key of a KV secret
+func (v *Vault) Fetch(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("vault reference must be <path>#<key>")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.Addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %d %s", resp.StatusCode, strings.Join(body.Errors, "; "))
+	}
+
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nested
+		}
+	}
+	switch value := data[key].(type) {
+	case string:
+		return value, nil
+	case nil:
+		return "", fmt.Errorf("key %q not found", key)
+	default:
+		return "", fmt.Errorf("key %q is not a string", key)
+	}
+}
@@ -0,0 +1,187 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Activity actions
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ChangeSource identifies who made a change (recorded in the activity log)
+type ChangeSource struct {
+	SessionID string // X-Sync-Session (empty if none)
+	DeviceID  string // Optional X-Device-ID
+}
+
+type changeSourceKey struct{}
+
+// WithChangeSource attaches the writer's session/device to ctx
+// Set by the HTTP session middleware and gRPC session interceptor.
+func WithChangeSource(ctx context.Context, src ChangeSource) context.Context {
+	return context.WithValue(ctx, changeSourceKey{}, src)
+}
+
+// ChangeSourceFrom returns the writer's session/device from ctx
+func ChangeSourceFrom(ctx context.Context) ChangeSource {
+	src, _ := ctx.Value(changeSourceKey{}).(ChangeSource)
+	return src
+}
+
+// nullIfEmpty maps "" to SQL NULL
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// recordActivity appends an applied write to the activity log within tx
+// and writes the change to the event outbox.
+// inserted reports whether the upsert created the row; deleted whether the
+// resulting row is a tombstone.
+func recordActivity(ctx context.Context, tx pgx.Tx, userID, entity string, uid uuid.UUID, version int, updatedAtMs int64, inserted, deleted bool) error {
+	action := ActionUpdated
+	switch {
+	case deleted:
+		action = ActionDeleted
+	case inserted:
+		action = ActionCreated
+	}
+
+	src := ChangeSourceFrom(ctx)
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO activity_log (owner_id, entity, uid, action, version, updated_at_ms, session_id, device_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, userID, entity, uid, action, version, updatedAtMs, nullIfEmpty(src.SessionID), nullIfEmpty(src.DeviceID)); err != nil {
+		return err
+	}
+
+	// Outbox event for webhooks and other publishers (same transaction)
+	return outbox.Write(ctx, tx, outbox.Event{
+		OwnerID:     userID,
+		Entity:      entity,
+		UID:         uid,
+		Action:      action,
+		Version:     version,
+		UpdatedAtMs: updatedAtMs,
+	})
+}
+
+// ActivityEntry is one change in the activity feed
+type ActivityEntry struct {
+	Entity    string  `json:"entity"`
+	UID       string  `json:"uid"`
+	Action    string  `json:"action"`
+	Version   int     `json:"version"`
+	UpdatedAt string  `json:"updatedAt"` // Entity timestamp after the change
+	ChangedAt string  `json:"changedAt"` // Server time the change was recorded
+	SessionID *string `json:"sessionId,omitempty"`
+	DeviceID  *string `json:"deviceId,omitempty"`
+}
+
+// ActivityFeed is a page of the activity feed (newest first)
+type ActivityFeed struct {
+	Items      []ActivityEntry `json:"items"`
+	NextCursor *string         `json:"nextCursor,omitempty"`
+}
+
+// ActivityService reads the per-user activity log
+type ActivityService struct {
+	DB *pgxpool.Pool
+}
+
+// NewActivityService creates a new ActivityService
+func NewActivityService(db *pgxpool.Pool) *ActivityService {
+	return &ActivityService{DB: db}
+}
+
+// EncodeActivityCursor creates an opaque cursor from an activity log id
+func EncodeActivityCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// DecodeActivityCursor parses a cursor from EncodeActivityCursor
+// Returns false if invalid or empty
+func DecodeActivityCursor(s string) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// ListActivity returns a page of the user's changes, newest first
+// beforeID (from a cursor) resumes after the previous page; 0 starts at the newest change.
+// since, if non-zero, stops the feed at changes recorded at or after that time.
+func (s *ActivityService) ListActivity(ctx context.Context, userID string, beforeID int64, since time.Time, limit int) (*ActivityFeed, error) {
+	logger := log.With().Logger()
+
+	var sinceArg *time.Time
+	if !since.IsZero() {
+		sinceArg = &since
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT id, entity, uid::text, action, version, updated_at_ms, created_at, session_id, device_id
+		FROM activity_log
+		WHERE owner_id = $1
+		  AND ($2::bigint = 0 OR id < $2)
+		  AND ($3::timestamptz IS NULL OR created_at >= $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`, userID, beforeID, sinceArg, limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to query activity log")
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]ActivityEntry, 0, limit)
+	var lastID int64
+	for rows.Next() {
+		var e ActivityEntry
+		var ms int64
+		var changedAt time.Time
+		if err := rows.Scan(&lastID, &e.Entity, &e.UID, &e.Action, &e.Version, &ms, &changedAt, &e.SessionID, &e.DeviceID); err != nil {
+			logger.Error().Err(err).Msg("failed to scan activity row")
+			return nil, err
+		}
+		e.UpdatedAt = syncx.RFC3339(ms)
+		e.ChangedAt = changedAt.UTC().Format(time.RFC3339Nano)
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error().Err(err).Msg("row iteration error")
+		return nil, err
+	}
+
+	// A full page may have more behind it
+	var nextCursor *string
+	if len(items) == limit {
+		encoded := EncodeActivityCursor(lastID)
+		nextCursor = &encoded
+	}
+
+	return &ActivityFeed{Items: items, NextCursor: nextCursor}, nil
+}
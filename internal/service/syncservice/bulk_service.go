@@ -0,0 +1,271 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxBulkItems caps how many items one bulk update writes; repeating the
+// request continues with the rest
+const MaxBulkItems = 1000
+
+// ErrInvalidBulk wraps the reason a bulk update request was rejected
+var ErrInvalidBulk = errors.New("invalid bulk update")
+
+// Bulk actions
+const (
+	BulkAddTag    = "add_tag"    // Value: the tag
+	BulkRemoveTag = "remove_tag" // Value: the tag
+	BulkMove      = "move"       // Value: the taskListUid, "" for no list (tasks only)
+	BulkSetStatus = "set_status" // Value: the status
+	BulkDelete    = "delete"     // Soft delete
+)
+
+// bulkStatuses are the statuses set_status accepts, per type
+var bulkStatuses = map[string][]string{
+	"notes": {"active", "archived"},
+	"tasks": {"open", "in_progress", "completed", "archived"},
+}
+
+// BulkFilter selects live items; all set criteria must match
+type BulkFilter struct {
+	UIDs          []string   `json:"uids,omitempty"`
+	Status        []string   `json:"status,omitempty"`      // Any of these statuses
+	Tag           string     `json:"tag,omitempty"`         // Has this tag
+	TaskListUID   *string    `json:"taskListUid,omitempty"` // In this list ("": in none); tasks only
+	UpdatedBefore *time.Time `json:"updatedBefore,omitempty"`
+}
+
+// BulkRequest is one action applied to every item matching Filter
+type BulkRequest struct {
+	Filter BulkFilter `json:"filter"`
+	Action string     `json:"action"`
+	Value  string     `json:"value,omitempty"`
+	DryRun bool       `json:"dryRun,omitempty"` // Count what would change without writing
+}
+
+// BulkResult describes an applied (or dry-run) bulk update
+type BulkResult struct {
+	Updated int  `json:"updated"` // Items written (would be, for a dry run)
+	More    bool `json:"more"`    // More than MaxBulkItems matched: repeat the request
+}
+
+// BulkService applies one mutation to a filtered set of notes or tasks in a
+// single transaction. Each item goes through the push path as a server-side
+// write with a fresh timestamp, so devices pull ordinary LWW updates and
+// activity, links and revisions are recorded as for any other edit. Items the
+// action wouldn't change are never matched, so a repeated request continues
+// where a capped one stopped.
+type BulkService struct {
+	DB *pgxpool.Pool
+
+	types map[string]bulkType
+}
+
+// bulkType is an entity bulk updates apply to, by its sync path name
+type bulkType struct {
+	table string
+	push  func(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck
+}
+
+// NewBulkService creates a new BulkService
+func NewBulkService(db *pgxpool.Pool) *BulkService {
+	notes, tasks := NewNoteService(db), NewTaskService(db)
+	return &BulkService{
+		DB: db,
+		types: map[string]bulkType{
+			"notes": {"note", notes.PushNoteItem},
+			"tasks": {"task", tasks.PushTaskItem},
+		},
+	}
+}
+
+// Type reports whether name is a type bulk updates apply to (notes, tasks)
+func (s *BulkService) Type(name string) bool {
+	_, ok := s.types[name]
+	return ok
+}
+
+// bulkQuery accumulates the WHERE conditions and args of the selection
+type bulkQuery struct {
+	where string
+	args  []any
+}
+
+func (q *bulkQuery) add(cond string, arg any) {
+	q.args = append(q.args, arg)
+	q.where += " AND " + fmt.Sprintf(cond, "$"+strconv.Itoa(len(q.args)))
+}
+
+// where validates req (canonicalizing its value) and builds the condition
+// selecting the items it changes: matching the filter and not already in the
+// action's target state
+func (s *BulkService) where(ctx context.Context, tx pgx.Tx, userID, typ string, req *BulkRequest) (*bulkQuery, error) {
+	f := req.Filter
+	if len(f.UIDs) == 0 && len(f.Status) == 0 && f.Tag == "" && f.TaskListUID == nil && f.UpdatedBefore == nil {
+		return nil, fmt.Errorf("%w: filter must set at least one of uids, status, tag, taskListUid, updatedBefore", ErrInvalidBulk)
+	}
+	if (f.TaskListUID != nil || req.Action == BulkMove) && typ != "tasks" {
+		return nil, fmt.Errorf("%w: taskListUid and move apply to tasks only", ErrInvalidBulk)
+	}
+
+	q := &bulkQuery{where: "owner_id = $1 AND deleted_at_ms IS NULL", args: []any{userID}}
+	if len(f.UIDs) > 0 {
+		for _, uid := range f.UIDs {
+			if _, err := uuid.Parse(uid); err != nil {
+				return nil, fmt.Errorf("%w: invalid uid %q", ErrInvalidBulk, uid)
+			}
+		}
+		q.add("uid = ANY(%s::uuid[])", f.UIDs)
+	}
+	if len(f.Status) > 0 {
+		q.add("coalesce(payload_json->>'status', '') = ANY(%s)", f.Status)
+	}
+	if f.Tag != "" {
+		q.add("coalesce(payload_json->'tags', '[]') ? %s", f.Tag)
+	}
+	if f.TaskListUID != nil {
+		q.add("coalesce(lower(payload_json->>'taskListUid'), '') = lower(%s)", *f.TaskListUID)
+	}
+	if f.UpdatedBefore != nil {
+		q.add("updated_at_ms < %s", f.UpdatedBefore.UnixMilli())
+	}
+
+	switch req.Action {
+	case BulkAddTag, BulkRemoveTag:
+		if req.Value == "" {
+			return nil, fmt.Errorf("%w: value must be the tag", ErrInvalidBulk)
+		}
+		if req.Action == BulkAddTag {
+			q.add("NOT coalesce(payload_json->'tags', '[]') ? %s", req.Value)
+		} else {
+			q.add("coalesce(payload_json->'tags', '[]') ? %s", req.Value)
+		}
+	case BulkMove:
+		if req.Value != "" {
+			list, err := uuid.Parse(req.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: value must be a taskListUid", ErrInvalidBulk)
+			}
+			var live bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM task_list WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL)`,
+				userID, list.String()).Scan(&live); err != nil {
+				return nil, err
+			}
+			if !live {
+				return nil, fmt.Errorf("%w: task list %s not found", ErrInvalidBulk, list)
+			}
+			req.Value = list.String()
+		}
+		q.add("coalesce(lower(payload_json->>'taskListUid'), '') <> %s", req.Value)
+	case BulkSetStatus:
+		if !slices.Contains(bulkStatuses[typ], req.Value) {
+			return nil, fmt.Errorf("%w: value must be one of %v", ErrInvalidBulk, bulkStatuses[typ])
+		}
+		q.add("coalesce(payload_json->>'status', '') <> %s", req.Value)
+	case BulkDelete:
+	default:
+		return nil, fmt.Errorf("%w: action must be add_tag, remove_tag, move, set_status or delete", ErrInvalidBulk)
+	}
+	return q, nil
+}
+
+// Apply applies req to the user's matching items of one type (at most
+// MaxBulkItems, oldest change first), all or nothing
+func (s *BulkService) Apply(ctx context.Context, userID, typ string, req BulkRequest) (*BulkResult, error) {
+	t := s.types[typ]
+	res := &BulkResult{}
+
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		q, err := s.where(ctx, tx, userID, typ, &req)
+		if err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `
+			SELECT payload_json, updated_at_ms FROM `+t.table+`
+			WHERE `+q.where+`
+			ORDER BY updated_at_ms, uid
+			LIMIT `+strconv.Itoa(MaxBulkItems+1)+`
+			FOR UPDATE
+		`, q.args...)
+		if err != nil {
+			return err
+		}
+		type item struct {
+			payload map[string]any
+			ms      int64
+		}
+		var items []item
+		for rows.Next() {
+			var it item
+			if err := rows.Scan(&it.payload, &it.ms); err != nil {
+				rows.Close()
+				return err
+			}
+			items = append(items, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(items) > MaxBulkItems {
+			items, res.More = items[:MaxBulkItems], true
+		}
+		if req.DryRun {
+			res.Updated = len(items)
+			return nil
+		}
+
+		for _, it := range items {
+			applyBulkAction(it.payload, typ, req)
+			mutated := syncx.BuildServerMutation(it.payload, syncx.EnsureMonotonicTimestamp(it.ms), req.Action == BulkDelete)
+			if ack := t.push(ctx, tx, userID, mutated); ack.Error != "" {
+				return &MutationError{Message: fmt.Sprintf("%v: %s", it.payload["uid"], ack.Error)}
+			}
+			res.Updated++
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidBulk) {
+			log.Error().Err(err).Str("type", typ).Str("action", req.Action).Msg("bulk update failed")
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// applyBulkAction edits one item's payload (deletes are applied by the push)
+func applyBulkAction(payload map[string]any, typ string, req BulkRequest) {
+	switch req.Action {
+	case BulkAddTag:
+		tags, _ := payload["tags"].([]any)
+		payload["tags"] = append(tags, req.Value)
+	case BulkRemoveTag:
+		tags, _ := payload["tags"].([]any)
+		payload["tags"] = slices.DeleteFunc(tags, func(tag any) bool { return tag == req.Value })
+	case BulkMove:
+		if req.Value == "" {
+			delete(payload, "taskListUid")
+		} else {
+			payload["taskListUid"] = req.Value
+		}
+	case BulkSetStatus:
+		payload["status"] = req.Value
+		if typ == "tasks" {
+			// done mirrors status, as in the task process/archive endpoints
+			payload["done"] = req.Value == "completed" || req.Value == "archived"
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package syncservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyBulkAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		req     BulkRequest
+		payload map[string]any
+		want    map[string]any
+	}{
+		{"add tag", "notes", BulkRequest{Action: BulkAddTag, Value: "work"},
+			map[string]any{"tags": []any{"home"}}, map[string]any{"tags": []any{"home", "work"}}},
+		{"add first tag", "notes", BulkRequest{Action: BulkAddTag, Value: "work"},
+			map[string]any{}, map[string]any{"tags": []any{"work"}}},
+		{"remove tag", "tasks", BulkRequest{Action: BulkRemoveTag, Value: "work"},
+			map[string]any{"tags": []any{"work", "home", "work"}}, map[string]any{"tags": []any{"home"}}},
+		{"move", "tasks", BulkRequest{Action: BulkMove, Value: "l1"},
+			map[string]any{"taskListUid": "l0"}, map[string]any{"taskListUid": "l1"}},
+		{"move out of lists", "tasks", BulkRequest{Action: BulkMove},
+			map[string]any{"taskListUid": "l0"}, map[string]any{}},
+		{"complete task", "tasks", BulkRequest{Action: BulkSetStatus, Value: "completed"},
+			map[string]any{"status": "open", "done": false}, map[string]any{"status": "completed", "done": true}},
+		{"reopen task", "tasks", BulkRequest{Action: BulkSetStatus, Value: "open"},
+			map[string]any{"status": "archived", "done": true}, map[string]any{"status": "open", "done": false}},
+		{"archive note", "notes", BulkRequest{Action: BulkSetStatus, Value: "archived"},
+			map[string]any{"status": "active"}, map[string]any{"status": "archived"}},
+		{"delete", "notes", BulkRequest{Action: BulkDelete},
+			map[string]any{"title": "x"}, map[string]any{"title": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyBulkAction(tt.payload, tt.typ, tt.req)
+			if !reflect.DeepEqual(tt.payload, tt.want) {
+				t.Errorf("payload = %v, want %v", tt.payload, tt.want)
+			}
+		})
+	}
+}
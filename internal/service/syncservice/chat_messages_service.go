@@ -3,8 +3,11 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -22,17 +25,95 @@ func NewChatMessageService(db *pgxpool.Pool) *ChatMessageService {
 	return &ChatMessageService{DB: db}
 }
 
-// PushChatMessageItem handles the push logic for a single chat_message item within a transaction
+// Hot chat message sync queries
+var (
+	upsertChatMessagesSQL = db.HotStatement("upsert_chat_messages", `
+		WITH upsert AS (
+			INSERT INTO chat_message (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, chat_uid)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				chat_uid       = EXCLUDED.chat_uid,
+				-- Bump version only on strictly newer update (not >=, just >)
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > chat_message.updated_at_ms
+					THEN chat_message.version + 1
+					ELSE chat_message.version
+				END
+			WHERE EXCLUDED.updated_at_ms > chat_message.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM chat_message
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullChatMessagesSQL = db.HotStatement("pull_chat_messages", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM chat_message
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushChatMessageItemJSON handles the push logic for a single chat_message item within a transaction
 // Returns a PushAck with either success or error information
 // Validates that parent chat exists before upserting
-func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+func (s *ChatMessageService) PushChatMessageItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	// Extract sync metadata + chat_uid from client JSON
+	ext, err := syncx.ExtractChatMessageJSON(payload)
+	return s.pushChatMessage(ctx, tx, userID, payload, ext, err, nil)
+}
+
+// PushChatMessageBatchJSON pushes a batch of chat messages within a transaction
+// Parent chats are validated up front with one query rather than one per item;
+// acks are returned in payload order.
+func (s *ChatMessageService) PushChatMessageBatchJSON(ctx context.Context, tx pgx.Tx, userID string, payloads []json.RawMessage) []PushAck {
+	exts := make([]syncx.Extracted, len(payloads))
+	errs := make([]error, len(payloads))
+	wanted := map[string][]string{}
+	for i, payload := range payloads {
+		exts[i], errs[i] = syncx.ExtractChatMessageJSON(payload)
+		if errs[i] == nil && exts[i].DeletedAtMs == nil {
+			wanted["chat"] = append(wanted["chat"], exts[i].ChatUID.String())
+		}
+	}
+	parents, err := loadLiveParents(ctx, tx, userID, wanted)
+	if err != nil {
+		// Fall back to checking each item's chat on its own
+		log.Error().Err(err).Msg("failed to batch-validate parent chats")
+	}
+
+	acks := make([]PushAck, len(payloads))
+	for i, payload := range payloads {
+		acks[i] = s.pushChatMessage(ctx, tx, userID, payload, exts[i], errs[i], parents)
+	}
+	return acks
+}
+
+// pushChatMessage validates and upserts one extracted chat message
+// parents holds the batch's prefetched live chats; nil checks this item's chat directly.
+func (s *ChatMessageService) pushChatMessage(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage, ext syncx.Extracted, err error, parents liveParents) PushAck {
+	defer metrics.TimeOperation("chat_messages", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
-	// Extract sync metadata + chat_uid from client JSON
-	ext, err := syncx.ExtractChatMessage(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
+	}
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "chat_message", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to assign server timestamp",
+		}
 	}
 
 	// Only validate parent chat exists if we're NOT deleting the message
@@ -41,9 +122,14 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 	if ext.DeletedAtMs == nil {
 		// Validate parent chat exists AND is not soft-deleted (critical for referential integrity)
 		var chatExists bool
-		err := tx.QueryRow(ctx,
-			`SELECT EXISTS(SELECT 1 FROM chat WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL)`,
-			userID, *ext.ChatUID).Scan(&chatExists)
+		var err error
+		if parents != nil {
+			chatExists = parents["chat"][*ext.ChatUID]
+		} else {
+			err = tx.QueryRow(ctx,
+				`SELECT EXISTS(SELECT 1 FROM chat WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL)`,
+				userID, *ext.ChatUID).Scan(&chatExists)
+		}
 		if err != nil {
 			logger.Error().Err(err).Str("chat_uid", ext.ChatUID.String()).Msg("failed to check chat existence")
 			return PushAck{
@@ -67,37 +153,23 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		}
 	}
 
-	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
-		}
-	}
-
 	// Insert or update with LWW conflict resolution
 	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
 	// If same timestamp arrives twice, version doesn't increment
-	_, err = tx.Exec(ctx, `
-		INSERT INTO chat_message (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, chat_uid)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			chat_uid       = EXCLUDED.chat_uid,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > chat_message.updated_at_ms
-				THEN chat_message.version + 1
-				ELSE chat_message.version
-			END
-		WHERE EXCLUDED.updated_at_ms > chat_message.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, *ext.ChatUID)
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertChatMessagesSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload, *ext.ChatUID).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM chat_message WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert chat_message")
@@ -109,18 +181,26 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		}
 	}
 
-	// Read back server state (authoritative version and timestamp)
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM chat_message WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read chat_message after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "chat_message", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record chat_message activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "chat_message", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index chat_message links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
 		}
 	}
 
@@ -129,23 +209,28 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushChatMessageItem pushes a chat message given as a decoded map (REST mutations, integrations)
+func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
 	}
+	return s.PushChatMessageItemJSON(ctx, tx, userID, payload)
 }
 
 // PullChatMessages handles the pull logic for chat_messages
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("chat_messages", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
 	// Query chat_messages ordered by (updated_at_ms, uid) for deterministic pagination
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM chat_message
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullChatMessagesSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query chat_messages")
@@ -153,13 +238,21 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -169,6 +262,12 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -188,18 +287,21 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 		return nil, err
 	}
 
-	// Generate next cursor if we returned any results
+	// The cursor after the last row is where the next pull resumes, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "chat_messages", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -261,7 +363,7 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list chat_messages")
 		return nil, err
@@ -271,8 +373,15 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -305,23 +414,27 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 		return nil, err
 	}
 
-	// Generate next cursor if we have results
+	// The cursor after the last row is where the next page starts, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "chat_messages", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyChatMessageMutation creates or updates a chat message via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("chat_messages", metrics.OpMutation)()
 	logger := log.With().Logger()
 
 	// Start transaction
@@ -339,8 +452,8 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 	}
 	if chatMessageUID == uuid.Nil {
 		chatMessageUID = uuid.New()
-		payload["uid"] = chatMessageUID.String()
 	}
+	payload["uid"] = chatMessageUID.String() // Canonical form, whatever spelling the client used
 
 	// Fetch existing chat_message to determine timestamp
 	var existingMs int64
@@ -362,6 +475,7 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      chatMessageUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
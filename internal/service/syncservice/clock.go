@@ -0,0 +1,37 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+)
+
+// serverStamp applies server timestamp mode (syncx.ServerTimestamps) to one
+// pushed item: ext.UpdatedAtMs becomes max(client ms, the owner's last ms in
+// table + 1) and the payload's timestamp is rewritten to match
+// Writers of one owner's table are serialized until commit by a transaction
+// advisory lock, so stamps increase in commit order and a pull cursor taken at
+// any point never has a later write land behind it. table is one of the sync
+// entity tables (never user input).
+func serverStamp(ctx context.Context, tx pgx.Tx, table, userID string, ext *syncx.Extracted, payload json.RawMessage) (json.RawMessage, error) {
+	if !syncx.ServerTimestamps() {
+		return payload, nil
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, "stamp:"+table+":"+userID); err != nil {
+		return nil, fmt.Errorf("lock %s clock: %w", table, err)
+	}
+	var lastMs int64
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(max(updated_at_ms), 0) FROM `+table+` WHERE owner_id = $1`, userID,
+	).Scan(&lastMs); err != nil {
+		return nil, fmt.Errorf("read %s clock: %w", table, err)
+	}
+	if ext.UpdatedAtMs > lastMs {
+		return payload, nil
+	}
+	ext.UpdatedAtMs = lastMs + 1
+	return syncx.StampUpdatedAt(payload, ext.UpdatedAtMs)
+}
@@ -3,8 +3,11 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -22,17 +25,96 @@ func NewCommentService(db *pgxpool.Pool) *CommentService {
 	return &CommentService{DB: db}
 }
 
-// PushCommentItem handles the push logic for a single comment item within a transaction
+// Hot comment sync queries
+var (
+	upsertCommentsSQL = db.HotStatement("upsert_comments", `
+		WITH upsert AS (
+			INSERT INTO comment (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, parent_type, parent_uid)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				parent_type    = EXCLUDED.parent_type,
+				parent_uid     = EXCLUDED.parent_uid,
+				-- Bump version only on strictly newer update (not >=, just >)
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > comment.updated_at_ms
+					THEN comment.version + 1
+					ELSE comment.version
+				END
+			WHERE EXCLUDED.updated_at_ms > comment.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM comment
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullCommentsSQL = db.HotStatement("pull_comments", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM comment
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushCommentItemJSON handles the push logic for a single comment item within a transaction
 // Returns a PushAck with either success or error information
 // Validates that parent (note or task) exists before upserting
-func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+func (s *CommentService) PushCommentItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	// Extract sync metadata + parent fields from client JSON
+	ext, err := syncx.ExtractCommentJSON(payload)
+	return s.pushComment(ctx, tx, userID, payload, ext, err, nil)
+}
+
+// PushCommentBatchJSON pushes a batch of comments within a transaction
+// Parents are validated up front with one query per parent type rather than
+// one per item; acks are returned in payload order.
+func (s *CommentService) PushCommentBatchJSON(ctx context.Context, tx pgx.Tx, userID string, payloads []json.RawMessage) []PushAck {
+	exts := make([]syncx.Extracted, len(payloads))
+	errs := make([]error, len(payloads))
+	wanted := map[string][]string{}
+	for i, payload := range payloads {
+		exts[i], errs[i] = syncx.ExtractCommentJSON(payload)
+		if errs[i] == nil && exts[i].DeletedAtMs == nil && (exts[i].ParentType == "note" || exts[i].ParentType == "task") {
+			wanted[exts[i].ParentType] = append(wanted[exts[i].ParentType], exts[i].ParentUID.String())
+		}
+	}
+	parents, err := loadLiveParents(ctx, tx, userID, wanted)
+	if err != nil {
+		// Fall back to checking each item's parent on its own
+		log.Error().Err(err).Msg("failed to batch-validate comment parents")
+	}
+
+	acks := make([]PushAck, len(payloads))
+	for i, payload := range payloads {
+		acks[i] = s.pushComment(ctx, tx, userID, payload, exts[i], errs[i], parents)
+	}
+	return acks
+}
+
+// pushComment validates and upserts one extracted comment
+// parents holds the batch's prefetched live parents; nil checks this item's parent directly.
+func (s *CommentService) pushComment(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage, ext syncx.Extracted, err error, parents liveParents) PushAck {
+	defer metrics.TimeOperation("comments", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
-	// Extract sync metadata + parent fields from client JSON
-	ext, err := syncx.ExtractComment(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
+	}
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "comment", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to assign server timestamp",
+		}
 	}
 
 	// Validate parent type
@@ -52,7 +134,9 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 	if ext.DeletedAtMs == nil {
 		// Validate parent exists AND is not soft-deleted (critical for referential integrity)
 		var parentExists bool
-		if ext.ParentType == "note" {
+		if parents != nil {
+			parentExists = parents[ext.ParentType][*ext.ParentUID]
+		} else if ext.ParentType == "note" {
 			err := tx.QueryRow(ctx,
 				`SELECT EXISTS(SELECT 1 FROM note WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL)`,
 				userID, *ext.ParentUID).Scan(&parentExists)
@@ -94,38 +178,23 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		}
 	}
 
-	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
-		}
-	}
-
 	// Insert or update with LWW conflict resolution
 	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
 	// If same timestamp arrives twice, version doesn't increment
-	_, err = tx.Exec(ctx, `
-		INSERT INTO comment (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, parent_type, parent_uid)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			parent_type    = EXCLUDED.parent_type,
-			parent_uid     = EXCLUDED.parent_uid,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > comment.updated_at_ms
-				THEN comment.version + 1
-				ELSE comment.version
-			END
-		WHERE EXCLUDED.updated_at_ms > comment.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, ext.ParentType, *ext.ParentUID)
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertCommentsSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload, ext.ParentType, *ext.ParentUID).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM comment WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert comment")
@@ -137,18 +206,26 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		}
 	}
 
-	// Read back server state (authoritative version and timestamp)
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM comment WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read comment after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "comment", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record comment activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "comment", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index comment links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
 		}
 	}
 
@@ -157,23 +234,28 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
 	}
 }
 
+// PushCommentItem pushes a comment given as a decoded map (REST mutations, integrations)
+func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
+	}
+	return s.PushCommentItemJSON(ctx, tx, userID, payload)
+}
+
 // PullComments handles the pull logic for comments
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *CommentService) PullComments(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("comments", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
 	// Query comments ordered by (updated_at_ms, uid) for deterministic pagination
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM comment
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullCommentsSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query comments")
@@ -181,13 +263,21 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -197,6 +287,12 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -216,18 +312,21 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 		return nil, err
 	}
 
-	// Generate next cursor if we returned any results
+	// The cursor after the last row is where the next pull resumes, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "comments", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -289,7 +388,7 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list comments")
 		return nil, err
@@ -299,8 +398,15 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -333,23 +439,27 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 		return nil, err
 	}
 
-	// Generate next cursor if we have results
+	// The cursor after the last row is where the next page starts, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "comments", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyCommentMutation creates or updates a comment via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("comments", metrics.OpMutation)()
 	logger := log.With().Logger()
 
 	// Start transaction
@@ -367,8 +477,8 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 	}
 	if commentUID == uuid.Nil {
 		commentUID = uuid.New()
-		payload["uid"] = commentUID.String()
 	}
+	payload["uid"] = commentUID.String() // Canonical form, whatever spelling the client used
 
 	// Fetch existing comment to determine timestamp
 	var existingMs int64
@@ -390,6 +500,7 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      commentUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
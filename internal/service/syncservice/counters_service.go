@@ -0,0 +1,93 @@
+package syncservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// ListCount is the number of open tasks in one task list
+type ListCount struct {
+	TaskListUID string `json:"taskListUid,omitempty"` // Empty for tasks in no list
+	Count       int64  `json:"count"`
+}
+
+// Counters are dashboard aggregates over one user's items
+type Counters struct {
+	OpenTasks            int64       `json:"openTasks"`
+	OpenTasksByList      []ListCount `json:"openTasksByList"`
+	NotesCreatedThisWeek int64       `json:"notesCreatedThisWeek"`
+	WeekStart            time.Time   `json:"weekStart"` // Monday 00:00 in the requested time zone
+	UnreadChats          int64       `json:"unreadChats"`
+	UnreadMessages       int64       `json:"unreadMessages"`
+}
+
+// CounterService computes dashboard counters from the generated columns and
+// partial indexes of migration 0026, without decoding payloads
+type CounterService struct {
+	DB *pgxpool.Pool
+}
+
+// NewCounterService creates a new CounterService
+func NewCounterService(db *pgxpool.Pool) *CounterService {
+	return &CounterService{DB: db}
+}
+
+// WeekStart returns the start of the week (Monday 00:00) containing t in loc
+func WeekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+}
+
+// Counters returns the user's counters, with the week starting in loc
+func (s *CounterService) Counters(ctx context.Context, userID string, loc *time.Location) (*Counters, error) {
+	logger := log.With().Logger()
+	c := &Counters{
+		OpenTasksByList: make([]ListCount, 0),
+		WeekStart:       WeekStart(time.UnixMilli(syncx.NowMs()), loc),
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT task_list_uid, count(*)
+		FROM task
+		WHERE owner_id = $1 AND is_open
+		GROUP BY task_list_uid
+		ORDER BY count(*) DESC, task_list_uid
+	`, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count open tasks")
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lc ListCount
+		if err := rows.Scan(&lc.TaskListUID, &lc.Count); err != nil {
+			return nil, err
+		}
+		c.OpenTasks += lc.Count
+		c.OpenTasksByList = append(c.OpenTasksByList, lc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Unread messages of deleted chats don't count
+	err = s.DB.QueryRow(ctx, `
+		SELECT
+			(SELECT count(*) FROM note WHERE owner_id = $1 AND deleted_at_ms IS NULL AND created_at >= $2),
+			count(DISTINCT m.chat_uid),
+			count(*)
+		FROM chat_message m
+		JOIN chat c ON c.owner_id = m.owner_id AND c.uid = m.chat_uid AND c.deleted_at_ms IS NULL
+		WHERE m.owner_id = $1 AND m.is_unread
+	`, userID, c.WeekStart).Scan(&c.NotesCreatedThisWeek, &c.UnreadChats, &c.UnreadMessages)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count notes and unread messages")
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,31 @@
+package syncservice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{"monday", time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC), time.UTC, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)},
+		{"sunday", time.Date(2025, 11, 9, 23, 59, 0, 0, time.UTC), time.UTC, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)},
+		// Sunday 23:30 UTC is already Monday in Berlin
+		{"zone", time.Date(2025, 11, 9, 23, 30, 0, 0, time.UTC), berlin, time.Date(2025, 11, 10, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStart(tt.t, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("WeekStart(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxMergeItems caps how many duplicates one merge tombstones
+const MaxMergeItems = 50
+
+// ErrInvalidMerge is returned for a merge whose items aren't live items of the
+// user's, or that names the kept item among the duplicates
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// dupType is an entity duplicates are detected for, by its sync path name
+type dupType struct {
+	table   string
+	parent  string // comment.parent_type
+	content string // Payload field compared besides the title
+	push    func(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck
+}
+
+// DuplicateItem is one item of a duplicate group
+type DuplicateItem struct {
+	UID       string    `json:"uid"`
+	UpdatedAt string    `json:"updatedAt"`
+	CreatedAt time.Time `json:"createdAt"` // When the server first stored it
+}
+
+// DuplicateGroup is a set of live items with the same title
+type DuplicateGroup struct {
+	Title     string          `json:"title"`     // Normalized (trimmed, lower-cased)
+	Identical bool            `json:"identical"` // Content matches too: almost certainly one item pushed twice
+	Items     []DuplicateItem `json:"items"`     // Oldest first
+}
+
+// MergeResult describes an applied merge
+type MergeResult struct {
+	Kept       string `json:"kept"`
+	Merged     int    `json:"merged"`     // Duplicates tombstoned
+	Reparented int    `json:"reparented"` // Comments (and subtasks) moved to the kept item
+}
+
+// DuplicateService finds probable duplicate notes and tasks (typically left by
+// a retry that pushed an item again under a new UID) and merges them
+// Merges go through the push path like REST mutations, so every device pulls
+// the tombstones and the re-parented children.
+type DuplicateService struct {
+	DB       *pgxpool.Pool
+	Comments *CommentService
+	Tasks    *TaskService
+
+	types map[string]dupType
+}
+
+// NewDuplicateService creates a new DuplicateService
+func NewDuplicateService(db *pgxpool.Pool) *DuplicateService {
+	notes, tasks := NewNoteService(db), NewTaskService(db)
+	return &DuplicateService{
+		DB:       db,
+		Comments: NewCommentService(db),
+		Tasks:    tasks,
+		types: map[string]dupType{
+			"notes": {"note", "note", "content", notes.PushNoteItem},
+			"tasks": {"task", "task", "description", tasks.PushTaskItem},
+		},
+	}
+}
+
+// Type reports whether name is a type duplicates are detected for (notes, tasks)
+func (s *DuplicateService) Type(name string) bool {
+	_, ok := s.types[name]
+	return ok
+}
+
+// Find returns up to limit groups of the user's live items of one type that
+// share a title, largest first
+func (s *DuplicateService) Find(ctx context.Context, userID, typ string, limit int) ([]DuplicateGroup, error) {
+	t := s.types[typ]
+	rows, err := s.DB.Query(ctx, `
+		WITH items AS (
+			SELECT uid::text, updated_at_ms, created_at,
+				lower(btrim(payload_json->>'title')) AS title,
+				md5(coalesce(payload_json->>$2, '')) AS content
+			FROM `+t.table+`
+			WHERE owner_id = $1 AND deleted_at_ms IS NULL AND btrim(payload_json->>'title') <> ''
+		), groups AS (
+			SELECT title, count(*) AS n, count(DISTINCT content) = 1 AS identical
+			FROM items GROUP BY title HAVING count(*) > 1
+			ORDER BY n DESC, title
+			LIMIT $3
+		)
+		SELECT g.title, g.identical, i.uid, i.updated_at_ms, i.created_at
+		FROM groups g JOIN items i ON i.title = g.title
+		ORDER BY g.n DESC, g.title, i.created_at, i.uid
+	`, userID, t.content, limit)
+	if err != nil {
+		log.Error().Err(err).Str("type", typ).Msg("failed to query duplicates")
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]DuplicateGroup, 0)
+	for rows.Next() {
+		var title string
+		var identical bool
+		var it DuplicateItem
+		var ms int64
+		if err := rows.Scan(&title, &identical, &it.UID, &ms, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		it.UpdatedAt = syncx.RFC3339(ms)
+		if n := len(groups); n == 0 || groups[n-1].Title != title {
+			groups = append(groups, DuplicateGroup{Title: title, Identical: identical})
+		}
+		groups[len(groups)-1].Items = append(groups[len(groups)-1].Items, it)
+	}
+	return groups, rows.Err()
+}
+
+// Merge consolidates duplicates into keep: their comments (and, for tasks,
+// subtasks) are re-parented to keep, then they are tombstoned. The kept item's
+// own content is left as it is. All or nothing, in one transaction.
+func (s *DuplicateService) Merge(ctx context.Context, userID, typ string, keep uuid.UUID, duplicates []uuid.UUID) (*MergeResult, error) {
+	t := s.types[typ]
+	if len(duplicates) == 0 || len(duplicates) > MaxMergeItems || slices.Contains(duplicates, keep) {
+		return nil, ErrInvalidMerge
+	}
+	res := &MergeResult{Kept: keep.String()}
+
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Lock the items so a concurrent push can't revive or edit them mid-merge
+		all := make([]string, 0, len(duplicates)+1)
+		for _, uid := range append([]uuid.UUID{keep}, duplicates...) {
+			all = append(all, uid.String())
+		}
+		rows, err := tx.Query(ctx, `
+			SELECT uid::text, payload_json, updated_at_ms FROM `+t.table+`
+			WHERE owner_id = $1 AND uid = ANY($2::uuid[]) AND deleted_at_ms IS NULL
+			FOR UPDATE
+		`, userID, all)
+		if err != nil {
+			return err
+		}
+		type row struct {
+			payload map[string]any
+			ms      int64
+		}
+		items := make(map[string]row, len(all))
+		for rows.Next() {
+			var uid string
+			var r row
+			if err := rows.Scan(&uid, &r.payload, &r.ms); err != nil {
+				rows.Close()
+				return err
+			}
+			items[uid] = r
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(items) != len(all) {
+			return ErrInvalidMerge // Missing, deleted, or listed twice
+		}
+
+		// Children first: their pushes validate that keep is a live parent
+		moved, err := s.reparent(ctx, tx, userID, t, all[0], all[1:])
+		if err != nil {
+			return err
+		}
+		res.Reparented = moved
+
+		for _, uid := range all[1:] {
+			r := items[uid]
+			tombstone := syncx.BuildServerMutation(r.payload, syncx.EnsureMonotonicTimestamp(r.ms), true)
+			if ack := t.push(ctx, tx, userID, tombstone); ack.Error != "" {
+				return &MutationError{Message: fmt.Sprintf("tombstone %s: %s", uid, ack.Error)}
+			}
+			res.Merged++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// reparent points the live comments of the duplicates (and the subtasks, for
+// tasks) at keep. Returns how many were moved.
+func (s *DuplicateService) reparent(ctx context.Context, tx pgx.Tx, userID string, t dupType, keep string, duplicates []string) (int, error) {
+	type child struct {
+		push    func(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck
+		payload map[string]any
+		ms      int64
+	}
+	var children []child
+	collect := func(push func(context.Context, pgx.Tx, string, map[string]any) PushAck, query string, args ...any) error {
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			c := child{push: push}
+			if err := rows.Scan(&c.payload, &c.ms); err != nil {
+				return err
+			}
+			children = append(children, c)
+		}
+		return rows.Err()
+	}
+
+	if err := collect(s.Comments.PushCommentItem, `
+		SELECT payload_json, updated_at_ms FROM comment
+		WHERE owner_id = $1 AND parent_type = $2 AND parent_uid = ANY($3::uuid[]) AND deleted_at_ms IS NULL
+		FOR UPDATE
+	`, userID, t.parent, duplicates); err != nil {
+		return 0, err
+	}
+	if t.table == "task" {
+		if err := collect(s.Tasks.PushTaskItem, `
+			SELECT payload_json, updated_at_ms FROM task
+			WHERE owner_id = $1 AND payload_json->>'parentUid' = ANY($2) AND deleted_at_ms IS NULL
+			FOR UPDATE
+		`, userID, duplicates); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, c := range children {
+		c.payload["parentUid"] = keep
+		item := syncx.BuildServerMutation(c.payload, syncx.EnsureMonotonicTimestamp(c.ms), false)
+		if ack := c.push(ctx, tx, userID, item); ack.Error != "" {
+			return 0, &MutationError{Message: fmt.Sprintf("re-parent %v: %s", c.payload["uid"], ack.Error)}
+		}
+	}
+	return len(children), nil
+}
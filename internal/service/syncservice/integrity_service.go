@@ -0,0 +1,292 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Orphan policies (ORPHAN_POLICY)
+const (
+	OrphanPolicyReport = "report" // Count orphans only
+	OrphanPolicyRepair = "repair" // Tombstone orphaned comments/messages/pins, detach tasks from missing lists
+)
+
+// IntegrityReport is the result of one orphan scan
+// Counts are keyed by entity table name.
+type IntegrityReport struct {
+	CheckedAt  time.Time        `json:"checkedAt"`
+	DurationMs int64            `json:"durationMs"`
+	Policy     string           `json:"policy"`
+	Orphans    map[string]int64 `json:"orphans"`  // Detected this run
+	Repaired   map[string]int64 `json:"repaired"` // Tombstoned (comment, chat_message, pin) or detached (task)
+	Failed     int64            `json:"failed"`   // Repairs that errored (retried next run)
+}
+
+// IntegrityService finds children whose parents were tombstoned or removed
+// Push validation prevents creating such rows, but a parent can be deleted
+// afterwards (REST/sync deletes don't cascade to comments or chat messages), and
+// a task can reference a list a client never pushed.
+//   - comment:      alive, parent note/task missing or tombstoned
+//   - chat_message: alive, chat missing or tombstoned
+//   - task:         alive, payload taskListUid names a missing or tombstoned list
+//   - pin:          alive, pinned note/task/chat missing or tombstoned
+//
+// Repairs go through the same service calls as the REST API (soft delete,
+// task list orphaning), so versions, timestamps and the activity log advance
+// and clients pick the change up on their next pull.
+type IntegrityService struct {
+	DB           *pgxpool.Pool
+	Policy       string
+	Grace        time.Duration // Only rows older than this count (child pushed just before its parent)
+	BatchSize    int           // Max orphans repaired per entity per run
+	Comments     *CommentService
+	ChatMessages *ChatMessageService
+	TaskLists    *TaskListService
+	Pins         *PinService
+
+	mu   sync.Mutex
+	last *IntegrityReport
+}
+
+// NewIntegrityService creates an integrity checker with a 1 hour grace period
+func NewIntegrityService(db *pgxpool.Pool, policy string) *IntegrityService {
+	return &IntegrityService{
+		DB:           db,
+		Policy:       policy,
+		Grace:        time.Hour,
+		BatchSize:    1000,
+		Comments:     NewCommentService(db),
+		ChatMessages: NewChatMessageService(db),
+		TaskLists:    NewTaskListService(db),
+		Pins:         NewPinService(db),
+	}
+}
+
+// orphanQueries select (owner_id, uid) of orphans per entity, oldest first
+// $1 = cutoff (updated_at_ms), $2 = limit. For task the uid is the missing list.
+var orphanQueries = []struct {
+	entity string // Table name (report keys)
+	label  string // Metrics entity label
+	count  string
+	list   string
+}{
+	{
+		entity: "comment",
+		label:  "comments",
+		count: `
+			SELECT count(*) FROM comment c
+			WHERE c.deleted_at_ms IS NULL AND c.updated_at_ms < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM note n WHERE c.parent_type = 'note' AND n.owner_id = c.owner_id AND n.uid = c.parent_uid AND n.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM task t WHERE c.parent_type = 'task' AND t.owner_id = c.owner_id AND t.uid = c.parent_uid AND t.deleted_at_ms IS NULL)`,
+		list: `
+			SELECT c.owner_id::text, c.uid::text FROM comment c
+			WHERE c.deleted_at_ms IS NULL AND c.updated_at_ms < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM note n WHERE c.parent_type = 'note' AND n.owner_id = c.owner_id AND n.uid = c.parent_uid AND n.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM task t WHERE c.parent_type = 'task' AND t.owner_id = c.owner_id AND t.uid = c.parent_uid AND t.deleted_at_ms IS NULL)
+			ORDER BY c.updated_at_ms LIMIT $2`,
+	},
+	{
+		entity: "chat_message",
+		label:  "chat_messages",
+		count: `
+			SELECT count(*) FROM chat_message m
+			WHERE m.deleted_at_ms IS NULL AND m.updated_at_ms < $1
+			  AND NOT EXISTS (SELECT 1 FROM chat c WHERE c.owner_id = m.owner_id AND c.uid = m.chat_uid AND c.deleted_at_ms IS NULL)`,
+		list: `
+			SELECT m.owner_id::text, m.uid::text FROM chat_message m
+			WHERE m.deleted_at_ms IS NULL AND m.updated_at_ms < $1
+			  AND NOT EXISTS (SELECT 1 FROM chat c WHERE c.owner_id = m.owner_id AND c.uid = m.chat_uid AND c.deleted_at_ms IS NULL)
+			ORDER BY m.updated_at_ms LIMIT $2`,
+	},
+	{
+		entity: "task",
+		label:  "tasks",
+		count: `
+			SELECT count(*) FROM task t
+			WHERE t.deleted_at_ms IS NULL AND t.updated_at_ms < $1
+			  AND t.payload_json->>'taskListUid' IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM task_list l WHERE l.owner_id = t.owner_id AND l.uid::text = t.payload_json->>'taskListUid' AND l.deleted_at_ms IS NULL)`,
+		list: `
+			SELECT DISTINCT t.owner_id::text, t.payload_json->>'taskListUid' FROM task t
+			WHERE t.deleted_at_ms IS NULL AND t.updated_at_ms < $1
+			  AND t.payload_json->>'taskListUid' IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM task_list l WHERE l.owner_id = t.owner_id AND l.uid::text = t.payload_json->>'taskListUid' AND l.deleted_at_ms IS NULL)
+			LIMIT $2`,
+	},
+	{
+		entity: "pin",
+		label:  "pins",
+		count: `
+			SELECT count(*) FROM pin p
+			WHERE p.deleted_at_ms IS NULL AND p.updated_at_ms < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM note n WHERE p.target_type = 'note' AND n.owner_id = p.owner_id AND n.uid = p.target_uid AND n.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM task t WHERE p.target_type = 'task' AND t.owner_id = p.owner_id AND t.uid = p.target_uid AND t.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM chat c WHERE p.target_type = 'chat' AND c.owner_id = p.owner_id AND c.uid = p.target_uid AND c.deleted_at_ms IS NULL)`,
+		list: `
+			SELECT p.owner_id::text, p.uid::text FROM pin p
+			WHERE p.deleted_at_ms IS NULL AND p.updated_at_ms < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM note n WHERE p.target_type = 'note' AND n.owner_id = p.owner_id AND n.uid = p.target_uid AND n.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM task t WHERE p.target_type = 'task' AND t.owner_id = p.owner_id AND t.uid = p.target_uid AND t.deleted_at_ms IS NULL
+				UNION ALL
+				SELECT 1 FROM chat c WHERE p.target_type = 'chat' AND c.owner_id = p.owner_id AND c.uid = p.target_uid AND c.deleted_at_ms IS NULL)
+			ORDER BY p.updated_at_ms LIMIT $2`,
+	},
+}
+
+// Check scans for orphans and, under the repair policy, fixes up to BatchSize per entity
+func (s *IntegrityService) Check(ctx context.Context) (*IntegrityReport, error) {
+	start := time.Now()
+	report := &IntegrityReport{
+		CheckedAt: start.UTC(),
+		Policy:    s.Policy,
+		Orphans:   make(map[string]int64),
+		Repaired:  make(map[string]int64),
+	}
+	cutoff := syncx.NowMs() - s.Grace.Milliseconds()
+	ctx = WithChangeSource(ctx, ChangeSource{DeviceID: "integrity"})
+
+	for _, q := range orphanQueries {
+		var n int64
+		if err := s.DB.QueryRow(ctx, q.count, cutoff).Scan(&n); err != nil {
+			return nil, fmt.Errorf("count %s orphans: %w", q.entity, err)
+		}
+		report.Orphans[q.entity] = n
+
+		if n > 0 && s.Policy == OrphanPolicyRepair {
+			repaired, failed, err := s.repair(ctx, q.entity, q.list, cutoff)
+			if err != nil {
+				return nil, err
+			}
+			report.Repaired[q.entity] = repaired
+			report.Failed += failed
+		}
+		metrics.ObserveIntegrity(q.label, n, report.Repaired[q.entity])
+	}
+
+	report.DurationMs = time.Since(start).Milliseconds()
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	ev := log.Info()
+	if report.Orphans["comment"]+report.Orphans["chat_message"]+report.Orphans["task"]+report.Orphans["pin"] > 0 {
+		ev = log.Warn()
+	}
+	ev.Interface("orphans", report.Orphans).
+		Interface("repaired", report.Repaired).
+		Int64("failed", report.Failed).
+		Str("policy", s.Policy).
+		Msg("integrity check complete")
+	return report, nil
+}
+
+// repair fixes one batch of orphans; per-row failures are logged and counted, not fatal
+func (s *IntegrityService) repair(ctx context.Context, entity, query string, cutoff int64) (repaired, failed int64, err error) {
+	rows, err := s.DB.Query(ctx, query, cutoff, s.BatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list %s orphans: %w", entity, err)
+	}
+	type orphan struct{ owner, uid string }
+	var orphans []orphan
+	for rows.Next() {
+		var o orphan
+		if err := rows.Scan(&o.owner, &o.uid); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		orphans = append(orphans, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, o := range orphans {
+		n, err := s.repairOne(ctx, entity, o.owner, o.uid)
+		if err != nil {
+			log.Warn().Err(err).Str("entity", entity).Str("uid", o.uid).Str("userId", o.owner).Msg("orphan repair failed")
+			failed++
+			continue
+		}
+		repaired += n
+	}
+	return repaired, failed, nil
+}
+
+// repairOne tombstones one orphaned comment/message/pin, or detaches every task from one missing list
+func (s *IntegrityService) repairOne(ctx context.Context, entity, userID, uidStr string) (int64, error) {
+	uid, err := uuid.Parse(uidStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid uid %q", uidStr)
+	}
+	opts := MutationOpts{SetDeleted: true}
+
+	switch entity {
+	case "comment":
+		existing, err := s.Comments.GetComment(ctx, userID, uid)
+		if err != nil || existing == nil || existing.DeletedAt != nil {
+			return 0, err
+		}
+		_, err = s.Comments.ApplyCommentMutation(ctx, userID, existing.Payload, opts)
+		return 1, err
+	case "chat_message":
+		existing, err := s.ChatMessages.GetChatMessage(ctx, userID, uid)
+		if err != nil || existing == nil || existing.DeletedAt != nil {
+			return 0, err
+		}
+		_, err = s.ChatMessages.ApplyChatMessageMutation(ctx, userID, existing.Payload, opts)
+		return 1, err
+	case "task":
+		return s.TaskLists.OrphanTasksInList(ctx, userID, uid)
+	case "pin":
+		return s.Pins.Unpin(ctx, userID, uid)
+	}
+	return 0, fmt.Errorf("unknown entity %s", entity)
+}
+
+// Last returns the most recent report (nil before the first check)
+func (s *IntegrityService) Last() *IntegrityReport {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Run checks every interval until ctx is cancelled
+func (s *IntegrityService) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Check(ctx); err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("integrity check failed; will retry")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Entities individual items can be shared for (item_grant.entity)
+// A chat's grant covers its messages.
+const (
+	GrantNote = "note"
+	GrantChat = "chat"
+)
+
+// grantTables are the tables grantable entities live in (identifiers, never user input)
+var grantTables = map[string]string{GrantNote: "note", GrantChat: "chat"}
+
+// SharedItem is a note or chat another user shared with the caller
+type SharedItem struct {
+	OwnerID    string    `json:"ownerId"`
+	UID        string    `json:"uid"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Grant shares the owner's live note or chat with the user whose subject is
+// sub, or changes that user's permission
+func (s *SharingService) Grant(ctx context.Context, ownerID, entity string, uid uuid.UUID, sub, permission string) (*ListMember, error) {
+	table, ok := grantTables[entity]
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	if permission != PermissionView && permission != PermissionEdit {
+		return nil, ErrInvalidPermission
+	}
+	var live bool
+	err := s.DB.QueryRow(ctx,
+		`SELECT deleted_at_ms IS NULL FROM `+table+` WHERE owner_id = $1 AND uid = $2`,
+		ownerID, uid).Scan(&live)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !live) {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g := ListMember{Sub: sub, Permission: permission}
+	err = s.DB.QueryRow(ctx, `SELECT id::text FROM app_user WHERE sub = $1`, sub).Scan(&g.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.UserID == ownerID {
+		return nil, ErrShareWithSelf
+	}
+
+	err = s.DB.QueryRow(ctx, `
+		INSERT INTO item_grant (owner_id, entity, item_uid, grantee_id, permission)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_id, entity, item_uid, grantee_id) DO UPDATE SET permission = excluded.permission
+		RETURNING created_at
+	`, ownerID, entity, uid, g.UserID, permission).Scan(&g.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// ListGrants returns the users one of the owner's items is shared with
+func (s *SharingService) ListGrants(ctx context.Context, ownerID, entity string, uid uuid.UUID) ([]ListMember, error) {
+	table, ok := grantTables[entity]
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	var exists bool
+	if err := s.DB.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE owner_id = $1 AND uid = $2)`,
+		ownerID, uid).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrItemNotFound
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT g.grantee_id::text, u.sub, g.permission, g.created_at
+		FROM item_grant g JOIN app_user u ON u.id = g.grantee_id
+		WHERE g.owner_id = $1 AND g.entity = $2 AND g.item_uid = $3
+		ORDER BY g.created_at, g.grantee_id
+	`, ownerID, entity, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	grants := make([]ListMember, 0)
+	for rows.Next() {
+		var g ListMember
+		if err := rows.Scan(&g.UserID, &g.Sub, &g.Permission, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+// RevokeGrant unshares the owner's item with granteeID
+func (s *SharingService) RevokeGrant(ctx context.Context, ownerID, entity string, uid uuid.UUID, granteeID string) error {
+	tag, err := s.DB.Exec(ctx,
+		`DELETE FROM item_grant WHERE owner_id = $1 AND entity = $2 AND item_uid = $3 AND grantee_id::text = $4`,
+		ownerID, entity, uid, granteeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// LeaveItem removes granteeID's access to an item shared with them
+func (s *SharingService) LeaveItem(ctx context.Context, granteeID, entity string, uid uuid.UUID) error {
+	tag, err := s.DB.Exec(ctx,
+		`DELETE FROM item_grant WHERE grantee_id = $1 AND entity = $2 AND item_uid = $3`,
+		granteeID, entity, uid)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// GrantedTo returns the items of entity shared with granteeID, oldest grant first
+func (s *SharingService) GrantedTo(ctx context.Context, granteeID, entity string) ([]SharedItem, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT owner_id::text, item_uid::text, permission, created_at
+		FROM item_grant
+		WHERE grantee_id = $1 AND entity = $2
+		ORDER BY created_at, owner_id, item_uid
+	`, granteeID, entity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := make([]SharedItem, 0)
+	for rows.Next() {
+		var it SharedItem
+		if err := rows.Scan(&it.OwnerID, &it.UID, &it.Permission, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// Shared item pull queries
+const (
+	pullSharedNotesSQL = `
+		SELECT n.payload_json, n.deleted_at_ms, n.updated_at_ms, n.uid
+		FROM note n
+		JOIN item_grant g ON g.owner_id = n.owner_id AND g.entity = 'note' AND g.item_uid = n.uid
+		WHERE g.grantee_id = $1
+		  AND (n.updated_at_ms, n.uid) > ($2, $3::uuid)
+		ORDER BY n.updated_at_ms, n.uid
+		LIMIT $4`
+	pullSharedChatsSQL = `
+		SELECT c.payload_json, c.deleted_at_ms, c.updated_at_ms, c.uid
+		FROM chat c
+		JOIN item_grant g ON g.owner_id = c.owner_id AND g.entity = 'chat' AND g.item_uid = c.uid
+		WHERE g.grantee_id = $1
+		  AND (c.updated_at_ms, c.uid) > ($2, $3::uuid)
+		ORDER BY c.updated_at_ms, c.uid
+		LIMIT $4`
+	pullSharedChatMessagesSQL = `
+		SELECT m.payload_json, m.deleted_at_ms, m.updated_at_ms, m.uid
+		FROM chat_message m
+		JOIN item_grant g ON g.owner_id = m.owner_id AND g.entity = 'chat' AND g.item_uid = m.chat_uid
+		WHERE g.grantee_id = $1
+		  AND (m.updated_at_ms, m.uid) > ($2, $3::uuid)
+		ORDER BY m.updated_at_ms, m.uid
+		LIMIT $4`
+)
+
+// PullSharedNotes returns the notes shared with granteeID
+// entity is the cursor scope ("shared_notes:" + MembershipTag).
+func (s *SharingService) PullSharedNotes(ctx context.Context, granteeID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("shared_notes", metrics.OpPullPage)()
+	return s.pullShared(ctx, pullSharedNotesSQL, granteeID, entity, cursor, limit)
+}
+
+// PullSharedChats returns the chats shared with granteeID
+func (s *SharingService) PullSharedChats(ctx context.Context, granteeID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("shared_chats", metrics.OpPullPage)()
+	return s.pullShared(ctx, pullSharedChatsSQL, granteeID, entity, cursor, limit)
+}
+
+// PullSharedChatMessages returns the messages in chats shared with granteeID
+func (s *SharingService) PullSharedChatMessages(ctx context.Context, granteeID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("shared_chat_messages", metrics.OpPullPage)()
+	return s.pullShared(ctx, pullSharedChatMessagesSQL, granteeID, entity, cursor, limit)
+}
+
+// grantOwner returns the owner of the live item of entity shared with
+// granteeID for editing ("" when there is none)
+// Grants outlive deletes, so without the liveness check a grantee could push
+// an undeleted copy and resurrect an item its owner deleted.
+func grantOwner(ctx context.Context, tx pgx.Tx, granteeID, entity string, uid uuid.UUID) (string, error) {
+	table, ok := grantTables[entity]
+	if !ok {
+		return "", nil
+	}
+	var ownerID string
+	err := tx.QueryRow(ctx, `
+		SELECT g.owner_id::text
+		FROM item_grant g
+		JOIN `+table+` i ON i.owner_id = g.owner_id AND i.uid = g.item_uid
+		WHERE g.grantee_id = $1 AND g.entity = $2 AND g.item_uid = $3 AND g.permission = 'edit' AND i.deleted_at_ms IS NULL
+		ORDER BY g.created_at
+		LIMIT 1
+	`, granteeID, entity, uid).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return ownerID, err
+}
+
+// PushSharedNoteItemJSON writes a grantee's edit to a note shared with them
+// for editing; the note stays the owner's. Grantees can't create notes.
+func (s *SharingService) PushSharedNoteItemJSON(ctx context.Context, tx pgx.Tx, granteeID string, payload json.RawMessage) PushAck {
+	return s.pushGranted(ctx, tx, granteeID, GrantNote, payload, s.Notes.PushNoteItemJSON)
+}
+
+// PushSharedChatItemJSON writes a grantee's edit to a chat shared with them
+// for editing; the chat stays the owner's. Grantees can't create chats.
+func (s *SharingService) PushSharedChatItemJSON(ctx context.Context, tx pgx.Tx, granteeID string, payload json.RawMessage) PushAck {
+	return s.pushGranted(ctx, tx, granteeID, GrantChat, payload, s.Chats.PushChatItemJSON)
+}
+
+// pushGranted checks granteeID can edit the item and pushes it as its owner's
+func (s *SharingService) pushGranted(ctx context.Context, tx pgx.Tx, granteeID, entity string, payload json.RawMessage,
+	push func(context.Context, pgx.Tx, string, json.RawMessage) PushAck) PushAck {
+	ext, err := syncx.ExtractCommonJSON(payload)
+	if err != nil {
+		return extractFailure(ext, err)
+	}
+	ownerID, err := grantOwner(ctx, tx, granteeID, entity, ext.UID)
+	if err != nil {
+		log.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to look up item grant")
+		return lookupFailureAck(ext, "grant lookup failed")
+	}
+	if ownerID == "" {
+		return forbiddenAck(ext, entity+" is not shared with you for editing")
+	}
+	return push(ctx, tx, ownerID, payload)
+}
+
+// PushSharedChatMessageItemJSON writes a grantee's message into a chat shared
+// with them for editing; the message is stored as the chat owner's. An
+// existing message can't be moved out of a chat the grantee can't edit.
+func (s *SharingService) PushSharedChatMessageItemJSON(ctx context.Context, tx pgx.Tx, granteeID string, payload json.RawMessage) PushAck {
+	ext, err := syncx.ExtractChatMessageJSON(payload)
+	if err != nil {
+		return extractFailure(ext, err)
+	}
+	chatUID := *ext.ChatUID // Set whenever extraction succeeds
+	ownerID, err := grantOwner(ctx, tx, granteeID, GrantChat, chatUID)
+	if err != nil {
+		log.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to look up item grant")
+		return lookupFailureAck(ext, "grant lookup failed")
+	}
+	if ownerID == "" {
+		return forbiddenAck(ext, "chat is not shared with you for editing")
+	}
+
+	var current uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT chat_uid FROM chat_message WHERE owner_id = $1 AND uid = $2`,
+		ownerID, ext.UID).Scan(&current)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to probe shared chat message")
+		return lookupFailureAck(ext, "shared message lookup failed")
+	}
+	if err == nil && current != chatUID {
+		owner, err := grantOwner(ctx, tx, granteeID, GrantChat, current)
+		if err != nil {
+			log.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to look up item grant")
+			return lookupFailureAck(ext, "grant lookup failed")
+		}
+		if owner != ownerID {
+			return forbiddenAck(ext, "message is in a chat not shared with you for editing")
+		}
+	}
+
+	return s.Messages.PushChatMessageItemJSON(ctx, tx, ownerID, payload)
+}
@@ -0,0 +1,154 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// linkSources are the entity tables whose pushes index links (table = entity name)
+var linkSources = []string{"note", "task", "task_list", "task_list_category", "comment", "chat", "chat_message", "pin"}
+
+// Backlink is an item that links to another one
+type Backlink struct {
+	Entity       string `json:"entity"`
+	UID          string `json:"uid"`
+	TargetEntity string `json:"targetEntity"` // The entity the link names for the target
+}
+
+// LinkService reads the link index written by pushes
+type LinkService struct {
+	DB *pgxpool.Pool
+}
+
+// NewLinkService creates a new LinkService
+func NewLinkService(db *pgxpool.Pool) *LinkService {
+	return &LinkService{DB: db}
+}
+
+// Backlinks returns up to limit live items of the user's that link to uid
+func (s *LinkService) Backlinks(ctx context.Context, userID string, uid uuid.UUID, limit int) ([]Backlink, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT source_entity, source_uid::text, target_entity
+		FROM link
+		WHERE owner_id = $1 AND target_uid = $2
+		ORDER BY source_entity, source_uid
+		LIMIT $3
+	`, userID, uid, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to query backlinks")
+		return nil, err
+	}
+	defer rows.Close()
+
+	backlinks := make([]Backlink, 0)
+	for rows.Next() {
+		var b Backlink
+		if err := rows.Scan(&b.Entity, &b.UID, &b.TargetEntity); err != nil {
+			return nil, err
+		}
+		backlinks = append(backlinks, b)
+	}
+	return backlinks, rows.Err()
+}
+
+// indexLinks replaces the link rows of an item after an applied write
+// inserted skips clearing rows a new item can't have; a tombstone (deleted)
+// just clears them. The returned warning describes malformed links and links
+// to items that don't exist (yet); it goes in the ack, the push still applies.
+func indexLinks(ctx context.Context, tx pgx.Tx, userID, entity string, uid uuid.UUID, payload []byte, inserted, deleted bool) (string, error) {
+	var links []syncx.Link
+	var warnings []string
+	if !deleted {
+		links, warnings = syncx.ExtractLinks(payload)
+	}
+	if !inserted {
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM link WHERE owner_id = $1 AND source_entity = $2 AND source_uid = $3`,
+			userID, entity, uid); err != nil {
+			return "", err
+		}
+	}
+	if len(links) == 0 {
+		return strings.Join(warnings, "; "), nil
+	}
+
+	entities := make([]string, len(links))
+	uids := make([]string, len(links))
+	wanted := make(map[string][]string)
+	for i, l := range links {
+		entities[i], uids[i] = l.Entity, l.UID.String()
+		wanted[l.Entity] = append(wanted[l.Entity], uids[i])
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO link (owner_id, source_entity, source_uid, target_entity, target_uid)
+		SELECT $1, $2, $3, t.entity, t.uid FROM unnest($4::text[], $5::uuid[]) AS t(entity, uid)
+		ON CONFLICT DO NOTHING
+	`, userID, entity, uid, entities, uids); err != nil {
+		return "", err
+	}
+
+	live, err := loadLiveParents(ctx, tx, userID, wanted)
+	if err != nil {
+		return "", err
+	}
+	for _, l := range links {
+		if !live[l.Entity][l.UID] {
+			warnings = append(warnings, fmt.Sprintf("links: %s %s not found", l.Entity, l.UID))
+		}
+	}
+	return strings.Join(warnings, "; "), nil
+}
+
+// ReindexLinks rebuilds the user's link index from the stored payloads within tx
+// For writes that bypass the push path (account restore). Links are read with
+// ExtractLinks as on push, but malformed ones aren't reported, just left out.
+func ReindexLinks(ctx context.Context, tx pgx.Tx, userID string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM link WHERE owner_id = $1`, userID); err != nil {
+		return err
+	}
+	var sources []string
+	for _, table := range linkSources {
+		sources = append(sources, `SELECT '`+table+`', uid::text, payload_json FROM `+table+
+			` WHERE owner_id = $1 AND deleted_at_ms IS NULL AND payload_json ? 'links'`)
+	}
+	rows, err := tx.Query(ctx, strings.Join(sources, " UNION ALL "), userID)
+	if err != nil {
+		return err
+	}
+	var srcEntities, srcUIDs, entities, uids []string
+	for rows.Next() {
+		var entity, uid string
+		var payload []byte
+		if err := rows.Scan(&entity, &uid, &payload); err != nil {
+			rows.Close()
+			return err
+		}
+		links, _ := syncx.ExtractLinks(payload)
+		for _, l := range links {
+			srcEntities, srcUIDs = append(srcEntities, entity), append(srcUIDs, uid)
+			entities, uids = append(entities, l.Entity), append(uids, l.UID.String())
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(entities) == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO link (owner_id, source_entity, source_uid, target_entity, target_uid)
+		SELECT $1, t.source_entity, t.source_uid, t.entity, t.uid
+		FROM unnest($2::text[], $3::uuid[], $4::text[], $5::uuid[]) AS t(source_entity, source_uid, entity, uid)
+		ON CONFLICT DO NOTHING
+	`, userID, srcEntities, srcUIDs, entities, uids)
+	return err
+}
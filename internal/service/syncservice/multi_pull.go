@@ -0,0 +1,55 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"golang.org/x/sync/errgroup"
+)
+
+// PullParallelism bounds how many entity pulls one multi-entity pull runs at
+// once, so a cold sync holds at most this many pool connections
+const PullParallelism = 4
+
+// PullFunc pulls one page of an entity type (e.g. NoteService.PullNotes)
+type PullFunc func(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error)
+
+// EntityPull is one entity's page request within a multi-entity pull
+type EntityPull struct {
+	Entity string
+	Cursor syncx.Cursor
+	Limit  int
+}
+
+// PullEntities runs the requested pulls concurrently (at most PullParallelism
+// at a time) and returns each entity's page keyed by entity name
+// The first failure cancels the remaining pulls and is returned.
+func PullEntities(ctx context.Context, userID string, pulls []EntityPull, pullers map[string]PullFunc) (map[string]*PullResponse, error) {
+	results := make([]*PullResponse, len(pulls))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(PullParallelism)
+	for i, p := range pulls {
+		pull, ok := pullers[p.Entity]
+		if !ok {
+			return nil, fmt.Errorf("unknown entity %q", p.Entity)
+		}
+		g.Go(func() error {
+			resp, err := pull(ctx, userID, p.Cursor, p.Limit)
+			if err != nil {
+				return fmt.Errorf("pull %s: %w", p.Entity, err)
+			}
+			results[i] = resp
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*PullResponse, len(pulls))
+	for i, p := range pulls {
+		out[p.Entity] = results[i]
+	}
+	return out, nil
+}
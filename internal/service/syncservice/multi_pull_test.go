@@ -0,0 +1,60 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestPullEntities(t *testing.T) {
+	var running, peak atomic.Int32
+	pull := func(name string) PullFunc {
+		return func(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			next := name
+			return &PullResponse{NextCursor: &next}, nil
+		}
+	}
+	pullers := map[string]PullFunc{}
+	var pulls []EntityPull
+	for _, e := range []string{"notes", "tasks", "comments", "chats", "chat_messages", "task_lists", "task_list_categories"} {
+		pullers[e] = pull(e)
+		pulls = append(pulls, EntityPull{Entity: e, Limit: 10})
+	}
+
+	got, err := PullEntities(context.Background(), "u1", pulls, pullers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range pulls {
+		if resp := got[p.Entity]; resp == nil || *resp.NextCursor != p.Entity {
+			t.Errorf("%s: got %+v", p.Entity, resp)
+		}
+	}
+	if p := peak.Load(); p < 2 || p > PullParallelism {
+		t.Errorf("peak concurrency = %d, want 2..%d", p, PullParallelism)
+	}
+
+	if _, err := PullEntities(context.Background(), "u1", []EntityPull{{Entity: "nope"}}, pullers); err == nil {
+		t.Error("unknown entity accepted")
+	}
+
+	pullers["tasks"] = func(context.Context, string, syncx.Cursor, int) (*PullResponse, error) {
+		return nil, errors.New("boom")
+	}
+	if _, err := PullEntities(context.Background(), "u1", pulls, pullers); err == nil {
+		t.Error("failed pull not reported")
+	}
+}
@@ -0,0 +1,199 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/textdiff"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxNoteRevisions is how many revisions are kept per note (oldest go first)
+const MaxNoteRevisions = 50
+
+// noteTextFields are the payload fields revisions track and diffs compare
+var noteTextFields = []string{"title", "content"}
+
+// ErrRevisionNotFound is returned for a version the note has no revision of
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// NoteRevision is a note's payload as of one version
+type NoteRevision struct {
+	Version    int            `json:"version"`
+	UpdatedAt  string         `json:"updatedAt"`  // Note timestamp of the write
+	RecordedAt time.Time      `json:"recordedAt"` // Server time the revision was stored
+	Payload    map[string]any `json:"payload"`
+}
+
+// RevisionDiff is the line diff of a note's text fields between two versions
+// Fields holds only the fields that differ.
+type RevisionDiff struct {
+	From   int                        `json:"from"`
+	To     int                        `json:"to"`
+	Fields map[string][]textdiff.Edit `json:"fields"`
+}
+
+// recordNoteRevision stores an applied live note write in the revision history
+// within tx, unless its title and content match the latest revision, then trims
+// the note's history to MaxNoteRevisions
+func recordNoteRevision(ctx context.Context, tx pgx.Tx, userID string, uid uuid.UUID, version int, updatedAtMs int64, payload []byte) error {
+	tag, err := tx.Exec(ctx, `
+		WITH latest AS (
+			SELECT payload_json FROM note_revision
+			WHERE owner_id = $1 AND note_uid = $2
+			ORDER BY version DESC LIMIT 1
+		)
+		INSERT INTO note_revision (owner_id, note_uid, version, updated_at_ms, payload_json)
+		SELECT $1, $2, $3, $4, $5::jsonb
+		WHERE NOT EXISTS (
+			SELECT 1 FROM latest
+			WHERE latest.payload_json->'title' IS NOT DISTINCT FROM $5::jsonb->'title'
+			  AND latest.payload_json->'content' IS NOT DISTINCT FROM $5::jsonb->'content'
+		)
+		ON CONFLICT (owner_id, note_uid, version) DO UPDATE SET
+			updated_at_ms = EXCLUDED.updated_at_ms,
+			payload_json  = EXCLUDED.payload_json,
+			created_at    = now()
+	`, userID, uid, version, updatedAtMs, payload)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM note_revision
+		WHERE owner_id = $1 AND note_uid = $2 AND version < (
+			SELECT version FROM note_revision
+			WHERE owner_id = $1 AND note_uid = $2
+			ORDER BY version DESC OFFSET $3 LIMIT 1
+		)
+	`, userID, uid, MaxNoteRevisions-1)
+	return err
+}
+
+// ListRevisions returns up to limit revisions of the user's note, newest first
+// Returns ErrItemNotFound if the note doesn't exist.
+func (s *NoteService) ListRevisions(ctx context.Context, userID string, uid uuid.UUID, limit int) ([]NoteRevision, error) {
+	var exists bool
+	if err := s.DB.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM note WHERE owner_id = $1 AND uid = $2)`,
+		userID, uid).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrItemNotFound
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT version, updated_at_ms, created_at, payload_json
+		FROM note_revision
+		WHERE owner_id = $1 AND note_uid = $2
+		ORDER BY version DESC
+		LIMIT $3
+	`, userID, uid, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to query note revisions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]NoteRevision, 0)
+	for rows.Next() {
+		var rev NoteRevision
+		var ms int64
+		if err := rows.Scan(&rev.Version, &ms, &rev.RecordedAt, &rev.Payload); err != nil {
+			return nil, err
+		}
+		rev.UpdatedAt = syncx.RFC3339(ms)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision returns the revision of the user's note at version
+// Returns ErrRevisionNotFound if there is none (never recorded, or trimmed).
+func (s *NoteService) GetRevision(ctx context.Context, userID string, uid uuid.UUID, version int) (*NoteRevision, error) {
+	rev := NoteRevision{Version: version}
+	var ms int64
+	err := s.DB.QueryRow(ctx, `
+		SELECT updated_at_ms, created_at, payload_json
+		FROM note_revision
+		WHERE owner_id = $1 AND note_uid = $2 AND version = $3
+	`, userID, uid, version).Scan(&ms, &rev.RecordedAt, &rev.Payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rev.UpdatedAt = syncx.RFC3339(ms)
+	return &rev, nil
+}
+
+// DiffRevisions diffs the title and content of the user's note between the
+// revisions at from and to; to 0 means the note as it is now
+func (s *NoteService) DiffRevisions(ctx context.Context, userID string, uid uuid.UUID, from, to int) (*RevisionDiff, error) {
+	old, err := s.GetRevision(ctx, userID, uid, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var newer map[string]any
+	if to == 0 {
+		current, err := s.GetNote(ctx, userID, uid)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, ErrItemNotFound
+		}
+		to, newer = current.Version, current.Payload
+	} else {
+		rev, err := s.GetRevision(ctx, userID, uid, to)
+		if err != nil {
+			return nil, err
+		}
+		newer = rev.Payload
+	}
+
+	diff := &RevisionDiff{From: from, To: to, Fields: map[string][]textdiff.Edit{}}
+	for _, field := range noteTextFields {
+		if edits := textdiff.Lines(fieldText(old.Payload[field]), fieldText(newer[field])); edits != nil {
+			diff.Fields[field] = edits
+		}
+	}
+	return diff, nil
+}
+
+// fieldText renders a payload field for diffing; non-string values (rich text
+// documents) diff as their JSON
+func fieldText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// RestoreRevision makes the revision at version the note's current content
+// It is a REST mutation with the revision's payload (a new version, synced to
+// every device), so opts can enforce If-Match.
+func (s *NoteService) RestoreRevision(ctx context.Context, userID string, uid uuid.UUID, version int, opts MutationOpts) (*RESTItem, error) {
+	rev, err := s.GetRevision(ctx, userID, uid, version)
+	if err != nil {
+		return nil, err
+	}
+	rev.Payload["uid"] = uid.String()
+	return s.ApplyNoteMutation(ctx, userID, rev.Payload, opts)
+}
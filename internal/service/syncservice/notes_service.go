@@ -3,7 +3,11 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -17,14 +21,52 @@ type PushAck struct {
 	Version   int    `json:"version"`
 	UpdatedAt string `json:"updatedAt"`
 	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"` // Machine-readable reason for some errors (e.g. syncx.ErrCodeClockSkew)
 	Applied   bool   `json:"applied,omitempty"`
+	Warning   string `json:"warning,omitempty"` // Non-fatal problems with an applied item (e.g. links to missing items)
+}
+
+// extractFailure is the ack for an item whose sync metadata was rejected
+// The UID is echoed when it parsed, so the client can tell which item failed.
+func extractFailure(ext syncx.Extracted, err error) PushAck {
+	ack := PushAck{Error: err.Error(), Code: syncx.ErrorCode(err)}
+	if ext.UID != uuid.Nil {
+		ack.UID = ext.UID.String()
+	}
+	return ack
+}
+
+// canonicalPayload returns the payload with its UID fields lower-cased when
+// the client sent them upper-case
+func canonicalPayload(payload json.RawMessage, ext syncx.Extracted) json.RawMessage {
+	if !ext.NonCanonical {
+		return payload
+	}
+	canonical, err := syncx.CanonicalizeUIDs(payload)
+	if err != nil {
+		return payload // Unreachable: extraction already decoded it as an object
+	}
+	return canonical
+}
+
+// encodeNextCursor signs the cursor after a page's last row for the user's
+// listing of entity (handlers check it with syncx.VerifyCursor)
+func encodeNextCursor(userID, entity string, lastMs int64, lastUID string) (*string, error) {
+	uid, ok := syncx.ParseUUID(lastUID)
+	if !ok {
+		return nil, fmt.Errorf("invalid uid %q in page", lastUID)
+	}
+	encoded := syncx.SignCursor(syncx.Cursor{Ms: lastMs, UID: uid}, userID, entity)
+	return &encoded, nil
 }
 
 // PullResponse represents the response from a pull operation
+// Upserts are the stored payloads as raw JSON, passed through without decoding.
 type PullResponse struct {
-	Upserts    []map[string]any `json:"upserts"`
-	Deletes    []map[string]any `json:"deletes"`
-	NextCursor *string          `json:"nextCursor,omitempty"`
+	Upserts    []json.RawMessage `json:"upserts"`
+	Deletes    []map[string]any  `json:"deletes"`
+	NextCursor *string           `json:"nextCursor,omitempty"` // Resume point (omitted on an empty page; keep the previous one)
+	HasMore    bool              `json:"hasMore"`              // More rows follow now: pull again with nextCursor
 }
 
 // NoteService encapsulates business logic for note sync operations
@@ -37,52 +79,81 @@ func NewNoteService(db *pgxpool.Pool) *NoteService {
 	return &NoteService{DB: db}
 }
 
-// PushNoteItem handles the push logic for a single note item within a transaction
+// Hot sync queries, prepared up front on each pool connection (db.HotStatement)
+var (
+	upsertNotesSQL = db.HotStatement("upsert_notes", `
+		WITH upsert AS (
+			INSERT INTO note (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				-- Bump version only on strictly newer update (not >=, just >)
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > note.updated_at_ms
+					THEN note.version + 1
+					ELSE note.version
+				END
+			WHERE EXCLUDED.updated_at_ms > note.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM note
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullNotesSQL = db.HotStatement("pull_notes", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM note
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushNoteItemJSON handles the push logic for a single note item within a transaction
 // Returns a PushAck with either success or error information
-func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+// payload is stored as-is, so each item is serialized only once; it is not
+// retained after the call returns (callers may reuse the buffer).
+func (s *NoteService) PushNoteItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	defer metrics.TimeOperation("notes", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
 	// Extract sync metadata from client JSON
-	ext, err := syncx.ExtractCommon(item)
+	ext, err := syncx.ExtractCommonJSON(payload)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
 	}
-
-	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "note", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
 		return PushAck{
 			UID:       ext.UID.String(),
 			Version:   ext.Version,
 			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
+			Error:     "failed to assign server timestamp",
 		}
 	}
 
 	// Insert or update with LWW conflict resolution
 	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
 	// If same timestamp arrives twice, version doesn't increment
-	tag, err := tx.Exec(ctx, `
-		INSERT INTO note (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > note.updated_at_ms
-				THEN note.version + 1
-				ELSE note.version
-			END
-		WHERE EXCLUDED.updated_at_ms > note.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
-
-	applied := false
-	if err == nil {
-		applied = tag.RowsAffected() > 0
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertNotesSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM note WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
 	}
 
 	if err != nil {
@@ -95,18 +166,38 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 		}
 	}
 
-	// Read back server state (authoritative version and timestamp)
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM note WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read note after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed, index their links and keep the
+	// revision history (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "note", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record note activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "note", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index note links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
+		}
+		if !tombstone {
+			if err := recordNoteRevision(ctx, tx, userID, ext.UID, serverVersion, serverMs, payload); err != nil {
+				logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record note revision")
+				return PushAck{
+					UID:       ext.UID.String(),
+					Version:   ext.Version,
+					UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+					Error:     "failed to record revision",
+				}
+			}
 		}
 	}
 
@@ -116,23 +207,27 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
 		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushNoteItem pushes a note given as a decoded map (REST mutations, integrations)
+func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
 	}
+	return s.PushNoteItemJSON(ctx, tx, userID, payload)
 }
 
 // PullNotes handles the pull logic for notes
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("notes", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
 	// Query notes ordered by (updated_at_ms, uid) for deterministic pagination
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM note
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullNotesSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query notes")
@@ -140,13 +235,21 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -156,6 +259,12 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -175,18 +284,21 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 		return nil, err
 	}
 
-	// Generate next cursor if we returned any results
+	// The cursor after the last row is where the next pull resumes, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "notes", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -248,7 +360,7 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list notes")
 		return nil, err
@@ -258,8 +370,15 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -292,23 +411,27 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 		return nil, err
 	}
 
-	// Generate next cursor if we have results
+	// The cursor after the last row is where the next page starts, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "notes", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyNoteMutation creates or updates a note via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("notes", metrics.OpMutation)()
 	logger := log.With().Logger()
 
 	// Start transaction
@@ -326,8 +449,8 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 	}
 	if noteUID == uuid.Nil {
 		noteUID = uuid.New()
-		payload["uid"] = noteUID.String()
 	}
+	payload["uid"] = noteUID.String() // Canonical form, whatever spelling the client used
 
 	// Fetch existing note to determine timestamp
 	var existingMs int64
@@ -349,6 +472,7 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      noteUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
@@ -401,6 +525,7 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 			mutatedPayload["isDeleted"] = 0
 		}
 		mutatedPayload["remoteUpdatedAt"] = ack.UpdatedAt
+		mutatedPayload["updatedTs"] = ack.UpdatedAt
 		mutatedPayload["updateTime"] = ack.UpdatedAt
 		mutatedPayload["lastSyncedAt"] = ack.UpdatedAt
 
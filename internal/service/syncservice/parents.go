@@ -0,0 +1,56 @@
+package syncservice
+
+import (
+	"context"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// liveParents maps parent type ("note", "task", "chat") to the UIDs of the
+// batch's parents that exist and are not soft-deleted (also used for link
+// targets, which can be any entity)
+// A nil liveParents means "not prefetched": items check their parent one query at a time.
+type liveParents map[string]map[uuid.UUID]bool
+
+// parentTables are the tables parent and link target types live in
+// (identifiers, never user input)
+var parentTables = map[string]string{
+	"note": "note", "task": "task", "task_list": "task_list", "task_list_category": "task_list_category",
+	"comment": "comment", "chat": "chat", "chat_message": "chat_message",
+}
+
+// loadLiveParents looks up the wanted parent UIDs with one query per parent type
+func loadLiveParents(ctx context.Context, tx pgx.Tx, userID string, wanted map[string][]string) (liveParents, error) {
+	live := make(liveParents, len(wanted))
+	for parentType, uids := range wanted {
+		table, ok := parentTables[parentType]
+		if !ok {
+			continue
+		}
+		found := make(map[uuid.UUID]bool, len(uids))
+		rows, err := tx.Query(ctx,
+			`SELECT uid::text FROM `+table+` WHERE owner_id = $1 AND uid = ANY($2::uuid[]) AND deleted_at_ms IS NULL`,
+			userID, uids)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var uid string
+			if err := rows.Scan(&uid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if id, ok := syncx.ParseUUID(uid); ok {
+				found[id] = true
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		live[parentType] = found
+	}
+	return live, nil
+}
@@ -0,0 +1,306 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// pinTargets are the entities that can be pinned, and their tables
+var pinTargets = map[string]string{"note": "note", "task": "task", "chat": "chat"}
+
+// PinService encapsulates business logic for pin (favorite) sync operations
+type PinService struct {
+	DB *pgxpool.Pool
+}
+
+// NewPinService creates a new PinService
+func NewPinService(db *pgxpool.Pool) *PinService {
+	return &PinService{DB: db}
+}
+
+// Hot pin sync queries
+var (
+	upsertPinsSQL = db.HotStatement("upsert_pins", `
+		WITH upsert AS (
+			INSERT INTO pin (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, target_type, target_uid)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				target_type    = EXCLUDED.target_type,
+				target_uid     = EXCLUDED.target_uid,
+				-- Bump version only on strictly newer update (not >=, just >)
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > pin.updated_at_ms
+					THEN pin.version + 1
+					ELSE pin.version
+				END
+			WHERE EXCLUDED.updated_at_ms > pin.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM pin
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullPinsSQL = db.HotStatement("pull_pins", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM pin
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushPinItemJSON handles the push logic for a single pin item within a transaction
+// Returns a PushAck with either success or error information
+// Validates that the pinned note, task or chat exists before upserting
+func (s *PinService) PushPinItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	defer metrics.TimeOperation("pins", metrics.OpPushItem)()
+	logger := log.With().Logger()
+
+	ext, err := syncx.ExtractPinJSON(payload)
+	if err != nil {
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
+	}
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "pin", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to assign server timestamp",
+		}
+	}
+
+	table, ok := pinTargets[ext.TargetType]
+	if !ok {
+		logger.Warn().Str("target_type", ext.TargetType).Msg("invalid target type")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     fmt.Sprintf("invalid targetType: %s (must be 'note', 'task' or 'chat')", ext.TargetType),
+		}
+	}
+
+	// Like comment parents, the target only has to be live when pinning: an
+	// unpin (tombstone) succeeds after the target is deleted
+	if ext.DeletedAtMs == nil {
+		var targetExists bool
+		err := tx.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM `+table+` WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL)`,
+			userID, *ext.TargetUID).Scan(&targetExists)
+		if err != nil {
+			logger.Error().Err(err).Str("target_uid", ext.TargetUID.String()).Msg("failed to check pin target existence")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to validate target",
+			}
+		}
+		if !targetExists {
+			logger.Warn().
+				Str("target_type", ext.TargetType).
+				Str("target_uid", ext.TargetUID.String()).
+				Msg("pin target not found")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     fmt.Sprintf("target %s not found: %s", ext.TargetType, ext.TargetUID.String()),
+			}
+		}
+	}
+
+	// Insert or update with LWW conflict resolution (see PushNoteItemJSON)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertPinsSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload, ext.TargetType, *ext.TargetUID).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM pin WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert pin")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "pin", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record pin activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "pin", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index pin links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
+		}
+	}
+
+	// Success - return server-authoritative values
+	return PushAck{
+		UID:       ext.UID.String(),
+		Version:   serverVersion,
+		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushPinItem pushes a pin given as a decoded map (integrations, seeding)
+func (s *PinService) PushPinItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
+	}
+	return s.PushPinItemJSON(ctx, tx, userID, payload)
+}
+
+// PullPins handles the pull logic for pins
+// Returns upserts, deletes, and an optional next cursor for pagination
+func (s *PinService) PullPins(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("pins", metrics.OpPullPage)()
+	logger := log.With().Logger()
+
+	// Query pins ordered by (updated_at_ms, uid) for deterministic pagination
+	rows, err := s.DB.Query(ctx, pullPinsSQL, userID, cursor.Ms, cursor.UID, limit+1)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to query pins")
+		return nil, err
+	}
+	defer rows.Close()
+
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
+	deletes := make([]map[string]any, 0)
+	var lastMs int64
+	var lastUID string
+	var hasMore bool
+
+	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
+		var deletedAtMs *int64
+		var ms int64
+		var uid string
+
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+			logger.Error().Err(err).Msg("failed to scan pin row")
+			return nil, err
+		}
+
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
+		if deletedAtMs != nil {
+			deletes = append(deletes, map[string]any{
+				"uid":       uid,
+				"deletedAt": syncx.RFC3339(*deletedAtMs),
+			})
+		} else {
+			upserts = append(upserts, payload)
+		}
+
+		lastMs, lastUID = ms, uid
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error().Err(err).Msg("row iteration error")
+		return nil, err
+	}
+
+	// The cursor after the last row is where the next pull resumes, whether or
+	// not hasMore says to make it now
+	var nextCursor *string
+	if len(upserts)+len(deletes) > 0 {
+		if nextCursor, err = encodeNextCursor(userID, "pins", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
+	}
+
+	return &PullResponse{
+		Upserts:    upserts,
+		Deletes:    deletes,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// Unpin tombstones a live pin with a server timestamp, as a REST delete would
+// Returns 1 if the pin was removed, 0 if it was already gone.
+func (s *PinService) Unpin(ctx context.Context, userID string, uid uuid.UUID) (int64, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var payload map[string]any
+	var updatedAtMs int64
+	err = tx.QueryRow(ctx, `
+		SELECT payload_json, updated_at_ms FROM pin
+		WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL
+		FOR UPDATE
+	`, userID, uid).Scan(&payload, &updatedAtMs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tombstone, err := json.Marshal(syncx.BuildServerMutation(payload, syncx.EnsureMonotonicTimestamp(updatedAtMs), true))
+	if err != nil {
+		return 0, err
+	}
+	if ack := s.PushPinItemJSON(ctx, tx, userID, tombstone); ack.Error != "" {
+		return 0, &MutationError{Message: ack.Error}
+	}
+	return 1, tx.Commit(ctx)
+}
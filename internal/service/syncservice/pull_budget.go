@@ -0,0 +1,30 @@
+package syncservice
+
+// MaxPullBytes caps the payload bytes in one pull page. Limit counts items, so
+// without it a page of large notes could run to hundreds of MB; a page that
+// reaches the cap ends early and its nextCursor resumes after the last item sent.
+const MaxPullBytes = 4 << 20
+
+// tombstoneBytes approximates one entry in a page's deletes
+// ({"uid":"…","deletedAt":"…"}); tombstones never carry their payload.
+const tombstoneBytes = 80
+
+// pullBudget tracks the bytes a pull page has used against its limit
+type pullBudget struct {
+	limit int
+	used  int
+}
+
+// fits reports whether the row fits in the page and, if so, charges it
+// The first row always fits so a single oversized item cannot stall a client.
+func (b *pullBudget) fits(payload []byte, tombstone bool) bool {
+	n := len(payload)
+	if tombstone {
+		n = tombstoneBytes
+	}
+	if b.used > 0 && b.used+n > b.limit {
+		return false
+	}
+	b.used += n
+	return true
+}
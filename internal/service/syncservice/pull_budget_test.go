@@ -0,0 +1,31 @@
+package syncservice
+
+import "testing"
+
+func TestPullBudget(t *testing.T) {
+	b := pullBudget{limit: 100}
+	big := make([]byte, 150)
+	if !b.fits(big, false) {
+		t.Fatal("first row rejected; an oversized item would never sync")
+	}
+	if b.fits(make([]byte, 1), false) {
+		t.Error("row accepted past the limit")
+	}
+
+	b = pullBudget{limit: 100}
+	for i := 0; i < 5; i++ {
+		if !b.fits(make([]byte, 20), false) {
+			t.Fatalf("row %d rejected at %d bytes used", i, b.used)
+		}
+	}
+	if b.fits(make([]byte, 1), false) {
+		t.Error("row accepted with the budget spent")
+	}
+
+	// Tombstones are charged their delete entry, not their stored payload
+	b = pullBudget{limit: 100}
+	b.fits(make([]byte, 10), false)
+	if !b.fits(big, true) {
+		t.Error("tombstone charged its payload size")
+	}
+}
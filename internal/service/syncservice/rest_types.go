@@ -15,6 +15,7 @@ type RESTItem struct {
 type RESTListResponse struct {
 	Items      []RESTItem `json:"items"`
 	NextCursor *string    `json:"nextCursor,omitempty"`
+	HasMore    bool       `json:"hasMore"` // Another page follows nextCursor
 }
 
 // MutationOpts configures REST mutation behavior
@@ -27,8 +28,9 @@ type MutationOpts struct {
 
 // VersionMismatchError indicates optimistic locking failure
 type VersionMismatchError struct {
-	Expected int
-	Actual   int
+	UID      string
+	Expected int // Version the client expected (If-Match)
+	Actual   int // Current server version
 }
 
 func (e *VersionMismatchError) Error() string {
@@ -0,0 +1,432 @@
+package syncservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Sharing permissions (task list memberships and item grants)
+const (
+	PermissionView = "view" // Pull the list and its tasks, or the item
+	PermissionEdit = "edit" // Also push tasks into the list, or edits to the item
+)
+
+// ErrCodeForbidden marks a shared push the member isn't allowed to make
+const ErrCodeForbidden = "forbidden"
+
+// Sharing errors
+var (
+	ErrListNotFound      = errors.New("task list not found")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrInvalidPermission = errors.New(`permission must be "view" or "edit"`)
+	ErrShareWithSelf     = errors.New("can't share with the owner")
+	ErrItemNotFound      = errors.New("item not found")
+)
+
+// ListMember is a user a task list or item is shared with
+type ListMember struct {
+	UserID     string    `json:"userId"`
+	Sub        string    `json:"sub"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SharedList is a task list another user shared with the caller
+type SharedList struct {
+	OwnerID    string    `json:"ownerId"`
+	ListUID    string    `json:"listUid"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SharingService manages task list memberships and item grants, and the
+// members' view of what is shared with them
+// Shared rows keep the owner's owner_id: members pull them through a join on
+// task_list_member or item_grant, and member pushes are written as the
+// owner's rows (so the owner's devices pull them as usual).
+type SharingService struct {
+	DB       *pgxpool.Pool
+	Tasks    *TaskService
+	Notes    *NoteService
+	Chats    *ChatService
+	Messages *ChatMessageService
+}
+
+// NewSharingService creates a new SharingService
+func NewSharingService(db *pgxpool.Pool, tasks *TaskService, notes *NoteService, chats *ChatService, messages *ChatMessageService) *SharingService {
+	return &SharingService{DB: db, Tasks: tasks, Notes: notes, Chats: chats, Messages: messages}
+}
+
+// AddMember shares the owner's live list with the user whose subject is sub,
+// or changes that member's permission
+func (s *SharingService) AddMember(ctx context.Context, ownerID string, listUID uuid.UUID, sub, permission string) (*ListMember, error) {
+	if permission != PermissionView && permission != PermissionEdit {
+		return nil, ErrInvalidPermission
+	}
+	var live bool
+	err := s.DB.QueryRow(ctx,
+		`SELECT deleted_at_ms IS NULL FROM task_list WHERE owner_id = $1 AND uid = $2`,
+		ownerID, listUID).Scan(&live)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !live) {
+		return nil, ErrListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := ListMember{Sub: sub, Permission: permission}
+	err = s.DB.QueryRow(ctx, `SELECT id::text FROM app_user WHERE sub = $1`, sub).Scan(&m.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.UserID == ownerID {
+		return nil, ErrShareWithSelf
+	}
+
+	err = s.DB.QueryRow(ctx, `
+		INSERT INTO task_list_member (owner_id, list_uid, member_id, permission)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (owner_id, list_uid, member_id) DO UPDATE SET permission = excluded.permission
+		RETURNING created_at
+	`, ownerID, listUID, m.UserID, permission).Scan(&m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMembers returns the members of one of the owner's lists
+func (s *SharingService) ListMembers(ctx context.Context, ownerID string, listUID uuid.UUID) ([]ListMember, error) {
+	var exists bool
+	if err := s.DB.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM task_list WHERE owner_id = $1 AND uid = $2)`,
+		ownerID, listUID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrListNotFound
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT m.member_id::text, u.sub, m.permission, m.created_at
+		FROM task_list_member m JOIN app_user u ON u.id = m.member_id
+		WHERE m.owner_id = $1 AND m.list_uid = $2
+		ORDER BY m.created_at, m.member_id
+	`, ownerID, listUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := make([]ListMember, 0)
+	for rows.Next() {
+		var m ListMember
+		if err := rows.Scan(&m.UserID, &m.Sub, &m.Permission, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// RemoveMember unshares the owner's list with memberID
+func (s *SharingService) RemoveMember(ctx context.Context, ownerID string, listUID uuid.UUID, memberID string) error {
+	tag, err := s.DB.Exec(ctx,
+		`DELETE FROM task_list_member WHERE owner_id = $1 AND list_uid = $2 AND member_id::text = $3`,
+		ownerID, listUID, memberID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Leave removes memberID from a list shared with them
+func (s *SharingService) Leave(ctx context.Context, memberID string, listUID uuid.UUID) error {
+	tag, err := s.DB.Exec(ctx,
+		`DELETE FROM task_list_member WHERE member_id = $1 AND list_uid = $2`,
+		memberID, listUID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// SharedWith returns the lists shared with memberID, oldest share first
+func (s *SharingService) SharedWith(ctx context.Context, memberID string) ([]SharedList, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT owner_id::text, list_uid::text, permission, created_at
+		FROM task_list_member
+		WHERE member_id = $1
+		ORDER BY created_at, owner_id, list_uid
+	`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	lists := make([]SharedList, 0)
+	for rows.Next() {
+		var l SharedList
+		if err := rows.Scan(&l.OwnerID, &l.ListUID, &l.Permission, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+// MembershipTag fingerprints the lists and items shared with memberID
+// Shared pull cursors are signed for "<entity>:<tag>", so adding, removing or
+// changing a membership or grant invalidates them and the client pulls from
+// scratch (a delta from the old cursor would miss the newly visible history,
+// or keep rows the member can no longer see).
+func (s *SharingService) MembershipTag(ctx context.Context, memberID string) (string, error) {
+	lists, err := s.SharedWith(ctx, memberID)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, l := range lists {
+		h.Write([]byte(l.OwnerID + "/" + l.ListUID + "/" + l.Permission + "\n"))
+	}
+	for _, entity := range []string{GrantNote, GrantChat} {
+		items, err := s.GrantedTo(ctx, memberID, entity)
+		if err != nil {
+			return "", err
+		}
+		for _, it := range items {
+			h.Write([]byte(entity + ":" + it.OwnerID + "/" + it.UID + "/" + it.Permission + "\n"))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// Shared pull queries (shared rows are matched on the owner's memberships, so
+// the member's own rows never appear)
+const (
+	pullSharedTaskListsSQL = `
+		SELECT l.payload_json, l.deleted_at_ms, l.updated_at_ms, l.uid
+		FROM task_list l
+		JOIN task_list_member m ON m.owner_id = l.owner_id AND m.list_uid = l.uid
+		WHERE m.member_id = $1
+		  AND (l.updated_at_ms, l.uid) > ($2, $3::uuid)
+		ORDER BY l.updated_at_ms, l.uid
+		LIMIT $4`
+	pullSharedTasksSQL = `
+		SELECT t.payload_json, t.deleted_at_ms, t.updated_at_ms, t.uid
+		FROM task t
+		JOIN task_list_member m ON m.owner_id = t.owner_id
+		  AND m.list_uid::text = lower(t.payload_json->>'taskListUid')
+		WHERE m.member_id = $1
+		  AND (t.updated_at_ms, t.uid) > ($2, $3::uuid)
+		ORDER BY t.updated_at_ms, t.uid
+		LIMIT $4`
+)
+
+// PullSharedTaskLists returns the lists shared with memberID
+// entity is the cursor scope ("shared_task_lists:" + MembershipTag).
+func (s *SharingService) PullSharedTaskLists(ctx context.Context, memberID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("shared_task_lists", metrics.OpPullPage)()
+	return s.pullShared(ctx, pullSharedTaskListsSQL, memberID, entity, cursor, limit)
+}
+
+// PullSharedTasks returns the tasks in lists shared with memberID
+// A task moved out of a shared list drops out of later pages rather than
+// being returned as a delete; clients see it gone on their next full pull.
+func (s *SharingService) PullSharedTasks(ctx context.Context, memberID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("shared_tasks", metrics.OpPullPage)()
+	return s.pullShared(ctx, pullSharedTasksSQL, memberID, entity, cursor, limit)
+}
+
+// pullShared runs one of the shared pull queries and pages it like the
+// per-owner pulls
+func (s *SharingService) pullShared(ctx context.Context, query, memberID, entity string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	logger := log.With().Logger()
+
+	rows, err := s.DB.Query(ctx, query, memberID, cursor.Ms, cursor.UID, limit+1)
+	if err != nil {
+		logger.Error().Err(err).Str("entity", entity).Msg("failed to query shared items")
+		return nil, err
+	}
+	defer rows.Close()
+
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
+	deletes := make([]map[string]any, 0)
+	var lastMs int64
+	var lastUID string
+	var hasMore bool
+
+	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
+		var deletedAtMs *int64
+		var ms int64
+		var uid string
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+			logger.Error().Err(err).Str("entity", entity).Msg("failed to scan shared row")
+			return nil, err
+		}
+
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
+		if deletedAtMs != nil {
+			deletes = append(deletes, map[string]any{
+				"uid":       uid,
+				"deletedAt": syncx.RFC3339(*deletedAtMs),
+			})
+		} else {
+			upserts = append(upserts, payload)
+		}
+		lastMs, lastUID = ms, uid
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error().Err(err).Msg("row iteration error")
+		return nil, err
+	}
+
+	var nextCursor *string
+	if len(upserts)+len(deletes) > 0 {
+		if nextCursor, err = encodeNextCursor(memberID, entity, lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
+	}
+
+	return &PullResponse{
+		Upserts:    upserts,
+		Deletes:    deletes,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// forbiddenAck is the ack for a shared push the member may not make
+func forbiddenAck(ext syncx.Extracted, msg string) PushAck {
+	return PushAck{
+		UID:       ext.UID.String(),
+		Version:   ext.Version,
+		UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+		Error:     msg,
+		Code:      ErrCodeForbidden,
+	}
+}
+
+// lookupFailureAck is the ack for a shared push whose permission check failed
+func lookupFailureAck(ext syncx.Extracted, msg string) PushAck {
+	return PushAck{
+		UID:       ext.UID.String(),
+		Version:   ext.Version,
+		UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+		Error:     msg,
+	}
+}
+
+// editableOwner returns the owner of the live list listUID shared with
+// memberID for editing ("" when there is none)
+func editableOwner(ctx context.Context, tx pgx.Tx, memberID string, listUID uuid.UUID) (string, error) {
+	var ownerID string
+	err := tx.QueryRow(ctx, `
+		SELECT m.owner_id::text
+		FROM task_list_member m
+		JOIN task_list l ON l.owner_id = m.owner_id AND l.uid = m.list_uid
+		WHERE m.member_id = $1 AND m.list_uid = $2 AND m.permission = 'edit' AND l.deleted_at_ms IS NULL
+		ORDER BY m.created_at
+		LIMIT 1
+	`, memberID, listUID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return ownerID, err
+}
+
+// PushSharedTaskItemJSON writes a member's task into a list shared with them
+// The item's taskListUid must name a live list the member can edit; the task
+// is stored as the list owner's. A task that already exists in another of
+// the owner's lists can only be moved if the member can edit that list too.
+func (s *SharingService) PushSharedTaskItemJSON(ctx context.Context, tx pgx.Tx, memberID string, payload json.RawMessage) PushAck {
+	logger := log.With().Logger()
+
+	ext, err := syncx.ExtractCommonJSON(payload)
+	if err != nil {
+		return extractFailure(ext, err)
+	}
+	forbidden := func(msg string) PushAck { return forbiddenAck(ext, msg) }
+
+	// The key must match exactly, as in the stored payload_json->>'taskListUid':
+	// a struct field would also take a "TASKLISTUID" key, checking one list
+	// while the task lands in another (see headerFromJSON)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(payload, &fields) // Already decoded once by the extractor
+	var listRef string
+	json.Unmarshal(fields["taskListUid"], &listRef) // Missing or not a string: no list
+	listUID, ok := syncx.ParseUUID(listRef)
+	if !ok {
+		return forbidden("shared tasks need the taskListUid of a shared list")
+	}
+	ownerID, err := editableOwner(ctx, tx, memberID, listUID)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to look up shared list")
+		return lookupFailureAck(ext, "shared list lookup failed")
+	}
+	if ownerID == "" {
+		return forbidden("task list is not shared with you for editing")
+	}
+
+	// Moving an existing task needs edit on the list it's leaving
+	var current *string
+	err = tx.QueryRow(ctx,
+		`SELECT payload_json->>'taskListUid' FROM task WHERE owner_id = $1 AND uid = $2`,
+		ownerID, ext.UID).Scan(&current)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to probe shared task")
+		return lookupFailureAck(ext, "shared task lookup failed")
+	}
+	if err == nil {
+		var from uuid.UUID // Nil for a task in no list, which no one shares
+		if current != nil {
+			from, _ = syncx.ParseUUID(*current)
+		}
+		if from != listUID {
+			owner, err := editableOwner(ctx, tx, memberID, from)
+			if err != nil {
+				logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to look up shared list")
+				return lookupFailureAck(ext, "shared list lookup failed")
+			}
+			if owner != ownerID {
+				return forbidden("task is in a list not shared with you for editing")
+			}
+		}
+	}
+
+	return s.Tasks.PushTaskItemJSON(ctx, tx, ownerID, payload)
+}
@@ -0,0 +1,86 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
+
+// BenchmarkStatementCache compares the statement cache modes on the hot push
+// and pull queries. Needs a migrated database:
+//
+//	TEST_DATABASE_URL=postgres://... go test ./internal/service/syncservice -run '^$' -bench StatementCache -benchmem
+func BenchmarkStatementCache(b *testing.B) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		b.Skip("TEST_DATABASE_URL not set, skipping database benchmarks")
+	}
+
+	modes := []struct {
+		name string
+		opts db.PoolOptions
+	}{
+		{"off", db.PoolOptions{StatementCache: db.StatementCacheOff}},
+		{"describe", db.PoolOptions{StatementCache: db.StatementCacheDescribe}},
+		{"prepare", db.PoolOptions{StatementCache: db.StatementCachePrepare}},
+		{"prepare_hot", db.PoolOptions{StatementCache: db.StatementCachePrepare, PrepareHot: true}},
+	}
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			ctx := context.Background()
+			pool, err := db.OpenWithOptions(ctx, dbURL, mode.opts)
+			if err != nil {
+				b.Fatalf("open: %v", err)
+			}
+			defer pool.Close()
+
+			var userID string
+			sub := "bench-statements-" + mode.name
+			if err := pool.QueryRow(ctx,
+				`INSERT INTO app_user (sub) VALUES ($1)
+				 ON CONFLICT (sub) DO UPDATE SET sub = EXCLUDED.sub
+				 RETURNING id`, sub).Scan(&userID); err != nil {
+				b.Fatalf("create user: %v", err)
+			}
+			defer pool.Exec(ctx, `DELETE FROM app_user WHERE id = $1`, userID)
+
+			svc := NewNoteService(pool)
+			base := time.Now().UnixMilli()
+
+			b.Run("push", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					payload, _ := json.Marshal(map[string]any{
+						"uid":       uuid.NewString(),
+						"updatedTs": syncx.RFC3339(base + int64(i)),
+						"title":     fmt.Sprintf("note %d", i),
+					})
+					tx, err := pool.Begin(ctx)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if ack := svc.PushNoteItemJSON(ctx, tx, userID, payload); ack.Error != "" {
+						b.Fatalf("push: %s", ack.Error)
+					}
+					if err := tx.Commit(ctx); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run("pull", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := svc.PullNotes(ctx, userID, syncx.Cursor{}, 100); err != nil {
+						b.Fatalf("pull: %v", err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -3,7 +3,10 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -21,41 +24,74 @@ func NewTaskListCategoryService(db *pgxpool.Pool) *TaskListCategoryService {
 	return &TaskListCategoryService{DB: db}
 }
 
-// PushTaskListCategoryItem handles the push logic for a single category item within a transaction
-func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+// Hot task list category sync queries
+var (
+	upsertTaskListCategoriesSQL = db.HotStatement("upsert_task_list_categories", `
+		WITH upsert AS (
+			INSERT INTO task_list_category (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
+					THEN task_list_category.version + 1
+					ELSE task_list_category.version
+				END
+			WHERE EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM task_list_category
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullTaskListCategoriesSQL = db.HotStatement("pull_task_list_categories", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM task_list_category
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushTaskListCategoryItemJSON handles the push logic for a single category item within a transaction
+func (s *TaskListCategoryService) PushTaskListCategoryItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	defer metrics.TimeOperation("task_list_categories", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
-	ext, err := syncx.ExtractCommon(item)
+	ext, err := syncx.ExtractCommonJSON(payload)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
 	}
-
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "task_list_category", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
 		return PushAck{
 			UID:       ext.UID.String(),
 			Version:   ext.Version,
 			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
+			Error:     "failed to assign server timestamp",
 		}
 	}
 
-	_, err = tx.Exec(ctx, `
-		INSERT INTO task_list_category (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
-				THEN task_list_category.version + 1
-				ELSE task_list_category.version
-			END
-		WHERE EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertTaskListCategoriesSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM task_list_category WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert task_list_category")
@@ -67,17 +103,26 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 		}
 	}
 
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM task_list_category WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read task_list_category after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "task_list_category", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record task_list_category activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "task_list_category", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index task_list_category links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
 		}
 	}
 
@@ -85,21 +130,26 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushTaskListCategoryItem pushes a task list category given as a decoded map (REST mutations, integrations)
+func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
 	}
+	return s.PushTaskListCategoryItemJSON(ctx, tx, userID, payload)
 }
 
 // PullTaskListCategories handles the pull logic for task list categories
 func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("task_list_categories", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM task_list_category
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullTaskListCategoriesSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query task_list_categories")
@@ -107,13 +157,21 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -123,6 +181,12 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			deletes = append(deletes, map[string]any{
 				"uid":       uid,
@@ -142,15 +206,17 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "task_list_categories", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -207,7 +273,7 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_list_categories")
 		return nil, err
@@ -217,8 +283,15 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -253,19 +326,22 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "task_list_categories", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyTaskListCategoryMutation creates or updates a category via REST
 func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("task_list_categories", metrics.OpMutation)()
 	logger := log.With().Logger()
 
 	tx, err := s.DB.Begin(ctx)
@@ -281,8 +357,8 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 	}
 	if categoryUID == uuid.Nil {
 		categoryUID = uuid.New()
-		payload["uid"] = categoryUID.String()
 	}
+	payload["uid"] = categoryUID.String() // Canonical form, whatever spelling the client used
 
 	var existingMs int64
 	var existingVersion int
@@ -302,6 +378,7 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      categoryUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
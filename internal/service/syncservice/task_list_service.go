@@ -3,7 +3,10 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -22,46 +25,78 @@ func NewTaskListService(db *pgxpool.Pool) *TaskListService {
 	return &TaskListService{DB: db}
 }
 
-// PushTaskListItem handles the push logic for a single task list item within a transaction
+// Hot task list sync queries
+var (
+	upsertTaskListsSQL = db.HotStatement("upsert_task_lists", `
+		WITH upsert AS (
+			INSERT INTO task_list (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > task_list.updated_at_ms
+					THEN task_list.version + 1
+					ELSE task_list.version
+				END
+			WHERE EXCLUDED.updated_at_ms > task_list.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM task_list
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullTaskListsSQL = db.HotStatement("pull_task_lists", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM task_list
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushTaskListItemJSON handles the push logic for a single task list item within a transaction
 // Returns a PushAck with either success or error information
-func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+func (s *TaskListService) PushTaskListItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	defer metrics.TimeOperation("task_lists", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
 	// Extract sync metadata from client JSON
-	ext, err := syncx.ExtractCommon(item)
+	ext, err := syncx.ExtractCommonJSON(payload)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
 	}
-
-	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "task_list", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
 		return PushAck{
 			UID:       ext.UID.String(),
 			Version:   ext.Version,
 			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
+			Error:     "failed to assign server timestamp",
 		}
 	}
 
 	// Insert or update with LWW conflict resolution
 	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
-	_, err = tx.Exec(ctx, `
-		INSERT INTO task_list (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > task_list.updated_at_ms
-				THEN task_list.version + 1
-				ELSE task_list.version
-			END
-		WHERE EXCLUDED.updated_at_ms > task_list.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertTaskListsSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM task_list WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert task_list")
@@ -73,18 +108,26 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 		}
 	}
 
-	// Read back server state (authoritative version and timestamp)
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM task_list WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read task_list after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "task_list", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record task_list activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "task_list", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index task_list links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
 		}
 	}
 
@@ -92,21 +135,26 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushTaskListItem pushes a task list given as a decoded map (REST mutations, integrations)
+func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
 	}
+	return s.PushTaskListItemJSON(ctx, tx, userID, payload)
 }
 
 // PullTaskLists handles the pull logic for task lists
 func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("task_lists", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM task_list
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullTaskListsSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query task_lists")
@@ -114,13 +162,21 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -130,6 +186,12 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			deletes = append(deletes, map[string]any{
 				"uid":       uid,
@@ -149,15 +211,17 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "task_lists", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -214,7 +278,7 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_lists")
 		return nil, err
@@ -224,8 +288,15 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -260,19 +331,22 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "task_lists", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyTaskListMutation creates or updates a task list via REST
 func (s *TaskListService) ApplyTaskListMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("task_lists", metrics.OpMutation)()
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to begin transaction")
@@ -305,8 +379,8 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 	}
 	if taskListUID == uuid.Nil {
 		taskListUID = uuid.New()
-		payload["uid"] = taskListUID.String()
 	}
+	payload["uid"] = taskListUID.String() // Canonical form, whatever spelling the client used
 
 	// Fetch existing to determine timestamp
 	var existingMs int64
@@ -328,6 +402,7 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      taskListUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
@@ -455,6 +530,7 @@ type DeleteTaskListResult struct {
 // DeleteTaskListWithOrphan atomically orphans tasks and soft-deletes the task list
 // This ensures both operations succeed or fail together
 func (s *TaskListService) DeleteTaskListWithOrphan(ctx context.Context, userID string, taskListUID uuid.UUID, payload map[string]any) (*DeleteTaskListResult, error) {
+	defer metrics.TimeOperation("task_lists", metrics.OpMutation)()
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to begin transaction for task list deletion")
@@ -3,7 +3,10 @@ package syncservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/metrics"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -21,48 +24,80 @@ func NewTaskService(db *pgxpool.Pool) *TaskService {
 	return &TaskService{DB: db}
 }
 
-// PushTaskItem handles the push logic for a single task item within a transaction
+// Hot task sync queries
+var (
+	upsertTasksSQL = db.HotStatement("upsert_tasks", `
+		WITH upsert AS (
+			INSERT INTO task (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
+			VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+			ON CONFLICT (owner_id, uid) DO UPDATE SET
+				payload_json   = EXCLUDED.payload_json,
+				updated_at_ms  = EXCLUDED.updated_at_ms,
+				deleted_at_ms  = EXCLUDED.deleted_at_ms,
+				-- Bump version only on strictly newer update (not >=, just >)
+				version        = CASE
+					WHEN EXCLUDED.updated_at_ms > task.updated_at_ms
+					THEN task.version + 1
+					ELSE task.version
+				END
+			WHERE EXCLUDED.updated_at_ms > task.updated_at_ms
+			RETURNING version, updated_at_ms, xmax = 0 AS inserted, deleted_at_ms IS NOT NULL AS tombstone
+		)
+		SELECT version, updated_at_ms, inserted, tombstone, true FROM upsert
+		UNION ALL
+		SELECT version, updated_at_ms, false, deleted_at_ms IS NOT NULL, false FROM task
+		WHERE owner_id = $2 AND uid = $1 AND NOT EXISTS (SELECT 1 FROM upsert)
+	`)
+	pullTasksSQL = db.HotStatement("pull_tasks", `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		FROM task
+		WHERE owner_id = $1
+		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		ORDER BY updated_at_ms, uid
+		LIMIT $4
+	`)
+)
+
+// PushTaskItemJSON handles the push logic for a single task item within a transaction
 // Returns a PushAck with either success or error information
-func (s *TaskService) PushTaskItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+func (s *TaskService) PushTaskItemJSON(ctx context.Context, tx pgx.Tx, userID string, payload json.RawMessage) PushAck {
+	defer metrics.TimeOperation("tasks", metrics.OpPushItem)()
 	logger := log.With().Logger()
 
 	// Extract sync metadata from client JSON
-	ext, err := syncx.ExtractCommon(item)
+	ext, err := syncx.ExtractCommonJSON(payload)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Bytes("item", payload).Msg("failed to extract sync metadata")
+		return extractFailure(ext, err)
 	}
-
-	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
-	if err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
+	payload = canonicalPayload(payload, ext)
+	if payload, err = serverStamp(ctx, tx, "task", userID, &ext, payload); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to assign server timestamp")
 		return PushAck{
 			UID:       ext.UID.String(),
 			Version:   ext.Version,
 			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "payload serialization error",
+			Error:     "failed to assign server timestamp",
 		}
 	}
 
 	// Insert or update with LWW conflict resolution
 	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
 	// If same timestamp arrives twice, version doesn't increment
-	_, err = tx.Exec(ctx, `
-		INSERT INTO task (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
-		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > task.updated_at_ms
-				THEN task.version + 1
-				ELSE task.version
-			END
-		WHERE EXCLUDED.updated_at_ms > task.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+	// One round trip: the CTE returns the row as written, or the existing row when
+	// the LWW guard made the upsert a no-op
+	// xmax = 0 only for a row this statement inserted (not for an ON CONFLICT update)
+	var serverVersion int
+	var serverMs int64
+	var inserted, tombstone, applied bool
+	err = tx.QueryRow(ctx, upsertTasksSQL, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payload).Scan(&serverVersion, &serverMs, &inserted, &tombstone, &applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent push committed the row after this statement's snapshot and
+		// won the LWW guard; read it back with a fresh snapshot
+		err = tx.QueryRow(ctx,
+			`SELECT version, updated_at_ms, deleted_at_ms IS NOT NULL FROM task WHERE uid = $1 AND owner_id = $2`,
+			ext.UID, userID).Scan(&serverVersion, &serverMs, &tombstone)
+	}
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert task")
@@ -74,18 +109,26 @@ func (s *TaskService) PushTaskItem(ctx context.Context, tx pgx.Tx, userID string
 		}
 	}
 
-	// Read back server state (authoritative version and timestamp)
-	var serverVersion int
-	var serverMs int64
-	if err := tx.QueryRow(ctx,
-		`SELECT version, updated_at_ms FROM task WHERE uid = $1 AND owner_id = $2`,
-		ext.UID, userID).Scan(&serverVersion, &serverMs); err != nil {
-		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to read task after upsert")
-		return PushAck{
-			UID:       ext.UID.String(),
-			Version:   ext.Version,
-			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
-			Error:     "failed to confirm write",
+	// Log applied writes for the activity feed and index their links (same transaction as the change)
+	var warning string
+	if applied {
+		if err := recordActivity(ctx, tx, userID, "task", ext.UID, serverVersion, serverMs, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record task activity")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to record activity",
+			}
+		}
+		if warning, err = indexLinks(ctx, tx, userID, "task", ext.UID, payload, inserted, tombstone); err != nil {
+			logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to index task links")
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     "failed to index links",
+			}
 		}
 	}
 
@@ -94,23 +137,28 @@ func (s *TaskService) PushTaskItem(ctx context.Context, tx pgx.Tx, userID string
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
 		UpdatedAt: syncx.RFC3339(serverMs),
+		Applied:   applied,
+		Warning:   warning,
+	}
+}
+
+// PushTaskItem pushes a task given as a decoded map (REST mutations, integrations)
+func (s *TaskService) PushTaskItem(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) PushAck {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return PushAck{Error: "payload serialization error"}
 	}
+	return s.PushTaskItemJSON(ctx, tx, userID, payload)
 }
 
 // PullTasks handles the pull logic for tasks
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *TaskService) PullTasks(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	defer metrics.TimeOperation("tasks", metrics.OpPullPage)()
 	logger := log.With().Logger()
 
 	// Query tasks ordered by (updated_at_ms, uid) for deterministic pagination
-	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
-		FROM task
-		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
-		ORDER BY updated_at_ms, uid
-		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, pullTasksSQL, userID, cursor.Ms, cursor.UID, limit+1)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query tasks")
@@ -118,13 +166,21 @@ func (s *TaskService) PullTasks(ctx context.Context, userID string, cursor syncx
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
+	upserts := make([]json.RawMessage, 0, limit)
+	budget := pullBudget{limit: MaxPullBytes}
 	deletes := make([]map[string]any, 0)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
-		var payload map[string]any
+		// The query reads one row past the page; if it's there, more follow
+		if len(upserts)+len(deletes) == limit {
+			hasMore = true
+			break
+		}
+
+		var payload []byte
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
@@ -134,6 +190,12 @@ func (s *TaskService) PullTasks(ctx context.Context, userID string, cursor syncx
 			return nil, err
 		}
 
+		// End the page early once it reaches MaxPullBytes
+		if !budget.fits(payload, deletedAtMs != nil) {
+			hasMore = true
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -153,18 +215,21 @@ func (s *TaskService) PullTasks(ctx context.Context, userID string, cursor syncx
 		return nil, err
 	}
 
-	// Generate next cursor if we returned any results
+	// The cursor after the last row is where the next pull resumes, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "tasks", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &PullResponse{
 		Upserts:    upserts,
 		Deletes:    deletes,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -226,7 +291,7 @@ func (s *TaskService) ListTasks(ctx context.Context, userID string, cursor syncx
 	}
 	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
 
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit+1)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list tasks")
 		return nil, err
@@ -236,8 +301,15 @@ func (s *TaskService) ListTasks(ctx context.Context, userID string, cursor syncx
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	var hasMore bool
 
 	for rows.Next() {
+		// The query reads one row past the page; if it's there, more follow
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
@@ -270,23 +342,27 @@ func (s *TaskService) ListTasks(ctx context.Context, userID string, cursor syncx
 		return nil, err
 	}
 
-	// Generate next cursor if we have results
+	// The cursor after the last row is where the next page starts, whether or
+	// not hasMore says to make it now
 	var nextCursor *string
 	if len(items) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		if nextCursor, err = encodeNextCursor(userID, "tasks", lastMs, lastUID); err != nil {
+			logger.Error().Err(err).Msg("failed to encode next cursor")
+			return nil, err
+		}
 	}
 
 	return &RESTListResponse{
 		Items:      items,
 		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}, nil
 }
 
 // ApplyTaskMutation creates or updates a task via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *TaskService) ApplyTaskMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	defer metrics.TimeOperation("tasks", metrics.OpMutation)()
 	logger := log.With().Logger()
 
 	// Start transaction
@@ -304,8 +380,8 @@ func (s *TaskService) ApplyTaskMutation(ctx context.Context, userID string, payl
 	}
 	if taskUID == uuid.Nil {
 		taskUID = uuid.New()
-		payload["uid"] = taskUID.String()
 	}
+	payload["uid"] = taskUID.String() // Canonical form, whatever spelling the client used
 
 	// Fetch existing task to determine timestamp
 	var existingMs int64
@@ -327,6 +403,7 @@ func (s *TaskService) ApplyTaskMutation(ctx context.Context, userID string, payl
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
+				UID:      taskUID.String(),
 				Expected: opts.ExpectedVersion,
 				Actual:   existingVersion,
 			}
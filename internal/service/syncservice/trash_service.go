@@ -0,0 +1,266 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotInTrash is returned when restoring an item that isn't deleted
+var ErrNotInTrash = errors.New("item is not in the trash")
+
+// trashKeep are the payload fields a purged tombstone keeps: identity, sync
+// metadata and parent references (so integrity checks and exports still
+// understand the row). Everything else, the content, is dropped.
+var trashKeep = []string{"uid", "sync", "updatedTs", "updateTime", "parentType", "parentUid", "chatUid", "taskListUid", "categoryUid"}
+
+// trashType is an entity the trash lists, by its sync path name
+type trashType struct {
+	table string
+	get   func(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error)
+	apply func(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error)
+}
+
+// TrashItem is a deleted item that can still be restored
+type TrashItem struct {
+	UID       string         `json:"uid"`
+	DeletedAt string         `json:"deletedAt"`
+	ExpiresAt string         `json:"expiresAt,omitempty"` // When the purge job drops its content (no retention: never)
+	Payload   map[string]any `json:"payload"`
+}
+
+// TrashPage is a page of the trash, most recently deleted first
+type TrashPage struct {
+	Items      []TrashItem `json:"items"`
+	NextCursor *string     `json:"nextCursor,omitempty"`
+}
+
+// TrashService exposes soft-deleted items as a trash (recycle bin)
+// Tombstones stay the sync mechanism: restoring is a REST mutation that revives
+// the item as a new version, and purging ("delete forever") replaces the
+// tombstone's payload with its trashKeep fields rather than deleting the row, so
+// devices that haven't pulled since the delete still receive it. Purged items
+// leave the trash; the purge job purges items deleted more than Retention ago.
+type TrashService struct {
+	DB        *pgxpool.Pool
+	Retention time.Duration // 0 keeps deleted items in the trash until purged by hand
+	BatchSize int           // Max tombstones purged per table per statement
+
+	types map[string]trashType
+}
+
+// NewTrashService creates a trash with the given retention window
+func NewTrashService(db *pgxpool.Pool, retention time.Duration) *TrashService {
+	notes, tasks, comments := NewNoteService(db), NewTaskService(db), NewCommentService(db)
+	chats, messages := NewChatService(db), NewChatMessageService(db)
+	lists, categories := NewTaskListService(db), NewTaskListCategoryService(db)
+	return &TrashService{
+		DB:        db,
+		Retention: retention,
+		BatchSize: 1000,
+		types: map[string]trashType{
+			"notes":                {"note", notes.GetNote, notes.ApplyNoteMutation},
+			"tasks":                {"task", tasks.GetTask, tasks.ApplyTaskMutation},
+			"comments":             {"comment", comments.GetComment, comments.ApplyCommentMutation},
+			"chats":                {"chat", chats.GetChat, chats.ApplyChatMutation},
+			"chat_messages":        {"chat_message", messages.GetChatMessage, messages.ApplyChatMessageMutation},
+			"task_lists":           {"task_list", lists.GetTaskList, lists.ApplyTaskListMutation},
+			"task_list_categories": {"task_list_category", categories.GetTaskListCategory, categories.ApplyTaskListCategoryMutation},
+		},
+	}
+}
+
+// Type reports whether name is a trash type (notes, tasks, ...)
+func (s *TrashService) Type(name string) bool {
+	_, ok := s.types[name]
+	return ok
+}
+
+// cutoffMs is the deleted_at_ms before which items have left the trash (0: none)
+func (s *TrashService) cutoffMs() int64 {
+	if s.Retention <= 0 {
+		return 0
+	}
+	return syncx.NowMs() - s.Retention.Milliseconds()
+}
+
+// inTrash is the condition for a row still in the trash ($cutoff is cutoffMs)
+func inTrash(cutoff string) string {
+	return `deleted_at_ms IS NOT NULL AND deleted_at_ms >= ` + cutoff + ` AND NOT payload_json ? 'purged'`
+}
+
+// List returns a page of the user's trash of one type, most recently deleted first
+// before resumes after the previous page (zero cursor: from the start).
+func (s *TrashService) List(ctx context.Context, userID, typ string, before syncx.Cursor, limit int) (*TrashPage, error) {
+	t := s.types[typ]
+	cutoff := s.cutoffMs()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT uid::text, deleted_at_ms, payload_json
+		FROM `+t.table+`
+		WHERE owner_id = $1 AND `+inTrash("$2")+`
+		  AND ($3::bigint = 0 OR (deleted_at_ms, uid) < ($3, $4::uuid))
+		ORDER BY deleted_at_ms DESC, uid DESC
+		LIMIT $5
+	`, userID, cutoff, before.Ms, before.UID, limit)
+	if err != nil {
+		log.Error().Err(err).Str("type", typ).Msg("failed to query trash")
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &TrashPage{Items: make([]TrashItem, 0, limit)}
+	var lastMs int64
+	var lastUID string
+	for rows.Next() {
+		var it TrashItem
+		if err := rows.Scan(&it.UID, &lastMs, &it.Payload); err != nil {
+			return nil, err
+		}
+		it.DeletedAt = syncx.RFC3339(lastMs)
+		if s.Retention > 0 {
+			it.ExpiresAt = syncx.RFC3339(lastMs + s.Retention.Milliseconds())
+		}
+		lastUID = it.UID
+		page.Items = append(page.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A full page may have more behind it
+	if len(page.Items) == limit {
+		if page.NextCursor, err = encodeNextCursor(userID, "trash:"+typ, lastMs, lastUID); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// Restore revives a trashed item as a new version, with the content it had
+// when deleted. Returns ErrItemNotFound if it's gone (never existed, purged or
+// past retention) and ErrNotInTrash if it isn't deleted. Children whose parent
+// is still deleted can't be restored (MutationError from the push validation).
+func (s *TrashService) Restore(ctx context.Context, userID, typ string, uid uuid.UUID) (*RESTItem, error) {
+	t := s.types[typ]
+	item, err := t.get(ctx, userID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if item.DeletedAt == nil {
+		return nil, ErrNotInTrash
+	}
+	deletedAt, _ := time.Parse(time.RFC3339Nano, *item.DeletedAt)
+	if _, purged := item.Payload["purged"]; purged || deletedAt.UnixMilli() < s.cutoffMs() {
+		return nil, ErrItemNotFound
+	}
+	return t.apply(ctx, userID, item.Payload, MutationOpts{})
+}
+
+// Purge permanently drops the content of the user's trashed items: one item,
+// or with uid nil the whole trash of that type. Returns how many were purged.
+func (s *TrashService) Purge(ctx context.Context, userID, typ string, uid *uuid.UUID) (int64, error) {
+	t := s.types[typ]
+	var n int64
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		var err error
+		n, err = purge(ctx, tx, t.table,
+			`owner_id = $1 AND `+inTrash("$2")+` AND ($3::uuid IS NULL OR uid = $3)`,
+			userID, s.cutoffMs(), uid)
+		return err
+	})
+	return n, err
+}
+
+// purge strips the content from the tombstones of table matching where, along
+// with what else holds it (note revisions, item grants)
+func purge(ctx context.Context, tx pgx.Tx, table, where string, args ...any) (int64, error) {
+	revisions := ""
+	if table == "note" {
+		revisions = `, revisions AS (
+			DELETE FROM note_revision r USING purged p
+			WHERE r.owner_id = p.owner_id AND r.note_uid = p.uid
+			RETURNING 1
+		)`
+	}
+	var n int64
+	err := tx.QueryRow(ctx, `
+		WITH purged AS (
+			UPDATE `+table+` SET payload_json = coalesce((
+				SELECT jsonb_object_agg(key, value) FROM jsonb_each(payload_json) WHERE key = ANY($`+fmt.Sprint(len(args)+1)+`)
+			), '{}'::jsonb) || '{"purged": true}'::jsonb
+			WHERE `+where+`
+			RETURNING owner_id, uid
+		), grants AS (
+			DELETE FROM item_grant g USING purged p
+			WHERE g.owner_id = p.owner_id AND g.entity = '`+table+`' AND g.item_uid = p.uid
+			RETURNING 1
+		)`+revisions+`
+		SELECT count(*) FROM purged
+	`, append(args, trashKeep)...).Scan(&n)
+	return n, err
+}
+
+// PurgeExpired purges every user's items deleted more than Retention ago
+// Returns how many were purged.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	cutoff := s.cutoffMs()
+	if cutoff == 0 {
+		return 0, nil
+	}
+	var total int64
+	for _, t := range s.types {
+		for {
+			var n int64
+			err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+				var err error
+				n, err = purge(ctx, tx, t.table, `(owner_id, uid) IN (
+					SELECT owner_id, uid FROM `+t.table+`
+					WHERE deleted_at_ms IS NOT NULL AND deleted_at_ms < $1 AND NOT payload_json ? 'purged'
+					LIMIT $2
+				)`, cutoff, s.BatchSize)
+				return err
+			})
+			if err != nil {
+				return total, fmt.Errorf("purge %s: %w", t.table, err)
+			}
+			total += n
+			if n < int64(s.BatchSize) {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// Run purges expired trash every interval until ctx is cancelled
+func (s *TrashService) Run(ctx context.Context, interval time.Duration) {
+	if s == nil || s.Retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("trash purge failed; will retry")
+		} else if n > 0 {
+			log.Info().Int64("purged", n).Msg("purged expired trash")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/clock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// queryTimeout bounds each session query (the Store methods take no context)
+const queryTimeout = 5 * time.Second
+
+// PostgresStore keeps sessions in the sync_session table, so they survive
+// restarts and are valid on every replica
+// Database errors are logged and treated as "no session": the client is asked
+// to begin a new one rather than the request failing with a 500.
+type PostgresStore struct {
+	db  *pgxpool.Pool
+	ttl time.Duration
+
+	Clock clock.Clock // Expiry time source (clock.Real), not the database's now()
+}
+
+// NewPostgresStore returns a store backed by pool (requires migration 0020)
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: pool, ttl: TTL, Clock: clock.Real}
+}
+
+// CreateSession generates a new session ID for the user
+func (s *PostgresStore) CreateSession(userID string, epoch int) Session {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	now := s.Clock.Now().UTC()
+	session := Session{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+		Epoch:     epoch,
+	}
+	if _, err := s.db.Exec(ctx, `
+		INSERT INTO sync_session (id, owner_id, epoch, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		session.ID, userID, epoch, session.CreatedAt, session.ExpiresAt); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("failed to store sync session")
+		return session
+	}
+
+	// Clean up expired sessions opportunistically
+	if _, err := s.db.Exec(ctx, `DELETE FROM sync_session WHERE expires_at < $1`, now); err != nil {
+		log.Warn().Err(err).Msg("failed to delete expired sync sessions")
+	}
+	return session
+}
+
+// GetSession retrieves an unexpired session by ID
+func (s *PostgresStore) GetSession(sessionID string) (Session, bool) {
+	if uuid.Validate(sessionID) != nil {
+		return Session{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var session Session
+	err := s.db.QueryRow(ctx, `
+		SELECT id, owner_id, epoch, created_at, expires_at
+		FROM sync_session
+		WHERE id = $1 AND expires_at > $2`, sessionID, s.Clock.Now()).
+		Scan(&session.ID, &session.UserID, &session.Epoch, &session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Error().Err(err).Str("sessionId", sessionID).Msg("failed to load sync session")
+		}
+		return Session{}, false
+	}
+	session.CreatedAt, session.ExpiresAt = session.CreatedAt.UTC(), session.ExpiresAt.UTC()
+	return session, true
+}
+
+// DeleteSession removes a session
+func (s *PostgresStore) DeleteSession(sessionID string) bool {
+	if uuid.Validate(sessionID) != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM sync_session WHERE id = $1`, sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("failed to delete sync session")
+		return false
+	}
+	return tag.RowsAffected() > 0
+}
+
+// DeleteUserSessions removes all sessions for a given user.
+// Returns the number of sessions deleted.
+func (s *PostgresStore) DeleteUserSessions(userID string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM sync_session WHERE owner_id = $1`, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("failed to delete user sync sessions")
+		return 0
+	}
+	return int(tag.RowsAffected())
+}
+
+// UserSessions returns the active (unexpired) sessions for a user
+func (s *PostgresStore) UserSessions(userID string) []Session {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, owner_id, epoch, created_at, expires_at
+		FROM sync_session
+		WHERE owner_id = $1 AND expires_at > $2
+		ORDER BY created_at`, userID, s.Clock.Now())
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("failed to list sync sessions")
+		return nil
+	}
+	defer rows.Close()
+	var out []Session
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.Epoch, &session.CreatedAt, &session.ExpiresAt); err != nil {
+			log.Error().Err(err).Str("userId", userID).Msg("failed to scan sync session")
+			return nil
+		}
+		session.CreatedAt, session.ExpiresAt = session.CreatedAt.UTC(), session.ExpiresAt.UTC()
+		out = append(out, session)
+	}
+	return out
+}
+
+// ActiveCounts returns the number of active (unexpired) sessions per user
+func (s *PostgresStore) ActiveCounts() map[string]int {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	counts := make(map[string]int)
+	rows, err := s.db.Query(ctx, `
+		SELECT owner_id, count(*) FROM sync_session
+		WHERE expires_at > $1
+		GROUP BY owner_id`, s.Clock.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to count sync sessions")
+		return counts
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var owner string
+		var n int
+		if err := rows.Scan(&owner, &n); err != nil {
+			log.Error().Err(err).Msg("failed to scan sync session count")
+			return counts
+		}
+		counts[owner] = n
+	}
+	return counts
+}
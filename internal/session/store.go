@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/clock"
 	"github.com/google/uuid"
 )
 
@@ -16,34 +17,72 @@ type Session struct {
 	Epoch     int       `json:"epoch"` // Tenant epoch for wipe/reset coordination
 }
 
+// TTL is how long a session stays valid after it is created
+const TTL = 30 * time.Minute
+
 // Store manages active sync sessions
-type Store struct {
+// GetSession, UserSessions and ActiveCounts never return expired sessions.
+type Store interface {
+	CreateSession(userID string, epoch int) Session
+	GetSession(sessionID string) (Session, bool)
+	DeleteSession(sessionID string) bool
+	DeleteUserSessions(userID string) int
+	UserSessions(userID string) []Session
+	ActiveCounts() map[string]int
+}
+
+// MemoryStore keeps sessions in process memory
+// Sessions are lost on restart and are not shared between replicas, so it
+// suits single-instance and dev deployments; see PostgresStore.
+type MemoryStore struct {
 	mu       sync.RWMutex
 	sessions map[string]Session // key: sessionId
 	ttl      time.Duration
+
+	Clock clock.Clock // Expiry time source (clock.Real; tests may swap in a clock.Fake)
 }
 
-// Global session store (in-memory)
-var globalStore = &Store{
-	sessions: make(map[string]Session),
-	ttl:      30 * time.Minute, // Sessions expire after 30 minutes
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]Session),
+		ttl:      TTL,
+		Clock:    clock.Real,
+	}
 }
 
-// GetStore returns the singleton session store
-func GetStore() *Store {
+// Global session store (in-memory until SetStore is called at startup)
+var (
+	globalMu    sync.RWMutex
+	globalStore Store = NewMemoryStore()
+)
+
+// GetStore returns the shared session store
+func GetStore() Store {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
 	return globalStore
 }
 
+// SetStore replaces the shared session store
+// Call it at startup, before serving requests: sessions in the previous store are not carried over.
+func SetStore(s Store) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalStore = s
+}
+
 // CreateSession generates a new session ID for the user
-func (s *Store) CreateSession(userID string, epoch int) Session {
+func (s *MemoryStore) CreateSession(userID string, epoch int) Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := s.Clock.Now().UTC()
 	session := Session{
 		ID:        uuid.New().String(),
 		UserID:    userID,
-		CreatedAt: time.Now().UTC(),
-		ExpiresAt: time.Now().UTC().Add(s.ttl),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
 		Epoch:     epoch,
 	}
 
@@ -56,7 +95,7 @@ func (s *Store) CreateSession(userID string, epoch int) Session {
 }
 
 // GetSession retrieves a session by ID
-func (s *Store) GetSession(sessionID string) (Session, bool) {
+func (s *MemoryStore) GetSession(sessionID string) (Session, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -66,7 +105,7 @@ func (s *Store) GetSession(sessionID string) (Session, bool) {
 	}
 
 	// Check if expired
-	if time.Now().UTC().After(session.ExpiresAt) {
+	if s.Clock.Now().UTC().After(session.ExpiresAt) {
 		return Session{}, false
 	}
 
@@ -74,7 +113,7 @@ func (s *Store) GetSession(sessionID string) (Session, bool) {
 }
 
 // DeleteSession removes a session
-func (s *Store) DeleteSession(sessionID string) bool {
+func (s *MemoryStore) DeleteSession(sessionID string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -89,7 +128,7 @@ func (s *Store) DeleteSession(sessionID string) bool {
 // DeleteUserSessions removes all sessions for a given user.
 // Returns the number of sessions deleted.
 // Used when wiping account data to invalidate all device sessions.
-func (s *Store) DeleteUserSessions(userID string) int {
+func (s *MemoryStore) DeleteUserSessions(userID string) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -103,9 +142,39 @@ func (s *Store) DeleteUserSessions(userID string) int {
 	return count
 }
 
+// UserSessions returns the active (unexpired) sessions for a user
+func (s *MemoryStore) UserSessions(userID string) []Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.Clock.Now().UTC()
+	var out []Session
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && !now.After(sess.ExpiresAt) {
+			out = append(out, sess)
+		}
+	}
+	return out
+}
+
+// ActiveCounts returns the number of active (unexpired) sessions per user
+func (s *MemoryStore) ActiveCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.Clock.Now().UTC()
+	counts := make(map[string]int)
+	for _, sess := range s.sessions {
+		if !now.After(sess.ExpiresAt) {
+			counts[sess.UserID]++
+		}
+	}
+	return counts
+}
+
 // cleanupExpiredLocked removes expired sessions (caller must hold write lock)
-func (s *Store) cleanupExpiredLocked() {
-	now := time.Now().UTC()
+func (s *MemoryStore) cleanupExpiredLocked() {
+	now := s.Clock.Now().UTC()
 	for id, session := range s.sessions {
 		if now.After(session.ExpiresAt) {
 			delete(s.sessions, id)
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/clock"
+)
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC))
+	s := NewMemoryStore()
+	s.Clock = fake
+
+	sess := s.CreateSession("u1", 1)
+	if !sess.ExpiresAt.Equal(fake.Now().Add(TTL)) {
+		t.Errorf("ExpiresAt = %s, want created + TTL", sess.ExpiresAt)
+	}
+
+	fake.Advance(TTL)
+	if _, ok := s.GetSession(sess.ID); !ok {
+		t.Error("session expired at exactly its TTL")
+	}
+	if n := s.ActiveCounts()["u1"]; n != 1 {
+		t.Errorf("active sessions = %d, want 1", n)
+	}
+
+	fake.Advance(time.Millisecond)
+	if _, ok := s.GetSession(sess.ID); ok {
+		t.Error("session still valid after its TTL")
+	}
+	if got := s.UserSessions("u1"); len(got) != 0 {
+		t.Errorf("UserSessions = %v, want none", got)
+	}
+
+	// Creating another session sweeps the expired one
+	s.CreateSession("u2", 1)
+	if _, ok := s.sessions[sess.ID]; ok {
+		t.Error("expired session not cleaned up")
+	}
+}
@@ -0,0 +1,38 @@
+package session
+
+import "sync/atomic"
+
+// Validation levels for the X-Sync-Session header on sync requests (HTTP and gRPC)
+const (
+	ValidationOff      = "off"      // Not required or checked; a session that is sent is only recorded
+	ValidationStandard = "standard" // Required, unexpired and owned by the authenticated user
+	ValidationStrict   = "strict"   // Standard, and begun at the epoch the client claims in X-Sync-Epoch
+)
+
+// ValidLevel reports whether level is a known validation level
+func ValidLevel(level string) bool {
+	switch level {
+	case ValidationOff, ValidationStandard, ValidationStrict:
+		return true
+	}
+	return false
+}
+
+var validation atomic.Pointer[string]
+
+// SetValidation sets how strictly sync requests' sessions are checked
+// Unknown levels fall back to standard.
+func SetValidation(level string) {
+	if !ValidLevel(level) {
+		level = ValidationStandard
+	}
+	validation.Store(&level)
+}
+
+// GetValidation returns the current validation level (standard until set)
+func GetValidation() string {
+	if level := validation.Load(); level != nil {
+		return *level
+	}
+	return ValidationStandard
+}
@@ -0,0 +1,161 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Slack request signing headers
+const (
+	HeaderSignature = "X-Slack-Signature"
+	HeaderTimestamp = "X-Slack-Request-Timestamp"
+)
+
+// maxSkew is how old a signed Slack request may be (replay protection)
+const maxSkew = 5 * time.Minute
+
+// Sign computes Slack's v0 request signature for body
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a Slack request signature and timestamp
+func (s *Service) Verify(timestamp, signature string, body []byte, now time.Time) bool {
+	if s.SigningSecret == "" {
+		return false
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(s.SigningSecret, timestamp, body)), []byte(signature))
+}
+
+// envelope is the Events API request body
+type envelope struct {
+	Type      string `json:"type"` // url_verification or event_callback
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type    string `json:"type"`
+		Subtype string `json:"subtype"` // Set for edits, joins, bot posts, ...
+		BotID   string `json:"bot_id"`
+		Channel string `json:"channel"`
+		User    string `json:"user"`
+		Text    string `json:"text"`
+		TS      string `json:"ts"`
+	} `json:"event"`
+}
+
+// HandleEvent processes a verified Events API request
+// Returns the challenge for url_verification requests. Plain user messages in
+// a channel are appended to every chat connected to it with ingestReplies.
+// Slack retries deliveries; message UIDs derive from the connection and the
+// message ts, so a retried event rewrites the same chat message.
+func (s *Service) HandleEvent(ctx context.Context, body []byte) (string, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return "", &ValidationError{Msg: "invalid event json"}
+	}
+	if env.Type == "url_verification" {
+		return env.Challenge, nil
+	}
+	ev := env.Event
+	if env.Type != "event_callback" || ev.Type != "message" || ev.Subtype != "" || ev.BotID != "" || ev.Channel == "" || ev.Text == "" {
+		return "", nil // Our own mirrored posts arrive as bot messages and are skipped here
+	}
+	sent, err := parseTS(ev.TS)
+	if err != nil {
+		return "", &ValidationError{Msg: "invalid message ts"}
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT subscription_id, owner_id::text, chat_uid::text
+		FROM slack_connection
+		WHERE channel_id = $1 AND ingest_replies
+	`, ev.Channel)
+	if err != nil {
+		return "", err
+	}
+	type target struct {
+		id      uuid.UUID
+		userID  string
+		chatUID string
+	}
+	var targets []target
+	for rows.Next() {
+		var t target
+		if err := rows.Scan(&t.id, &t.userID, &t.chatUID); err != nil {
+			rows.Close()
+			return "", err
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	ctx = syncservice.WithChangeSource(ctx, syncservice.ChangeSource{DeviceID: "slack"})
+	for _, t := range targets {
+		item := map[string]any{
+			"uid":       uuid.NewSHA1(t.id, []byte(ev.TS)).String(),
+			"updatedTs": sent.UTC().Format(time.RFC3339Nano),
+			"sync":      map[string]any{"version": float64(1)},
+			"chatUid":   t.chatUID,
+			"role":      "user",
+			"content":   ev.Text,
+			"source":    sourceSlack,
+			"slackUser": ev.User,
+			"slackTs":   ev.TS,
+		}
+		err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+			if ack := s.Messages.PushChatMessageItem(ctx, tx, t.userID, item); ack.Error != "" {
+				// The chat was deleted after connecting; nothing to append to
+				log.Warn().Str("userId", t.userID).Str("chatUid", t.chatUID).Str("error", ack.Error).Msg("slack reply not ingested")
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// parseTS parses a Slack message ts ("1700000000.123456")
+func parseTS(ts string) (time.Time, error) {
+	secs, frac, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var usec int64
+	if frac != "" {
+		if len(frac) > 6 {
+			frac = frac[:6]
+		}
+		frac += strings.Repeat("0", 6-len(frac))
+		if usec, err = strconv.ParseInt(frac, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid ts %q", ts)
+		}
+	}
+	return time.Unix(sec, usec*1000), nil
+}
@@ -0,0 +1,79 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxTextLen keeps mirrored messages under Slack's message size limit
+const maxTextLen = 39000
+
+// sourceSlack marks chat messages ingested from Slack, so they aren't echoed back
+const sourceSlack = "slack"
+
+// Name implements outbox.Publisher
+func (s *Service) Name() string { return "slack" }
+
+// Publish implements outbox.Publisher: it queues a webhook delivery of each
+// newly created chat message to the Slack connection of its chat
+// Runs in the dispatcher's transaction; the webhook worker sends the deliveries
+// with its usual retries, and they show up in the connection's delivery log.
+func (s *Service) Publish(ctx context.Context, tx pgx.Tx, events []outbox.Event) error {
+	for _, e := range events {
+		if e.Entity != "chat_message" || e.Action != "created" {
+			continue
+		}
+		var subscriptionID string
+		var raw []byte
+		err := tx.QueryRow(ctx, `
+			SELECT c.subscription_id::text, m.payload_json
+			FROM chat_message m
+			JOIN slack_connection c ON c.owner_id = m.owner_id AND c.chat_uid = m.chat_uid
+			WHERE m.owner_id = $1 AND m.uid = $2 AND m.deleted_at_ms IS NULL
+		`, e.OwnerID, e.UID).Scan(&subscriptionID, &raw)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // Chat isn't connected (or the message is already gone)
+		}
+		if err != nil {
+			return err
+		}
+
+		var payload map[string]any
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			continue
+		}
+		if src, _ := syncx.GetString(payload, "source"); src == sourceSlack {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload)
+			VALUES ($1, $2, 'chat_message.created', $3)
+			ON CONFLICT (subscription_id, event_id) DO NOTHING
+		`, subscriptionID, e.ID, map[string]any{"text": messageText(payload)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slackEscaper escapes the characters Slack's mrkdwn treats as control sequences
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// messageText renders a chat message for Slack as "*role*: content"
+func messageText(payload map[string]any) string {
+	role, _ := syncx.GetString(payload, "role")
+	if role == "" {
+		role = "user"
+	}
+	content, _ := syncx.GetString(payload, "content")
+	if len(content) > maxTextLen {
+		content = strings.ToValidUTF8(content[:maxTextLen], "") + "…"
+	}
+	return "*" + slackEscaper.Replace(role) + "*: " + slackEscaper.Replace(content)
+}
@@ -0,0 +1,206 @@
+// Package slack mirrors toolbridge chats to Slack channels.
+// Outgoing messages reuse the webhook pipeline: a connection owns a webhook
+// subscription (kind 'slack') pointing at the channel's incoming webhook, and
+// Publisher queues one delivery per new chat message from the outbox. Replies
+// posted in the channel can be ingested back as chat messages via the Events API.
+package slack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxConnections caps Slack connections per user
+const MaxConnections = 10
+
+// ErrNotFound is returned when a connection doesn't exist (or belongs to another user)
+var ErrNotFound = errors.New("slack connection not found")
+
+// ErrLimit is returned when the user already has MaxConnections connections
+var ErrLimit = errors.New("slack connection limit reached")
+
+// ErrExists is returned when the chat is already connected
+var ErrExists = errors.New("chat already connected to slack")
+
+// ValidationError describes an invalid connection request
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Connection mirrors one chat to one Slack channel
+// The incoming webhook URL is a credential and is never returned.
+type Connection struct {
+	ID            string    `json:"id"`
+	ChatUID       string    `json:"chatUid"`
+	ChannelID     string    `json:"channelId,omitempty"`
+	IngestReplies bool      `json:"ingestReplies"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ConnectionRequest is the input to Create
+type ConnectionRequest struct {
+	ChatUID       string `json:"chatUid"`
+	WebhookURL    string `json:"webhookUrl"` // Slack incoming webhook for the channel
+	ChannelID     string `json:"channelId"`  // Required with IngestReplies
+	IngestReplies bool   `json:"ingestReplies"`
+}
+
+// Service manages connections and ingests Slack events
+type Service struct {
+	DB            *pgxpool.Pool
+	Messages      *syncservice.ChatMessageService
+	SigningSecret string   // Slack app signing secret (empty disables the events endpoint)
+	WebhookHosts  []string // Accepted incoming webhook hosts
+}
+
+// NewService creates a Slack service
+func NewService(db *pgxpool.Pool, signingSecret string) *Service {
+	return &Service{
+		DB:            db,
+		Messages:      syncservice.NewChatMessageService(db),
+		SigningSecret: signingSecret,
+		WebhookHosts:  []string{"hooks.slack.com"},
+	}
+}
+
+// channelIDPattern matches Slack conversation IDs (public, private, DM)
+var channelIDPattern = regexp.MustCompile(`^[CGD][A-Z0-9]{6,}$`)
+
+// validate checks a connection request
+func (s *Service) validate(req ConnectionRequest) (uuid.UUID, error) {
+	chatUID, err := uuid.Parse(req.ChatUID)
+	if err != nil {
+		return uuid.Nil, &ValidationError{Msg: "chatUid must be a UUID"}
+	}
+	u, err := url.Parse(req.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return uuid.Nil, &ValidationError{Msg: "webhookUrl must be a Slack incoming webhook URL"}
+	}
+	allowed := false
+	for _, h := range s.WebhookHosts {
+		allowed = allowed || u.Host == h
+	}
+	if !allowed {
+		return uuid.Nil, &ValidationError{Msg: "webhookUrl must be a Slack incoming webhook URL"}
+	}
+	if req.ChannelID != "" && !channelIDPattern.MatchString(req.ChannelID) {
+		return uuid.Nil, &ValidationError{Msg: "channelId must be a Slack channel ID (e.g. C0123456789)"}
+	}
+	if req.IngestReplies && req.ChannelID == "" {
+		return uuid.Nil, &ValidationError{Msg: "channelId is required to ingest replies"}
+	}
+	return chatUID, nil
+}
+
+// Create connects one of the user's chats to a Slack channel
+func (s *Service) Create(ctx context.Context, userID string, req ConnectionRequest) (*Connection, error) {
+	chatUID, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	conn := Connection{ChatUID: chatUID.String(), ChannelID: req.ChannelID, IngestReplies: req.IngestReplies}
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Serialize per-user creates so the limit check can't race
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('slack:' || $1))`, userID); err != nil {
+			return err
+		}
+
+		var chatExists bool
+		var n int
+		var connected bool
+		if err := tx.QueryRow(ctx, `
+			SELECT
+				EXISTS (SELECT 1 FROM chat WHERE owner_id = $1 AND uid = $2 AND deleted_at_ms IS NULL),
+				(SELECT count(*) FROM slack_connection WHERE owner_id = $1),
+				EXISTS (SELECT 1 FROM slack_connection WHERE owner_id = $1 AND chat_uid = $2)
+		`, userID, chatUID).Scan(&chatExists, &n, &connected); err != nil {
+			return err
+		}
+		switch {
+		case !chatExists:
+			return &ValidationError{Msg: "chat not found: " + chatUID.String()}
+		case connected:
+			return ErrExists
+		case n >= MaxConnections:
+			return ErrLimit
+		}
+
+		// Deliveries are signed like any webhook; Slack ignores the headers
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO webhook_subscription (owner_id, url, secret, entities, kind)
+			VALUES ($1, $2, $3, '{chat_message}', 'slack')
+			RETURNING id::text, created_at
+		`, userID, req.WebhookURL, "whsec_"+hex.EncodeToString(secret)).Scan(&conn.ID, &conn.CreatedAt); err != nil {
+			return err
+		}
+		var channel *string
+		if req.ChannelID != "" {
+			channel = &req.ChannelID
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO slack_connection (subscription_id, owner_id, chat_uid, channel_id, ingest_replies, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, conn.ID, userID, chatUID, channel, req.IngestReplies, conn.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// List returns the user's connections, oldest first
+func (s *Service) List(ctx context.Context, userID string) ([]Connection, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT subscription_id::text, chat_uid::text, coalesce(channel_id, ''), ingest_replies, created_at
+		FROM slack_connection
+		WHERE owner_id = $1
+		ORDER BY created_at, subscription_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Connection{}
+	for rows.Next() {
+		var c Connection
+		if err := rows.Scan(&c.ID, &c.ChatUID, &c.ChannelID, &c.IngestReplies, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// Delete disconnects a chat; pending deliveries are dropped with the subscription
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	tag, err := s.DB.Exec(ctx, `
+		DELETE FROM webhook_subscription
+		WHERE id = $1 AND owner_id = $2 AND kind = 'slack'
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
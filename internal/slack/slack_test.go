@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	s := NewService(nil, "")
+	ok := ConnectionRequest{
+		ChatUID:    "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c",
+		WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+	}
+	if _, err := s.validate(ok); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+
+	for name, mutate := range map[string]func(*ConnectionRequest){
+		"bad chat":        func(r *ConnectionRequest) { r.ChatUID = "nope" },
+		"http url":        func(r *ConnectionRequest) { r.WebhookURL = "http://hooks.slack.com/services/x" },
+		"other host":      func(r *ConnectionRequest) { r.WebhookURL = "https://example.com/services/x" },
+		"bad channel":     func(r *ConnectionRequest) { r.ChannelID = "#general" },
+		"ingest no chan":  func(r *ConnectionRequest) { r.IngestReplies = true },
+		"credentials url": func(r *ConnectionRequest) { r.WebhookURL = "https://u:p@hooks.slack.com/services/x" },
+	} {
+		req := ok
+		mutate(&req)
+		var verr *ValidationError
+		if _, err := s.validate(req); !errors.As(err, &verr) {
+			t.Errorf("%s: got %v, want ValidationError", name, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s := NewService(nil, "8f742231b10e8888abcd99yyyzzz85a5")
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"type":"event_callback"}`)
+	sig := Sign(s.SigningSecret, ts, body)
+
+	if !s.Verify(ts, sig, body, now) {
+		t.Error("valid signature rejected")
+	}
+	if s.Verify(ts, sig, []byte(`{}`), now) {
+		t.Error("tampered body accepted")
+	}
+	if s.Verify(ts, sig, body, now.Add(10*time.Minute)) {
+		t.Error("stale timestamp accepted")
+	}
+	if NewService(nil, "").Verify(ts, sig, body, now) {
+		t.Error("verification must fail without a signing secret")
+	}
+}
+
+func TestHandleEventWithoutDB(t *testing.T) {
+	s := NewService(nil, "secret")
+	challenge, err := s.HandleEvent(context.Background(), []byte(`{"type":"url_verification","challenge":"abc"}`))
+	if err != nil || challenge != "abc" {
+		t.Errorf("url_verification = %q, %v", challenge, err)
+	}
+	// Bot posts (including our own mirrored messages) and edits are ignored before any lookup
+	for _, body := range []string{
+		`{"type":"event_callback","event":{"type":"message","bot_id":"B1","channel":"C1234567","text":"hi","ts":"1.2"}}`,
+		`{"type":"event_callback","event":{"type":"message","subtype":"message_changed","channel":"C1234567","ts":"1.2"}}`,
+	} {
+		if _, err := s.HandleEvent(context.Background(), []byte(body)); err != nil {
+			t.Errorf("HandleEvent(%s) = %v", body, err)
+		}
+	}
+}
+
+func TestParseTS(t *testing.T) {
+	got, err := parseTS("1700000000.000123")
+	if err != nil || !got.Equal(time.Unix(1700000000, 123000)) {
+		t.Errorf("parseTS = %v, %v", got, err)
+	}
+	if _, err := parseTS("abc"); err == nil {
+		t.Error("invalid ts accepted")
+	}
+}
+
+func TestMessageText(t *testing.T) {
+	got := messageText(map[string]any{"role": "assistant", "content": "a < b & c"})
+	if want := "*assistant*: a &lt; b &amp; c"; got != want {
+		t.Errorf("messageText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+// Package syncclient is a minimal Go client for the HTTP sync API
+// It handles the session/epoch handshake and rate-limit retries the way
+// clients are expected to, and is used by the loadtest subcommand.
+package syncclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Conflict errors; match with errors.Is, or errors.As a *ConflictError for the
+// server's current state
+var (
+	// ErrEpochMismatch is returned when the server's epoch moved (wipe or restore)
+	// The client has already started a new session at the new epoch; callers
+	// should discard local state before retrying.
+	ErrEpochMismatch = errors.New("epoch mismatch")
+	// ErrVersionMismatch is returned when an item changed since it was read
+	// (If-Match failed); callers re-read the item before retrying.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// ConflictError is a 409/412 conflict response
+type ConflictError struct {
+	Status  int
+	Code    string `json:"error"` // epoch_mismatch or version_mismatch
+	Message string `json:"message"`
+	UID     string `json:"uid"`
+	Version int    `json:"version"` // Server's current item version (version_mismatch)
+	Epoch   int    `json:"epoch"`   // Server's current epoch (epoch_mismatch)
+}
+
+func (e *ConflictError) Error() string {
+	if e.UID != "" {
+		return fmt.Sprintf("HTTP %d: %s for %s (server version %d)", e.Status, e.Code, e.UID, e.Version)
+	}
+	return fmt.Sprintf("HTTP %d: %s (server epoch %d)", e.Status, e.Code, e.Epoch)
+}
+
+// Is matches ErrEpochMismatch and ErrVersionMismatch by code
+func (e *ConflictError) Is(target error) bool {
+	switch target {
+	case ErrEpochMismatch:
+		return e.Code == "epoch_mismatch"
+	case ErrVersionMismatch:
+		return e.Code == "version_mismatch"
+	}
+	return false
+}
+
+// conflict decodes a 409/412 body, or returns nil if it isn't a conflict
+func conflict(status int, body []byte) *ConflictError {
+	if status != http.StatusConflict && status != http.StatusPreconditionFailed {
+		return nil
+	}
+	ce := &ConflictError{Status: status}
+	if json.Unmarshal(body, ce) != nil || (ce.Code != "epoch_mismatch" && ce.Code != "version_mismatch") {
+		return nil
+	}
+	return ce
+}
+
+// StatusError is a non-2xx response
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, strings.TrimSpace(e.Body))
+}
+
+// PushAck mirrors the per-item push acknowledgement
+type PushAck struct {
+	UID       string `json:"uid"`
+	Version   int    `json:"version"`
+	UpdatedAt string `json:"updatedAt"`
+	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Warning   string `json:"warning,omitempty"`
+}
+
+// PullResponse mirrors the pull response
+type PullResponse struct {
+	Upserts    []map[string]any `json:"upserts"`
+	Deletes    []map[string]any `json:"deletes"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+	HasMore    bool             `json:"hasMore"`
+}
+
+// Client talks to one server as one user
+type Client struct {
+	BaseURL string // e.g. http://localhost:8081
+	Token   string // Bearer token; if empty, Subject is sent as X-Debug-Sub (dev mode only)
+	Subject string
+	Tenant  string // X-TB-Tenant-ID
+	HTTP    *http.Client
+
+	// MaxRetries bounds retries on 429 (honoring Retry-After)
+	MaxRetries int
+	// OnRetry, if set, is called before each rate-limit retry
+	OnRetry func(wait time.Duration)
+
+	mu      sync.Mutex
+	session string
+	epoch   int
+}
+
+// New creates a client with a 30s HTTP timeout and 3 rate-limit retries
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTP:       &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: 3,
+	}
+}
+
+// Session returns the current session ID and epoch (empty before BeginSession)
+func (c *Client) Session() (string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session, c.epoch
+}
+
+// BeginSession starts a sync session (POST /v1/sync/sessions)
+func (c *Client) BeginSession(ctx context.Context) error {
+	var s struct {
+		ID    string `json:"id"`
+		Epoch int    `json:"epoch"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/sync/sessions", nil, &s); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.session, c.epoch = s.ID, s.Epoch
+	c.mu.Unlock()
+	return nil
+}
+
+// Push sends items to POST /v1/sync/{entity}/push (entity as in the URL, e.g. "notes")
+func (c *Client) Push(ctx context.Context, entity string, items []map[string]any) ([]PushAck, error) {
+	var acks []PushAck
+	err := c.do(ctx, http.MethodPost, "/v1/sync/"+entity+"/push", map[string]any{"items": items}, &acks)
+	return acks, err
+}
+
+// Pull fetches one page from GET /v1/sync/{entity}/pull
+func (c *Client) Pull(ctx context.Context, entity, cursor string, limit int) (*PullResponse, error) {
+	q := url.Values{}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/v1/sync/" + entity + "/pull"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var resp PullResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Wipe deletes all of the user's synced data (POST /v1/sync/wipe) and returns
+// the new epoch
+// The server ends every session, so call BeginSession before syncing again.
+func (c *Client) Wipe(ctx context.Context) (int, error) {
+	var resp struct {
+		Epoch int `json:"epoch"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/sync/wipe", map[string]string{"confirm": "WIPE"}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Epoch, nil
+}
+
+// do sends one request, retrying on 429 and renewing the session on epoch mismatch
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		c.setHeaders(req, body != nil)
+
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		ce := conflict(resp.StatusCode, data)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < c.MaxRetries:
+			wait := retryAfter(resp.Header.Get("Retry-After"))
+			if c.OnRetry != nil {
+				c.OnRetry(wait)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+
+		case ce != nil:
+			if errors.Is(ce, ErrEpochMismatch) {
+				if err := c.BeginSession(ctx); err != nil {
+					return err
+				}
+			}
+			return ce
+
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return &StatusError{Status: resp.StatusCode, Body: string(data)}
+		}
+
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, out)
+	}
+}
+
+func (c *Client) setHeaders(req *http.Request, hasBody bool) {
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else if c.Subject != "" {
+		req.Header.Set("X-Debug-Sub", c.Subject)
+	}
+	if c.Tenant != "" {
+		req.Header.Set("X-TB-Tenant-ID", c.Tenant)
+	}
+	session, epoch := c.Session()
+	if session != "" {
+		req.Header.Set("X-Sync-Session", session)
+		req.Header.Set("X-Sync-Epoch", strconv.Itoa(epoch))
+	}
+}
+
+// retryAfter parses a Retry-After seconds value (default 1s when absent or invalid)
+func retryAfter(v string) time.Duration {
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	return time.Second
+}
@@ -0,0 +1,83 @@
+package syncclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientSessionEpochAndRetry(t *testing.T) {
+	var epoch atomic.Int32
+	epoch.Store(1)
+	var throttled atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Debug-Sub") != "load-1" || r.Header.Get("X-TB-Tenant-ID") != "t1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/sync/sessions":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "s1", "epoch": epoch.Load()})
+		case "/v1/sync/notes/push":
+			if !throttled.Swap(true) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			if r.Header.Get("X-Sync-Epoch") != "1" {
+				t.Errorf("X-Sync-Epoch = %q, want 1", r.Header.Get("X-Sync-Epoch"))
+			}
+			_ = json.NewEncoder(w).Encode([]PushAck{{UID: "u1", Version: 1}})
+		case "/v1/sync/notes/pull":
+			if r.Header.Get("X-Sync-Epoch") != "2" {
+				w.WriteHeader(http.StatusConflict)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "epoch_mismatch", "epoch": 2})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(PullResponse{Upserts: []map[string]any{{"uid": "u1"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	c.Subject, c.Tenant = "load-1", "t1"
+	retries := 0
+	c.OnRetry = func(time.Duration) { retries++ }
+	ctx := context.Background()
+
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatalf("BeginSession: %v", err)
+	}
+	acks, err := c.Push(ctx, "notes", []map[string]any{{"uid": "u1"}})
+	if err != nil || len(acks) != 1 || retries != 1 {
+		t.Fatalf("Push = %v, %v (retries %d); want one ack after one retry", acks, err, retries)
+	}
+
+	// Server moves to epoch 2: first pull reports the mismatch and renews the session
+	epoch.Store(2)
+	_, err = c.Pull(ctx, "notes", "", 10)
+	var ce *ConflictError
+	if !errors.Is(err, ErrEpochMismatch) || errors.Is(err, ErrVersionMismatch) || !errors.As(err, &ce) || ce.Epoch != 2 {
+		t.Fatalf("Pull err = %v, want ErrEpochMismatch at epoch 2", err)
+	}
+	if _, e := c.Session(); e != 2 {
+		t.Fatalf("epoch after renewal = %d, want 2", e)
+	}
+	resp, err := c.Pull(ctx, "notes", "", 10)
+	if err != nil || len(resp.Upserts) != 1 {
+		t.Fatalf("Pull after renewal = %+v, %v", resp, err)
+	}
+
+	var se *StatusError
+	if _, err := c.Pull(ctx, "bogus", "", 0); !errors.As(err, &se) || se.Status != http.StatusNotFound {
+		t.Errorf("unknown entity err = %v, want 404 StatusError", err)
+	}
+}
@@ -0,0 +1,29 @@
+package syncx
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/clock"
+)
+
+// clockBox lets atomic.Value hold any Clock implementation
+type clockBox struct{ clock.Clock }
+
+// syncClock is the time source for NowMs (the system clock until SetClock)
+var syncClock atomic.Value
+
+// SetClock replaces the clock behind Now and NowMs (nil restores the system clock)
+// Server-side mutation timestamps, the clock skew check and serverTime in
+// /v1/sync/info all read it, so tests can simulate skew deterministically.
+func SetClock(c clock.Clock) {
+	syncClock.Store(clockBox{clock.Or(c)})
+}
+
+// Now returns the current time (UTC) by the sync clock
+func Now() time.Time {
+	if b, ok := syncClock.Load().(clockBox); ok {
+		return b.Now().UTC()
+	}
+	return time.Now().UTC()
+}
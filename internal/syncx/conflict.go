@@ -0,0 +1,16 @@
+package syncx
+
+// Conflict codes: the "error" field of HTTP 409/412 conflict bodies and the
+// ErrorInfo reason on gRPC FailedPrecondition errors. Clients branch on these
+// rather than on the message text.
+const (
+	// ErrCodeEpochMismatch: the account was wiped or restored since the session
+	// began; the client resets local state and begins a new session
+	ErrCodeEpochMismatch = "epoch_mismatch"
+	// ErrCodeVersionMismatch: the item changed since the client read it
+	// (If-Match failed); the client re-reads it and retries
+	ErrCodeVersionMismatch = "version_mismatch"
+)
+
+// ConflictDomain is the gRPC ErrorInfo domain for conflict errors
+const ConflictDomain = "toolbridge.sync"
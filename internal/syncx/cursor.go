@@ -1,17 +1,23 @@
 package syncx
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 // Cursor represents a position in the sync stream
-// Format: base64("<updated_at_ms>|<uuid>")
+// Format: base64("<updated_at_ms>|<uuid>"); cursors handed to clients are
+// signed and scoped with SignCursor.
 // Ensures lexicographically ordered, deterministic pagination
 type Cursor struct {
 	Ms  int64     // Unix milliseconds timestamp
@@ -44,28 +50,114 @@ func DecodeCursor(s string) (Cursor, bool) {
 	if len(parts) != 2 {
 		return Cursor{}, false
 	}
+	return parsePosition(parts[0], parts[1])
+}
 
-	ms, err := strconv.ParseInt(parts[0], 10, 64)
-	if err != nil {
+// parsePosition parses a cursor's "<updated_at_ms>" and "<uuid>" fields
+func parsePosition(msField, uidField string) (Cursor, bool) {
+	ms, err := strconv.ParseInt(msField, 10, 64)
+	if err != nil || ms < 0 {
 		return Cursor{}, false
 	}
 
-	id, err := uuid.Parse(parts[1])
-	if err != nil {
+	id, ok := ParseUUID(uidField)
+	if !ok {
 		return Cursor{}, false
 	}
 
 	return Cursor{Ms: ms, UID: id}, true
 }
 
+// Signed cursor errors; either way the client restarts pagination without a cursor
+var (
+	ErrCursorInvalid = errors.New("invalid cursor")                            // Malformed, tampered or signed with another key
+	ErrCursorScope   = errors.New("cursor was issued for a different listing") // Another entity type or user
+)
+
+// cursorMACSize is the truncated HMAC-SHA256 length in signed cursors
+const cursorMACSize = 16
+
+// cursorKey signs pull cursors; random per process until SetCursorKey
+var cursorKey atomic.Pointer[[]byte]
+
+func init() {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("syncx: generate cursor key: " + err.Error())
+	}
+	cursorKey.Store(&key)
+}
+
+// SetCursorKey sets the key pull cursors are signed with
+// Replicas must share it; changing it invalidates cursors already issued
+// (clients restart pagination).
+func SetCursorKey(key []byte) {
+	if len(key) == 0 {
+		return
+	}
+	key = append([]byte(nil), key...)
+	cursorKey.Store(&key)
+}
+
+func cursorMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, *cursorKey.Load())
+	mac.Write(payload)
+	return mac.Sum(nil)[:cursorMACSize]
+}
+
+// SignCursor encodes c for one user's pages of one entity type
+// Format: base64("<ms>|<uuid>|<entity>|<owner>") "." base64(HMAC), so a
+// client can't forge a position or replay a cursor against another listing.
+func SignCursor(c Cursor, owner, entity string) string {
+	payload := []byte(fmt.Sprintf("%d|%s|%s|%s", c.Ms, c.UID, entity, owner))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(cursorMAC(payload))
+}
+
+// VerifyCursor decodes a cursor from SignCursor for owner's entity listing
+// An empty string is the start of the stream (zero cursor, no error).
+func VerifyCursor(s, owner, entity string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	encPayload, encMAC, ok := strings.Cut(s, ".")
+	if !ok {
+		return Cursor{}, ErrCursorInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return Cursor{}, ErrCursorInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil || !hmac.Equal(sig, cursorMAC(payload)) {
+		return Cursor{}, ErrCursorInvalid
+	}
+
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 4 {
+		return Cursor{}, ErrCursorInvalid
+	}
+	if parts[2] != entity {
+		return Cursor{}, fmt.Errorf("%w (issued for %s)", ErrCursorScope, parts[2])
+	}
+	if parts[3] != owner {
+		return Cursor{}, ErrCursorScope
+	}
+	c, ok := parsePosition(parts[0], parts[1])
+	if !ok {
+		return Cursor{}, ErrCursorInvalid
+	}
+	return c, nil
+}
+
 // RFC3339 converts Unix milliseconds to RFC3339 timestamp string
 func RFC3339(ms int64) string {
 	return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
 }
 
-// NowMs returns current Unix milliseconds timestamp (UTC)
+// NowMs returns current Unix milliseconds timestamp (UTC) by the sync clock
 func NowMs() int64 {
-	return time.Now().UTC().UnixMilli()
+	return Now().UnixMilli()
 }
 
 // MsToTime converts Unix milliseconds to time.Time
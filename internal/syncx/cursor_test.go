@@ -1,6 +1,8 @@
 package syncx
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -88,6 +90,20 @@ func TestDecodeCursor(t *testing.T) {
 			wantUID:   uuid.Nil,
 			wantValid: false,
 		},
+		{
+			name:      "negative timestamp",
+			encoded:   "LTF8YzFkOWI3ZGMtYTFiMi00YzNkLTllOGYtN2E2YjVjNGQzZTJm", // "-1|c1d9b7dc-..."
+			wantMs:    0,
+			wantUID:   uuid.Nil,
+			wantValid: false,
+		},
+		{
+			name:      "braced uuid",
+			encoded:   "MTIzNDU2fHtjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmZ9", // "123456|{c1d9b7dc-...}"
+			wantMs:    0,
+			wantUID:   uuid.Nil,
+			wantValid: false,
+		},
 		{
 			name:      "invalid uuid",
 			encoded:   "MTIzNDU2fG5vdC1hLXV1aWQ", // "123456|not-a-uuid"
@@ -135,6 +151,46 @@ func TestCursorRoundTrip(t *testing.T) {
 	}
 }
 
+func TestSignedCursor(t *testing.T) {
+	SetCursorKey([]byte("test-cursor-key"))
+	c := Cursor{Ms: 1730635200000, UID: uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f")}
+	signed := SignCursor(c, "user-1", "notes")
+
+	if got, err := VerifyCursor(signed, "user-1", "notes"); err != nil || got != c {
+		t.Fatalf("VerifyCursor() = %+v, %v; want %+v", got, err, c)
+	}
+	if got, err := VerifyCursor("", "user-1", "notes"); err != nil || got != (Cursor{}) {
+		t.Errorf("empty cursor = %+v, %v; want the start of the stream", got, err)
+	}
+
+	payload, mac, _ := strings.Cut(signed, ".")
+	tampered := SignCursor(Cursor{Ms: c.Ms + 1, UID: c.UID}, "user-1", "notes")
+	tamperedPayload, _, _ := strings.Cut(tampered, ".")
+	for name, bad := range map[string]string{
+		"legacy unsigned": EncodeCursor(c),
+		"tampered":        tamperedPayload + "." + mac,
+		"truncated mac":   payload + "." + mac[:len(mac)-2],
+		"garbage":         "not a cursor",
+	} {
+		if _, err := VerifyCursor(bad, "user-1", "notes"); !errors.Is(err, ErrCursorInvalid) {
+			t.Errorf("%s: err = %v, want ErrCursorInvalid", name, err)
+		}
+	}
+
+	if _, err := VerifyCursor(signed, "user-1", "tasks"); !errors.Is(err, ErrCursorScope) {
+		t.Errorf("other entity: err = %v, want ErrCursorScope", err)
+	}
+	if _, err := VerifyCursor(signed, "user-2", "notes"); !errors.Is(err, ErrCursorScope) {
+		t.Errorf("other user: err = %v, want ErrCursorScope", err)
+	}
+
+	// Rotating the key invalidates cursors already issued
+	SetCursorKey([]byte("rotated-cursor-key"))
+	if _, err := VerifyCursor(signed, "user-1", "notes"); !errors.Is(err, ErrCursorInvalid) {
+		t.Errorf("after key rotation: err = %v, want ErrCursorInvalid", err)
+	}
+}
+
 func TestRFC3339(t *testing.T) {
 	tests := []struct {
 		name string
@@ -179,3 +235,60 @@ func TestNowMs(t *testing.T) {
 		t.Errorf("NowMs() took more than 1 second between calls: %d ms", after-before)
 	}
 }
+
+func FuzzDecodeCursor(f *testing.F) {
+	f.Add("MTczMDYzNTIwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY")
+	f.Add("MTIzNDU2fG5vdC1hLXV1aWQ")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, s string) {
+		c, ok := DecodeCursor(s)
+		if !ok {
+			return
+		}
+		// Accepted cursors are canonical: re-encoding yields the same position
+		if c.Ms < 0 || c.UID == uuid.Nil {
+			t.Fatalf("accepted cursor %+v", c)
+		}
+		again, ok := DecodeCursor(EncodeCursor(c))
+		if !ok || again != c {
+			t.Fatalf("cursor %+v does not round-trip", c)
+		}
+	})
+}
+
+func FuzzVerifyCursor(f *testing.F) {
+	SetCursorKey([]byte("fuzz-cursor-key"))
+	uid := uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f")
+	// Cursors as the server hands them out, plus near misses
+	f.Add(SignCursor(Cursor{Ms: 1730635200000, UID: uid}, "user-1", "notes"))
+	f.Add(SignCursor(Cursor{Ms: 1, UID: uid}, "user-1", "notes"))
+	f.Add(SignCursor(Cursor{Ms: 1730635200000, UID: uid}, "user-2", "chat_messages"))
+	f.Add(EncodeCursor(Cursor{Ms: 1730635200000, UID: uid}))
+	f.Add("MTczMDYzNTIwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmZ8bm90ZXN8dXNlci0x.AAAA")
+	f.Add(".")
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := VerifyCursor(s, "user-1", "notes")
+		if err != nil {
+			if !errors.Is(err, ErrCursorInvalid) && !errors.Is(err, ErrCursorScope) {
+				t.Fatalf("unclassified error %v", err)
+			}
+			return
+		}
+		if s == "" {
+			return
+		}
+		if c.Ms < 0 || c.UID == uuid.Nil {
+			t.Fatalf("accepted cursor %+v", c)
+		}
+		// The position is exactly what was signed, and only for this listing
+		if again, err := VerifyCursor(SignCursor(c, "user-1", "notes"), "user-1", "notes"); err != nil || again != c {
+			t.Fatalf("cursor %+v does not round-trip: %v", c, err)
+		}
+		if _, err := VerifyCursor(s, "user-2", "notes"); !errors.Is(err, ErrCursorScope) {
+			t.Fatalf("accepted for another user: %v", err)
+		}
+		if _, err := VerifyCursor(s, "user-1", "tasks"); !errors.Is(err, ErrCursorScope) {
+			t.Fatalf("accepted for another entity: %v", err)
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package syncx
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 
@@ -18,6 +20,10 @@ type Extracted struct {
 	ParentType  string     // for comments
 	ParentUID   *uuid.UUID // for comments
 	ChatUID     *uuid.UUID // for chat_message
+	TargetType  string     // for pins
+	TargetUID   *uuid.UUID // for pins
+
+	NonCanonical bool // A UID field was sent upper-case; store CanonicalizeUIDs(payload)
 }
 
 // GetString safely extracts a string value from a map
@@ -51,13 +57,63 @@ func GetMap(m map[string]any, k string) (map[string]any, bool) {
 	return nil, false
 }
 
-// ParseUUID parses a UUID string
+// ParseUUID parses a UID in the 8-4-4-4-12 hex form (either case)
+// The braced, urn:uuid: and undashed forms uuid.Parse also accepts are
+// rejected, as is the nil UUID (it sorts as the zero cursor), so every
+// accepted string maps to one canonical spelling.
 func ParseUUID(s string) (uuid.UUID, bool) {
-	if s == "" {
+	if len(s) != 36 {
 		return uuid.Nil, false
 	}
 	id, err := uuid.Parse(s)
-	return id, err == nil
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseUID reads a required UID field of an item
+// canonical is false when the client sent it upper-case (see CanonicalizeUIDs).
+func parseUID(field string, v any) (id uuid.UUID, canonical bool, err error) {
+	s, isString := v.(string)
+	switch {
+	case v == nil || s == "" && isString:
+		return uuid.Nil, false, fmt.Errorf("missing %s", field)
+	case !isString:
+		return uuid.Nil, false, fmt.Errorf("invalid %s: must be a string", field)
+	}
+	id, ok := ParseUUID(s)
+	if !ok {
+		if len(s) > 40 {
+			s = s[:40] + "..."
+		}
+		return uuid.Nil, false, fmt.Errorf("invalid %s %q (want a UUID like 123e4567-e89b-12d3-a456-426614174000)", field, s)
+	}
+	return id, s == id.String(), nil
+}
+
+// uidFields are the item fields holding UIDs
+var uidFields = []string{"uid", "parentUid", "chatUid", "targetUid"}
+
+// CanonicalizeUIDs rewrites the item's UID fields in canonical lower-case
+// form, so the stored payload agrees with the uid column and clients comparing
+// UIDs as strings don't see two spellings of one item. Only needed when
+// Extracted.NonCanonical is set.
+func CanonicalizeUIDs(payload []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range uidFields {
+		var s string
+		if json.Unmarshal(fields[name], &s) != nil {
+			continue
+		}
+		if id, ok := ParseUUID(s); ok {
+			fields[name], _ = json.Marshal(id.String())
+		}
+	}
+	return json.Marshal(fields)
 }
 
 // ParseTimeToMs converts various time formats to Unix milliseconds
@@ -83,37 +139,97 @@ func ParseTimeToMs(s string) (int64, bool) {
 	return 0, false
 }
 
+// itemHeader holds the item fields sync metadata is read from
+type itemHeader struct {
+	UID        any
+	UpdatedTs  any
+	UpdatedAt  any
+	UpdateTime any
+	Sync       any
+	ParentType any // Comments
+	ParentUID  any // Comments
+	ChatUID    any // Chat messages
+	TargetType any // Pins
+	TargetUID  any // Pins
+}
+
+// headerFromMap picks the metadata fields out of a decoded item
+func headerFromMap(item map[string]any) itemHeader {
+	return itemHeader{
+		UID:        item["uid"],
+		UpdatedTs:  item["updatedTs"],
+		UpdatedAt:  item["updatedAt"],
+		UpdateTime: item["updateTime"],
+		Sync:       item["sync"],
+		ParentType: item["parentType"],
+		ParentUID:  item["parentUid"],
+		ChatUID:    item["chatUid"],
+		TargetType: item["targetType"],
+		TargetUID:  item["targetUid"],
+	}
+}
+
+// headerFields are the keys headerFromMap reads
+var headerFields = []string{"uid", "updatedTs", "updatedAt", "updateTime", "sync", "parentType", "parentUid", "chatUid", "targetType", "targetUid"}
+
+// headerFromJSON decodes the metadata fields of a raw JSON item
+// Only those fields are decoded beyond raw bytes, and keys match exactly as in
+// headerFromMap: encoding/json's case-insensitive struct matching would let
+// a "UID" key set the uid column while the stored payload has none.
+func headerFromJSON(payload []byte) (itemHeader, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return itemHeader{}, errors.New("item is not a JSON object")
+	}
+	item := make(map[string]any, len(headerFields))
+	for _, name := range headerFields {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return itemHeader{}, err
+		}
+		item[name] = v
+	}
+	return headerFromMap(item), nil
+}
+
 // ExtractCommon parses common sync metadata from client JSON
 // Tolerant of various field naming conventions (updatedTs, updatedAt, updateTime)
 func ExtractCommon(item map[string]any) (Extracted, error) {
+	return headerFromMap(item).common()
+}
+
+// ExtractCommonJSON is ExtractCommon for an item still in JSON form
+func ExtractCommonJSON(payload []byte) (Extracted, error) {
+	h, err := headerFromJSON(payload)
+	if err != nil {
+		return Extracted{}, err
+	}
+	return h.common()
+}
+
+func (h itemHeader) common() (Extracted, error) {
 	var out Extracted
 
 	// 1. Extract UID (required)
-	uidStr, _ := GetString(item, "uid")
-	id, ok := ParseUUID(uidStr)
-	if !ok {
-		return out, errors.New("missing or invalid uid")
+	id, canonical, err := parseUID("uid", h.UID)
+	if err != nil {
+		return out, err
 	}
 	out.UID = id
+	out.NonCanonical = !canonical
 
-	// 2. Extract updated timestamp (try multiple field names)
+	// 2. Extract updated timestamp (try multiple field names; times at or before
+	// the Unix epoch are skipped, as cursor positions are never negative)
 	var updMs int64
-	if s, ok := GetString(item, "updatedTs"); ok {
-		if ms, ok2 := ParseTimeToMs(s); ok2 {
-			updMs = ms
-		}
-	}
-	if updMs == 0 {
-		if s, ok := GetString(item, "updatedAt"); ok {
-			if ms, ok2 := ParseTimeToMs(s); ok2 {
-				updMs = ms
-			}
-		}
-	}
-	if updMs == 0 {
-		if s, ok := GetString(item, "updateTime"); ok {
-			if ms, ok2 := ParseTimeToMs(s); ok2 {
+	for _, v := range []any{h.UpdatedTs, h.UpdatedAt, h.UpdateTime} {
+		if s, ok := v.(string); ok {
+			if ms, ok2 := ParseTimeToMs(s); ok2 && ms > 0 {
 				updMs = ms
+				break
 			}
 		}
 	}
@@ -124,16 +240,16 @@ func ExtractCommon(item map[string]any) (Extracted, error) {
 	out.UpdatedAtMs = updMs
 
 	// 3. Extract sync metadata (version, isDeleted, deletedAt)
-	if sync, ok := GetMap(item, "sync"); ok {
-		// Version
-		if v, ok := sync["version"].(float64); ok {
+	if sync, ok := h.Sync.(map[string]any); ok {
+		// Version (ignored unless it fits the version column)
+		if v, ok := sync["version"].(float64); ok && v >= 1 && v <= math.MaxInt32 {
 			out.Version = int(v)
 		}
 
 		// Deletion flag + timestamp
 		if del, ok := sync["isDeleted"].(bool); ok && del {
 			if ds, ok := GetString(sync, "deletedAt"); ok {
-				if ms, ok2 := ParseTimeToMs(ds); ok2 {
+				if ms, ok2 := ParseTimeToMs(ds); ok2 && ms > 0 {
 					out.DeletedAtMs = &ms
 				}
 			}
@@ -150,54 +266,120 @@ func ExtractCommon(item map[string]any) (Extracted, error) {
 		out.Version = 1
 	}
 
+	// 4. Reject timestamps from a clock running ahead (they would win LWW indefinitely)
+	if err := checkClockSkew("updatedAt", out.UpdatedAtMs); err != nil {
+		return out, err
+	}
+	if out.DeletedAtMs != nil {
+		if err := checkClockSkew("deletedAt", *out.DeletedAtMs); err != nil {
+			return out, err
+		}
+	}
+
 	return out, nil
 }
 
 // ExtractComment adds comment-specific fields (parentType, parentUid)
 func ExtractComment(item map[string]any) (Extracted, error) {
-	ext, err := ExtractCommon(item)
+	return headerFromMap(item).comment()
+}
+
+// ExtractCommentJSON is ExtractComment for an item still in JSON form
+func ExtractCommentJSON(payload []byte) (Extracted, error) {
+	h, err := headerFromJSON(payload)
+	if err != nil {
+		return Extracted{}, err
+	}
+	return h.comment()
+}
+
+func (h itemHeader) comment() (Extracted, error) {
+	ext, err := h.common()
 	if err != nil {
 		return ext, err
 	}
 
 	// Extract parent type
-	if pt, ok := GetString(item, "parentType"); ok {
+	if pt, ok := h.ParentType.(string); ok {
 		ext.ParentType = pt
 	} else {
 		return ext, errors.New("missing parentType")
 	}
 
 	// Extract parent UID
-	if pu, ok := GetString(item, "parentUid"); ok {
-		if puid, ok2 := ParseUUID(pu); ok2 {
-			ext.ParentUID = &puid
-		} else {
-			return ext, errors.New("invalid parentUid")
-		}
-	} else {
-		return ext, errors.New("missing parentUid")
+	puid, canonical, err := parseUID("parentUid", h.ParentUID)
+	if err != nil {
+		return ext, err
 	}
+	ext.ParentUID = &puid
+	ext.NonCanonical = ext.NonCanonical || !canonical
 
 	return ext, nil
 }
 
 // ExtractChatMessage adds chat message specific fields (chatUid)
 func ExtractChatMessage(item map[string]any) (Extracted, error) {
-	ext, err := ExtractCommon(item)
+	return headerFromMap(item).chatMessage()
+}
+
+// ExtractChatMessageJSON is ExtractChatMessage for an item still in JSON form
+func ExtractChatMessageJSON(payload []byte) (Extracted, error) {
+	h, err := headerFromJSON(payload)
+	if err != nil {
+		return Extracted{}, err
+	}
+	return h.chatMessage()
+}
+
+func (h itemHeader) chatMessage() (Extracted, error) {
+	ext, err := h.common()
 	if err != nil {
 		return ext, err
 	}
 
 	// Extract chat UID
-	if cu, ok := GetString(item, "chatUid"); ok {
-		if cuid, ok2 := ParseUUID(cu); ok2 {
-			ext.ChatUID = &cuid
-		} else {
-			return ext, fmt.Errorf("invalid chatUid: %s", cu)
-		}
+	cuid, canonical, err := parseUID("chatUid", h.ChatUID)
+	if err != nil {
+		return ext, err
+	}
+	ext.ChatUID = &cuid
+	ext.NonCanonical = ext.NonCanonical || !canonical
+
+	return ext, nil
+}
+
+// ExtractPin adds pin-specific fields (targetType, targetUid)
+func ExtractPin(item map[string]any) (Extracted, error) {
+	return headerFromMap(item).pin()
+}
+
+// ExtractPinJSON is ExtractPin for an item still in JSON form
+func ExtractPinJSON(payload []byte) (Extracted, error) {
+	h, err := headerFromJSON(payload)
+	if err != nil {
+		return Extracted{}, err
+	}
+	return h.pin()
+}
+
+func (h itemHeader) pin() (Extracted, error) {
+	ext, err := h.common()
+	if err != nil {
+		return ext, err
+	}
+
+	if tt, ok := h.TargetType.(string); ok {
+		ext.TargetType = tt
 	} else {
-		return ext, errors.New("missing chatUid")
+		return ext, errors.New("missing targetType")
+	}
+
+	tuid, canonical, err := parseUID("targetUid", h.TargetUID)
+	if err != nil {
+		return ext, err
 	}
+	ext.TargetUID = &tuid
+	ext.NonCanonical = ext.NonCanonical || !canonical
 
 	return ext, nil
 }
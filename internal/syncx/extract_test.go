@@ -1,11 +1,28 @@
 package syncx
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/clock"
+	"github.com/erauner12/toolbridge-api/internal/fixtures"
 	"github.com/google/uuid"
 )
 
+func mustJSON(t *testing.T, item map[string]any) []byte {
+	t.Helper()
+	b, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
 func TestExtractCommon(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -142,6 +159,16 @@ func TestExtractCommon(t *testing.T) {
 			if !tt.wantErr && tt.check != nil {
 				tt.check(t, got)
 			}
+
+			// The raw JSON form must extract the same metadata
+			got, err = ExtractCommonJSON(mustJSON(t, tt.item))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractCommonJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				tt.check(t, got)
+			}
 		})
 	}
 }
@@ -216,6 +243,16 @@ func TestExtractComment(t *testing.T) {
 			if !tt.wantErr && tt.check != nil {
 				tt.check(t, got)
 			}
+
+			// The raw JSON form must extract the same metadata
+			got, err = ExtractCommentJSON(mustJSON(t, tt.item))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractCommentJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				tt.check(t, got)
+			}
 		})
 	}
 }
@@ -275,10 +312,106 @@ func TestExtractChatMessage(t *testing.T) {
 			if !tt.wantErr && tt.check != nil {
 				tt.check(t, got)
 			}
+
+			// The raw JSON form must extract the same metadata
+			got, err = ExtractChatMessageJSON(mustJSON(t, tt.item))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractChatMessageJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				tt.check(t, got)
+			}
 		})
 	}
 }
 
+func TestExtractPin(t *testing.T) {
+	valid := map[string]any{
+		"uid":        "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f",
+		"targetType": "note",
+		"targetUid":  "A1B2C3D4-E5F6-7890-ABCD-EF1234567890",
+		"sortOrder":  float64(2),
+		"updatedTs":  "2025-11-03T10:00:00Z",
+	}
+	for _, extract := range []func() (Extracted, error){
+		func() (Extracted, error) { return ExtractPin(valid) },
+		func() (Extracted, error) { return ExtractPinJSON(mustJSON(t, valid)) },
+	} {
+		ext, err := extract()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ext.TargetType != "note" || ext.TargetUID == nil || *ext.TargetUID != uuid.MustParse("a1b2c3d4-e5f6-7890-abcd-ef1234567890") {
+			t.Errorf("target = %q %v", ext.TargetType, ext.TargetUID)
+		}
+		if !ext.NonCanonical {
+			t.Error("upper-case targetUid not flagged NonCanonical")
+		}
+	}
+
+	for _, field := range []string{"targetType", "targetUid"} {
+		item := map[string]any{}
+		for k, v := range valid {
+			if k != field {
+				item[k] = v
+			}
+		}
+		if _, err := ExtractPin(item); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("without %s: err = %v", field, err)
+		}
+	}
+}
+
+func TestExtractCommonJSONInvalid(t *testing.T) {
+	for _, payload := range []string{`42`, `"uid"`, `[{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"}]`, `null`, `{"uid":`} {
+		if _, err := ExtractCommonJSON([]byte(payload)); err == nil {
+			t.Errorf("%s accepted", payload)
+		}
+	}
+}
+
+func TestExtractClockSkew(t *testing.T) {
+	defer SetMaxClockSkew(MaxClockSkew())
+	SetMaxClockSkew(time.Minute)
+
+	stamp := func(ms int64) string { return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano) }
+	item := func(updated, deleted string) map[string]any {
+		m := map[string]any{"uid": "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "updatedTs": updated}
+		if deleted != "" {
+			m["sync"] = map[string]any{"isDeleted": true, "deletedAt": deleted}
+		}
+		return m
+	}
+	now := NowMs()
+	future := stamp(now + time.Hour.Milliseconds())
+
+	if _, err := ExtractCommon(item(stamp(now+30_000), "")); err != nil {
+		t.Errorf("timestamp within skew rejected: %v", err)
+	}
+	for name, it := range map[string]map[string]any{
+		"updatedAt": item(future, ""),
+		"deletedAt": item(stamp(now), future),
+	} {
+		ext, err := ExtractCommonJSON(mustJSON(t, it))
+		var skew *ClockSkewError
+		if !errors.As(err, &skew) || skew.Field != name || ErrorCode(err) != ErrCodeClockSkew {
+			t.Errorf("%s an hour ahead: err = %v, want ClockSkewError", name, err)
+		}
+		if ext.UID == uuid.Nil {
+			t.Errorf("%s: UID not extracted alongside the skew error", name)
+		}
+	}
+	if ErrorCode(errors.New("missing or invalid uid")) != "" {
+		t.Error("malformed item given a code")
+	}
+
+	SetMaxClockSkew(0)
+	if _, err := ExtractCommon(item(future, "")); err != nil {
+		t.Errorf("skew check disabled but item rejected: %v", err)
+	}
+}
+
 func TestParseTimeToMs(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -330,3 +463,292 @@ func TestParseTimeToMs(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUUIDStrict(t *testing.T) {
+	const canonical = "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"
+	for _, s := range []string{canonical, "C1D9B7DC-A1B2-4C3D-9E8F-7A6B5C4D3E2F"} {
+		if id, ok := ParseUUID(s); !ok || id.String() != canonical {
+			t.Errorf("ParseUUID(%q) = %v, %t", s, id, ok)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"{" + canonical + "}",
+		"urn:uuid:" + canonical,
+		"c1d9b7dca1b24c3d9e8f7a6b5c4d3e2f",
+		"00000000-0000-0000-0000-000000000000",
+		"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2g",
+		" " + canonical[1:],
+	} {
+		if _, ok := ParseUUID(s); ok {
+			t.Errorf("ParseUUID(%q) accepted", s)
+		}
+	}
+}
+
+func TestExtractCanonicalUIDs(t *testing.T) {
+	item := map[string]any{
+		"uid":        "C1D9B7DC-A1B2-4C3D-9E8F-7A6B5C4D3E2F",
+		"parentType": "note",
+		"parentUid":  "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d",
+		"updatedTs":  "2025-11-03T10:00:00Z",
+		"title":      "Keep me",
+	}
+	payload := mustJSON(t, item)
+	ext, err := ExtractCommentJSON(payload)
+	if err != nil || !ext.NonCanonical {
+		t.Fatalf("upper-case uid: NonCanonical = %t, err = %v", ext.NonCanonical, err)
+	}
+
+	fixed, err := CanonicalizeUIDs(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(fixed, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["uid"] != "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f" || got["parentUid"] != item["parentUid"] || got["title"] != "Keep me" {
+		t.Errorf("canonicalized payload = %v", got)
+	}
+	if ext, err := ExtractCommentJSON(fixed); err != nil || ext.NonCanonical {
+		t.Errorf("canonicalized payload: NonCanonical = %t, err = %v", ext.NonCanonical, err)
+	}
+
+	for field, value := range map[string]any{"uid": float64(42), "parentUid": "{" + item["parentUid"].(string) + "}"} {
+		bad := map[string]any{}
+		for k, v := range item {
+			bad[k] = v
+		}
+		bad[field] = value
+		if _, err := ExtractComment(bad); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("%s = %v: err = %v, want an error naming the field", field, value, err)
+		}
+	}
+}
+
+// clientPayloads are items as the apps push them (including the quirks seen
+// in the wild), seeding the extractor fuzz targets
+var clientPayloads = []string{
+	`{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","title":"Test Note","content":"Hello from API","sync":{"version":1,"isDeleted":false},"updatedTs":"2025-11-03T10:00:00Z"}`,
+	`{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","title":"Groceries","tags":["home"],"pinned":true,"sync":{"version":4,"isDeleted":true,"deletedAt":"2025-11-03T10:05:00.123Z"},"updatedTs":"2025-11-03T10:05:00.123Z"}`,
+	`{"uid":"d1e9b7dc-b2c3-4d4e-af9f-8b7c6d5e4f3e","title":"Task 1","status":"todo","dueDate":"2025-11-10","sync":{"version":1},"updatedAt":"1730628000000"}`,
+	`{"uid":"C1D9B7DC-A1B2-4C3D-9E8F-7A6B5C4D3E2F","title":"Upper-case uid","updateTime":"2025-11-03T10:00:00+02:00"}`,
+	`{"uid":"e2f3a4b5-c3d4-4e5f-ba0b-9c8d7e6f5a4b","content":"Child","parentType":"note","parentUid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","sync":{"version":1,"isDeleted":false},"updatedTs":"2025-11-03T10:00:00Z"}`,
+	`{"uid":"e2f3a4b5-c3d4-4e5f-ba0b-9c8d7e6f5a4b","content":"On a task","parentType":"task","parentUid":"D1E9B7DC-B2C3-4D4E-AF9F-8B7C6D5E4F3E","updatedTs":"2025-11-03T10:00:00Z"}`,
+	`{"uid":"f3a4b5c6-d4e5-4f60-8b1c-0d9e8f7a6b5c","chatUid":"a1b2c3d4-e5f6-4890-abcd-ef1234567890","role":"user","content":"Hi","sync":{"version":2},"updatedTs":"2025-11-03T10:00:00Z"}`,
+	`{"uid":"f3a4b5c6-d4e5-4f60-8b1c-0d9e8f7a6b5c","chatUid":"a1b2c3d4-e5f6-4890-abcd-ef1234567890","role":"assistant","content":"","sync":{"version":1.0,"isDeleted":true}}`,
+	`{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","updatedTs":"1969-12-31T23:59:59Z","sync":{"version":-1,"deletedAt":"0"}}`,
+	`{"uid":"{c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f}"}`,
+	`{"uid":"a4b5c6d7-e5f6-4a71-9c2d-1e0f9a8b7c6d","targetType":"task","targetUid":"D1E9B7DC-B2C3-4D4E-AF9F-8B7C6D5E4F3E","sortOrder":1.5,"updatedTs":"2025-11-03T10:00:00Z"}`,
+	`{"uid":123,"parentUid":null,"chatUid":""}`,
+	`{"uid":"a1b2c3d4-e5f6-4890-abcd-ef1234567890","UID":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"}`,
+	`[{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"}]`,
+	`null`,
+}
+
+// fuzzExtract checks that an extractor's map and JSON forms agree on payload
+// and that anything they accept is well-formed
+// uidFields are the item's UID fields the extractor parses.
+func fuzzExtract(t *testing.T, payload []byte, fromMap func(map[string]any) (Extracted, error), fromJSON func([]byte) (Extracted, error), uidFields ...string) {
+	ext, err := fromJSON(payload)
+	var item map[string]any
+	if json.Unmarshal(payload, &item) != nil {
+		if err == nil {
+			t.Fatalf("accepted %q, which is not a JSON object", payload)
+		}
+		return
+	}
+	mapExt, mapErr := fromMap(item)
+	if (err == nil) != (mapErr == nil) || err != nil && err.Error() != mapErr.Error() {
+		t.Fatalf("JSON form err = %v, map form err = %v", err, mapErr)
+	}
+	if err != nil {
+		return
+	}
+	if !reflect.DeepEqual(ext, mapExt) {
+		t.Fatalf("JSON form = %+v, map form = %+v", ext, mapExt)
+	}
+
+	if ext.UID == uuid.Nil {
+		t.Fatal("accepted item with nil uid")
+	}
+	if ext.Version < 1 || ext.Version > math.MaxInt32 {
+		t.Fatalf("accepted version %d", ext.Version)
+	}
+	if ext.DeletedAtMs != nil && *ext.DeletedAtMs <= 0 {
+		t.Fatalf("accepted deletedAt %d", *ext.DeletedAtMs)
+	}
+	// The item's position must be one a pull cursor can resume from
+	pos := Cursor{Ms: ext.UpdatedAtMs, UID: ext.UID}
+	if c, err := VerifyCursor(SignCursor(pos, "user-1", "notes"), "user-1", "notes"); err != nil || c != pos {
+		t.Fatalf("position %+v does not round-trip through a cursor: %v", pos, err)
+	}
+
+	// Canonical means every UID is stored as the column will hold it
+	canonical := true
+	for _, field := range uidFields {
+		s, _ := item[field].(string)
+		id, ok := ParseUUID(s)
+		if !ok {
+			t.Fatalf("accepted %s %q", field, s)
+		}
+		canonical = canonical && s == id.String()
+	}
+	if ext.NonCanonical == canonical {
+		t.Fatalf("NonCanonical = %t for %v", ext.NonCanonical, item)
+	}
+	if ext.NonCanonical {
+		fixed, err := CanonicalizeUIDs(payload)
+		if err != nil {
+			t.Fatalf("CanonicalizeUIDs: %v", err)
+		}
+		again, err := fromJSON(fixed)
+		if err != nil || again.NonCanonical {
+			t.Fatalf("canonicalized payload: NonCanonical = %t, err = %v", again.NonCanonical, err)
+		}
+		again.NonCanonical = true
+		if !reflect.DeepEqual(again, ext) {
+			t.Fatalf("canonicalizing changed the item: %+v, was %+v", again, ext)
+		}
+	}
+}
+
+// fuzzSetup seeds f with client payloads and stops the clock, so items
+// without a timestamp extract the same way through both forms
+func fuzzSetup(f *testing.F) {
+	for _, p := range clientPayloads {
+		f.Add([]byte(p))
+	}
+	// Generated items of every entity, tombstones and Unicode edge cases included
+	base := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	for _, it := range fixtures.New(1, base).Generate(fixtures.Counts{
+		Categories: 1, TaskLists: 2, Notes: 2, Tasks: 2, Comments: 1, Chats: 2, Messages: 2,
+		PayloadBytes: 64, Tombstones: 0.3, Unicode: true,
+	}) {
+		b, err := json.Marshal(it.Payload)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	SetClock(clock.NewFake(base))
+	f.Cleanup(func() { SetClock(nil) })
+}
+
+func FuzzExtractCommonJSON(f *testing.F) {
+	f.Add([]byte(`{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","updatedTs":"2025-11-03T10:00:00Z"}`))
+	f.Add([]byte(`{"uid":"C1D9B7DC-A1B2-4C3D-9E8F-7A6B5C4D3E2F","sync":{"isDeleted":true}}`))
+	f.Add([]byte(`{"uid":"{c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f}"}`))
+	f.Add([]byte(`{"uid":123}`))
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		ext, err := ExtractCommonJSON(payload)
+		if err != nil {
+			return
+		}
+		// Anything accepted has a UID that round-trips through a cursor
+		if ext.UID == uuid.Nil {
+			t.Fatal("accepted item with nil uid")
+		}
+		c, ok := DecodeCursor(EncodeCursor(Cursor{Ms: 1, UID: ext.UID}))
+		if !ok || c.UID != ext.UID {
+			t.Fatalf("uid %s does not round-trip through a cursor", ext.UID)
+		}
+		if ext.NonCanonical {
+			fixed, err := CanonicalizeUIDs(payload)
+			if err != nil {
+				t.Fatalf("CanonicalizeUIDs: %v", err)
+			}
+			again, err := ExtractCommonJSON(fixed)
+			if err != nil || again.UID != ext.UID {
+				t.Fatalf("canonicalized payload changed the uid: %v, %v", again.UID, err)
+			}
+		}
+	})
+}
+
+func FuzzExtractCommon(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		fuzzExtract(t, payload, ExtractCommon, ExtractCommonJSON, "uid")
+	})
+}
+
+func FuzzExtractComment(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		fuzzExtract(t, payload, ExtractComment, ExtractCommentJSON, "uid", "parentUid")
+	})
+}
+
+func FuzzExtractChatMessage(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		fuzzExtract(t, payload, ExtractChatMessage, ExtractChatMessageJSON, "uid", "chatUid")
+	})
+}
+
+func FuzzExtractPin(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		fuzzExtract(t, payload, ExtractPin, ExtractPinJSON, "uid", "targetUid")
+	})
+}
+
+func TestStampUpdatedAt(t *testing.T) {
+	ms := int64(1730635200123)
+	out, err := StampUpdatedAt([]byte(`{"uid":"c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f","updatedTs":"2020-01-01T00:00:00Z","updateTime":"x","title":"t"}`), ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := ExtractCommonJSON(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.UpdatedAtMs != ms {
+		t.Errorf("UpdatedAtMs = %d, want %d", ext.UpdatedAtMs, ms)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["updateTime"] != RFC3339(ms) || got["title"] != "t" {
+		t.Errorf("payload = %s", out)
+	}
+	if _, ok := got["updatedAt"]; ok {
+		t.Error("updatedAt added when the client didn't send it")
+	}
+
+	if _, err := StampUpdatedAt([]byte(`[]`), ms); err == nil {
+		t.Error("non-object payload stamped")
+	}
+}
+
+func TestSyncClock(t *testing.T) {
+	defer SetClock(nil)
+	defer SetMaxClockSkew(MaxClockSkew())
+	SetMaxClockSkew(time.Minute)
+
+	// A server clock a day behind the device: its timestamps look skewed
+	now := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	SetClock(fake)
+	if NowMs() != now.UnixMilli() {
+		t.Fatalf("NowMs = %d, want the fake clock's %d", NowMs(), now.UnixMilli())
+	}
+	item := map[string]any{"uid": "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "updatedTs": RFC3339(now.Add(24 * time.Hour).UnixMilli())}
+	if _, err := ExtractCommon(item); ErrorCode(err) != ErrCodeClockSkew {
+		t.Errorf("day-ahead item: err = %v, want clock_skew", err)
+	}
+
+	// Once the server catches up it is accepted
+	fake.Advance(24 * time.Hour)
+	if _, err := ExtractCommon(item); err != nil {
+		t.Errorf("item at server time rejected: %v", err)
+	}
+
+	SetClock(nil)
+	if d := time.Since(Now()); d < 0 || d > time.Minute {
+		t.Errorf("Now after SetClock(nil) = %s, want the system clock", Now())
+	}
+}
@@ -0,0 +1,70 @@
+package syncx
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// LinkEntities are the entities a payload's links may point at
+var LinkEntities = []string{"note", "task", "task_list", "task_list_category", "comment", "chat", "chat_message"}
+
+// MaxLinks caps the links indexed per item; the rest are reported and skipped
+const MaxLinks = 100
+
+// Link is a reference from an item to another entity
+type Link struct {
+	Entity string
+	UID    uuid.UUID
+}
+
+// ExtractLinks reads an item's optional "links" field, an array of
+// {"entity": "note", "uid": "..."} references (e.g. a task pointing at the note
+// it came from). Malformed entries don't fail the push: they are skipped and
+// described in the returned warnings. Duplicates are dropped.
+func ExtractLinks(payload []byte) ([]Link, []string) {
+	var fields struct {
+		Links json.RawMessage `json:"links"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil || len(fields.Links) == 0 || string(fields.Links) == "null" {
+		return nil, nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(fields.Links, &entries); err != nil {
+		return nil, []string{"links: must be an array of {entity, uid} objects"}
+	}
+
+	var links []Link
+	var warnings []string
+	for i, raw := range entries {
+		var entry struct {
+			Entity string `json:"entity"`
+			UID    string `json:"uid"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			warnings = append(warnings, fmt.Sprintf("links[%d]: must be an {entity, uid} object", i))
+			continue
+		}
+		if !slices.Contains(LinkEntities, entry.Entity) {
+			warnings = append(warnings, fmt.Sprintf("links[%d]: unknown entity %q", i, entry.Entity))
+			continue
+		}
+		id, ok := ParseUUID(entry.UID)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("links[%d]: invalid uid", i))
+			continue
+		}
+		link := Link{Entity: entry.Entity, UID: id}
+		if slices.Contains(links, link) {
+			continue
+		}
+		if len(links) == MaxLinks {
+			warnings = append(warnings, fmt.Sprintf("links: only the first %d links are indexed", MaxLinks))
+			break
+		}
+		links = append(links, link)
+	}
+	return links, warnings
+}
@@ -0,0 +1,52 @@
+package syncx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestExtractLinks(t *testing.T) {
+	note := uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f")
+	task := uuid.MustParse("a1b2c3d4-e5f6-7890-abcd-ef1234567890")
+
+	tests := []struct {
+		name     string
+		payload  string
+		want     []Link
+		warnings []string
+	}{
+		{name: "no links", payload: `{"uid": "x"}`},
+		{name: "null links", payload: `{"links": null}`},
+		{
+			name:    "valid and duplicate",
+			payload: `{"links": [{"entity": "note", "uid": "C1D9B7DC-A1B2-4C3D-9E8F-7A6B5C4D3E2F"}, {"entity": "task", "uid": "a1b2c3d4-e5f6-7890-abcd-ef1234567890"}, {"entity": "note", "uid": "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"}]}`,
+			want:    []Link{{Entity: "note", UID: note}, {Entity: "task", UID: task}},
+		},
+		{
+			name:     "malformed entries are skipped",
+			payload:  `{"links": ["note", {"entity": "folder", "uid": "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"}, {"entity": "note", "uid": "nope"}, {"entity": "task", "uid": "a1b2c3d4-e5f6-7890-abcd-ef1234567890"}]}`,
+			want:     []Link{{Entity: "task", UID: task}},
+			warnings: []string{"links[0]", "links[1]: unknown entity", "links[2]: invalid uid"},
+		},
+		{name: "not an array", payload: `{"links": {"entity": "note"}}`, warnings: []string{"links: must be an array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, warnings := ExtractLinks([]byte(tt.payload))
+			if !reflect.DeepEqual(links, tt.want) {
+				t.Errorf("links = %v, want %v", links, tt.want)
+			}
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("warnings = %q, want %d", warnings, len(tt.warnings))
+			}
+			for i, prefix := range tt.warnings {
+				if !strings.HasPrefix(warnings[i], prefix) {
+					t.Errorf("warning %d = %q, want prefix %q", i, warnings[i], prefix)
+				}
+			}
+		})
+	}
+}
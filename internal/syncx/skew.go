@@ -0,0 +1,68 @@
+package syncx
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCodeClockSkew is the push ack code for items stamped too far in the future
+const ErrCodeClockSkew = "clock_skew"
+
+// DefaultMaxClockSkew is how far ahead of server time item timestamps may be
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// maxClockSkewMs is the current limit (0 disables the check)
+var maxClockSkewMs atomic.Int64
+
+func init() {
+	maxClockSkewMs.Store(DefaultMaxClockSkew.Milliseconds())
+}
+
+// SetMaxClockSkew sets how far ahead of server time a pushed updatedAt or
+// deletedAt may be; 0 disables the check
+// Without it a device with a wrong clock wins last-write-wins against every
+// other device until real time catches up.
+func SetMaxClockSkew(d time.Duration) {
+	maxClockSkewMs.Store(max(d.Milliseconds(), 0))
+}
+
+// MaxClockSkew returns the limit in effect
+func MaxClockSkew() time.Duration {
+	return time.Duration(maxClockSkewMs.Load()) * time.Millisecond
+}
+
+// ClockSkewError rejects an item whose timestamp is too far ahead of server time
+type ClockSkewError struct {
+	Field   string // updatedAt or deletedAt
+	AheadMs int64  // How far ahead of server time the timestamp is
+	Max     time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	ahead := (time.Duration(e.AheadMs) * time.Millisecond).Round(time.Second)
+	return fmt.Sprintf("%s is %s ahead of server time (max %s); check the device clock", e.Field, ahead, e.Max)
+}
+
+// checkClockSkew returns a ClockSkewError if ms is beyond the allowed skew
+func checkClockSkew(field string, ms int64) error {
+	limit := maxClockSkewMs.Load()
+	if limit == 0 {
+		return nil
+	}
+	if ahead := ms - NowMs(); ahead > limit {
+		return &ClockSkewError{Field: field, AheadMs: ahead, Max: time.Duration(limit) * time.Millisecond}
+	}
+	return nil
+}
+
+// ErrorCode returns the machine-readable code for an extraction error, or ""
+// for malformed items (the message says what is wrong)
+func ErrorCode(err error) string {
+	var skew *ClockSkewError
+	if errors.As(err, &skew) {
+		return ErrCodeClockSkew
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package syncx
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// serverTimestamps is the server timestamp mode switch (off by default)
+var serverTimestamps atomic.Bool
+
+// SetServerTimestamps turns server-assigned push timestamps on or off
+// When on, each pushed item is stamped max(client ms, owner's last ms for the
+// entity + 1) so pull cursors never skip a write, whatever the client clocks
+// say; the ack carries the stamp. The catch is that the last push to arrive
+// wins, even if its edit is older.
+func SetServerTimestamps(on bool) {
+	serverTimestamps.Store(on)
+}
+
+// ServerTimestamps reports whether server timestamp mode is on
+func ServerTimestamps() bool {
+	return serverTimestamps.Load()
+}
+
+// StampUpdatedAt rewrites the item's updated timestamp to ms, so the stored
+// payload agrees with the updated_at_ms column
+// updatedTs is always set; updatedAt and updateTime are rewritten if present.
+func StampUpdatedAt(payload []byte, ms int64) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	ts, _ := json.Marshal(RFC3339(ms))
+	fields["updatedTs"] = ts
+	for _, name := range []string{"updatedAt", "updateTime"} {
+		if _, ok := fields[name]; ok {
+			fields[name] = ts
+		}
+	}
+	return json.Marshal(fields)
+}
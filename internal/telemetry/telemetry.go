@@ -0,0 +1,145 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies spans created by this service
+const instrumentationName = "github.com/erauner12/toolbridge-api"
+
+// Config controls OpenTelemetry tracing
+//
+// The OTLP exporter endpoint, headers and protocol options are read by the
+// exporter itself from the standard OTEL_EXPORTER_OTLP_* environment variables.
+type Config struct {
+	Enabled     bool    // Export spans (when false, traceparent is still propagated)
+	ServiceName string  // service.name resource attribute
+	Environment string  // deployment.environment resource attribute
+	SampleRatio float64 // Fraction of new root traces to sample (0..1); parent decision is honored
+}
+
+var enabled bool
+
+// propagator handles W3C traceparent/tracestate (and baggage)
+// Kept package-level so Extract/Inject work even before Init runs (tests, tools).
+var propagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Init configures the global tracer provider and W3C trace context propagator.
+// Returns a shutdown function that flushes pending spans; it is safe to call
+// even when tracing is disabled.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// Always install the propagator so incoming traceparent headers are honored
+	// and forwarded, even when this service isn't exporting spans itself
+	otel.SetTextMapPropagator(propagator)
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+		attribute.String("deployment.environment", cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	enabled = true
+
+	return provider.Shutdown, nil
+}
+
+// Enabled reports whether spans are being exported
+// Used to skip per-query instrumentation overhead when tracing is off
+func Enabled() bool {
+	return enabled
+}
+
+// Tracer returns the service tracer (a no-op tracer until Init enables tracing)
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Extract continues the caller's trace from traceparent/tracestate in carrier
+// When the caller sent no valid traceparent and spans aren't being exported, a
+// fresh unsampled trace context is generated so every request still has a
+// trace ID to log and echo back. When exporting, the root server span assigns
+// the IDs (and the sampler decides) instead.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	ctx = propagator.Extract(ctx, carrier)
+	if enabled || trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, newSpanContext())
+}
+
+// Inject writes the traceparent/tracestate of the span in ctx into carrier
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// newSpanContext generates random trace and span IDs (unsampled)
+func newSpanContext() trace.SpanContext {
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	_, _ = rand.Read(traceID[:])
+	_, _ = rand.Read(spanID[:])
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	})
+}
+
+// StartSpan starts an internal child span of the span in ctx
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" if there is none
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// StartPushSpan starts the span covering one push batch for an entity type
+// Per-query DB spans for the batch's transaction nest under it.
+func StartPushSpan(ctx context.Context, entity string, items int) (context.Context, trace.Span) {
+	return StartSpan(ctx, "sync.push "+entity,
+		attribute.String("sync.entity", entity),
+		attribute.Int("sync.batch_size", items),
+	)
+}
+
+// SetBatchSize records the size of a push batch decoded while it was applied
+// (the span was started with 0 items)
+func SetBatchSize(span trace.Span, items int) {
+	span.SetAttributes(attribute.Int("sync.batch_size", items))
+}
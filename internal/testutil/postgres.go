@@ -0,0 +1,222 @@
+// Package testutil runs tests against real Postgres and a fully wired API
+//
+// NewDB gives each test its own freshly migrated database. The server is
+// TEST_DATABASE_URL when set (the role needs CREATEDB), otherwise an ephemeral
+// Postgres container started once per test binary; with neither available the
+// test is skipped. NewEnv serves the API on such a database and hands out
+// authenticated sync clients.
+package testutil
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/migrations"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// PostgresImage is the image started when TEST_DATABASE_URL is unset (same as docker-compose.yml)
+const PostgresImage = "postgres:16-alpine"
+
+// templateLockKey serializes template creation across test binaries sharing a server
+const templateLockKey = 0x7462_7465_7374 // "tbtest"
+
+var (
+	serverOnce sync.Once
+	serverURL  string
+	serverErr  error
+
+	templateOnce sync.Once
+	templateName string
+	templateErr  error
+)
+
+// postgresURL returns the URL of a server tests may create databases on
+// The container, if one is started, is removed by the testcontainers reaper
+// when the test binary exits.
+func postgresURL() (string, error) {
+	serverOnce.Do(func() {
+		if u := os.Getenv("TEST_DATABASE_URL"); u != "" {
+			serverURL = u
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		// testcontainers panics rather than erroring when it finds no Docker host
+		defer func() {
+			if r := recover(); r != nil {
+				serverErr = fmt.Errorf("start postgres container: %v", r)
+			}
+		}()
+		c, err := postgres.Run(ctx, PostgresImage,
+			postgres.WithDatabase("toolbridge"),
+			postgres.WithUsername("toolbridge"),
+			postgres.WithPassword("toolbridge"),
+			postgres.BasicWaitStrategies(),
+		)
+		if err != nil {
+			serverErr = fmt.Errorf("start postgres container: %w", err)
+			return
+		}
+		serverURL, serverErr = c.ConnectionString(ctx, "sslmode=disable")
+	})
+	return serverURL, serverErr
+}
+
+// withDatabase returns base pointed at database name
+func withDatabase(base, name string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = "/" + name
+	return u.String(), nil
+}
+
+// migrationsHash identifies the schema, so a template built from the same
+// migrations is reused (by later runs against TEST_DATABASE_URL too)
+func migrationsHash() (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(migrations.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(migrations.FS, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(b))
+		h.Write(b)
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil))[:12], err
+}
+
+// template returns a database with every migration applied, creating it once
+// Test databases are cloned from it (CREATE DATABASE … TEMPLATE), which is much
+// faster than migrating each one.
+func template(ctx context.Context, server string) (string, error) {
+	templateOnce.Do(func() {
+		templateName, templateErr = createTemplate(ctx, server)
+	})
+	return templateName, templateErr
+}
+
+func createTemplate(ctx context.Context, server string) (string, error) {
+	hash, err := migrationsHash()
+	if err != nil {
+		return "", fmt.Errorf("hash migrations: %w", err)
+	}
+	name := "toolbridge_tmpl_" + hash
+
+	conn, err := pgx.Connect(ctx, server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, templateLockKey); err != nil {
+		return "", err
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, templateLockKey)
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)`, name).Scan(&exists); err != nil {
+		return "", err
+	}
+	if exists {
+		return name, nil
+	}
+
+	// Built under a scratch name and renamed when complete, so a run that dies
+	// midway never leaves a half-migrated template behind
+	building := name + "_building"
+	if _, err := conn.Exec(ctx, `DROP DATABASE IF EXISTS `+building); err != nil {
+		return "", err
+	}
+	if _, err := conn.Exec(ctx, `CREATE DATABASE `+building); err != nil {
+		return "", err
+	}
+	buildURL, err := withDatabase(server, building)
+	if err != nil {
+		return "", err
+	}
+	pool, err := pgxpool.New(ctx, buildURL)
+	if err != nil {
+		return "", err
+	}
+	_, err = db.NewMigrator(pool, migrations.FS).Up(ctx)
+	pool.Close()
+	if err != nil {
+		return "", fmt.Errorf("migrate template: %w", err)
+	}
+	if _, err := conn.Exec(ctx, `ALTER DATABASE `+building+` RENAME TO `+name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// NewDB returns a pool on a new, fully migrated database of its own
+// The database is dropped when the test ends. Tests are skipped when there is
+// no Postgres to use (see the package comment) and in -short mode.
+func NewDB(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+	server, err := postgresURL()
+	if err != nil {
+		t.Skipf("no Postgres for integration tests (set TEST_DATABASE_URL or run Docker): %v", err)
+	}
+
+	ctx := context.Background()
+	tmpl, err := template(ctx, server)
+	if err != nil {
+		t.Fatalf("prepare template database: %v", err)
+	}
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	name := "toolbridge_test_" + hex.EncodeToString(suffix)
+	conn, err := pgx.Connect(ctx, server)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, `CREATE DATABASE `+name+` TEMPLATE `+tmpl); err != nil {
+		t.Fatalf("create test database: %v", err)
+	}
+
+	dbURL, err := withDatabase(server, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := db.OpenWithOptions(ctx, dbURL, db.PoolOptions{PrepareHot: true})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		conn, err := pgx.Connect(context.Background(), server)
+		if err != nil {
+			t.Logf("drop test database %s: %v", name, err)
+			return
+		}
+		defer conn.Close(context.Background())
+		if _, err := conn.Exec(context.Background(), `DROP DATABASE IF EXISTS `+name+` WITH (FORCE)`); err != nil {
+			t.Logf("drop test database %s: %v", name, err)
+		}
+	})
+	return pool
+}
@@ -0,0 +1,140 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/httpapi"
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HS256Secret signs the tokens Env hands out
+const HS256Secret = "testutil-secret"
+
+// Env is the API serving a database of its own over a real HTTP listener
+type Env struct {
+	DB     *pgxpool.Pool
+	Config *config.Config
+	Server *httpapi.Server
+	URL    string // Base URL, e.g. http://127.0.0.1:41235
+
+	handler atomic.Pointer[http.Handler] // Server's routes; swapped by Restart
+	jwtCfg  auth.JWTCfg                  // Same for every server built from Config
+}
+
+// NewEnv starts the API on a new database (see NewDB) with the dev profile
+// and default settings
+// configure, if non-nil, adjusts the configuration before the server is built.
+// With Auth.MockIdP set the issuer defaults to this server's mock IdP (see MockIdPToken).
+// Runtime settings (clock skew, server timestamps, cursor key) are process-wide,
+// so tests that change them shouldn't run in parallel with ones that don't.
+func NewEnv(t testing.TB, configure func(*config.Config)) *Env {
+	t.Helper()
+	pool := NewDB(t)
+
+	e := &Env{DB: pool}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := e.handler.Load()
+		if h == nil {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		(*h).ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	e.URL = ts.URL
+
+	cfg := config.Default()
+	cfg.Env = "dev"
+	cfg.Auth.HS256Secret = HS256Secret
+	cfg.Sync.CursorKey = HS256Secret
+	if configure != nil {
+		configure(cfg)
+	}
+	// As config.Load does, but for this server's URL
+	if cfg.Auth.MockIdP && cfg.Auth.Issuer == "" {
+		cfg.Auth.Issuer = config.MockIdPIssuer(e.URL)
+		cfg.Auth.JWKSURL = cfg.Auth.Issuer + "/jwks"
+	}
+
+	e.Config = cfg
+	e.serve(httpapi.NewServer(pool, cfg))
+	e.jwtCfg = e.Server.JWTCfg
+	return e
+}
+
+func (e *Env) serve(srv *httpapi.Server) {
+	h := srv.Routes(srv.JWTCfg)
+	e.Server = srv
+	e.handler.Store(&h)
+}
+
+// Restart replaces the server with a new one on the same database, config and
+// URL, as a deploy does
+// Server is updated, so don't read it concurrently; Token and URL are safe.
+// In-memory state is lost with the old server: rate limit buckets and, unless
+// the process-wide session store is the Postgres one, every sync session.
+// Requests already in flight finish on the old server.
+func (e *Env) Restart() {
+	if _, ok := session.GetStore().(*session.MemoryStore); ok {
+		session.SetStore(session.NewMemoryStore())
+	}
+	e.serve(httpapi.NewServer(e.DB, e.Config))
+}
+
+// Token returns a backend access token for subject, valid for an hour
+func (e *Env) Token(t testing.TB, subject string) string {
+	t.Helper()
+	token, err := auth.SignBackendToken(jwt.MapClaims{
+		"sub":        subject,
+		"iss":        "toolbridge-api",
+		"token_type": "backend",
+		"iat":        time.Now().Unix(),
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}, e.jwtCfg)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// MockIdPToken returns an RS256 token for subject from the server's mock IdP
+// (configure the Env with Auth.MockIdP), validated through JWKS like a real
+// IdP's tokens
+func (e *Env) MockIdPToken(t testing.TB, subject string) string {
+	t.Helper()
+	resp, err := http.PostForm(e.Config.Auth.Issuer+"/token", url.Values{"sub": {subject}})
+	if err != nil {
+		t.Fatalf("mock IdP token: %v", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("mock IdP token: status %d (%v)", resp.StatusCode, err)
+	}
+	return body.AccessToken
+}
+
+// Client returns a sync client authenticated as subject with a session begun
+func (e *Env) Client(t testing.TB, subject string) *syncclient.Client {
+	t.Helper()
+	c := syncclient.New(e.URL)
+	c.Token = e.Token(t, subject)
+	if err := c.BeginSession(context.Background()); err != nil {
+		t.Fatalf("begin session as %s: %v", subject, err)
+	}
+	return c
+}
@@ -0,0 +1,464 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// Soak test: a few users, each with several devices, push and pull notes for
+// SOAK_DURATION while the server restarts, accounts are wiped and devices
+// restart, with skewed device clocks and a rate limit the devices keep hitting.
+// At the end every device must converge on the server's state, and no note's
+// version may exceed the number of pushes it received.
+//
+// It is opt-in: set SOAK_DURATION (e.g. 2h) to run it. SOAK_USERS and
+// SOAK_DEVICES size the fleet (default 2 and 4); SOAK_SEED replays a run's
+// choices (the seed is logged).
+//
+// Server timestamps are on, as for any fleet with unreliable clocks: with
+// client timestamps a device pulling incrementally misses edits stamped
+// behind its cursor, so devices would not converge without a full resync.
+
+const (
+	soakMaxSkew   = time.Minute // Server's limit; the +3m device is always rejected
+	soakMaxItems  = 40          // Per user, so edits keep colliding
+	soakPageSize  = 7           // Small pages so catch-ups span several
+	soakRetries   = 50          // 429 retries before a request fails
+	soakMaxEvents = 200
+)
+
+// soakSkews are the device clock offsets, cycled over each user's devices
+var soakSkews = []time.Duration{0, -90 * time.Second, 40 * time.Second, 3 * time.Minute}
+
+// soakStats counts what happened during the run
+type soakStats struct {
+	pushes, pulls, retries, skewRejected atomic.Int64
+	sessions, resets, tokens             atomic.Int64
+	serverRestarts, deviceRestarts       atomic.Int64
+	wipes                                atomic.Int64
+}
+
+// soakLedger counts the pushes each note received, the upper bound on its version
+type soakLedger struct {
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+func (l *soakLedger) send(items []map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, item := range items {
+		l.sent[item["uid"].(string)]++
+	}
+}
+
+func (l *soakLedger) pushes(uid string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sent[uid]
+}
+
+// soakDevice is one app install: a local replica of the user's notes kept
+// current by incremental pulls
+// Its state belongs to the goroutine running it; other goroutines only set
+// the restart and wipe flags.
+type soakDevice struct {
+	t       *testing.T
+	env     *testutil.Env
+	name    string
+	subject string
+	skew    time.Duration
+	rng     *rand.Rand
+	ledger  *soakLedger
+	stats   *soakStats
+	client  *syncclient.Client
+
+	epoch    int
+	cursor   string
+	items    map[string]map[string]any // uid -> payload as last pulled
+	lastPush []map[string]any
+	edits    int
+
+	restart atomic.Bool // Drop the session (app restarted) before the next step
+	wipe    atomic.Bool // Wipe the account on the next step
+}
+
+func (d *soakDevice) newClient() {
+	d.client = syncclient.New(d.env.URL)
+	d.client.Token = d.env.Token(d.t, d.subject)
+	d.client.MaxRetries = soakRetries
+	d.client.OnRetry = func(time.Duration) { d.stats.retries.Add(1) }
+}
+
+// reset discards local data, as a client must when the epoch moves
+func (d *soakDevice) reset(epoch int) {
+	d.epoch = epoch
+	d.cursor = ""
+	d.items = make(map[string]map[string]any)
+	d.lastPush = nil
+}
+
+// beginSession starts a session, resetting if the epoch moved meanwhile
+func (d *soakDevice) beginSession(ctx context.Context) error {
+	if err := d.client.BeginSession(ctx); err != nil {
+		return err
+	}
+	d.stats.sessions.Add(1)
+	d.checkEpoch()
+	return nil
+}
+
+func (d *soakDevice) checkEpoch() {
+	if _, epoch := d.client.Session(); epoch != d.epoch {
+		if d.epoch != 0 {
+			d.stats.resets.Add(1)
+		}
+		d.reset(epoch)
+	}
+}
+
+// recover handles the errors a client is expected to: an expired token, a
+// lost session (the server restarted, or a wipe ended it) and an epoch
+// mismatch, for which syncclient has already begun a new session
+// The failed operation is dropped rather than retried: its edits were made
+// to data the new epoch no longer has.
+func (d *soakDevice) recover(ctx context.Context, err error) error {
+	var se *syncclient.StatusError
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return nil
+	case errors.Is(err, syncclient.ErrEpochMismatch):
+		d.checkEpoch()
+		return nil
+	case errors.As(err, &se) && se.Status == http.StatusPreconditionRequired:
+		return d.beginSession(ctx)
+	case errors.As(err, &se) && se.Status == http.StatusUnauthorized:
+		d.stats.tokens.Add(1)
+		d.client.Token = d.env.Token(d.t, d.subject)
+		return nil
+	}
+	return err
+}
+
+func (d *soakDevice) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := d.recover(ctx, d.step(ctx)); err != nil {
+			d.t.Errorf("%s: %v", d.name, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(20+d.rng.Intn(200)) * time.Millisecond):
+		}
+	}
+}
+
+func (d *soakDevice) step(ctx context.Context) error {
+	if d.restart.Swap(false) {
+		d.stats.deviceRestarts.Add(1)
+		d.newClient()
+		return d.beginSession(ctx)
+	}
+	if d.wipe.Swap(false) {
+		if _, err := d.client.Wipe(ctx); err != nil {
+			return err
+		}
+		d.stats.wipes.Add(1)
+		return d.beginSession(ctx)
+	}
+
+	switch r := d.rng.Float64(); {
+	case r < 0.4:
+		return d.push(ctx, d.makeEdits())
+	case r < 0.5 && d.lastPush != nil:
+		// The response to the last push was lost; send it again
+		return d.push(ctx, d.lastPush)
+	default:
+		return d.catchUp(ctx)
+	}
+}
+
+// makeEdits creates, edits and deletes a few notes of the local replica
+func (d *soakDevice) makeEdits() []map[string]any {
+	uids := make([]string, 0, len(d.items))
+	for uid := range d.items {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids) // Map order would make runs unrepeatable
+
+	ts := syncx.RFC3339(time.Now().Add(d.skew).UnixMilli())
+	var edits []map[string]any
+	for n := 1 + d.rng.Intn(3); n > 0; n-- {
+		d.edits++
+		title := fmt.Sprintf("%s edit %d", d.name, d.edits)
+		switch r := d.rng.Float64(); {
+		case len(uids) == 0 || r < 0.3 && len(uids) < soakMaxItems:
+			id, _ := uuid.NewRandomFromReader(d.rng)
+			edits = append(edits, map[string]any{"uid": id.String(), "title": title, "updatedTs": ts})
+		case r < 0.9:
+			item := map[string]any{}
+			for k, v := range d.items[uids[d.rng.Intn(len(uids))]] {
+				item[k] = v
+			}
+			item["title"], item["updatedTs"] = title, ts
+			edits = append(edits, item)
+		default:
+			uid := uids[d.rng.Intn(len(uids))]
+			edits = append(edits, map[string]any{"uid": uid, "updatedTs": ts, "sync": map[string]any{"isDeleted": true}})
+		}
+	}
+	return edits
+}
+
+func (d *soakDevice) push(ctx context.Context, items []map[string]any) error {
+	d.ledger.send(items)
+	d.stats.pushes.Add(1)
+	acks, err := d.client.Push(ctx, "notes", items)
+	if err != nil {
+		return err
+	}
+	d.lastPush = items
+	for _, ack := range acks {
+		switch {
+		case ack.Code == syncx.ErrCodeClockSkew && d.skew > soakMaxSkew:
+			d.stats.skewRejected.Add(1)
+		case ack.Error != "":
+			d.t.Errorf("%s: push %s: %s (%s)", d.name, ack.UID, ack.Error, ack.Code)
+		case ack.Version > d.ledger.pushes(ack.UID):
+			d.t.Errorf("%s: %s is at version %d after %d pushes", d.name, ack.UID, ack.Version, d.ledger.pushes(ack.UID))
+		}
+	}
+	return nil
+}
+
+// catchUp pulls from the device's cursor until the server has no more
+func (d *soakDevice) catchUp(ctx context.Context) error {
+	for {
+		d.stats.pulls.Add(1)
+		resp, err := d.client.Pull(ctx, "notes", d.cursor, soakPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range resp.Upserts {
+			d.items[item["uid"].(string)] = item
+		}
+		for _, del := range resp.Deletes {
+			delete(d.items, del["uid"].(string))
+		}
+		if resp.NextCursor != nil {
+			d.cursor = *resp.NextCursor
+		}
+		if !resp.HasMore {
+			return nil
+		}
+	}
+}
+
+// soakEnv reads the SOAK_* settings, skipping the test when it isn't enabled
+func soakEnv(t *testing.T) (duration time.Duration, users, devices int, seed int64) {
+	t.Helper()
+	v := os.Getenv("SOAK_DURATION")
+	if v == "" {
+		t.Skip("soak test is opt-in: set SOAK_DURATION (e.g. 2h)")
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil || duration <= 0 {
+		t.Fatalf("SOAK_DURATION=%q: want a positive duration", v)
+	}
+	atoi := func(name string, def int) int {
+		if v := os.Getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				t.Fatalf("%s=%q: want a positive integer", name, v)
+			}
+			return n
+		}
+		return def
+	}
+	users, devices = atoi("SOAK_USERS", 2), atoi("SOAK_DEVICES", 4)
+	seed = time.Now().UnixNano()
+	if v := os.Getenv("SOAK_SEED"); v != "" {
+		if seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			t.Fatalf("SOAK_SEED=%q: %v", v, err)
+		}
+	}
+	return duration, users, devices, seed
+}
+
+func TestSoak(t *testing.T) {
+	duration, users, devices, seed := soakEnv(t)
+	t.Logf("soak: %s, %d users x %d devices, SOAK_SEED=%d", duration, users, devices, seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	env := testutil.NewEnv(t, func(c *config.Config) {
+		c.Sync.ServerTimestamps = true
+		c.Sync.MaxClockSkew = soakMaxSkew
+		// Four requests a second per user, well under what the devices try
+		c.RateLimit.Sync = config.RateLimitValues{WindowSeconds: 10, MaxRequests: 40}
+	})
+
+	stats := &soakStats{}
+	ledger := &soakLedger{sent: make(map[string]int)}
+	subjects := make([]string, users)
+	fleet := make([][]*soakDevice, users)
+	ctx := context.Background()
+	for u := range subjects {
+		subjects[u] = fmt.Sprintf("soak-user-%d", u+1)
+		for i := 0; i < devices; i++ {
+			d := &soakDevice{
+				t:       t,
+				env:     env,
+				name:    fmt.Sprintf("%s/device-%d", subjects[u], i+1),
+				subject: subjects[u],
+				skew:    soakSkews[i%len(soakSkews)],
+				rng:     rand.New(rand.NewSource(rng.Int63())),
+				ledger:  ledger,
+				stats:   stats,
+			}
+			d.newClient()
+			if err := d.beginSession(ctx); err != nil {
+				t.Fatalf("%s: %v", d.name, err)
+			}
+			fleet[u] = append(fleet[u], d)
+		}
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, ds := range fleet {
+		for _, d := range ds {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.run(runCtx)
+			}()
+		}
+	}
+
+	// Disruptions, spread over the run
+	events := time.NewTicker(max(duration/soakMaxEvents, 2*time.Second))
+	defer events.Stop()
+	for runCtx.Err() == nil {
+		select {
+		case <-runCtx.Done():
+		case <-events.C:
+			d := fleet[rng.Intn(users)][rng.Intn(devices)]
+			switch r := rng.Float64(); {
+			case r < 0.3:
+				env.Restart()
+				stats.serverRestarts.Add(1)
+			case r < 0.5:
+				d.wipe.Store(true)
+			default:
+				d.restart.Store(true)
+			}
+		}
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+
+	// Every device catches up from where it is and must match the server
+	for u, subject := range subjects {
+		truth := syncclient.New(env.URL)
+		truth.Token = env.Token(t, subject)
+		truth.MaxRetries = soakRetries
+		if err := truth.BeginSession(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var want map[string]map[string]any
+		for _, d := range fleet[u] {
+			for attempt := 1; ; attempt++ {
+				err := d.catchUp(ctx)
+				if err == nil {
+					break
+				}
+				if attempt == 5 { // Token, session and epoch may all need renewing
+					t.Fatalf("%s: final catch-up: %v", d.name, err)
+				}
+				if err := d.recover(ctx, err); err != nil {
+					t.Fatalf("%s: final catch-up: %v", d.name, err)
+				}
+			}
+			if want == nil {
+				want = pullAll(t, truth)
+			}
+			if !reflect.DeepEqual(d.items, want) {
+				t.Errorf("%s did not converge: has %d notes, server has %d", d.name, len(d.items), len(want))
+			}
+		}
+
+		rows, err := env.DB.Query(ctx, `
+			SELECT n.uid::text, n.version FROM note n JOIN app_user u ON u.id = n.owner_id
+			WHERE u.sub = $1`, subject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var uid string
+			var version int
+			if err := rows.Scan(&uid, &version); err != nil {
+				t.Fatal(err)
+			}
+			if n := ledger.pushes(uid); version > n {
+				t.Errorf("%s: note %s is at version %d after %d pushes", subject, uid, version, n)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Logf("soak: %d pushes, %d pulls, %d rate-limit retries, %d clock-skew rejections, %d sessions, %d resets, %d token refreshes, %d server restarts, %d device restarts, %d wipes",
+		stats.pushes.Load(), stats.pulls.Load(), stats.retries.Load(), stats.skewRejected.Load(),
+		stats.sessions.Load(), stats.resets.Load(), stats.tokens.Load(),
+		stats.serverRestarts.Load(), stats.deviceRestarts.Load(), stats.wipes.Load())
+	if stats.retries.Load() == 0 {
+		t.Error("no request was rate limited; the run didn't exercise 429 handling")
+	}
+	if devices >= len(soakSkews) && stats.skewRejected.Load() == 0 {
+		t.Error("no push was rejected for clock skew")
+	}
+}
+
+// pullAll returns the user's live notes by uid, from a full pull
+func pullAll(t *testing.T, c *syncclient.Client) map[string]map[string]any {
+	t.Helper()
+	items := make(map[string]map[string]any)
+	var cursor string
+	for {
+		resp, err := c.Pull(context.Background(), "notes", cursor, 500)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range resp.Upserts {
+			items[item["uid"].(string)] = item
+		}
+		for _, del := range resp.Deletes {
+			delete(items, del["uid"].(string))
+		}
+		if !resp.HasMore {
+			return items
+		}
+		cursor = *resp.NextCursor
+	}
+}
@@ -0,0 +1,134 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+func note(uid, title, updatedTs string) map[string]any {
+	return map[string]any{
+		"uid":       uid,
+		"title":     title,
+		"updatedTs": updatedTs,
+		"sync":      map[string]any{"version": float64(1)},
+	}
+}
+
+func pushOne(t *testing.T, c *syncclient.Client, item map[string]any) syncclient.PushAck {
+	t.Helper()
+	acks, err := c.Push(context.Background(), "notes", []map[string]any{item})
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if len(acks) != 1 || acks[0].Error != "" {
+		t.Fatalf("push acks = %+v", acks)
+	}
+	return acks[0]
+}
+
+func TestLastWriteWins(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	c := env.Client(t, "lww-user")
+	const uid = "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"
+
+	newer := pushOne(t, c, note(uid, "newer", "2025-11-03T10:01:00Z"))
+	if newer.Version != 1 {
+		t.Fatalf("first push version = %d, want 1", newer.Version)
+	}
+	if older := pushOne(t, c, note(uid, "older", "2025-11-03T10:00:00Z")); older != newer {
+		t.Errorf("older edit ack = %+v, want the stored %+v", older, newer)
+	}
+	if again := pushOne(t, c, note(uid, "newer", "2025-11-03T10:01:00Z")); again.Version != 1 {
+		t.Errorf("repeated push bumped version to %d", again.Version)
+	}
+	if edit := pushOne(t, c, note(uid, "newest", "2025-11-03T10:02:00Z")); edit.Version != 2 {
+		t.Errorf("newer edit version = %d, want 2", edit.Version)
+	}
+
+	resp, err := c.Pull(context.Background(), "notes", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Upserts) != 1 || resp.Upserts[0]["title"] != "newest" {
+		t.Errorf("pulled %v, want the newest edit", resp.Upserts)
+	}
+}
+
+func TestCursors(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	alice := env.Client(t, "alice")
+	bob := env.Client(t, "bob")
+
+	const n = 5
+	for i := 0; i < n; i++ {
+		uid := fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		pushOne(t, alice, note(uid, "n", fmt.Sprintf("2025-11-03T10:00:0%dZ", i)))
+	}
+
+	var cursor string
+	var got, pages int
+	for {
+		resp, err := alice.Pull(ctx, "notes", cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		got += len(resp.Upserts)
+		cursor = *resp.NextCursor
+		if !resp.HasMore {
+			break
+		}
+	}
+	if got != n || pages != 3 {
+		t.Errorf("paged %d notes in %d pages, want %d in 3", got, pages, n)
+	}
+
+	// Cursors are scoped to the user (and entity) they were issued to
+	var se *syncclient.StatusError
+	if _, err := bob.Pull(ctx, "notes", cursor, 2); !errors.As(err, &se) || se.Status != http.StatusBadRequest {
+		t.Errorf("another user's cursor: err = %v, want 400", err)
+	}
+	if _, err := alice.Pull(ctx, "tasks", cursor, 2); !errors.As(err, &se) || se.Status != http.StatusBadRequest {
+		t.Errorf("another entity's cursor: err = %v, want 400", err)
+	}
+	if resp, err := bob.Pull(ctx, "notes", "", 0); err != nil || len(resp.Upserts) != 0 {
+		t.Errorf("bob pulled %v (err %v), want nothing of alice's", resp, err)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	env := testutil.NewEnv(t, nil)
+	ctx := context.Background()
+	c := env.Client(t, "wipe-user")
+	pushOne(t, c, note("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f", "gone soon", "2025-11-03T10:00:00Z"))
+
+	_, before := c.Session()
+	epoch, err := c.Wipe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epoch != before+1 {
+		t.Errorf("epoch after wipe = %d, want %d", epoch, before+1)
+	}
+
+	if err := c.BeginSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, e := c.Session(); e != epoch {
+		t.Errorf("new session epoch = %d, want %d", e, epoch)
+	}
+	resp, err := c.Pull(ctx, "notes", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Upserts)+len(resp.Deletes) != 0 {
+		t.Errorf("pulled %v after wipe, want nothing", resp)
+	}
+}
@@ -0,0 +1,118 @@
+// Package textdiff computes line diffs between two versions of a text
+package textdiff
+
+import "strings"
+
+// Diff operations
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// maxCells bounds the LCS table; larger inputs diff as a whole replacement
+// of the changed middle instead
+const maxCells = 4_000_000
+
+// Edit is a run of consecutive lines with the same operation
+type Edit struct {
+	Op    string   `json:"op"`
+	Lines []string `json:"lines"`
+}
+
+// Lines diffs a against b line by line (longest common subsequence)
+// Returns the runs that turn a into b, deletions before insertions within a
+// change; nil when the texts are equal.
+func Lines(a, b string) []Edit {
+	if a == b {
+		return nil
+	}
+	x, y := split(a), split(b)
+
+	// Common prefix and suffix don't need the table
+	pre := 0
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+
+	var d differ
+	d.add(OpEqual, x[:pre]...)
+	d.middle(x[pre:len(x)-suf], y[pre:len(y)-suf])
+	d.add(OpEqual, x[len(x)-suf:]...)
+	if len(d.edits) == 1 && d.edits[0].Op == OpEqual {
+		return nil // Only line terminators differ
+	}
+	return d.edits
+}
+
+// split breaks text into lines, without their terminators
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type differ struct {
+	edits []Edit
+}
+
+// add appends lines to the last run when it has the same op
+func (d *differ) add(op string, lines ...string) {
+	if len(lines) == 0 {
+		return
+	}
+	if n := len(d.edits); n > 0 && d.edits[n-1].Op == op {
+		d.edits[n-1].Lines = append(d.edits[n-1].Lines, lines...)
+		return
+	}
+	d.edits = append(d.edits, Edit{Op: op, Lines: append([]string(nil), lines...)})
+}
+
+func (d *differ) middle(x, y []string) {
+	if len(x)*len(y) > maxCells || len(x) == 0 || len(y) == 0 {
+		d.add(OpDelete, x...)
+		d.add(OpInsert, y...)
+		return
+	}
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int32, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table, holding insertions back until the deletions they replace
+	// are out so each change reads delete-then-insert
+	var inserts []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			d.add(OpInsert, inserts...)
+			inserts = inserts[:0]
+			d.add(OpEqual, x[i])
+			i, j = i+1, j+1
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			inserts = append(inserts, y[j])
+			j++
+		default:
+			d.add(OpDelete, x[i])
+			i++
+		}
+	}
+	d.add(OpInsert, inserts...)
+}
@@ -0,0 +1,61 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{name: "equal", a: "one\ntwo", b: "one\ntwo"},
+		{name: "from empty", a: "", b: "one\ntwo\n", want: []Edit{{OpInsert, []string{"one", "two"}}}},
+		{name: "to empty", a: "one", b: "", want: []Edit{{OpDelete, []string{"one"}}}},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Edit{{OpEqual, []string{"one"}}, {OpDelete, []string{"two"}}, {OpInsert, []string{"2"}}, {OpEqual, []string{"three"}}},
+		},
+		{
+			name: "insert and delete in the middle",
+			a:    "a\nb\nc\nd\ne",
+			b:    "a\nx\nb\nd\ne",
+			want: []Edit{{OpEqual, []string{"a"}}, {OpInsert, []string{"x"}}, {OpEqual, []string{"b"}}, {OpDelete, []string{"c"}}, {OpEqual, []string{"d", "e"}}},
+		},
+		{
+			name: "trailing newline only",
+			a:    "one\n",
+			b:    "one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Applying the edits to a must give b
+func TestLinesRoundTrip(t *testing.T) {
+	a := "the\nquick\nbrown\nfox\njumps\nover\nthe\nlazy\ndog"
+	b := "a\nquick\nred\nfox\njumps\nthe\nlazy\nsleeping\ndog"
+	var gotA, gotB []string
+	for _, e := range Lines(a, b) {
+		if e.Op != OpInsert {
+			gotA = append(gotA, e.Lines...)
+		}
+		if e.Op != OpDelete {
+			gotB = append(gotB, e.Lines...)
+		}
+	}
+	if strings.Join(gotA, "\n") != a || strings.Join(gotB, "\n") != b {
+		t.Errorf("edits rebuild %q -> %q", gotA, gotB)
+	}
+}
@@ -0,0 +1,240 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// entityTables are the per-user sync tables included in row/storage counts
+var entityTables = []string{"note", "task", "task_list", "task_list_category", "comment", "chat", "chat_message", "pin"}
+
+// EntityUsage is the row and storage footprint of one entity type
+type EntityUsage struct {
+	Rows       int64 `json:"rows"`
+	Tombstones int64 `json:"tombstones"` // Included in Rows
+	Bytes      int64 `json:"bytes"`      // Sum of row sizes (pg_column_size), excluding indexes
+}
+
+func (e *EntityUsage) add(o EntityUsage) {
+	e.Rows += o.Rows
+	e.Tombstones += o.Tombstones
+	e.Bytes += o.Bytes
+}
+
+// Requests summarizes sync request volume from sync_analytics_daily
+type Requests struct {
+	PushesToday int64 `json:"pushesToday"` // Current UTC day
+	PullsToday  int64 `json:"pullsToday"`
+	Pushes30d   int64 `json:"pushes30d"` // Last 30 UTC days including today
+	Pulls30d    int64 `json:"pulls30d"`
+	BytesIn30d  int64 `json:"bytesIn30d"`
+	BytesOut30d int64 `json:"bytesOut30d"`
+}
+
+func (r *Requests) add(o Requests) {
+	r.PushesToday += o.PushesToday
+	r.PullsToday += o.PullsToday
+	r.Pushes30d += o.Pushes30d
+	r.Pulls30d += o.Pulls30d
+	r.BytesIn30d += o.BytesIn30d
+	r.BytesOut30d += o.BytesOut30d
+}
+
+// Totals is usage for one user or the whole deployment
+type Totals struct {
+	Entities       map[string]EntityUsage `json:"entities"` // Keyed by entity table name
+	Rows           int64                  `json:"rows"`
+	Bytes          int64                  `json:"bytes"`
+	ActiveSessions int                    `json:"activeSessions"`
+	Requests       Requests               `json:"requests"`
+}
+
+func newTotals() Totals {
+	return Totals{Entities: make(map[string]EntityUsage)}
+}
+
+func (t *Totals) addEntity(entity string, e EntityUsage) {
+	cur := t.Entities[entity]
+	cur.add(e)
+	t.Entities[entity] = cur
+	t.Rows += e.Rows
+	t.Bytes += e.Bytes
+}
+
+// UserUsage is one user's usage
+type UserUsage struct {
+	UserID  string `json:"userId"`
+	Subject string `json:"subject"`
+	Totals
+}
+
+// Snapshot is a point-in-time usage report
+type Snapshot struct {
+	ComputedAt time.Time   `json:"computedAt"`
+	Users      int         `json:"users"` // Users with any rows, sessions or recent requests
+	Global     Totals      `json:"global"`
+	PerUser    []UserUsage `json:"-"` // Sorted by Bytes, largest first
+	byID       map[string]*UserUsage
+}
+
+// User returns a user's usage from the snapshot
+func (s *Snapshot) User(userID string) (UserUsage, bool) {
+	u, ok := s.byID[userID]
+	if !ok {
+		return UserUsage{}, false
+	}
+	return *u, true
+}
+
+// Collector computes usage snapshots and caches them for TTL
+// The aggregate queries scan every entity table, so operators hitting the
+// endpoint repeatedly share one computation per TTL.
+type Collector struct {
+	DB  *pgxpool.Pool
+	TTL time.Duration
+
+	mu   sync.Mutex // Held while computing, so concurrent callers wait for one query
+	snap *Snapshot
+}
+
+// NewCollector creates a collector with a 5 minute cache
+func NewCollector(db *pgxpool.Pool) *Collector {
+	return &Collector{DB: db, TTL: 5 * time.Minute}
+}
+
+// Snapshot returns the cached snapshot, recomputing it when older than TTL (or when refresh is set)
+func (c *Collector) Snapshot(ctx context.Context, refresh bool) (*Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !refresh && c.snap != nil && time.Since(c.snap.ComputedAt) < c.TTL {
+		return c.snap, nil
+	}
+	snap, err := c.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.snap = snap
+	return snap, nil
+}
+
+func (c *Collector) compute(ctx context.Context) (*Snapshot, error) {
+	snap := &Snapshot{
+		ComputedAt: time.Now().UTC(),
+		Global:     newTotals(),
+		byID:       make(map[string]*UserUsage),
+	}
+	user := func(id string) *UserUsage {
+		u, ok := snap.byID[id]
+		if !ok {
+			u = &UserUsage{UserID: id, Totals: newTotals()}
+			snap.byID[id] = u
+		}
+		return u
+	}
+
+	// Row counts and storage per user and entity (one round trip)
+	parts := make([]string, 0, len(entityTables))
+	for _, t := range entityTables {
+		parts = append(parts, `
+			SELECT '`+t+`' AS entity, owner_id::text, count(*),
+			       count(*) FILTER (WHERE deleted_at_ms IS NOT NULL),
+			       coalesce(sum(pg_column_size(t.*)), 0)::bigint
+			FROM `+t+` t GROUP BY owner_id`)
+	}
+	rows, err := c.DB.Query(ctx, strings.Join(parts, " UNION ALL "))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var entity, owner string
+		var e EntityUsage
+		if err := rows.Scan(&entity, &owner, &e.Rows, &e.Tombstones, &e.Bytes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		user(owner).addEntity(entity, e)
+		snap.Global.addEntity(entity, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Request volume from the daily rollup
+	rows, err = c.DB.Query(ctx, `
+		SELECT owner_id::text,
+		       coalesce(sum(pushes) FILTER (WHERE day = current_date), 0)::bigint,
+		       coalesce(sum(pulls) FILTER (WHERE day = current_date), 0)::bigint,
+		       sum(pushes)::bigint, sum(pulls)::bigint, sum(bytes_in)::bigint, sum(bytes_out)::bigint
+		FROM sync_analytics_daily
+		WHERE day > current_date - 30
+		GROUP BY owner_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var owner string
+		var r Requests
+		if err := rows.Scan(&owner, &r.PushesToday, &r.PullsToday, &r.Pushes30d, &r.Pulls30d, &r.BytesIn30d, &r.BytesOut30d); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		user(owner).Requests.add(r)
+		snap.Global.Requests.add(r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Active sessions (this replica's only with the in-memory store)
+	for owner, n := range session.GetStore().ActiveCounts() {
+		user(owner).ActiveSessions += n
+		snap.Global.ActiveSessions += n
+	}
+
+	// Subjects for readability
+	if len(snap.byID) > 0 {
+		ids := make([]string, 0, len(snap.byID))
+		for id := range snap.byID {
+			ids = append(ids, id)
+		}
+		rows, err = c.DB.Query(ctx, `SELECT id::text, sub FROM app_user WHERE id::text = ANY($1)`, ids)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id, sub string
+			if err := rows.Scan(&id, &sub); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			snap.byID[id].Subject = sub
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	snap.Users = len(snap.byID)
+	snap.PerUser = make([]UserUsage, 0, len(snap.byID))
+	for _, u := range snap.byID {
+		snap.PerUser = append(snap.PerUser, *u)
+	}
+	sort.Slice(snap.PerUser, func(i, j int) bool {
+		if snap.PerUser[i].Bytes != snap.PerUser[j].Bytes {
+			return snap.PerUser[i].Bytes > snap.PerUser[j].Bytes
+		}
+		return snap.PerUser[i].UserID < snap.PerUser[j].UserID
+	})
+	return snap, nil
+}
@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// Delivery request headers
+const (
+	HeaderEvent     = "X-Toolbridge-Event"
+	HeaderDelivery  = "X-Toolbridge-Delivery"
+	HeaderTimestamp = "X-Toolbridge-Timestamp"
+	HeaderSignature = "X-Toolbridge-Signature"
+)
+
+// HTTPDoer sends delivery requests (an *http.Client in production)
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// errPrivateAddress is returned when a callback resolves to a non-public address
+var errPrivateAddress = errors.New("callback address is not public")
+
+// nonPublicPrefixes are ranges publicIP rejects beyond loopback, private,
+// link-local and multicast: "this network", carrier-grade NAT, benchmarking
+// and NAT64 (which can embed any IPv4 address)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicIP reports whether a callback may be delivered to ip
+// Loopback, private, link-local (including cloud metadata at 169.254.169.254)
+// and other internal addresses are rejected so subscriptions can't reach the
+// server's own network.
+func publicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultClient returns the delivery HTTP client
+// Redirects are not followed: a subscriber must register its final URL.
+// Unless allowPrivate returns true (dev mode), connections to non-public
+// addresses are refused after DNS resolution, which also defeats hostnames
+// that resolve (or rebind) to internal addresses. Proxies are not used: the
+// dialer would only see the proxy's address.
+func defaultClient(allowPrivate func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil || !publicIP(ap.Addr()) {
+				return fmt.Errorf("%w: %s", errPrivateAddress, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign computes the X-Toolbridge-Signature value for a delivery
+// Subscribers recompute HMAC-SHA256(secret, timestamp + "." + body) and compare
+// in constant time, rejecting stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before the next attempt after attempts failures
+func (s *Service) backoff(attempts int) time.Duration {
+	d := s.RetryBase
+	for i := 1; i < attempts && d < s.RetryMax; i++ {
+		d *= 2
+	}
+	return min(d, s.RetryMax)
+}
+
+// Run delivers queued events until ctx is cancelled
+// Each tick drains due deliveries, then deletes old finished ones.
+// Safe to run on every replica: deliveries are claimed with FOR UPDATE SKIP LOCKED
+// and leased for Lease, so a crashed worker's deliveries are retried later.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.processBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("webhook delivery failed")
+			}
+			if n == 0 || ctx.Err() != nil {
+				break
+			}
+		}
+		if _, err := s.DB.Exec(ctx, `
+			DELETE FROM webhook_delivery
+			WHERE status <> 'pending' AND created_at < now() - $1::interval
+		`, fmt.Sprintf("%d seconds", int64(s.Retention.Seconds()))); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("webhook delivery cleanup failed")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// claimed is a delivery leased by this worker
+type claimed struct {
+	id        string
+	eventType string
+	payload   []byte
+	attempts  int
+	url       string
+	secret    string
+}
+
+// processBatch claims up to BatchSize due deliveries and attempts each once
+// Returns how many were claimed.
+func (s *Service) processBatch(ctx context.Context) (int, error) {
+	rows, err := s.DB.Query(ctx, `
+		UPDATE webhook_delivery d
+		SET next_attempt_at = now() + $1::interval
+		FROM webhook_subscription sub
+		WHERE d.subscription_id = sub.id
+		  AND d.id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id::text, d.event_type, d.payload::text, d.attempts, sub.url, sub.secret
+	`, fmt.Sprintf("%d seconds", int64(s.Lease.Seconds())), s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var batch []claimed
+	for rows.Next() {
+		var c claimed
+		var payload string
+		if err := rows.Scan(&c.id, &c.eventType, &payload, &c.attempts, &c.url, &c.secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		c.payload = []byte(payload)
+		batch = append(batch, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range batch {
+		if ctx.Err() != nil {
+			break // Unattempted deliveries are retried when the lease expires
+		}
+		if err := s.attempt(ctx, c); err != nil {
+			return len(batch), err
+		}
+	}
+	return len(batch), nil
+}
+
+// attempt POSTs one delivery and records the outcome
+func (s *Service) attempt(ctx context.Context, c claimed) error {
+	logger := log.With().Str("deliveryId", c.id).Str("event", c.eventType).Logger()
+	attempts := c.attempts + 1
+
+	code, sendErr := s.send(ctx, c)
+	if sendErr != nil && ctx.Err() != nil {
+		return nil // Shutting down; the lease expires and another worker retries
+	}
+	var statusCode *int
+	if code != 0 {
+		statusCode = &code
+	}
+
+	if sendErr == nil {
+		metrics.ObserveWebhookDelivery("succeeded")
+		_, err := s.DB.Exec(ctx, `
+			UPDATE webhook_delivery
+			SET status = 'succeeded', attempts = $2, last_status_code = $3, last_error = NULL, delivered_at = now()
+			WHERE id = $1
+		`, c.id, attempts, statusCode)
+		return err
+	}
+
+	errMsg := sendErr.Error()
+	if attempts >= s.MaxAttempts {
+		metrics.ObserveWebhookDelivery("failed")
+		logger.Warn().Err(sendErr).Int("attempts", attempts).Msg("webhook delivery failed permanently")
+		_, err := s.DB.Exec(ctx, `
+			UPDATE webhook_delivery
+			SET status = 'failed', attempts = $2, last_status_code = $3, last_error = $4
+			WHERE id = $1
+		`, c.id, attempts, statusCode, errMsg)
+		return err
+	}
+
+	metrics.ObserveWebhookDelivery("retry")
+	logger.Debug().Err(sendErr).Int("attempts", attempts).Msg("webhook delivery failed; will retry")
+	_, err := s.DB.Exec(ctx, `
+		UPDATE webhook_delivery
+		SET attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = now() + $5::interval
+		WHERE id = $1
+	`, c.id, attempts, statusCode, errMsg, fmt.Sprintf("%d seconds", int64(s.backoff(attempts).Seconds())))
+	return err
+}
+
+// send POSTs a signed delivery; any non-2xx response is an error
+// Returns the response status code (0 if there was no response).
+func (s *Service) send(ctx context.Context, c claimed) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(c.payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "toolbridge-webhooks/1")
+	req.Header.Set(HeaderEvent, c.eventType)
+	req.Header.Set(HeaderDelivery, c.id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(c.secret, ts, c.payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("callback returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,323 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Entities lists the entity names a subscription can filter on
+// These match the entity field of the activity feed.
+var Entities = []string{"note", "task", "task_list", "task_list_category", "comment", "chat", "chat_message", "pin"}
+
+// MaxSubscriptions caps webhook subscriptions per user
+const MaxSubscriptions = 10
+
+// ErrNotFound is returned when a subscription doesn't exist (or belongs to another user)
+var ErrNotFound = errors.New("webhook subscription not found")
+
+// ErrLimit is returned when the user already has MaxSubscriptions subscriptions
+var ErrLimit = errors.New("webhook subscription limit reached")
+
+// ValidationError describes an invalid subscription request
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Event is the JSON body POSTed to subscribers for one entity change
+type Event struct {
+	ID         string `json:"id"`      // Delivery ID (stable across retries)
+	EventID    string `json:"eventId"` // Change event ID (dedup key; the same for every subscription)
+	Type       string `json:"type"`    // <entity>.<action>, e.g. task.updated
+	Entity     string `json:"entity"`
+	UID        string `json:"uid"`
+	Action     string `json:"action"`
+	Version    int    `json:"version"`
+	UpdatedAt  string `json:"updatedAt"`  // Entity timestamp after the change
+	OccurredAt string `json:"occurredAt"` // Server time the change was committed
+}
+
+// Subscription is a registered callback
+// Secret is only populated in the response to Create.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Entities  []string  `json:"entities"` // Empty = all entities
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Delivery is one entry of a subscription's delivery log
+type Delivery struct {
+	ID             string          `json:"id"`
+	EventType      string          `json:"eventType"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"` // Only while pending
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// Service manages subscriptions and delivers queued events
+// Deliveries are retried with exponential backoff (RetryBase doubling, capped
+// at RetryMax) until MaxAttempts, then marked failed.
+type Service struct {
+	DB          *pgxpool.Pool
+	Client      HTTPDoer
+	AllowHTTP   bool          // Accept plain http:// and private-network callbacks (dev only)
+	MaxAttempts int           // Attempts before a delivery is marked failed
+	RetryBase   time.Duration // Delay before the first retry
+	RetryMax    time.Duration // Longest delay between retries
+	Lease       time.Duration // How long a claimed delivery is hidden from other workers
+	Retention   time.Duration // Finished deliveries older than this are deleted
+	BatchSize   int           // Deliveries claimed per worker tick
+}
+
+// NewService creates a webhook service with default retry policy
+func NewService(db *pgxpool.Pool) *Service {
+	s := &Service{
+		DB:          db,
+		MaxAttempts: 8,
+		RetryBase:   30 * time.Second,
+		RetryMax:    6 * time.Hour,
+		Lease:       2 * time.Minute,
+		Retention:   30 * 24 * time.Hour,
+		BatchSize:   50,
+	}
+	// AllowHTTP is usually set after construction, so the client reads it per dial
+	s.Client = defaultClient(func() bool { return s.AllowHTTP })
+	return s
+}
+
+// ValidateURL checks that a callback URL is absolute HTTPS (or HTTP when allowed)
+// Literal non-public IPs are rejected unless AllowHTTP is set; hostnames are
+// checked when delivery resolves them (see defaultClient).
+func (s *Service) ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return &ValidationError{Msg: "url must be an absolute URL"}
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && s.AllowHTTP:
+	default:
+		return &ValidationError{Msg: "url must use https"}
+	}
+	if u.User != nil {
+		return &ValidationError{Msg: "url must not contain credentials"}
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !s.AllowHTTP && !publicIP(ip) {
+		return &ValidationError{Msg: "url must not point to a private, loopback or link-local address"}
+	}
+	return nil
+}
+
+// normalizeEntities validates and de-duplicates an entity filter
+func normalizeEntities(entities []string) ([]string, error) {
+	out := make([]string, 0, len(entities))
+	for _, e := range entities {
+		if !slices.Contains(Entities, e) {
+			return nil, &ValidationError{Msg: "unknown entity: " + e}
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// newSecret returns a random signing secret
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Create registers a subscription for the user and returns it with its secret
+func (s *Service) Create(ctx context.Context, userID, callbackURL string, entities []string) (*Subscription, error) {
+	if err := s.ValidateURL(callbackURL); err != nil {
+		return nil, err
+	}
+	entities, err := normalizeEntities(entities)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := Subscription{URL: callbackURL, Entities: entities, Secret: secret}
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Serialize per-user creates so the limit check can't race
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('webhook:' || $1))`, userID); err != nil {
+			return err
+		}
+		var n int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM webhook_subscription WHERE owner_id = $1 AND kind = 'generic'`, userID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= MaxSubscriptions {
+			return ErrLimit
+		}
+		return tx.QueryRow(ctx, `
+			INSERT INTO webhook_subscription (owner_id, url, secret, entities)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id::text, created_at
+		`, userID, callbackURL, secret, entities).Scan(&sub.ID, &sub.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List returns the user's subscriptions, oldest first (without secrets)
+// Subscriptions owned by integrations (e.g. Slack) are listed by the integration.
+func (s *Service) List(ctx context.Context, userID string) ([]Subscription, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT id::text, url, entities, created_at
+		FROM webhook_subscription
+		WHERE owner_id = $1 AND kind = 'generic'
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []Subscription{}
+	for rows.Next() {
+		var sub Subscription
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Entities, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// Get returns the user's subscription by ID (without its secret)
+func (s *Service) Get(ctx context.Context, userID, id string) (*Subscription, error) {
+	var sub Subscription
+	err := s.DB.QueryRow(ctx, `
+		SELECT id::text, url, entities, created_at
+		FROM webhook_subscription
+		WHERE id = $1 AND owner_id = $2
+	`, id, userID).Scan(&sub.ID, &sub.URL, &sub.Entities, &sub.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Delete removes the user's subscription and its pending deliveries
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	tag, err := s.DB.Exec(ctx, `DELETE FROM webhook_subscription WHERE id = $1 AND owner_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Deliveries returns a subscription's most recent deliveries, newest first
+func (s *Service) Deliveries(ctx context.Context, userID, id string, limit int) ([]Delivery, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT id::text, event_type, status, attempts, last_status_code, last_error,
+		       next_attempt_at, created_at, delivered_at, payload
+		FROM webhook_delivery
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Delivery, 0, limit)
+	for rows.Next() {
+		var d Delivery
+		var next time.Time
+		if err := rows.Scan(&d.ID, &d.EventType, &d.Status, &d.Attempts, &d.LastStatusCode, &d.LastError,
+			&next, &d.CreatedAt, &d.DeliveredAt, &d.Payload); err != nil {
+			return nil, err
+		}
+		if d.Status == StatusPending {
+			d.NextAttemptAt = &next
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Name implements outbox.Publisher
+func (s *Service) Name() string { return "webhooks" }
+
+// Publish implements outbox.Publisher: it queues a delivery of each event to
+// every generic subscription of its owner that matches its entity
+// Runs in the dispatcher's transaction. A re-dispatched event doesn't queue a
+// second delivery (unique per subscription and event).
+func (s *Service) Publish(ctx context.Context, tx pgx.Tx, events []outbox.Event) error {
+	for _, e := range events {
+		ev := Event{
+			EventID:    e.ID,
+			Type:       e.Entity + "." + e.Action,
+			Entity:     e.Entity,
+			UID:        e.UID.String(),
+			Action:     e.Action,
+			Version:    e.Version,
+			UpdatedAt:  syncx.RFC3339(e.UpdatedAtMs),
+			OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		}
+		if _, err := tx.Exec(ctx, `
+			WITH d AS (
+				SELECT id AS subscription_id, uuid_generate_v4() AS id
+				FROM webhook_subscription
+				WHERE owner_id = $1 AND kind = 'generic' AND (cardinality(entities) = 0 OR $2 = ANY(entities))
+			)
+			INSERT INTO webhook_delivery (id, subscription_id, event_id, event_type, payload)
+			SELECT d.id, d.subscription_id, $3, $4, $5::jsonb || jsonb_build_object('id', d.id::text)
+			FROM d
+			ON CONFLICT (subscription_id, event_id) DO NOTHING
+		`, e.OwnerID, e.Entity, e.ID, ev.Type, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Package websocket is the server side of RFC 6455 for notification sockets:
+// the server sends text messages, pings and a final close frame. Client
+// data frames are read and discarded; pings are answered and a client close
+// is echoed. No extensions or subprotocols are negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Close codes (RFC 6455 section 7.4.1); 4000-4999 are free for applications
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooBig        = 1009
+	CloseInternalError = 1011
+)
+
+// ErrNotWebSocket is returned by Upgrade for requests that aren't a
+// version 13 WebSocket handshake; nothing has been written to the response
+var ErrNotWebSocket = errors.New("websocket handshake required (GET with Upgrade: websocket, Sec-WebSocket-Version: 13)")
+
+// Frame opcodes
+const (
+	opText   = 0x1
+	opClose  = 0x8
+	opPing   = 0x9
+	opPong   = 0xA
+	maxFrame = 64 << 10 // Larger client frames close the socket with CloseTooBig
+)
+
+// handshakeGUID is appended to Sec-WebSocket-Key to compute Sec-WebSocket-Accept
+const handshakeGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeTimeout bounds every frame write, so a stalled client can't block its sender
+const writeTimeout = 10 * time.Second
+
+// Conn is an upgraded server connection
+// Writes are safe for concurrent use. The connection is read in the
+// background until the client closes it or the read timeout passes without a
+// frame; Done is closed then.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	readTimeout time.Duration
+
+	mu      sync.Mutex // Serializes writes
+	closing bool       // A close frame was sent
+
+	done chan struct{}
+}
+
+// Upgrade completes the WebSocket handshake and takes over the connection
+// The client must send a frame (a pong will do) at least every readTimeout;
+// 0 means no timeout. On ErrNotWebSocket the caller still owns w and should
+// answer the request.
+func Upgrade(w http.ResponseWriter, r *http.Request, readTimeout time.Duration) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!hasToken(r.Header, "Connection", "upgrade") ||
+		!hasToken(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		return nil, ErrNotWebSocket
+	}
+
+	nc, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + handshakeGUID))
+	_ = nc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	if err := brw.Flush(); err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	c := &Conn{conn: nc, br: brw.Reader, readTimeout: readTimeout, done: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+// Done is closed when the connection has ended
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// WriteText sends one text message
+func (c *Conn) WriteText(msg []byte) error {
+	return c.writeFrame(opText, msg)
+}
+
+// Ping sends a ping; the client's pong keeps the read timeout from expiring
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a close frame with code and reason (at most 123 bytes) and
+// closes the connection once the client echoes it, or after a second
+// Only the first close frame is sent; closing again just waits for the end.
+func (c *Conn) Close(code int, reason string) error {
+	err := c.writeClose(code, reason)
+	select {
+	case <-c.done:
+	case <-time.After(time.Second):
+		c.conn.Close()
+		<-c.done
+	}
+	return err
+}
+
+// writeClose sends the close frame, once
+func (c *Conn) writeClose(code int, reason string) error {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closing = true
+	c.mu.Unlock()
+
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.write(opClose, append(payload, reason...), true)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	return c.write(op, payload, false)
+}
+
+// write sends one unmasked final frame; once a close frame was sent, only
+// that close frame itself is written
+func (c *Conn) write(op byte, payload []byte, isClose bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing && !isClose {
+		return net.ErrClosed
+	}
+
+	hdr := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n <= 125:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = binary.BigEndian.AppendUint16(append(hdr, 126), uint16(n))
+	default:
+		hdr = binary.BigEndian.AppendUint64(append(hdr, 127), uint64(n))
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := (&net.Buffers{hdr, payload}).WriteTo(c.conn)
+	return err
+}
+
+// readLoop reads client frames until the connection ends
+func (c *Conn) readLoop() {
+	defer close(c.done)
+	defer c.conn.Close()
+
+	for {
+		if c.readTimeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+		}
+		op, payload, err := c.readFrame()
+		if err != nil {
+			var ce closeError
+			if errors.As(err, &ce) {
+				_ = c.writeClose(int(ce), "")
+			}
+			return
+		}
+		switch op {
+		case opPing:
+			_ = c.writeFrame(opPong, payload)
+		case opClose:
+			// Echo the client's code (or answer ours); the connection then ends
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			_ = c.writeClose(code, "")
+			return
+		}
+	}
+}
+
+// closeError is a protocol violation answered with its close code
+type closeError int
+
+func (e closeError) Error() string { return "websocket: protocol error" }
+
+// readFrame reads and unmasks one client frame
+// Fragments are returned as they arrive; data frames are only discarded.
+func (c *Conn) readFrame() (byte, []byte, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	op := hdr[0] & 0x0F
+	if hdr[0]&0x70 != 0 || hdr[1]&0x80 == 0 {
+		return 0, nil, closeError(CloseProtocolError) // Reserved bits set, or unmasked
+	}
+
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if op >= opClose && (n > 125 || hdr[0]&0x80 == 0) {
+		return 0, nil, closeError(CloseProtocolError) // Control frames are short and unfragmented
+	}
+	if n > maxFrame {
+		return 0, nil, closeError(CloseTooBig)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// hasToken reports whether a comma-separated header contains token (case-insensitive)
+func hasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dial performs a client handshake against srv
+func dial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_, _ = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	// The RFC 6455 example key and accept value
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", got)
+	}
+	return conn, br
+}
+
+// readFrame reads one unmasked server frame
+func readFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var hdr [2]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(hdr[1] & 0x7F)
+	if n == 126 {
+		var b [2]byte
+		_, _ = io.ReadFull(br, b[:])
+		n = int(binary.BigEndian.Uint16(b[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return hdr[0] & 0x0F, payload
+}
+
+// writeFrame writes one masked client frame
+func writeFrame(t *testing.T, conn net.Conn, op byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | op, 0x80 | byte(len(payload))}, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConn(t *testing.T) {
+	closed := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, time.Minute)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = c.WriteText([]byte(`{"type":"ready"}`))
+		_ = c.WriteText([]byte(strings.Repeat("x", 300)))
+		<-c.Done() // Until the client pings, then closes
+		closed <- nil
+	}))
+	defer srv.Close()
+
+	conn, br := dial(t, srv)
+	if op, msg := readFrame(t, br); op != opText || string(msg) != `{"type":"ready"}` {
+		t.Errorf("first frame = %x %q", op, msg)
+	}
+	if op, msg := readFrame(t, br); op != opText || len(msg) != 300 {
+		t.Errorf("second frame = %x, %d bytes", op, len(msg))
+	}
+
+	writeFrame(t, conn, opPing, []byte("hi"))
+	if op, msg := readFrame(t, br); op != opPong || string(msg) != "hi" {
+		t.Errorf("ping answer = %x %q", op, msg)
+	}
+
+	writeFrame(t, conn, opClose, binary.BigEndian.AppendUint16(nil, CloseNormal))
+	if op, msg := readFrame(t, br); op != opClose || binary.BigEndian.Uint16(msg) != CloseNormal {
+		t.Errorf("close echo = %x %v", op, msg)
+	}
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not done after client close")
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, 0)
+		if err != nil {
+			return
+		}
+		_ = c.Close(4409, "epoch_mismatch")
+		if err := c.WriteText([]byte("late")); err == nil {
+			t.Error("expected write after close to fail")
+		}
+	}))
+	defer srv.Close()
+
+	conn, br := dial(t, srv)
+	op, msg := readFrame(t, br)
+	if op != opClose || binary.BigEndian.Uint16(msg) != 4409 || string(msg[2:]) != "epoch_mismatch" {
+		t.Fatalf("close frame = %x %v", op, msg)
+	}
+	writeFrame(t, conn, opClose, msg[:2])
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := Upgrade(w, r, 0); err != ErrNotWebSocket {
+			t.Errorf("err = %v, want ErrNotWebSocket", err)
+		}
+		w.WriteHeader(http.StatusUpgradeRequired)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestUnmaskedFrameIsProtocolError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := Upgrade(w, r, 0); err == nil {
+			<-c.Done()
+		}
+	}))
+	defer srv.Close()
+
+	conn, br := dial(t, srv)
+	_, _ = conn.Write([]byte{0x81, 0x02, 'h', 'i'})
+	if op, msg := readFrame(t, br); op != opClose || binary.BigEndian.Uint16(msg) != CloseProtocolError {
+		t.Errorf("answer = %x %v", op, msg)
+	}
+}
@@ -0,0 +1,136 @@
+package zapier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// KeyPrefix starts every API key, so leaked keys are easy to recognize
+const KeyPrefix = "tbk_"
+
+// MaxAPIKeys caps API keys per user
+const MaxAPIKeys = 10
+
+// ErrInvalidKey is returned by Authenticate for unknown or malformed keys
+var ErrInvalidKey = errors.New("invalid api key")
+
+// APIKey describes a key (Key is only set in the response to CreateKey)
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// hashKey returns the stored form of an API key
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateKey issues a new API key for the user
+func (s *Service) CreateKey(ctx context.Context, userID, name string) (*APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, &ValidationError{Msg: "name is required (at most 100 characters)"}
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	key := APIKey{Name: name, Key: KeyPrefix + hex.EncodeToString(b)}
+	key.Prefix = key.Key[:len(KeyPrefix)+8]
+
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		// Serialize per-user creates so the limit check can't race
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('apikey:' || $1))`, userID); err != nil {
+			return err
+		}
+		var n int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM api_key WHERE owner_id = $1`, userID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= MaxAPIKeys {
+			return ErrLimit
+		}
+		return tx.QueryRow(ctx, `
+			INSERT INTO api_key (owner_id, name, key_hash, prefix) VALUES ($1, $2, $3, $4)
+			RETURNING id::text, created_at
+		`, userID, key.Name, hashKey(key.Key), key.Prefix).Scan(&key.ID, &key.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListKeys returns the user's API keys, oldest first (without the keys)
+func (s *Service) ListKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT id::text, name, prefix, created_at, last_used_at
+		FROM api_key WHERE owner_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.CreatedAt, &k.LastUsedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeKey deletes one of the user's API keys
+func (s *Service) RevokeKey(ctx context.Context, userID, id string) error {
+	tag, err := s.DB.Exec(ctx, `DELETE FROM api_key WHERE id = $1 AND owner_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves an API key to its user ID and subject
+// last_used_at is refreshed at most once a minute per key.
+func (s *Service) Authenticate(ctx context.Context, key string) (userID, subject string, err error) {
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return "", "", ErrInvalidKey
+	}
+	var keyID string
+	var lastUsed *time.Time
+	err = s.DB.QueryRow(ctx, `
+		SELECT k.id::text, k.owner_id::text, u.sub, k.last_used_at
+		FROM api_key k JOIN app_user u ON u.id = k.owner_id
+		WHERE k.key_hash = $1
+	`, hashKey(key)).Scan(&keyID, &userID, &subject, &lastUsed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrInvalidKey
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if lastUsed == nil || time.Since(*lastUsed) > time.Minute {
+		if _, err := s.DB.Exec(ctx, `UPDATE api_key SET last_used_at = now() WHERE id = $1`, keyID); err != nil {
+			return "", "", err
+		}
+	}
+	return userID, subject, nil
+}
@@ -0,0 +1,263 @@
+// Package zapier serves no-code platforms (Zapier, Make): static API keys,
+// a "changed since" polling endpoint and REST hook subscriptions, all with
+// flat JSON items instead of sync cursors and sessions.
+package zapier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/outbox"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Actions a REST hook can subscribe to
+var Actions = []string{"created", "updated", "deleted"}
+
+// MaxHooks caps REST hook subscriptions per user
+const MaxHooks = 50
+
+// ErrNotFound is returned when a key or hook doesn't exist (or belongs to another user)
+var ErrNotFound = errors.New("not found")
+
+// ErrLimit is returned when the user already has the maximum number of keys or hooks
+var ErrLimit = errors.New("limit reached")
+
+// ValidationError describes an invalid request
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Hook is a REST hook subscription
+type Hook struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"` // <entity>.<action>, e.g. task.created
+	TargetURL string    `json:"targetUrl"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Service implements the no-code endpoints
+type Service struct {
+	DB       *pgxpool.Pool
+	Webhooks *webhook.Service // Validates hook URLs; its worker sends the deliveries
+}
+
+// NewService creates a no-code integration service
+func NewService(db *pgxpool.Pool, webhooks *webhook.Service) *Service {
+	return &Service{DB: db, Webhooks: webhooks}
+}
+
+// omitFields are payload fields that duplicate the flat item's metadata
+var omitFields = map[string]bool{"uid": true, "updatedTs": true, "sync": true}
+
+// FlatItem renders an entity row as one flat JSON object
+// id is "<uid>:<version>", so platforms that dedupe on id see every update
+// as a new item; the payload's own fields sit alongside the metadata.
+func FlatItem(entity, uid string, version int, updatedAtMs int64, deletedAtMs *int64, payload map[string]any) map[string]any {
+	item := make(map[string]any, len(payload)+6)
+	for k, v := range payload {
+		if !omitFields[k] {
+			item[k] = v
+		}
+	}
+	item["id"] = uid + ":" + strconv.Itoa(version)
+	item["uid"] = uid
+	item["entity"] = entity
+	item["version"] = version
+	item["updatedAt"] = syncx.RFC3339(updatedAtMs)
+	item["deleted"] = deletedAtMs != nil
+	return item
+}
+
+// Poll returns the user's live items of entity changed after since, newest first
+func (s *Service) Poll(ctx context.Context, userID, entity string, since time.Time, limit int) ([]map[string]any, error) {
+	if !slices.Contains(webhook.Entities, entity) {
+		return nil, &ValidationError{Msg: "unknown entity: " + entity}
+	}
+	// entity is one of webhook.Entities, all of which are table names
+	rows, err := s.DB.Query(ctx, `
+		SELECT uid::text, version, updated_at_ms, payload_json
+		FROM `+entity+`
+		WHERE owner_id = $1 AND deleted_at_ms IS NULL AND updated_at_ms > $2
+		ORDER BY updated_at_ms DESC, uid DESC
+		LIMIT $3
+	`, userID, since.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []map[string]any{}
+	for rows.Next() {
+		var uid string
+		var version int
+		var updatedMs int64
+		var raw []byte
+		if err := rows.Scan(&uid, &version, &updatedMs, &raw); err != nil {
+			return nil, err
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			payload = map[string]any{}
+		}
+		items = append(items, FlatItem(entity, uid, version, updatedMs, nil, payload))
+	}
+	return items, rows.Err()
+}
+
+// parseEvent splits and validates "<entity>.<action>"
+func parseEvent(event string) (string, string, error) {
+	for _, action := range Actions {
+		entity, ok := strings.CutSuffix(event, "."+action)
+		if ok && slices.Contains(webhook.Entities, entity) {
+			return entity, action, nil
+		}
+	}
+	return "", "", &ValidationError{Msg: "event must be <entity>.<created|updated|deleted>, e.g. task.created"}
+}
+
+// Subscribe registers a REST hook: every matching change is POSTed to targetURL
+func (s *Service) Subscribe(ctx context.Context, userID, event, targetURL string) (*Hook, error) {
+	entity, action, err := parseEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Webhooks.ValidateURL(targetURL); err != nil {
+		var verr *webhook.ValidationError
+		if errors.As(err, &verr) {
+			return nil, &ValidationError{Msg: verr.Msg}
+		}
+		return nil, err
+	}
+
+	// Platforms don't verify signatures, so the secret is random and never shown
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	hook := Hook{Event: event, TargetURL: targetURL}
+	err = pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('zapier:' || $1))`, userID); err != nil {
+			return err
+		}
+		var n int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM zapier_hook WHERE owner_id = $1`, userID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= MaxHooks {
+			return ErrLimit
+		}
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO webhook_subscription (owner_id, url, secret, entities, kind)
+			VALUES ($1, $2, $3, ARRAY[$4], 'zapier')
+			RETURNING id::text, created_at
+		`, userID, targetURL, "whsec_"+hex.EncodeToString(secret), entity).Scan(&hook.ID, &hook.CreatedAt); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO zapier_hook (subscription_id, owner_id, entity, action, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, hook.ID, userID, entity, action, hook.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// Unsubscribe removes a REST hook and its pending deliveries
+func (s *Service) Unsubscribe(ctx context.Context, userID, id string) error {
+	tag, err := s.DB.Exec(ctx, `
+		DELETE FROM webhook_subscription
+		WHERE id = $1 AND owner_id = $2 AND kind = 'zapier'
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Name implements outbox.Publisher
+func (s *Service) Name() string { return "zapier" }
+
+// Publish implements outbox.Publisher: it queues a webhook delivery of the
+// changed item (as FlatItem) to each of the owner's hooks for the event
+// Runs in the dispatcher's transaction; the item is read as of dispatch.
+func (s *Service) Publish(ctx context.Context, tx pgx.Tx, events []outbox.Event) error {
+	for _, e := range events {
+		if !slices.Contains(webhook.Entities, e.Entity) {
+			continue
+		}
+		var hooks []string
+		rows, err := tx.Query(ctx, `
+			SELECT subscription_id::text FROM zapier_hook
+			WHERE owner_id = $1 AND entity = $2 AND action = $3
+		`, e.OwnerID, e.Entity, e.Action)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			hooks = append(hooks, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			continue
+		}
+
+		var version int
+		var updatedMs int64
+		var deletedMs *int64
+		var raw []byte
+		err = tx.QueryRow(ctx, `
+			SELECT version, updated_at_ms, deleted_at_ms, payload_json FROM `+e.Entity+`
+			WHERE owner_id = $1 AND uid = $2
+		`, e.OwnerID, e.UID).Scan(&version, &updatedMs, &deletedMs, &raw)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // Removed by a wipe before dispatch
+		}
+		if err != nil {
+			return err
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			payload = map[string]any{}
+		}
+		item := FlatItem(e.Entity, e.UID.String(), version, updatedMs, deletedMs, payload)
+
+		for _, id := range hooks {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (subscription_id, event_id) DO NOTHING
+			`, id, e.ID, e.Entity+"."+e.Action, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
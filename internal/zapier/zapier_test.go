@@ -0,0 +1,77 @@
+package zapier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/webhook"
+)
+
+func TestFlatItem(t *testing.T) {
+	payload := map[string]any{
+		"uid":       "ignored",
+		"updatedTs": "ignored",
+		"sync":      map[string]any{"version": 3},
+		"title":     "Buy milk",
+	}
+	deleted := int64(1700000000000)
+	item := FlatItem("task", "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c", 3, 1700000000000, &deleted, payload)
+
+	want := map[string]any{
+		"id":        "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c:3",
+		"uid":       "6f1c2b9a-0d3e-4f5a-8b7c-1d2e3f4a5b6c",
+		"entity":    "task",
+		"version":   3,
+		"updatedAt": "2023-11-14T22:13:20Z",
+		"deleted":   true,
+		"title":     "Buy milk",
+	}
+	if len(item) != len(want) {
+		t.Fatalf("FlatItem = %v, want %v", item, want)
+	}
+	for k, v := range want {
+		if item[k] != v {
+			t.Errorf("%s = %v, want %v", k, item[k], v)
+		}
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	entity, action, err := parseEvent("chat_message.created")
+	if err != nil || entity != "chat_message" || action != "created" {
+		t.Errorf("parseEvent = %q, %q, %v", entity, action, err)
+	}
+	for _, event := range []string{"", "task", "task.archived", "user.created", ".created"} {
+		var verr *ValidationError
+		if _, _, err := parseEvent(event); !errors.As(err, &verr) {
+			t.Errorf("parseEvent(%q) = %v, want ValidationError", event, err)
+		}
+	}
+}
+
+func TestSubscribeRejectsPrivateTargets(t *testing.T) {
+	// Validated before any write, like webhook subscriptions
+	s := NewService(nil, webhook.NewService(nil))
+	for _, target := range []string{"https://127.0.0.1/hook", "https://169.254.169.254/latest/meta-data/"} {
+		var verr *ValidationError
+		if _, err := s.Subscribe(context.Background(), "user-a", "task.created", target); !errors.As(err, &verr) {
+			t.Errorf("Subscribe(%q) = %v, want ValidationError", target, err)
+		}
+	}
+}
+
+func TestAuthenticateRejectsForeignKeys(t *testing.T) {
+	// Keys without the prefix are rejected before any lookup
+	s := NewService(nil, nil)
+	if _, _, err := s.Authenticate(context.Background(), "eyJhbGciOiJIUzI1NiJ9.x.y"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Authenticate = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestHashKey(t *testing.T) {
+	h := hashKey(KeyPrefix + "abc")
+	if len(h) != 64 || h == hashKey(KeyPrefix+"abd") {
+		t.Errorf("hashKey = %q", h)
+	}
+}
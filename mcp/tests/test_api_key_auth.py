@@ -0,0 +1,152 @@
+"""
+Unit tests for static API key authentication.
+
+Tests APIKeyVerifier hash comparison and service user claims, and that the
+key itself never reaches the Go API.
+"""
+
+from unittest.mock import AsyncMock, patch
+
+import httpx
+import pytest
+from cryptography.hazmat.primitives import serialization
+from cryptography.hazmat.primitives.asymmetric import rsa
+from fastmcp.server.auth import AccessToken
+
+from toolbridge_mcp.auth import token_exchange
+from toolbridge_mcp.auth.api_key import APIKeyVerifier, hash_api_key
+from toolbridge_mcp.config import Settings, get_settings
+from toolbridge_mcp.utils import requests as req
+from toolbridge_mcp.utils.session import get_session_cache
+
+
+API_KEY = "test-api-key-0123456789"
+
+
+@pytest.fixture
+def verifier():
+    return APIKeyVerifier(
+        api_key_sha256=hash_api_key(API_KEY),
+        service_user_id="service-user",
+        service_user_email="owner@example.com",
+    )
+
+
+class TestAPIKeyVerifier:
+    """Tests for APIKeyVerifier."""
+
+    @pytest.mark.asyncio
+    async def test_valid_key_maps_to_service_user(self, verifier):
+        """Test that the configured key authenticates as the service user."""
+        token = await verifier.verify_token(API_KEY)
+
+        assert token is not None
+        assert token.claims["sub"] == "service-user"
+        assert token.claims["email"] == "owner@example.com"
+
+    @pytest.mark.asyncio
+    async def test_invalid_key_rejected(self, verifier):
+        """Test that any other key is rejected."""
+        assert await verifier.verify_token("wrong-key") is None
+        assert await verifier.verify_token("") is None
+
+    @pytest.mark.asyncio
+    async def test_hash_is_case_insensitive(self):
+        """Test that an uppercase hex digest in config still matches."""
+        verifier = APIKeyVerifier(
+            api_key_sha256=hash_api_key(API_KEY).upper(),
+            service_user_id="service-user",
+        )
+
+        assert await verifier.verify_token(API_KEY) is not None
+
+
+def _signing_key() -> str:
+    key = rsa.generate_private_key(public_exponent=65537, key_size=2048)
+    return key.private_bytes(
+        serialization.Encoding.PEM,
+        serialization.PrivateFormat.PKCS8,
+        serialization.NoEncryption(),
+    ).decode()
+
+
+@pytest.fixture
+def api_key_mode(monkeypatch):
+    """Run as an api_key deployment whose caller authenticated with API_KEY."""
+    settings = get_settings()
+    monkeypatch.setattr(settings, "auth_mode", "api_key")
+    monkeypatch.setattr(settings, "tenant_id", "tenant-a")
+    monkeypatch.setattr(settings, "jwt_signing_key", _signing_key())
+
+    token = AccessToken(
+        token=API_KEY,
+        client_id="toolbridge-api-key",
+        scopes=[],
+        expires_at=None,
+        claims={"sub": "service-user", "email": None, "auth_mode": "api_key"},
+    )
+    req._tenant_cache.clear()
+    req._jwt_cache.clear()
+    get_session_cache().clear()
+    with patch.object(req, "get_access_token", return_value=token), \
+         patch.object(token_exchange, "get_access_token", return_value=token), \
+         patch.object(req, "get_circuit_breaker", return_value=None), \
+         patch.object(req, "resolve_tenant", new=AsyncMock()) as resolve_tenant:
+        yield settings, resolve_tenant
+    req._tenant_cache.clear()
+    req._jwt_cache.clear()
+    get_session_cache().clear()
+
+
+class TestAPIKeyUpstream:
+    """Tests that api_key mode authenticates upstream with its own service JWT."""
+
+    def test_tenant_id_required(self):
+        """Test that api_key mode refuses to start without a configured tenant."""
+        settings = Settings(
+            public_base_url="http://localhost:8001",
+            auth_mode="api_key",
+            api_key_sha256=hash_api_key(API_KEY),
+            service_user_id="service-user",
+            jwt_signing_key="key",
+            tenant_id=None,
+        )
+
+        with pytest.raises(ValueError, match="TOOLBRIDGE_TENANT_ID"):
+            settings.validate_api_key_config()
+
+    @pytest.mark.asyncio
+    async def test_api_key_never_sent_upstream(self, api_key_mode):
+        """Test that no outgoing request carries the inbound API key."""
+        _, resolve_tenant = api_key_mode
+        sent = []
+
+        def handler(request: httpx.Request) -> httpx.Response:
+            sent.append(request)
+            if request.url.path == "/v1/sync/sessions":
+                return httpx.Response(201, json={"id": "session-a", "epoch": 1})
+            return httpx.Response(200, json={"items": []})
+
+        transport = httpx.MockTransport(handler)
+        async with httpx.AsyncClient(transport=transport, base_url="http://api") as client:
+            response = await req.call_get(client, "/v1/notes")
+
+        assert response.status_code == 200
+        assert [r.url.path for r in sent] == ["/v1/sync/sessions", "/v1/notes"]
+        resolve_tenant.assert_not_called()
+        for request in sent:
+            assert API_KEY not in str(request.url)
+            assert all(API_KEY not in value for value in request.headers.values())
+            assert API_KEY.encode() not in request.content
+            assert request.headers["Authorization"] != f"Bearer {API_KEY}"
+
+    @pytest.mark.asyncio
+    async def test_missing_tenant_is_not_resolved_with_api_key(self, api_key_mode, monkeypatch):
+        """Test that without a tenant the key is not sent to /v1/auth/tenant."""
+        settings, resolve_tenant = api_key_mode
+        monkeypatch.setattr(settings, "tenant_id", None)
+
+        with pytest.raises(req.AuthorizationError):
+            await req.ensure_tenant_resolved(None)
+
+        resolve_tenant.assert_not_called()
@@ -0,0 +1,69 @@
+"""
+Unit tests for proactive backend JWT refresh.
+
+Tests that cached JWTs are reused until close to expiry and that concurrent
+callers share a single refresh.
+"""
+
+import asyncio
+import time
+from unittest.mock import AsyncMock, patch
+
+import pytest
+from jose import jwt
+
+from toolbridge_mcp.utils import requests as req
+
+
+def _make_jwt(exp_in_seconds: int) -> str:
+    return jwt.encode({"sub": "user-a", "exp": int(time.time()) + exp_in_seconds}, "secret", algorithm="HS256")
+
+
+@pytest.fixture(autouse=True)
+def clear_caches():
+    req._jwt_cache.clear()
+    req._jwt_refresh_locks.clear()
+    yield
+    req._jwt_cache.clear()
+    req._jwt_refresh_locks.clear()
+
+
+class TestBackendJWTRefresh:
+    """Tests for _get_backend_jwt."""
+
+    @pytest.mark.asyncio
+    async def test_fresh_token_is_reused(self):
+        """Test that a token far from expiry is served from cache."""
+        cached = _make_jwt(3600)
+        req._jwt_cache["user-a"] = cached
+
+        with patch.object(req, "exchange_for_backend_jwt", new=AsyncMock()) as exchange:
+            assert await req._get_backend_jwt(None, "user-a") == cached
+            exchange.assert_not_called()
+
+    @pytest.mark.asyncio
+    async def test_expiring_token_is_refreshed(self):
+        """Test that a token inside the refresh margin is exchanged again."""
+        req._jwt_cache["user-a"] = _make_jwt(10)
+        refreshed = _make_jwt(3600)
+
+        with patch.object(req, "exchange_for_backend_jwt", new=AsyncMock(return_value=refreshed)) as exchange:
+            assert await req._get_backend_jwt(None, "user-a") == refreshed
+            exchange.assert_awaited_once()
+
+        assert req._jwt_cache["user-a"] == refreshed
+
+    @pytest.mark.asyncio
+    async def test_concurrent_refresh_exchanges_once(self):
+        """Test that concurrent callers wait on a single exchange."""
+        refreshed = _make_jwt(3600)
+
+        async def slow_exchange(_client):
+            await asyncio.sleep(0.01)
+            return refreshed
+
+        with patch.object(req, "exchange_for_backend_jwt", new=AsyncMock(side_effect=slow_exchange)) as exchange:
+            results = await asyncio.gather(*(req._get_backend_jwt(None, "user-a") for _ in range(5)))
+
+        assert results == [refreshed] * 5
+        exchange.assert_awaited_once()
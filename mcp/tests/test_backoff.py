@@ -0,0 +1,42 @@
+"""
+Unit tests for 429 retry backoff.
+
+Tests full-jitter bounds and Retry-After handling.
+"""
+
+from unittest.mock import patch
+
+from toolbridge_mcp.utils.backoff import full_jitter_delay, parse_retry_after, retry_delay
+
+
+class TestFullJitterDelay:
+    """Tests for full_jitter_delay."""
+
+    def test_delay_within_exponential_ceiling(self):
+        """Test that delays never exceed base * 2^attempt."""
+        for attempt in range(4):
+            for _ in range(50):
+                delay = full_jitter_delay(attempt, base_seconds=0.5, cap_seconds=100)
+                assert 0 <= delay <= 0.5 * (2 ** attempt)
+
+    def test_delay_respects_cap(self):
+        """Test that the cap bounds large attempts."""
+        with patch("toolbridge_mcp.utils.backoff.random.uniform", side_effect=lambda a, b: b):
+            assert full_jitter_delay(10, base_seconds=0.5, cap_seconds=8) == 8
+
+
+class TestRetryAfter:
+    """Tests for Retry-After handling."""
+
+    def test_parse_retry_after(self):
+        """Test that only non-negative second values are accepted."""
+        assert parse_retry_after("3") == 3.0
+        assert parse_retry_after("-1") is None
+        assert parse_retry_after("Wed, 21 Oct 2015 07:28:00 GMT") is None
+        assert parse_retry_after(None) is None
+
+    def test_retry_after_is_lower_bound(self):
+        """Test that the server's Retry-After wins over a shorter jittered delay."""
+        with patch("toolbridge_mcp.utils.backoff.random.uniform", return_value=0.1):
+            assert retry_delay(0, base_seconds=0.5, cap_seconds=8, retry_after="2") == 2.0
+            assert retry_delay(0, base_seconds=0.5, cap_seconds=8) == 0.1
@@ -0,0 +1,87 @@
+"""
+Unit tests for the upstream circuit breaker.
+
+Tests state transitions between closed, open, and half_open.
+"""
+
+from unittest.mock import patch
+
+import pytest
+
+from toolbridge_mcp.utils.circuit_breaker import CircuitBreaker, UpstreamUnavailableError
+
+
+MONOTONIC = "toolbridge_mcp.utils.circuit_breaker.time.monotonic"
+
+
+def _open_breaker(now: float) -> CircuitBreaker:
+    breaker = CircuitBreaker(failure_threshold=3, open_seconds=30)
+    with patch(MONOTONIC, return_value=now):
+        for _ in range(3):
+            breaker.before_call()
+            breaker.record_failure()
+    return breaker
+
+
+class TestCircuitBreaker:
+    """Tests for CircuitBreaker."""
+
+    def test_opens_after_consecutive_failures(self):
+        """Test that the breaker opens at the failure threshold and fails fast."""
+        breaker = _open_breaker(now=100.0)
+        assert breaker.state == CircuitBreaker.OPEN
+
+        with patch(MONOTONIC, return_value=110.0):
+            with pytest.raises(UpstreamUnavailableError) as exc_info:
+                breaker.before_call()
+        assert exc_info.value.retry_after_seconds == 20.0
+
+    def test_success_resets_failure_count(self):
+        """Test that failures must be consecutive to open the breaker."""
+        breaker = CircuitBreaker(failure_threshold=3, open_seconds=30)
+        breaker.record_failure()
+        breaker.record_failure()
+        breaker.record_success()
+        breaker.record_failure()
+
+        assert breaker.state == CircuitBreaker.CLOSED
+
+    def test_half_open_allows_single_trial(self):
+        """Test that only one trial call is let through after open_seconds."""
+        breaker = _open_breaker(now=100.0)
+
+        with patch(MONOTONIC, return_value=131.0):
+            assert breaker.state == CircuitBreaker.HALF_OPEN
+            breaker.before_call()
+            with pytest.raises(UpstreamUnavailableError):
+                breaker.before_call()
+
+    def test_half_open_success_closes(self):
+        """Test that a successful trial closes the breaker."""
+        breaker = _open_breaker(now=100.0)
+
+        with patch(MONOTONIC, return_value=131.0):
+            breaker.before_call()
+            breaker.record_success()
+            assert breaker.state == CircuitBreaker.CLOSED
+
+    def test_half_open_failure_reopens(self):
+        """Test that a failed trial re-opens the breaker for another interval."""
+        breaker = _open_breaker(now=100.0)
+
+        with patch(MONOTONIC, return_value=131.0):
+            breaker.before_call()
+            breaker.record_failure()
+            assert breaker.state == CircuitBreaker.OPEN
+
+        with patch(MONOTONIC, return_value=150.0):
+            assert breaker.state == CircuitBreaker.OPEN
+
+    def test_release_frees_trial(self):
+        """Test that a trial aborted before reaching upstream can be retried."""
+        breaker = _open_breaker(now=100.0)
+
+        with patch(MONOTONIC, return_value=131.0):
+            breaker.before_call()
+            breaker.release()
+            breaker.before_call()
@@ -0,0 +1,50 @@
+"""
+Unit tests for the per-user activity recorded for sync_status.
+
+Tests that pulls and pushes are recorded and that the map stays bounded.
+"""
+
+import httpx
+import pytest
+from jose import jwt
+
+from toolbridge_mcp.config import get_settings
+from toolbridge_mcp.utils import requests as req
+
+
+def _auth(user_id: str) -> str:
+    return "Bearer " + jwt.encode({"sub": user_id}, "secret", algorithm="HS256")
+
+
+@pytest.fixture(autouse=True)
+def clear_activity():
+    req._last_activity.clear()
+    yield
+    req._last_activity.clear()
+
+
+class TestLastActivity:
+    """Tests for _record_activity and get_last_activity."""
+
+    def test_records_pull_push_and_rate_limit(self):
+        """Test that reads and writes are recorded separately with rate-limit headers."""
+        response = httpx.Response(200, headers={"X-RateLimit-Remaining": "42"})
+        req._record_activity(_auth("user-a"), "GET", response)
+        req._record_activity(_auth("user-a"), "POST", httpx.Response(200))
+
+        activity = req.get_last_activity("user-a")
+        assert {"pull", "push"} <= activity.keys()
+        assert activity["rateLimitRemaining"] == 42
+        assert req.get_last_activity("user-b") == {}
+
+    def test_evicts_least_recently_active_users(self, monkeypatch):
+        """Test that only session_cache_max_users users are kept, dropping the idlest."""
+        monkeypatch.setattr(get_settings(), "session_cache_max_users", 2)
+
+        for user_id in ("user-a", "user-b", "user-a", "user-c"):
+            req._record_activity(_auth(user_id), "GET", httpx.Response(200))
+
+        assert len(req._last_activity) == 2
+        assert req.get_last_activity("user-b") == {}
+        assert "pull" in req.get_last_activity("user-a")
+        assert "pull" in req.get_last_activity("user-c")
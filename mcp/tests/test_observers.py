@@ -0,0 +1,108 @@
+"""
+Unit tests for request instrumentation hooks.
+"""
+
+import pytest
+
+from toolbridge_mcp.utils import observers
+from toolbridge_mcp.utils.observers import RequestObserver, notify, register_observer
+
+
+# Captured before isolated_observers replaces the list for each test
+DEFAULT_OBSERVER_TYPES = [type(o) for o in observers._observers]
+
+
+class RecordingObserver(RequestObserver):
+    def __init__(self):
+        self.calls = []
+
+    def on_response(self, method, path, status_code, duration_seconds):
+        self.calls.append(("response", method, path, status_code))
+
+    def on_retry(self, method, path, attempt, delay_seconds, reason):
+        self.calls.append(("retry", method, path, attempt, reason))
+
+
+class FailingObserver(RequestObserver):
+    def on_response(self, method, path, status_code, duration_seconds):
+        raise RuntimeError("boom")
+
+
+@pytest.fixture(autouse=True)
+def isolated_observers(monkeypatch):
+    monkeypatch.setattr(observers, "_observers", [])
+
+
+def test_notify_dispatches_to_registered_observers():
+    """Test that hooks reach every registered observer with their arguments."""
+    recorder = RecordingObserver()
+    register_observer(recorder)
+
+    notify("on_response", "GET", "/v1/notes", 200, 0.01)
+    notify("on_retry", "POST", "/v1/notes", 1, 0.5, "rate_limited")
+    notify("on_request", "GET", "/v1/notes")  # Not overridden: no-op
+
+    assert recorder.calls == [
+        ("response", "GET", "/v1/notes", 200),
+        ("retry", "POST", "/v1/notes", 1, "rate_limited"),
+    ]
+
+
+def test_failing_observer_does_not_break_others():
+    """Test that an observer raising is isolated from the request and other observers."""
+    recorder = RecordingObserver()
+    register_observer(FailingObserver())
+    register_observer(recorder)
+
+    notify("on_response", "GET", "/v1/tasks", 500, 0.02)
+
+    assert recorder.calls == [("response", "GET", "/v1/tasks", 500)]
+
+
+def test_prometheus_observer_not_registered_on_import():
+    """Test that importing the module registers only the LoggingObserver."""
+    assert DEFAULT_OBSERVER_TYPES == [observers.LoggingObserver]
+
+
+def test_prometheus_observer_records_metrics():
+    """Test that responses, errors and retries are counted by method, status and reason."""
+    prometheus_client = pytest.importorskip("prometheus_client")
+    registry = prometheus_client.CollectorRegistry()
+    register_observer(observers.PrometheusObserver(registry=registry))
+
+    notify("on_response", "GET", "/v1/notes", 200, 0.01)
+    notify("on_response", "GET", "/v1/notes/abc", 200, 0.02)
+    notify("on_error", "POST", "/v1/notes", TimeoutError(), 5.0)
+    notify("on_retry", "POST", "/v1/notes", 1, 0.5, "rate_limited")
+
+    def sample(name, **labels):
+        return registry.get_sample_value(name, labels)
+
+    assert sample("toolbridge_mcp_upstream_requests_total", method="GET", status="200") == 2
+    assert sample("toolbridge_mcp_upstream_request_duration_seconds_count", method="GET") == 2
+    assert sample("toolbridge_mcp_upstream_request_duration_seconds_count", method="POST") == 1
+    assert sample("toolbridge_mcp_upstream_errors_total", method="POST") == 1
+    assert sample("toolbridge_mcp_upstream_retries_total", method="POST", reason="rate_limited") == 1
+
+
+def test_prometheus_observer_shares_metrics_per_registry():
+    """Test that a second observer for the same registry doesn't re-register its metrics."""
+    prometheus_client = pytest.importorskip("prometheus_client")
+    registry = prometheus_client.CollectorRegistry()
+    first = observers.PrometheusObserver(registry=registry)
+    second = observers.PrometheusObserver(registry=registry)
+
+    first.on_response("GET", "/v1/notes", 200, 0.01)
+    second.on_response("GET", "/v1/notes", 200, 0.01)
+
+    assert registry.get_sample_value(
+        "toolbridge_mcp_upstream_requests_total", {"method": "GET", "status": "200"}
+    ) == 2
+
+
+def test_prometheus_observer_requires_prometheus_client(monkeypatch):
+    """Test that constructing the observer without prometheus_client fails clearly."""
+    monkeypatch.setattr(observers, "prometheus_client", None)
+
+    with pytest.raises(RuntimeError):
+        observers.PrometheusObserver()
@@ -0,0 +1,63 @@
+"""
+Unit tests for MCP tool call rate limiting.
+
+Tests token bucket refill and per-key isolation in RateLimiter.
+"""
+
+from unittest.mock import patch
+
+from toolbridge_mcp.utils.rate_limit import RateLimiter, TokenBucket
+
+
+MONOTONIC = "toolbridge_mcp.utils.rate_limit.time.monotonic"
+
+
+class TestTokenBucket:
+    """Tests for TokenBucket."""
+
+    def test_allows_burst_then_rejects(self):
+        """Test that the bucket allows up to capacity calls at once."""
+        with patch(MONOTONIC, return_value=100.0):
+            bucket = TokenBucket(capacity=3, refill_rate=1.0)
+            assert bucket.allow()[0]
+            assert bucket.allow()[0]
+            assert bucket.allow()[0]
+
+            allowed, retry_after = bucket.allow()
+            assert not allowed
+            assert retry_after == 1.0
+
+    def test_refills_over_time(self):
+        """Test that tokens are refilled based on elapsed time."""
+        with patch(MONOTONIC, return_value=100.0):
+            bucket = TokenBucket(capacity=1, refill_rate=0.5)
+            assert bucket.allow()[0]
+            assert not bucket.allow()[0]
+
+        with patch(MONOTONIC, return_value=102.0):
+            assert bucket.allow()[0]
+
+
+class TestRateLimiter:
+    """Tests for RateLimiter."""
+
+    def test_keys_have_independent_buckets(self):
+        """Test that one key exhausting its bucket doesn't affect another."""
+        with patch(MONOTONIC, return_value=100.0):
+            limiter = RateLimiter(max_calls=60, window_seconds=60, burst=2)
+            assert limiter.allow("user-a")[0]
+            assert limiter.allow("user-a")[0]
+            assert not limiter.allow("user-a")[0]
+
+            assert limiter.allow("user-b")[0]
+
+    def test_evicts_least_recently_used_keys(self):
+        """Test that idle keys are dropped once max_keys is exceeded."""
+        limiter = RateLimiter(max_calls=60, window_seconds=60, burst=1, max_keys=2)
+        limiter.allow("user-a")
+        limiter.allow("user-b")
+        limiter.allow("user-a")
+        limiter.allow("user-c")
+
+        assert len(limiter) == 2
+        assert "user-b" not in limiter._buckets
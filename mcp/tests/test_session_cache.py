@@ -0,0 +1,141 @@
+"""
+Unit tests for the per-user sync session cache.
+
+Tests TTL expiry, LRU eviction, and invalidation in SessionCache, and that
+the request helpers replace a cached session the Go API rejects.
+"""
+
+from unittest.mock import AsyncMock, patch
+
+import httpx
+import pytest
+from jose import jwt
+
+from toolbridge_mcp.utils import requests as req
+from toolbridge_mcp.utils.session import SessionCache
+
+
+SESSION_A = {"X-Sync-Session": "session-a", "X-Sync-Epoch": "1"}
+SESSION_B = {"X-Sync-Session": "session-b", "X-Sync-Epoch": "1"}
+SESSION_C = {"X-Sync-Session": "session-c", "X-Sync-Epoch": "2"}
+
+
+class TestSessionCache:
+    """Tests for SessionCache."""
+
+    def test_get_returns_stored_headers(self):
+        """Test that stored session headers are returned for the same user."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+        cache.put("user-a", SESSION_A)
+
+        assert cache.get("user-a") == SESSION_A
+        assert cache.get("user-b") is None
+
+    def test_get_returns_copy(self):
+        """Test that callers cannot mutate cached headers."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+        cache.put("user-a", SESSION_A)
+
+        headers = cache.get("user-a")
+        headers["X-Sync-Session"] = "tampered"
+
+        assert cache.get("user-a") == SESSION_A
+
+    def test_entries_expire_after_ttl(self):
+        """Test that entries older than the TTL are dropped."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+
+        with patch("toolbridge_mcp.utils.session.time.monotonic", return_value=1000.0):
+            cache.put("user-a", SESSION_A)
+
+        with patch("toolbridge_mcp.utils.session.time.monotonic", return_value=1059.0):
+            assert cache.get("user-a") == SESSION_A
+
+        with patch("toolbridge_mcp.utils.session.time.monotonic", return_value=1060.0):
+            assert cache.get("user-a") is None
+
+        assert len(cache) == 0
+
+    def test_evicts_least_recently_used(self):
+        """Test that the least recently used user is evicted when full."""
+        cache = SessionCache(ttl_seconds=60, max_users=2)
+        cache.put("user-a", SESSION_A)
+        cache.put("user-b", SESSION_B)
+
+        # Touch user-a so user-b becomes least recently used
+        cache.get("user-a")
+        cache.put("user-c", SESSION_C)
+
+        assert len(cache) == 2
+        assert cache.get("user-a") == SESSION_A
+        assert cache.get("user-b") is None
+        assert cache.get("user-c") == SESSION_C
+
+    def test_invalidate_removes_entry(self):
+        """Test that invalidate drops only the given user's session."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+        cache.put("user-a", SESSION_A)
+        cache.put("user-b", SESSION_B)
+
+        cache.invalidate("user-a")
+        cache.invalidate("missing-user")  # No error for unknown users
+
+        assert cache.get("user-a") is None
+        assert cache.get("user-b") == SESSION_B
+
+
+class FakeClient:
+    """Stands in for the Go API client, answering GETs from a list of responses."""
+
+    def __init__(self, *responses):
+        self.responses = list(responses)
+        self.sent_headers = []
+
+    async def get(self, path, headers=None, **kwargs):
+        self.sent_headers.append(headers)
+        return self.responses.pop(0)
+
+
+class TestSessionRejection:
+    """Tests that a rejected cached session is replaced and the request retried once."""
+
+    AUTH = "Bearer " + jwt.encode({"sub": "user-a"}, "secret", algorithm="HS256")
+
+    async def _send(self, client, cache):
+        with patch.object(req, "ensure_tenant_resolved", new=AsyncMock()), \
+             patch.object(req, "get_backend_auth_header", new=AsyncMock(return_value=self.AUTH)), \
+             patch.object(req, "get_session_cache", return_value=cache), \
+             patch.object(req, "create_session", new=AsyncMock(return_value=SESSION_C)) as create:
+            response, _ = await req._send_with_session(client, "GET", "/v1/notes", None)
+        return response, create
+
+    @pytest.mark.asyncio
+    async def test_epoch_mismatch_replaces_session(self):
+        """Test that a 409 epoch_mismatch (wipe or restore) invalidates the pinned epoch."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+        cache.put("user-a", SESSION_A)
+        client = FakeClient(
+            httpx.Response(409, json={"error": "epoch_mismatch", "epoch": 2}),
+            httpx.Response(200, json={"items": []}),
+        )
+
+        response, create = await self._send(client, cache)
+
+        assert response.status_code == 200
+        create.assert_awaited_once()
+        assert client.sent_headers[0]["X-Sync-Epoch"] == "1"
+        assert client.sent_headers[1]["X-Sync-Epoch"] == "2"
+        assert cache.get("user-a") == SESSION_C
+
+    @pytest.mark.asyncio
+    async def test_version_conflict_is_not_retried(self):
+        """Test that other 409s are returned as-is and keep the cached session."""
+        cache = SessionCache(ttl_seconds=60, max_users=10)
+        cache.put("user-a", SESSION_A)
+        client = FakeClient(httpx.Response(409, json={"error": "version_mismatch", "version": 3}))
+
+        response, create = await self._send(client, cache)
+
+        assert response.status_code == 409
+        create.assert_not_called()
+        assert cache.get("user-a") == SESSION_A
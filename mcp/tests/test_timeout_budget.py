@@ -0,0 +1,134 @@
+"""
+Unit tests for per-call timeout budgets in the MCP request helpers.
+
+Tests that each send gets only the time left in the budget, including the
+session retry, and that setup steps and retries stop once it is spent.
+"""
+
+import asyncio
+from unittest.mock import AsyncMock, patch
+
+import httpx
+import pytest
+from jose import jwt
+
+from toolbridge_mcp.utils import requests as req
+
+
+AUTH = "Bearer " + jwt.encode({"sub": "user-a"}, "secret", algorithm="HS256")
+SESSION = {"X-Sync-Session": "session-a", "X-Sync-Epoch": "1"}
+
+
+class FakeClock:
+    """Replaces the time module seen by utils.requests; only advances when told to."""
+
+    def __init__(self):
+        self.now = 0.0
+
+    def monotonic(self):
+        return self.now
+
+    def time(self):
+        return 1_700_000_000 + self.now
+
+    def advance(self, seconds):
+        self.now += seconds
+
+
+class FakeClient:
+    """Answers GETs in order, each taking the given (fake) number of seconds."""
+
+    def __init__(self, clock, *responses):
+        self.clock = clock
+        self.responses = list(responses)
+        self.timeouts = []
+
+    async def get(self, path, headers=None, timeout=None, **kwargs):
+        self.timeouts.append(timeout)
+        seconds, status = self.responses.pop(0)
+        self.clock.advance(seconds)
+        return httpx.Response(status, request=httpx.Request("GET", path))
+
+
+@pytest.fixture
+def clock():
+    clock = FakeClock()
+    with patch.object(req, "time", clock), \
+         patch.object(req, "get_circuit_breaker", return_value=None), \
+         patch.object(req, "ensure_tenant_resolved", new=AsyncMock()), \
+         patch.object(req, "get_backend_auth_header", new=AsyncMock(return_value=AUTH)), \
+         patch.object(req, "invalidate_session"):
+        yield clock
+
+
+def _ensure_session(clock, seconds):
+    """ensure_session stand-in that takes seconds of fake time per call."""
+
+    async def ensure_session(client, auth_header):
+        clock.advance(seconds)
+        return SESSION
+
+    return patch.object(req, "ensure_session", new=AsyncMock(side_effect=ensure_session))
+
+
+class TestTimeoutBudget:
+    """Tests for the timeout budget passed to _send."""
+
+    @pytest.mark.asyncio
+    async def test_per_attempt_timeout_shrinks(self, clock):
+        """Test that a 429 retry is sent with the time left, not a fresh timeout."""
+        client = FakeClient(clock, (3.0, 429), (1.0, 200))
+
+        with _ensure_session(clock, 0.0), patch.object(req, "retry_delay", return_value=0.0):
+            response = await req._send(client, "GET", "/v1/notes", timeout=10.0)
+
+        assert response.status_code == 200
+        assert client.timeouts == [pytest.approx(10.0), pytest.approx(7.0)]
+
+    @pytest.mark.asyncio
+    async def test_budget_exhausted_before_retry(self, clock):
+        """Test that no retry is sent once the first attempt used up the budget."""
+        client = FakeClient(clock, (5.0, 429), (1.0, 200))
+
+        with _ensure_session(clock, 0.0), patch.object(req, "retry_delay", return_value=0.0):
+            with pytest.raises(httpx.TimeoutException):
+                await req._send(client, "GET", "/v1/notes", timeout=5.0)
+
+        assert len(client.timeouts) == 1
+
+    @pytest.mark.asyncio
+    async def test_session_retry_stays_within_budget(self, clock):
+        """Test that the 428 retry's timeout accounts for the time spent creating a session."""
+        client = FakeClient(clock, (3.0, 428), (1.0, 200))
+
+        with _ensure_session(clock, 2.0):
+            response = await req._send(client, "GET", "/v1/notes", timeout=10.0)
+
+        assert response.status_code == 200
+        # 2s session + 3s send + 2s new session leaves 3s of the 10s budget
+        assert client.timeouts == [pytest.approx(8.0), pytest.approx(3.0)]
+
+    @pytest.mark.asyncio
+    async def test_session_retry_not_sent_past_budget(self, clock):
+        """Test that the 428 retry is abandoned if creating the new session spent the budget."""
+        client = FakeClient(clock, (3.0, 428), (1.0, 200))
+
+        with _ensure_session(clock, 3.0):
+            with pytest.raises(httpx.TimeoutException):
+                await req._send(client, "GET", "/v1/notes", timeout=6.0)
+
+        assert len(client.timeouts) == 1
+
+    @pytest.mark.asyncio
+    async def test_setup_steps_are_bounded(self, clock):
+        """Test that a hanging session creation is cut off at the deadline."""
+        client = FakeClient(clock, (0.0, 200))
+
+        async def hang(client, auth_header):
+            await asyncio.sleep(10)
+
+        with patch.object(req, "ensure_session", new=AsyncMock(side_effect=hang)):
+            with pytest.raises(httpx.TimeoutException):
+                await req._send(client, "GET", "/v1/notes", timeout=0.05)
+
+        assert client.timeouts == []
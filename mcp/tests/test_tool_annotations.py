@@ -0,0 +1,65 @@
+"""
+Tests that every registered MCP tool declares behavior annotations
+and that data tools publish structured output schemas.
+
+Hosts rely on readOnlyHint/destructiveHint to decide when to confirm calls,
+so a tool registered without annotations is treated as a bug.
+"""
+
+import pytest
+
+from toolbridge_mcp.tools import (
+    chat_messages,
+    chats,
+    comments,
+    notes,
+    notes_ui,
+    sync_status,
+    tasks,
+    tasks_ui,
+)
+from toolbridge_mcp.tools.overrides import collect_tools
+
+TOOL_MODULES = [chat_messages, chats, comments, notes, notes_ui, sync_status, tasks, tasks_ui]
+
+
+def _registered_tools():
+    return collect_tools(TOOL_MODULES).values()
+
+
+@pytest.mark.parametrize("tool", list(_registered_tools()), ids=lambda t: t.name)
+def test_tool_has_annotations(tool):
+    """Test that each tool declares read-only and destructive hints."""
+    assert tool.annotations is not None
+    assert tool.annotations.readOnlyHint is not None
+
+
+@pytest.mark.parametrize("name", ["delete_note", "delete_task", "apply_note_edit", "delete_note_ui"])
+def test_destructive_tools_are_flagged(name):
+    """Test that tools that remove or overwrite content are marked destructive."""
+    tool = next(t for t in _registered_tools() if t.name == name)
+    assert tool.annotations.destructiveHint is True
+
+
+@pytest.mark.parametrize("name", ["list_notes", "get_task", "show_note_ui", "list_tasks_ui"])
+def test_read_tools_are_read_only(name):
+    """Test that list/get/show tools are marked read-only."""
+    tool = next(t for t in _registered_tools() if t.name == name)
+    assert tool.annotations.readOnlyHint is True
+
+
+DATA_TOOL_MODULES = [chat_messages, chats, comments, notes, sync_status, tasks]
+
+
+@pytest.mark.parametrize(
+    "tool", list(collect_tools(DATA_TOOL_MODULES).values()), ids=lambda t: t.name
+)
+def test_data_tool_declares_output_schema(tool):
+    """Test that data tools publish a JSON output schema for structured content.
+
+    FastMCP derives the schema from the pydantic return annotation and returns
+    structuredContent alongside the text block, so hosts can render entities
+    natively. UI tools return content blocks and are intentionally excluded.
+    """
+    assert tool.output_schema is not None
+    assert tool.output_schema.get("type") == "object"
@@ -0,0 +1,73 @@
+"""
+Unit tests for operator tool overrides.
+
+Tests startup validation of disabled tools, aliases, and argument overrides.
+"""
+
+import pytest
+
+from toolbridge_mcp.config import get_settings
+from toolbridge_mcp.tools import notes, tasks
+from toolbridge_mcp.tools.overrides import collect_tools, validate_tool_overrides
+
+
+@pytest.fixture
+def tools():
+    return collect_tools([notes, tasks])
+
+
+@pytest.fixture
+def overrides(monkeypatch):
+    """Set tool override settings for a single test."""
+    settings = get_settings()
+
+    def apply(**values):
+        for key in ("tools_disabled", "tools_aliases", "tools_arg_defaults", "tools_arg_locked"):
+            monkeypatch.setattr(settings, key, values.get(key, type(getattr(settings, key))()))
+
+    return apply
+
+
+class TestValidateToolOverrides:
+    """Tests for validate_tool_overrides."""
+
+    def test_valid_overrides_pass(self, tools, overrides):
+        """Test that overrides referencing real tools and arguments are accepted."""
+        overrides(
+            tools_disabled=["delete_note"],
+            tools_aliases={"list_notes": "search_notes"},
+            tools_arg_defaults={"list_notes": {"limit": 20}},
+            tools_arg_locked={"list_tasks": {"include_deleted": False}},
+        )
+
+        validate_tool_overrides(tools)
+
+    def test_unknown_tool_rejected(self, tools, overrides):
+        """Test that disabling a tool that doesn't exist fails at startup."""
+        overrides(tools_disabled=["drop_database"])
+
+        with pytest.raises(ValueError, match="drop_database"):
+            validate_tool_overrides(tools)
+
+    def test_unknown_argument_rejected(self, tools, overrides):
+        """Test that overriding an argument the tool doesn't take fails."""
+        overrides(tools_arg_defaults={"list_notes": {"page_size": 10}})
+
+        with pytest.raises(ValueError, match="page_size"):
+            validate_tool_overrides(tools)
+
+    def test_alias_clash_rejected(self, tools, overrides):
+        """Test that an alias can't shadow another enabled tool."""
+        overrides(tools_aliases={"list_notes": "list_tasks"})
+
+        with pytest.raises(ValueError, match="list_tasks"):
+            validate_tool_overrides(tools)
+
+    def test_alias_may_reuse_disabled_name(self, tools, overrides):
+        """Test that an alias may take the name of a disabled tool."""
+        overrides(
+            tools_disabled=["list_tasks"],
+            tools_aliases={"list_notes": "list_tasks"},
+        )
+
+        validate_tool_overrides(tools)
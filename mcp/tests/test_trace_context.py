@@ -0,0 +1,51 @@
+"""
+Unit tests for W3C trace context propagation.
+"""
+
+from toolbridge_mcp.utils import trace_context
+from toolbridge_mcp.utils.trace_context import parse_traceparent, trace_headers
+
+
+def test_parse_traceparent_accepts_valid_header():
+    """Test that a version-00 traceparent is continued with its flags and tracestate."""
+    ctx = parse_traceparent(
+        "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "vendor=value"
+    )
+
+    assert ctx is not None
+    assert ctx.trace_id == "4bf92f3577b34da6a3ce929d0e0e4736"
+    assert ctx.flags == "00"
+    assert ctx.tracestate == "vendor=value"
+
+
+def test_parse_traceparent_rejects_invalid_headers():
+    """Test that malformed or all-zero IDs are ignored."""
+    assert parse_traceparent(None) is None
+    assert parse_traceparent("not-a-traceparent") is None
+    assert parse_traceparent("00-" + "0" * 32 + "-00f067aa0ba902b7-01") is None
+    assert parse_traceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-" + "0" * 16 + "-01") is None
+
+
+def test_headers_keep_trace_id_with_new_span_per_request():
+    """Test that requests within one tool call share the trace but not the parent span."""
+    ctx = parse_traceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+    token = trace_context._current.set(ctx)
+    try:
+        first = trace_headers()["traceparent"]
+        second = trace_headers()["traceparent"]
+    finally:
+        trace_context._current.reset(token)
+
+    assert first.startswith("00-4bf92f3577b34da6a3ce929d0e0e4736-")
+    assert second.startswith("00-4bf92f3577b34da6a3ce929d0e0e4736-")
+    assert first != second
+    assert parse_traceparent(first) is not None
+
+
+def test_headers_outside_tool_call_start_new_trace():
+    """Test that requests outside a tool call each get a valid, distinct trace."""
+    first = parse_traceparent(trace_headers()["traceparent"])
+    second = parse_traceparent(trace_headers()["traceparent"])
+
+    assert first is not None and second is not None
+    assert first.trace_id != second.trace_id
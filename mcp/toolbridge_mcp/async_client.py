@@ -3,6 +3,8 @@ Async HTTP client factory for making requests to the Go API.
 
 Provides a context manager pattern for creating httpx clients with the
 TenantDirectTransport, which automatically adds tenant headers to requests.
+Clients share one connection pool (see transports.tenant_direct), tuned via
+the http_* settings.
 """
 
 from contextlib import asynccontextmanager
@@ -57,6 +59,9 @@ async def get_client() -> AsyncGenerator[httpx.AsyncClient, None]:
         async with httpx.AsyncClient(
             transport=transport,
             base_url=settings.go_api_base_url,
-            timeout=httpx.Timeout(30.0),
+            timeout=httpx.Timeout(
+                settings.http_timeout_seconds,
+                connect=settings.http_connect_timeout_seconds,
+            ),
         ) as client:
             yield client
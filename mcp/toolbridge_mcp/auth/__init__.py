@@ -10,6 +10,7 @@ from toolbridge_mcp.auth.token_exchange import (
     issue_backend_jwt,
     extract_user_id_from_backend_jwt,  # DEPRECATED: Use _unsafe_extract_user_id_for_logging
     _unsafe_extract_user_id_for_logging,
+    _unsafe_extract_expiry_for_refresh,
 )
 from toolbridge_mcp.auth.tenant_resolver import (
     TenantResolutionError,
@@ -23,6 +24,7 @@ __all__ = [
     "issue_backend_jwt",
     "extract_user_id_from_backend_jwt",  # DEPRECATED: Use _unsafe_extract_user_id_for_logging
     "_unsafe_extract_user_id_for_logging",
+    "_unsafe_extract_expiry_for_refresh",
     "TenantResolutionError",
     "MultiOrganizationError",
     "resolve_tenant",
@@ -0,0 +1,66 @@
+"""
+Static API key authentication for self-hosted single-user deployments.
+
+In api_key mode the MCP server skips WorkOS AuthKit entirely:
+- Inbound MCP hosts send `Authorization: Bearer <api key>`
+- The key is compared against a SHA-256 hash from config (the plaintext key
+  is never stored on the server)
+- Every authenticated call acts as the configured service user in
+  TOOLBRIDGE_TENANT_ID, and backend JWTs are issued locally with
+  TOOLBRIDGE_JWT_SIGNING_KEY (see token_exchange.py); the API key itself is
+  never sent upstream
+
+Generate a key and its hash with:
+    python -c "import secrets; print(secrets.token_urlsafe(32))"
+    echo -n "<key>" | sha256sum
+"""
+
+import hashlib
+import hmac
+
+from fastmcp.server.auth import AccessToken, TokenVerifier
+from loguru import logger
+
+from toolbridge_mcp.config import settings
+
+
+def hash_api_key(api_key: str) -> str:
+    """Return the hex-encoded SHA-256 hash of an API key."""
+    return hashlib.sha256(api_key.encode("utf-8")).hexdigest()
+
+
+class APIKeyVerifier(TokenVerifier):
+    """Token verifier that accepts a single static API key."""
+
+    def __init__(self, api_key_sha256: str, service_user_id: str, service_user_email: str | None = None):
+        super().__init__()
+        self.api_key_sha256 = api_key_sha256.strip().lower()
+        self.service_user_id = service_user_id
+        self.service_user_email = service_user_email
+
+    async def verify_token(self, token: str) -> AccessToken | None:
+        """Validate the bearer token against the configured key hash."""
+        if not hmac.compare_digest(hash_api_key(token), self.api_key_sha256):
+            logger.debug("Rejected MCP request with invalid API key")
+            return None
+
+        return AccessToken(
+            token=token,
+            client_id="toolbridge-api-key",
+            scopes=[],
+            expires_at=None,
+            claims={
+                "sub": self.service_user_id,
+                "email": self.service_user_email,
+                "auth_mode": "api_key",
+            },
+        )
+
+
+def build_api_key_verifier() -> APIKeyVerifier:
+    """Build the API key verifier from settings (call validate_api_key_config first)."""
+    return APIKeyVerifier(
+        api_key_sha256=settings.api_key_sha256,
+        service_user_id=settings.service_user_id,
+        service_user_email=settings.service_user_email,
+    )
@@ -56,6 +56,18 @@ async def exchange_for_backend_jwt(
     email = token.claims.get("email")
     tenant_id = token.claims.get("tenant_id")  # Custom claim if configured
 
+    # API key mode: the inbound key is not a WorkOS token, so the backend exchange
+    # endpoint can't validate it. Issue the service user's JWT locally instead.
+    if settings.auth_mode == "api_key":
+        return issue_backend_jwt(
+            user_id=user_id,
+            email=email,
+            tenant_id=tenant_id,
+            scopes=token.scopes or [],
+            raw_token=token.token,
+            exchanged_from="mcp_api_key",
+        )
+
     logger.debug(f"Exchanging WorkOS AuthKit token for user: {user_id}, tenant: {tenant_id or 'default'}")
     
     # OPTION 1: Backend token exchange endpoint (RECOMMENDED)
@@ -117,6 +129,7 @@ def issue_backend_jwt(
     tenant_id: Optional[str],
     scopes: list[str],
     raw_token: str,
+    exchanged_from: str = "mcp_oauth",
 ) -> str:
     """
     Issue a JWT for the backend API (Option 2).
@@ -130,6 +143,7 @@ def issue_backend_jwt(
         tenant_id: Tenant ID from custom claim (optional)
         scopes: OAuth scopes from MCP token
         raw_token: Original MCP token (for debugging/audit)
+        exchanged_from: Inbound credential type recorded in the token metadata
 
     Returns:
         Signed JWT for backend API
@@ -157,7 +171,7 @@ def issue_backend_jwt(
 
         # Metadata (for debugging)
         "token_type": "backend",
-        "exchanged_from": "mcp_oauth",
+        "exchanged_from": exchanged_from,
     }
     
     # Sign JWT with RS256
@@ -221,6 +235,29 @@ def _unsafe_extract_user_id_for_logging(backend_jwt: str) -> str:
         return "unknown"
 
 
+def _unsafe_extract_expiry_for_refresh(backend_jwt: str) -> Optional[int]:
+    """
+    WARNING: Cache-scheduling helper - DO NOT use for authorization decisions.
+
+    Reads the exp claim from a backend JWT WITHOUT signature validation, so the
+    MCP server can refresh its own cached token shortly before it expires. The
+    Go API still validates every token it receives.
+
+    Args:
+        backend_jwt: Backend JWT token string (trusted source only)
+
+    Returns:
+        Expiry as a Unix timestamp, or None if absent or undecodable
+    """
+    try:
+        claims = jwt.get_unverified_claims(backend_jwt)
+        exp = claims.get("exp")
+        return int(exp) if exp is not None else None
+    except Exception as e:
+        logger.warning(f"Failed to read backend JWT expiry: {e}")
+        return None
+
+
 # Backwards compatibility alias - prefer using the explicit unsafe name
 # TODO: Remove this alias after updating all call sites
 def extract_user_id_from_backend_jwt(backend_jwt: str) -> str:
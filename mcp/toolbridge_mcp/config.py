@@ -4,6 +4,8 @@ Configuration management for ToolBridge MCP service.
 Loads settings from environment variables with TOOLBRIDGE_ prefix.
 """
 
+from typing import Any
+
 from pydantic_settings import BaseSettings, SettingsConfigDict
 
 
@@ -22,10 +24,40 @@ class Settings(BaseSettings):
     # Go API connection
     go_api_base_url: str = "http://localhost:8080"
 
+    # Go API HTTP transport (shared connection pool, see transports/tenant_direct.py)
+    http_timeout_seconds: float = 30.0
+    http_connect_timeout_seconds: float = 5.0
+    http_max_connections: int = 100
+    http_max_keepalive_connections: int = 20
+    http_keepalive_expiry_seconds: float = 30.0
+    # Retries for failed TCP connects only (never for requests that reached the API)
+    http_connect_retries: int = 1
+    # HTTP/2 requires the h2 package (pip install httpx[http2])
+    http2_enabled: bool = False
+    # Outbound proxy URL (e.g., "http://proxy.internal:3128")
+    http_proxy: str | None = None
+    # TLS verification; http_ca_bundle is a path to a custom CA bundle
+    http_verify_tls: bool = True
+    http_ca_bundle: str | None = None
+
+    # Inbound authentication mode
+    # - "authkit" (default): Per-user OAuth via WorkOS AuthKit
+    # - "api_key": Static API key for self-hosted single-user deployments; all calls
+    #   act as service_user_id in tenant_id and backend JWTs are signed with jwt_signing_key
+    auth_mode: str = "authkit"
+
     # WorkOS AuthKit Configuration
     # These configure FastMCP's AuthKitProvider for per-user authentication
     # Users authenticate via browser through WorkOS AuthKit OAuth 2.1 + PKCE flow
-    authkit_domain: str  # WorkOS AuthKit domain (e.g., "toolbridge.authkit.app")
+    # Required when auth_mode is "authkit"
+    authkit_domain: str = ""  # WorkOS AuthKit domain (e.g., "toolbridge.authkit.app")
+
+    # API Key Configuration (auth_mode="api_key")
+    # SHA-256 hex digest of the API key; the plaintext key is never configured here
+    api_key_sha256: str | None = None
+    # Backend user the MCP server acts as (JWT sub claim sent to the Go API)
+    service_user_id: str | None = None
+    service_user_email: str | None = None
 
     # Public MCP URL (used in OAuth metadata and resource identification)
     public_base_url: str  # e.g., "https://toolbridge-mcp-staging.fly.dev"
@@ -38,12 +70,58 @@ class Settings(BaseSettings):
     # Private key for signing backend JWTs if not using backend /token-exchange endpoint
     jwt_signing_key: str | None = None
 
+    # Cached backend JWTs are re-exchanged this many seconds before they expire,
+    # so tool calls never hit a 401 on token rollover
+    backend_jwt_refresh_margin_seconds: int = 60
+
     # UI Configuration
     # HTML MIME type for UI resources:
     # - "text/html" (default): Works with all MCP-UI hosts (ToolBridge, Nanobot, Goose)
     # - "text/html+skybridge": Required for ChatGPT Apps SDK
     ui_html_mime_type: str = "text/html"
 
+    # Per-user sync session cache
+    # Sessions are reused across tool calls for the same user. TTL must stay below
+    # the Go API session TTL (30 minutes) so cached sessions never outlive the server's.
+    session_cache_ttl_seconds: int = 1500
+    # Maximum number of users with a cached session (least recently used are evicted)
+    session_cache_max_users: int = 1000
+
+    # Tool call rate limiting (token buckets, see utils/rate_limit.py)
+    # Protects the Go API from runaway model loops. Set a max_calls to 0 to disable that bucket.
+    tool_rate_limit_enabled: bool = True
+    tool_rate_limit_window_seconds: int = 60
+    # Per user (all MCP sessions for the same WorkOS user combined)
+    tool_rate_limit_user_max_calls: int = 120
+    tool_rate_limit_user_burst: int = 30
+    # Per MCP session (a single host connection)
+    tool_rate_limit_session_max_calls: int = 60
+    tool_rate_limit_session_burst: int = 20
+
+    # Upstream circuit breaker (see utils/circuit_breaker.py)
+    # Opens after this many consecutive 5xx/timeouts from the Go API (0 disables)
+    circuit_breaker_failure_threshold: int = 5
+    # How long to fail fast before letting a trial call through
+    circuit_breaker_open_seconds: float = 30.0
+
+    # 429 retries against the Go API (full-jitter exponential backoff, see utils/backoff.py)
+    retry_max_attempts: int = 3
+    retry_base_seconds: float = 0.5
+    retry_cap_seconds: float = 8.0
+    # Total time a single call may spend waiting between retries
+    retry_budget_seconds: float = 15.0
+
+    # Prometheus metrics for Go API calls (see utils/observers.py); requires the
+    # "metrics" extra. Served without authentication at GET /metrics.
+    metrics_enabled: bool = False
+
+    # Tool overrides (JSON-encoded, see tools/overrides.py)
+    # Disable tools, rename them, or change/lock argument defaults; validated at startup
+    tools_disabled: list[str] = []
+    tools_aliases: dict[str, str] = {}
+    tools_arg_defaults: dict[str, dict[str, Any]] = {}
+    tools_arg_locked: dict[str, dict[str, Any]] = {}
+
     # Logging
     log_level: str = "INFO"
 
@@ -72,6 +150,42 @@ class Settings(BaseSettings):
         case_sensitive=False,
     )
 
+    def validate_auth_config(self) -> None:
+        """Validate the configured inbound authentication mode at startup."""
+        if self.auth_mode == "authkit":
+            self.validate_authkit_config()
+        elif self.auth_mode == "api_key":
+            self.validate_api_key_config()
+        else:
+            raise ValueError(
+                f"Unknown TOOLBRIDGE_AUTH_MODE {self.auth_mode!r} (expected 'authkit' or 'api_key')"
+            )
+
+    def validate_api_key_config(self) -> None:
+        """Validate static API key configuration at startup."""
+        if not self.api_key_sha256 or len(self.api_key_sha256.strip()) != 64:
+            raise ValueError(
+                "TOOLBRIDGE_API_KEY_SHA256 must be the 64-character SHA-256 hex digest of the API key "
+                "when TOOLBRIDGE_AUTH_MODE=api_key."
+            )
+        if not self.service_user_id:
+            raise ValueError(
+                "TOOLBRIDGE_SERVICE_USER_ID is required when TOOLBRIDGE_AUTH_MODE=api_key. "
+                "Set this to the backend user the MCP server should act as."
+            )
+        if not self.jwt_signing_key:
+            raise ValueError(
+                "TOOLBRIDGE_JWT_SIGNING_KEY is required when TOOLBRIDGE_AUTH_MODE=api_key "
+                "(backend JWTs are issued locally for the service user)."
+            )
+        if not self.tenant_id:
+            # Tenant resolution authenticates with the caller's token, which here is the
+            # API key itself; it must never be forwarded to the Go API
+            raise ValueError(
+                "TOOLBRIDGE_TENANT_ID is required when TOOLBRIDGE_AUTH_MODE=api_key "
+                "(the service user's tenant is not resolved dynamically)."
+            )
+
     def validate_authkit_config(self) -> None:
         """Validate WorkOS AuthKit provider configuration at startup."""
         if not self.authkit_domain:
@@ -2,7 +2,8 @@
 MCP server instance with OAuth 2.1 authentication.
 
 This module creates the MCP server instance configured with AuthKitProvider
-for per-user authentication via browser-based OAuth 2.1 + PKCE flow.
+for per-user authentication via browser-based OAuth 2.1 + PKCE flow, or with
+a static API key verifier when TOOLBRIDGE_AUTH_MODE=api_key.
 """
 
 from fastmcp import FastMCP
@@ -11,24 +12,36 @@ from loguru import logger
 
 from toolbridge_mcp.config import settings
 
-# Validate WorkOS AuthKit configuration at module load
-settings.validate_authkit_config()
-
-# Create WorkOS AuthKit provider for per-user authentication
-# Users authenticate via claude.ai web UI → browser → WorkOS AuthKit login
-# The MCP server acts as a protected resource that validates WorkOS tokens
-auth_provider = AuthKitProvider(
-    authkit_domain=settings.authkit_domain,
-    # MCP's public URL (used in OAuth metadata) - must be root URL without /mcp path
-    # The AuthKitProvider will automatically append the MCP path to generate the
-    # resource metadata URL at /.well-known/oauth-protected-resource/mcp
-    base_url=settings.public_base_url,
-)
+# Validate inbound auth configuration at module load
+settings.validate_auth_config()
 
-logger.info(
-    f"✓ AuthKitProvider configured: domain={settings.authkit_domain}, "
-    f"backend_audience={settings.backend_api_audience}"
-)
+if settings.auth_mode == "api_key":
+    # Static API key for self-hosted single-user deployments
+    # Every call acts as the configured service user (see auth/api_key.py)
+    from toolbridge_mcp.auth.api_key import build_api_key_verifier
+
+    auth_provider = build_api_key_verifier()
+
+    logger.info(
+        f"✓ API key authentication configured: service_user={settings.service_user_id}, "
+        f"backend_audience={settings.backend_api_audience}"
+    )
+else:
+    # Create WorkOS AuthKit provider for per-user authentication
+    # Users authenticate via claude.ai web UI → browser → WorkOS AuthKit login
+    # The MCP server acts as a protected resource that validates WorkOS tokens
+    auth_provider = AuthKitProvider(
+        authkit_domain=settings.authkit_domain,
+        # MCP's public URL (used in OAuth metadata) - must be root URL without /mcp path
+        # The AuthKitProvider will automatically append the MCP path to generate the
+        # resource metadata URL at /.well-known/oauth-protected-resource/mcp
+        base_url=settings.public_base_url,
+    )
+
+    logger.info(
+        f"✓ AuthKitProvider configured: domain={settings.authkit_domain}, "
+        f"backend_audience={settings.backend_api_audience}"
+    )
 
 # Create MCP server instance with OAuth authentication
 # Note: server.py will build an ASGI app via mcp.http_app() and run it with uvicorn,
@@ -38,3 +51,20 @@ mcp = FastMCP(
     name="ToolBridge",
     auth=auth_provider,
 )
+
+# One W3C trace per tool call, forwarded to the Go API (see utils/trace_context.py)
+from toolbridge_mcp.utils.trace_context import TraceContextMiddleware  # noqa: E402
+
+mcp.add_middleware(TraceContextMiddleware())
+
+# Rate limit tool calls per user and per MCP session (see utils/rate_limit.py)
+from toolbridge_mcp.utils.rate_limit import build_rate_limit_middleware  # noqa: E402
+
+rate_limit_middleware = build_rate_limit_middleware()
+if rate_limit_middleware is not None:
+    mcp.add_middleware(rate_limit_middleware)
+    logger.info(
+        f"✓ Tool rate limiting enabled: "
+        f"user={settings.tool_rate_limit_user_max_calls}/{settings.tool_rate_limit_window_seconds}s, "
+        f"session={settings.tool_rate_limit_session_max_calls}/{settings.tool_rate_limit_window_seconds}s"
+    )
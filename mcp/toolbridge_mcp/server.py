@@ -38,8 +38,11 @@ logger.add(
     filter=OAuthTokenFilter(),
 )
 
-logger.info("🚀 ToolBridge MCP Server - WorkOS AuthKit Mode")
-logger.info(f"✓ WorkOS AuthKit domain: {settings.authkit_domain}")
+if settings.auth_mode == "api_key":
+    logger.info("🚀 ToolBridge MCP Server - API Key Mode (single user)")
+else:
+    logger.info("🚀 ToolBridge MCP Server - WorkOS AuthKit Mode")
+    logger.info(f"✓ WorkOS AuthKit domain: {settings.authkit_domain}")
 logger.info(f"✓ Backend API audience: {settings.backend_api_audience}")
 logger.info(f"✓ MCP public URL: {settings.public_base_url}")
 logger.info(
@@ -72,13 +75,42 @@ from toolbridge_mcp.tools import tasks  # noqa: F401, E402
 from toolbridge_mcp.tools import comments  # noqa: F401, E402
 from toolbridge_mcp.tools import chats  # noqa: F401, E402
 from toolbridge_mcp.tools import chat_messages  # noqa: F401, E402
+from toolbridge_mcp.tools import sync_status  # noqa: F401, E402
 
 # Import MCP-UI enabled tools (return both text and UIResource)
 from toolbridge_mcp.tools import notes_ui  # noqa: F401, E402
 from toolbridge_mcp.tools import tasks_ui  # noqa: F401, E402
 
+# Apply operator tool overrides (disable/rename/argument defaults) from config
+from toolbridge_mcp.tools.overrides import apply_tool_overrides, collect_tools  # noqa: E402
+
+apply_tool_overrides(
+    mcp,
+    collect_tools([notes, tasks, comments, chats, chat_messages, sync_status, notes_ui, tasks_ui]),
+)
+
 logger.info("✓ ToolBridge MCP server initialized with 47 tools (40 data + 7 UI)")
 
+# Prometheus metrics for Go API calls, scraped from GET /metrics
+if settings.metrics_enabled:
+    from starlette.requests import Request  # noqa: E402
+    from starlette.responses import Response  # noqa: E402
+
+    from toolbridge_mcp.utils.observers import PrometheusObserver, register_observer  # noqa: E402
+
+    register_observer(PrometheusObserver())  # Fails fast without prometheus_client
+
+    import prometheus_client  # noqa: E402
+
+    @mcp.custom_route("/metrics", methods=["GET"])
+    async def metrics(request: Request) -> Response:
+        return Response(
+            prometheus_client.generate_latest(),
+            media_type=prometheus_client.CONTENT_TYPE_LATEST,
+        )
+
+    logger.info("✓ Prometheus metrics enabled at /metrics")
+
 # Note: health_check tool is provided by FastMCP by default
 # No need to register a custom one to avoid "Tool already exists" warnings
 
@@ -128,6 +160,12 @@ if __name__ == "__main__":
                 # Non-POSIX platforms (not relevant for Fly.io)
                 pass
 
-        await server.serve()
+        try:
+            await server.serve()
+        finally:
+            # Release pooled Go API connections
+            from toolbridge_mcp.transports.tenant_direct import close_shared_transport
+
+            await close_shared_transport()
 
     asyncio.run(serve())
@@ -0,0 +1,38 @@
+"""
+Shared MCP tool annotations.
+
+Hosts use these hints to decide when to ask the user for confirmation
+(e.g. prompt before destructive calls, auto-run read-only ones). Hints are
+advisory only; the Go API still enforces auth and ownership on every call.
+
+- READ_ONLY: list/get/show tools - never modify data
+- CREATE: adds a new entity - not idempotent (each call creates another)
+- UPDATE: overwrites fields on an existing entity - repeat calls are no-ops
+- STATE_CHANGE: reversible transitions (archive, pin, complete)
+- DESTRUCTIVE: deletes an entity or applies an edit over existing content
+- EDIT_SESSION: only touches in-memory note edit sessions, not synced data
+"""
+
+from mcp.types import ToolAnnotations
+
+READ_ONLY = ToolAnnotations(readOnlyHint=True, idempotentHint=True, openWorldHint=False)
+
+CREATE = ToolAnnotations(
+    readOnlyHint=False, destructiveHint=False, idempotentHint=False, openWorldHint=False
+)
+
+UPDATE = ToolAnnotations(
+    readOnlyHint=False, destructiveHint=True, idempotentHint=True, openWorldHint=False
+)
+
+STATE_CHANGE = ToolAnnotations(
+    readOnlyHint=False, destructiveHint=False, idempotentHint=True, openWorldHint=False
+)
+
+DESTRUCTIVE = ToolAnnotations(
+    readOnlyHint=False, destructiveHint=True, idempotentHint=True, openWorldHint=False
+)
+
+EDIT_SESSION = ToolAnnotations(
+    readOnlyHint=False, destructiveHint=False, idempotentHint=False, openWorldHint=False
+)
@@ -14,6 +14,7 @@ from loguru import logger
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import CREATE, DESTRUCTIVE, READ_ONLY, STATE_CHANGE, UPDATE
 
 
 # Pydantic models matching Go API responses
@@ -45,7 +46,7 @@ class ChatMessagesListResponse(BaseModel):
 # MCP Tool Definitions
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_chat_messages(
     limit: Annotated[
         int, Field(ge=1, le=1000, description="Maximum number of messages to return")
@@ -95,7 +96,7 @@ async def list_chat_messages(
         return ChatMessagesListResponse(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def get_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
     include_deleted: Annotated[
@@ -134,7 +135,7 @@ async def get_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=CREATE)
 async def create_chat_message(
     chat_uid: Annotated[str, Field(description="UID of the parent chat")],
     content: Annotated[str, Field(description="Message content")],
@@ -229,7 +230,7 @@ async def create_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def update_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
     content: Annotated[str, Field(description="Message content")],
@@ -292,7 +293,7 @@ async def update_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def patch_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
     updates: Annotated[Union[Dict[str, Any], str], Field(description="Fields to update (partial)")],
@@ -335,7 +336,7 @@ async def patch_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
 ) -> ChatMessage:
@@ -362,7 +363,7 @@ async def delete_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
 ) -> ChatMessage:
@@ -389,7 +390,7 @@ async def archive_chat_message(
         return ChatMessage(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_chat_message(
     uid: Annotated[str, Field(description="Unique identifier of the chat message")],
     action: Annotated[str, Field(description="Action to perform (mark_read, mark_delivered)")],
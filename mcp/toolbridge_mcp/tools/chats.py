@@ -14,6 +14,7 @@ from loguru import logger
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import CREATE, DESTRUCTIVE, READ_ONLY, STATE_CHANGE, UPDATE
 
 
 # Pydantic models matching Go API responses
@@ -45,7 +46,7 @@ class ChatsListResponse(BaseModel):
 # MCP Tool Definitions
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_chats(
     limit: Annotated[
         int, Field(ge=1, le=1000, description="Maximum number of chats to return")
@@ -95,7 +96,7 @@ async def list_chats(
         return ChatsListResponse(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def get_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
     include_deleted: Annotated[bool, Field(description="Allow retrieving deleted chats")] = False,
@@ -132,7 +133,7 @@ async def get_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=CREATE)
 async def create_chat(
     title: Annotated[str, Field(description="Chat title")],
     description: Annotated[Optional[str], Field(description="Chat description")] = None,
@@ -231,7 +232,7 @@ async def create_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def update_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
     title: Annotated[str, Field(description="Chat title")],
@@ -297,7 +298,7 @@ async def update_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def patch_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
     updates: Annotated[Union[Dict[str, Any], str], Field(description="Fields to update (partial)")],
@@ -340,7 +341,7 @@ async def patch_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
 ) -> Chat:
@@ -367,7 +368,7 @@ async def delete_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
 ) -> Chat:
@@ -394,7 +395,7 @@ async def archive_chat(
         return Chat(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_chat(
     uid: Annotated[str, Field(description="Unique identifier of the chat")],
     action: Annotated[str, Field(description="Action to perform (resolve, reopen)")],
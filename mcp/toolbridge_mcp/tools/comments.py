@@ -14,6 +14,7 @@ from loguru import logger
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import CREATE, DESTRUCTIVE, READ_ONLY, STATE_CHANGE, UPDATE
 
 
 # Pydantic models matching Go API responses
@@ -45,7 +46,7 @@ class CommentsListResponse(BaseModel):
 # MCP Tool Definitions
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_comments(
     limit: Annotated[
         int, Field(ge=1, le=1000, description="Maximum number of comments to return")
@@ -95,7 +96,7 @@ async def list_comments(
         return CommentsListResponse(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def get_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
     include_deleted: Annotated[
@@ -134,7 +135,7 @@ async def get_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=CREATE)
 async def create_comment(
     content: Annotated[str, Field(description="Comment content")],
     parent_type: Annotated[str, Field(description="Type of parent entity (note, task, chat)")],
@@ -228,7 +229,7 @@ async def create_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def update_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
     content: Annotated[str, Field(description="Comment content")],
@@ -289,7 +290,7 @@ async def update_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def patch_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
     updates: Annotated[Union[Dict[str, Any], str], Field(description="Fields to update (partial)")],
@@ -332,7 +333,7 @@ async def patch_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
 ) -> Comment:
@@ -359,7 +360,7 @@ async def delete_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
 ) -> Comment:
@@ -386,7 +387,7 @@ async def archive_comment(
         return Comment(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_comment(
     uid: Annotated[str, Field(description="Unique identifier of the comment")],
     action: Annotated[str, Field(description="Action to perform (resolve, reopen)")],
@@ -15,6 +15,7 @@ from loguru import logger
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import CREATE, DESTRUCTIVE, READ_ONLY, STATE_CHANGE, UPDATE
 
 
 # Pydantic models matching Go API responses
@@ -46,7 +47,7 @@ class NotesListResponse(BaseModel):
 # MCP Tool Definitions
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_notes(
     limit: Annotated[
         int, Field(ge=1, le=1000, description="Maximum number of notes to return")
@@ -109,7 +110,7 @@ async def list_notes(
         return NotesListResponse(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def get_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
     include_deleted: Annotated[bool, Field(description="Allow retrieving deleted notes")] = False,
@@ -146,7 +147,7 @@ async def get_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=CREATE)
 async def create_note(
     title: Annotated[str, Field(description="Note title")],
     content: Annotated[str, Field(description="Note content (markdown supported)")],
@@ -230,7 +231,7 @@ async def create_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def update_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
     title: Annotated[str, Field(description="Note title")],
@@ -292,7 +293,7 @@ async def update_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def patch_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
     updates: Annotated[
@@ -338,7 +339,7 @@ async def patch_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
 ) -> Note:
@@ -365,7 +366,7 @@ async def delete_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
 ) -> Note:
@@ -392,7 +393,7 @@ async def archive_note(
         return Note(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_note(
     uid: Annotated[str, Field(description="Unique identifier of the note")],
     action: Annotated[str, Field(description="Action to perform (pin, unpin, archive, unarchive)")],
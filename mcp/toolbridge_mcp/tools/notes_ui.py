@@ -12,6 +12,7 @@ from loguru import logger
 from mcp.types import TextContent, EmbeddedResource
 
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import DESTRUCTIVE, EDIT_SESSION, READ_ONLY
 from toolbridge_mcp.tools.notes import (
     list_notes as list_notes_tool,
     get_note as get_note_tool,
@@ -96,7 +97,7 @@ def _truncate_unchanged_for_display(
     return result
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_notes_ui(
     limit: Annotated[int, Field(ge=1, le=100, description="Max notes to display")] = 20,
     include_deleted: Annotated[bool, Field(description="Include deleted notes")] = False,
@@ -184,7 +185,7 @@ async def list_notes_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def show_note_ui(
     uid: Annotated[str, Field(description="UID of the note to display")],
     include_deleted: Annotated[bool, Field(description="Allow deleted notes")] = False,
@@ -255,7 +256,7 @@ async def show_note_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_note_ui(
     uid: Annotated[str, Field(description="UID of the note to delete")],
     limit: Annotated[int, Field(ge=1, le=100, description="Max notes to display in refreshed list")] = 20,
@@ -328,7 +329,7 @@ async def delete_note_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=EDIT_SESSION)
 async def edit_note_ui(
     uid: Annotated[str, Field(description="UID of the note to edit")],
     new_content: Annotated[
@@ -461,7 +462,7 @@ async def edit_note_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def apply_note_edit(
     edit_id: Annotated[str, Field(description="ID of the pending note edit session")],
     ui_format: Annotated[
@@ -655,7 +656,7 @@ async def apply_note_edit(
         )
 
 
-@mcp.tool()
+@mcp.tool(annotations=EDIT_SESSION)
 async def discard_note_edit(
     edit_id: Annotated[str, Field(description="ID of the pending note edit session")],
     ui_format: Annotated[
@@ -715,7 +716,7 @@ async def discard_note_edit(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=EDIT_SESSION)
 async def accept_note_edit_hunk(
     edit_id: Annotated[str, Field(description="ID of the pending note edit session")],
     hunk_id: Annotated[str, Field(description="ID of the diff hunk to accept (e.g., 'h1', 'h2')")],
@@ -813,7 +814,7 @@ async def accept_note_edit_hunk(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=EDIT_SESSION)
 async def reject_note_edit_hunk(
     edit_id: Annotated[str, Field(description="ID of the pending note edit session")],
     hunk_id: Annotated[str, Field(description="ID of the diff hunk to reject (e.g., 'h1', 'h2')")],
@@ -911,7 +912,7 @@ async def reject_note_edit_hunk(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=EDIT_SESSION)
 async def revise_note_edit_hunk(
     edit_id: Annotated[str, Field(description="ID of the pending note edit session")],
     hunk_id: Annotated[str, Field(description="ID of the diff hunk to revise (e.g., 'h1', 'h2')")],
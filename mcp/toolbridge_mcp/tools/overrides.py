@@ -0,0 +1,105 @@
+"""
+Operator overrides for the registered MCP tool set.
+
+Lets deployments trim or reshape tools without code changes:
+- tools_disabled: remove tools entirely (e.g. every destructive tool)
+- tools_aliases: expose a tool under a different name
+- tools_arg_defaults: change an argument's default (callers can still override)
+- tools_arg_locked: pin an argument to a value and hide it from callers
+
+Settings are JSON-encoded environment variables, e.g.
+    TOOLBRIDGE_TOOLS_DISABLED='["delete_note", "delete_task"]'
+    TOOLBRIDGE_TOOLS_ALIASES='{"list_notes": "search_notes"}'
+    TOOLBRIDGE_TOOLS_ARG_DEFAULTS='{"list_notes": {"limit": 20}}'
+    TOOLBRIDGE_TOOLS_ARG_LOCKED='{"list_tasks": {"include_deleted": false}}'
+
+Overrides are validated when the server starts: unknown tool or argument names
+raise ValueError instead of silently doing nothing.
+"""
+
+from types import ModuleType
+from typing import Any, Dict, Iterable
+
+from fastmcp import FastMCP
+from fastmcp.tools import FunctionTool, Tool
+from fastmcp.tools.tool_transform import ArgTransform
+from loguru import logger
+
+from toolbridge_mcp.config import settings
+
+
+def collect_tools(modules: Iterable[ModuleType]) -> Dict[str, FunctionTool]:
+    """Collect tools registered by @mcp.tool() in the given modules, keyed by name."""
+    tools: Dict[str, FunctionTool] = {}
+    for module in modules:
+        for value in vars(module).values():
+            # Skip tools re-imported from another module (e.g. notes_ui imports list_notes)
+            if isinstance(value, FunctionTool) and value.fn.__module__ == module.__name__:
+                tools[value.name] = value
+    return tools
+
+
+def validate_tool_overrides(tools: Dict[str, FunctionTool]) -> None:
+    """Raise ValueError if any override refers to an unknown tool or argument."""
+    referenced = (
+        set(settings.tools_disabled)
+        | set(settings.tools_aliases)
+        | set(settings.tools_arg_defaults)
+        | set(settings.tools_arg_locked)
+    )
+    unknown = sorted(referenced - set(tools))
+    if unknown:
+        raise ValueError(f"Tool overrides reference unknown tools: {', '.join(unknown)}")
+
+    aliases = list(settings.tools_aliases.values())
+    clashes = sorted(
+        {alias for alias in aliases if aliases.count(alias) > 1}
+        | {alias for alias in aliases if alias in tools and alias not in settings.tools_disabled}
+    )
+    if clashes:
+        raise ValueError(f"Tool aliases clash with other tool names: {', '.join(clashes)}")
+
+    for option, overrides in (
+        ("TOOLBRIDGE_TOOLS_ARG_DEFAULTS", settings.tools_arg_defaults),
+        ("TOOLBRIDGE_TOOLS_ARG_LOCKED", settings.tools_arg_locked),
+    ):
+        for tool_name, args in overrides.items():
+            known_args = set(tools[tool_name].parameters.get("properties", {}))
+            unknown_args = sorted(set(args) - known_args)
+            if unknown_args:
+                raise ValueError(
+                    f"{option} references unknown arguments for {tool_name}: {', '.join(unknown_args)}"
+                )
+
+
+def apply_tool_overrides(mcp: FastMCP, tools: Dict[str, FunctionTool]) -> None:
+    """Validate and apply tool overrides from settings to the server."""
+    validate_tool_overrides(tools)
+
+    for name in settings.tools_disabled:
+        mcp.remove_tool(name)
+        logger.info(f"✓ Tool disabled by config: {name}")
+
+    for name, tool in tools.items():
+        if name in settings.tools_disabled:
+            continue
+
+        alias = settings.tools_aliases.get(name)
+        defaults: Dict[str, Any] = settings.tools_arg_defaults.get(name, {})
+        locked: Dict[str, Any] = settings.tools_arg_locked.get(name, {})
+        if not (alias or defaults or locked):
+            continue
+
+        transform_args = {arg: ArgTransform(default=value) for arg, value in defaults.items()}
+        transform_args.update(
+            {arg: ArgTransform(default=value, hide=True) for arg, value in locked.items()}
+        )
+
+        mcp.remove_tool(name)
+        mcp.add_tool(Tool.from_tool(tool, name=alias or name, transform_args=transform_args))
+        logger.info(
+            f"✓ Tool overridden by config: {name}"
+            + (f" → {alias}" if alias else "")
+            + (f", defaults={sorted(defaults)}" if defaults else "")
+            + (f", locked={sorted(locked)}" if locked else "")
+        )
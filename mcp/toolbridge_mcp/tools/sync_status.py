@@ -0,0 +1,135 @@
+"""
+MCP tool for sync diagnostics.
+
+Lets users ask the assistant "why isn't my sync working" by reporting the
+server epoch, the MCP server's cached session, recent activity, and rate-limit
+status from GET /v1/sync/state and GET /v1/sync/info.
+"""
+
+from typing import Any, Dict, Optional
+
+import httpx
+from pydantic import BaseModel, Field
+from loguru import logger
+
+from toolbridge_mcp.async_client import get_client
+from toolbridge_mcp.auth import extract_user_id_from_backend_jwt
+from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import READ_ONLY
+from toolbridge_mcp.utils.requests import (
+    call_get,
+    ensure_tenant_resolved,
+    get_backend_auth_header,
+    get_last_activity,
+)
+from toolbridge_mcp.utils.session import get_session_cache
+
+
+class RateLimitStatus(BaseModel):
+    """Rate-limit configuration and the caller's current budget."""
+
+    window_seconds: Optional[int] = Field(default=None, alias="windowSeconds")
+    max_requests: Optional[int] = Field(default=None, alias="maxRequests")
+    burst: Optional[int] = None
+    remaining: Optional[int] = None  # As of the last rate-limited call
+    reset_at: Optional[int] = Field(default=None, alias="resetAt")
+
+    class Config:
+        populate_by_name = True
+
+
+class SyncStatus(BaseModel):
+    """Sync diagnostics for the current user."""
+
+    server_epoch: int = Field(alias="serverEpoch")
+    session_epoch: Optional[int] = Field(default=None, alias="sessionEpoch")
+    epoch_mismatch: bool = Field(alias="epochMismatch")
+    last_wipe_at: Optional[str] = Field(default=None, alias="lastWipeAt")
+    last_wipe_by: Optional[str] = Field(default=None, alias="lastWipeBy")
+    session_id: Optional[str] = Field(default=None, alias="sessionId")
+    session_expires_in_seconds: Optional[int] = Field(default=None, alias="sessionExpiresInSeconds")
+    last_push_ms: Optional[int] = Field(default=None, alias="lastPushMs")
+    last_pull_ms: Optional[int] = Field(default=None, alias="lastPullMs")
+    api_version: Optional[str] = Field(default=None, alias="apiVersion")
+    server_time: Optional[str] = Field(default=None, alias="serverTime")
+    rate_limit: RateLimitStatus = Field(alias="rateLimit")
+    entities: Dict[str, Any] = Field(default_factory=dict)
+
+    class Config:
+        populate_by_name = True
+
+
+@mcp.tool(annotations=READ_ONLY)
+async def sync_status() -> SyncStatus:
+    """
+    Report sync diagnostics for the current user.
+
+    Use this when the user asks why sync isn't working. It reports:
+    - Server epoch and whether the MCP session's epoch still matches it
+      (a mismatch means the account was wiped and clients must re-sync)
+    - The cached sync session and when it expires
+    - Last successful push (write) and pull (read) made through this MCP server
+    - Rate-limit configuration and the remaining request budget as of the
+      last rate-limited call (None if no entity calls were made yet)
+
+    Returns:
+        Sync status for the authenticated user
+
+    Examples:
+        >>> await sync_status()
+    """
+    async with get_client() as client:
+        await ensure_tenant_resolved(client)
+        auth_header = await get_backend_auth_header(client)
+        user_id = extract_user_id_from_backend_jwt(auth_header[7:])
+
+        # Snapshot activity before our own state call is recorded as a pull
+        activity = get_last_activity(user_id)
+
+        # Diagnostics should answer quickly even when the API is struggling
+        state = (await call_get(client, "/v1/sync/state", timeout=10.0)).json()
+
+        # Server info is public and doesn't need session headers
+        info: Dict[str, Any] = {}
+        try:
+            info_response = await client.get("/v1/sync/info", timeout=5.0)
+            info_response.raise_for_status()
+            info = info_response.json()
+        except httpx.HTTPError as e:
+            logger.warning(f"Failed to fetch /v1/sync/info for sync_status: {e}")
+
+    cache = get_session_cache()
+    session_headers = cache.get(user_id) or {}
+    expires_in = cache.expires_in(user_id)
+    session_epoch = session_headers.get("X-Sync-Epoch")
+    server_epoch = state.get("epoch", 0)
+
+    rate_limit_info = info.get("rateLimit") or {}
+
+    status = SyncStatus(
+        server_epoch=server_epoch,
+        session_epoch=int(session_epoch) if session_epoch else None,
+        epoch_mismatch=bool(session_epoch) and int(session_epoch) != server_epoch,
+        last_wipe_at=state.get("lastWipeAt"),
+        last_wipe_by=state.get("lastWipeBy"),
+        session_id=session_headers.get("X-Sync-Session"),
+        session_expires_in_seconds=int(expires_in) if expires_in is not None else None,
+        last_push_ms=activity.get("push"),
+        last_pull_ms=activity.get("pull"),
+        api_version=info.get("apiVersion"),
+        server_time=info.get("serverTime"),
+        rate_limit=RateLimitStatus(
+            window_seconds=rate_limit_info.get("windowSeconds"),
+            max_requests=rate_limit_info.get("maxRequests"),
+            burst=rate_limit_info.get("burst"),
+            remaining=activity.get("rateLimitRemaining"),
+            reset_at=activity.get("rateLimitReset"),
+        ),
+        entities=info.get("entities") or {},
+    )
+
+    logger.info(
+        f"sync_status: epoch={server_epoch} session_epoch={status.session_epoch} "
+        f"remaining={status.rate_limit.remaining}"
+    )
+    return status
@@ -14,6 +14,7 @@ from loguru import logger
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import CREATE, DESTRUCTIVE, READ_ONLY, STATE_CHANGE, UPDATE
 
 
 # Pydantic models matching Go API responses
@@ -45,7 +46,7 @@ class TasksListResponse(BaseModel):
 # MCP Tool Definitions
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_tasks(
     limit: Annotated[
         int, Field(ge=1, le=1000, description="Maximum number of tasks to return")
@@ -95,7 +96,7 @@ async def list_tasks(
         return TasksListResponse(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def get_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
     include_deleted: Annotated[bool, Field(description="Allow retrieving deleted tasks")] = False,
@@ -132,7 +133,7 @@ async def get_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=CREATE)
 async def create_task(
     title: Annotated[str, Field(description="Task title")],
     description: Annotated[Optional[str], Field(description="Task description")] = None,
@@ -226,7 +227,7 @@ async def create_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def update_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
     title: Annotated[str, Field(description="Task title")],
@@ -294,7 +295,7 @@ async def update_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=UPDATE)
 async def patch_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
     updates: Annotated[
@@ -340,7 +341,7 @@ async def patch_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=DESTRUCTIVE)
 async def delete_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
 ) -> Task:
@@ -367,7 +368,7 @@ async def delete_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
 ) -> Task:
@@ -394,7 +395,7 @@ async def archive_task(
         return Task(**data)
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_task(
     uid: Annotated[str, Field(description="Unique identifier of the task")],
     action: Annotated[str, Field(description="Action to perform (start, complete, reopen)")],
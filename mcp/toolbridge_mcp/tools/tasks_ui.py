@@ -12,13 +12,14 @@ from loguru import logger
 from mcp.types import TextContent, EmbeddedResource
 
 from toolbridge_mcp.mcp_instance import mcp
+from toolbridge_mcp.tools.annotations import READ_ONLY, STATE_CHANGE
 from toolbridge_mcp.tools.tasks import list_tasks, get_task, process_task, archive_task, Task, TasksListResponse
 from toolbridge_mcp.ui.resources import build_ui_with_text_and_dom, UIContent, UIFormat
 from toolbridge_mcp.ui.templates import tasks as tasks_templates
 from toolbridge_mcp.ui.remote_dom import tasks as tasks_dom_templates
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def list_tasks_ui(
     limit: Annotated[int, Field(ge=1, le=100, description="Max tasks to display")] = 20,
     include_deleted: Annotated[bool, Field(description="Include deleted tasks")] = False,
@@ -107,7 +108,7 @@ async def list_tasks_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=READ_ONLY)
 async def show_task_ui(
     uid: Annotated[str, Field(description="UID of the task to display")],
     include_deleted: Annotated[bool, Field(description="Allow deleted tasks")] = False,
@@ -187,7 +188,7 @@ async def show_task_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def process_task_ui(
     uid: Annotated[str, Field(description="UID of the task to process")],
     action: Annotated[str, Field(description="Action to perform (start, complete, reopen)")],
@@ -265,7 +266,7 @@ async def process_task_ui(
     )
 
 
-@mcp.tool()
+@mcp.tool(annotations=STATE_CHANGE)
 async def archive_task_ui(
     uid: Annotated[str, Field(description="UID of the task to archive")],
     limit: Annotated[int, Field(ge=1, le=100, description="Max tasks to display in refreshed list")] = 20,
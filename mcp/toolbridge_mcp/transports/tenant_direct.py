@@ -9,12 +9,60 @@ Supports two modes:
 - Multi-tenant: Uses dynamically resolved tenant ID (primary mode)
 """
 
+from typing import Optional
+
 import httpx
 from loguru import logger
 
 from toolbridge_mcp.config import settings
 
 
+# Shared connection pool for all Go API requests. get_client() creates a new
+# AsyncClient per tool call; sharing the underlying transport keeps connections
+# alive across calls instead of opening (and leaving in TIME_WAIT) a new socket
+# for every request.
+_shared_transport: Optional[httpx.AsyncHTTPTransport] = None
+
+
+def build_http_transport() -> httpx.AsyncHTTPTransport:
+    """Build the Go API transport from the http_* settings."""
+    verify: bool | str = settings.http_verify_tls
+    if settings.http_verify_tls and settings.http_ca_bundle:
+        verify = settings.http_ca_bundle
+
+    return httpx.AsyncHTTPTransport(
+        verify=verify,
+        http2=settings.http2_enabled,
+        proxy=settings.http_proxy,
+        retries=settings.http_connect_retries,
+        limits=httpx.Limits(
+            max_connections=settings.http_max_connections,
+            max_keepalive_connections=settings.http_max_keepalive_connections,
+            keepalive_expiry=settings.http_keepalive_expiry_seconds,
+        ),
+    )
+
+
+def get_shared_transport() -> httpx.AsyncHTTPTransport:
+    """Get the process-wide Go API transport, creating it on first use."""
+    global _shared_transport
+    if _shared_transport is None:
+        _shared_transport = build_http_transport()
+        logger.info(
+            f"✓ Go API connection pool: max_connections={settings.http_max_connections}, "
+            f"keepalive={settings.http_max_keepalive_connections}, http2={settings.http2_enabled}"
+        )
+    return _shared_transport
+
+
+async def close_shared_transport() -> None:
+    """Close the shared transport (call on shutdown)."""
+    global _shared_transport
+    if _shared_transport is not None:
+        await _shared_transport.aclose()
+        _shared_transport = None
+
+
 class TenantDirectTransport(httpx.AsyncBaseTransport):
     """
     Transport that adds X-TB-Tenant-ID header to all requests.
@@ -25,16 +73,21 @@ class TenantDirectTransport(httpx.AsyncBaseTransport):
     (multi-tenant) via the requests module.
     """
 
-    def __init__(self):
+    def __init__(self, transport: Optional[httpx.AsyncHTTPTransport] = None):
         """
         Initialize transport.
 
         The actual tenant_id is resolved at request time by the requests module.
         This allows us to support both single-tenant (configured) and multi-tenant
         (dynamic resolution) modes.
+
+        Args:
+            transport: Underlying transport to own. Defaults to the shared
+                connection pool, which is left open when this wrapper is closed.
         """
-        # Create underlying HTTP transport for actual network requests
-        self._transport = httpx.AsyncHTTPTransport()
+        # Underlying HTTP transport for actual network requests
+        self._owns_transport = transport is not None
+        self._transport = transport or get_shared_transport()
 
         mode = "single-tenant" if settings.tenant_id else "multi-tenant"
         logger.debug(
@@ -91,6 +144,7 @@ class TenantDirectTransport(httpx.AsyncBaseTransport):
             raise
 
     async def aclose(self):
-        """Close the underlying transport."""
-        await self._transport.aclose()
+        """Close the underlying transport unless it is the shared pool."""
+        if self._owns_transport:
+            await self._transport.aclose()
         logger.debug("TenantDirectTransport closed")
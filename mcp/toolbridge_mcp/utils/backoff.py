@@ -0,0 +1,42 @@
+"""
+Retry backoff for rate-limited (429) Go API calls.
+
+Uses "full jitter" exponential backoff: each delay is drawn uniformly from
+[0, min(cap, base * 2^attempt)], so many MCP sessions throttled at the same
+moment spread their retries out instead of hitting the API again in lockstep.
+A Retry-After header from the server is treated as a lower bound.
+"""
+
+import random
+from typing import Optional
+
+
+def full_jitter_delay(attempt: int, base_seconds: float, cap_seconds: float) -> float:
+    """Return a jittered delay in seconds for the given (0-based) retry attempt."""
+    ceiling = min(cap_seconds, base_seconds * (2 ** attempt))
+    return random.uniform(0, ceiling)
+
+
+def parse_retry_after(value: Optional[str]) -> Optional[float]:
+    """Parse a Retry-After header given in seconds (HTTP-date form is not used by the Go API)."""
+    if not value:
+        return None
+    try:
+        seconds = float(value)
+    except ValueError:
+        return None
+    return seconds if seconds >= 0 else None
+
+
+def retry_delay(
+    attempt: int,
+    base_seconds: float,
+    cap_seconds: float,
+    retry_after: Optional[str] = None,
+) -> float:
+    """Delay before the next retry, honoring Retry-After as a minimum."""
+    delay = full_jitter_delay(attempt, base_seconds, cap_seconds)
+    server_delay = parse_retry_after(retry_after)
+    if server_delay is not None:
+        delay = max(delay, server_delay)
+    return delay
@@ -0,0 +1,119 @@
+"""
+Circuit breaker for upstream Go API calls.
+
+When the sync API is struggling (5xx responses or timeouts), every tool call
+retrying against it makes things worse. The breaker tracks consecutive
+failures and, once the threshold is reached, fails calls fast with
+UpstreamUnavailableError instead of sending them.
+
+States:
+- closed: calls flow normally; consecutive failures are counted
+- open: calls fail immediately until open_seconds have elapsed
+- half_open: a single trial call is let through; success closes the breaker,
+  failure re-opens it for another open_seconds
+
+4xx responses are the caller's problem, not the upstream's, and count as success.
+"""
+
+import threading
+import time
+from typing import Optional
+
+from loguru import logger
+
+
+class UpstreamUnavailableError(Exception):
+    """Raised when the circuit breaker is open and the call was not attempted."""
+
+    def __init__(self, retry_after_seconds: float):
+        self.retry_after_seconds = retry_after_seconds
+        super().__init__(
+            "ToolBridge API is temporarily unavailable after repeated failures. "
+            f"Retry in {max(1, int(retry_after_seconds + 0.999))}s."
+        )
+
+
+class CircuitBreaker:
+    """Consecutive-failure circuit breaker (closed → open → half_open → closed)."""
+
+    CLOSED = "closed"
+    OPEN = "open"
+    HALF_OPEN = "half_open"
+
+    def __init__(self, failure_threshold: int, open_seconds: float):
+        self.failure_threshold = failure_threshold
+        self.open_seconds = open_seconds
+        self._state = self.CLOSED
+        self._failures = 0
+        self._opened_at = 0.0
+        self._trial_in_flight = False
+        self._lock = threading.Lock()
+
+    @property
+    def state(self) -> str:
+        with self._lock:
+            return self._current_state()
+
+    def _current_state(self) -> str:
+        if self._state == self.OPEN and time.monotonic() - self._opened_at >= self.open_seconds:
+            self._state = self.HALF_OPEN
+            self._trial_in_flight = False
+        return self._state
+
+    def before_call(self) -> None:
+        """Raise UpstreamUnavailableError if the call should not be attempted."""
+        with self._lock:
+            state = self._current_state()
+            if state == self.CLOSED:
+                return
+            if state == self.HALF_OPEN and not self._trial_in_flight:
+                self._trial_in_flight = True
+                return
+
+            retry_after = self.open_seconds - (time.monotonic() - self._opened_at)
+            raise UpstreamUnavailableError(max(retry_after, 0.0))
+
+    def release(self) -> None:
+        """Release a half-open trial that ended without reaching the upstream."""
+        with self._lock:
+            self._trial_in_flight = False
+
+    def record_success(self) -> None:
+        with self._lock:
+            if self._state != self.CLOSED:
+                logger.info("Circuit breaker closed: upstream recovered")
+            self._state = self.CLOSED
+            self._failures = 0
+            self._trial_in_flight = False
+
+    def record_failure(self) -> None:
+        with self._lock:
+            self._failures += 1
+            if self._state == self.HALF_OPEN or self._failures >= self.failure_threshold:
+                if self._state != self.OPEN:
+                    logger.warning(
+                        f"Circuit breaker opened after {self._failures} consecutive upstream failures "
+                        f"(failing fast for {self.open_seconds}s)"
+                    )
+                self._state = self.OPEN
+                self._opened_at = time.monotonic()
+                self._trial_in_flight = False
+
+
+# Global breaker for the Go API - lazily created from settings
+_breaker: Optional[CircuitBreaker] = None
+
+
+def get_circuit_breaker() -> Optional[CircuitBreaker]:
+    """Get the Go API circuit breaker, or None when disabled (threshold 0)."""
+    global _breaker
+    if _breaker is None:
+        from toolbridge_mcp.config import settings
+
+        if settings.circuit_breaker_failure_threshold <= 0:
+            return None
+        _breaker = CircuitBreaker(
+            failure_threshold=settings.circuit_breaker_failure_threshold,
+            open_seconds=settings.circuit_breaker_open_seconds,
+        )
+    return _breaker
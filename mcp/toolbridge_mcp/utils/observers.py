@@ -0,0 +1,155 @@
+"""
+Instrumentation hooks for Go API requests.
+
+Embedders can register a RequestObserver to wire metrics or tracing into every
+upstream call made by utils.requests without forking the request helpers:
+
+    class MetricsObserver(RequestObserver):
+        def on_response(self, method, path, status_code, duration_seconds):
+            REQUEST_LATENCY.labels(method, status_code).observe(duration_seconds)
+
+    register_observer(MetricsObserver())
+
+Observer errors are logged and swallowed so instrumentation can never fail a
+tool call. A LoggingObserver that logs each upstream call at DEBUG is
+registered by default. PrometheusObserver (requires prometheus_client, the
+"metrics" extra) is registered by the server when TOOLBRIDGE_METRICS_ENABLED
+is set, which also serves the default registry at GET /metrics; embedders
+registering it themselves must expose its registry.
+"""
+
+import weakref
+from typing import List, Optional
+
+from loguru import logger
+
+try:
+    import prometheus_client
+except ImportError:  # Optional: only PrometheusObserver needs it
+    prometheus_client = None
+
+
+class RequestObserver:
+    """Base class for request observers; override only the hooks you need."""
+
+    def on_request(self, method: str, path: str) -> None:
+        """Called before each attempt is sent."""
+
+    def on_response(self, method: str, path: str, status_code: int, duration_seconds: float) -> None:
+        """Called when an attempt receives a response (any status)."""
+
+    def on_error(self, method: str, path: str, error: Exception, duration_seconds: float) -> None:
+        """Called when an attempt fails without a response (timeout, connection error)."""
+
+    def on_retry(self, method: str, path: str, attempt: int, delay_seconds: float, reason: str) -> None:
+        """Called before a retry; reason is e.g. "rate_limited", "session_rejected" or "epoch_mismatch"."""
+
+
+class LoggingObserver(RequestObserver):
+    """Logs upstream calls at DEBUG and retries at INFO."""
+
+    def on_response(self, method: str, path: str, status_code: int, duration_seconds: float) -> None:
+        logger.debug(f"Go API {method} {path} → {status_code} ({duration_seconds * 1000:.0f}ms)")
+
+    def on_error(self, method: str, path: str, error: Exception, duration_seconds: float) -> None:
+        logger.debug(f"Go API {method} {path} failed after {duration_seconds * 1000:.0f}ms: {error!r}")
+
+    def on_retry(self, method: str, path: str, attempt: int, delay_seconds: float, reason: str) -> None:
+        logger.info(f"Retrying Go API {method} {path} ({reason}, attempt {attempt}, in {delay_seconds:.2f}s)")
+
+
+class _UpstreamMetrics:
+    """The Prometheus collectors for upstream calls, registered once per registry."""
+
+    def __init__(self, registry: "prometheus_client.CollectorRegistry"):
+        self.requests = prometheus_client.Counter(
+            "toolbridge_mcp_upstream_requests_total",
+            "Go API responses by method and status code",
+            ["method", "status"],
+            registry=registry,
+        )
+        self.duration = prometheus_client.Histogram(
+            "toolbridge_mcp_upstream_request_duration_seconds",
+            "Go API request duration per attempt, including failed ones",
+            ["method"],
+            registry=registry,
+        )
+        self.errors = prometheus_client.Counter(
+            "toolbridge_mcp_upstream_errors_total",
+            "Go API attempts that failed without a response (timeout, connection error)",
+            ["method"],
+            registry=registry,
+        )
+        self.retries = prometheus_client.Counter(
+            "toolbridge_mcp_upstream_retries_total",
+            "Go API retries by reason",
+            ["method", "reason"],
+            registry=registry,
+        )
+
+
+# Collectors per registry: prometheus_client rejects registering the same
+# metric names twice, so observers for one registry share them
+_metrics_by_registry: "weakref.WeakKeyDictionary[object, _UpstreamMetrics]" = (
+    weakref.WeakKeyDictionary()
+)
+
+
+class PrometheusObserver(RequestObserver):
+    """
+    Records upstream calls as Prometheus metrics.
+
+    Labels are the HTTP method and status (not the path, which contains item
+    UIDs). Metrics are registered in registry (default: prometheus_client's
+    default registry); observers created for the same registry share them, so
+    constructing one twice is safe. Requires prometheus_client.
+    """
+
+    def __init__(self, registry: Optional["prometheus_client.CollectorRegistry"] = None):
+        if prometheus_client is None:
+            raise RuntimeError(
+                "PrometheusObserver requires prometheus_client (install the metrics extra)"
+            )
+        if registry is None:
+            registry = prometheus_client.REGISTRY
+
+        metrics = _metrics_by_registry.get(registry)
+        if metrics is None:
+            metrics = _metrics_by_registry[registry] = _UpstreamMetrics(registry)
+        self.requests = metrics.requests
+        self.duration = metrics.duration
+        self.errors = metrics.errors
+        self.retries = metrics.retries
+
+    def on_response(self, method: str, path: str, status_code: int, duration_seconds: float) -> None:
+        self.requests.labels(method, str(status_code)).inc()
+        self.duration.labels(method).observe(duration_seconds)
+
+    def on_error(self, method: str, path: str, error: Exception, duration_seconds: float) -> None:
+        self.errors.labels(method).inc()
+        self.duration.labels(method).observe(duration_seconds)
+
+    def on_retry(self, method: str, path: str, attempt: int, delay_seconds: float, reason: str) -> None:
+        self.retries.labels(method, reason).inc()
+
+
+_observers: List[RequestObserver] = [LoggingObserver()]
+
+
+def register_observer(observer: RequestObserver) -> None:
+    """Register an observer for all subsequent Go API requests."""
+    _observers.append(observer)
+
+
+def clear_observers() -> None:
+    """Remove all observers, including the default LoggingObserver."""
+    _observers.clear()
+
+
+def notify(hook: str, *args) -> None:
+    """Invoke hook on every registered observer, isolating observer failures."""
+    for observer in list(_observers):
+        try:
+            getattr(observer, hook)(*args)
+        except Exception as e:
+            logger.warning(f"Request observer {type(observer).__name__}.{hook} failed: {e}")
@@ -0,0 +1,179 @@
+"""
+Rate limiting for MCP tool calls.
+
+Every tool call reaches the Go API with the calling user's backend credentials,
+so a runaway model loop can burn through the user's sync API budget (and hit
+429s that break unrelated clients). This module applies token buckets in front
+of tool execution, mirroring the Go API's RateLimitMiddleware:
+
+- Per-session bucket: limits a single MCP session (one host connection)
+- Per-user bucket: limits the sum of all sessions for the same WorkOS user
+
+A call must get a token from both buckets. Rejected calls surface as a ToolError
+with a retry hint so the model can back off instead of retrying immediately.
+"""
+
+import threading
+import time
+from collections import OrderedDict
+from typing import Optional
+
+from fastmcp.exceptions import ToolError
+from fastmcp.server.dependencies import get_access_token
+from fastmcp.server.middleware import Middleware, MiddlewareContext
+from loguru import logger
+
+from toolbridge_mcp.config import settings
+
+
+class TokenBucket:
+    """Token bucket with a fixed capacity and refill rate (tokens per second)."""
+
+    def __init__(self, capacity: int, refill_rate: float):
+        self.capacity = float(capacity)
+        self.refill_rate = refill_rate
+        self.tokens = float(capacity)
+        self.last_refill = time.monotonic()
+
+    def allow(self) -> tuple[bool, float]:
+        """
+        Consume a token if one is available.
+
+        Returns:
+            (allowed, retry_after_seconds) - retry_after is 0 when allowed
+        """
+        now = time.monotonic()
+        elapsed = now - self.last_refill
+        self.tokens = min(self.capacity, self.tokens + elapsed * self.refill_rate)
+        self.last_refill = now
+
+        if self.tokens >= 1.0:
+            self.tokens -= 1.0
+            return True, 0.0
+
+        if self.refill_rate <= 0:
+            return False, float("inf")
+        return False, (1.0 - self.tokens) / self.refill_rate
+
+
+class RateLimiter:
+    """
+    Keyed collection of token buckets.
+
+    Buckets are created on first use and the least recently used keys are
+    dropped once max_keys is exceeded, so idle users don't accumulate forever.
+    """
+
+    def __init__(self, max_calls: int, window_seconds: int, burst: int, max_keys: int = 10000):
+        self.capacity = burst
+        self.refill_rate = max_calls / window_seconds if window_seconds > 0 else 0.0
+        self.max_keys = max_keys
+        self._buckets: OrderedDict[str, TokenBucket] = OrderedDict()
+        self._lock = threading.Lock()
+
+    def allow(self, key: str) -> tuple[bool, float]:
+        """Consume a token from the bucket for key."""
+        with self._lock:
+            bucket = self._buckets.get(key)
+            if bucket is None:
+                bucket = TokenBucket(self.capacity, self.refill_rate)
+                self._buckets[key] = bucket
+                while len(self._buckets) > self.max_keys:
+                    self._buckets.popitem(last=False)
+            else:
+                self._buckets.move_to_end(key)
+            return bucket.allow()
+
+    def __len__(self) -> int:
+        return len(self._buckets)
+
+
+class ToolRateLimitMiddleware(Middleware):
+    """FastMCP middleware that rejects tool calls exceeding the configured limits."""
+
+    def __init__(
+        self,
+        per_user: Optional[RateLimiter] = None,
+        per_session: Optional[RateLimiter] = None,
+    ):
+        self.per_user = per_user
+        self.per_session = per_session
+
+    async def on_call_tool(self, context: MiddlewareContext, call_next):
+        tool_name = getattr(context.message, "name", "unknown")
+
+        if self.per_session is not None:
+            session_id = _session_id(context)
+            if session_id:
+                allowed, retry_after = self.per_session.allow(session_id)
+                if not allowed:
+                    _reject("session", session_id, tool_name, retry_after)
+
+        if self.per_user is not None:
+            user_id = _user_id()
+            if user_id:
+                allowed, retry_after = self.per_user.allow(user_id)
+                if not allowed:
+                    _reject("user", user_id, tool_name, retry_after)
+
+        return await call_next(context)
+
+
+def _session_id(context: MiddlewareContext) -> Optional[str]:
+    """Return the MCP session id for the current request, if any."""
+    fastmcp_context = context.fastmcp_context
+    if fastmcp_context is None:
+        return None
+    try:
+        return fastmcp_context.session_id
+    except Exception:
+        # session_id is unavailable outside an HTTP request (e.g. stdio transport)
+        return None
+
+
+def _user_id() -> Optional[str]:
+    """Return the authenticated WorkOS user (sub) for the current request, if any."""
+    token = get_access_token()
+    if token is None:
+        return None
+    return token.claims.get("sub")
+
+
+def _reject(scope: str, key: str, tool_name: str, retry_after: float) -> None:
+    """Log and raise a ToolError for a rate-limited call."""
+    retry_seconds = max(1, int(retry_after + 0.999)) if retry_after != float("inf") else None
+    logger.warning(
+        f"Rate limited tool call: tool={tool_name} {scope}={key} retry_after={retry_seconds}s"
+    )
+    hint = f" Retry after {retry_seconds}s." if retry_seconds else ""
+    raise ToolError(f"Rate limit exceeded for this {scope}.{hint}")
+
+
+def build_rate_limit_middleware() -> Optional[ToolRateLimitMiddleware]:
+    """
+    Build the tool rate limit middleware from settings.
+
+    Returns None when rate limiting is disabled, or when both limits are zero.
+    """
+    if not settings.tool_rate_limit_enabled:
+        return None
+
+    window = settings.tool_rate_limit_window_seconds
+    per_user = None
+    if settings.tool_rate_limit_user_max_calls > 0:
+        per_user = RateLimiter(
+            max_calls=settings.tool_rate_limit_user_max_calls,
+            window_seconds=window,
+            burst=settings.tool_rate_limit_user_burst,
+        )
+    per_session = None
+    if settings.tool_rate_limit_session_max_calls > 0:
+        per_session = RateLimiter(
+            max_calls=settings.tool_rate_limit_session_max_calls,
+            window_seconds=window,
+            burst=settings.tool_rate_limit_session_burst,
+        )
+
+    if per_user is None and per_session is None:
+        return None
+    return ToolRateLimitMiddleware(per_user=per_user, per_session=per_session)
@@ -8,28 +8,39 @@ WorkOS AuthKit OAuth 2.1 Flow:
 4. Backend JWT sent to Go API with tenant header
 5. Go API validates backend JWT and creates per-user session
 
-Session management: Each request creates a fresh sync session before calling
-the API. Sessions are NOT cached or reused to avoid stale session issues.
+Session management: Sync sessions are cached per user and reused across tool
+calls (see utils.session.SessionCache). If the Go API rejects a cached session
+(428 Precondition Required when it is missing or expired, 409 epoch_mismatch
+after the account was wiped or restored), the entry is invalidated and the
+request is retried once with a fresh session.
 
 Tenant resolution: Supports two modes:
 - Single-tenant mode: TENANT_ID env var set → uses hardcoded tenant (smoke testing)
 - Multi-tenant mode: TENANT_ID not set → dynamically resolves via /v1/auth/tenant (primary mode)
 """
 
-from typing import Any, Dict, Optional
+import asyncio
+import time
+from collections import OrderedDict
+from typing import Any, Awaitable, Callable, Dict, Optional
 
 import httpx
 from fastmcp.server.dependencies import get_access_token
 from loguru import logger
 
 from toolbridge_mcp.auth import (
+    _unsafe_extract_expiry_for_refresh,
     exchange_for_backend_jwt,
     extract_user_id_from_backend_jwt,
     resolve_tenant,
     TenantResolutionError,
 )
 from toolbridge_mcp.config import settings
-from toolbridge_mcp.utils.session import create_session
+from toolbridge_mcp.utils.backoff import retry_delay
+from toolbridge_mcp.utils.circuit_breaker import get_circuit_breaker
+from toolbridge_mcp.utils.observers import notify
+from toolbridge_mcp.utils.session import create_session, get_session_cache
+from toolbridge_mcp.utils.trace_context import trace_headers
 
 
 class AuthorizationError(Exception):
@@ -43,8 +54,12 @@ _tenant_cache: Dict[str, str] = {}
 
 # Per-user backend JWT cache: key is user_id, value is backend JWT
 # Prevents double token exchange per request (ensure_tenant_resolved + get_backend_auth_header)
+# Entries are refreshed proactively shortly before they expire (see _get_backend_jwt)
 _jwt_cache: Dict[str, str] = {}
 
+# Per-user locks so concurrent tool calls share a single token refresh
+_jwt_refresh_locks: Dict[str, asyncio.Lock] = {}
+
 
 def get_cached_tenant_id(user_id: str) -> Optional[str]:
     """Get cached tenant ID for specific user."""
@@ -56,6 +71,40 @@ def get_cached_backend_jwt(user_id: str) -> Optional[str]:
     return _jwt_cache.get(user_id)
 
 
+def _is_fresh(backend_jwt: str) -> bool:
+    """True if the JWT won't expire within the refresh margin (or has no exp)."""
+    exp = _unsafe_extract_expiry_for_refresh(backend_jwt)
+    if exp is None:
+        return True
+    return exp - time.time() > settings.backend_jwt_refresh_margin_seconds
+
+
+async def _get_backend_jwt(client: httpx.AsyncClient, user_id: str) -> str:
+    """
+    Return a cached backend JWT for user_id, exchanging a new one if it is
+    missing or about to expire.
+
+    Concurrent callers for the same user wait on one exchange instead of each
+    refreshing, so a token rollover costs a single round-trip.
+    """
+    cached = _jwt_cache.get(user_id)
+    if cached and _is_fresh(cached):
+        return cached
+
+    lock = _jwt_refresh_locks.setdefault(user_id, asyncio.Lock())
+    async with lock:
+        # Another call may have refreshed while we waited
+        cached = _jwt_cache.get(user_id)
+        if cached and _is_fresh(cached):
+            return cached
+
+        if cached:
+            logger.debug(f"Backend JWT for user {user_id} expires soon, refreshing")
+        backend_jwt = await exchange_for_backend_jwt(client)
+        _jwt_cache[user_id] = backend_jwt
+        return backend_jwt
+
+
 async def ensure_tenant_resolved(client: httpx.AsyncClient) -> str:
     """
     Ensure tenant ID is resolved for the current user.
@@ -92,13 +141,12 @@ async def ensure_tenant_resolved(client: httpx.AsyncClient) -> str:
         cached_tenant = _tenant_cache.get(user_id)
         cached_jwt = _jwt_cache.get(user_id)
 
-        if cached_tenant and cached_jwt:
+        if cached_tenant and cached_jwt and _is_fresh(cached_jwt):
             logger.debug(f"Using cached tenant and JWT for user {user_id}: {cached_tenant}")
             return cached_tenant
 
-        # Need to exchange for backend JWT (cache miss or first request)
-        backend_jwt = await exchange_for_backend_jwt(client)
-        _jwt_cache[user_id] = backend_jwt
+        # Need a (fresh) backend JWT: cache miss, first request, or near expiry
+        await _get_backend_jwt(client, user_id)
 
         # Check if tenant was cached (JWT cache miss but tenant cache hit)
         if cached_tenant:
@@ -112,6 +160,11 @@ async def ensure_tenant_resolved(client: httpx.AsyncClient) -> str:
             _tenant_cache[user_id] = settings.tenant_id
             return settings.tenant_id
 
+        # API key mode has no ID token: the MCP token is the static API key, which
+        # must never leave this server (validate_api_key_config requires TENANT_ID)
+        if settings.auth_mode == "api_key":
+            raise AuthorizationError("TOOLBRIDGE_TENANT_ID is required in api_key mode")
+
         # Multi-tenant mode: Resolve tenant dynamically via /v1/auth/tenant
         logger.debug("Resolving tenant dynamically via /v1/auth/tenant (multi-tenant mode)")
 
@@ -146,7 +199,8 @@ async def get_backend_auth_header(client: httpx.AsyncClient) -> str:
 
     First checks the JWT cache (populated by ensure_tenant_resolved) for the
     CURRENT user (identified via MCP OAuth context). Falls back to exchanging
-    MCP OAuth token if not cached.
+    MCP OAuth token if not cached or if the cached JWT expires within
+    backend_jwt_refresh_margin_seconds.
 
     Args:
         client: httpx client for token exchange requests
@@ -165,16 +219,15 @@ async def get_backend_auth_header(client: httpx.AsyncClient) -> str:
         mcp_token = get_access_token()
         current_user_id = mcp_token.claims.get("sub")
 
-        # Try to get cached JWT for THIS specific user (avoids double token exchange)
+        # Use cached JWT for THIS specific user (avoids double token exchange),
+        # refreshing it first if it is about to expire
         # ensure_tenant_resolved caches the JWT when it exchanges for user_id
-        if current_user_id and current_user_id in _jwt_cache:
-            cached_jwt = _jwt_cache[current_user_id]
-            logger.debug(f"Using cached backend JWT for user {current_user_id}")
-            return f"Bearer {cached_jwt}"
-
-        # Fall back to token exchange if not cached
-        # (shouldn't happen if ensure_tenant_resolved was called first)
-        logger.debug(f"Exchanging MCP OAuth token for backend JWT (cache miss for user {current_user_id})")
+        if current_user_id:
+            backend_jwt = await _get_backend_jwt(client, current_user_id)
+            return f"Bearer {backend_jwt}"
+
+        # No user identity to cache under - exchange without caching
+        logger.debug("Exchanging MCP OAuth token for backend JWT (no user id in MCP token)")
         backend_jwt = await exchange_for_backend_jwt(client)
         return f"Bearer {backend_jwt}"
     except TokenExchangeError as e:
@@ -192,10 +245,10 @@ async def get_backend_auth_header(client: httpx.AsyncClient) -> str:
 
 async def ensure_session(client: httpx.AsyncClient, auth_header: str) -> Dict[str, str]:
     """
-    Create a fresh sync session for this request.
+    Get sync session headers for the current user, reusing a cached session.
 
-    Always creates a new session - no caching or reuse.
-    This ensures MCP tools can recover from session expiration.
+    Creates a new session only when the user has no cached session or the
+    cached one has expired/been invalidated.
 
     Args:
         client: httpx client (with TenantDirectTransport)
@@ -207,25 +260,276 @@ async def ensure_session(client: httpx.AsyncClient, auth_header: str) -> Dict[st
     # Extract user ID from backend JWT
     token = auth_header[7:]  # Remove "Bearer " prefix
     user_id = extract_user_id_from_backend_jwt(token)
-    
-    # Always create a fresh session for each request
-    return await create_session(client, auth_header, user_id)
+
+    cache = get_session_cache()
+    cached = cache.get(user_id)
+    if cached:
+        logger.debug(f"Reusing cached sync session for user: {user_id}")
+        return cached
+
+    session_headers = await create_session(client, auth_header, user_id)
+    cache.put(user_id, session_headers)
+    return session_headers
+
+
+# Last successful read (GET) and write (POST/PUT/PATCH/DELETE) per user, as
+# epoch milliseconds, plus the most recent rate-limit headers seen for the user.
+# Reported by the sync_status tool for diagnostics only. Bounded like the
+# session cache: beyond session_cache_max_users, the least recently active
+# users are dropped.
+_last_activity: "OrderedDict[str, Dict[str, int]]" = OrderedDict()
+
+
+def get_last_activity(user_id: str) -> Dict[str, int]:
+    """Return the user's last activity: pull/push timestamps and rate-limit budget."""
+    return dict(_last_activity.get(user_id, {}))
+
+
+def _record_activity(auth_header: str, method: str, response: httpx.Response) -> None:
+    user_id = extract_user_id_from_backend_jwt(auth_header[7:])
+    activity = _last_activity.setdefault(user_id, {})
+    _last_activity.move_to_end(user_id)
+    while len(_last_activity) > settings.session_cache_max_users:
+        _last_activity.popitem(last=False)
+    activity["pull" if method.upper() == "GET" else "push"] = int(time.time() * 1000)
+
+    # Only rate-limited routes send these headers (e.g. not /v1/sync/state)
+    for header, key in (
+        ("X-RateLimit-Remaining", "rateLimitRemaining"),
+        ("X-RateLimit-Reset", "rateLimitReset"),
+    ):
+        value = response.headers.get(header)
+        if value is not None and value.isdigit():
+            activity[key] = int(value)
+
+
+def invalidate_session(auth_header: str) -> None:
+    """Drop the cached sync session for the user identified by auth_header."""
+    user_id = extract_user_id_from_backend_jwt(auth_header[7:])
+    get_session_cache().invalidate(user_id)
+
+
+async def _send(
+    client: httpx.AsyncClient,
+    method: str,
+    path: str,
+    extra_headers: Optional[Dict[str, str]] = None,
+    timeout: Optional[float] = None,
+    **kwargs: Any,
+) -> httpx.Response:
+    """
+    Send an authenticated request with sync session headers.
+
+    Ensures tenant is resolved and a sync session exists. If the Go API rejects
+    the session (428, or 409 epoch_mismatch after a wipe or restore), the cached
+    session is invalidated and the request is retried once with a fresh session.
+
+    Calls go through the upstream circuit breaker: after repeated 5xx responses
+    or timeouts, UpstreamUnavailableError is raised without contacting the API.
+
+    429 responses are retried with full-jitter exponential backoff (see
+    utils.backoff) until retry_max_attempts is reached or the next delay would
+    exceed the total retry_budget_seconds.
+
+    If timeout is given it is the total budget for the call: tenant resolution,
+    token exchange and session creation are bounded by it too, each send
+    (including a session retry) gets only the time remaining rather than a
+    fresh timeout, and retries stop once the budget is spent.
+
+    Every attempt and retry is reported to registered observers (see utils.observers).
+    """
+    deadline = time.monotonic() + (timeout if timeout is not None else settings.retry_budget_seconds)
+    # Without a timeout the budget only limits 429 retries; attempts use the client timeout
+    call_deadline = deadline if timeout is not None else None
+
+    attempt = 0
+    while True:
+        # Checked here too so a spent budget doesn't count against the circuit breaker
+        _time_left(call_deadline, method, path)
+
+        response, auth_header = await _send_through_breaker(
+            client, method, path, extra_headers, deadline=call_deadline, **kwargs
+        )
+        if response.status_code != 429 or attempt >= settings.retry_max_attempts:
+            break
+
+        delay = retry_delay(
+            attempt,
+            base_seconds=settings.retry_base_seconds,
+            cap_seconds=settings.retry_cap_seconds,
+            retry_after=response.headers.get("Retry-After"),
+        )
+        if time.monotonic() + delay > deadline:
+            logger.info(f"Rate limited on {method} {path}, retry budget exhausted")
+            break
+
+        notify("on_retry", method, path, attempt + 1, delay, "rate_limited")
+        await asyncio.sleep(delay)
+        attempt += 1
+
+    response.raise_for_status()
+    _record_activity(auth_header, method, response)
+    return response
+
+
+async def _send_through_breaker(
+    client: httpx.AsyncClient,
+    method: str,
+    path: str,
+    extra_headers: Optional[Dict[str, str]],
+    deadline: Optional[float] = None,
+    **kwargs: Any,
+) -> tuple[httpx.Response, str]:
+    """Send a single attempt, recording the outcome on the circuit breaker."""
+    breaker = get_circuit_breaker()
+    if breaker is not None:
+        breaker.before_call()
+
+    try:
+        response, auth_header = await _send_with_session(
+            client, method, path, extra_headers, deadline=deadline, **kwargs
+        )
+    except (httpx.TimeoutException, httpx.TransportError):
+        if breaker is not None:
+            breaker.record_failure()
+        raise
+    except Exception:
+        if breaker is not None:
+            breaker.release()
+        raise
+
+    if breaker is not None:
+        if response.status_code >= 500:
+            breaker.record_failure()
+        else:
+            breaker.record_success()
+
+    return response, auth_header
+
+
+def _time_left(deadline: Optional[float], method: str, path: str) -> Optional[float]:
+    """Seconds left before deadline (time.monotonic), or None without a deadline.
+
+    Raises httpx.TimeoutException once the deadline has passed.
+    """
+    if deadline is None:
+        return None
+    remaining = deadline - time.monotonic()
+    if remaining <= 0:
+        raise httpx.TimeoutException(f"{method} {path} exceeded its time budget")
+    return remaining
+
+
+async def _before_deadline(
+    deadline: Optional[float],
+    method: str,
+    path: str,
+    step: Callable[..., Awaitable[Any]],
+    *args: Any,
+) -> Any:
+    """Run a setup step (tenant, auth header, session) within the call's deadline."""
+    remaining = _time_left(deadline, method, path)
+    if remaining is None:
+        return await step(*args)
+    try:
+        return await asyncio.wait_for(step(*args), remaining)
+    except asyncio.TimeoutError as e:
+        raise httpx.TimeoutException(f"{method} {path} exceeded its time budget") from e
+
+
+def _session_rejection(response: httpx.Response) -> Optional[str]:
+    """Return the retry reason if the Go API rejected the request's sync session.
+
+    428 means the session is missing or expired. A 409 with error
+    "epoch_mismatch" means the account was wiped or restored since the session
+    was created, so the X-Sync-Epoch it pins is stale. Other 409s (e.g.
+    version_mismatch) are real conflicts and are returned to the caller.
+    """
+    if response.status_code == 428:
+        return "session_rejected"
+    if response.status_code == 409:
+        try:
+            body = response.json()
+        except ValueError:
+            return None
+        if isinstance(body, dict) and body.get("error") == "epoch_mismatch":
+            return "epoch_mismatch"
+    return None
+
+
+async def _send_with_session(
+    client: httpx.AsyncClient,
+    method: str,
+    path: str,
+    extra_headers: Optional[Dict[str, str]],
+    deadline: Optional[float] = None,
+    **kwargs: Any,
+) -> tuple[httpx.Response, str]:
+    """Send the request, retrying once with a fresh session if it was rejected.
+
+    With a deadline, every step is bounded by the time left when it starts and
+    each send is given that as its timeout.
+
+    Returns the response and the Authorization header it was sent with.
+    """
+    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
+    await _before_deadline(deadline, method, path, ensure_tenant_resolved, client)
+
+    auth_header = await _before_deadline(deadline, method, path, get_backend_auth_header, client)
+
+    response: Optional[httpx.Response] = None
+    for attempt in range(2):
+        session_headers = await _before_deadline(
+            deadline, method, path, ensure_session, client, auth_header
+        )
+        headers = {
+            "Authorization": auth_header,
+            **session_headers,
+            **trace_headers(),
+            **(extra_headers or {}),
+        }
+
+        remaining = _time_left(deadline, method, path)
+        if remaining is not None:
+            kwargs["timeout"] = remaining
+
+        send = getattr(client, method.lower())
+        notify("on_request", method, path)
+        started = time.monotonic()
+        try:
+            response = await send(path, headers=headers, **kwargs)
+        except Exception as e:
+            notify("on_error", method, path, e, time.monotonic() - started)
+            raise
+        notify("on_response", method, path, response.status_code, time.monotonic() - started)
+
+        reason = _session_rejection(response)
+        if reason is None or attempt > 0:
+            break
+
+        notify("on_retry", method, path, attempt + 1, 0.0, reason)
+        invalidate_session(auth_header)
+
+    return response, auth_header
 
 
 async def call_get(
     client: httpx.AsyncClient,
     path: str,
     params: Optional[Dict[str, Any]] = None,
+    timeout: Optional[float] = None,
 ) -> httpx.Response:
     """
     Make GET request to Go API.
 
-    Ensures tenant is resolved, creates a fresh sync session, and includes all required headers.
+    Ensures tenant is resolved, reuses (or creates) the user's sync session, and includes all required headers.
 
     Args:
         client: httpx client (with TenantDirectTransport)
         path: API endpoint path (e.g., "/v1/notes")
         params: Query parameters
+        timeout: Total time budget in seconds for this call, including retries
+            (defaults to the client timeout per attempt and retry_budget_seconds)
 
     Returns:
         HTTP response
@@ -234,37 +538,27 @@ async def call_get(
         httpx.HTTPStatusError: If request fails
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
-    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
-    await ensure_tenant_resolved(client)
-
-    auth_header = await get_backend_auth_header(client)
-    session_headers = await ensure_session(client, auth_header)
-
-    headers = {
-        "Authorization": auth_header,
-        **session_headers,
-    }
-
     logger.debug(f"GET {path} params={params}")
-    response = await client.get(path, params=params, headers=headers)
-    response.raise_for_status()
-    return response
+    return await _send(client, "GET", path, timeout=timeout, params=params)
 
 
 async def call_post(
     client: httpx.AsyncClient,
     path: str,
     json: Optional[Dict[str, Any]] = None,
+    timeout: Optional[float] = None,
 ) -> httpx.Response:
     """
     Make POST request to Go API.
 
-    Ensures tenant is resolved, creates a fresh sync session, and includes all required headers.
+    Ensures tenant is resolved, reuses (or creates) the user's sync session, and includes all required headers.
 
     Args:
         client: httpx client (with TenantDirectTransport)
         path: API endpoint path (e.g., "/v1/notes")
         json: JSON request body
+        timeout: Total time budget in seconds for this call, including retries
+            (defaults to the client timeout per attempt and retry_budget_seconds)
 
     Returns:
         HTTP response
@@ -273,21 +567,8 @@ async def call_post(
         httpx.HTTPStatusError: If request fails
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
-    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
-    await ensure_tenant_resolved(client)
-
-    auth_header = await get_backend_auth_header(client)
-    session_headers = await ensure_session(client, auth_header)
-
-    headers = {
-        "Authorization": auth_header,
-        **session_headers,
-    }
-
     logger.debug(f"POST {path}")
-    response = await client.post(path, json=json, headers=headers)
-    response.raise_for_status()
-    return response
+    return await _send(client, "POST", path, timeout=timeout, json=json)
 
 
 async def call_put(
@@ -295,17 +576,20 @@ async def call_put(
     path: str,
     json: Optional[Dict[str, Any]] = None,
     if_match: Optional[int] = None,
+    timeout: Optional[float] = None,
 ) -> httpx.Response:
     """
     Make PUT request to Go API.
 
-    Ensures tenant is resolved, creates a fresh sync session, and includes all required headers.
+    Ensures tenant is resolved, reuses (or creates) the user's sync session, and includes all required headers.
 
     Args:
         client: httpx client (with TenantDirectTransport)
         path: API endpoint path (e.g., "/v1/notes/{uid}")
         json: JSON request body
         if_match: Optional version for optimistic locking
+        timeout: Total time budget in seconds for this call, including retries
+            (defaults to the client timeout per attempt and retry_budget_seconds)
 
     Returns:
         HTTP response
@@ -314,40 +598,31 @@ async def call_put(
         httpx.HTTPStatusError: If request fails
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
-    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
-    await ensure_tenant_resolved(client)
-
-    auth_header = await get_backend_auth_header(client)
-    session_headers = await ensure_session(client, auth_header)
-
-    headers = {
-        "Authorization": auth_header,
-        **session_headers,
-    }
-
+    extra_headers = {}
     if if_match is not None:
-        headers["If-Match"] = str(if_match)
+        extra_headers["If-Match"] = str(if_match)
 
     logger.debug(f"PUT {path} if_match={if_match}")
-    response = await client.put(path, json=json, headers=headers)
-    response.raise_for_status()
-    return response
+    return await _send(client, "PUT", path, extra_headers=extra_headers, timeout=timeout, json=json)
 
 
 async def call_patch(
     client: httpx.AsyncClient,
     path: str,
     json: Optional[Dict[str, Any]] = None,
+    timeout: Optional[float] = None,
 ) -> httpx.Response:
     """
     Make PATCH request to Go API.
 
-    Ensures tenant is resolved, creates a fresh sync session, and includes all required headers.
+    Ensures tenant is resolved, reuses (or creates) the user's sync session, and includes all required headers.
 
     Args:
         client: httpx client (with TenantDirectTransport)
         path: API endpoint path (e.g., "/v1/notes/{uid}")
         json: JSON request body (partial update)
+        timeout: Total time budget in seconds for this call, including retries
+            (defaults to the client timeout per attempt and retry_budget_seconds)
 
     Returns:
         HTTP response
@@ -356,35 +631,25 @@ async def call_patch(
         httpx.HTTPStatusError: If request fails
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
-    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
-    await ensure_tenant_resolved(client)
-
-    auth_header = await get_backend_auth_header(client)
-    session_headers = await ensure_session(client, auth_header)
-
-    headers = {
-        "Authorization": auth_header,
-        **session_headers,
-    }
-
     logger.debug(f"PATCH {path}")
-    response = await client.patch(path, json=json, headers=headers)
-    response.raise_for_status()
-    return response
+    return await _send(client, "PATCH", path, timeout=timeout, json=json)
 
 
 async def call_delete(
     client: httpx.AsyncClient,
     path: str,
+    timeout: Optional[float] = None,
 ) -> httpx.Response:
     """
     Make DELETE request to Go API.
 
-    Ensures tenant is resolved, creates a fresh sync session, and includes all required headers.
+    Ensures tenant is resolved, reuses (or creates) the user's sync session, and includes all required headers.
 
     Args:
         client: httpx client (with TenantDirectTransport)
         path: API endpoint path (e.g., "/v1/notes/{uid}")
+        timeout: Total time budget in seconds for this call, including retries
+            (defaults to the client timeout per attempt and retry_budget_seconds)
 
     Returns:
         HTTP response
@@ -393,18 +658,5 @@ async def call_delete(
         httpx.HTTPStatusError: If request fails
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
-    # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
-    await ensure_tenant_resolved(client)
-
-    auth_header = await get_backend_auth_header(client)
-    session_headers = await ensure_session(client, auth_header)
-
-    headers = {
-        "Authorization": auth_header,
-        **session_headers,
-    }
-
     logger.debug(f"DELETE {path}")
-    response = await client.delete(path, headers=headers)
-    response.raise_for_status()
-    return response
+    return await _send(client, "DELETE", path, timeout=timeout)
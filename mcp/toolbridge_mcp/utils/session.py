@@ -1,17 +1,23 @@
 """
 Session management for MCP tool requests with per-user authentication.
 
-Each MCP tool invocation creates a new sync session with the Go API.
-This session-per-request approach is simple and doesn't require cleanup.
+Sync sessions are cached per user (keyed by the backend JWT sub claim) so
+consecutive tool calls reuse the same session instead of creating a new one
+every time. Cached sessions expire before the Go API's 30 minute session TTL,
+and the least recently used users are evicted once the cache is full.
 
 Path B OAuth 2.1: Backend JWT contains per-user identity (sub claim),
 so the Go API automatically creates sessions for the correct user.
 
-Note: Sessions are NOT cached or reused. Every call to create_session
-creates a fresh session to avoid stale session issues when sessions expire.
+Stale sessions are handled by the request helpers: a 428 response (session
+expired or lost on server restart) or a 409 epoch_mismatch (account wiped or
+restored, so the cached X-Sync-Epoch is stale) invalidates the cached entry
+and the call is retried once with a fresh session.
 """
 
-from typing import Dict
+import time
+from collections import OrderedDict
+from typing import Dict, Optional
 
 import httpx
 from loguru import logger
@@ -25,14 +31,86 @@ class SessionError(Exception):
     pass
 
 
+class SessionCache:
+    """
+    Per-user cache of sync session headers with TTL expiry and LRU eviction.
+
+    Entries are keyed by user ID (backend JWT sub claim). Each entry expires
+    ttl_seconds after it was stored; when max_users is reached the least
+    recently used entry is evicted.
+    """
+
+    def __init__(self, ttl_seconds: int, max_users: int):
+        self.ttl_seconds = ttl_seconds
+        self.max_users = max_users
+        self._entries: "OrderedDict[str, tuple[Dict[str, str], float]]" = OrderedDict()
+
+    def get(self, user_id: str) -> Optional[Dict[str, str]]:
+        """Return cached session headers for user, or None if missing/expired."""
+        entry = self._entries.get(user_id)
+        if entry is None:
+            return None
+
+        headers, expires_at = entry
+        if time.monotonic() >= expires_at:
+            del self._entries[user_id]
+            return None
+
+        self._entries.move_to_end(user_id)
+        return dict(headers)
+
+    def put(self, user_id: str, headers: Dict[str, str]) -> None:
+        """Store session headers for user, evicting the LRU entry if full."""
+        self._entries[user_id] = (dict(headers), time.monotonic() + self.ttl_seconds)
+        self._entries.move_to_end(user_id)
+
+        while len(self._entries) > self.max_users:
+            evicted, _ = self._entries.popitem(last=False)
+            logger.debug(f"Evicted cached sync session for user: {evicted}")
+
+    def expires_in(self, user_id: str) -> Optional[float]:
+        """Seconds until the user's cached session expires, or None if not cached."""
+        entry = self._entries.get(user_id)
+        if entry is None:
+            return None
+        remaining = entry[1] - time.monotonic()
+        return remaining if remaining > 0 else None
+
+    def invalidate(self, user_id: str) -> None:
+        """Drop the cached session for user (e.g., after a 428 or epoch_mismatch response)."""
+        self._entries.pop(user_id, None)
+
+    def clear(self) -> None:
+        """Drop all cached sessions."""
+        self._entries.clear()
+
+    def __len__(self) -> int:
+        return len(self._entries)
+
+
+# Global session cache - lazily created so settings are read on first use
+_session_cache: Optional[SessionCache] = None
+
+
+def get_session_cache() -> SessionCache:
+    """Get the global per-user session cache, creating it on first access."""
+    global _session_cache
+    if _session_cache is None:
+        _session_cache = SessionCache(
+            ttl_seconds=settings.session_cache_ttl_seconds,
+            max_users=settings.session_cache_max_users,
+        )
+    return _session_cache
+
+
 async def create_session(
     client: httpx.AsyncClient, auth_header: str, user_id: str
 ) -> Dict[str, str]:
     """
     Create a new sync session with the Go API.
 
-    This always creates a fresh session - no caching or reuse.
-    This ensures MCP tools can recover from session expiration.
+    This always creates a fresh session. Callers that want reuse across
+    tool calls should go through SessionCache (see utils.requests.ensure_session).
 
     Path B OAuth 2.1: The backend JWT (auth_header) contains the user's
     identity (sub claim), so the Go API automatically creates a session
@@ -0,0 +1,92 @@
+"""
+W3C trace context propagation to the Go API.
+
+Each tool call gets one trace: the host's traceparent/tracestate headers are
+continued when the MCP request carried them, otherwise a new trace ID is
+generated. Every Go API request made during the call is sent with a
+traceparent for that trace (and a fresh parent span ID), so the call can be
+followed through the proxy into the API's HTTP and DB spans.
+"""
+
+import re
+import secrets
+from contextvars import ContextVar
+from dataclasses import dataclass
+from typing import Dict, Optional
+
+from fastmcp.server.dependencies import get_http_headers
+from fastmcp.server.middleware import Middleware, MiddlewareContext
+from loguru import logger
+
+_TRACEPARENT_RE = re.compile(r"^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$")
+_INVALID_TRACE_ID = "0" * 32
+_INVALID_SPAN_ID = "0" * 16
+
+
+@dataclass(frozen=True)
+class TraceContext:
+    """Trace identifiers for the current tool call."""
+
+    trace_id: str
+    flags: str = "01"
+    tracestate: Optional[str] = None
+
+    def headers(self) -> Dict[str, str]:
+        """Headers for one outbound request (new span ID per request)."""
+        headers = {"traceparent": f"00-{self.trace_id}-{secrets.token_hex(8)}-{self.flags}"}
+        if self.tracestate:
+            headers["tracestate"] = self.tracestate
+        return headers
+
+
+_current: ContextVar[Optional[TraceContext]] = ContextVar("toolbridge_trace_context", default=None)
+
+
+def parse_traceparent(value: Optional[str], tracestate: Optional[str] = None) -> Optional[TraceContext]:
+    """Parse a version-00 traceparent header; returns None when missing or invalid."""
+    if not value:
+        return None
+    match = _TRACEPARENT_RE.match(value.strip().lower())
+    if not match:
+        return None
+    trace_id, span_id, flags = match.groups()
+    if trace_id == _INVALID_TRACE_ID or span_id == _INVALID_SPAN_ID:
+        return None
+    return TraceContext(trace_id=trace_id, flags=flags, tracestate=tracestate or None)
+
+
+def new_trace_context() -> TraceContext:
+    """Start a new (sampled) trace."""
+    return TraceContext(trace_id=secrets.token_hex(16))
+
+
+def current_trace_context() -> TraceContext:
+    """Trace context for the current tool call (a new trace outside of one)."""
+    return _current.get() or new_trace_context()
+
+
+def trace_headers() -> Dict[str, str]:
+    """traceparent/tracestate headers to send with a Go API request."""
+    return current_trace_context().headers()
+
+
+class TraceContextMiddleware(Middleware):
+    """FastMCP middleware that binds a trace context to each tool call."""
+
+    async def on_call_tool(self, context: MiddlewareContext, call_next):
+        try:
+            incoming = get_http_headers(include_all=True)
+        except Exception:
+            # No HTTP request (e.g. stdio transport)
+            incoming = {}
+
+        ctx = parse_traceparent(incoming.get("traceparent"), incoming.get("tracestate"))
+        if ctx is None:
+            ctx = new_trace_context()
+
+        token = _current.set(ctx)
+        try:
+            with logger.contextualize(trace_id=ctx.trace_id):
+                return await call_next(context)
+        finally:
+            _current.reset(token)
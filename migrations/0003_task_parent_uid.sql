@@ -3,6 +3,9 @@
 --
 -- The parentUid field is stored in payload_json (JSONB) for sync compatibility.
 -- This migration adds an optional expression index for future server-side filtering.
+--
+-- CREATE INDEX CONCURRENTLY cannot run inside a transaction block:
+-- migrate:no-transaction
 
 -- Expression index on parentUid extracted from JSONB payload
 -- This enables efficient queries like: WHERE payload_json->>'parentUid' = 'some-uid'
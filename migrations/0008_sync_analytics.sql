@@ -0,0 +1,19 @@
+-- Per-user daily sync analytics
+-- Rolled up from in-process counters by the API's background flusher (internal/analytics).
+-- Each flush adds to the day's row, so multiple API replicas can write the same day.
+
+CREATE TABLE IF NOT EXISTS sync_analytics_daily (
+  owner_id      UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  day           DATE NOT NULL,               -- UTC calendar day
+  pushes        BIGINT NOT NULL DEFAULT 0,   -- Push requests (committed batches)
+  pulls         BIGINT NOT NULL DEFAULT 0,   -- Pull requests (pages)
+  items_pushed  BIGINT NOT NULL DEFAULT 0,
+  items_pulled  BIGINT NOT NULL DEFAULT 0,   -- Upserts + tombstones returned
+  bytes_in      BIGINT NOT NULL DEFAULT 0,   -- Sync request payload bytes (mostly push)
+  bytes_out     BIGINT NOT NULL DEFAULT 0,   -- Sync response payload bytes (mostly pull)
+  conflicts     BIGINT NOT NULL DEFAULT 0,   -- Pushed items where LWW kept the server row
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, day)
+);
+
+COMMENT ON TABLE sync_analytics_daily IS 'Daily per-user sync aggregates for GET /v1/sync/analytics';
@@ -0,0 +1,22 @@
+-- Activity (change) log for GET /v1/activity
+-- One row per applied write (sync push or REST mutation), written in the same
+-- transaction as the entity change. Idempotent re-pushes and LWW-rejected
+-- updates don't change the entity and aren't logged.
+
+CREATE TABLE IF NOT EXISTS activity_log (
+  id            BIGSERIAL PRIMARY KEY,       -- Insertion order (feed cursor)
+  owner_id      UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity        TEXT NOT NULL,               -- Entity table name (note, task, comment, ...)
+  uid           UUID NOT NULL,
+  action        TEXT NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+  version       INT NOT NULL,                -- Server version after the change
+  updated_at_ms BIGINT NOT NULL,             -- Entity updated_at_ms after the change
+  session_id    TEXT,                        -- X-Sync-Session of the writer (NULL if none)
+  device_id     TEXT,                        -- Optional X-Device-ID of the writer
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Newest-first feed per user
+CREATE INDEX IF NOT EXISTS activity_log_owner_id_idx ON activity_log (owner_id, id DESC);
+
+COMMENT ON TABLE activity_log IS 'Per-user change log of applied entity writes (created/updated/deleted)';
@@ -0,0 +1,23 @@
+-- Account data export jobs (GDPR / data portability)
+-- POST /v1/account/export enqueues a job; the API's background worker builds a
+-- zip archive of all the user's data and stores it here until it expires.
+
+CREATE TABLE IF NOT EXISTS export_job (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id     UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  status       TEXT NOT NULL DEFAULT 'queued'
+               CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'expired')),
+  error        TEXT,                        -- Failure reason (status = failed)
+  archive      BYTEA,                       -- Zip archive (status = succeeded; cleared on expiry)
+  size_bytes   BIGINT,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at   TIMESTAMPTZ,
+  finished_at  TIMESTAMPTZ,
+  expires_at   TIMESTAMPTZ                  -- Archive (and download URLs) invalid after this
+);
+
+-- Worker queue scan and per-user job listing
+CREATE INDEX IF NOT EXISTS export_job_status_created_idx ON export_job (status, created_at);
+CREATE INDEX IF NOT EXISTS export_job_owner_created_idx ON export_job (owner_id, created_at DESC);
+
+COMMENT ON TABLE export_job IS 'Asynchronous account data export jobs and their archives';
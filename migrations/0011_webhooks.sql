@@ -0,0 +1,36 @@
+-- Webhook subscriptions and their delivery log (/v1/webhooks)
+-- Deliveries are queued in the same transaction as the entity change (alongside
+-- activity_log), then POSTed by the API's background worker with retries.
+
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id    UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  url         TEXT NOT NULL,                 -- HTTPS callback
+  secret      TEXT NOT NULL,                 -- HMAC-SHA256 signing secret (shown once on create)
+  entities    TEXT[] NOT NULL DEFAULT '{}',  -- Entity filter (empty = all entities)
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_subscription_owner_idx ON webhook_subscription (owner_id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  subscription_id  UUID NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+  event_type       TEXT NOT NULL,            -- <entity>.<action>, e.g. task.updated
+  payload          JSONB NOT NULL,           -- Event body sent to the callback
+  status           TEXT NOT NULL DEFAULT 'pending'
+                   CHECK (status IN ('pending', 'succeeded', 'failed')),
+  attempts         INT NOT NULL DEFAULT 0,
+  next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(), -- Also the worker's lease while sending
+  last_status_code INT,                      -- HTTP status of the last attempt (NULL if no response)
+  last_error       TEXT,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at     TIMESTAMPTZ
+);
+
+-- Worker queue scan and per-subscription delivery log
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_delivery_subscription_idx ON webhook_delivery (subscription_id, created_at DESC);
+
+COMMENT ON TABLE webhook_subscription IS 'User-registered HTTPS callbacks for entity change events';
+COMMENT ON TABLE webhook_delivery IS 'Queued and attempted webhook deliveries (retried with backoff)';
@@ -0,0 +1,28 @@
+-- Transactional outbox for change events
+-- Every applied write inserts an event here in the same transaction as the
+-- entity upsert; the background dispatcher hands undispatched events to the
+-- publishers (webhooks, ...) and marks them dispatched. Delivery is
+-- at-least-once: consumers dedupe on event_id.
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+  id            BIGSERIAL PRIMARY KEY,        -- Dispatch order
+  event_id      UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(), -- Dedup key sent with every publish
+  owner_id      UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity        TEXT NOT NULL,
+  uid           UUID NOT NULL,
+  action        TEXT NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+  version       INT NOT NULL,
+  updated_at_ms BIGINT NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  dispatched_at TIMESTAMPTZ                   -- NULL until every publisher accepted the event
+);
+
+-- Dispatcher queue scan
+CREATE INDEX IF NOT EXISTS event_outbox_pending_idx ON event_outbox (id) WHERE dispatched_at IS NULL;
+
+-- Webhook deliveries are fanned out from the outbox; a re-dispatched event
+-- must not queue a second delivery to the same subscription.
+ALTER TABLE webhook_delivery ADD COLUMN IF NOT EXISTS event_id UUID;
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_delivery_event_idx ON webhook_delivery (subscription_id, event_id);
+
+COMMENT ON TABLE event_outbox IS 'Change events written with the entity change, dispatched to publishers at least once';
@@ -0,0 +1,12 @@
+-- Per-user ICS calendar feed tokens (GET /v1/calendar/{token}/tasks.ics)
+-- The feed URL is unauthenticated so calendar apps can subscribe to it; the
+-- random token in the URL authorizes it. Only its SHA-256 hash is stored, and
+-- rotating or revoking the token replaces or deletes the row.
+
+CREATE TABLE IF NOT EXISTS calendar_feed (
+  owner_id    UUID PRIMARY KEY REFERENCES app_user(id) ON DELETE CASCADE,
+  token_hash  TEXT NOT NULL UNIQUE,           -- hex SHA-256 of the URL token
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE calendar_feed IS 'Tokenized ICS task feed URLs (one per user)';
@@ -0,0 +1,34 @@
+-- Email notifications: per-user preferences and the outgoing queue
+-- Nothing is emailed until the user sets an address in their preferences.
+-- Notifications are queued by their triggers (reminder scan, account wipe, ...)
+-- and sent by the API's background worker with retries.
+
+CREATE TABLE IF NOT EXISTS notification_preference (
+  owner_id       UUID PRIMARY KEY REFERENCES app_user(id) ON DELETE CASCADE,
+  email          TEXT,                         -- Destination address (NULL = notifications off)
+  reminder_due   BOOLEAN NOT NULL DEFAULT true, -- A task reminder time was reached
+  task_assigned  BOOLEAN NOT NULL DEFAULT true, -- A task in a shared list was assigned to the user
+  account_wipe   BOOLEAN NOT NULL DEFAULT true, -- The account's synced data was wiped
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS notification (
+  id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id        UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  kind            TEXT NOT NULL,               -- Trigger (reminder_due, task_assigned, account_wipe)
+  dedup_key       TEXT NOT NULL UNIQUE,        -- A trigger firing twice queues one email
+  to_address      TEXT NOT NULL,
+  subject         TEXT NOT NULL,
+  body            TEXT NOT NULL,
+  status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- Also the worker's lease while sending
+  last_error      TEXT,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notification_due_idx ON notification (next_attempt_at) WHERE status = 'pending';
+
+COMMENT ON TABLE notification_preference IS 'Per-user email notification address and trigger opt-outs';
+COMMENT ON TABLE notification IS 'Queued and sent notification emails';
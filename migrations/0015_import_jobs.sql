@@ -0,0 +1,26 @@
+-- Import jobs (Todoist / TickTick exports)
+-- POST /v1/import/{source} stores the uploaded export here; the API's background
+-- worker maps it into task lists, tasks, notes and comments, updating progress
+-- as it goes. The upload is dropped once the job finishes.
+
+CREATE TABLE IF NOT EXISTS import_job (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id     UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  source       TEXT NOT NULL CHECK (source IN ('todoist', 'ticktick')),
+  status       TEXT NOT NULL DEFAULT 'queued'
+               CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+  input        BYTEA,                       -- Uploaded export (cleared when the job finishes)
+  total        INT NOT NULL DEFAULT 0,      -- Items to import
+  processed    INT NOT NULL DEFAULT 0,      -- Items pushed so far
+  counts       JSONB,                       -- Entity -> items written (status = succeeded)
+  error        TEXT,                        -- Failure reason (status = failed)
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at   TIMESTAMPTZ,
+  finished_at  TIMESTAMPTZ
+);
+
+-- Worker queue scan and per-user job listing
+CREATE INDEX IF NOT EXISTS import_job_status_created_idx ON import_job (status, created_at);
+CREATE INDEX IF NOT EXISTS import_job_owner_created_idx ON import_job (owner_id, created_at DESC);
+
+COMMENT ON TABLE import_job IS 'Asynchronous imports of third-party task manager exports';
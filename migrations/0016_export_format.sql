@@ -0,0 +1,8 @@
+-- Export formats: the JSON account archive or a Markdown (Obsidian) vault
+-- Existing jobs keep the JSON format.
+
+ALTER TABLE export_job
+  ADD COLUMN IF NOT EXISTS format TEXT NOT NULL DEFAULT 'json'
+    CHECK (format IN ('json', 'markdown')),
+  ADD COLUMN IF NOT EXISTS include_tasks BOOLEAN NOT NULL DEFAULT false,    -- Markdown: also render tasks
+  ADD COLUMN IF NOT EXISTS include_comments BOOLEAN NOT NULL DEFAULT false; -- Markdown: append comments to their note/task
@@ -0,0 +1,24 @@
+-- Slack integration: mirror a chat to a Slack channel (/v1/integrations/slack)
+-- Each connection owns a webhook subscription of kind 'slack' whose URL is the
+-- channel's Slack incoming webhook; new chat messages are queued as
+-- webhook_delivery rows by the outbox dispatcher and sent by the webhook worker.
+-- Replies in the channel can be ingested back as chat messages (Slack Events API).
+
+ALTER TABLE webhook_subscription
+  ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'generic'
+    CHECK (kind IN ('generic', 'slack'));
+
+CREATE TABLE IF NOT EXISTS slack_connection (
+  subscription_id UUID PRIMARY KEY REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+  owner_id        UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  chat_uid        UUID NOT NULL,               -- Mirrored chat
+  channel_id      TEXT,                        -- Slack channel ID (required to ingest replies)
+  ingest_replies  BOOLEAN NOT NULL DEFAULT false,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (owner_id, chat_uid)
+);
+
+-- Inbound events are routed by channel
+CREATE INDEX IF NOT EXISTS slack_connection_channel_idx ON slack_connection (channel_id) WHERE ingest_replies;
+
+COMMENT ON TABLE slack_connection IS 'Per-user mirroring of a chat to a Slack channel';
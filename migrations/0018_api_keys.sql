@@ -0,0 +1,33 @@
+-- Static API keys and REST hooks for no-code platforms (Zapier, Make)
+-- /v1/zapier endpoints authenticate with an API key instead of a JWT + sync
+-- session. REST hook subscriptions are webhook subscriptions of kind 'zapier'
+-- whose deliveries carry the changed item as flat JSON.
+
+CREATE TABLE IF NOT EXISTS api_key (
+  id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id      UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  name          TEXT NOT NULL,               -- User label ("Zapier")
+  key_hash      TEXT NOT NULL UNIQUE,        -- SHA-256 of the key (the key is shown once)
+  prefix        TEXT NOT NULL,               -- First characters of the key, to tell keys apart
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_key_owner_idx ON api_key (owner_id);
+
+ALTER TABLE webhook_subscription DROP CONSTRAINT IF EXISTS webhook_subscription_kind_check;
+ALTER TABLE webhook_subscription ADD CONSTRAINT webhook_subscription_kind_check
+  CHECK (kind IN ('generic', 'slack', 'zapier'));
+
+CREATE TABLE IF NOT EXISTS zapier_hook (
+  subscription_id UUID PRIMARY KEY REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+  owner_id        UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity          TEXT NOT NULL,               -- Entity table name (task, note, ...)
+  action          TEXT NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS zapier_hook_owner_idx ON zapier_hook (owner_id, entity, action);
+
+COMMENT ON TABLE api_key IS 'Static per-user API keys for /v1/zapier endpoints';
+COMMENT ON TABLE zapier_hook IS 'REST hook subscriptions (Zapier/Make instant triggers)';
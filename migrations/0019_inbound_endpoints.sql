@@ -0,0 +1,19 @@
+-- Inbound webhook endpoints: POST /v1/inbound/{token} turns external payloads
+-- (JSON, form posts, email-parse webhooks) into notes or tasks
+-- The token is the only credential, so only its hash is stored. Templates map
+-- item fields to strings with {{path}} placeholders into the payload.
+
+CREATE TABLE IF NOT EXISTS inbound_endpoint (
+  id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id          UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  name              TEXT NOT NULL,               -- User label ("Email to task")
+  token_hash        TEXT NOT NULL UNIQUE,        -- SHA-256 of the URL token (shown once)
+  entity            TEXT NOT NULL CHECK (entity IN ('note', 'task')),
+  template          JSONB NOT NULL,              -- Item field -> template string
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_received_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS inbound_endpoint_owner_idx ON inbound_endpoint (owner_id);
+
+COMMENT ON TABLE inbound_endpoint IS 'Tokenized inbound webhook URLs that create notes or tasks';
@@ -0,0 +1,17 @@
+-- Sync sessions shared across replicas
+-- Used by the postgres session store (SESSION_STORE=postgres, the staging and
+-- prod default); dev keeps sessions in memory. Expired rows are deleted
+-- opportunistically when sessions are created.
+
+CREATE TABLE IF NOT EXISTS sync_session (
+  id          UUID PRIMARY KEY,
+  owner_id    UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  epoch       INT NOT NULL,                -- owner_state.epoch when the session began
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sync_session_owner_idx ON sync_session (owner_id);
+CREATE INDEX IF NOT EXISTS sync_session_expires_idx ON sync_session (expires_at);
+
+COMMENT ON TABLE sync_session IS 'Active sync sessions (X-Sync-Session) for the postgres session store';
@@ -0,0 +1,21 @@
+-- Shared task lists
+-- The owner of a task list can add other users as members with view or edit
+-- permission. Shared lists and their tasks stay in the owner's rows (owner_id
+-- is unchanged); members read them through /v1/sync/shared/* and, with edit,
+-- push tasks into them. Memberships go with the list when the owner's data is
+-- wiped.
+
+CREATE TABLE IF NOT EXISTS task_list_member (
+  owner_id    UUID NOT NULL,
+  list_uid    UUID NOT NULL,
+  member_id   UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  permission  TEXT NOT NULL CHECK (permission IN ('view', 'edit')),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, list_uid, member_id),
+  FOREIGN KEY (owner_id, list_uid) REFERENCES task_list (owner_id, uid) ON DELETE CASCADE,
+  CHECK (member_id <> owner_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_list_member_member_idx ON task_list_member (member_id);
+
+COMMENT ON TABLE task_list_member IS 'Users a task list is shared with, and whether they can edit its tasks';
@@ -0,0 +1,21 @@
+-- Per-item sharing
+-- The owner of a note or chat can grant other users view or edit access to
+-- that one item (a chat's grant covers its messages). Like shared task lists,
+-- shared items stay in the owner's rows; grantees read and write them through
+-- /v1/sync/shared/*. Entity rows have no common table to reference, so grants
+-- are deleted with the owner's data on wipe rather than by foreign key.
+
+CREATE TABLE IF NOT EXISTS item_grant (
+  owner_id    UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity      TEXT NOT NULL CHECK (entity IN ('note', 'chat')),
+  item_uid    UUID NOT NULL,
+  grantee_id  UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  permission  TEXT NOT NULL CHECK (permission IN ('view', 'edit')),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, entity, item_uid, grantee_id),
+  CHECK (grantee_id <> owner_id)
+);
+
+CREATE INDEX IF NOT EXISTS item_grant_grantee_idx ON item_grant (grantee_id, entity);
+
+COMMENT ON TABLE item_grant IS 'Users an individual note or chat is shared with, and whether they can edit it';
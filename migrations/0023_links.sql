@@ -0,0 +1,42 @@
+-- Cross-entity links and backlinks
+-- Items can carry a "links" array of {"entity", "uid"} references. Each applied
+-- push replaces the item's rows here (a tombstone removes them), so
+-- GET /v1/links/backlinks/{uid} lists what refers to an item without clients
+-- scanning everything. Targets aren't required to exist: a link may arrive
+-- before its target, and pushes only warn about it.
+
+CREATE TABLE IF NOT EXISTS link (
+  owner_id       UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  source_entity  TEXT NOT NULL,
+  source_uid     UUID NOT NULL,
+  target_entity  TEXT NOT NULL,
+  target_uid     UUID NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, source_entity, source_uid, target_entity, target_uid)
+);
+
+-- Backlink lookups
+CREATE INDEX IF NOT EXISTS link_target_idx ON link (owner_id, target_uid);
+
+COMMENT ON TABLE link IS 'References between a user''s items, extracted from payload "links" arrays';
+
+-- Index links already in stored payloads
+INSERT INTO link (owner_id, source_entity, source_uid, target_entity, target_uid)
+SELECT s.owner_id, s.entity, s.uid, l->>'entity', (l->>'uid')::uuid
+FROM (
+  SELECT owner_id, 'note' AS entity, uid, payload_json FROM note WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'task', uid, payload_json FROM task WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'task_list', uid, payload_json FROM task_list WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'task_list_category', uid, payload_json FROM task_list_category WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'comment', uid, payload_json FROM comment WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'chat', uid, payload_json FROM chat WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, 'chat_message', uid, payload_json FROM chat_message WHERE deleted_at_ms IS NULL
+) s
+CROSS JOIN LATERAL jsonb_array_elements(
+  CASE WHEN jsonb_typeof(s.payload_json->'links') = 'array' THEN s.payload_json->'links' ELSE '[]'::jsonb END
+) l
+WHERE jsonb_typeof(l) = 'object'
+  AND l->>'entity' IN ('note', 'task', 'task_list', 'task_list_category', 'comment', 'chat', 'chat_message')
+  AND l->>'uid' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$'
+  AND l->>'uid' <> '00000000-0000-0000-0000-000000000000'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,25 @@
+-- Pins (favorites) for delta sync
+-- A pin marks a note, task or chat as a favorite, with the client's sort order
+-- in the payload, so favorites sync across devices like any other entity.
+
+CREATE TABLE IF NOT EXISTS pin (
+  uid            UUID NOT NULL,
+  owner_id       UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  updated_at_ms  BIGINT NOT NULL,            -- Unix milliseconds for cursor-based pagination
+  deleted_at_ms  BIGINT,                     -- NULL = alive, non-NULL = tombstone
+  version        INT NOT NULL DEFAULT 1,     -- Server-controlled version for conflict detection
+  payload_json   JSONB NOT NULL,             -- Original client JSON (preserved as-is)
+  target_type    TEXT NOT NULL CHECK (target_type IN ('note', 'task', 'chat')),
+  target_uid     UUID NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, uid)                -- Composite key for tenant isolation
+);
+
+-- Indexes for efficient delta sync queries
+CREATE INDEX IF NOT EXISTS pin_owner_updated_idx ON pin (owner_id, updated_at_ms);
+CREATE INDEX IF NOT EXISTS pin_cursor_idx ON pin (updated_at_ms, uid);
+CREATE INDEX IF NOT EXISTS pin_target_idx ON pin (owner_id, target_type, target_uid);
+
+COMMENT ON TABLE pin IS 'Pinned (favorite) notes, tasks and chats - uses LWW conflict resolution';
+COMMENT ON COLUMN pin.payload_json IS 'Client JSON with fields: uid, targetType, targetUid, sortOrder, updatedTs, sync';
+COMMENT ON COLUMN pin.target_uid IS 'UID of the pinned item - existence validated at application level';
@@ -0,0 +1,24 @@
+-- Note revision history
+-- Each applied push that changes a note's title or content records the new
+-- payload here, keyed by the note version it produced, so clients can show
+-- edit history, diff versions and restore one that LWW has since overwritten.
+-- Only the newest 50 revisions per note are kept. Revisions go with their note
+-- (wipe, hard delete) through the foreign key.
+
+CREATE TABLE IF NOT EXISTS note_revision (
+  owner_id       UUID NOT NULL,
+  note_uid       UUID NOT NULL,
+  version        INT NOT NULL,               -- Note version the write produced
+  updated_at_ms  BIGINT NOT NULL,            -- Note updated_at_ms after the write
+  payload_json   JSONB NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (owner_id, note_uid, version),
+  FOREIGN KEY (owner_id, note_uid) REFERENCES note (owner_id, uid) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE note_revision IS 'Bounded history of note payloads, one row per text-changing write';
+
+-- Start each live note's history at its current state
+INSERT INTO note_revision (owner_id, note_uid, version, updated_at_ms, payload_json)
+SELECT owner_id, uid, version, updated_at_ms, payload_json FROM note WHERE deleted_at_ms IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,28 @@
+-- Dashboard counters
+-- GET /v1/sync/counters reads a few aggregates that would otherwise mean
+-- pulling whole tables. The payload fields they depend on are copied into
+-- generated columns, so the counts come from small partial indexes instead of
+-- decoding every payload. Adding stored generated columns rewrites task and
+-- chat_message once.
+
+-- Open tasks: live and not done/completed/archived, by list ('' for none)
+ALTER TABLE task
+  ADD COLUMN IF NOT EXISTS task_list_uid TEXT
+    GENERATED ALWAYS AS (coalesce(lower(payload_json->>'taskListUid'), '')) STORED,
+  ADD COLUMN IF NOT EXISTS is_open BOOLEAN
+    GENERATED ALWAYS AS (deleted_at_ms IS NULL AND coalesce(payload_json->>'status', '') NOT IN ('done', 'completed', 'archived')) STORED;
+
+CREATE INDEX IF NOT EXISTS task_open_idx ON task (owner_id, task_list_uid) WHERE is_open;
+
+-- Unread messages: live, not marked read, and not written by the user
+ALTER TABLE chat_message
+  ADD COLUMN IF NOT EXISTS is_unread BOOLEAN
+    GENERATED ALWAYS AS (deleted_at_ms IS NULL AND (payload_json->'read') IS DISTINCT FROM 'true'::jsonb AND (payload_json->>'role') IS DISTINCT FROM 'user') STORED;
+
+CREATE INDEX IF NOT EXISTS chat_message_unread_idx ON chat_message (owner_id, chat_uid) WHERE is_unread;
+
+-- Notes created since a given time
+CREATE INDEX IF NOT EXISTS note_created_idx ON note (owner_id, created_at) WHERE deleted_at_ms IS NULL;
+
+COMMENT ON COLUMN task.is_open IS 'Generated: live and status not done/completed/archived (dashboard counters)';
+COMMENT ON COLUMN chat_message.is_unread IS 'Generated: live, not read and not role user (dashboard counters)';
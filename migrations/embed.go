@@ -0,0 +1,11 @@
+// Package migrations embeds the SQL schema migrations
+// The same files are applied by `toolbridge-api migrate`, MIGRATE_ON_START and
+// scripts/migrate.sh; see internal/db/migrate.go for the file conventions.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -96,6 +96,11 @@ pending_count=0
 for migration_file in $(ls -1 "$MIGRATIONS_DIR"/*.sql | sort); do
     migration_name=$(basename "$migration_file")
 
+    # Down migrations are only run by `toolbridge-api migrate down`
+    case "$migration_name" in
+        *.down.sql) continue ;;
+    esac
+
     # Check if migration was already applied
     if echo "$applied_migrations" | grep -q "$migration_name"; then
         log_info "Already applied: $migration_name"
@@ -0,0 +1,124 @@
+// Package toolbridgetest runs the ToolBridge API in-process for integration
+// tests in other Go projects (for example an MCP server's tests)
+//
+// NewServer serves the real router, sync services and migrations on a fresh
+// Postgres database of its own, so tests exercise exactly what a deployment
+// does. The database comes from TEST_DATABASE_URL when set (the role needs
+// CREATEDB), otherwise from a Postgres container started once per test binary;
+// with neither available the test is skipped, as it is in -short mode.
+//
+//	func TestCreateNote(t *testing.T) {
+//		srv := toolbridgetest.NewServer(t)
+//		req, _ := http.NewRequest("POST", srv.URL+"/v1/notes", body)
+//		req.Header = srv.Headers(t, "user-1")
+//		...
+//	}
+//
+// Servers run in dev mode with an in-memory session store. Session store,
+// clock skew and cursor settings are process-wide, so don't run servers with
+// different Options in parallel.
+package toolbridgetest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/config"
+	"github.com/erauner12/toolbridge-api/internal/syncclient"
+	"github.com/erauner12/toolbridge-api/internal/testutil"
+)
+
+// Options adjusts the server's configuration
+// The zero value matches the dev profile's defaults.
+type Options struct {
+	// MockIdP serves the mock OIDC issuer at URL+"/dev/idp", and Token returns
+	// RS256 tokens from it (validated through JWKS, like WorkOS or Auth0 tokens)
+	// instead of HS256 backend tokens
+	MockIdP bool
+	// Audience is JWT_AUDIENCE (only checked on IdP tokens)
+	Audience string
+	// TenantClaim is TENANT_CLAIM
+	TenantClaim string
+	// ServerTimestamps is SYNC_SERVER_TIMESTAMPS: the server stamps pushed items
+	ServerTimestamps bool
+	// DisableRateLimits lifts the per-user rate limits so busy tests don't see 429s
+	DisableRateLimits bool
+}
+
+// Server is a running API
+type Server struct {
+	URL string // Base URL, e.g. http://127.0.0.1:41235
+
+	env     *testutil.Env
+	mockIdP bool
+}
+
+// NewServer starts the API with default options (see the package comment for
+// where its database comes from)
+// The server and database are torn down when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	return NewServerWithOptions(t, Options{})
+}
+
+// NewServerWithOptions starts the API configured by opts
+func NewServerWithOptions(t testing.TB, opts Options) *Server {
+	t.Helper()
+	env := testutil.NewEnv(t, func(c *config.Config) {
+		c.Auth.MockIdP = opts.MockIdP
+		c.Auth.Audience = opts.Audience
+		c.Auth.TenantClaim = opts.TenantClaim
+		c.Sync.ServerTimestamps = opts.ServerTimestamps
+		if opts.DisableRateLimits {
+			unlimited := config.RateLimitValues{WindowSeconds: 1, MaxRequests: 1_000_000, Burst: 1_000_000}
+			c.RateLimit.Sync, c.RateLimit.Auth = unlimited, unlimited
+		}
+	})
+	return &Server{URL: env.URL, env: env, mockIdP: opts.MockIdP}
+}
+
+// Token returns an access token for subject, valid for an hour
+// The user is created on its first request, as with a real IdP.
+func (s *Server) Token(t testing.TB, subject string) string {
+	t.Helper()
+	if s.mockIdP {
+		return s.env.MockIdPToken(t, subject)
+	}
+	return s.env.Token(t, subject)
+}
+
+// BeginSession starts a sync session with token and returns its ID and the
+// user's epoch (the X-Sync-Session and X-Sync-Epoch values)
+func (s *Server) BeginSession(t testing.TB, token string) (string, int) {
+	t.Helper()
+	c := syncclient.New(s.URL)
+	c.Token = token
+	if err := c.BeginSession(context.Background()); err != nil {
+		t.Fatalf("begin session: %v", err)
+	}
+	return c.Session()
+}
+
+// Headers returns the headers sync and REST endpoints require for subject:
+// Authorization with a new token, and X-Sync-Session and X-Sync-Epoch for a
+// new session
+func (s *Server) Headers(t testing.TB, subject string) http.Header {
+	t.Helper()
+	token := s.Token(t, subject)
+	session, epoch := s.BeginSession(t, token)
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+token)
+	h.Set("X-Sync-Session", session)
+	h.Set("X-Sync-Epoch", strconv.Itoa(epoch))
+	return h
+}
+
+// Restart replaces the server with a new one on the same database and URL, as
+// a deploy does
+// Sync sessions and rate limit state are lost; with MockIdP, tokens issued
+// before the restart are rejected (the signing key is per server).
+func (s *Server) Restart() {
+	s.env.Restart()
+}
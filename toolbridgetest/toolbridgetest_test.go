@@ -0,0 +1,79 @@
+package toolbridgetest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/toolbridgetest"
+)
+
+// do sends one request with h and decodes a JSON response into out
+func do(t *testing.T, method, url string, h http.Header, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = h.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	for name, opts := range map[string]toolbridgetest.Options{
+		"hs256":    {},
+		"mock idp": {MockIdP: true, Audience: "toolbridge-api"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := toolbridgetest.NewServerWithOptions(t, opts)
+			h := srv.Headers(t, "downstream-user")
+
+			var created map[string]any
+			if code := do(t, http.MethodPost, srv.URL+"/v1/notes", h, `{"title":"from a downstream test"}`, &created); code != http.StatusCreated {
+				t.Fatalf("create note status = %d", code)
+			}
+			var pulled struct {
+				Upserts []map[string]any `json:"upserts"`
+			}
+			if code := do(t, http.MethodGet, srv.URL+"/v1/sync/notes/pull", h, "", &pulled); code != http.StatusOK {
+				t.Fatalf("pull status = %d", code)
+			}
+			if len(pulled.Upserts) != 1 || pulled.Upserts[0]["title"] != "from a downstream test" {
+				t.Errorf("pulled %v, want the created note", pulled.Upserts)
+			}
+
+			// Users are isolated
+			var other struct {
+				Upserts []map[string]any `json:"upserts"`
+			}
+			do(t, http.MethodGet, srv.URL+"/v1/sync/notes/pull", srv.Headers(t, "someone-else"), "", &other)
+			if len(other.Upserts) != 0 {
+				t.Errorf("another user pulled %v", other.Upserts)
+			}
+
+			// Sessions (and mock IdP keys) don't survive a restart; new ones work on the same data
+			srv.Restart()
+			want := http.StatusPreconditionRequired
+			if opts.MockIdP {
+				want = http.StatusUnauthorized
+			}
+			if code := do(t, http.MethodGet, srv.URL+"/v1/sync/notes/pull", h, "", nil); code != want {
+				t.Errorf("pull with pre-restart headers = %d, want %d", code, want)
+			}
+			do(t, http.MethodGet, srv.URL+"/v1/sync/notes/pull", srv.Headers(t, "downstream-user"), "", &pulled)
+			if len(pulled.Upserts) != 1 {
+				t.Errorf("pulled %d notes after restart, want 1", len(pulled.Upserts))
+			}
+		})
+	}
+}